@@ -3,12 +3,16 @@ package main
 import (
 	"flag"
 	"fmt"
-	"net"
+	"net/http"
 	"os"
 
 	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
 	"github.com/pion/ion-avp/cmd/signal/grpc/server"
 	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recordings"
+	"github.com/pion/ion-avp/pkg/remote"
+	rpb "github.com/pion/ion-avp/pkg/remote/proto"
+	"github.com/pion/ion-avp/pkg/sandbox"
 	log "github.com/pion/ion-log"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
@@ -68,6 +72,11 @@ func parse() bool {
 }
 
 func main() {
+	elems := map[string]avp.ElementFun{}
+	if sandbox.IsChild() {
+		os.Exit(sandbox.Main(elems))
+	}
+
 	if !parse() {
 		showHelp()
 		os.Exit(-1)
@@ -77,15 +86,35 @@ func main() {
 	fixByFunc := []string{}
 	log.Init(conf.Log.Level, fixByFile, fixByFunc)
 
-	lis, err := net.Listen("tcp", addr)
+	family, err := avp.ParseFamily(conf.Network.Family)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+
+	lis, err := family.Listen(addr)
 	if err != nil {
 		log.Panicf("failed to listen: %v", err)
 	}
-	log.Infof("--- AVP Node Listening at %s ---", addr)
+	log.Infof("--- AVP Node Listening at %s (%s) ---", lis.Addr(), family)
+
+	if conf.HTTP.Addr != "" {
+		hl, err := family.Listen(conf.HTTP.Addr)
+		if err != nil {
+			log.Panicf("failed to listen: %v", err)
+		}
+		go func() {
+			log.Infof("--- Serving recordings from %s at %s ---", conf.HTTP.Root, hl.Addr())
+			err := http.Serve(hl, recordings.NewHandler(conf.HTTP.Root, conf.HTTP.Token))
+			if err != nil {
+				log.Errorf("recordings http server: %v", err)
+			}
+		}()
+	}
 
 	s := grpc.NewServer()
-	srv := server.NewAVPServer(conf, map[string]avp.ElementFun{})
+	srv := server.NewAVPServer(conf, elems)
 	pb.RegisterAVPServer(s, srv)
+	rpb.RegisterRemoteServer(s, remote.NewServer(elems))
 
 	if err := s.Serve(lis); err != nil {
 		log.Panicf("failed to serve: %v", err)
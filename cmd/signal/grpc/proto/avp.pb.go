@@ -25,19 +25,84 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+// Priority decides which processes degrade first under load
+type Priority int32
+
+const (
+	Priority_NORMAL Priority = 0 // e.g. recording
+	Priority_LOW    Priority = 1 // e.g. snapshots, ML
+	Priority_HIGH   Priority = 2 // e.g. live restream
+)
+
+// Enum value maps for Priority.
+var (
+	Priority_name = map[int32]string{
+		0: "NORMAL",
+		1: "LOW",
+		2: "HIGH",
+	}
+	Priority_value = map[string]int32{
+		"NORMAL": 0,
+		"LOW":    1,
+		"HIGH":   2,
+	}
+)
+
+func (x Priority) Enum() *Priority {
+	p := new(Priority)
+	*p = x
+	return p
+}
+
+func (x Priority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Priority) Descriptor() protoreflect.EnumDescriptor {
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[0].Descriptor()
+}
+
+func (Priority) Type() protoreflect.EnumType {
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[0]
+}
+
+func (x Priority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Priority.Descriptor instead.
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{0}
+}
+
 type RecordConfig_Format int32
 
 const (
-	RecordConfig_WEBM RecordConfig_Format = 0
+	RecordConfig_WEBM RecordConfig_Format = 0 // VP8, VP9 or AV1, and Opus
+	RecordConfig_MP4  RecordConfig_Format = 1 // fragmented, H.264 and Opus
+	RecordConfig_MKV  RecordConfig_Format = 2 // Matroska, H.264, VP8, VP9 or AV1, and Opus
+	RecordConfig_HLS  RecordConfig_Format = 3 // live fMP4 segments of H.264 and Opus, and index.m3u8, in the directory filename, or under it in storage
+	RecordConfig_DASH RecordConfig_Format = 4 // fMP4 segments of H.264 and Opus, and manifest.mpd, in the directory filename, or under it in storage
+	RecordConfig_CMAF RecordConfig_Format = 5 // DASH whose segments are also listed by HLS playlists, index.m3u8
 )
 
 // Enum value maps for RecordConfig_Format.
 var (
 	RecordConfig_Format_name = map[int32]string{
 		0: "WEBM",
+		1: "MP4",
+		2: "MKV",
+		3: "HLS",
+		4: "DASH",
+		5: "CMAF",
 	}
 	RecordConfig_Format_value = map[string]int32{
 		"WEBM": 0,
+		"MP4":  1,
+		"MKV":  2,
+		"HLS":  3,
+		"DASH": 4,
+		"CMAF": 5,
 	}
 )
 
@@ -52,11 +117,11 @@ func (x RecordConfig_Format) String() string {
 }
 
 func (RecordConfig_Format) Descriptor() protoreflect.EnumDescriptor {
-	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[0].Descriptor()
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[1].Descriptor()
 }
 
 func (RecordConfig_Format) Type() protoreflect.EnumType {
-	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[0]
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[1]
 }
 
 func (x RecordConfig_Format) Number() protoreflect.EnumNumber {
@@ -65,7 +130,7 @@ func (x RecordConfig_Format) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RecordConfig_Format.Descriptor instead.
 func (RecordConfig_Format) EnumDescriptor() ([]byte, []int) {
-	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{5, 0}
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{7, 0}
 }
 
 type RecordConfig_Audio int32
@@ -101,11 +166,11 @@ func (x RecordConfig_Audio) String() string {
 }
 
 func (RecordConfig_Audio) Descriptor() protoreflect.EnumDescriptor {
-	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[1].Descriptor()
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[2].Descriptor()
 }
 
 func (RecordConfig_Audio) Type() protoreflect.EnumType {
-	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[1]
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[2]
 }
 
 func (x RecordConfig_Audio) Number() protoreflect.EnumNumber {
@@ -114,7 +179,7 @@ func (x RecordConfig_Audio) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RecordConfig_Audio.Descriptor instead.
 func (RecordConfig_Audio) EnumDescriptor() ([]byte, []int) {
-	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{5, 1}
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{7, 1}
 }
 
 type RecordConfig_Video int32
@@ -147,11 +212,11 @@ func (x RecordConfig_Video) String() string {
 }
 
 func (RecordConfig_Video) Descriptor() protoreflect.EnumDescriptor {
-	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[2].Descriptor()
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[3].Descriptor()
 }
 
 func (RecordConfig_Video) Type() protoreflect.EnumType {
-	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[2]
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[3]
 }
 
 func (x RecordConfig_Video) Number() protoreflect.EnumNumber {
@@ -160,7 +225,239 @@ func (x RecordConfig_Video) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RecordConfig_Video.Descriptor instead.
 func (RecordConfig_Video) EnumDescriptor() ([]byte, []int) {
-	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{5, 2}
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{7, 2}
+}
+
+type Redundancy_Role int32
+
+const (
+	Redundancy_PRIMARY Redundancy_Role = 0
+	Redundancy_BACKUP  Redundancy_Role = 1
+)
+
+// Enum value maps for Redundancy_Role.
+var (
+	Redundancy_Role_name = map[int32]string{
+		0: "PRIMARY",
+		1: "BACKUP",
+	}
+	Redundancy_Role_value = map[string]int32{
+		"PRIMARY": 0,
+		"BACKUP":  1,
+	}
+)
+
+func (x Redundancy_Role) Enum() *Redundancy_Role {
+	p := new(Redundancy_Role)
+	*p = x
+	return p
+}
+
+func (x Redundancy_Role) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Redundancy_Role) Descriptor() protoreflect.EnumDescriptor {
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[4].Descriptor()
+}
+
+func (Redundancy_Role) Type() protoreflect.EnumType {
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[4]
+}
+
+func (x Redundancy_Role) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Redundancy_Role.Descriptor instead.
+func (Redundancy_Role) EnumDescriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{9, 0}
+}
+
+type ExportJob_State int32
+
+const (
+	ExportJob_QUEUED   ExportJob_State = 0
+	ExportJob_RUNNING  ExportJob_State = 1
+	ExportJob_DONE     ExportJob_State = 2
+	ExportJob_FAILED   ExportJob_State = 3
+	ExportJob_CANCELED ExportJob_State = 4
+)
+
+// Enum value maps for ExportJob_State.
+var (
+	ExportJob_State_name = map[int32]string{
+		0: "QUEUED",
+		1: "RUNNING",
+		2: "DONE",
+		3: "FAILED",
+		4: "CANCELED",
+	}
+	ExportJob_State_value = map[string]int32{
+		"QUEUED":   0,
+		"RUNNING":  1,
+		"DONE":     2,
+		"FAILED":   3,
+		"CANCELED": 4,
+	}
+)
+
+func (x ExportJob_State) Enum() *ExportJob_State {
+	p := new(ExportJob_State)
+	*p = x
+	return p
+}
+
+func (x ExportJob_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportJob_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[5].Descriptor()
+}
+
+func (ExportJob_State) Type() protoreflect.EnumType {
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[5]
+}
+
+func (x ExportJob_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportJob_State.Descriptor instead.
+func (ExportJob_State) EnumDescriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type Recording_State int32
+
+const (
+	Recording_PENDING              Recording_State = 0
+	Recording_WAITING_FOR_KEYFRAME Recording_State = 1
+	Recording_RECORDING            Recording_State = 2
+	Recording_PAUSED               Recording_State = 3
+	Recording_FINALIZING           Recording_State = 4
+	Recording_UPLOADING            Recording_State = 5
+	Recording_COMPLETE             Recording_State = 6
+	Recording_FAILED               Recording_State = 7
+)
+
+// Enum value maps for Recording_State.
+var (
+	Recording_State_name = map[int32]string{
+		0: "PENDING",
+		1: "WAITING_FOR_KEYFRAME",
+		2: "RECORDING",
+		3: "PAUSED",
+		4: "FINALIZING",
+		5: "UPLOADING",
+		6: "COMPLETE",
+		7: "FAILED",
+	}
+	Recording_State_value = map[string]int32{
+		"PENDING":              0,
+		"WAITING_FOR_KEYFRAME": 1,
+		"RECORDING":            2,
+		"PAUSED":               3,
+		"FINALIZING":           4,
+		"UPLOADING":            5,
+		"COMPLETE":             6,
+		"FAILED":               7,
+	}
+)
+
+func (x Recording_State) Enum() *Recording_State {
+	p := new(Recording_State)
+	*p = x
+	return p
+}
+
+func (x Recording_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Recording_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[6].Descriptor()
+}
+
+func (Recording_State) Type() protoreflect.EnumType {
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[6]
+}
+
+func (x Recording_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Recording_State.Descriptor instead.
+func (Recording_State) EnumDescriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{19, 0}
+}
+
+type ValidationError_Code int32
+
+const (
+	ValidationError_INVALID             ValidationError_Code = 0 // the request is incomplete
+	ValidationError_ELEMENT_NOT_FOUND   ValidationError_Code = 1 // no element of that id on this node
+	ValidationError_ELEMENT_DISABLED    ValidationError_Code = 2 // disabled in low memory mode
+	ValidationError_CODEC_UNSUPPORTED   ValidationError_Code = 3 // the track's codec can't be processed
+	ValidationError_DESTINATION         ValidationError_Code = 4 // the recording can't be written locally
+	ValidationError_STORAGE_UNREACHABLE ValidationError_Code = 5 // the storage backend failed a listing
+	ValidationError_PEER_UNREACHABLE    ValidationError_Code = 6 // the primary of a backup didn't answer
+	ValidationError_OVERLOADED          ValidationError_Code = 7 // the node is shedding samples of the priority
+	ValidationError_TRACK_PENDING       ValidationError_Code = 8 // the track hasn't arrived, the pipeline waits for it
+)
+
+// Enum value maps for ValidationError_Code.
+var (
+	ValidationError_Code_name = map[int32]string{
+		0: "INVALID",
+		1: "ELEMENT_NOT_FOUND",
+		2: "ELEMENT_DISABLED",
+		3: "CODEC_UNSUPPORTED",
+		4: "DESTINATION",
+		5: "STORAGE_UNREACHABLE",
+		6: "PEER_UNREACHABLE",
+		7: "OVERLOADED",
+		8: "TRACK_PENDING",
+	}
+	ValidationError_Code_value = map[string]int32{
+		"INVALID":             0,
+		"ELEMENT_NOT_FOUND":   1,
+		"ELEMENT_DISABLED":    2,
+		"CODEC_UNSUPPORTED":   3,
+		"DESTINATION":         4,
+		"STORAGE_UNREACHABLE": 5,
+		"PEER_UNREACHABLE":    6,
+		"OVERLOADED":          7,
+		"TRACK_PENDING":       8,
+	}
+)
+
+func (x ValidationError_Code) Enum() *ValidationError_Code {
+	p := new(ValidationError_Code)
+	*p = x
+	return p
+}
+
+func (x ValidationError_Code) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ValidationError_Code) Descriptor() protoreflect.EnumDescriptor {
+	return file_cmd_signal_grpc_proto_avp_proto_enumTypes[7].Descriptor()
+}
+
+func (ValidationError_Code) Type() protoreflect.EnumType {
+	return &file_cmd_signal_grpc_proto_avp_proto_enumTypes[7]
+}
+
+func (x ValidationError_Code) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ValidationError_Code.Descriptor instead.
+func (ValidationError_Code) EnumDescriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{22, 0}
 }
 
 type SignalRequest struct {
@@ -172,6 +469,8 @@ type SignalRequest struct {
 	//	*SignalRequest_Process
 	//	*SignalRequest_RecordStart
 	//	*SignalRequest_RecordStop
+	//	*SignalRequest_RecordPause
+	//	*SignalRequest_RecordResume
 	Payload isSignalRequest_Payload `protobuf_oneof:"payload"`
 }
 
@@ -235,6 +534,20 @@ func (x *SignalRequest) GetRecordStop() *RecordStop {
 	return nil
 }
 
+func (x *SignalRequest) GetRecordPause() *RecordPause {
+	if x, ok := x.GetPayload().(*SignalRequest_RecordPause); ok {
+		return x.RecordPause
+	}
+	return nil
+}
+
+func (x *SignalRequest) GetRecordResume() *RecordResume {
+	if x, ok := x.GetPayload().(*SignalRequest_RecordResume); ok {
+		return x.RecordResume
+	}
+	return nil
+}
+
 type isSignalRequest_Payload interface {
 	isSignalRequest_Payload()
 }
@@ -251,12 +564,24 @@ type SignalRequest_RecordStop struct {
 	RecordStop *RecordStop `protobuf:"bytes,3,opt,name=recordStop,proto3,oneof"`
 }
 
+type SignalRequest_RecordPause struct {
+	RecordPause *RecordPause `protobuf:"bytes,4,opt,name=recordPause,proto3,oneof"`
+}
+
+type SignalRequest_RecordResume struct {
+	RecordResume *RecordResume `protobuf:"bytes,5,opt,name=recordResume,proto3,oneof"`
+}
+
 func (*SignalRequest_Process) isSignalRequest_Payload() {}
 
 func (*SignalRequest_RecordStart) isSignalRequest_Payload() {}
 
 func (*SignalRequest_RecordStop) isSignalRequest_Payload() {}
 
+func (*SignalRequest_RecordPause) isSignalRequest_Payload() {}
+
+func (*SignalRequest_RecordResume) isSignalRequest_Payload() {}
+
 type SignalReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -301,12 +626,14 @@ type Process struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Sfu    string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu
-	Pid    string `protobuf:"bytes,2,opt,name=pid,proto3" json:"pid,omitempty"` // pipeline id
-	Sid    string `protobuf:"bytes,3,opt,name=sid,proto3" json:"sid,omitempty"` // session id
-	Tid    string `protobuf:"bytes,4,opt,name=tid,proto3" json:"tid,omitempty"` // track id
-	Eid    string `protobuf:"bytes,5,opt,name=eid,proto3" json:"eid,omitempty"` // element id
-	Config []byte `protobuf:"bytes,6,opt,name=config,proto3" json:"config,omitempty"`
+	Sfu         string   `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu
+	Pid         string   `protobuf:"bytes,2,opt,name=pid,proto3" json:"pid,omitempty"` // pipeline id
+	Sid         string   `protobuf:"bytes,3,opt,name=sid,proto3" json:"sid,omitempty"` // session id
+	Tid         string   `protobuf:"bytes,4,opt,name=tid,proto3" json:"tid,omitempty"` // track id
+	Eid         string   `protobuf:"bytes,5,opt,name=eid,proto3" json:"eid,omitempty"` // element id
+	Config      []byte   `protobuf:"bytes,6,opt,name=config,proto3" json:"config,omitempty"`
+	Priority    Priority `protobuf:"varint,7,opt,name=priority,proto3,enum=avp.Priority" json:"priority,omitempty"`
+	Correlation string   `protobuf:"bytes,8,opt,name=correlation,proto3" json:"correlation,omitempty"` // traces the process through logs, stats and events
 }
 
 func (x *Process) Reset() {
@@ -383,16 +710,32 @@ func (x *Process) GetConfig() []byte {
 	return nil
 }
 
+func (x *Process) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_NORMAL
+}
+
+func (x *Process) GetCorrelation() string {
+	if x != nil {
+		return x.Correlation
+	}
+	return ""
+}
+
 // Record a track to disk
 type RecordStart struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Sfu string        `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu address
-	Sid string        `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"` // session id
-	Tid string        `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"` // track id
-	Cfg *RecordConfig `protobuf:"bytes,4,opt,name=cfg,proto3" json:"cfg,omitempty"` // everything we need to configure on the recording
+	Sfu         string        `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu address
+	Sid         string        `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"` // session id
+	Tid         string        `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"` // track id
+	Cfg         *RecordConfig `protobuf:"bytes,4,opt,name=cfg,proto3" json:"cfg,omitempty"` // everything we need to configure on the recording
+	Priority    Priority      `protobuf:"varint,5,opt,name=priority,proto3,enum=avp.Priority" json:"priority,omitempty"`
+	Correlation string        `protobuf:"bytes,6,opt,name=correlation,proto3" json:"correlation,omitempty"` // traces the recording through logs, stats, events and sidecars
 }
 
 func (x *RecordStart) Reset() {
@@ -455,15 +798,30 @@ func (x *RecordStart) GetCfg() *RecordConfig {
 	return nil
 }
 
+func (x *RecordStart) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_NORMAL
+}
+
+func (x *RecordStart) GetCorrelation() string {
+	if x != nil {
+		return x.Correlation
+	}
+	return ""
+}
+
 // Stop recording a track. Ensures recording gets flushed to disk.
 type RecordStop struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Sfu string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu address
-	Sid string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"` // session id
-	Tid string `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"` // track id
+	Sfu         string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu address
+	Sid         string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"` // session id
+	Tid         string `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"` // track id
+	Correlation string `protobuf:"bytes,4,opt,name=correlation,proto3" json:"correlation,omitempty"`
 }
 
 func (x *RecordStop) Reset() {
@@ -519,20 +877,27 @@ func (x *RecordStop) GetTid() string {
 	return ""
 }
 
-type RecordConfig struct {
+func (x *RecordStop) GetCorrelation() string {
+	if x != nil {
+		return x.Correlation
+	}
+	return ""
+}
+
+// Pause recording a track. Media is dropped until resumed.
+type RecordPause struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Format     RecordConfig_Format `protobuf:"varint,1,opt,name=format,proto3,enum=avp.RecordConfig_Format" json:"format,omitempty"`
-	Filename   string              `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"` // full path to write recording to
-	Audio      RecordConfig_Audio  `protobuf:"varint,3,opt,name=audio,proto3,enum=avp.RecordConfig_Audio" json:"audio,omitempty"`
-	Video      RecordConfig_Video  `protobuf:"varint,4,opt,name=video,proto3,enum=avp.RecordConfig_Video" json:"video,omitempty"`
-	Buffersize uint64              `protobuf:"varint,5,opt,name=buffersize,proto3" json:"buffersize,omitempty"` // in bytes
+	Sfu         string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu address
+	Sid         string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"` // session id
+	Tid         string `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"` // track id
+	Correlation string `protobuf:"bytes,4,opt,name=correlation,proto3" json:"correlation,omitempty"`
 }
 
-func (x *RecordConfig) Reset() {
-	*x = RecordConfig{}
+func (x *RecordPause) Reset() {
+	*x = RecordPause{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -540,13 +905,13 @@ func (x *RecordConfig) Reset() {
 	}
 }
 
-func (x *RecordConfig) String() string {
+func (x *RecordPause) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecordConfig) ProtoMessage() {}
+func (*RecordPause) ProtoMessage() {}
 
-func (x *RecordConfig) ProtoReflect() protoreflect.Message {
+func (x *RecordPause) ProtoReflect() protoreflect.Message {
 	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -558,153 +923,4129 @@ func (x *RecordConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecordConfig.ProtoReflect.Descriptor instead.
-func (*RecordConfig) Descriptor() ([]byte, []int) {
+// Deprecated: Use RecordPause.ProtoReflect.Descriptor instead.
+func (*RecordPause) Descriptor() ([]byte, []int) {
 	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *RecordConfig) GetFormat() RecordConfig_Format {
-	if x != nil {
-		return x.Format
-	}
-	return RecordConfig_WEBM
-}
-
-func (x *RecordConfig) GetFilename() string {
+func (x *RecordPause) GetSfu() string {
 	if x != nil {
-		return x.Filename
+		return x.Sfu
 	}
 	return ""
 }
 
-func (x *RecordConfig) GetAudio() RecordConfig_Audio {
+func (x *RecordPause) GetSid() string {
 	if x != nil {
-		return x.Audio
+		return x.Sid
 	}
-	return RecordConfig_AUDIO_OFF
+	return ""
 }
 
-func (x *RecordConfig) GetVideo() RecordConfig_Video {
+func (x *RecordPause) GetTid() string {
 	if x != nil {
-		return x.Video
+		return x.Tid
 	}
-	return RecordConfig_VIDEO_OFF
+	return ""
 }
 
-func (x *RecordConfig) GetBuffersize() uint64 {
+func (x *RecordPause) GetCorrelation() string {
 	if x != nil {
-		return x.Buffersize
+		return x.Correlation
 	}
-	return 0
+	return ""
 }
 
-var File_cmd_signal_grpc_proto_avp_proto protoreflect.FileDescriptor
+// Resume a paused recording from the next keyframe
+type RecordResume struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_cmd_signal_grpc_proto_avp_proto_rawDesc = []byte{
-	0x0a, 0x1f, 0x63, 0x6d, 0x64, 0x2f, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70,
-	0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x76, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x03, 0x61, 0x76, 0x70, 0x22, 0xad, 0x01, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x61,
-	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63,
-	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x76, 0x70, 0x2e,
-	0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x48, 0x00, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65,
-	0x73, 0x73, 0x12, 0x34, 0x0a, 0x0b, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65,
-	0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x65, 0x63,
-	0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x31, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61,
-	0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x48, 0x00, 0x52,
-	0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x42, 0x09, 0x0a, 0x07, 0x70,
-	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x0d, 0x0a, 0x0b, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x7b, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
-	0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73,
-	0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x70, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x69, 0x64, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x22, 0x68, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72,
-	0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x23, 0x0a, 0x03, 0x63, 0x66, 0x67, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72,
-	0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x03, 0x63, 0x66, 0x67, 0x22, 0x42, 0x0a, 0x0a,
-	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66,
-	0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03,
-	0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10,
-	0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64,
-	0x22, 0xce, 0x02, 0x0a, 0x0c, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x12, 0x30, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x18, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72,
-	0x6d, 0x61, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12,
-	0x2d, 0x0a, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17,
-	0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x52, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x12, 0x2d,
-	0x0a, 0x05, 0x76, 0x69, 0x64, 0x65, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e,
-	0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x2e, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x52, 0x05, 0x76, 0x69, 0x64, 0x65, 0x6f, 0x12, 0x1e, 0x0a,
-	0x0a, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x0a, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x12, 0x0a,
-	0x06, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x45, 0x42, 0x4d, 0x10,
-	0x00, 0x22, 0x38, 0x0a, 0x05, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x12, 0x0d, 0x0a, 0x09, 0x41, 0x55,
-	0x44, 0x49, 0x4f, 0x5f, 0x4f, 0x46, 0x46, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x55, 0x44,
-	0x49, 0x4f, 0x5f, 0x4d, 0x4f, 0x4e, 0x4f, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x41, 0x55, 0x44,
-	0x49, 0x4f, 0x5f, 0x53, 0x54, 0x45, 0x52, 0x45, 0x4f, 0x10, 0x02, 0x22, 0x24, 0x0a, 0x05, 0x56,
-	0x69, 0x64, 0x65, 0x6f, 0x12, 0x0d, 0x0a, 0x09, 0x56, 0x49, 0x44, 0x45, 0x4f, 0x5f, 0x4f, 0x46,
-	0x46, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x56, 0x49, 0x44, 0x45, 0x4f, 0x5f, 0x4f, 0x4e, 0x10,
-	0x01, 0x32, 0x3b, 0x0a, 0x03, 0x41, 0x56, 0x50, 0x12, 0x34, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e,
-	0x61, 0x6c, 0x12, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x53, 0x69, 0x67,
-	0x6e, 0x61, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2f,
-	0x5a, 0x2d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x69, 0x6f,
-	0x6e, 0x2f, 0x69, 0x6f, 0x6e, 0x2d, 0x61, 0x76, 0x70, 0x2f, 0x63, 0x6d, 0x64, 0x2f, 0x73, 0x69,
-	0x67, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	Sfu         string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"` // media sfu address
+	Sid         string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"` // session id
+	Tid         string `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"` // track id
+	Correlation string `protobuf:"bytes,4,opt,name=correlation,proto3" json:"correlation,omitempty"`
 }
 
-var (
-	file_cmd_signal_grpc_proto_avp_proto_rawDescOnce sync.Once
-	file_cmd_signal_grpc_proto_avp_proto_rawDescData = file_cmd_signal_grpc_proto_avp_proto_rawDesc
-)
-
-func file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP() []byte {
-	file_cmd_signal_grpc_proto_avp_proto_rawDescOnce.Do(func() {
-		file_cmd_signal_grpc_proto_avp_proto_rawDescData = protoimpl.X.CompressGZIP(file_cmd_signal_grpc_proto_avp_proto_rawDescData)
-	})
-	return file_cmd_signal_grpc_proto_avp_proto_rawDescData
+func (x *RecordResume) Reset() {
+	*x = RecordResume{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-var file_cmd_signal_grpc_proto_avp_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_cmd_signal_grpc_proto_avp_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_cmd_signal_grpc_proto_avp_proto_goTypes = []interface{}{
-	(RecordConfig_Format)(0), // 0: avp.RecordConfig.Format
-	(RecordConfig_Audio)(0),  // 1: avp.RecordConfig.Audio
-	(RecordConfig_Video)(0),  // 2: avp.RecordConfig.Video
-	(*SignalRequest)(nil),    // 3: avp.SignalRequest
-	(*SignalReply)(nil),      // 4: avp.SignalReply
-	(*Process)(nil),          // 5: avp.Process
-	(*RecordStart)(nil),      // 6: avp.RecordStart
-	(*RecordStop)(nil),       // 7: avp.RecordStop
-	(*RecordConfig)(nil),     // 8: avp.RecordConfig
+func (x *RecordResume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordResume) ProtoMessage() {}
+
+func (x *RecordResume) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordResume.ProtoReflect.Descriptor instead.
+func (*RecordResume) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RecordResume) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *RecordResume) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *RecordResume) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *RecordResume) GetCorrelation() string {
+	if x != nil {
+		return x.Correlation
+	}
+	return ""
+}
+
+type RecordConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format     RecordConfig_Format `protobuf:"varint,1,opt,name=format,proto3,enum=avp.RecordConfig_Format" json:"format,omitempty"`
+	Filename   string              `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"` // full path to write recording to
+	Audio      RecordConfig_Audio  `protobuf:"varint,3,opt,name=audio,proto3,enum=avp.RecordConfig_Audio" json:"audio,omitempty"`
+	Video      RecordConfig_Video  `protobuf:"varint,4,opt,name=video,proto3,enum=avp.RecordConfig_Video" json:"video,omitempty"`
+	Buffersize uint64              `protobuf:"varint,5,opt,name=buffersize,proto3" json:"buffersize,omitempty"` // in bytes
+	Align      bool                `protobuf:"varint,6,opt,name=align,proto3" json:"align,omitempty"`           // align to the session's first recording, padding the start
+	Redundancy *Redundancy         `protobuf:"bytes,7,opt,name=redundancy,proto3" json:"redundancy,omitempty"`
+	Pausegap   int64               `protobuf:"varint,8,opt,name=pausegap,proto3" json:"pausegap,omitempty"`     // milliseconds left in WebM files where paused, none by default
+	Segment    uint32              `protobuf:"varint,9,opt,name=segment,proto3" json:"segment,omitempty"`       // seconds after which WebM files are split at a keyframe, as name-0.webm, name-1.webm...
+	Encryption *HlsEncryption      `protobuf:"bytes,10,opt,name=encryption,proto3" json:"encryption,omitempty"` // of HLS segments, none if unset
+	Live       bool                `protobuf:"varint,11,opt,name=live,proto3" json:"live,omitempty"`            // DASH or CMAF manifest updated while recording, otherwise written at the end for VOD
+	Key        []byte              `protobuf:"bytes,12,opt,name=key,proto3" json:"key,omitempty"`               // AES key of 16, 24 or 32 bytes the recording's files, segments and sidecars are encrypted with at rest, see package seal; none if unset
+	Metadata   bool                `protobuf:"varint,13,opt,name=metadata,proto3" json:"metadata,omitempty"`    // JSON sidecar of WebM, MKV, MP4 and segmented recordings, as filename.json: session, tracks, codecs, resolutions, wall-clock start and end, dropped frames
+	Rotate     bool                `protobuf:"varint,14,opt,name=rotate,proto3" json:"rotate,omitempty"`        // start a new WebM file, as with segment, where the video changes resolution, so each file's track has the size of its frames; always on when video is recorded
+	Gaps       string              `protobuf:"bytes,15,opt,name=gaps,proto3" json:"gaps,omitempty"`             // keep, fill, clamp or rebase jumps in WebM timestamps, from lost packets or the publisher pausing; the node's recording gaps if unset
+	Maxgap     int64               `protobuf:"varint,16,opt,name=maxgap,proto3" json:"maxgap,omitempty"`        // milliseconds of the longest jump that isn't a gap, the node's recording maxgap if 0
+	Room       bool                `protobuf:"varint,17,opt,name=room,proto3" json:"room,omitempty"`            // record every track of the session into one WebM file with a track each, added as participants join; tid is ignored, and a RecordStop without one ends it
+	Composite  bool                `protobuf:"varint,18,opt,name=composite,proto3" json:"composite,omitempty"`  // record the session's video composed into one WebM track, laid out as SetLayout says with the active speaker highlighted, and its audio mixed into another unless AUDIO_OFF; tid is ignored, and a RecordStop without one ends it; needs a node built with libvpx, and libopus for audio
+}
+
+func (x *RecordConfig) Reset() {
+	*x = RecordConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordConfig) ProtoMessage() {}
+
+func (x *RecordConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordConfig.ProtoReflect.Descriptor instead.
+func (*RecordConfig) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RecordConfig) GetFormat() RecordConfig_Format {
+	if x != nil {
+		return x.Format
+	}
+	return RecordConfig_WEBM
+}
+
+func (x *RecordConfig) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *RecordConfig) GetAudio() RecordConfig_Audio {
+	if x != nil {
+		return x.Audio
+	}
+	return RecordConfig_AUDIO_OFF
+}
+
+func (x *RecordConfig) GetVideo() RecordConfig_Video {
+	if x != nil {
+		return x.Video
+	}
+	return RecordConfig_VIDEO_OFF
+}
+
+func (x *RecordConfig) GetBuffersize() uint64 {
+	if x != nil {
+		return x.Buffersize
+	}
+	return 0
+}
+
+func (x *RecordConfig) GetAlign() bool {
+	if x != nil {
+		return x.Align
+	}
+	return false
+}
+
+func (x *RecordConfig) GetRedundancy() *Redundancy {
+	if x != nil {
+		return x.Redundancy
+	}
+	return nil
+}
+
+func (x *RecordConfig) GetPausegap() int64 {
+	if x != nil {
+		return x.Pausegap
+	}
+	return 0
+}
+
+func (x *RecordConfig) GetSegment() uint32 {
+	if x != nil {
+		return x.Segment
+	}
+	return 0
+}
+
+func (x *RecordConfig) GetEncryption() *HlsEncryption {
+	if x != nil {
+		return x.Encryption
+	}
+	return nil
+}
+
+func (x *RecordConfig) GetLive() bool {
+	if x != nil {
+		return x.Live
+	}
+	return false
+}
+
+func (x *RecordConfig) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RecordConfig) GetMetadata() bool {
+	if x != nil {
+		return x.Metadata
+	}
+	return false
+}
+
+func (x *RecordConfig) GetRotate() bool {
+	if x != nil {
+		return x.Rotate
+	}
+	return false
+}
+
+func (x *RecordConfig) GetGaps() string {
+	if x != nil {
+		return x.Gaps
+	}
+	return ""
+}
+
+func (x *RecordConfig) GetMaxgap() int64 {
+	if x != nil {
+		return x.Maxgap
+	}
+	return 0
+}
+
+func (x *RecordConfig) GetRoom() bool {
+	if x != nil {
+		return x.Room
+	}
+	return false
+}
+
+func (x *RecordConfig) GetComposite() bool {
+	if x != nil {
+		return x.Composite
+	}
+	return false
+}
+
+// Encrypt HLS segments with AES-128. Keys are written next to the playlist
+// as key-0.key, key-1.key..., for the server at keyuri to deliver.
+type HlsEncryption struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keyuri string `protobuf:"bytes,1,opt,name=keyuri,proto3" json:"keyuri,omitempty"`  // template of key uris, {key} replaced by the key's number
+	Rotate uint32 `protobuf:"varint,2,opt,name=rotate,proto3" json:"rotate,omitempty"` // segments encrypted with each key, all if 0
+}
+
+func (x *HlsEncryption) Reset() {
+	*x = HlsEncryption{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HlsEncryption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HlsEncryption) ProtoMessage() {}
+
+func (x *HlsEncryption) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HlsEncryption.ProtoReflect.Descriptor instead.
+func (*HlsEncryption) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *HlsEncryption) GetKeyuri() string {
+	if x != nil {
+		return x.Keyuri
+	}
+	return ""
+}
+
+func (x *HlsEncryption) GetRotate() uint32 {
+	if x != nil {
+		return x.Rotate
+	}
+	return 0
+}
+
+// Record the same track on two nodes. Backups record next to the
+// primary's file, and on finishing are deleted if the primary completed.
+type Redundancy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Role Redundancy_Role `protobuf:"varint,1,opt,name=role,proto3,enum=avp.Redundancy_Role" json:"role,omitempty"`
+	Peer string          `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"` // grpc address of the primary, for backups
+}
+
+func (x *Redundancy) Reset() {
+	*x = Redundancy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Redundancy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Redundancy) ProtoMessage() {}
+
+func (x *Redundancy) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Redundancy.ProtoReflect.Descriptor instead.
+func (*Redundancy) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Redundancy) GetRole() Redundancy_Role {
+	if x != nil {
+		return x.Role
+	}
+	return Redundancy_PRIMARY
+}
+
+func (x *Redundancy) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+// Export a finished recording to a single file
+type ExportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Manifest string `protobuf:"bytes,1,opt,name=manifest,proto3" json:"manifest,omitempty"` // path of the recording manifest
+	Output   string `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`     // path of the file to write, extension selects the format
+	Webhook  string `protobuf:"bytes,3,opt,name=webhook,proto3" json:"webhook,omitempty"`   // url receiving a POST with the job when it finishes
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExportRequest) GetManifest() string {
+	if x != nil {
+		return x.Manifest
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetWebhook() string {
+	if x != nil {
+		return x.Webhook
+	}
+	return ""
+}
+
+type ExportQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // export job id
+}
+
+func (x *ExportQuery) Reset() {
+	*x = ExportQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportQuery) ProtoMessage() {}
+
+func (x *ExportQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportQuery.ProtoReflect.Descriptor instead.
+func (*ExportQuery) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ExportQuery) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ExportJob struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string          `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	State    ExportJob_State `protobuf:"varint,2,opt,name=state,proto3,enum=avp.ExportJob_State" json:"state,omitempty"`
+	Progress float64         `protobuf:"fixed64,3,opt,name=progress,proto3" json:"progress,omitempty"` // 0 to 1
+	Error    string          `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Output   string          `protobuf:"bytes,5,opt,name=output,proto3" json:"output,omitempty"`
+}
+
+func (x *ExportJob) Reset() {
+	*x = ExportJob{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportJob) ProtoMessage() {}
+
+func (x *ExportJob) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportJob.ProtoReflect.Descriptor instead.
+func (*ExportJob) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ExportJob) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExportJob) GetState() ExportJob_State {
+	if x != nil {
+		return x.State
+	}
+	return ExportJob_QUEUED
+}
+
+func (x *ExportJob) GetProgress() float64 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *ExportJob) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ExportJob) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{13}
+}
+
+type StatsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pressure   float64         `protobuf:"fixed64,1,opt,name=pressure,proto3" json:"pressure,omitempty"` // how backed up element queues are, 0 to 1
+	Elements   []*ElementStats `protobuf:"bytes,2,rep,name=elements,proto3" json:"elements,omitempty"`
+	Recordings []*Recording    `protobuf:"bytes,3,rep,name=recordings,proto3" json:"recordings,omitempty"`
+	Retries    []*RetryStats   `protobuf:"bytes,4,rep,name=retries,proto3" json:"retries,omitempty"`
+}
+
+func (x *StatsReply) Reset() {
+	*x = StatsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsReply) ProtoMessage() {}
+
+func (x *StatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsReply.ProtoReflect.Descriptor instead.
+func (*StatsReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StatsReply) GetPressure() float64 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+func (x *StatsReply) GetElements() []*ElementStats {
+	if x != nil {
+		return x.Elements
+	}
+	return nil
+}
+
+func (x *StatsReply) GetRecordings() []*Recording {
+	if x != nil {
+		return x.Recordings
+	}
+	return nil
+}
+
+func (x *StatsReply) GetRetries() []*RetryStats {
+	if x != nil {
+		return x.Retries
+	}
+	return nil
+}
+
+// Resource usage of an element on one track
+type ElementStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pid         string   `protobuf:"bytes,1,opt,name=pid,proto3" json:"pid,omitempty"`         // process id, or track id for recordings
+	Sid         string   `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`         // session id
+	Tid         string   `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"`         // track id
+	Element     string   `protobuf:"bytes,4,opt,name=element,proto3" json:"element,omitempty"` // element type
+	Priority    Priority `protobuf:"varint,5,opt,name=priority,proto3,enum=avp.Priority" json:"priority,omitempty"`
+	Samples     uint64   `protobuf:"varint,6,opt,name=samples,proto3" json:"samples,omitempty"`
+	Dropped     uint64   `protobuf:"varint,7,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Busy        int64    `protobuf:"varint,8,opt,name=busy,proto3" json:"busy,omitempty"`           // wall clock nanoseconds in Write, not CPU time
+	Allocated   uint64   `protobuf:"varint,9,opt,name=allocated,proto3" json:"allocated,omitempty"` // estimated bytes allocated in Write
+	Correlation string   `protobuf:"bytes,10,opt,name=correlation,proto3" json:"correlation,omitempty"`
+	Cpu         int64    `protobuf:"varint,11,opt,name=cpu,proto3" json:"cpu,omitempty"` // estimated CPU nanoseconds in Write
+}
+
+func (x *ElementStats) Reset() {
+	*x = ElementStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ElementStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ElementStats) ProtoMessage() {}
+
+func (x *ElementStats) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ElementStats.ProtoReflect.Descriptor instead.
+func (*ElementStats) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ElementStats) GetPid() string {
+	if x != nil {
+		return x.Pid
+	}
+	return ""
+}
+
+func (x *ElementStats) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *ElementStats) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *ElementStats) GetElement() string {
+	if x != nil {
+		return x.Element
+	}
+	return ""
+}
+
+func (x *ElementStats) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_NORMAL
+}
+
+func (x *ElementStats) GetSamples() uint64 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *ElementStats) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *ElementStats) GetBusy() int64 {
+	if x != nil {
+		return x.Busy
+	}
+	return 0
+}
+
+func (x *ElementStats) GetAllocated() uint64 {
+	if x != nil {
+		return x.Allocated
+	}
+	return 0
+}
+
+func (x *ElementStats) GetCorrelation() string {
+	if x != nil {
+		return x.Correlation
+	}
+	return ""
+}
+
+func (x *ElementStats) GetCpu() int64 {
+	if x != nil {
+		return x.Cpu
+	}
+	return 0
+}
+
+// Retries of a network sink, e.g. a storage backend or webhook
+type RetryStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Calls    uint64 `protobuf:"varint,2,opt,name=calls,proto3" json:"calls,omitempty"`
+	Retries  uint64 `protobuf:"varint,3,opt,name=retries,proto3" json:"retries,omitempty"`   // calls to the sink after the first
+	Failures uint64 `protobuf:"varint,4,opt,name=failures,proto3" json:"failures,omitempty"` // calls that failed after retrying
+	Rejected uint64 `protobuf:"varint,5,opt,name=rejected,proto3" json:"rejected,omitempty"` // calls refused while the circuit was open
+	Open     bool   `protobuf:"varint,6,opt,name=open,proto3" json:"open,omitempty"`         // whether the circuit is open
+}
+
+func (x *RetryStats) Reset() {
+	*x = RetryStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetryStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryStats) ProtoMessage() {}
+
+func (x *RetryStats) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryStats.ProtoReflect.Descriptor instead.
+func (*RetryStats) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RetryStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RetryStats) GetCalls() uint64 {
+	if x != nil {
+		return x.Calls
+	}
+	return 0
+}
+
+func (x *RetryStats) GetRetries() uint64 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *RetryStats) GetFailures() uint64 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *RetryStats) GetRejected() uint64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *RetryStats) GetOpen() bool {
+	if x != nil {
+		return x.Open
+	}
+	return false
+}
+
+type RecordingsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sid string `protobuf:"bytes,1,opt,name=sid,proto3" json:"sid,omitempty"` // only list recordings of this session if set
+}
+
+func (x *RecordingsRequest) Reset() {
+	*x = RecordingsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordingsRequest) ProtoMessage() {}
+
+func (x *RecordingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordingsRequest.ProtoReflect.Descriptor instead.
+func (*RecordingsRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RecordingsRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+type RecordingsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Recordings []*Recording `protobuf:"bytes,1,rep,name=recordings,proto3" json:"recordings,omitempty"`
+}
+
+func (x *RecordingsReply) Reset() {
+	*x = RecordingsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordingsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordingsReply) ProtoMessage() {}
+
+func (x *RecordingsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordingsReply.ProtoReflect.Descriptor instead.
+func (*RecordingsReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RecordingsReply) GetRecordings() []*Recording {
+	if x != nil {
+		return x.Recordings
+	}
+	return nil
+}
+
+// Recording in progress or recently finished
+type Recording struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string          `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Sid         string          `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`   // session id
+	Tid         string          `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"`   // track id
+	Name        string          `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"` // file or object name
+	State       Recording_State `protobuf:"varint,5,opt,name=state,proto3,enum=avp.Recording_State" json:"state,omitempty"`
+	Error       string          `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Created     int64           `protobuf:"varint,7,opt,name=created,proto3" json:"created,omitempty"` // unix milliseconds
+	Updated     int64           `protobuf:"varint,8,opt,name=updated,proto3" json:"updated,omitempty"` // unix milliseconds, of the last state change
+	Url         string          `protobuf:"bytes,9,opt,name=url,proto3" json:"url,omitempty"`          // signed download url once complete, see storage urlexpiry
+	Correlation string          `protobuf:"bytes,10,opt,name=correlation,proto3" json:"correlation,omitempty"`
+}
+
+func (x *Recording) Reset() {
+	*x = Recording{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Recording) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recording) ProtoMessage() {}
+
+func (x *Recording) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recording.ProtoReflect.Descriptor instead.
+func (*Recording) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Recording) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Recording) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *Recording) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *Recording) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Recording) GetState() Recording_State {
+	if x != nil {
+		return x.State
+	}
+	return Recording_PENDING
+}
+
+func (x *Recording) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Recording) GetCreated() int64 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *Recording) GetUpdated() int64 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *Recording) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Recording) GetCorrelation() string {
+	if x != nil {
+		return x.Correlation
+	}
+	return ""
+}
+
+// Check a pipeline could start on this node without starting it. Set
+// process or record as they would be signaled.
+type ValidateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Process *Process     `protobuf:"bytes,1,opt,name=process,proto3" json:"process,omitempty"`
+	Record  *RecordStart `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ValidateRequest) GetProcess() *Process {
+	if x != nil {
+		return x.Process
+	}
+	return nil
+}
+
+func (x *ValidateRequest) GetRecord() *RecordStart {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type ValidateReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid    bool               `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"` // no errors, the pipeline would start
+	Errors   []*ValidationError `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	Warnings []*ValidationError `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"` // the pipeline would start, but may not as expected
+}
+
+func (x *ValidateReply) Reset() {
+	*x = ValidateReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateReply) ProtoMessage() {}
+
+func (x *ValidateReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateReply.ProtoReflect.Descriptor instead.
+func (*ValidateReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ValidateReply) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateReply) GetErrors() []*ValidationError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ValidateReply) GetWarnings() []*ValidationError {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// Why a pipeline can't start, and what to do about it
+type ValidationError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code    ValidationError_Code `protobuf:"varint,1,opt,name=code,proto3,enum=avp.ValidationError_Code" json:"code,omitempty"`
+	Field   string               `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"` // request field at fault, e.g. "record.cfg.filename"
+	Message string               `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *ValidationError) Reset() {
+	*x = ValidationError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidationError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationError) ProtoMessage() {}
+
+func (x *ValidationError) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationError.ProtoReflect.Descriptor instead.
+func (*ValidationError) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ValidationError) GetCode() ValidationError_Code {
+	if x != nil {
+		return x.Code
+	}
+	return ValidationError_INVALID
+}
+
+func (x *ValidationError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *ValidationError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Start the same pipeline on many tracks at once, e.g. recording every
+// active room for a legal hold. Each track starts independently, failures
+// are reported without affecting the others.
+type BatchStart struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Targets []*BatchTarget `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+	Joined  bool           `protobuf:"varint,2,opt,name=joined,proto3" json:"joined,omitempty"` // also target every session joined on this node
+	// The pipeline started on each track. Its sfu, sid and tid are taken from
+	// the target, and {sid} and {tid} in the process pid or recording
+	// filename are replaced by the track's.
+	// Types that are assignable to Profile:
+	//	*BatchStart_Process
+	//	*BatchStart_Record
+	Profile isBatchStart_Profile `protobuf_oneof:"profile"`
+}
+
+func (x *BatchStart) Reset() {
+	*x = BatchStart{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchStart) ProtoMessage() {}
+
+func (x *BatchStart) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchStart.ProtoReflect.Descriptor instead.
+func (*BatchStart) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BatchStart) GetTargets() []*BatchTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *BatchStart) GetJoined() bool {
+	if x != nil {
+		return x.Joined
+	}
+	return false
+}
+
+func (m *BatchStart) GetProfile() isBatchStart_Profile {
+	if m != nil {
+		return m.Profile
+	}
+	return nil
+}
+
+func (x *BatchStart) GetProcess() *Process {
+	if x, ok := x.GetProfile().(*BatchStart_Process); ok {
+		return x.Process
+	}
+	return nil
+}
+
+func (x *BatchStart) GetRecord() *RecordStart {
+	if x, ok := x.GetProfile().(*BatchStart_Record); ok {
+		return x.Record
+	}
+	return nil
+}
+
+type isBatchStart_Profile interface {
+	isBatchStart_Profile()
+}
+
+type BatchStart_Process struct {
+	Process *Process `protobuf:"bytes,3,opt,name=process,proto3,oneof"`
+}
+
+type BatchStart_Record struct {
+	Record *RecordStart `protobuf:"bytes,4,opt,name=record,proto3,oneof"`
+}
+
+func (*BatchStart_Process) isBatchStart_Profile() {}
+
+func (*BatchStart_Record) isBatchStart_Profile() {}
+
+// Stop the pipelines of many tracks at once
+type BatchStop struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Targets []*BatchTarget `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+	Joined  bool           `protobuf:"varint,2,opt,name=joined,proto3" json:"joined,omitempty"` // also target every session joined on this node
+}
+
+func (x *BatchStop) Reset() {
+	*x = BatchStop{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchStop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchStop) ProtoMessage() {}
+
+func (x *BatchStop) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchStop.ProtoReflect.Descriptor instead.
+func (*BatchStop) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BatchStop) GetTargets() []*BatchTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *BatchStop) GetJoined() bool {
+	if x != nil {
+		return x.Joined
+	}
+	return false
+}
+
+type BatchTarget struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu  string   `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`   // media sfu address
+	Sid  string   `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`   // session id
+	Tids []string `protobuf:"bytes,3,rep,name=tids,proto3" json:"tids,omitempty"` // every track of the session that has arrived if empty
+}
+
+func (x *BatchTarget) Reset() {
+	*x = BatchTarget{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchTarget) ProtoMessage() {}
+
+func (x *BatchTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchTarget.ProtoReflect.Descriptor instead.
+func (*BatchTarget) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BatchTarget) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *BatchTarget) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *BatchTarget) GetTids() []string {
+	if x != nil {
+		return x.Tids
+	}
+	return nil
+}
+
+type BatchReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*BatchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // one per track targeted
+	Failed  uint32         `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+}
+
+func (x *BatchReply) Reset() {
+	*x = BatchReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchReply) ProtoMessage() {}
+
+func (x *BatchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchReply.ProtoReflect.Descriptor instead.
+func (*BatchReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *BatchReply) GetResults() []*BatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BatchReply) GetFailed() uint32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+type BatchResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu   string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`
+	Sid   string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Tid   string `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"`
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"` // why the track failed, empty if it succeeded
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *BatchResult) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *BatchResult) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *BatchResult) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *BatchResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Place or release the legal hold of a recording. A held recording and its
+// sidecars can't be deleted until released.
+type LegalHoldRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`     // file or object name of the recording
+	Held   bool   `protobuf:"varint,2,opt,name=held,proto3" json:"held,omitempty"`    // place the hold if set, else release it
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // e.g. the case or ticket, recorded with the hold
+}
+
+func (x *LegalHoldRequest) Reset() {
+	*x = LegalHoldRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LegalHoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LegalHoldRequest) ProtoMessage() {}
+
+func (x *LegalHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LegalHoldRequest.ProtoReflect.Descriptor instead.
+func (*LegalHoldRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *LegalHoldRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LegalHoldRequest) GetHeld() bool {
+	if x != nil {
+		return x.Held
+	}
+	return false
+}
+
+func (x *LegalHoldRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type LegalHold struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Held   bool   `protobuf:"varint,2,opt,name=held,proto3" json:"held,omitempty"`
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Since  int64  `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"` // unix milliseconds the hold was placed
+}
+
+func (x *LegalHold) Reset() {
+	*x = LegalHold{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LegalHold) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LegalHold) ProtoMessage() {}
+
+func (x *LegalHold) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LegalHold.ProtoReflect.Descriptor instead.
+func (*LegalHold) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *LegalHold) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LegalHold) GetHeld() bool {
+	if x != nil {
+		return x.Held
+	}
+	return false
+}
+
+func (x *LegalHold) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *LegalHold) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+// Delete a recording and its sidecars, e.g. for an erasure request.
+// Fails with FAILED_PRECONDITION while the recording is under legal hold.
+type DeleteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // file or object name of the recording
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *DeleteRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Deleted []string `protobuf:"bytes,1,rep,name=deleted,proto3" json:"deleted,omitempty"` // names of the files deleted
+}
+
+func (x *DeleteReply) Reset() {
+	*x = DeleteReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReply) ProtoMessage() {}
+
+func (x *DeleteReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReply.ProtoReflect.Descriptor instead.
+func (*DeleteReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DeleteReply) GetDeleted() []string {
+	if x != nil {
+		return x.Deleted
+	}
+	return nil
+}
+
+// Switch the layout of a session's composites, from their next frame
+type LayoutRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu    string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`
+	Sid    string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Layout string `protobuf:"bytes,3,opt,name=layout,proto3" json:"layout,omitempty"` // a preset, "grid" or "screenshare", or a JSON definition
+}
+
+func (x *LayoutRequest) Reset() {
+	*x = LayoutRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LayoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LayoutRequest) ProtoMessage() {}
+
+func (x *LayoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LayoutRequest.ProtoReflect.Descriptor instead.
+func (*LayoutRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *LayoutRequest) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *LayoutRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *LayoutRequest) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+type LayoutReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *LayoutReply) Reset() {
+	*x = LayoutReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LayoutReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LayoutReply) ProtoMessage() {}
+
+func (x *LayoutReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LayoutReply.ProtoReflect.Descriptor instead.
+func (*LayoutReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{33}
+}
+
+// Set the name and avatar composites of a session show on the tiles of a
+// source, a stream or track id
+type ParticipantRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu    string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`
+	Sid    string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Name   string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Avatar []byte `protobuf:"bytes,5,opt,name=avatar,proto3" json:"avatar,omitempty"`  // PNG or JPEG, shown while video is muted
+	Remove bool   `protobuf:"varint,6,opt,name=remove,proto3" json:"remove,omitempty"` // forget the participant of the source
+}
+
+func (x *ParticipantRequest) Reset() {
+	*x = ParticipantRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParticipantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParticipantRequest) ProtoMessage() {}
+
+func (x *ParticipantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParticipantRequest.ProtoReflect.Descriptor instead.
+func (*ParticipantRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ParticipantRequest) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *ParticipantRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *ParticipantRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ParticipantRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ParticipantRequest) GetAvatar() []byte {
+	if x != nil {
+		return x.Avatar
+	}
+	return nil
+}
+
+func (x *ParticipantRequest) GetRemove() bool {
+	if x != nil {
+		return x.Remove
+	}
+	return false
+}
+
+type ParticipantReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ParticipantReply) Reset() {
+	*x = ParticipantReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParticipantReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParticipantReply) ProtoMessage() {}
+
+func (x *ParticipantReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParticipantReply.ProtoReflect.Descriptor instead.
+func (*ParticipantReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{35}
+}
+
+// Set the background and safe margins of a session's composites, for
+// branded recordings
+type CanvasRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu        string   `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`
+	Sid        string   `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Background string   `protobuf:"bytes,3,opt,name=background,proto3" json:"background,omitempty"` // colour, "#rrggbb", black if empty
+	Images     [][]byte `protobuf:"bytes,4,rep,name=images,proto3" json:"images,omitempty"`         // PNG or JPEG drawn over the colour, several cycle as a slate
+	Interval   int64    `protobuf:"varint,5,opt,name=interval,proto3" json:"interval,omitempty"`    // milliseconds each slate image is shown
+	Margins    *Margins `protobuf:"bytes,6,opt,name=margins,proto3" json:"margins,omitempty"`
+}
+
+func (x *CanvasRequest) Reset() {
+	*x = CanvasRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CanvasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CanvasRequest) ProtoMessage() {}
+
+func (x *CanvasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CanvasRequest.ProtoReflect.Descriptor instead.
+func (*CanvasRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *CanvasRequest) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *CanvasRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *CanvasRequest) GetBackground() string {
+	if x != nil {
+		return x.Background
+	}
+	return ""
+}
+
+func (x *CanvasRequest) GetImages() [][]byte {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+func (x *CanvasRequest) GetInterval() int64 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *CanvasRequest) GetMargins() *Margins {
+	if x != nil {
+		return x.Margins
+	}
+	return nil
+}
+
+// Fractions of the canvas kept clear of tiles at each edge
+type Margins struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Top    float64 `protobuf:"fixed64,1,opt,name=top,proto3" json:"top,omitempty"`
+	Right  float64 `protobuf:"fixed64,2,opt,name=right,proto3" json:"right,omitempty"`
+	Bottom float64 `protobuf:"fixed64,3,opt,name=bottom,proto3" json:"bottom,omitempty"`
+	Left   float64 `protobuf:"fixed64,4,opt,name=left,proto3" json:"left,omitempty"`
+}
+
+func (x *Margins) Reset() {
+	*x = Margins{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Margins) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Margins) ProtoMessage() {}
+
+func (x *Margins) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Margins.ProtoReflect.Descriptor instead.
+func (*Margins) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *Margins) GetTop() float64 {
+	if x != nil {
+		return x.Top
+	}
+	return 0
+}
+
+func (x *Margins) GetRight() float64 {
+	if x != nil {
+		return x.Right
+	}
+	return 0
+}
+
+func (x *Margins) GetBottom() float64 {
+	if x != nil {
+		return x.Bottom
+	}
+	return 0
+}
+
+func (x *Margins) GetLeft() float64 {
+	if x != nil {
+		return x.Left
+	}
+	return 0
+}
+
+type CanvasReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CanvasReply) Reset() {
+	*x = CanvasReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CanvasReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CanvasReply) ProtoMessage() {}
+
+func (x *CanvasReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CanvasReply.ProtoReflect.Descriptor instead.
+func (*CanvasReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{38}
+}
+
+// Route the audio of an interpreter to a channel of a session, taking over
+// from the channel's last interpreter
+type InterpreterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu      string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`
+	Sid      string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Channel  string `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`   // name, e.g. of a DASH audio track
+	Source   string `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`     // audio track id of the interpreter
+	Language string `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"` // RFC 5646 tag of the channel
+	Remove   bool   `protobuf:"varint,6,opt,name=remove,proto3" json:"remove,omitempty"`    // drop the channel
+}
+
+func (x *InterpreterRequest) Reset() {
+	*x = InterpreterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InterpreterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterpreterRequest) ProtoMessage() {}
+
+func (x *InterpreterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterpreterRequest.ProtoReflect.Descriptor instead.
+func (*InterpreterRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *InterpreterRequest) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *InterpreterRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *InterpreterRequest) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *InterpreterRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *InterpreterRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *InterpreterRequest) GetRemove() bool {
+	if x != nil {
+		return x.Remove
+	}
+	return false
+}
+
+type InterpreterReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *InterpreterReply) Reset() {
+	*x = InterpreterReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InterpreterReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterpreterReply) ProtoMessage() {}
+
+func (x *InterpreterReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterpreterReply.ProtoReflect.Descriptor instead.
+func (*InterpreterReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{40}
+}
+
+// Stream the decoded audio of a track, e.g. to speech to text
+type PCMRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu      string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`
+	Sid      string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Tid      string `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"`            // Opus audio track id
+	Rate     int32  `protobuf:"varint,4,opt,name=rate,proto3" json:"rate,omitempty"`         // 16000 or 48000, 48000 if unset
+	Channels int32  `protobuf:"varint,5,opt,name=channels,proto3" json:"channels,omitempty"` // 1 or 2, 1 if unset
+	Buffer   int32  `protobuf:"varint,6,opt,name=buffer,proto3" json:"buffer,omitempty"`     // chunks held while the client is behind, 50 if unset
+}
+
+func (x *PCMRequest) Reset() {
+	*x = PCMRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PCMRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PCMRequest) ProtoMessage() {}
+
+func (x *PCMRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PCMRequest.ProtoReflect.Descriptor instead.
+func (*PCMRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *PCMRequest) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *PCMRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *PCMRequest) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *PCMRequest) GetRate() int32 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *PCMRequest) GetChannels() int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+func (x *PCMRequest) GetBuffer() int32 {
+	if x != nil {
+		return x.Buffer
+	}
+	return 0
+}
+
+// A frame of 16 bit little endian interleaved PCM
+type PCMChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data        []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Rate        int32  `protobuf:"varint,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	Channels    int32  `protobuf:"varint,3,opt,name=channels,proto3" json:"channels,omitempty"`
+	Time        int64  `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`               // milliseconds from the first audio of the track
+	CaptureTime int64  `protobuf:"varint,5,opt,name=captureTime,proto3" json:"captureTime,omitempty"` // unix milliseconds, 0 if unknown
+	Dropped     uint64 `protobuf:"varint,6,opt,name=dropped,proto3" json:"dropped,omitempty"`         // chunks dropped so far while the client was behind
+}
+
+func (x *PCMChunk) Reset() {
+	*x = PCMChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PCMChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PCMChunk) ProtoMessage() {}
+
+func (x *PCMChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PCMChunk.ProtoReflect.Descriptor instead.
+func (*PCMChunk) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *PCMChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *PCMChunk) GetRate() int32 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *PCMChunk) GetChannels() int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+func (x *PCMChunk) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *PCMChunk) GetCaptureTime() int64 {
+	if x != nil {
+		return x.CaptureTime
+	}
+	return 0
+}
+
+func (x *PCMChunk) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+// Opt a track out of recording, or back in. While opted out its samples
+// reach no pipeline, whatever they record.
+type OptOutRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sfu    string `protobuf:"bytes,1,opt,name=sfu,proto3" json:"sfu,omitempty"`
+	Sid    string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Tid    string `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"`        // may not have arrived yet
+	OptOut bool   `protobuf:"varint,4,opt,name=optOut,proto3" json:"optOut,omitempty"` // false opts back in
+}
+
+func (x *OptOutRequest) Reset() {
+	*x = OptOutRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptOutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptOutRequest) ProtoMessage() {}
+
+func (x *OptOutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptOutRequest.ProtoReflect.Descriptor instead.
+func (*OptOutRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *OptOutRequest) GetSfu() string {
+	if x != nil {
+		return x.Sfu
+	}
+	return ""
+}
+
+func (x *OptOutRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *OptOutRequest) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *OptOutRequest) GetOptOut() bool {
+	if x != nil {
+		return x.OptOut
+	}
+	return false
+}
+
+type OptOutReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OptedOut bool `protobuf:"varint,1,opt,name=optedOut,proto3" json:"optedOut,omitempty"` // by the application or the sender's header extension
+}
+
+func (x *OptOutReply) Reset() {
+	*x = OptOutReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptOutReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptOutReply) ProtoMessage() {}
+
+func (x *OptOutReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptOutReply.ProtoReflect.Descriptor instead.
+func (*OptOutReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *OptOutReply) GetOptedOut() bool {
+	if x != nil {
+		return x.OptedOut
+	}
+	return false
+}
+
+// Search the archive of recordings this node has produced, see recording
+// index. Recordings matching every field set are returned.
+type SearchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sid        string `protobuf:"bytes,1,opt,name=sid,proto3" json:"sid,omitempty"`                // of this session
+	Tid        string `protobuf:"bytes,2,opt,name=tid,proto3" json:"tid,omitempty"`                // of this track
+	From       int64  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`             // unix milliseconds, spanning some of the time from
+	To         int64  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`                 // unix milliseconds, spanning some of the time until
+	Name       string `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`              // names containing it
+	Transcript bool   `protobuf:"varint,6,opt,name=transcript,proto3" json:"transcript,omitempty"` // only recordings with a transcript
+	Limit      uint32 `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`           // of the latest recordings matching, all if 0
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[45]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[45]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *SearchRequest) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetTranscript() bool {
+	if x != nil {
+		return x.Transcript
+	}
+	return false
+}
+
+func (x *SearchRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Recordings []*ArchivedRecording `protobuf:"bytes,1,rep,name=recordings,proto3" json:"recordings,omitempty"` // oldest first
+}
+
+func (x *SearchReply) Reset() {
+	*x = SearchReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[46]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchReply) ProtoMessage() {}
+
+func (x *SearchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[46]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchReply.ProtoReflect.Descriptor instead.
+func (*SearchReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *SearchReply) GetRecordings() []*ArchivedRecording {
+	if x != nil {
+		return x.Recordings
+	}
+	return nil
+}
+
+// Recording of the archive
+type ArchivedRecording struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string          `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Sid         string          `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Tid         string          `protobuf:"bytes,3,opt,name=tid,proto3" json:"tid,omitempty"`
+	Name        string          `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	State       Recording_State `protobuf:"varint,5,opt,name=state,proto3,enum=avp.Recording_State" json:"state,omitempty"`
+	Start       int64           `protobuf:"varint,6,opt,name=start,proto3" json:"start,omitempty"`             // unix milliseconds, of the first media
+	End         int64           `protobuf:"varint,7,opt,name=end,proto3" json:"end,omitempty"`                 // unix milliseconds, of the last media
+	Duration    int64           `protobuf:"varint,8,opt,name=duration,proto3" json:"duration,omitempty"`       // milliseconds
+	Locations   []string        `protobuf:"bytes,9,rep,name=locations,proto3" json:"locations,omitempty"`      // files or objects holding the media
+	Transcripts []string        `protobuf:"bytes,10,rep,name=transcripts,proto3" json:"transcripts,omitempty"` // files of transcripts
+}
+
+func (x *ArchivedRecording) Reset() {
+	*x = ArchivedRecording{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[47]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArchivedRecording) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchivedRecording) ProtoMessage() {}
+
+func (x *ArchivedRecording) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[47]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchivedRecording.ProtoReflect.Descriptor instead.
+func (*ArchivedRecording) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ArchivedRecording) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ArchivedRecording) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *ArchivedRecording) GetTid() string {
+	if x != nil {
+		return x.Tid
+	}
+	return ""
+}
+
+func (x *ArchivedRecording) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArchivedRecording) GetState() Recording_State {
+	if x != nil {
+		return x.State
+	}
+	return Recording_PENDING
+}
+
+func (x *ArchivedRecording) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *ArchivedRecording) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *ArchivedRecording) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *ArchivedRecording) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *ArchivedRecording) GetTranscripts() []string {
+	if x != nil {
+		return x.Transcripts
+	}
+	return nil
+}
+
+// Transcript of a recording of the archive, indexed for SearchTranscripts.
+// One of the same name replaces the transcript indexed before.
+type TranscriptRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`     // of the recording
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"` // file of the transcript
+	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"` // WebVTT or SRT
+}
+
+func (x *TranscriptRequest) Reset() {
+	*x = TranscriptRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[48]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TranscriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscriptRequest) ProtoMessage() {}
+
+func (x *TranscriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[48]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscriptRequest.ProtoReflect.Descriptor instead.
+func (*TranscriptRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *TranscriptRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TranscriptRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TranscriptRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type TranscriptReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cues uint32 `protobuf:"varint,1,opt,name=cues,proto3" json:"cues,omitempty"` // indexed
+}
+
+func (x *TranscriptReply) Reset() {
+	*x = TranscriptReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[49]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TranscriptReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscriptReply) ProtoMessage() {}
+
+func (x *TranscriptReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[49]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscriptReply.ProtoReflect.Descriptor instead.
+func (*TranscriptReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *TranscriptReply) GetCues() uint32 {
+	if x != nil {
+		return x.Cues
+	}
+	return 0
+}
+
+// Search the transcripts of the archive for cues containing every word of
+// text, in any order and case.
+type TranscriptSearch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text  string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Sid   string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`      // of recordings of this session
+	Limit uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // of the latest cues matching, all if 0
+}
+
+func (x *TranscriptSearch) Reset() {
+	*x = TranscriptSearch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[50]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TranscriptSearch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscriptSearch) ProtoMessage() {}
+
+func (x *TranscriptSearch) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[50]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscriptSearch.ProtoReflect.Descriptor instead.
+func (*TranscriptSearch) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *TranscriptSearch) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TranscriptSearch) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *TranscriptSearch) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type TranscriptHits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hits []*TranscriptHit `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"` // oldest first
+}
+
+func (x *TranscriptHits) Reset() {
+	*x = TranscriptHits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[51]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TranscriptHits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscriptHits) ProtoMessage() {}
+
+func (x *TranscriptHits) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[51]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscriptHits.ProtoReflect.Descriptor instead.
+func (*TranscriptHits) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *TranscriptHits) GetHits() []*TranscriptHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+// Cue of a transcript matching a search
+type TranscriptHit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // of the recording
+	Sid   string `protobuf:"bytes,2,opt,name=sid,proto3" json:"sid,omitempty"`
+	Name  string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`    // file of the transcript
+	Start int64  `protobuf:"varint,4,opt,name=start,proto3" json:"start,omitempty"` // milliseconds from the start of the recording
+	End   int64  `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`     // milliseconds from the start of the recording
+	At    int64  `protobuf:"varint,6,opt,name=at,proto3" json:"at,omitempty"`       // unix milliseconds, of the start of the cue
+	Text  string `protobuf:"bytes,7,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *TranscriptHit) Reset() {
+	*x = TranscriptHit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[52]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TranscriptHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscriptHit) ProtoMessage() {}
+
+func (x *TranscriptHit) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[52]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscriptHit.ProtoReflect.Descriptor instead.
+func (*TranscriptHit) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *TranscriptHit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TranscriptHit) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+func (x *TranscriptHit) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TranscriptHit) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *TranscriptHit) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *TranscriptHit) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+func (x *TranscriptHit) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// Media file on the node run through the ingest pipelines, as files of
+// the watched directory are.
+type IngestRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // WebM, Matroska, IVF or Ogg Opus
+}
+
+func (x *IngestRequest) Reset() {
+	*x = IngestRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[53]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IngestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestRequest) ProtoMessage() {}
+
+func (x *IngestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[53]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestRequest.ProtoReflect.Descriptor instead.
+func (*IngestRequest) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *IngestRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type IngestReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`              // session of its tracks in pipelines, and of its archive entry
+	Tracks   []string `protobuf:"bytes,2,rep,name=tracks,proto3" json:"tracks,omitempty"`      // ids
+	Duration int64    `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"` // milliseconds
+}
+
+func (x *IngestReply) Reset() {
+	*x = IngestReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[54]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IngestReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestReply) ProtoMessage() {}
+
+func (x *IngestReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_signal_grpc_proto_avp_proto_msgTypes[54]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestReply.ProtoReflect.Descriptor instead.
+func (*IngestReply) Descriptor() ([]byte, []int) {
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *IngestReply) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *IngestReply) GetTracks() []string {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+func (x *IngestReply) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+var File_cmd_signal_grpc_proto_avp_proto protoreflect.FileDescriptor
+
+var file_cmd_signal_grpc_proto_avp_proto_rawDesc = []byte{
+	0x0a, 0x1f, 0x63, 0x6d, 0x64, 0x2f, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70,
+	0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x76, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x03, 0x61, 0x76, 0x70, 0x22, 0x9c, 0x02, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x76, 0x70, 0x2e,
+	0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x48, 0x00, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x12, 0x34, 0x0a, 0x0b, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x31, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x48, 0x00, 0x52,
+	0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x12, 0x34, 0x0a, 0x0b, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x50, 0x61, 0x75, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x50, 0x61, 0x75,
+	0x73, 0x65, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x50, 0x61, 0x75, 0x73,
+	0x65, 0x12, 0x37, 0x0a, 0x0c, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6d,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x72, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x0d, 0x0a, 0x0b, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0xc8, 0x01, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73,
+	0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x70, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x69, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x29, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x50, 0x72, 0x69, 0x6f, 0x72,
+	0x69, 0x74, 0x79, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x20, 0x0a,
+	0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0xb5, 0x01, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66,
+	0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x23, 0x0a, 0x03, 0x63, 0x66, 0x67, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x03, 0x63, 0x66, 0x67, 0x12, 0x29, 0x0a, 0x08, 0x70, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x08, 0x70, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x72, 0x72,
+	0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x64, 0x0a, 0x0a, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x53, 0x74, 0x6f, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63,
+	0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x65, 0x0a,
+	0x0b, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x50, 0x61, 0x75, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10,
+	0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64,
+	0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74,
+	0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x22, 0x66, 0x0a, 0x0c, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65,
+	0x73, 0x75, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f,
+	0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xe6, 0x05, 0x0a,
+	0x0c, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x30, 0x0a,
+	0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e,
+	0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x61,
+	0x75, 0x64, 0x69, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x61, 0x76, 0x70,
+	0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x41, 0x75,
+	0x64, 0x69, 0x6f, 0x52, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x12, 0x2d, 0x0a, 0x05, 0x76, 0x69,
+	0x64, 0x65, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x61, 0x76, 0x70, 0x2e,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x56, 0x69, 0x64,
+	0x65, 0x6f, 0x52, 0x05, 0x76, 0x69, 0x64, 0x65, 0x6f, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x75, 0x66,
+	0x66, 0x65, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x62,
+	0x75, 0x66, 0x66, 0x65, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69,
+	0x67, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x12,
+	0x2f, 0x0a, 0x0a, 0x72, 0x65, 0x64, 0x75, 0x6e, 0x64, 0x61, 0x6e, 0x63, 0x79, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x64, 0x75, 0x6e, 0x64,
+	0x61, 0x6e, 0x63, 0x79, 0x52, 0x0a, 0x72, 0x65, 0x64, 0x75, 0x6e, 0x64, 0x61, 0x6e, 0x63, 0x79,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x75, 0x73, 0x65, 0x67, 0x61, 0x70, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x70, 0x61, 0x75, 0x73, 0x65, 0x67, 0x61, 0x70, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x73,
+	0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x32, 0x0a, 0x0a, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x76, 0x70,
+	0x2e, 0x48, 0x6c, 0x73, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a,
+	0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69,
+	0x76, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6c, 0x69, 0x76, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x6f,
+	0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x67, 0x61, 0x70, 0x73, 0x18, 0x0f, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x67, 0x61, 0x70, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61, 0x78, 0x67,
+	0x61, 0x70, 0x18, 0x10, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x67, 0x61, 0x70,
+	0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04,
+	0x72, 0x6f, 0x6f, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x65, 0x22, 0x41, 0x0a, 0x06, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x08, 0x0a, 0x04,
+	0x57, 0x45, 0x42, 0x4d, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x4d, 0x50, 0x34, 0x10, 0x01, 0x12,
+	0x07, 0x0a, 0x03, 0x4d, 0x4b, 0x56, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x48, 0x4c, 0x53, 0x10,
+	0x03, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x41, 0x53, 0x48, 0x10, 0x04, 0x12, 0x08, 0x0a, 0x04, 0x43,
+	0x4d, 0x41, 0x46, 0x10, 0x05, 0x22, 0x38, 0x0a, 0x05, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x12, 0x0d,
+	0x0a, 0x09, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x4f, 0x46, 0x46, 0x10, 0x00, 0x12, 0x0e, 0x0a,
+	0x0a, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x4d, 0x4f, 0x4e, 0x4f, 0x10, 0x01, 0x12, 0x10, 0x0a,
+	0x0c, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x53, 0x54, 0x45, 0x52, 0x45, 0x4f, 0x10, 0x02, 0x22,
+	0x24, 0x0a, 0x05, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x12, 0x0d, 0x0a, 0x09, 0x56, 0x49, 0x44, 0x45,
+	0x4f, 0x5f, 0x4f, 0x46, 0x46, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x56, 0x49, 0x44, 0x45, 0x4f,
+	0x5f, 0x4f, 0x4e, 0x10, 0x01, 0x22, 0x3f, 0x0a, 0x0d, 0x48, 0x6c, 0x73, 0x45, 0x6e, 0x63, 0x72,
+	0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6b, 0x65, 0x79, 0x75, 0x72, 0x69,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6b, 0x65, 0x79, 0x75, 0x72, 0x69, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06,
+	0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x22, 0x6b, 0x0a, 0x0a, 0x52, 0x65, 0x64, 0x75, 0x6e, 0x64,
+	0x61, 0x6e, 0x63, 0x79, 0x12, 0x28, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x64, 0x75, 0x6e, 0x64, 0x61,
+	0x6e, 0x63, 0x79, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x65, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x65,
+	0x65, 0x72, 0x22, 0x1f, 0x0a, 0x04, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x52,
+	0x49, 0x4d, 0x41, 0x52, 0x59, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x42, 0x41, 0x43, 0x4b, 0x55,
+	0x50, 0x10, 0x01, 0x22, 0x5d, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x65, 0x62, 0x68,
+	0x6f, 0x6f, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x77, 0x65, 0x62, 0x68, 0x6f,
+	0x6f, 0x6b, 0x22, 0x1d, 0x0a, 0x0b, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x22, 0xd7, 0x01, 0x0a, 0x09, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4a, 0x6f, 0x62, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x2a, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14,
+	0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4a, 0x6f, 0x62, 0x2e, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x70,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x16, 0x0a,
+	0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x44, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0a,
+	0x0a, 0x06, 0x51, 0x55, 0x45, 0x55, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x55,
+	0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x4f, 0x4e, 0x45, 0x10,
+	0x02, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x12, 0x0c, 0x0a,
+	0x08, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x22, 0x0e, 0x0a, 0x0c, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xb2, 0x01, 0x0a, 0x0a,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x12, 0x2d, 0x0a, 0x08, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x08, 0x65, 0x6c, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x76, 0x70, 0x2e,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x29, 0x0a, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x74,
+	0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x22, 0xa3, 0x02, 0x0a, 0x0c, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x70, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x29, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69,
+	0x74, 0x79, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65,
+	0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x62, 0x75, 0x73, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x62, 0x75, 0x73, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x70, 0x75, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x03, 0x63, 0x70, 0x75, 0x22, 0x9c, 0x01, 0x0a, 0x0a, 0x52, 0x65, 0x74, 0x72, 0x79,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x6c,
+	0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x61, 0x6c, 0x6c, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x61, 0x69,
+	0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x66, 0x61, 0x69,
+	0x6c, 0x75, 0x72, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x04, 0x6f, 0x70, 0x65, 0x6e, 0x22, 0x25, 0x0a, 0x11, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x22, 0x41, 0x0a, 0x0f,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x2e, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x69, 0x6e, 0x67, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x22,
+	0x82, 0x03, 0x0a, 0x09, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a,
+	0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12,
+	0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75,
+	0x72, 0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x20, 0x0a,
+	0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0x82, 0x01, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x45, 0x4e,
+	0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x57, 0x41, 0x49, 0x54, 0x49, 0x4e,
+	0x47, 0x5f, 0x46, 0x4f, 0x52, 0x5f, 0x4b, 0x45, 0x59, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x10, 0x01,
+	0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x43, 0x4f, 0x52, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12,
+	0x0a, 0x0a, 0x06, 0x50, 0x41, 0x55, 0x53, 0x45, 0x44, 0x10, 0x03, 0x12, 0x0e, 0x0a, 0x0a, 0x46,
+	0x49, 0x4e, 0x41, 0x4c, 0x49, 0x5a, 0x49, 0x4e, 0x47, 0x10, 0x04, 0x12, 0x0d, 0x0a, 0x09, 0x55,
+	0x50, 0x4c, 0x4f, 0x41, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x05, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x4f,
+	0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x06, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c,
+	0x45, 0x44, 0x10, 0x07, 0x22, 0x63, 0x0a, 0x0f, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x50,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x12,
+	0x28, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72,
+	0x74, 0x52, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x22, 0x85, 0x01, 0x0a, 0x0d, 0x56, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x12, 0x2c, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12,
+	0x30, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67,
+	0x73, 0x22, 0xad, 0x02, 0x0a, 0x0f, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2d, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x04,
+	0x63, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x22, 0xba, 0x01, 0x0a, 0x04, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x0b, 0x0a,
+	0x07, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x4c,
+	0x45, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10,
+	0x01, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x4c, 0x45, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x44, 0x49, 0x53,
+	0x41, 0x42, 0x4c, 0x45, 0x44, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f, 0x44, 0x45, 0x43,
+	0x5f, 0x55, 0x4e, 0x53, 0x55, 0x50, 0x50, 0x4f, 0x52, 0x54, 0x45, 0x44, 0x10, 0x03, 0x12, 0x0f,
+	0x0a, 0x0b, 0x44, 0x45, 0x53, 0x54, 0x49, 0x4e, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x04, 0x12,
+	0x17, 0x0a, 0x13, 0x53, 0x54, 0x4f, 0x52, 0x41, 0x47, 0x45, 0x5f, 0x55, 0x4e, 0x52, 0x45, 0x41,
+	0x43, 0x48, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x05, 0x12, 0x14, 0x0a, 0x10, 0x50, 0x45, 0x45, 0x52,
+	0x5f, 0x55, 0x4e, 0x52, 0x45, 0x41, 0x43, 0x48, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x06, 0x12, 0x0e,
+	0x0a, 0x0a, 0x4f, 0x56, 0x45, 0x52, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x44, 0x10, 0x07, 0x12, 0x11,
+	0x0a, 0x0d, 0x54, 0x52, 0x41, 0x43, 0x4b, 0x5f, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10,
+	0x08, 0x22, 0xb1, 0x01, 0x0a, 0x0a, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x12, 0x2a, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x6a, 0x6f, 0x69, 0x6e, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6a, 0x6f,
+	0x69, 0x6e, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x50, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x48, 0x00, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x12, 0x2a,
+	0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x22, 0x4f, 0x0a, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74,
+	0x6f, 0x70, 0x12, 0x2a, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x6a, 0x6f, 0x69, 0x6e, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x6a, 0x6f, 0x69, 0x6e, 0x65, 0x64, 0x22, 0x45, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x64,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x69, 0x64, 0x73, 0x22, 0x50, 0x0a,
+	0x0a, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2a, 0x0a, 0x07, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x22,
+	0x59, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73,
+	0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x74, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x52, 0x0a, 0x10, 0x4c, 0x65,
+	0x67, 0x61, 0x6c, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x65, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x04, 0x68, 0x65, 0x6c, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x61,
+	0x0a, 0x09, 0x4c, 0x65, 0x67, 0x61, 0x6c, 0x48, 0x6f, 0x6c, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x68, 0x65, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x68,
+	0x65, 0x6c, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x69, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63,
+	0x65, 0x22, 0x23, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x27, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22,
+	0x4b, 0x0a, 0x0d, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73,
+	0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x73, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x22, 0x0d, 0x0a, 0x0b,
+	0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x94, 0x01, 0x0a, 0x12,
+	0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x76, 0x61, 0x74, 0x61, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x61, 0x76, 0x61, 0x74, 0x61, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x22, 0x12, 0x0a, 0x10, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e,
+	0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0xaf, 0x01, 0x0a, 0x0d, 0x43, 0x61, 0x6e, 0x76, 0x61,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66, 0x75, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x1e, 0x0a, 0x0a,
+	0x62, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x62, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x69, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
+	0x12, 0x26, 0x0a, 0x07, 0x6d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x4d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x73, 0x52,
+	0x07, 0x6d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x73, 0x22, 0x5d, 0x0a, 0x07, 0x4d, 0x61, 0x72, 0x67,
+	0x69, 0x6e, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x6f, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x03, 0x74, 0x6f, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x72, 0x69, 0x67, 0x68, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x62,
+	0x6f, 0x74, 0x74, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x62, 0x6f, 0x74,
+	0x74, 0x6f, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x65, 0x66, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x04, 0x6c, 0x65, 0x66, 0x74, 0x22, 0x0d, 0x0a, 0x0b, 0x43, 0x61, 0x6e, 0x76, 0x61,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x9e, 0x01, 0x0a, 0x12, 0x49, 0x6e, 0x74, 0x65, 0x72,
+	0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a,
+	0x03, 0x73, 0x66, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12,
+	0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x22, 0x12, 0x0a, 0x10, 0x49, 0x6e, 0x74, 0x65, 0x72,
+	0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x8a, 0x01, 0x0a, 0x0a,
+	0x50, 0x43, 0x4d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66,
+	0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10,
+	0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x72, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73,
+	0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x06, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x22, 0x9e, 0x01, 0x0a, 0x08, 0x50, 0x43, 0x4d,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x20, 0x0a,
+	0x0b, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x22, 0x5d, 0x0a, 0x0d, 0x4f, 0x70, 0x74,
+	0x4f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x66,
+	0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x66, 0x75, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10,
+	0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64,
+	0x12, 0x16, 0x0a, 0x06, 0x6f, 0x70, 0x74, 0x4f, 0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x6f, 0x70, 0x74, 0x4f, 0x75, 0x74, 0x22, 0x29, 0x0a, 0x0b, 0x4f, 0x70, 0x74, 0x4f,
+	0x75, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x70, 0x74, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6f, 0x70, 0x74, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x22, 0xa1, 0x01, 0x0a, 0x0d, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a,
+	0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x45, 0x0a, 0x0b, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x36, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x76, 0x70,
+	0x2e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x67, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x22, 0x8b,
+	0x02, 0x0a, 0x11, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x69, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x05,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x76,
+	0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x65, 0x6e, 0x64,
+	0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x73, 0x22, 0x4b, 0x0a, 0x11,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x25, 0x0a, 0x0f, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x63, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x75, 0x65, 0x73,
+	0x22, 0x4e, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x53, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x22, 0x38, 0x0a, 0x0e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x48, 0x69,
+	0x74, 0x73, 0x12, 0x26, 0x0a, 0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x48, 0x69, 0x74, 0x52, 0x04, 0x68, 0x69, 0x74, 0x73, 0x22, 0x91, 0x01, 0x0a, 0x0d, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x48, 0x69, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x69, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x61, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65,
+	0x78, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x22, 0x23,
+	0x0a, 0x0d, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70,
+	0x61, 0x74, 0x68, 0x22, 0x51, 0x0a, 0x0b, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x06, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2a, 0x29, 0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69,
+	0x74, 0x79, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x4f, 0x52, 0x4d, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x07,
+	0x0a, 0x03, 0x4c, 0x4f, 0x57, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x49, 0x47, 0x48, 0x10,
+	0x02, 0x32, 0xa2, 0x09, 0x0a, 0x03, 0x41, 0x56, 0x50, 0x12, 0x34, 0x0a, 0x06, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x6c, 0x12, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12,
+	0x33, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x12,
+	0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4a,
+	0x6f, 0x62, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x12, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x1a, 0x0e, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74,
+	0x4a, 0x6f, 0x62, 0x22, 0x00, 0x12, 0x32, 0x0a, 0x0c, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x0e, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x45, 0x78,
+	0x70, 0x6f, 0x72, 0x74, 0x4a, 0x6f, 0x62, 0x22, 0x00, 0x12, 0x2d, 0x0a, 0x05, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x12, 0x11, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3c, 0x0a, 0x0a, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x16, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
+	0x2e, 0x61, 0x76, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3e, 0x0a, 0x10, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x65, 0x50, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x14, 0x2e, 0x61, 0x76, 0x70,
+	0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x30, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x72, 0x74, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x0f, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x1a, 0x0f, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x09, 0x53, 0x74, 0x6f, 0x70,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x0e, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x53, 0x74, 0x6f, 0x70, 0x1a, 0x0f, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x0c, 0x53, 0x65, 0x74, 0x4c,
+	0x65, 0x67, 0x61, 0x6c, 0x48, 0x6f, 0x6c, 0x64, 0x12, 0x15, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x4c,
+	0x65, 0x67, 0x61, 0x6c, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0e, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x4c, 0x65, 0x67, 0x61, 0x6c, 0x48, 0x6f, 0x6c, 0x64, 0x22,
+	0x00, 0x12, 0x39, 0x0a, 0x0f, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x33, 0x0a, 0x09,
+	0x53, 0x65, 0x74, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e,
+	0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e,
+	0x61, 0x76, 0x70, 0x2e, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x42, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70,
+	0x61, 0x6e, 0x74, 0x12, 0x17, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x33, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x43, 0x61, 0x6e, 0x76,
+	0x61, 0x73, 0x12, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x43, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x43, 0x61, 0x6e,
+	0x76, 0x61, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x0e, 0x53, 0x65,
+	0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x2f,
+	0x0a, 0x09, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x43, 0x4d, 0x12, 0x0f, 0x2e, 0x61, 0x76,
+	0x70, 0x2e, 0x50, 0x43, 0x4d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x50, 0x43, 0x4d, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x00, 0x30, 0x01, 0x12,
+	0x33, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x4f, 0x70, 0x74, 0x4f, 0x75, 0x74, 0x12, 0x12, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x4f, 0x70, 0x74, 0x4f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x4f, 0x70, 0x74, 0x4f, 0x75, 0x74, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x10, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x12, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x61,
+	0x76, 0x70, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x3f, 0x0a, 0x0d, 0x41, 0x64, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x12, 0x16, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x61, 0x76, 0x70, 0x2e,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x41, 0x0a, 0x11, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x73, 0x12, 0x15, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x1a, 0x13, 0x2e,
+	0x61, 0x76, 0x70, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x48, 0x69,
+	0x74, 0x73, 0x22, 0x00, 0x12, 0x30, 0x0a, 0x06, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x2e, 0x61, 0x76, 0x70, 0x2e, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x10, 0x2e, 0x61, 0x76, 0x70, 0x2e, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x2f, 0x5a, 0x2d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x69, 0x6f, 0x6e, 0x2f, 0x69, 0x6f, 0x6e, 0x2d, 0x61, 0x76,
+	0x70, 0x2f, 0x63, 0x6d, 0x64, 0x2f, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70,
+	0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_cmd_signal_grpc_proto_avp_proto_rawDescOnce sync.Once
+	file_cmd_signal_grpc_proto_avp_proto_rawDescData = file_cmd_signal_grpc_proto_avp_proto_rawDesc
+)
+
+func file_cmd_signal_grpc_proto_avp_proto_rawDescGZIP() []byte {
+	file_cmd_signal_grpc_proto_avp_proto_rawDescOnce.Do(func() {
+		file_cmd_signal_grpc_proto_avp_proto_rawDescData = protoimpl.X.CompressGZIP(file_cmd_signal_grpc_proto_avp_proto_rawDescData)
+	})
+	return file_cmd_signal_grpc_proto_avp_proto_rawDescData
+}
+
+var file_cmd_signal_grpc_proto_avp_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_cmd_signal_grpc_proto_avp_proto_msgTypes = make([]protoimpl.MessageInfo, 55)
+var file_cmd_signal_grpc_proto_avp_proto_goTypes = []interface{}{
+	(Priority)(0),              // 0: avp.Priority
+	(RecordConfig_Format)(0),   // 1: avp.RecordConfig.Format
+	(RecordConfig_Audio)(0),    // 2: avp.RecordConfig.Audio
+	(RecordConfig_Video)(0),    // 3: avp.RecordConfig.Video
+	(Redundancy_Role)(0),       // 4: avp.Redundancy.Role
+	(ExportJob_State)(0),       // 5: avp.ExportJob.State
+	(Recording_State)(0),       // 6: avp.Recording.State
+	(ValidationError_Code)(0),  // 7: avp.ValidationError.Code
+	(*SignalRequest)(nil),      // 8: avp.SignalRequest
+	(*SignalReply)(nil),        // 9: avp.SignalReply
+	(*Process)(nil),            // 10: avp.Process
+	(*RecordStart)(nil),        // 11: avp.RecordStart
+	(*RecordStop)(nil),         // 12: avp.RecordStop
+	(*RecordPause)(nil),        // 13: avp.RecordPause
+	(*RecordResume)(nil),       // 14: avp.RecordResume
+	(*RecordConfig)(nil),       // 15: avp.RecordConfig
+	(*HlsEncryption)(nil),      // 16: avp.HlsEncryption
+	(*Redundancy)(nil),         // 17: avp.Redundancy
+	(*ExportRequest)(nil),      // 18: avp.ExportRequest
+	(*ExportQuery)(nil),        // 19: avp.ExportQuery
+	(*ExportJob)(nil),          // 20: avp.ExportJob
+	(*StatsRequest)(nil),       // 21: avp.StatsRequest
+	(*StatsReply)(nil),         // 22: avp.StatsReply
+	(*ElementStats)(nil),       // 23: avp.ElementStats
+	(*RetryStats)(nil),         // 24: avp.RetryStats
+	(*RecordingsRequest)(nil),  // 25: avp.RecordingsRequest
+	(*RecordingsReply)(nil),    // 26: avp.RecordingsReply
+	(*Recording)(nil),          // 27: avp.Recording
+	(*ValidateRequest)(nil),    // 28: avp.ValidateRequest
+	(*ValidateReply)(nil),      // 29: avp.ValidateReply
+	(*ValidationError)(nil),    // 30: avp.ValidationError
+	(*BatchStart)(nil),         // 31: avp.BatchStart
+	(*BatchStop)(nil),          // 32: avp.BatchStop
+	(*BatchTarget)(nil),        // 33: avp.BatchTarget
+	(*BatchReply)(nil),         // 34: avp.BatchReply
+	(*BatchResult)(nil),        // 35: avp.BatchResult
+	(*LegalHoldRequest)(nil),   // 36: avp.LegalHoldRequest
+	(*LegalHold)(nil),          // 37: avp.LegalHold
+	(*DeleteRequest)(nil),      // 38: avp.DeleteRequest
+	(*DeleteReply)(nil),        // 39: avp.DeleteReply
+	(*LayoutRequest)(nil),      // 40: avp.LayoutRequest
+	(*LayoutReply)(nil),        // 41: avp.LayoutReply
+	(*ParticipantRequest)(nil), // 42: avp.ParticipantRequest
+	(*ParticipantReply)(nil),   // 43: avp.ParticipantReply
+	(*CanvasRequest)(nil),      // 44: avp.CanvasRequest
+	(*Margins)(nil),            // 45: avp.Margins
+	(*CanvasReply)(nil),        // 46: avp.CanvasReply
+	(*InterpreterRequest)(nil), // 47: avp.InterpreterRequest
+	(*InterpreterReply)(nil),   // 48: avp.InterpreterReply
+	(*PCMRequest)(nil),         // 49: avp.PCMRequest
+	(*PCMChunk)(nil),           // 50: avp.PCMChunk
+	(*OptOutRequest)(nil),      // 51: avp.OptOutRequest
+	(*OptOutReply)(nil),        // 52: avp.OptOutReply
+	(*SearchRequest)(nil),      // 53: avp.SearchRequest
+	(*SearchReply)(nil),        // 54: avp.SearchReply
+	(*ArchivedRecording)(nil),  // 55: avp.ArchivedRecording
+	(*TranscriptRequest)(nil),  // 56: avp.TranscriptRequest
+	(*TranscriptReply)(nil),    // 57: avp.TranscriptReply
+	(*TranscriptSearch)(nil),   // 58: avp.TranscriptSearch
+	(*TranscriptHits)(nil),     // 59: avp.TranscriptHits
+	(*TranscriptHit)(nil),      // 60: avp.TranscriptHit
+	(*IngestRequest)(nil),      // 61: avp.IngestRequest
+	(*IngestReply)(nil),        // 62: avp.IngestReply
 }
 var file_cmd_signal_grpc_proto_avp_proto_depIdxs = []int32{
-	5, // 0: avp.SignalRequest.process:type_name -> avp.Process
-	6, // 1: avp.SignalRequest.recordStart:type_name -> avp.RecordStart
-	7, // 2: avp.SignalRequest.recordStop:type_name -> avp.RecordStop
-	8, // 3: avp.RecordStart.cfg:type_name -> avp.RecordConfig
-	0, // 4: avp.RecordConfig.format:type_name -> avp.RecordConfig.Format
-	1, // 5: avp.RecordConfig.audio:type_name -> avp.RecordConfig.Audio
-	2, // 6: avp.RecordConfig.video:type_name -> avp.RecordConfig.Video
-	3, // 7: avp.AVP.Signal:input_type -> avp.SignalRequest
-	4, // 8: avp.AVP.Signal:output_type -> avp.SignalReply
-	8, // [8:9] is the sub-list for method output_type
-	7, // [7:8] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	10, // 0: avp.SignalRequest.process:type_name -> avp.Process
+	11, // 1: avp.SignalRequest.recordStart:type_name -> avp.RecordStart
+	12, // 2: avp.SignalRequest.recordStop:type_name -> avp.RecordStop
+	13, // 3: avp.SignalRequest.recordPause:type_name -> avp.RecordPause
+	14, // 4: avp.SignalRequest.recordResume:type_name -> avp.RecordResume
+	0,  // 5: avp.Process.priority:type_name -> avp.Priority
+	15, // 6: avp.RecordStart.cfg:type_name -> avp.RecordConfig
+	0,  // 7: avp.RecordStart.priority:type_name -> avp.Priority
+	1,  // 8: avp.RecordConfig.format:type_name -> avp.RecordConfig.Format
+	2,  // 9: avp.RecordConfig.audio:type_name -> avp.RecordConfig.Audio
+	3,  // 10: avp.RecordConfig.video:type_name -> avp.RecordConfig.Video
+	17, // 11: avp.RecordConfig.redundancy:type_name -> avp.Redundancy
+	16, // 12: avp.RecordConfig.encryption:type_name -> avp.HlsEncryption
+	4,  // 13: avp.Redundancy.role:type_name -> avp.Redundancy.Role
+	5,  // 14: avp.ExportJob.state:type_name -> avp.ExportJob.State
+	23, // 15: avp.StatsReply.elements:type_name -> avp.ElementStats
+	27, // 16: avp.StatsReply.recordings:type_name -> avp.Recording
+	24, // 17: avp.StatsReply.retries:type_name -> avp.RetryStats
+	0,  // 18: avp.ElementStats.priority:type_name -> avp.Priority
+	27, // 19: avp.RecordingsReply.recordings:type_name -> avp.Recording
+	6,  // 20: avp.Recording.state:type_name -> avp.Recording.State
+	10, // 21: avp.ValidateRequest.process:type_name -> avp.Process
+	11, // 22: avp.ValidateRequest.record:type_name -> avp.RecordStart
+	30, // 23: avp.ValidateReply.errors:type_name -> avp.ValidationError
+	30, // 24: avp.ValidateReply.warnings:type_name -> avp.ValidationError
+	7,  // 25: avp.ValidationError.code:type_name -> avp.ValidationError.Code
+	33, // 26: avp.BatchStart.targets:type_name -> avp.BatchTarget
+	10, // 27: avp.BatchStart.process:type_name -> avp.Process
+	11, // 28: avp.BatchStart.record:type_name -> avp.RecordStart
+	33, // 29: avp.BatchStop.targets:type_name -> avp.BatchTarget
+	35, // 30: avp.BatchReply.results:type_name -> avp.BatchResult
+	45, // 31: avp.CanvasRequest.margins:type_name -> avp.Margins
+	55, // 32: avp.SearchReply.recordings:type_name -> avp.ArchivedRecording
+	6,  // 33: avp.ArchivedRecording.state:type_name -> avp.Recording.State
+	60, // 34: avp.TranscriptHits.hits:type_name -> avp.TranscriptHit
+	8,  // 35: avp.AVP.Signal:input_type -> avp.SignalRequest
+	18, // 36: avp.AVP.StartExport:input_type -> avp.ExportRequest
+	19, // 37: avp.AVP.GetExport:input_type -> avp.ExportQuery
+	19, // 38: avp.AVP.CancelExport:input_type -> avp.ExportQuery
+	21, // 39: avp.AVP.Stats:input_type -> avp.StatsRequest
+	25, // 40: avp.AVP.Recordings:input_type -> avp.RecordingsRequest
+	28, // 41: avp.AVP.ValidatePipeline:input_type -> avp.ValidateRequest
+	31, // 42: avp.AVP.StartBatch:input_type -> avp.BatchStart
+	32, // 43: avp.AVP.StopBatch:input_type -> avp.BatchStop
+	36, // 44: avp.AVP.SetLegalHold:input_type -> avp.LegalHoldRequest
+	38, // 45: avp.AVP.DeleteRecording:input_type -> avp.DeleteRequest
+	40, // 46: avp.AVP.SetLayout:input_type -> avp.LayoutRequest
+	42, // 47: avp.AVP.SetParticipant:input_type -> avp.ParticipantRequest
+	44, // 48: avp.AVP.SetCanvas:input_type -> avp.CanvasRequest
+	47, // 49: avp.AVP.SetInterpreter:input_type -> avp.InterpreterRequest
+	49, // 50: avp.AVP.StreamPCM:input_type -> avp.PCMRequest
+	51, // 51: avp.AVP.SetOptOut:input_type -> avp.OptOutRequest
+	53, // 52: avp.AVP.SearchRecordings:input_type -> avp.SearchRequest
+	56, // 53: avp.AVP.AddTranscript:input_type -> avp.TranscriptRequest
+	58, // 54: avp.AVP.SearchTranscripts:input_type -> avp.TranscriptSearch
+	61, // 55: avp.AVP.Ingest:input_type -> avp.IngestRequest
+	9,  // 56: avp.AVP.Signal:output_type -> avp.SignalReply
+	20, // 57: avp.AVP.StartExport:output_type -> avp.ExportJob
+	20, // 58: avp.AVP.GetExport:output_type -> avp.ExportJob
+	20, // 59: avp.AVP.CancelExport:output_type -> avp.ExportJob
+	22, // 60: avp.AVP.Stats:output_type -> avp.StatsReply
+	26, // 61: avp.AVP.Recordings:output_type -> avp.RecordingsReply
+	29, // 62: avp.AVP.ValidatePipeline:output_type -> avp.ValidateReply
+	34, // 63: avp.AVP.StartBatch:output_type -> avp.BatchReply
+	34, // 64: avp.AVP.StopBatch:output_type -> avp.BatchReply
+	37, // 65: avp.AVP.SetLegalHold:output_type -> avp.LegalHold
+	39, // 66: avp.AVP.DeleteRecording:output_type -> avp.DeleteReply
+	41, // 67: avp.AVP.SetLayout:output_type -> avp.LayoutReply
+	43, // 68: avp.AVP.SetParticipant:output_type -> avp.ParticipantReply
+	46, // 69: avp.AVP.SetCanvas:output_type -> avp.CanvasReply
+	48, // 70: avp.AVP.SetInterpreter:output_type -> avp.InterpreterReply
+	50, // 71: avp.AVP.StreamPCM:output_type -> avp.PCMChunk
+	52, // 72: avp.AVP.SetOptOut:output_type -> avp.OptOutReply
+	54, // 73: avp.AVP.SearchRecordings:output_type -> avp.SearchReply
+	57, // 74: avp.AVP.AddTranscript:output_type -> avp.TranscriptReply
+	59, // 75: avp.AVP.SearchTranscripts:output_type -> avp.TranscriptHits
+	62, // 76: avp.AVP.Ingest:output_type -> avp.IngestReply
+	56, // [56:77] is the sub-list for method output_type
+	35, // [35:56] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
 }
 
 func init() { file_cmd_signal_grpc_proto_avp_proto_init() }
@@ -725,8 +5066,308 @@ func file_cmd_signal_grpc_proto_avp_proto_init() {
 				return nil
 			}
 		}
-		file_cmd_signal_grpc_proto_avp_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignalReply); i {
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignalReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Process); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordStart); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordStop); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordPause); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordResume); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HlsEncryption); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Redundancy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportJob); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ElementStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RetryStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordingsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordingsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Recording); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidationError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchStart); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchStop); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchTarget); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -737,8 +5378,8 @@ func file_cmd_signal_grpc_proto_avp_proto_init() {
 				return nil
 			}
 		}
-		file_cmd_signal_grpc_proto_avp_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Process); i {
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -749,8 +5390,8 @@ func file_cmd_signal_grpc_proto_avp_proto_init() {
 				return nil
 			}
 		}
-		file_cmd_signal_grpc_proto_avp_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RecordStart); i {
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LegalHoldRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -761,8 +5402,8 @@ func file_cmd_signal_grpc_proto_avp_proto_init() {
 				return nil
 			}
 		}
-		file_cmd_signal_grpc_proto_avp_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RecordStop); i {
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LegalHold); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -773,8 +5414,296 @@ func file_cmd_signal_grpc_proto_avp_proto_init() {
 				return nil
 			}
 		}
-		file_cmd_signal_grpc_proto_avp_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RecordConfig); i {
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LayoutRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LayoutReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParticipantRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParticipantReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CanvasRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Margins); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CanvasReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InterpreterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InterpreterReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PCMRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PCMChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OptOutRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OptOutReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[47].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArchivedRecording); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[48].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranscriptRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[49].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranscriptReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[50].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranscriptSearch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[51].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranscriptHits); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[52].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranscriptHit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[53].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngestRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cmd_signal_grpc_proto_avp_proto_msgTypes[54].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngestReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -790,14 +5719,20 @@ func file_cmd_signal_grpc_proto_avp_proto_init() {
 		(*SignalRequest_Process)(nil),
 		(*SignalRequest_RecordStart)(nil),
 		(*SignalRequest_RecordStop)(nil),
+		(*SignalRequest_RecordPause)(nil),
+		(*SignalRequest_RecordResume)(nil),
+	}
+	file_cmd_signal_grpc_proto_avp_proto_msgTypes[23].OneofWrappers = []interface{}{
+		(*BatchStart_Process)(nil),
+		(*BatchStart_Record)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_cmd_signal_grpc_proto_avp_proto_rawDesc,
-			NumEnums:      3,
-			NumMessages:   6,
+			NumEnums:      8,
+			NumMessages:   55,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,6 +19,26 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AVPClient interface {
 	Signal(ctx context.Context, opts ...grpc.CallOption) (AVP_SignalClient, error)
+	StartExport(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (*ExportJob, error)
+	GetExport(ctx context.Context, in *ExportQuery, opts ...grpc.CallOption) (*ExportJob, error)
+	CancelExport(ctx context.Context, in *ExportQuery, opts ...grpc.CallOption) (*ExportJob, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error)
+	Recordings(ctx context.Context, in *RecordingsRequest, opts ...grpc.CallOption) (*RecordingsReply, error)
+	ValidatePipeline(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateReply, error)
+	StartBatch(ctx context.Context, in *BatchStart, opts ...grpc.CallOption) (*BatchReply, error)
+	StopBatch(ctx context.Context, in *BatchStop, opts ...grpc.CallOption) (*BatchReply, error)
+	SetLegalHold(ctx context.Context, in *LegalHoldRequest, opts ...grpc.CallOption) (*LegalHold, error)
+	DeleteRecording(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error)
+	SetLayout(ctx context.Context, in *LayoutRequest, opts ...grpc.CallOption) (*LayoutReply, error)
+	SetParticipant(ctx context.Context, in *ParticipantRequest, opts ...grpc.CallOption) (*ParticipantReply, error)
+	SetCanvas(ctx context.Context, in *CanvasRequest, opts ...grpc.CallOption) (*CanvasReply, error)
+	SetInterpreter(ctx context.Context, in *InterpreterRequest, opts ...grpc.CallOption) (*InterpreterReply, error)
+	SetOptOut(ctx context.Context, in *OptOutRequest, opts ...grpc.CallOption) (*OptOutReply, error)
+	SearchRecordings(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchReply, error)
+	AddTranscript(ctx context.Context, in *TranscriptRequest, opts ...grpc.CallOption) (*TranscriptReply, error)
+	SearchTranscripts(ctx context.Context, in *TranscriptSearch, opts ...grpc.CallOption) (*TranscriptHits, error)
+	Ingest(ctx context.Context, in *IngestRequest, opts ...grpc.CallOption) (*IngestReply, error)
+	StreamPCM(ctx context.Context, in *PCMRequest, opts ...grpc.CallOption) (AVP_StreamPCMClient, error)
 }
 
 type aVPClient struct {
@@ -60,11 +80,234 @@ func (x *aVPSignalClient) Recv() (*SignalReply, error) {
 	return m, nil
 }
 
+func (c *aVPClient) StartExport(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (*ExportJob, error) {
+	out := new(ExportJob)
+	err := c.cc.Invoke(ctx, "/avp.AVP/StartExport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) GetExport(ctx context.Context, in *ExportQuery, opts ...grpc.CallOption) (*ExportJob, error) {
+	out := new(ExportJob)
+	err := c.cc.Invoke(ctx, "/avp.AVP/GetExport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) CancelExport(ctx context.Context, in *ExportQuery, opts ...grpc.CallOption) (*ExportJob, error) {
+	out := new(ExportJob)
+	err := c.cc.Invoke(ctx, "/avp.AVP/CancelExport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error) {
+	out := new(StatsReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) Recordings(ctx context.Context, in *RecordingsRequest, opts ...grpc.CallOption) (*RecordingsReply, error) {
+	out := new(RecordingsReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/Recordings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) ValidatePipeline(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateReply, error) {
+	out := new(ValidateReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/ValidatePipeline", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) StartBatch(ctx context.Context, in *BatchStart, opts ...grpc.CallOption) (*BatchReply, error) {
+	out := new(BatchReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/StartBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) StopBatch(ctx context.Context, in *BatchStop, opts ...grpc.CallOption) (*BatchReply, error) {
+	out := new(BatchReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/StopBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SetLegalHold(ctx context.Context, in *LegalHoldRequest, opts ...grpc.CallOption) (*LegalHold, error) {
+	out := new(LegalHold)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SetLegalHold", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) DeleteRecording(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error) {
+	out := new(DeleteReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/DeleteRecording", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SetLayout(ctx context.Context, in *LayoutRequest, opts ...grpc.CallOption) (*LayoutReply, error) {
+	out := new(LayoutReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SetLayout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SetParticipant(ctx context.Context, in *ParticipantRequest, opts ...grpc.CallOption) (*ParticipantReply, error) {
+	out := new(ParticipantReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SetParticipant", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SetCanvas(ctx context.Context, in *CanvasRequest, opts ...grpc.CallOption) (*CanvasReply, error) {
+	out := new(CanvasReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SetCanvas", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SetInterpreter(ctx context.Context, in *InterpreterRequest, opts ...grpc.CallOption) (*InterpreterReply, error) {
+	out := new(InterpreterReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SetInterpreter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SetOptOut(ctx context.Context, in *OptOutRequest, opts ...grpc.CallOption) (*OptOutReply, error) {
+	out := new(OptOutReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SetOptOut", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SearchRecordings(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchReply, error) {
+	out := new(SearchReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SearchRecordings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) AddTranscript(ctx context.Context, in *TranscriptRequest, opts ...grpc.CallOption) (*TranscriptReply, error) {
+	out := new(TranscriptReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/AddTranscript", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) SearchTranscripts(ctx context.Context, in *TranscriptSearch, opts ...grpc.CallOption) (*TranscriptHits, error) {
+	out := new(TranscriptHits)
+	err := c.cc.Invoke(ctx, "/avp.AVP/SearchTranscripts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) Ingest(ctx context.Context, in *IngestRequest, opts ...grpc.CallOption) (*IngestReply, error) {
+	out := new(IngestReply)
+	err := c.cc.Invoke(ctx, "/avp.AVP/Ingest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aVPClient) StreamPCM(ctx context.Context, in *PCMRequest, opts ...grpc.CallOption) (AVP_StreamPCMClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AVP_ServiceDesc.Streams[1], "/avp.AVP/StreamPCM", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aVPStreamPCMClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AVP_StreamPCMClient interface {
+	Recv() (*PCMChunk, error)
+	grpc.ClientStream
+}
+
+type aVPStreamPCMClient struct {
+	grpc.ClientStream
+}
+
+func (x *aVPStreamPCMClient) Recv() (*PCMChunk, error) {
+	m := new(PCMChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // AVPServer is the server API for AVP service.
 // All implementations must embed UnimplementedAVPServer
 // for forward compatibility
 type AVPServer interface {
 	Signal(AVP_SignalServer) error
+	StartExport(context.Context, *ExportRequest) (*ExportJob, error)
+	GetExport(context.Context, *ExportQuery) (*ExportJob, error)
+	CancelExport(context.Context, *ExportQuery) (*ExportJob, error)
+	Stats(context.Context, *StatsRequest) (*StatsReply, error)
+	Recordings(context.Context, *RecordingsRequest) (*RecordingsReply, error)
+	ValidatePipeline(context.Context, *ValidateRequest) (*ValidateReply, error)
+	StartBatch(context.Context, *BatchStart) (*BatchReply, error)
+	StopBatch(context.Context, *BatchStop) (*BatchReply, error)
+	SetLegalHold(context.Context, *LegalHoldRequest) (*LegalHold, error)
+	DeleteRecording(context.Context, *DeleteRequest) (*DeleteReply, error)
+	SetLayout(context.Context, *LayoutRequest) (*LayoutReply, error)
+	SetParticipant(context.Context, *ParticipantRequest) (*ParticipantReply, error)
+	SetCanvas(context.Context, *CanvasRequest) (*CanvasReply, error)
+	SetInterpreter(context.Context, *InterpreterRequest) (*InterpreterReply, error)
+	SetOptOut(context.Context, *OptOutRequest) (*OptOutReply, error)
+	SearchRecordings(context.Context, *SearchRequest) (*SearchReply, error)
+	AddTranscript(context.Context, *TranscriptRequest) (*TranscriptReply, error)
+	SearchTranscripts(context.Context, *TranscriptSearch) (*TranscriptHits, error)
+	Ingest(context.Context, *IngestRequest) (*IngestReply, error)
+	StreamPCM(*PCMRequest, AVP_StreamPCMServer) error
 	mustEmbedUnimplementedAVPServer()
 }
 
@@ -75,6 +318,66 @@ type UnimplementedAVPServer struct {
 func (UnimplementedAVPServer) Signal(AVP_SignalServer) error {
 	return status.Errorf(codes.Unimplemented, "method Signal not implemented")
 }
+func (UnimplementedAVPServer) StartExport(context.Context, *ExportRequest) (*ExportJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartExport not implemented")
+}
+func (UnimplementedAVPServer) GetExport(context.Context, *ExportQuery) (*ExportJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExport not implemented")
+}
+func (UnimplementedAVPServer) CancelExport(context.Context, *ExportQuery) (*ExportJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelExport not implemented")
+}
+func (UnimplementedAVPServer) Stats(context.Context, *StatsRequest) (*StatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedAVPServer) Recordings(context.Context, *RecordingsRequest) (*RecordingsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Recordings not implemented")
+}
+func (UnimplementedAVPServer) ValidatePipeline(context.Context, *ValidateRequest) (*ValidateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidatePipeline not implemented")
+}
+func (UnimplementedAVPServer) StartBatch(context.Context, *BatchStart) (*BatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartBatch not implemented")
+}
+func (UnimplementedAVPServer) StopBatch(context.Context, *BatchStop) (*BatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopBatch not implemented")
+}
+func (UnimplementedAVPServer) SetLegalHold(context.Context, *LegalHoldRequest) (*LegalHold, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLegalHold not implemented")
+}
+func (UnimplementedAVPServer) DeleteRecording(context.Context, *DeleteRequest) (*DeleteReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRecording not implemented")
+}
+func (UnimplementedAVPServer) SetLayout(context.Context, *LayoutRequest) (*LayoutReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLayout not implemented")
+}
+func (UnimplementedAVPServer) SetParticipant(context.Context, *ParticipantRequest) (*ParticipantReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetParticipant not implemented")
+}
+func (UnimplementedAVPServer) SetCanvas(context.Context, *CanvasRequest) (*CanvasReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCanvas not implemented")
+}
+func (UnimplementedAVPServer) SetInterpreter(context.Context, *InterpreterRequest) (*InterpreterReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetInterpreter not implemented")
+}
+func (UnimplementedAVPServer) SetOptOut(context.Context, *OptOutRequest) (*OptOutReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOptOut not implemented")
+}
+func (UnimplementedAVPServer) SearchRecordings(context.Context, *SearchRequest) (*SearchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchRecordings not implemented")
+}
+func (UnimplementedAVPServer) AddTranscript(context.Context, *TranscriptRequest) (*TranscriptReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTranscript not implemented")
+}
+func (UnimplementedAVPServer) SearchTranscripts(context.Context, *TranscriptSearch) (*TranscriptHits, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchTranscripts not implemented")
+}
+func (UnimplementedAVPServer) Ingest(context.Context, *IngestRequest) (*IngestReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedAVPServer) StreamPCM(*PCMRequest, AVP_StreamPCMServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPCM not implemented")
+}
 func (UnimplementedAVPServer) mustEmbedUnimplementedAVPServer() {}
 
 // UnsafeAVPServer may be embedded to opt out of forward compatibility for this service.
@@ -114,13 +417,453 @@ func (x *aVPSignalServer) Recv() (*SignalRequest, error) {
 	return m, nil
 }
 
+func _AVP_StartExport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).StartExport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/StartExport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).StartExport(ctx, req.(*ExportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_GetExport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).GetExport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/GetExport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).GetExport(ctx, req.(*ExportQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_CancelExport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).CancelExport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/CancelExport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).CancelExport(ctx, req.(*ExportQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_Recordings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).Recordings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/Recordings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).Recordings(ctx, req.(*RecordingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_ValidatePipeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).ValidatePipeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/ValidatePipeline",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).ValidatePipeline(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_StartBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchStart)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).StartBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/StartBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).StartBatch(ctx, req.(*BatchStart))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_StopBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchStop)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).StopBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/StopBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).StopBatch(ctx, req.(*BatchStop))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SetLegalHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LegalHoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SetLegalHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SetLegalHold",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SetLegalHold(ctx, req.(*LegalHoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_DeleteRecording_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).DeleteRecording(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/DeleteRecording",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).DeleteRecording(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SetLayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SetLayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SetLayout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SetLayout(ctx, req.(*LayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SetParticipant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParticipantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SetParticipant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SetParticipant",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SetParticipant(ctx, req.(*ParticipantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SetCanvas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CanvasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SetCanvas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SetCanvas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SetCanvas(ctx, req.(*CanvasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SetInterpreter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InterpreterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SetInterpreter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SetInterpreter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SetInterpreter(ctx, req.(*InterpreterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SetOptOut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OptOutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SetOptOut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SetOptOut",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SetOptOut(ctx, req.(*OptOutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SearchRecordings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SearchRecordings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SearchRecordings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SearchRecordings(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_AddTranscript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranscriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).AddTranscript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/AddTranscript",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).AddTranscript(ctx, req.(*TranscriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_SearchTranscripts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranscriptSearch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).SearchTranscripts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/SearchTranscripts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).SearchTranscripts(ctx, req.(*TranscriptSearch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_Ingest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IngestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AVPServer).Ingest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/avp.AVP/Ingest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AVPServer).Ingest(ctx, req.(*IngestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AVP_StreamPCM_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PCMRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AVPServer).StreamPCM(m, &aVPStreamPCMServer{stream})
+}
+
+type AVP_StreamPCMServer interface {
+	Send(*PCMChunk) error
+	grpc.ServerStream
+}
+
+type aVPStreamPCMServer struct {
+	grpc.ServerStream
+}
+
+func (x *aVPStreamPCMServer) Send(m *PCMChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // AVP_ServiceDesc is the grpc.ServiceDesc for AVP service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var AVP_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "avp.AVP",
 	HandlerType: (*AVPServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartExport",
+			Handler:    _AVP_StartExport_Handler,
+		},
+		{
+			MethodName: "GetExport",
+			Handler:    _AVP_GetExport_Handler,
+		},
+		{
+			MethodName: "CancelExport",
+			Handler:    _AVP_CancelExport_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _AVP_Stats_Handler,
+		},
+		{
+			MethodName: "Recordings",
+			Handler:    _AVP_Recordings_Handler,
+		},
+		{
+			MethodName: "ValidatePipeline",
+			Handler:    _AVP_ValidatePipeline_Handler,
+		},
+		{
+			MethodName: "StartBatch",
+			Handler:    _AVP_StartBatch_Handler,
+		},
+		{
+			MethodName: "StopBatch",
+			Handler:    _AVP_StopBatch_Handler,
+		},
+		{
+			MethodName: "SetLegalHold",
+			Handler:    _AVP_SetLegalHold_Handler,
+		},
+		{
+			MethodName: "DeleteRecording",
+			Handler:    _AVP_DeleteRecording_Handler,
+		},
+		{
+			MethodName: "SetLayout",
+			Handler:    _AVP_SetLayout_Handler,
+		},
+		{
+			MethodName: "SetParticipant",
+			Handler:    _AVP_SetParticipant_Handler,
+		},
+		{
+			MethodName: "SetCanvas",
+			Handler:    _AVP_SetCanvas_Handler,
+		},
+		{
+			MethodName: "SetInterpreter",
+			Handler:    _AVP_SetInterpreter_Handler,
+		},
+		{
+			MethodName: "SetOptOut",
+			Handler:    _AVP_SetOptOut_Handler,
+		},
+		{
+			MethodName: "SearchRecordings",
+			Handler:    _AVP_SearchRecordings_Handler,
+		},
+		{
+			MethodName: "AddTranscript",
+			Handler:    _AVP_AddTranscript_Handler,
+		},
+		{
+			MethodName: "SearchTranscripts",
+			Handler:    _AVP_SearchTranscripts_Handler,
+		},
+		{
+			MethodName: "Ingest",
+			Handler:    _AVP_Ingest_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Signal",
@@ -128,6 +871,11 @@ var AVP_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "StreamPCM",
+			Handler:       _AVP_StreamPCM_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "cmd/signal/grpc/proto/avp.proto",
 }
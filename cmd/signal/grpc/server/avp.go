@@ -3,32 +3,152 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"sync"
 
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
 	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/alert"
+	"github.com/pion/ion-avp/pkg/archive"
+	"github.com/pion/ion-avp/pkg/counter"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/export"
+	"github.com/pion/ion-avp/pkg/hold"
+	"github.com/pion/ion-avp/pkg/ingest"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/remote"
+	"github.com/pion/ion-avp/pkg/retry"
+	"github.com/pion/ion-avp/pkg/sandbox"
+	"github.com/pion/ion-avp/pkg/seal"
+	"github.com/pion/ion-avp/pkg/storage"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc"
 )
 
 // AVP represents an avp instance
 type AVP struct {
-	config  avp.Config
-	clients map[string]*SFU
-	mu      sync.RWMutex
+	config   avp.Config
+	clients  map[string]*SFU
+	store    storage.Storage
+	counters *counter.Store
+	archive  *archive.Index
+	exports  *export.Manager
+	records  *recording.Tracker
+	holds    *hold.Holds
+	alerts   *alert.Engine
+	ingester *ingest.Ingester
+	rooms    map[string]*room // room and composite recordings by sfu, session and kind
+	mu       sync.RWMutex
 }
 
 // NewAVP creates a new avp instance
 func NewAVP(c avp.Config, elems map[string]avp.ElementFun) *AVP {
+	retry.SetDefaults(c.Retry)
+	avp.SetLowMemory(c.LowMemory)
+
 	a := &AVP{
 		config:  c,
 		clients: make(map[string]*SFU),
+		exports: export.NewManager(c.Export, contactSheets(c), podcasts(c), polyWAVs(c), mp4s()),
+		records: recording.NewTracker(c.Recording),
+		rooms:   make(map[string]*room),
+	}
+
+	if c.Storage.Type != "" || len(c.Storage.Replicas) > 0 {
+		store, err := storage.New(c.Storage)
+		if err != nil {
+			log.Errorf("error initializing storage: %v", err)
+		} else {
+			a.store = store
+		}
+		if r, ok := store.(*storage.Replicated); ok {
+			r.OnComplete(func(name string, status []storage.ReplicaStatus) {
+				for _, s := range status {
+					if s.State == storage.ReplicaFailed {
+						log.Warnf("recording %s: optional replica %s failed: %v", name, s.Name, s.Err)
+					}
+				}
+				log.Infof("recording %s replicated", name)
+			})
+		}
+	}
+	if c.Recording.Counters != "" {
+		counters, err := counter.Open(c.Recording.Counters)
+		if err != nil {
+			log.Errorf("error opening counters: %v", err)
+		} else {
+			a.counters = counters
+		}
+	}
+
+	if c.Recording.Index != "" {
+		index, err := archive.Open(c.Recording.Index)
+		if err != nil {
+			log.Errorf("error opening recording index: %v", err)
+		} else {
+			a.archive = index
+			index.Follow(a.records)
+		}
+	}
+
+	a.holds = hold.New(a.store)
+	if a.store != nil {
+		a.store = hold.Protect(a.store)
+	}
+
+	if len(c.Sandbox.Elements) > 0 || len(c.Remote.Elements) > 0 {
+		elems = a.wrap(elems)
 	}
 
 	avp.Init(elems)
+	a.ingester = newIngester(c, a.archive)
+
+	if c.Alert.Enabled() {
+		a.alerts = alert.NewEngine(c.Alert, a)
+		a.alerts.Start()
+	}
 
 	return a
 }
 
-// Process starts a process for a track.
-func (a *AVP) Process(ctx context.Context, addr, pid, sid, tid, eid string, config []byte) error {
+// wrap runs elements configured as sandboxed in child processes, and
+// elements configured as remote on other nodes
+func (a *AVP) wrap(elems map[string]avp.ElementFun) map[string]avp.ElementFun {
+	wrapped := make(map[string]avp.ElementFun, len(elems))
+	for eid, f := range elems {
+		wrapped[eid] = f
+	}
+
+	sc := sandbox.Config{Command: a.config.Sandbox.Command, Args: a.config.Sandbox.Args}
+	for _, eid := range a.config.Sandbox.Elements {
+		if _, ok := elems[eid]; !ok {
+			log.Warnf("sandboxed element %s not registered", eid)
+			continue
+		}
+		wrapped[eid] = sandbox.Wrap(sc, eid)
+	}
+
+	conns := make(map[string]*grpc.ClientConn)
+	for eid, addr := range a.config.Remote.Elements {
+		cc, ok := conns[addr]
+		if !ok {
+			var err error
+			if cc, err = grpc.Dial(addr, grpc.WithInsecure(),
+				grpc.WithContextDialer(a.config.Family().Dialer())); err != nil {
+				log.Errorf("error dialing remote %s for element %s: %v", addr, eid, err)
+				continue
+			}
+			conns[addr] = cc
+		}
+		wrapped[eid] = remote.Wrap(cc, eid, a.config.Remote.Window)
+	}
+	return wrapped
+}
+
+// Process starts a process for a track. The correlation ID, if any, traces
+// it through logs and stats.
+func (a *AVP) Process(ctx context.Context, addr, pid, sid, tid, eid string, config []byte, priority avp.Priority, correlation string) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -37,7 +157,66 @@ func (a *AVP) Process(ctx context.Context, addr, pid, sid, tid, eid string, conf
 		return err
 	}
 
-	return t.Process(pid, tid, eid, config)
+	return t.ProcessCorrelated(pid, tid, eid, config, priority, correlation)
+}
+
+// counter returns the persisted counter key, or nil without a counters
+// file
+func (a *AVP) counter(key string) elements.Counter {
+	if a.counters == nil {
+		return nil
+	}
+	return a.counters.Counter(key)
+}
+
+// Archive returns the index of the recordings of the node, or nil if
+// it isn't configured
+func (a *AVP) Archive() *archive.Index {
+	return a.archive
+}
+
+// Storage returns the configured storage backend, or nil
+// if recordings should be written to local files.
+func (a *AVP) Storage() storage.Storage {
+	return a.store
+}
+
+// sidecar returns a function creating the recording sidecar name, in
+// storage or as a local file like the recording, sealed if given a key
+// like the recording. Sidecars of recordings under legal hold aren't
+// created.
+func (a *AVP) sidecar(name string, key []byte) func() (io.WriteCloser, error) {
+	return func() (io.WriteCloser, error) {
+		if err := a.holds.Check(name); err != nil {
+			return nil, err
+		}
+		var w io.WriteCloser
+		var err error
+		if a.store != nil {
+			w, err = a.store.Open(name)
+		} else {
+			w, err = os.Create(name)
+		}
+		if err != nil || len(key) == 0 {
+			return w, err
+		}
+		sw, err := seal.NewWriteCloser(w, key)
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+		return sw, nil
+	}
+}
+
+// Exports returns the export job manager
+func (a *AVP) Exports() *export.Manager {
+	return a.exports
+}
+
+// Recordings returns the recording state tracker
+func (a *AVP) Recordings() *recording.Tracker {
+	return a.records
 }
 
 func (a *AVP) Run(addr, sid, tid string, element avp.Element) error {
@@ -53,7 +232,12 @@ func (a *AVP) Run(addr, sid, tid string, element avp.Element) error {
 }
 
 // Stop stops processing a track. Call when Process or Run should end.
+// Without a track it ends the session's room and composite recordings.
 func (a *AVP) Stop(addr, sid, tid string) error {
+	if tid == "" {
+		a.stopRoom(addr, sid)
+		return nil
+	}
 	t, err := a.getTransportLocked(addr, sid, nil)
 	if err != nil {
 		return err
@@ -63,6 +247,29 @@ func (a *AVP) Stop(addr, sid, tid string) error {
 	return nil
 }
 
+// transport returns the transport of a session if joined
+func (a *AVP) transport(addr, sid string) *avp.WebRTCTransport {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if c := a.clients[addr]; c != nil {
+		return c.Transport(sid)
+	}
+	return nil
+}
+
+// joined returns the sessions joined on this node
+func (a *AVP) joined() []*pb.BatchTarget {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var targets []*pb.BatchTarget
+	for addr, c := range a.clients {
+		for _, sid := range c.Sessions() {
+			targets = append(targets, &pb.BatchTarget{Sfu: addr, Sid: sid})
+		}
+	}
+	return targets
+}
+
 func (a *AVP) getTransportLocked(addr, sid string, config []byte) (*avp.WebRTCTransport, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -88,6 +295,59 @@ func (a *AVP) getTransport(addr, sid string, config []byte) (*avp.WebRTCTranspor
 	return c.GetTransport(sid)
 }
 
+// Stats returns the resource usage of all elements
+func (a *AVP) Stats() []avp.ElementStats {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var stats []avp.ElementStats
+	for _, c := range a.clients {
+		for _, t := range c.Transports() {
+			stats = append(stats, t.Stats()...)
+		}
+	}
+	return stats
+}
+
+// TrackStats returns the receive quality of every track
+func (a *AVP) TrackStats() []avp.TrackStats {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var stats []avp.TrackStats
+	for _, c := range a.clients {
+		for _, t := range c.Transports() {
+			stats = append(stats, t.TrackStats()...)
+		}
+	}
+	return stats
+}
+
+// Pipelines returns the sample counts of elements for alerting
+func (a *AVP) Pipelines() []alert.Pipeline {
+	stats := a.Stats()
+	pipelines := make([]alert.Pipeline, 0, len(stats))
+	for _, s := range stats {
+		pipelines = append(pipelines, alert.Pipeline{
+			Key:     s.Session + "/" + s.Track + "/" + s.ID,
+			Samples: s.Samples + s.Dropped,
+		})
+	}
+	return pipelines
+}
+
+// Tracks returns the receive counts of tracks for alerting
+func (a *AVP) Tracks() []alert.Track {
+	stats := a.TrackStats()
+	tracks := make([]alert.Track, 0, len(stats))
+	for _, s := range stats {
+		tracks = append(tracks, alert.Track{
+			Key:     s.Session + "/" + s.Track,
+			Packets: s.Packets,
+			Lost:    s.Lost,
+		})
+	}
+	return tracks
+}
+
 // CloseSession stops all processing for a session. Call it when the session ends.
 func (a *AVP) CloseSession(addr, sid string) error {
 	c := a.clients[addr]
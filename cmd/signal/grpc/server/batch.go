@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	log "github.com/pion/ion-log"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	errNoTracks  = errors.New("no tracks of the session have arrived")
+	errNotJoined = errors.New("session isn't joined on this node")
+)
+
+// batchTrack is a track targeted by a batch operation
+type batchTrack struct {
+	sfu, sid, tid string
+}
+
+// batch collects the results of a batch operation
+type batch struct {
+	reply *pb.BatchReply
+}
+
+func (b *batch) result(t batchTrack, err error) {
+	r := &pb.BatchResult{Sfu: t.sfu, Sid: t.sid, Tid: t.tid}
+	if err != nil {
+		r.Error = err.Error()
+		b.reply.Failed++
+	}
+	b.reply.Results = append(b.reply.Results, r)
+}
+
+// tracks expands the targets to their tracks, adding every session joined
+// on this node if joined is set. Sessions without tracks are reported as
+// failed.
+func (s *server) tracks(b *batch, targets []*pb.BatchTarget, joined bool) []batchTrack {
+	if joined {
+		targets = append(targets[:len(targets):len(targets)], s.avp.joined()...)
+	}
+	seen := make(map[batchTrack]bool)
+	var tracks []batchTrack
+	for _, target := range targets {
+		tids := target.GetTids()
+		if len(tids) == 0 {
+			if t := s.avp.transport(target.GetSfu(), target.GetSid()); t != nil {
+				tids = t.Tracks()
+			}
+		}
+		if len(tids) == 0 {
+			b.result(batchTrack{sfu: target.GetSfu(), sid: target.GetSid()}, errNoTracks)
+			continue
+		}
+		for _, tid := range tids {
+			t := batchTrack{sfu: target.GetSfu(), sid: target.GetSid(), tid: tid}
+			if !seen[t] {
+				seen[t] = true
+				tracks = append(tracks, t)
+			}
+		}
+	}
+	return tracks
+}
+
+// expand replaces {sid} and {tid} in a pid or filename template
+func (t batchTrack) expand(template string) string {
+	return strings.NewReplacer("{sid}", t.sid, "{tid}", t.tid).Replace(template)
+}
+
+// StartBatch starts the same pipeline on many tracks, reporting each
+// track's result, so operators can e.g. record every active room at once
+func (s *server) StartBatch(ctx context.Context, in *pb.BatchStart) (*pb.BatchReply, error) {
+	b := &batch{reply: &pb.BatchReply{}}
+	tracks := s.tracks(b, in.GetTargets(), in.GetJoined())
+	names := make(map[string]bool)
+	corr := in.GetProcess().GetCorrelation()
+	if corr == "" {
+		corr = in.GetRecord().GetCorrelation()
+	}
+	for _, t := range tracks {
+		var err error
+		switch {
+		case in.GetProcess() != nil:
+			err = s.startProcess(ctx, t, in.GetProcess(), names)
+		case in.GetRecord() != nil:
+			err = s.startRecord(ctx, t, in.GetRecord(), names)
+		default:
+			err = errors.New("set process or record")
+		}
+		if err != nil {
+			log.Warnf("StartBatch session %s track %s%s: %v", t.sid, t.tid, traced(ctx, corr), err)
+		}
+		b.result(t, err)
+	}
+	return b.reply, nil
+}
+
+func (s *server) startProcess(ctx context.Context, t batchTrack, profile *pb.Process, pids map[string]bool) error {
+	pid := t.expand(profile.GetPid())
+	if pid == "" {
+		return errors.New("pid is required")
+	}
+	key := t.sfu + "/" + t.sid + "/" + pid
+	if pids[key] {
+		return fmt.Errorf("pid %s is already used in the session, include {tid} in the pid", pid)
+	}
+	pids[key] = true
+	return s.avp.Process(ctx, t.sfu, pid, t.sid, t.tid, profile.GetEid(), profile.GetConfig(),
+		priorities[profile.GetPriority()], correlation(ctx, profile.GetCorrelation()))
+}
+
+func (s *server) startRecord(ctx context.Context, t batchTrack, profile *pb.RecordStart, filenames map[string]bool) error {
+	in := proto.Clone(profile).(*pb.RecordStart)
+	in.Sfu, in.Sid, in.Tid = t.sfu, t.sid, t.tid
+	if in.Cfg == nil {
+		return errors.New("cfg is required")
+	}
+	in.Cfg.Filename = t.expand(in.Cfg.Filename)
+	if in.Cfg.Filename == "" {
+		return errors.New("filename is required")
+	}
+	if filenames[in.Cfg.Filename] {
+		return fmt.Errorf("filename %s is already used, include {sid} and {tid} in the filename", in.Cfg.Filename)
+	}
+	filenames[in.Cfg.Filename] = true
+
+	// A track only has one kind of media, so a profile recording both
+	// records each track's own
+	if tr := s.avp.transport(t.sfu, t.sid); tr != nil {
+		if codec, ok := tr.Codec(t.tid); ok {
+			switch mime := strings.ToLower(codec.MimeType); {
+			case strings.HasPrefix(mime, "audio/"):
+				in.Cfg.Video = pb.RecordConfig_VIDEO_OFF
+			case strings.HasPrefix(mime, "video/"):
+				in.Cfg.Audio = pb.RecordConfig_AUDIO_OFF
+			}
+		}
+	}
+	return s.record(ctx, in)
+}
+
+// StopBatch stops the pipelines of many tracks, reporting each track's
+// result
+func (s *server) StopBatch(ctx context.Context, in *pb.BatchStop) (*pb.BatchReply, error) {
+	b := &batch{reply: &pb.BatchReply{}}
+	for _, t := range s.tracks(b, in.GetTargets(), in.GetJoined()) {
+		var err error
+		if s.avp.transport(t.sfu, t.sid) == nil {
+			err = errNotJoined
+		} else {
+			err = s.avp.Stop(t.sfu, t.sid, t.tid)
+		}
+		if err != nil {
+			log.Warnf("StopBatch session %s track %s%s: %v", t.sid, t.tid, traced(ctx, ""), err)
+		}
+		b.result(t, err)
+	}
+	return b.reply, nil
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/ingest"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vp8Key is a 320x240 VP8 keyframe header
+var vp8Key = []byte{0x10, 0x02, 0x00, 0x9d, 0x01, 0x2a, 0x40, 0x01, 0xf0, 0x00, 0x00, 0x00}
+
+// arrive joins the session sid with an audio and a video track, which
+// send until the returned func is called
+func arrive(t *testing.T, sid string) (*avp.WebRTCTransport, func()) {
+	me := webrtc.MediaEngine{}
+	require.NoError(t, me.RegisterDefaultCodecs())
+	remote, err := webrtc.NewAPI(webrtc.WithMediaEngine(&me)).NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	audio, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus}, "audio", "pion")
+	require.NoError(t, err)
+	video, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8}, "video", "pion")
+	require.NoError(t, err)
+	for _, track := range []webrtc.TrackLocal{audio, video} {
+		_, err = remote.AddTrack(track)
+		require.NoError(t, err)
+	}
+
+	tr := avp.NewWebRTCTransport(sid, avp.Config{})
+	offer, err := remote.CreateOffer(nil)
+	require.NoError(t, err)
+	gathered := webrtc.GatheringCompletePromise(remote)
+	require.NoError(t, remote.SetLocalDescription(offer))
+	<-gathered
+	// With its candidates, as the answer's aren't trickled
+	answer, err := tr.Answer(*remote.LocalDescription())
+	require.NoError(t, err)
+	require.NoError(t, remote.SetRemoteDescription(answer))
+
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				audio.WriteSample(media.Sample{Data: []byte{0xf8, 0xff, 0xfe}, Duration: 20 * time.Millisecond}) // nolint: errcheck
+				video.WriteSample(media.Sample{Data: vp8Key, Duration: 20 * time.Millisecond})                   // nolint: errcheck
+			case <-done:
+				return
+			}
+		}
+	}()
+	require.Eventually(t, func() bool {
+		return len(tr.Tracks()) == 2
+	}, 10*time.Second, 20*time.Millisecond)
+	return tr, func() {
+		close(done)
+		<-stopped
+		tr.Close()     // nolint: errcheck
+		remote.Close() // nolint: errcheck
+	}
+}
+
+func resultErrors(reply *pb.BatchReply) map[string]string {
+	errs := make(map[string]string)
+	for _, r := range reply.Results {
+		errs[r.Sid+"/"+r.Tid] = r.Error
+	}
+	return errs
+}
+
+func TestStartBatch_Record(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tr, leave := arrive(t, "s1")
+	defer leave()
+	s := newTestServer(map[string]*avp.WebRTCTransport{"s1": tr})
+
+	// Both kinds, narrowed to each track's own
+	record := func(filename string) *pb.BatchStart_Record {
+		return &pb.BatchStart_Record{Record: &pb.RecordStart{Cfg: &pb.RecordConfig{
+			Filename: filepath.Join(dir, filename),
+			Audio:    pb.RecordConfig_AUDIO_STEREO,
+			Video:    pb.RecordConfig_VIDEO_ON,
+		}}}
+	}
+	reply, err := s.StartBatch(context.Background(), &pb.BatchStart{
+		Targets: []*pb.BatchTarget{
+			// Again, by track, so recorded once
+			{Sfu: "sfu", Sid: "s1", Tids: []string{"video"}},
+			// Not joined, and can't be
+			{Sfu: "sfu", Sid: "s2", Tids: []string{"audio"}},
+			// Not joined, without tracks to expand to
+			{Sfu: "sfu", Sid: "s3"},
+		},
+		Joined:  true,
+		Profile: record("{sid}-{tid}.webm"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), reply.Failed)
+	errs := resultErrors(reply)
+	assert.Len(t, reply.Results, 4)
+	assert.Equal(t, "", errs["s1/audio"])
+	assert.Equal(t, "", errs["s1/video"])
+	assert.Contains(t, errs["s2/audio"], "sfu unavailable")
+	assert.Equal(t, errNoTracks.Error(), errs["s3/"])
+
+	for _, tid := range []string{"audio", "video"} {
+		rec, ok := s.avp.Recordings().Get("s1", tid)
+		require.True(t, ok)
+		assert.Equal(t, filepath.Join(dir, "s1-"+tid+".webm"), rec.Status().Name)
+		assert.Eventually(t, func() bool {
+			return rec.State() == recording.StateRecording
+		}, 5*time.Second, 20*time.Millisecond, tid)
+	}
+
+	// A filename without {tid} is used by the first track only
+	reply, err = s.StartBatch(context.Background(), &pb.BatchStart{
+		Targets: []*pb.BatchTarget{{Sfu: "sfu", Sid: "s2", Tids: []string{"a", "b"}}},
+		Profile: record("{sid}.webm"),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resultErrors(reply)["s2/b"], "filename "+filepath.Join(dir, "s2.webm")+" is already used")
+
+	reply, err = s.StopBatch(context.Background(), &pb.BatchStop{
+		Targets: []*pb.BatchTarget{{Sfu: "sfu", Sid: "s2", Tids: []string{"audio"}}},
+		Joined:  true,
+	})
+	require.NoError(t, err)
+	errs = resultErrors(reply)
+	assert.Len(t, reply.Results, 3)
+	assert.Equal(t, uint32(1), reply.Failed)
+	assert.Equal(t, "", errs["s1/audio"])
+	assert.Equal(t, "", errs["s1/video"])
+	assert.Equal(t, errNotJoined.Error(), errs["s2/audio"])
+
+	// Each file has its track's kind only, video rotated where it resizes
+	for name, kind := range map[string]string{"s1-audio.webm": "audio", "s1-video-0.webm": "video"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		require.NoError(t, err)
+		m, err := ingest.Demux(f.Name(), f)
+		f.Close()
+		require.NoError(t, err)
+		require.Len(t, m.Tracks, 1, name)
+		assert.Equal(t, kind, m.Tracks[0].Kind)
+	}
+}
+
+func TestStartBatch_Process(t *testing.T) {
+	avp.Init(map[string]avp.ElementFun{
+		"leaf": func(sid, pid, tid string, config []byte) avp.Element {
+			return &elements.Leaf{}
+		},
+	})
+	s := newTestServer(map[string]*avp.WebRTCTransport{"s1": avp.NewWebRTCTransport("s1", avp.Config{})})
+	defer s.avp.transport("sfu", "s1").Close() // nolint: errcheck
+
+	start := func(pid string) map[string]string {
+		reply, err := s.StartBatch(context.Background(), &pb.BatchStart{
+			Targets: []*pb.BatchTarget{{Sfu: "sfu", Sid: "s1", Tids: []string{"a", "b", "a"}}},
+			Profile: &pb.BatchStart_Process{Process: &pb.Process{Pid: pid, Eid: "leaf"}},
+		})
+		require.NoError(t, err)
+		assert.Len(t, reply.Results, 2)
+		return resultErrors(reply)
+	}
+	assert.Equal(t, map[string]string{"s1/a": "", "s1/b": ""}, start("{sid}-{tid}"))
+	// Both tracks would be processed by the one pipeline s1
+	assert.Equal(t, map[string]string{
+		"s1/a": "",
+		"s1/b": "pid s1 is already used in the session, include {tid} in the pid",
+	}, start("{sid}"))
+	assert.Equal(t, map[string]string{
+		"s1/a": "pid is required",
+		"s1/b": "pid is required",
+	}, start(""))
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/contactsheet"
+	"github.com/pion/ion-avp/pkg/export"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/podcast"
+	"github.com/pion/ion-avp/pkg/polywav"
+	"github.com/pion/ion-avp/pkg/stitch/mp4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var exportStates = map[export.State]pb.ExportJob_State{
+	export.Queued:   pb.ExportJob_QUEUED,
+	export.Running:  pb.ExportJob_RUNNING,
+	export.Done:     pb.ExportJob_DONE,
+	export.Failed:   pb.ExportJob_FAILED,
+	export.Canceled: pb.ExportJob_CANCELED,
+}
+
+// contactSheets exports recordings to .jpg and .png contact sheets
+func contactSheets(c avp.Config) export.Format {
+	cfg := contactsheet.Config{
+		Interval: c.ContactSheet.Interval,
+		Max:      c.ContactSheet.Max,
+		Columns:  c.ContactSheet.Columns,
+		Width:    c.ContactSheet.Width,
+	}
+	return export.Format{
+		Supported: contactsheet.Supported,
+		Export: func(ctx context.Context, w io.Writer, name string, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+			return contactsheet.Render(ctx, w, name, m, open, cfg, progress)
+		},
+	}
+}
+
+// podcasts exports recordings to .opus and .ogg podcast episodes
+func podcasts(c avp.Config) export.Format {
+	cfg := podcast.Config{
+		Level:    c.Podcast.Level,
+		Silence:  c.Podcast.Silence,
+		MaxGain:  c.Podcast.MaxGain,
+		MaxPause: c.Podcast.MaxPause,
+		Chapter:  c.Podcast.Chapter,
+		Bitrate:  c.Podcast.Bitrate,
+		Artist:   c.Podcast.Artist,
+	}
+	return export.Format{
+		Supported: podcast.Supported,
+		Export: func(ctx context.Context, w io.Writer, name string, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+			return podcast.Render(ctx, w, m, open, cfg, progress)
+		},
+	}
+}
+
+// polyWAVs exports recordings to .wav files with a channel pair per
+// participant, and .zip files of a .wav file per participant
+func polyWAVs(c avp.Config) export.Format {
+	cfg := polywav.Config{Channels: c.PolyWAV.Channels}
+	return export.Format{
+		Supported: polywav.Supported,
+		Export: func(ctx context.Context, w io.Writer, name string, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+			return polywav.Render(ctx, w, name, m, open, cfg, progress)
+		},
+	}
+}
+
+// mp4s exports recordings to .mp4 files
+func mp4s() export.Format {
+	return export.Format{
+		Supported: mp4.Supported,
+		Export: func(ctx context.Context, w io.Writer, name string, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+			return mp4.Render(ctx, w, m, open, progress)
+		},
+	}
+}
+
+func exportReply(j export.Job, err error) (*pb.ExportJob, error) {
+	switch {
+	case errors.Is(err, export.ErrNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, export.ErrQueueFull):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.ExportJob{
+		Id:       j.ID,
+		State:    exportStates[j.State],
+		Progress: j.Progress,
+		Error:    j.Error,
+		Output:   j.Output,
+	}, nil
+}
+
+// StartExport queues stitching of a finished recording into one file, or
+// rendering its contact sheet, podcast episode or multitrack audio
+func (s *server) StartExport(ctx context.Context, in *pb.ExportRequest) (*pb.ExportJob, error) {
+	return exportReply(s.avp.Exports().Submit(export.Request{
+		Manifest: in.Manifest,
+		Output:   in.Output,
+		Webhook:  in.Webhook,
+	}))
+}
+
+// GetExport returns the progress of an export
+func (s *server) GetExport(ctx context.Context, in *pb.ExportQuery) (*pb.ExportJob, error) {
+	return exportReply(s.avp.Exports().Get(in.Id))
+}
+
+// CancelExport stops an export
+func (s *server) CancelExport(ctx context.Context, in *pb.ExportQuery) (*pb.ExportJob, error) {
+	return exportReply(s.avp.Exports().Cancel(in.Id))
+}
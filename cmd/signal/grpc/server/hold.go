@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	"github.com/pion/ion-avp/pkg/hold"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetLegalHold places or releases the legal hold of a recording
+func (s *server) SetLegalHold(ctx context.Context, in *pb.LegalHoldRequest) (*pb.LegalHold, error) {
+	if in.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if !in.GetHeld() {
+		if err := s.avp.holds.Release(in.GetName()); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return &pb.LegalHold{Name: in.GetName()}, nil
+	}
+	h, err := s.avp.holds.Place(in.GetName(), in.GetReason())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.LegalHold{
+		Name:   h.Recording,
+		Held:   true,
+		Reason: h.Reason,
+		Since:  h.Since.UnixNano() / 1e6,
+	}, nil
+}
+
+// DeleteRecording deletes a recording and its sidecars unless it is under
+// legal hold
+func (s *server) DeleteRecording(ctx context.Context, in *pb.DeleteRequest) (*pb.DeleteReply, error) {
+	if in.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	deleted, err := s.avp.remove(in.GetName())
+	if errors.Is(err, hold.ErrHeld) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.Infof("recording %s deleted: %v", in.GetName(), deleted)
+	return &pb.DeleteReply{Deleted: deleted}, nil
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/ion-avp/pkg/hold"
+	"github.com/pion/ion-avp/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeldRecordingNotOverwritten(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hold")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "a.webm")
+	assert.NoError(t, ioutil.WriteFile(name, []byte("evidence"), 0644))
+	s := &server{avp: &AVP{holds: hold.New(nil)}}
+	_, err = s.avp.holds.Place(name, "case 42")
+	assert.NoError(t, err)
+
+	_, err = s.writer(name, 0, nil)
+	assert.True(t, errors.Is(err, hold.ErrHeld))
+	_, err = s.avp.sidecar(name+".quality.json", nil)()
+	assert.True(t, errors.Is(err, hold.ErrHeld))
+	b, err := ioutil.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, "evidence", string(b))
+	_, err = os.Stat(name + ".quality.json")
+	assert.True(t, os.IsNotExist(err))
+
+	// Others are written as usual
+	w, err := s.writer(filepath.Join(dir, "b.webm"), 0, nil)
+	assert.NoError(t, err)
+	w.Close()
+}
+
+func TestRemoveStreamed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remove")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// An HLS recording is a directory of segments
+	name := filepath.Join(dir, "live")
+	assert.NoError(t, os.MkdirAll(name, 0755))
+	for _, f := range []string{filepath.Join(name, "index.m3u8"), filepath.Join(name, "segment-0.m4s"), name + ".speech.json"} {
+		assert.NoError(t, ioutil.WriteFile(f, []byte("x"), 0644))
+	}
+	a := &AVP{holds: hold.New(nil)}
+	deleted, err := a.remove(name)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{name, name + ".speech.json"}, deleted)
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err))
+
+	// In storage, of its objects
+	store := storage.NewLocal(storage.LocalConfig{Root: dir})
+	for _, f := range []string{"live/index.m3u8", "live/segment-0.m4s", "live.json", "lively.webm"} {
+		w, err := store.Open(f)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+		assert.NoError(t, store.Finalize(f))
+	}
+	a = &AVP{store: store, holds: hold.New(store)}
+	deleted, err = a.remove("live")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"live/index.m3u8", "live/segment-0.m4s", "live.json"}, deleted)
+	left, err := store.List("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lively.webm"}, left)
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/archive"
+	"github.com/pion/ion-avp/pkg/ingest"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newIngester runs files through the configured pipelines, watching the
+// ingest directory if there is one. Files are listed in index, if any.
+func newIngester(c avp.Config, index *archive.Index) *ingest.Ingester {
+	cfg := ingest.Config{
+		Dir:      c.Ingest.Dir,
+		Interval: c.Ingest.Interval,
+		Settle:   c.Ingest.Settle,
+		Index:    index,
+	}
+	for _, p := range c.Ingest.Pipelines {
+		if err := avp.CheckElement(p.Eid); err != nil {
+			log.Warnf("ingest pipeline %s: %v", p.Eid, err)
+		}
+		cfg.Pipelines = append(cfg.Pipelines, ingest.Pipeline{Eid: p.Eid, Config: []byte(p.Config), Kind: p.Kind})
+	}
+	if cfg.Dir != "" {
+		log.Infof("ingesting media files copied into %s", cfg.Dir)
+	}
+	return ingest.New(cfg)
+}
+
+// Ingest runs a media file of the node through the ingest pipelines
+func (s *server) Ingest(ctx context.Context, in *pb.IngestRequest) (*pb.IngestReply, error) {
+	if in.GetPath() == "" {
+		return nil, status.Error(codes.InvalidArgument, "path is required")
+	}
+	res, err := s.avp.ingester.Ingest(ctx, in.GetPath())
+	switch {
+	case os.IsNotExist(err):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ingest.ErrFormat):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, avp.ErrElementNotFound), errors.Is(err, avp.ErrElementDisabled):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	reply := &pb.IngestReply{Id: res.ID, Duration: res.Duration.Milliseconds()}
+	for _, t := range res.Tracks {
+		reply.Tracks = append(reply.Tracks, t.ID)
+	}
+	return reply, nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	"github.com/pion/ion-avp/pkg/interpret"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetInterpreter routes an interpreter's audio to a channel of a session,
+// as interpreters hand over in the booth
+func (s *server) SetInterpreter(ctx context.Context, in *pb.InterpreterRequest) (*pb.InterpreterReply, error) {
+	if in.GetChannel() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel is required")
+	}
+	if !in.GetRemove() && in.GetSource() == "" {
+		return nil, status.Error(codes.InvalidArgument, "source is required")
+	}
+	t := s.avp.transport(in.GetSfu(), in.GetSid())
+	if t == nil {
+		return nil, status.Error(codes.FailedPrecondition, errNotJoined.Error())
+	}
+	if in.GetRemove() {
+		t.Interpreters().Remove(in.GetChannel())
+		return &pb.InterpreterReply{}, nil
+	}
+	t.Interpreters().Set(in.GetChannel(), interpret.Route{Source: in.GetSource(), Language: in.GetLanguage()})
+	log.Infof("session %s channel %s interpreted by %s%s", in.GetSid(), in.GetChannel(), in.GetSource(), traced(ctx, ""))
+	return &pb.InterpreterReply{}, nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"image"
+	_ "image/jpeg" // avatars and backgrounds
+	_ "image/png"
+	"strconv"
+	"time"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	"github.com/pion/ion-avp/pkg/layout"
+	"github.com/pion/ion-avp/pkg/pixel"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetLayout switches the layout of a session's composites at runtime
+func (s *server) SetLayout(ctx context.Context, in *pb.LayoutRequest) (*pb.LayoutReply, error) {
+	p, err := layout.Define(in.GetLayout())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	t := s.avp.transport(in.GetSfu(), in.GetSid())
+	if t == nil {
+		return nil, status.Error(codes.FailedPrecondition, errNotJoined.Error())
+	}
+	t.Layout().Set(p)
+	log.Infof("session %s layout switched%s", in.GetSid(), traced(ctx, ""))
+	return &pb.LayoutReply{}, nil
+}
+
+// maxAvatar is the largest side avatars are kept at, tiles of a composite
+// rarely being larger
+const maxAvatar = 256
+
+// SetParticipant sets the name and avatar composites of a session show on
+// a source's tiles
+func (s *server) SetParticipant(ctx context.Context, in *pb.ParticipantRequest) (*pb.ParticipantReply, error) {
+	if in.GetSource() == "" {
+		return nil, status.Error(codes.InvalidArgument, "source is required")
+	}
+	t := s.avp.transport(in.GetSfu(), in.GetSid())
+	if t == nil {
+		return nil, status.Error(codes.FailedPrecondition, errNotJoined.Error())
+	}
+	if in.GetRemove() {
+		t.Roster().Remove(in.GetSource())
+		return &pb.ParticipantReply{}, nil
+	}
+	p := layout.Participant{Name: in.GetName()}
+	if len(in.GetAvatar()) > 0 {
+		img, _, err := image.Decode(bytes.NewReader(in.GetAvatar()))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "avatar isn't a PNG or JPEG: %v", err)
+		}
+		p.Avatar = avatar(img)
+	}
+	t.Roster().Set(in.GetSource(), p)
+	return &pb.ParticipantReply{}, nil
+}
+
+// SetCanvas sets the background composites of a session draw, a colour,
+// image or slate, and the margins kept clear of tiles
+func (s *server) SetCanvas(ctx context.Context, in *pb.CanvasRequest) (*pb.CanvasReply, error) {
+	colour := layout.Colour{A: 0xff}
+	if in.GetBackground() != "" {
+		if err := colour.UnmarshalJSON([]byte(strconv.Quote(in.GetBackground()))); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	var frames []*image.YCbCr
+	for i, b := range in.GetImages() {
+		img, _, err := image.Decode(bytes.NewReader(b))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "image %d isn't a PNG or JPEG: %v", i, err)
+		}
+		frames = append(frames, pixel.FromImage(img))
+	}
+	m := in.GetMargins()
+	margins := layout.Margins{Top: m.GetTop(), Right: m.GetRight(), Bottom: m.GetBottom(), Left: m.GetLeft()}
+
+	t := s.avp.transport(in.GetSfu(), in.GetSid())
+	if t == nil {
+		return nil, status.Error(codes.FailedPrecondition, errNotJoined.Error())
+	}
+	c := t.Canvas()
+	if err := c.SetMargins(margins); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	interval := time.Duration(in.GetInterval()) * time.Millisecond
+	if err := c.SetBackground(colour, frames, interval); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Infof("session %s canvas set%s", in.GetSid(), traced(ctx, ""))
+	return &pb.CanvasReply{}, nil
+}
+
+// avatar converts an image to YCbCr, scaled down to maxAvatar
+func avatar(img image.Image) *image.YCbCr {
+	m := pixel.FromImage(img)
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	if w <= maxAvatar && h <= maxAvatar {
+		return m
+	}
+	if w > h {
+		w, h = maxAvatar, h*maxAvatar/w
+	} else {
+		w, h = w*maxAvatar/h, maxAvatar
+	}
+	if w < 1 || h < 1 {
+		return m
+	}
+	dst := image.NewYCbCr(image.Rect(0, 0, w, h), m.SubsampleRatio)
+	if err := pixel.ScaleFit(dst, m, pixel.FitPad); err != nil {
+		return m
+	}
+	return dst
+}
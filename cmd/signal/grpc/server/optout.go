@@ -0,0 +1,25 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetOptOut opts a track out of recording, or back in, as the application
+// asks, e.g. for a participant who declined
+func (s *server) SetOptOut(ctx context.Context, in *pb.OptOutRequest) (*pb.OptOutReply, error) {
+	if in.GetTid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "tid is required")
+	}
+	t := s.avp.transport(in.GetSfu(), in.GetSid())
+	if t == nil {
+		return nil, status.Error(codes.FailedPrecondition, errNotJoined.Error())
+	}
+	t.SetOptOut(in.GetTid(), in.GetOptOut())
+	log.Infof("track %s of session %s opted out: %t%s", in.GetTid(), in.GetSid(), in.GetOptOut(), traced(ctx, ""))
+	return &pb.OptOutReply{OptedOut: t.OptedOut(in.GetTid())}, nil
+}
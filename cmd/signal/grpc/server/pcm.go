@@ -0,0 +1,77 @@
+package server
+
+import (
+	"strings"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamPCM streams the decoded audio of a track until the client goes or
+// the track ends. A client reading slowly has chunks dropped rather than
+// holding up the track's pipelines.
+func (s *server) StreamPCM(in *pb.PCMRequest, stream pb.AVP_StreamPCMServer) error {
+	ctx := stream.Context()
+	t := s.avp.transport(in.GetSfu(), in.GetSid())
+	if t == nil {
+		return status.Error(codes.FailedPrecondition, errNotJoined.Error())
+	}
+	codec, ok := t.Codec(in.GetTid())
+	if !ok {
+		return status.Error(codes.FailedPrecondition, avp.ErrTrackNotFound.Error())
+	}
+	if !strings.EqualFold(codec.MimeType, avp.MimeTypeOpus) {
+		return status.Error(codes.InvalidArgument, "track is not Opus audio")
+	}
+	pcm, err := elements.NewPCMStream(elements.PCMStreamConfig{
+		Rate:     int(in.GetRate()),
+		Channels: int(in.GetChannels()),
+		Buffer:   int(in.GetBuffer()),
+	})
+	if err == elements.ErrPCMRate {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if err := t.Attach(in.GetTid(), pcm); err != nil {
+		pcm.Close()
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	defer t.Detach(in.GetTid(), pcm)
+	log.Infof("streaming PCM of track %s%s", in.GetTid(), traced(ctx, ""))
+
+	var buf []byte
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case c, ok := <-pcm.Chunks():
+			if !ok {
+				return nil
+			}
+			buf = buf[:0]
+			for _, v := range c.Data {
+				buf = append(buf, byte(v), byte(v>>8))
+			}
+			out := &pb.PCMChunk{
+				Data:     buf,
+				Rate:     int32(c.Rate),
+				Channels: int32(c.Channels),
+				Time:     c.Time.Milliseconds(),
+				Dropped:  c.Dropped,
+			}
+			if !c.CaptureTime.IsZero() {
+				out.CaptureTime = c.CaptureTime.UnixNano() / 1e6
+			}
+			if err := stream.Send(out); err != nil {
+				log.Infof("PCM stream of track %s ended: %s%s", in.GetTid(), err, traced(ctx, ""))
+				return err
+			}
+		}
+	}
+}
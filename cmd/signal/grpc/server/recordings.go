@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	"github.com/pion/ion-avp/pkg/archive"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var recordingStates = map[recording.State]pb.Recording_State{
+	recording.StatePending:            pb.Recording_PENDING,
+	recording.StateWaitingForKeyframe: pb.Recording_WAITING_FOR_KEYFRAME,
+	recording.StateRecording:          pb.Recording_RECORDING,
+	recording.StatePaused:             pb.Recording_PAUSED,
+	recording.StateFinalizing:         pb.Recording_FINALIZING,
+	recording.StateUploading:          pb.Recording_UPLOADING,
+	recording.StateComplete:           pb.Recording_COMPLETE,
+	recording.StateFailed:             pb.Recording_FAILED,
+}
+
+// recording returns the recording of a track. It is nil for tracks not
+// being recorded, which is safe to call methods on.
+func (s *server) recording(sid, tid string) *recording.Recording {
+	r, ok := s.avp.Recordings().Get(sid, tid)
+	if !ok {
+		log.Warnf("no recording of session %s track %s", sid, tid)
+	}
+	return r
+}
+
+func recordings(list []recording.Status) []*pb.Recording {
+	var out []*pb.Recording
+	for _, r := range list {
+		out = append(out, &pb.Recording{
+			Id:          r.ID,
+			Sid:         r.Session,
+			Tid:         r.Track,
+			Name:        r.Name,
+			State:       recordingStates[r.State],
+			Error:       r.Error,
+			Created:     r.Created.UnixNano() / 1e6,
+			Updated:     r.Updated.UnixNano() / 1e6,
+			Url:         r.URL,
+			Correlation: r.Correlation,
+		})
+	}
+	return out
+}
+
+// Recordings lists recordings in progress and recently finished
+func (s *server) Recordings(ctx context.Context, in *pb.RecordingsRequest) (*pb.RecordingsReply, error) {
+	return &pb.RecordingsReply{Recordings: recordings(s.avp.Recordings().List(in.Sid))}, nil
+}
+
+// SearchRecordings searches the archive of recordings this node has
+// produced
+func (s *server) SearchRecordings(ctx context.Context, in *pb.SearchRequest) (*pb.SearchReply, error) {
+	index := s.avp.Archive()
+	if index == nil {
+		return nil, status.Error(codes.FailedPrecondition, "recording index isn't configured")
+	}
+	q := archive.Query{
+		Session:    in.GetSid(),
+		Track:      in.GetTid(),
+		Name:       in.GetName(),
+		Transcript: in.GetTranscript(),
+		Limit:      int(in.GetLimit()),
+	}
+	if in.GetFrom() > 0 {
+		q.From = time.Unix(0, in.GetFrom()*1e6)
+	}
+	if in.GetTo() > 0 {
+		q.To = time.Unix(0, in.GetTo()*1e6)
+	}
+	reply := &pb.SearchReply{}
+	for _, e := range index.Search(q) {
+		reply.Recordings = append(reply.Recordings, &pb.ArchivedRecording{
+			Id:          e.ID,
+			Sid:         e.Session,
+			Tid:         e.Track,
+			Name:        e.Name,
+			State:       recordingStates[e.State],
+			Start:       e.Start.UnixNano() / 1e6,
+			End:         e.End.UnixNano() / 1e6,
+			Duration:    e.Duration().Milliseconds(),
+			Locations:   e.Locations,
+			Transcripts: e.Transcripts,
+		})
+	}
+	return reply, nil
+}
+
+// AddTranscript indexes a transcript of a recording of the archive
+func (s *server) AddTranscript(ctx context.Context, in *pb.TranscriptRequest) (*pb.TranscriptReply, error) {
+	index := s.avp.Archive()
+	if index == nil {
+		return nil, status.Error(codes.FailedPrecondition, "recording index isn't configured")
+	}
+	if in.GetId() == "" || in.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "transcript needs a recording id and name")
+	}
+	cues, err := archive.ParseCues(bytes.NewReader(in.GetData()))
+	if errors.Is(err, archive.ErrCues) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := index.IndexTranscript(in.GetId(), in.GetName(), cues); err != nil {
+		return nil, err
+	}
+	return &pb.TranscriptReply{Cues: uint32(len(cues))}, nil
+}
+
+// SearchTranscripts searches the transcripts of the archive for cues
+func (s *server) SearchTranscripts(ctx context.Context, in *pb.TranscriptSearch) (*pb.TranscriptHits, error) {
+	index := s.avp.Archive()
+	if index == nil {
+		return nil, status.Error(codes.FailedPrecondition, "recording index isn't configured")
+	}
+	if strings.TrimSpace(in.GetText()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "search text is empty")
+	}
+	hits := index.SearchText(archive.TextQuery{
+		Text:    in.GetText(),
+		Session: in.GetSid(),
+		Limit:   int(in.GetLimit()),
+	})
+	reply := &pb.TranscriptHits{}
+	for _, h := range hits {
+		hit := &pb.TranscriptHit{
+			Id:    h.Recording,
+			Sid:   h.Session,
+			Name:  h.Transcript,
+			Start: h.Start.Milliseconds(),
+			End:   h.End.Milliseconds(),
+			Text:  h.Text,
+		}
+		if !h.At.IsZero() {
+			hit.At = h.At.UnixNano() / 1e6
+		}
+		reply.Hits = append(reply.Hits, hit)
+	}
+	return reply, nil
+}
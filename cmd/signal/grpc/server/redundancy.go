@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/redundancy"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc"
+)
+
+// peer asks the node recording the primary copy for its recordings
+type peer struct {
+	client pb.AVPClient
+}
+
+func (p peer) Recording(ctx context.Context, sid, tid string) (recording.Status, error) {
+	reply, err := p.client.Recordings(ctx, &pb.RecordingsRequest{Sid: sid})
+	if err != nil {
+		return recording.Status{}, err
+	}
+	for _, r := range reply.Recordings {
+		if r.Tid != tid {
+			continue
+		}
+		for state, s := range recordingStates {
+			if s == r.State {
+				return recording.Status{ID: r.Id, Session: r.Sid, Track: r.Tid, Name: r.Name, State: state}, nil
+			}
+		}
+	}
+	return recording.Status{}, redundancy.ErrNotFound
+}
+
+// backup deletes the recording once finished if the primary node at addr
+// completed its copy
+func (a *AVP) backup(rec *recording.Recording, addr string) {
+	rec.OnFinished(func(s recording.Status) {
+		go func() {
+			cc, err := grpc.Dial(addr, grpc.WithInsecure(),
+				grpc.WithContextDialer(a.config.Family().Dialer()))
+			if err != nil {
+				log.Warnf("recording %s: keeping backup, dialing primary %s: %v", s.ID, addr, err)
+				return
+			}
+			defer cc.Close()
+
+			keep, err := redundancy.Keep(context.Background(), a.config.Redundancy,
+				peer{client: pb.NewAVPClient(cc)}, s.Session, s.Track)
+			if keep {
+				log.Infof("recording %s: keeping backup %s, primary %s did not complete: %v", s.ID, s.Name, addr, err)
+				return
+			}
+			log.Infof("recording %s: primary %s complete, deleting backup %s", s.ID, addr, s.Name)
+			if _, err := a.remove(s.Name); err != nil {
+				log.Warnf("recording %s: keeping backup %s: %v", s.ID, s.Name, err)
+			}
+		}()
+	})
+}
+
+// remove deletes a recording and its sidecars, unless under legal hold,
+// and returns the names deleted
+func (a *AVP) remove(name string) ([]string, error) {
+	if err := a.holds.Check(name); err != nil {
+		return nil, err
+	}
+	var names []string
+	if a.store != nil {
+		list, err := a.store.List(name)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", name, err)
+		}
+		names = list
+	} else {
+		list, _ := filepath.Glob(name + ".*")
+		names = append(list, name)
+	}
+	var deleted []string
+	for _, n := range names {
+		// Streamed recordings are directories of segments
+		if n != name && !strings.HasPrefix(n, name+".") && !strings.HasPrefix(n, name+"/") {
+			continue
+		}
+		var err error
+		if a.store != nil {
+			err = a.store.Delete(n)
+		} else if info, serr := os.Stat(n); serr == nil && info.IsDir() {
+			err = os.RemoveAll(n)
+		} else {
+			err = os.Remove(n)
+		}
+		if err != nil {
+			log.Errorf("deleting %s: %v", n, err)
+			continue
+		}
+		deleted = append(deleted, n)
+	}
+	return deleted, nil
+}
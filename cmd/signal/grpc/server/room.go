@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/pixel"
+	log "github.com/pion/ion-log"
+)
+
+var (
+	errRoomRecording      = errors.New("session's room is being recorded already")
+	errCompositeRecording = errors.New("session's composite is being recorded already")
+)
+
+// Kinds of recordings of a whole session, one of each at a time
+const (
+	roomTracks    = "room"
+	roomComposite = "composite"
+)
+
+// room is a recording of every track of a session's transport
+type room struct {
+	t *avp.WebRTCTransport
+	r avp.Room
+}
+
+// rooms are run as one, each joined by every track
+type rooms []avp.Room
+
+func (rs rooms) Join(tid string) avp.Element {
+	n := &elements.Node{}
+	for _, r := range rs {
+		n.Attach(r.Join(tid))
+	}
+	return n
+}
+
+func (rs rooms) Close() {
+	for _, r := range rs {
+		r.Close()
+	}
+}
+
+// Composite is true if any of the rooms composes video
+func (rs rooms) Composite() bool {
+	for _, r := range rs {
+		if c, ok := r.(avp.Composite); ok && c.Composite() {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingRoom reports whether the room of a session's transport is being
+// recorded, as kind. Rooms of transports closed since are over.
+func (a *AVP) recordingRoom(addr, sid, kind string, t *avp.WebRTCTransport) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	r := a.rooms[addr+"/"+sid+"/"+kind]
+	return r != nil && r.t == t
+}
+
+// runRoom records every track of a session's transport with r, unless its
+// room is being recorded as kind already
+func (a *AVP) runRoom(addr, sid, kind string, t *avp.WebRTCTransport, r avp.Room) error {
+	if a.recordingRoom(addr, sid, kind, t) {
+		if kind == roomComposite {
+			return errCompositeRecording
+		}
+		return errRoomRecording
+	}
+	a.mu.Lock()
+	a.rooms[addr+"/"+sid+"/"+kind] = &room{t: t, r: r}
+	a.mu.Unlock()
+	t.RunRoom(r)
+	return nil
+}
+
+// stopRoom ends the room and composite recordings of a session, if any
+func (a *AVP) stopRoom(addr, sid string) {
+	var stopped []*room
+	a.mu.Lock()
+	for _, kind := range []string{roomTracks, roomComposite} {
+		key := addr + "/" + sid + "/" + kind
+		if r := a.rooms[key]; r != nil {
+			stopped = append(stopped, r)
+		}
+		delete(a.rooms, key)
+	}
+	a.mu.Unlock()
+	for _, r := range stopped {
+		r.t.StopRoom(r.r)
+	}
+}
+
+// recordRoom records every track of a session into one WebM file, with a
+// track added as each of the participants' arrives
+func (s *server) recordRoom(ctx context.Context, in *pb.RecordStart) error {
+	cfg := in.Cfg
+	if f := cfg.GetFormat(); f != pb.RecordConfig_WEBM && f != pb.RecordConfig_MKV {
+		return fmt.Errorf("room recordings are WebM, not %s", f)
+	}
+	// Not over a recording under legal hold
+	if err := s.avp.holds.Check(cfg.GetFilename()); err != nil {
+		return err
+	}
+	t, err := s.avp.getTransportLocked(in.Sfu, in.Sid, nil)
+	if err != nil {
+		return err
+	}
+	// A second recording would take the first's place in the tracker
+	if s.avp.recordingRoom(in.Sfu, in.Sid, roomTracks, t) {
+		return errRoomRecording
+	}
+	filename := cfg.GetFilename()
+	rec := s.avp.Recordings().StartCorrelated(in.Sid, "", filename, correlation(ctx, in.Correlation))
+	var epoch time.Time
+	if cfg.GetAlign() {
+		epoch = s.avp.Recordings().Epoch(in.Sid)
+	}
+	recorder := elements.NewRoomRecorder(elements.RoomRecorderConfig{
+		Recording: rec,
+		Epoch:     epoch,
+		Participant: func(tid string) elements.Participant {
+			p, _ := t.Roster().Get(tid)
+			return elements.Participant{ID: tid, Name: p.Name}
+		},
+	})
+	if err := s.avp.runRoom(in.Sfu, in.Sid, roomTracks, t, recorder); err != nil {
+		rec.Fail(err)
+		return err
+	}
+	// The file is written when the recording ends
+	w, err := s.writer(filename, int(cfg.GetBuffersize()), cfg.GetKey())
+	if err != nil {
+		s.avp.stopRoom(in.Sfu, in.Sid)
+		rec.Fail(err)
+		return err
+	}
+	w.SetRecording(rec)
+	recorder.Attach(w)
+	log.Infof("recording room of session %s to %s%s", in.Sid, filename, traced(ctx, in.Correlation))
+	return nil
+}
+
+// recordComposite records the video of a session composed into one WebM
+// track, laid out with the session's layout, canvas and roster, with its
+// audio mixed into another unless it's off
+func (s *server) recordComposite(ctx context.Context, in *pb.RecordStart) error {
+	cfg := in.Cfg
+	if f := cfg.GetFormat(); f != pb.RecordConfig_WEBM && f != pb.RecordConfig_MKV {
+		return fmt.Errorf("composite recordings are WebM, not %s", f)
+	}
+	// Not over a recording under legal hold, or its review copy
+	if err := s.avp.holds.Check(cfg.GetFilename()); err != nil {
+		return err
+	}
+	if review := s.avp.config.Review; review.Enabled {
+		if err := s.avp.holds.Check(reviewFilename(cfg.GetFilename(), review.Suffix)); err != nil {
+			return err
+		}
+	}
+	t, err := s.avp.getTransportLocked(in.Sfu, in.Sid, nil)
+	if err != nil {
+		return err
+	}
+	if s.avp.recordingRoom(in.Sfu, in.Sid, roomComposite, t) {
+		return errCompositeRecording
+	}
+	review := s.avp.config.Review
+	var outputs []elements.TeeOutput
+	if review.Enabled {
+		corner, err := pixel.ParseCorner(review.Corner)
+		if err != nil {
+			return err
+		}
+		// A copy with timecode burned in, encoded from the same frames
+		outputs = []elements.TeeOutput{
+			{Name: "archive"},
+			{Name: "review", Filters: []avp.Element{elements.NewTimecodeBurner(elements.TimecodeBurnerConfig{
+				Session:   in.Sid,
+				TimeOfDay: review.TimeOfDay,
+				Corner:    corner,
+			})}},
+		}
+	}
+	compositor, err := elements.NewVideoCompositor(elements.VideoCompositorConfig{
+		Layout:  t.Layout(),
+		Canvas:  t.Canvas(),
+		Roster:  t.Roster(),
+		Labels:  true,
+		Speaker: t.Dominant,
+		Outputs: outputs,
+	})
+	if err != nil {
+		return err
+	}
+	filename := cfg.GetFilename()
+	rec := s.avp.Recordings().StartCorrelated(in.Sid, "", filename, correlation(ctx, in.Correlation))
+	var epoch time.Time
+	if cfg.GetAlign() {
+		epoch = s.avp.Recordings().Epoch(in.Sid)
+	}
+	w, err := s.writer(filename, int(cfg.GetBuffersize()), cfg.GetKey())
+	if err != nil {
+		compositor.Close()
+		rec.Fail(err)
+		return err
+	}
+	w.SetRecording(rec)
+	var rw recordingWriter
+	if review.Enabled {
+		if rw, err = s.writer(reviewFilename(filename, review.Suffix), int(cfg.GetBuffersize()), cfg.GetKey()); err != nil {
+			w.Close()
+			compositor.Close()
+			rec.Fail(err)
+			return err
+		}
+	}
+	var mixer *elements.AudioMixer
+	if cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF {
+		channels := 1
+		if cfg.GetAudio() == pb.RecordConfig_AUDIO_STEREO {
+			channels = 2
+		}
+		if mixer, err = elements.NewAudioMixer(elements.AudioMixerConfig{Channels: channels}); err != nil {
+			log.Warnf("composite of session %s without audio: %v", in.Sid, err)
+		}
+	}
+	saver := elements.NewWebmSaver(&elements.WebmSaverConfig{
+		Audio:     mixer != nil,
+		Video:     true,
+		Recording: rec,
+		Epoch:     epoch,
+	})
+	saver.Attach(w)
+	room := rooms{compositor}
+	if rw != nil {
+		reviewSaver := elements.NewWebmSaver(&elements.WebmSaverConfig{
+			Audio: mixer != nil,
+			Video: true,
+			Epoch: epoch,
+		})
+		reviewSaver.Attach(rw)
+		compositor.Output("archive").Attach(saver)
+		compositor.Output("review").Attach(reviewSaver)
+		if mixer != nil {
+			mixer.Attach(reviewSaver)
+		}
+	} else {
+		compositor.Attach(saver)
+	}
+	if mixer != nil {
+		mixer.Attach(saver)
+		// Highlights who is speaking from the audio recorded, rather than
+		// the SFU's levels
+		detector := elements.NewAudioLevelDetector(elements.AudioLevelConfig{Tracks: t.StreamTracks})
+		detector.Attach(compositor)
+		room = append(room, mixer, detector)
+	}
+	if err := s.avp.runRoom(in.Sfu, in.Sid, roomComposite, t, room); err != nil {
+		room.Close()
+		rec.Fail(err)
+		return err
+	}
+	log.Infof("recording composite of session %s to %s%s", in.Sid, filename, traced(ctx, in.Correlation))
+	return nil
+}
+
+// reviewFilename returns the name of the review copy of a recording,
+// suffix before its extension
+func reviewFilename(filename, suffix string) string {
+	if suffix == "" {
+		suffix = ".review"
+	}
+	ext := path.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + suffix + ext
+}
@@ -1,16 +1,29 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"path/filepath"
+	"time"
 
 	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
 	avp "github.com/pion/ion-avp/pkg"
 	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/redundancy"
 	log "github.com/pion/ion-log"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+var priorities = map[pb.Priority]avp.Priority{
+	pb.Priority_NORMAL: avp.PriorityNormal,
+	pb.Priority_LOW:    avp.PriorityLow,
+	pb.Priority_HIGH:   avp.PriorityHigh,
+}
+
 type server struct {
 	pb.UnimplementedAVPServer
 	avp *AVP
@@ -22,6 +35,32 @@ func NewAVPServer(conf avp.Config, elems map[string]avp.ElementFun) pb.AVPServer
 	}
 }
 
+// correlationHeader is the grpc metadata key of the correlation ID of
+// requests that don't set one in the message
+const correlationHeader = "x-correlation-id"
+
+// correlation returns the correlation ID of a request, from the message
+// or else the stream's metadata
+func correlation(ctx context.Context, id string) string {
+	if id != "" {
+		return id
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(correlationHeader); len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// traced formats the correlation ID of a request for log messages
+func traced(ctx context.Context, id string) string {
+	if id = correlation(ctx, id); id != "" {
+		return " [" + id + "]"
+	}
+	return ""
+}
+
 // Signal handler for avp server
 func (s *server) Signal(stream pb.AVP_SignalServer) error {
 	for {
@@ -51,37 +90,25 @@ func (s *server) Signal(stream pb.AVP_SignalServer) error {
 				payload.Process.Tid,
 				payload.Process.Eid,
 				payload.Process.Config,
+				priorities[payload.Process.Priority],
+				correlation(stream.Context(), payload.Process.Correlation),
 			); err != nil {
-				log.Errorf("process error: %v", err)
+				log.Errorf("process error%s: %v", traced(stream.Context(), payload.Process.Correlation), err)
 			}
 
 		case *pb.SignalRequest_RecordStart:
-			cfg := payload.RecordStart.Cfg
-			switch cfg.GetFormat() {
-			case pb.RecordConfig_WEBM:
-				webm := elements.NewWebmSaver(
-					&elements.WebmSaverConfig{
-						// TODO MONO vs STEREO
-						Audio: cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF,
-						Video: cfg.GetVideo() == pb.RecordConfig_VIDEO_ON,
-					},
-				)
-				filewriter := elements.NewFileWriter(
-					cfg.GetFilename(),
-					int(cfg.GetBuffersize()),
-				)
-				webm.Attach(filewriter)
-
-				if err = s.avp.Run(
-					payload.RecordStart.Sfu,
-					payload.RecordStart.Sid,
-					payload.RecordStart.Tid,
-					webm,
-				); err != nil {
-					log.Errorf("RecordStart Run error: %v", err)
-				}
-			default:
-				log.Errorf("RecordStart: unknown format %s", cfg.GetFormat())
+			if err := s.record(stream.Context(), payload.RecordStart); err != nil {
+				log.Errorf("RecordStart error%s: %v", traced(stream.Context(), payload.RecordStart.Correlation), err)
+			}
+
+		case *pb.SignalRequest_RecordPause:
+			if err := s.recording(payload.RecordPause.Sid, payload.RecordPause.Tid).Pause(); err != nil {
+				log.Errorf("RecordPause error%s: %v", traced(stream.Context(), payload.RecordPause.Correlation), err)
+			}
+
+		case *pb.SignalRequest_RecordResume:
+			if err := s.recording(payload.RecordResume.Sid, payload.RecordResume.Tid).Resume(); err != nil {
+				log.Errorf("RecordResume error%s: %v", traced(stream.Context(), payload.RecordResume.Correlation), err)
 			}
 
 		case *pb.SignalRequest_RecordStop:
@@ -91,8 +118,299 @@ func (s *server) Signal(stream pb.AVP_SignalServer) error {
 				payload.RecordStop.Tid,
 			)
 			if err != nil {
-				log.Errorf("RecordStop error: %v", err)
+				log.Errorf("RecordStop error%s: %v", traced(stream.Context(), payload.RecordStop.Correlation), err)
+			}
+		}
+	}
+}
+
+// recordingWriter writes a recording file and moves its recording along
+type recordingWriter interface {
+	avp.Element
+	SetRecording(*recording.Recording)
+}
+
+// encryptedWriter encrypts a recording file as it is written
+type encryptedWriter struct {
+	*elements.EncryptWriter
+	w recordingWriter
+}
+
+func (e *encryptedWriter) SetRecording(r *recording.Recording) {
+	e.w.SetRecording(r)
+}
+
+// writer opens a recording file in storage, or on disk without storage,
+// encrypted if given a key. Files of recordings under legal hold aren't
+// opened, as that would truncate them.
+func (s *server) writer(filename string, bufSize int, key []byte) (recordingWriter, error) {
+	if err := s.avp.holds.Check(filename); err != nil {
+		return nil, err
+	}
+	var w recordingWriter
+	if store := s.avp.Storage(); store != nil {
+		sw := elements.NewStorageWriter(store, filename)
+		if sw == nil {
+			return nil, fmt.Errorf("opening %s in storage", filename)
+		}
+		sw.SignURL(s.avp.config.Storage.URLExpiry)
+		w = sw
+	} else {
+		fw, err := elements.NewFileWriterWithConfig(elements.FileWriterConfig{
+			Path:    filename,
+			BufSize: bufSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", filename, err)
+		}
+		w = fw
+	}
+	if len(key) == 0 {
+		return w, nil
+	}
+	enc, err := elements.NewEncryptWriter(elements.EncryptWriterConfig{Key: key})
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("encrypting %s: %w", filename, err)
+	}
+	enc.Attach(w)
+	return &encryptedWriter{EncryptWriter: enc, w: w}, nil
+}
+
+// sink returns where the segments and manifests of a streamed recording
+// are stored, in storage or a directory on disk without storage, sealed
+// if given a key
+func (s *server) sink(filename string, key []byte) (elements.Sink, error) {
+	var sink elements.Sink = elements.DirSink(filename)
+	if store := s.avp.Storage(); store != nil {
+		sink = elements.NewStorageSink(store, filename)
+	}
+	if len(key) == 0 {
+		return sink, nil
+	}
+	sealed, err := elements.NewSealedSink(sink, key)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting %s: %w", filename, err)
+	}
+	return sealed, nil
+}
+
+// gapPolicy returns the gap policy of a recording, or the node's default
+func (s *server) gapPolicy(cfg *pb.RecordConfig) (elements.GapPolicy, time.Duration, error) {
+	name, maxGap := cfg.GetGaps(), time.Duration(cfg.GetMaxgap())*time.Millisecond
+	if name == "" {
+		name = s.avp.config.Recording.Gaps
+	}
+	if maxGap <= 0 {
+		maxGap = s.avp.config.Recording.MaxGap
+	}
+	policy, err := elements.ParseGapPolicy(name)
+	return policy, maxGap, err
+}
+
+// record starts recording a track, failing the recording if it can't
+func (s *server) record(ctx context.Context, in *pb.RecordStart) error {
+	cfg := in.Cfg
+	if cfg.GetRoom() {
+		return s.recordRoom(ctx, in)
+	}
+	if cfg.GetComposite() {
+		return s.recordComposite(ctx, in)
+	}
+	if f := cfg.GetFormat(); f != pb.RecordConfig_WEBM && f != pb.RecordConfig_MP4 && f != pb.RecordConfig_MKV && f != pb.RecordConfig_HLS && f != pb.RecordConfig_DASH && f != pb.RecordConfig_CMAF {
+		return fmt.Errorf("unknown format %s", f)
+	}
+	filename := cfg.GetFilename()
+	backup := cfg.GetRedundancy().GetRole() == pb.Redundancy_BACKUP
+	if backup {
+		filename = redundancy.BackupName(filename)
+	}
+	// Not over a recording under legal hold
+	if err := s.avp.holds.Check(filename); err != nil {
+		return err
+	}
+	corr := correlation(ctx, in.Correlation)
+	rec := s.avp.Recordings().StartCorrelated(in.Sid, in.Tid, filename, corr)
+	if backup {
+		s.avp.backup(rec, cfg.GetRedundancy().GetPeer())
+	}
+	var epoch time.Time
+	if cfg.GetAlign() {
+		epoch = s.avp.Recordings().Epoch(in.Sid)
+	}
+	gaps, maxGap, err := s.gapPolicy(cfg)
+	if err != nil {
+		rec.Fail(err)
+		return err
+	}
+	// Streamed formats have their own manifests
+	var sidecar avp.Element
+	if f := cfg.GetFormat(); cfg.GetMetadata() && (f == pb.RecordConfig_WEBM || f == pb.RecordConfig_MKV || f == pb.RecordConfig_MP4) {
+		w, err := s.writer(filename+".json", 0, cfg.GetKey())
+		if err != nil {
+			rec.Fail(err)
+			return err
+		}
+		sidecar = w
+	}
+	saverCfg := elements.WebmSaverConfig{
+		// TODO MONO vs STEREO
+		Audio:     cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF,
+		Video:     cfg.GetVideo() == pb.RecordConfig_VIDEO_ON,
+		Recording: rec,
+		Epoch:     epoch,
+		Colour:    s.avp.config.Colour,
+		PauseGap:  time.Duration(cfg.GetPausegap()) * time.Millisecond,
+		Gaps:      gaps,
+		MaxGap:    maxGap,
+		Sidecar:   sidecar,
+	}
+	// A WebM file's track keeps the size it started at, so files of video
+	// are rotated where it changes resolution, e.g. on a simulcast layer
+	// switch
+	rotate := cfg.GetRotate() || saverCfg.Video
+	var saver avp.Element
+	written := false // by the saver itself
+	var sink elements.Sink
+	if f := cfg.GetFormat(); f == pb.RecordConfig_HLS || f == pb.RecordConfig_DASH || f == pb.RecordConfig_CMAF {
+		if sink, err = s.sink(filename, cfg.GetKey()); err != nil {
+			if sidecar != nil {
+				sidecar.Close()
 			}
+			rec.Fail(err)
+			return err
+		}
+	}
+	switch {
+	case cfg.GetFormat() == pb.RecordConfig_HLS:
+		var enc *elements.HlsEncryption
+		if e := cfg.GetEncryption(); e != nil {
+			enc = &elements.HlsEncryption{KeyURI: e.GetKeyuri(), Rotate: int(e.GetRotate())}
+		}
+		saver = elements.NewHlsSaver(elements.HlsSaverConfig{
+			Audio:      cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF,
+			Video:      cfg.GetVideo() == pb.RecordConfig_VIDEO_ON,
+			Recording:  rec,
+			Sink:       sink,
+			Encryption: enc,
+			Counter:    s.avp.counter("hls:" + filename),
+		})
+		written = true
+	case cfg.GetFormat() == pb.RecordConfig_DASH || cfg.GetFormat() == pb.RecordConfig_CMAF:
+		saver = elements.NewDashSaver(elements.DashSaverConfig{
+			Audio:     cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF,
+			Video:     cfg.GetVideo() == pb.RecordConfig_VIDEO_ON,
+			Recording: rec,
+			Live:      cfg.GetLive(),
+			Sink:      sink,
+			HLS:       cfg.GetFormat() == pb.RecordConfig_CMAF,
+		})
+		written = true
+	case cfg.GetFormat() == pb.RecordConfig_MP4:
+		saver = elements.NewMp4Saver(&elements.Mp4SaverConfig{
+			Audio:     cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF,
+			Video:     cfg.GetVideo() == pb.RecordConfig_VIDEO_ON,
+			Recording: rec,
+			Sidecar:   sidecar,
+		})
+	case cfg.GetSegment() > 0 || rotate:
+		// Segments are written by writers of their own
+		saver = elements.NewSegmentedSaver(elements.SegmentedSaverConfig{
+			Saver:              saverCfg,
+			MaxSegmentDuration: time.Duration(cfg.GetSegment()) * time.Second,
+			Filename:           filename,
+			Open: func(name string) (avp.Element, error) {
+				return s.writer(name, int(cfg.GetBuffersize()), cfg.GetKey())
+			},
+			Counter: s.avp.counter("segments:" + filename),
+		})
+		written = true
+	default:
+		saver = elements.NewWebmSaver(&saverCfg)
+	}
+	if !written {
+		w, err := s.writer(filename, int(cfg.GetBuffersize()), cfg.GetKey())
+		if err != nil {
+			// Failed first, so closing doesn't complete the recording
+			rec.Fail(err)
+			saver.Close()
+			return err
 		}
+		w.SetRecording(rec)
+		saver.Attach(w)
+	}
+
+	root := saver
+	if v := s.avp.config.VAD; v.Enabled && cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF && cfg.GetVideo() != pb.RecordConfig_VIDEO_ON {
+		vd := elements.NewVoiceDetector(elements.VoiceDetectorConfig{
+			Threshold: v.Threshold,
+			Hangover:  v.Hangover,
+			Gate:      v.Gate,
+		}, in.Tid, s.avp.sidecar(filename+".speech.json", cfg.GetKey()))
+		vd.Attach(root)
+		root = vd
+	}
+	var hd *elements.HighlightDetector
+	if h := s.avp.config.Highlights; h.Enabled && cfg.GetAudio() != pb.RecordConfig_AUDIO_OFF {
+		hd = elements.NewHighlightDetector(elements.HighlightConfig{
+			Window:  h.Window,
+			Count:   h.Count,
+			Weights: h.Weights,
+		}, in.Tid, s.avp.sidecar(filename+".highlights.json", cfg.GetKey()))
+		hd.SetCorrelation(corr)
+		hd.Attach(root)
+		root = hd
+	}
+	if pc := s.avp.config.Preview; pc.Enabled && cfg.GetVideo() == pb.RecordConfig_VIDEO_ON {
+		pcfg := elements.PreviewConfig{
+			Duration:  pc.Duration,
+			FrameRate: pc.FrameRate,
+			Width:     pc.Width,
+		}
+		if pc.Highlight && hd != nil {
+			pcfg.Highlights = hd
+		}
+		if p, err := elements.NewAnimatedPreview(pcfg, in.Tid, s.avp.sidecar(filename+".preview.gif", cfg.GetKey())); err != nil {
+			log.Warnf("no preview of %s: %v", filename, err)
+		} else {
+			p.Attach(root)
+			root = p
+		}
+	}
+	if sc := s.avp.config.Storyboard; sc.Enabled && cfg.GetVideo() == pb.RecordConfig_VIDEO_ON {
+		// Of files split only where the video resizes, which follow on
+		// from each other on the timeline the storyboard's times are of
+		if _, ok := saver.(*elements.SegmentedSaver); ok && cfg.GetSegment() == 0 {
+			sb := elements.NewStoryboard(elements.StoryboardConfig{
+				Interval: sc.Interval,
+				Width:    sc.Width,
+				Columns:  sc.Columns,
+				MaxTiles: sc.MaxTiles,
+				Sprite:   filepath.Base(filename) + ".storyboard.jpg",
+				Epoch:    epoch,
+			}, in.Tid, s.avp.sidecar(filename+".storyboard.jpg", cfg.GetKey()), s.avp.sidecar(filename+".storyboard.vtt", cfg.GetKey()))
+			sb.Attach(root)
+			root = sb
+		}
+	}
+	if s.avp.config.Quality.Interval > 0 {
+		qr := elements.NewQualityRecorder(in.Tid, s.avp.sidecar(filename+".quality.json", cfg.GetKey()))
+		qr.SetCorrelation(corr)
+		qr.Attach(root)
+		root = qr
+	}
+
+	if err := s.avp.Run(
+		in.Sfu,
+		in.Sid,
+		in.Tid,
+		avp.WithCorrelation(avp.WithPriority(root, priorities[in.Priority]), corr),
+	); err != nil {
+		// Failed first, so closing the saver, its writers and sidecars
+		// doesn't complete the recording
+		rec.Fail(err)
+		root.Close()
+		return fmt.Errorf("run: %w", err)
 	}
+	return nil
 }
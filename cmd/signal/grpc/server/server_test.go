@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/hold"
+	"github.com/pion/ion-avp/pkg/recording"
+	sfu "github.com/pion/ion-sfu/cmd/signal/grpc/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// unavailableSFU refuses to signal, so sessions that aren't joined can't
+// be
+type unavailableSFU struct {
+	sfu.SFUClient
+}
+
+func (unavailableSFU) Signal(context.Context, ...grpc.CallOption) (sfu.SFU_SignalClient, error) {
+	return nil, errors.New("sfu unavailable")
+}
+
+// newTestServer returns a server with the sessions joined on the sfu
+// "sfu", which can't join others
+func newTestServer(sessions map[string]*avp.WebRTCTransport) *server {
+	if sessions == nil {
+		sessions = make(map[string]*avp.WebRTCTransport)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var c avp.Config
+	return &server{avp: &AVP{
+		config: c,
+		clients: map[string]*SFU{"sfu": {
+			ctx:        ctx,
+			cancel:     cancel,
+			client:     unavailableSFU{},
+			config:     c,
+			transports: sessions,
+		}},
+		records: recording.NewTracker(c.Recording),
+		holds:   hold.New(nil),
+		rooms:   make(map[string]*room),
+	}}
+}
+
+func TestRecordRunFailureClosesWriters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := newTestServer(nil)
+	name := filepath.Join(dir, "a.webm")
+	err = s.record(context.Background(), &pb.RecordStart{
+		Sfu: "sfu",
+		Sid: "sid",
+		Tid: "audio",
+		Cfg: &pb.RecordConfig{Filename: name, Metadata: true},
+	})
+	assert.Error(t, err)
+
+	// Failed, not completed by closing its writers
+	rec, ok := s.avp.Recordings().Get("sid", "audio")
+	assert.True(t, ok)
+	assert.Equal(t, recording.StateFailed, rec.Status().State)
+
+	// The sidecar is written on close
+	b, err := ioutil.ReadFile(name + ".json")
+	assert.NoError(t, err)
+	var meta elements.Metadata
+	assert.NoError(t, json.Unmarshal(b, &meta))
+	assert.Equal(t, "sid", meta.Session)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"sort"
 	"sync"
 
 	avp "github.com/pion/ion-avp/pkg"
@@ -30,7 +31,8 @@ type SFU struct {
 func NewSFU(addr string, config avp.Config) (*SFU, error) {
 	log.Infof("Connecting to sfu: %s", addr)
 	// Set up a connection to the sfu server.
-	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithBlock())
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(config.Family().Dialer()))
 	if err != nil {
 		log.Errorf("did not connect: %v", err)
 		return nil, err
@@ -74,6 +76,37 @@ func (s *SFU) GetTransport(sid string) (*avp.WebRTCTransport, error) {
 	return t, nil
 }
 
+// Transport returns the webrtc transport of a session if joined, without
+// joining it
+func (s *SFU) Transport(sid string) *avp.WebRTCTransport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.transports[sid]
+}
+
+// Transports returns the webrtc transports of the client
+func (s *SFU) Transports() []*avp.WebRTCTransport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	transports := make([]*avp.WebRTCTransport, 0, len(s.transports))
+	for _, t := range s.transports {
+		transports = append(transports, t)
+	}
+	return transports
+}
+
+// Sessions returns the ids of the sessions joined
+func (s *SFU) Sessions() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sids := make([]string, 0, len(s.transports))
+	for sid := range s.transports {
+		sids = append(sids, sid)
+	}
+	sort.Strings(sids)
+	return sids
+}
+
 // OnClose handler called when sfu client is closed
 func (s *SFU) OnClose(f func()) {
 	s.onCloseFn = f
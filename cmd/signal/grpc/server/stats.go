@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/retry"
+)
+
+// Stats reports per element resource usage, so operators can see which
+// elements load the node
+func (s *server) Stats(ctx context.Context, in *pb.StatsRequest) (*pb.StatsReply, error) {
+	reply := &pb.StatsReply{Pressure: avp.Pressure()}
+	for _, st := range s.avp.Stats() {
+		priority := pb.Priority_NORMAL
+		for p, v := range priorities {
+			if v == st.Priority {
+				priority = p
+			}
+		}
+		reply.Elements = append(reply.Elements, &pb.ElementStats{
+			Pid:         st.ID,
+			Sid:         st.Session,
+			Tid:         st.Track,
+			Element:     st.Element,
+			Priority:    priority,
+			Samples:     st.Samples,
+			Dropped:     st.Dropped,
+			Busy:        int64(st.Busy),
+			Cpu:         int64(st.CPU),
+			Allocated:   st.Allocated,
+			Correlation: st.Correlation,
+		})
+	}
+	reply.Recordings = recordings(s.avp.Recordings().List(""))
+	for _, st := range retry.All() {
+		reply.Retries = append(reply.Retries, &pb.RetryStats{
+			Name:     st.Name,
+			Calls:    st.Calls,
+			Retries:  st.Retries,
+			Failures: st.Failures,
+			Rejected: st.Rejected,
+			Open:     st.Open,
+		})
+	}
+	return reply, nil
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/redundancy"
+	"google.golang.org/grpc"
+)
+
+// validateTimeout bounds each reachability check
+const validateTimeout = 5 * time.Second
+
+// validation collects the problems of a pipeline
+type validation struct {
+	reply *pb.ValidateReply
+}
+
+func (v *validation) error(code pb.ValidationError_Code, field, format string, args ...interface{}) {
+	v.reply.Errors = append(v.reply.Errors, &pb.ValidationError{Code: code, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validation) warn(code pb.ValidationError_Code, field, format string, args ...interface{}) {
+	v.reply.Warnings = append(v.reply.Warnings, &pb.ValidationError{Code: code, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidatePipeline checks a pipeline against the session's tracks and the
+// node without starting it, so app servers can fail fast
+func (s *server) ValidatePipeline(ctx context.Context, in *pb.ValidateRequest) (*pb.ValidateReply, error) {
+	v := &validation{reply: &pb.ValidateReply{}}
+	switch {
+	case in.GetProcess() != nil:
+		s.validateProcess(v, in.GetProcess())
+	case in.GetRecord() != nil:
+		s.validateRecord(ctx, v, in.GetRecord())
+	default:
+		v.error(pb.ValidationError_INVALID, "", "set process or record")
+	}
+	v.reply.Valid = len(v.reply.Errors) == 0
+	return v.reply, nil
+}
+
+func (s *server) validateProcess(v *validation, p *pb.Process) {
+	s.validateTrack(v, "process", p.GetSfu(), p.GetSid(), p.GetTid())
+	if p.GetPid() == "" {
+		v.error(pb.ValidationError_INVALID, "process.pid", "pid is required")
+	}
+	switch err := avp.CheckElement(p.GetEid()); err {
+	case avp.ErrElementNotFound:
+		v.error(pb.ValidationError_ELEMENT_NOT_FOUND, "process.eid", "element %q isn't registered on this node", p.GetEid())
+	case avp.ErrElementDisabled:
+		v.error(pb.ValidationError_ELEMENT_DISABLED, "process.eid", "element %q is disabled in low memory mode, use a node with more memory", p.GetEid())
+	}
+	s.validatePriority(v, "process.priority", p.GetPriority())
+}
+
+func (s *server) validateRecord(ctx context.Context, v *validation, r *pb.RecordStart) {
+	var codec string
+	if r.GetCfg().GetRoom() || r.GetCfg().GetComposite() {
+		s.validateRoom(v, r)
+	} else {
+		codec = s.validateTrack(v, "record", r.GetSfu(), r.GetSid(), r.GetTid())
+	}
+	cfg := r.GetCfg()
+	if cfg == nil {
+		v.error(pb.ValidationError_INVALID, "record.cfg", "cfg is required")
+		return
+	}
+	if cfg.GetAudio() == pb.RecordConfig_AUDIO_OFF && cfg.GetVideo() == pb.RecordConfig_VIDEO_OFF {
+		v.error(pb.ValidationError_INVALID, "record.cfg", "audio and video are both off, nothing would be recorded")
+	}
+	format, video, videos := "WebM", "VP8, VP9 or AV1", []string{avp.MimeTypeVP8, avp.MimeTypeVP9, avp.MimeTypeAV1}
+	switch cfg.GetFormat() {
+	case pb.RecordConfig_MP4:
+		format, video, videos = "MP4", "H.264", []string{avp.MimeTypeH264}
+	case pb.RecordConfig_HLS:
+		format, video, videos = "HLS", "H.264", []string{avp.MimeTypeH264}
+	case pb.RecordConfig_DASH, pb.RecordConfig_CMAF:
+		format, video, videos = cfg.GetFormat().String(), "H.264", []string{avp.MimeTypeH264}
+	case pb.RecordConfig_MKV:
+		format, video, videos = "MKV", "H.264, VP8, VP9 or AV1", []string{avp.MimeTypeH264, avp.MimeTypeVP8, avp.MimeTypeVP9, avp.MimeTypeAV1}
+	}
+	if mime := strings.ToLower(codec); mime != "" {
+		switch {
+		case strings.HasPrefix(mime, "audio/") && mime != strings.ToLower(avp.MimeTypeOpus):
+			v.error(pb.ValidationError_CODEC_UNSUPPORTED, "record.tid", "track is %s, %s recordings need Opus audio", codec, format)
+		case strings.HasPrefix(mime, "video/") && !oneOf(mime, videos):
+			v.error(pb.ValidationError_CODEC_UNSUPPORTED, "record.tid", "track is %s, %s recordings need %s video", codec, format, video)
+		case strings.HasPrefix(mime, "audio/") && cfg.GetVideo() == pb.RecordConfig_VIDEO_ON:
+			v.error(pb.ValidationError_INVALID, "record.cfg.video", "track is audio, with video on the recording waits for video forever, set VIDEO_OFF")
+		}
+	}
+	if _, _, err := s.gapPolicy(cfg); err != nil {
+		v.error(pb.ValidationError_INVALID, "record.cfg.gaps", "%v, use keep, fill, clamp or rebase", err)
+	}
+	s.validateDestination(v, cfg.GetFilename())
+	if red := cfg.GetRedundancy(); red.GetRole() == pb.Redundancy_BACKUP {
+		s.validatePeer(ctx, v, red.GetPeer(), r.GetSid(), r.GetTid())
+	}
+	s.validatePriority(v, "record.priority", r.GetPriority())
+}
+
+// validateRoom checks a recording of every track of a session, or of its
+// composite, which needs no track
+func (s *server) validateRoom(v *validation, r *pb.RecordStart) {
+	for _, id := range []struct{ name, value string }{{"sfu", r.GetSfu()}, {"sid", r.GetSid()}} {
+		if id.value == "" {
+			v.error(pb.ValidationError_INVALID, "record."+id.name, "%s is required", id.name)
+		}
+	}
+	if f := r.GetCfg().GetFormat(); f != pb.RecordConfig_WEBM && f != pb.RecordConfig_MKV {
+		v.error(pb.ValidationError_INVALID, "record.cfg.format", "room and composite recordings are WebM or MKV, not %s", f)
+	}
+	if r.GetCfg().GetComposite() && r.GetCfg().GetVideo() == pb.RecordConfig_VIDEO_OFF {
+		v.error(pb.ValidationError_INVALID, "record.cfg.video", "composite recordings are of video, set VIDEO_ON")
+	}
+}
+
+// oneOf reports whether a lower case mime type is one of mimes
+func oneOf(mime string, mimes []string) bool {
+	for _, m := range mimes {
+		if mime == strings.ToLower(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTrack checks the ids of a pipeline and returns the mime type
+// of its track, or "" if it hasn't arrived
+func (s *server) validateTrack(v *validation, field, sfu, sid, tid string) string {
+	for _, id := range []struct{ name, value string }{{"sfu", sfu}, {"sid", sid}, {"tid", tid}} {
+		if id.value == "" {
+			v.error(pb.ValidationError_INVALID, field+"."+id.name, "%s is required", id.name)
+		}
+	}
+	if t := s.avp.transport(sfu, sid); t != nil {
+		if codec, ok := t.Codec(tid); ok {
+			return codec.MimeType
+		}
+	}
+	v.warn(pb.ValidationError_TRACK_PENDING, field+".tid", "track %s hasn't arrived on this node, the pipeline starts when it does", tid)
+	return ""
+}
+
+func (s *server) validateDestination(v *validation, filename string) {
+	if filename == "" {
+		v.error(pb.ValidationError_INVALID, "record.cfg.filename", "filename is required")
+		return
+	}
+	if store := s.avp.Storage(); store != nil {
+		done := make(chan error, 1)
+		go func() {
+			_, err := store.List(filename)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				v.error(pb.ValidationError_STORAGE_UNREACHABLE, "record.cfg.filename", "listing %s in storage: %v, check storage credentials", filename, err)
+			}
+		case <-time.After(validateTimeout):
+			v.error(pb.ValidationError_STORAGE_UNREACHABLE, "record.cfg.filename", "listing %s in storage timed out", filename)
+		}
+		return
+	}
+
+	dir := filepath.Dir(filename)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		v.error(pb.ValidationError_DESTINATION, "record.cfg.filename", "directory %s doesn't exist on this node", dir)
+		return
+	}
+	f, err := ioutil.TempFile(dir, ".validate-")
+	if err != nil {
+		v.error(pb.ValidationError_DESTINATION, "record.cfg.filename", "directory %s isn't writable: %v", dir, err)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	if _, err := os.Stat(filename); err == nil {
+		v.warn(pb.ValidationError_DESTINATION, "record.cfg.filename", "%s exists and would be overwritten", filename)
+	}
+}
+
+func (s *server) validatePeer(ctx context.Context, v *validation, addr, sid, tid string) {
+	if addr == "" {
+		v.error(pb.ValidationError_INVALID, "record.cfg.redundancy.peer", "backups need the address of their primary")
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, validateTimeout)
+	defer cancel()
+	cc, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(s.avp.config.Family().Dialer()))
+	if err != nil {
+		v.error(pb.ValidationError_PEER_UNREACHABLE, "record.cfg.redundancy.peer", "dialing primary %s: %v", addr, err)
+		return
+	}
+	defer cc.Close()
+	if _, err := (peer{client: pb.NewAVPClient(cc)}).Recording(ctx, sid, tid); err != nil && !errors.Is(err, redundancy.ErrNotFound) {
+		v.error(pb.ValidationError_PEER_UNREACHABLE, "record.cfg.redundancy.peer", "asking primary %s for recordings: %v", addr, err)
+	}
+}
+
+func (s *server) validatePriority(v *validation, field string, p pb.Priority) {
+	if avp.Overloaded(priorities[p]) {
+		v.error(pb.ValidationError_OVERLOADED, field, "node is shedding %s priority samples at %.0f%% pressure, use another node or a higher priority", priorities[p], avp.Pressure()*100)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/stitch"
+	"github.com/pion/ion-avp/pkg/stitch/mp4"
+)
+
+func showHelp() {
+	fmt.Printf("Usage:%s {params}\n", os.Args[0])
+	fmt.Println("      -m {recording manifest}")
+	fmt.Println("      -o {output file, .webm, .mkv or .mp4}")
+	fmt.Println("      -h (show help info)")
+}
+
+func run(manifestFile, output string) error {
+	f, err := os.Open(manifestFile)
+	if err != nil {
+		return err
+	}
+	m, err := manifest.Read(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	open := manifest.Dir(filepath.Dir(manifestFile))
+	if err := m.Verify(open); err != nil {
+		return err
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if mp4.Supported(output) {
+		err = mp4.Render(context.Background(), out, m, open, nil)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	} else {
+		err = stitch.Stitch(context.Background(), out, output, m, open, nil)
+	}
+	if err != nil {
+		out.Close()
+		os.Remove(output)
+		return err
+	}
+	return nil
+}
+
+func main() {
+	manifestFile := flag.String("m", "", "recording manifest")
+	output := flag.String("o", "", "output file")
+	help := flag.Bool("h", false, "help info")
+	flag.Parse()
+
+	if *help || *manifestFile == "" || *output == "" {
+		showHelp()
+		os.Exit(-1)
+	}
+
+	if err := run(*manifestFile, *output); err != nil {
+		fmt.Printf("stitch failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("wrote %s\n", *output)
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"path"
+	"path/filepath"
 
 	pb "github.com/pion/ion-avp/cmd/signal/grpc/proto"
 	"github.com/pion/ion-avp/cmd/signal/grpc/server"
@@ -34,7 +34,7 @@ var (
 
 func createWebmSaver(sid, pid, tid string, config []byte) avp.Element {
 	filewriter := elements.NewFileWriter(
-		path.Join(conf.Webmsaver.Path, fmt.Sprintf("%s-%s.webm", sid, pid)),
+		filepath.Join(conf.Webmsaver.Path, fmt.Sprintf("%s-%s.webm", sid, pid)),
 		4096,
 	)
 	webm := elements.NewWebmSaver(nil)
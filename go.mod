@@ -15,6 +15,8 @@ require (
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.7.0
 	github.com/xlab/libvpx-go v0.0.0-20201217121537-9736e1703824
+	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad
+	golang.org/x/sys v0.0.0-20210217090653-ed5674b6da4a
 	google.golang.org/grpc v1.35.0
 	google.golang.org/protobuf v1.25.0
 )
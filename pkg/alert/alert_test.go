@@ -0,0 +1,143 @@
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type source struct {
+	pipelines []Pipeline
+	tracks    []Track
+}
+
+func (s *source) Pipelines() []Pipeline { return s.pipelines }
+func (s *source) Tracks() []Track       { return s.tracks }
+
+type recorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recorder) notify(ctx context.Context, a Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func (r *recorder) take() []Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a := r.alerts
+	r.alerts = nil
+	return a
+}
+
+func TestEngine(t *testing.T) {
+	src := &source{}
+	e := NewEngine(Config{NoSamples: 30 * time.Second, Loss: 0.05, DiskMinGB: 1}, src)
+	var rec recorder
+	e.notify = []Notifier{rec.notify}
+	free := uint64(10 << 30)
+	e.free = func(string) (uint64, error) { return free, nil }
+
+	now := time.Now()
+	src.pipelines = []Pipeline{{Key: "s/t/p", Samples: 10}}
+	src.tracks = []Track{{Key: "s/t", Packets: 100}}
+	e.Evaluate(now)
+	assert.Empty(t, rec.take())
+
+	// The pipeline stalls, loss jumps and the disk fills up
+	src.tracks = []Track{{Key: "s/t", Packets: 180, Lost: 20}}
+	free = 512 << 20
+	e.Evaluate(now.Add(30 * time.Second))
+	alerts := rec.take()
+	assert.Len(t, alerts, 3)
+	assert.ElementsMatch(t, alerts, e.Active())
+	for _, a := range alerts {
+		assert.Equal(t, Firing, a.State)
+	}
+	assert.Equal(t, RuleNoSamples, alerts[0].Rule)
+	assert.Equal(t, "s/t/p", alerts[0].Key)
+	assert.Equal(t, RuleLoss, alerts[1].Rule)
+	assert.Equal(t, "s/t", alerts[1].Key)
+	assert.InDelta(t, 0.2, alerts[1].Value, 1e-9)
+	assert.Equal(t, RuleDisk, alerts[2].Rule)
+	assert.InDelta(t, 0.5, alerts[2].Value, 1e-9)
+
+	// Firing alerts aren't repeated
+	src.tracks = []Track{{Key: "s/t", Packets: 260, Lost: 40}}
+	e.Evaluate(now.Add(40 * time.Second))
+	assert.Empty(t, rec.take())
+
+	// Everything recovers
+	src.pipelines = []Pipeline{{Key: "s/t/p", Samples: 11}}
+	src.tracks = []Track{{Key: "s/t", Packets: 360, Lost: 40}}
+	free = 10 << 30
+	e.Evaluate(now.Add(50 * time.Second))
+	alerts = rec.take()
+	assert.Len(t, alerts, 3)
+	for _, a := range alerts {
+		assert.Equal(t, Resolved, a.State)
+	}
+	assert.Empty(t, e.Active())
+}
+
+func TestFreeSpace(t *testing.T) {
+	free, err := freeSpace(".")
+	assert.NoError(t, err)
+	assert.True(t, free > 0)
+}
+
+func TestNATS(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	got := make(chan []string, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("INFO {}\r\n"))
+		rd := bufio.NewReader(conn)
+		var lines []string
+		for {
+			line, err := rd.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			if line == "PING" {
+				conn.Write([]byte("PONG\r\n"))
+				got <- lines
+				return
+			}
+			lines = append(lines, line)
+		}
+	}()
+
+	n := NATS("nats://alice:secret@"+lis.Addr().String(), "avp.alerts")
+	assert.NoError(t, n(context.Background(), Alert{Rule: RuleDisk, Key: "/", State: Firing}))
+
+	lines := <-got
+	assert.Len(t, lines, 3)
+	var connect natsConnect
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[0], "CONNECT ")), &connect))
+	assert.Equal(t, "alice", connect.User)
+	assert.Equal(t, "secret", connect.Pass)
+	assert.True(t, strings.HasPrefix(lines[1], "PUB avp.alerts "))
+	var a Alert
+	assert.NoError(t, json.Unmarshal([]byte(lines[2]), &a))
+	assert.Equal(t, RuleDisk, a.Rule)
+}
@@ -0,0 +1,321 @@
+// Package archive indexes the recordings a node has produced, so small
+// deployments can look them up by session, track, time or transcript
+// without running a catalog service of their own.
+//
+// The index is a file of JSON lines, one per change of a recording, the
+// last line of each recording winning. It is compacted to a line each
+// when opened. Transcripts are indexed by word for SearchText, their cues
+// kept alongside in a file of their own.
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// Entry describes a recording of the archive
+type Entry struct {
+	ID      string          `json:"id"`
+	Session string          `json:"sid"`
+	Track   string          `json:"tid"`
+	Name    string          `json:"name"`
+	State   recording.State `json:"state"`
+	// Start and End are the wall-clock times the media spans, or the
+	// recording started and last changed before its segments are known
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Locations are the files or objects holding the media, the
+	// recording's name or its segments'
+	Locations []string `json:"locations"`
+	// Segments counts the files of a recording split into several
+	Segments int `json:"segments,omitempty"`
+	// Transcripts are the files of transcripts of the recording
+	Transcripts []string `json:"transcripts,omitempty"`
+}
+
+// Duration returns the time the recording spans
+func (e *Entry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Query selects entries of the archive, those matching every field set
+// Session: Of the session.
+// Track: Of the track.
+// From, To: Spanning some of the time from From to To.
+// Name: With names containing it.
+// Transcript: Only those with a transcript.
+// Limit: How many of the latest matching entries to return.
+type Query struct {
+	Session    string
+	Track      string
+	From, To   time.Time
+	Name       string
+	Transcript bool
+	Limit      int
+}
+
+func (q *Query) match(e *Entry) bool {
+	switch {
+	case q.Session != "" && e.Session != q.Session:
+		return false
+	case q.Track != "" && e.Track != q.Track:
+		return false
+	case !q.From.IsZero() && e.End.Before(q.From):
+		return false
+	case !q.To.IsZero() && e.Start.After(q.To):
+		return false
+	case q.Name != "" && !strings.Contains(e.Name, q.Name):
+		return false
+	case q.Transcript && len(e.Transcripts) == 0:
+		return false
+	}
+	return true
+}
+
+// Index is the archive of recordings, kept in a file
+type Index struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	entries map[string]*Entry
+	// transcripts, and the cues of them each word is in
+	tf          *os.File
+	transcripts []*transcript
+	words       map[string][]posting
+}
+
+// Open loads the index of the file at path, created if it doesn't exist.
+// Transcripts are kept next to it, in path.transcripts.
+func Open(path string) (*Index, error) {
+	x := &Index{path: path, entries: make(map[string]*Entry)}
+	err := readLines(path, func(b []byte) error {
+		var e Entry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return err
+		}
+		x.entries[e.ID] = &e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := x.compact(); err != nil {
+		return nil, err
+	}
+	if err := x.openTranscripts(); err != nil {
+		x.f.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+// readLines calls line with each line of the file at path, if it exists.
+// A line cut short by a crash while it was appended is skipped.
+func readLines(path string, line func(b []byte) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if err := line(scanner.Bytes()); err != nil {
+			log.Warnf("archive %s: skipping damaged line: %s", path, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// compact rewrites the file with a line per entry, and opens it for
+// appending
+func (x *Index) compact() error {
+	var err error
+	x.f, err = rewrite(x.path, func(enc *json.Encoder) error {
+		for _, e := range x.sorted() {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// rewrite writes the lines of a file through a temporary one renamed over
+// it, so a crash leaves the old or the new, and opens it for appending
+func rewrite(path string, lines func(enc *json.Encoder) error) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	if err := lines(json.NewEncoder(w)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+}
+
+// sorted returns the entries by start, then ID
+func (x *Index) sorted() []*Entry {
+	list := make([]*Entry, 0, len(x.entries))
+	for _, e := range x.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Start.Equal(list[j].Start) {
+			return list[i].Start.Before(list[j].Start)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Update changes the entry of recording id with f, adding it if new, and
+// persists it
+func (x *Index) Update(id string, f func(e *Entry)) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	e, ok := x.entries[id]
+	if !ok {
+		e = &Entry{ID: id}
+	}
+	next := *e
+	next.Locations = append([]string(nil), e.Locations...)
+	next.Transcripts = append([]string(nil), e.Transcripts...)
+	f(&next)
+	next.ID = id
+	b, err := json.Marshal(&next)
+	if err != nil {
+		return err
+	}
+	if x.f == nil {
+		return os.ErrClosed
+	}
+	if _, err := x.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	x.entries[id] = &next
+	return nil
+}
+
+// AddTranscript lists a transcript of recording id
+func (x *Index) AddTranscript(id, name string) error {
+	return x.Update(id, func(e *Entry) {
+		e.Transcripts = appendNew(e.Transcripts, name)
+	})
+}
+
+// Get returns the entry of recording id
+func (x *Index) Get(id string) (Entry, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	e, ok := x.entries[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Search returns the entries matching q, the latest last
+func (x *Index) Search(q Query) []Entry {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var found []Entry
+	for _, e := range x.sorted() {
+		if q.match(e) {
+			found = append(found, *e)
+		}
+	}
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[len(found)-q.Limit:]
+	}
+	return found
+}
+
+// Follow indexes the recordings of a tracker as they change
+func (x *Index) Follow(t *recording.Tracker) {
+	t.OnStateChange(func(s recording.Status) {
+		err := x.Update(s.ID, func(e *Entry) {
+			e.Session, e.Track, e.Name, e.State = s.Session, s.Track, s.Name, s.State
+			// Segments time the media once there are any
+			if e.Segments == 0 {
+				e.Start, e.End = s.Created, s.Updated
+				e.Locations = []string{s.Name}
+			}
+		})
+		if err != nil {
+			log.Errorf("archive: indexing recording %s: %s", s.ID, err)
+		}
+	})
+	t.OnSegment(func(s recording.Segment) {
+		err := x.Update(s.Recording, func(e *Entry) {
+			e.Session, e.Track = s.Session, s.Track
+			if e.Segments == 0 {
+				e.Start, e.End, e.Locations = s.Start, s.End, nil
+			}
+			if s.Start.Before(e.Start) {
+				e.Start = s.Start
+			}
+			if s.End.After(e.End) {
+				e.End = s.End
+			}
+			e.Locations = appendNew(e.Locations, s.Name)
+			e.Segments++
+		})
+		if err != nil {
+			log.Errorf("archive: indexing segment %s: %s", s.Name, err)
+		}
+	})
+}
+
+// Close closes the files of the index
+func (x *Index) Close() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.f == nil {
+		return nil
+	}
+	err := x.f.Close()
+	if terr := x.tf.Close(); err == nil {
+		err = terr
+	}
+	x.f, x.tf = nil, nil
+	return err
+}
+
+func appendNew(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
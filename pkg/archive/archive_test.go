@@ -0,0 +1,156 @@
+package archive
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "index", "recordings.jsonl")
+
+	x, err := Open(path)
+	assert.NoError(t, err)
+	t0 := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, sid := range []string{"a", "b", "a"} {
+		id := string(rune('0' + i))
+		assert.NoError(t, x.Update(id, func(e *Entry) {
+			e.Session, e.Track, e.Name = sid, "tid", sid+"/"+id+".webm"
+			e.Start = t0.Add(time.Duration(i) * time.Hour)
+			e.End = e.Start.Add(30 * time.Minute)
+			e.State = recording.StateComplete
+		}))
+	}
+	assert.NoError(t, x.AddTranscript("2", "a/2.vtt"))
+
+	found := x.Search(Query{Session: "a"})
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, "0", found[0].ID)
+		assert.Equal(t, 30*time.Minute, found[1].Duration())
+	}
+	assert.Len(t, x.Search(Query{From: t0.Add(45 * time.Minute), To: t0.Add(65 * time.Minute)}), 1)
+	assert.Len(t, x.Search(Query{Transcript: true}), 1)
+	assert.Len(t, x.Search(Query{Name: "b/"}), 1)
+	found = x.Search(Query{Limit: 1})
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, "2", found[0].ID)
+	}
+	assert.NoError(t, x.Close())
+
+	// Reopened, as after a restart, with a line cut short by a crash
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"id":"3","sid":`)
+	assert.NoError(t, err)
+	f.Close()
+	x, err = Open(path)
+	assert.NoError(t, err)
+	defer x.Close()
+	e, ok := x.Get("2")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a/2.vtt"}, e.Transcripts)
+	assert.Equal(t, recording.StateComplete, e.State)
+	assert.Len(t, x.Search(Query{}), 3)
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, bytes.Count(b, []byte("\n")))
+}
+
+func TestIndex_Follow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	x, err := Open(filepath.Join(dir, "recordings.jsonl"))
+	assert.NoError(t, err)
+	defer x.Close()
+
+	tr := recording.NewTracker(recording.Config{})
+	defer tr.Close()
+	x.Follow(tr)
+	r := tr.Start("sid", "tid", "rec.webm")
+	id := r.Status().ID
+	assert.NoError(t, r.Set(recording.StateRecording))
+	t0 := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	r.Segment("rec-0.webm", t0, t0.Add(time.Minute))
+	r.Segment("rec-1.webm", t0.Add(time.Minute), t0.Add(90*time.Second))
+	assert.NoError(t, r.Set(recording.StateFinalizing))
+	assert.NoError(t, r.Set(recording.StateComplete))
+
+	assert.Eventually(t, func() bool {
+		e, _ := x.Get(id)
+		return e.State == recording.StateComplete
+	}, 5*time.Second, 10*time.Millisecond)
+	e, _ := x.Get(id)
+	assert.Equal(t, "sid", e.Session)
+	assert.Equal(t, "tid", e.Track)
+	assert.Equal(t, []string{"rec-0.webm", "rec-1.webm"}, e.Locations)
+	assert.Equal(t, 90*time.Second, e.Duration())
+	assert.Equal(t, t0, e.Start)
+}
+
+func TestIndex_SearchText(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "recordings.jsonl")
+	x, err := Open(path)
+	assert.NoError(t, err)
+
+	t0 := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, sid := range []string{"a", "b"} {
+		id := string(rune('0' + i))
+		assert.NoError(t, x.Update(id, func(e *Entry) {
+			e.Session, e.Start = sid, t0.Add(time.Duration(i)*time.Hour)
+		}))
+	}
+	assert.NoError(t, x.IndexTranscript("0", "0.vtt", []Cue{
+		{Start: time.Second, End: 2 * time.Second, Text: "Welcome to the quarterly review."},
+		{Start: 5 * time.Second, End: 6 * time.Second, Text: "Revenue is up, review the slides"},
+	}))
+	assert.NoError(t, x.IndexTranscript("1", "1.vtt", []Cue{
+		{Start: time.Minute, End: time.Minute + time.Second, Text: "Quarterly REVIEW of session b"},
+	}))
+	e, _ := x.Get("1")
+	assert.Equal(t, []string{"1.vtt"}, e.Transcripts)
+
+	hits := x.SearchText(TextQuery{Text: "review quarterly"})
+	if assert.Len(t, hits, 2) {
+		assert.Equal(t, Hit{Recording: "0", Session: "a", Transcript: "0.vtt", Cue: Cue{Start: time.Second, End: 2 * time.Second, Text: "Welcome to the quarterly review."}, At: t0.Add(time.Second)}, hits[0])
+		assert.Equal(t, t0.Add(time.Hour+time.Minute), hits[1].At)
+	}
+	assert.Len(t, x.SearchText(TextQuery{Text: "review"}), 3)
+	assert.Len(t, x.SearchText(TextQuery{Text: "review", Session: "a"}), 2)
+	hits = x.SearchText(TextQuery{Text: "review", Limit: 1})
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "1", hits[0].Recording)
+	}
+	assert.Empty(t, x.SearchText(TextQuery{Text: "review budget"}))
+	assert.Empty(t, x.SearchText(TextQuery{Text: " ,. "}))
+
+	// A transcript of the same name replaces the old one
+	assert.NoError(t, x.IndexTranscript("0", "0.vtt", []Cue{{Start: 3 * time.Second, Text: "The budget review"}}))
+	hits = x.SearchText(TextQuery{Text: "review", Session: "a"})
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "The budget review", hits[0].Text)
+	}
+	assert.NoError(t, x.Close())
+
+	// Reopened, as after a restart
+	x, err = Open(path)
+	assert.NoError(t, err)
+	defer x.Close()
+	assert.Len(t, x.SearchText(TextQuery{Text: "review"}), 2)
+	assert.Len(t, x.SearchText(TextQuery{Text: "budget"}), 1)
+	b, err := ioutil.ReadFile(path + ".transcripts")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(b, []byte("\n")))
+}
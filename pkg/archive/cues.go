@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCues is returned for transcripts that aren't WebVTT or SRT
+var ErrCues = errors.New("archive: invalid transcript")
+
+// Cue is a line of a transcript, timed from the start of its recording
+type Cue struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// ParseCues reads the cues of a WebVTT or SRT transcript. Cue settings
+// and markup are left in their text.
+func ParseCues(r io.Reader) ([]Cue, error) {
+	var (
+		cues []Cue
+		cue  *Cue
+		text []string
+	)
+	end := func() {
+		if cue != nil {
+			cue.Text = strings.Join(text, "\n")
+			cues = append(cues, *cue)
+		}
+		cue, text = nil, nil
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+			end()
+		case cue != nil:
+			text = append(text, line)
+		case strings.Contains(line, "-->"):
+			timing := strings.SplitN(line, "-->", 2)
+			start, err := parseCueTime(timing[0])
+			if err != nil {
+				return nil, err
+			}
+			// Settings follow the end time in WebVTT
+			stop, err := parseCueTime(strings.Fields(timing[1] + " ")[0])
+			if err != nil {
+				return nil, err
+			}
+			cue = &Cue{Start: start, End: stop}
+		}
+		// Other lines outside cues are headers, identifiers, notes and
+		// styles
+	}
+	end()
+	return cues, scanner.Err()
+}
+
+// parseCueTime parses [hh:]mm:ss.ttt, or hh:mm:ss,ttt of SRT
+func parseCueTime(s string) (time.Duration, error) {
+	s = strings.Replace(strings.TrimSpace(s), ",", ".", 1)
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("%w: time %q", ErrCues, s)
+	}
+	var d time.Duration
+	for i, p := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%w: time %q", ErrCues, s)
+		}
+		unit := time.Minute
+		if len(parts) == 3 && i == 0 {
+			unit = time.Hour
+		}
+		d += time.Duration(n) * unit
+	}
+	secs, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || secs < 0 {
+		return 0, fmt.Errorf("%w: time %q", ErrCues, s)
+	}
+	return d + time.Duration(secs*1000+0.5)*time.Millisecond, nil
+}
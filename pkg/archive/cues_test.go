@@ -0,0 +1,31 @@
+package archive
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCues(t *testing.T) {
+	vtt := "\ufeffWEBVTT - a meeting\n\nNOTE a note\n\nintro\n00:01.000 --> 00:04.500 align:start\n<v Ann>Hello,\nworld\n\n01:00:00.250 --> 01:00:02.000\nBye\n"
+	cues, err := ParseCues(strings.NewReader(vtt))
+	assert.NoError(t, err)
+	assert.Equal(t, []Cue{
+		{Start: time.Second, End: 4500 * time.Millisecond, Text: "<v Ann>Hello,\nworld"},
+		{Start: time.Hour + 250*time.Millisecond, End: time.Hour + 2*time.Second, Text: "Bye"},
+	}, cues)
+
+	srt := "1\r\n00:00:01,000 --> 00:00:02,500\r\nHello\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\nAgain\r\n"
+	cues, err = ParseCues(strings.NewReader(srt))
+	assert.NoError(t, err)
+	assert.Equal(t, []Cue{
+		{Start: time.Second, End: 2500 * time.Millisecond, Text: "Hello"},
+		{Start: 3 * time.Second, End: 4 * time.Second, Text: "Again"},
+	}, cues)
+
+	_, err = ParseCues(strings.NewReader("WEBVTT\n\n00:01.000 --> soon\nHello\n"))
+	assert.True(t, errors.Is(err, ErrCues))
+}
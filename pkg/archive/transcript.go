@@ -0,0 +1,190 @@
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// transcript is a transcript of a recording, a line of path.transcripts.
+// A later line with the same recording and name replaces it.
+type transcript struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Cues []Cue  `json:"cues"`
+}
+
+// posting is a cue of a transcript containing a word
+type posting struct {
+	doc, cue int
+}
+
+// TextQuery selects cues of transcripts
+// Text: Words every cue must contain, in any order and case.
+// Session: Of recordings of the session.
+// Limit: How many of the latest matching cues to return.
+type TextQuery struct {
+	Text    string
+	Session string
+	Limit   int
+}
+
+// Hit is a cue of a transcript matching a TextQuery
+type Hit struct {
+	Recording  string
+	Session    string
+	Transcript string
+	Cue
+	// At is the wall-clock time of the cue, from the start of the recording
+	At time.Time
+}
+
+// words returns the words of text, lowercase
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// openTranscripts loads the transcripts, compacts them to a line each and
+// opens their file for appending
+func (x *Index) openTranscripts() error {
+	path := x.path + ".transcripts"
+	x.words = make(map[string][]posting)
+	err := readLines(path, func(b []byte) error {
+		var t transcript
+		if err := json.Unmarshal(b, &t); err != nil {
+			return err
+		}
+		x.index(&t)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	x.tf, err = rewrite(path, func(enc *json.Encoder) error {
+		for _, t := range x.transcripts {
+			if t == nil {
+				continue
+			}
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// index adds t to the transcripts searched, replacing one of the same
+// recording and name
+func (x *Index) index(t *transcript) {
+	for i, old := range x.transcripts {
+		if old != nil && old.ID == t.ID && old.Name == t.Name {
+			// Postings of the old one are skipped until compacted at Open
+			x.transcripts[i] = nil
+		}
+	}
+	doc := len(x.transcripts)
+	x.transcripts = append(x.transcripts, t)
+	for i, c := range t.Cues {
+		seen := make(map[string]bool)
+		for _, w := range words(c.Text) {
+			if !seen[w] {
+				seen[w] = true
+				x.words[w] = append(x.words[w], posting{doc, i})
+			}
+		}
+	}
+}
+
+// IndexTranscript makes the cues of a transcript of recording id
+// searchable, and lists it in the recording's entry. A transcript of the
+// same name replaces the one indexed before.
+func (x *Index) IndexTranscript(id, name string, cues []Cue) error {
+	t := &transcript{ID: id, Name: name, Cues: cues}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	x.mu.Lock()
+	if x.tf == nil {
+		x.mu.Unlock()
+		return os.ErrClosed
+	}
+	if _, err := x.tf.Write(append(b, '\n')); err != nil {
+		x.mu.Unlock()
+		return err
+	}
+	x.index(t)
+	x.mu.Unlock()
+	return x.AddTranscript(id, name)
+}
+
+// SearchText returns the cues matching q, by time of their recording then
+// of the cue, the latest last
+func (x *Index) SearchText(q TextQuery) []Hit {
+	terms := words(q.Text)
+	if len(terms) == 0 {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	// Cues with the rarest word, then those of them with every other
+	sort.Slice(terms, func(i, j int) bool {
+		return len(x.words[terms[i]]) < len(x.words[terms[j]])
+	})
+	var found []Hit
+	for _, p := range x.words[terms[0]] {
+		t := x.transcripts[p.doc]
+		if t == nil || !x.contains(terms[1:], p) {
+			continue
+		}
+		e := x.entries[t.ID]
+		h := Hit{Recording: t.ID, Transcript: t.Name, Cue: t.Cues[p.cue]}
+		if e != nil {
+			h.Session = e.Session
+			h.At = e.Start.Add(h.Start)
+		}
+		if q.Session != "" && h.Session != q.Session {
+			continue
+		}
+		found = append(found, h)
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		switch {
+		case !a.At.Equal(b.At):
+			return a.At.Before(b.At)
+		case a.Recording != b.Recording:
+			return a.Recording < b.Recording
+		case a.Transcript != b.Transcript:
+			return a.Transcript < b.Transcript
+		}
+		return a.Start < b.Start
+	})
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[len(found)-q.Limit:]
+	}
+	return found
+}
+
+// contains reports whether the cue of p has every word of terms
+func (x *Index) contains(terms []string, p posting) bool {
+	for _, w := range terms {
+		ok := false
+		for _, q := range x.words[w] {
+			if q == p {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package avp
+
+import (
+	"sync"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+)
+
+// AudioLevelURI identifies the ssrc-audio-level RTP header extension
+// (RFC 6464), carrying the level of the audio in each packet
+const AudioLevelURI = "urn:ietf:params:rtp-hdrext:ssrc-audio-level"
+
+// AudioLevel is the level of a sample's audio as its sender measured it
+type AudioLevel struct {
+	// Level is in -dBov, from 0 for the loudest audio to 127 for silence
+	Level uint8
+	// Voice is set when the sender detected speech
+	Voice bool
+}
+
+// audioLevels is the number of levels kept for samples still being built
+const audioLevels = 16
+
+// levelReader reads the ssrc-audio-level extension of a track's packets,
+// keeping the levels of the last few timestamps until their samples are
+// built
+type levelReader struct {
+	mu     sync.Mutex
+	ext    uint8
+	ts     [audioLevels]uint32
+	levels [audioLevels]*AudioLevel
+	next   int
+}
+
+// negotiated sets the ssrc-audio-level extension id from the receiver's
+// negotiated header extensions
+func (r *levelReader) negotiated(params webrtc.RTPParameters) {
+	for _, e := range params.HeaderExtensions {
+		if e.URI == AudioLevelURI {
+			r.mu.Lock()
+			r.ext = uint8(e.ID)
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *levelReader) packet(p *rtp.Packet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ext == 0 {
+		return
+	}
+	b := p.GetExtension(r.ext)
+	if len(b) < 1 {
+		return
+	}
+	r.ts[r.next] = p.Timestamp
+	r.levels[r.next] = &AudioLevel{Level: b[0] & 0x7f, Voice: b[0]&0x80 != 0}
+	r.next = (r.next + 1) % audioLevels
+}
+
+// at returns the level of the packet of timestamp, nil if it had none
+func (r *levelReader) at(timestamp uint32) *AudioLevel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.ts {
+		if l := r.levels[i]; l != nil && r.ts[i] == timestamp {
+			return l
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package avp
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelReader(t *testing.T) {
+	r := &levelReader{}
+	p := &rtp.Packet{Header: rtp.Header{Timestamp: 960}}
+	assert.NoError(t, p.Header.SetExtension(2, []byte{0x80 | 30}))
+
+	// Not negotiated, the extension isn't read
+	r.packet(p)
+	assert.Nil(t, r.at(960))
+
+	r.negotiated(webrtc.RTPParameters{HeaderExtensions: []webrtc.RTPHeaderExtensionParameter{
+		{URI: AbsCaptureTimeURI, ID: 3},
+		{URI: AudioLevelURI, ID: 2},
+	}})
+	r.packet(p)
+	assert.Equal(t, &AudioLevel{Level: 30, Voice: true}, r.at(960))
+	assert.Nil(t, r.at(1920))
+
+	// Only the last few are kept
+	for i := uint32(2); i < 2+audioLevels; i++ {
+		p.Timestamp = i * 960
+		assert.NoError(t, p.Header.SetExtension(2, []byte{127}))
+		r.packet(p)
+	}
+	assert.Nil(t, r.at(960))
+	assert.Equal(t, &AudioLevel{Level: 127}, r.at(3*960))
+}
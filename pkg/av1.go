@@ -0,0 +1,116 @@
+package avp
+
+import "errors"
+
+var errAV1Packet = errors.New("invalid AV1 packet")
+
+// AV1 OBU types
+const (
+	obuTemporalDelimiter = 2
+)
+
+// av1Packet depacketizes AV1 as in the RTP Payload Format for AV1. OBUs
+// may be fragmented across packets and carry no sizes, so OBU elements are
+// framed as [continuation][leb128 size][element] for av1TemporalUnit to
+// join once the sample builder has the packets of a temporal unit.
+type av1Packet struct{}
+
+func (p *av1Packet) Unmarshal(payload []byte) ([]byte, error) {
+	if len(payload) < 1 {
+		return nil, errAV1Packet
+	}
+	// Aggregation header: Z continues an OBU, W counts elements if not 0
+	z := payload[0]&0x80 != 0
+	w := int(payload[0] >> 4 & 0x3)
+	b := payload[1:]
+	var out []byte
+	for i := 0; len(b) > 0; i++ {
+		// Every element has a size, but the last of W
+		size := len(b)
+		if w == 0 || i < w-1 {
+			v, n := readLeb128(b)
+			if n == 0 || v > uint64(len(b)-n) {
+				return nil, errAV1Packet
+			}
+			b = b[n:]
+			size = int(v)
+		}
+		var continuation byte
+		if i == 0 && z {
+			continuation = 1
+		}
+		out = append(out, continuation)
+		out = appendLeb128(out, uint64(size))
+		out = append(out, b[:size]...)
+		b = b[size:]
+	}
+	return out, nil
+}
+
+// av1TemporalUnit joins the elements of av1Packet into a temporal unit in
+// the low overhead bitstream format, each OBU with its size. Temporal
+// delimiters are dropped, as containers leave them out.
+func av1TemporalUnit(framed []byte) []byte {
+	var obus [][]byte
+	for len(framed) > 1 {
+		continuation := framed[0] == 1
+		v, n := readLeb128(framed[1:])
+		if n == 0 || v > uint64(len(framed)-1-n) {
+			break
+		}
+		element := framed[1+n : 1+n+int(v)]
+		framed = framed[1+n+int(v):]
+		switch {
+		case continuation && len(obus) > 0:
+			obus[len(obus)-1] = append(obus[len(obus)-1], element...)
+		case continuation:
+			// The start of the OBU was lost
+		default:
+			obus = append(obus, append([]byte(nil), element...))
+		}
+	}
+
+	var tu []byte
+	for _, obu := range obus {
+		if len(obu) == 0 || obu[0]>>3&0xf == obuTemporalDelimiter {
+			continue
+		}
+		header := 1
+		if obu[0]&0x4 != 0 {
+			header++
+		}
+		if len(obu) < header {
+			continue
+		}
+		if obu[0]&0x2 != 0 {
+			tu = append(tu, obu...)
+			continue
+		}
+		tu = append(tu, obu[0]|0x2)
+		tu = append(tu, obu[1:header]...)
+		tu = appendLeb128(tu, uint64(len(obu)-header))
+		tu = append(tu, obu[header:]...)
+	}
+	return tu
+}
+
+// readLeb128 returns an unsigned LEB128 value and its length, 0 if b
+// doesn't start with one
+func readLeb128(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8 && i < len(b); i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func appendLeb128(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v&0x7f|0x80))
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
@@ -0,0 +1,37 @@
+package avp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAV1Packet(t *testing.T) {
+	p := &av1Packet{}
+	var framed []byte
+	for _, payload := range [][]byte{
+		// Two elements, a temporal delimiter and the start of a sequence
+		// header continued in the next packet
+		{0x60, 0x01, 0x10, 0x08, 0xaa, 0xbb},
+		// The rest of the sequence header and a frame, both sized
+		{0x80, 0x02, 0xcc, 0xdd, 0x03, 0x30, 0x01, 0x02},
+	} {
+		b, err := p.Unmarshal(payload)
+		assert.NoError(t, err)
+		framed = append(framed, b...)
+	}
+	assert.Equal(t, []byte{
+		0x0a, 0x04, 0xaa, 0xbb, 0xcc, 0xdd,
+		0x32, 0x02, 0x01, 0x02,
+	}, av1TemporalUnit(framed))
+
+	// A continuation without its start is dropped
+	b, err := p.Unmarshal([]byte{0x90, 0xcc, 0xdd})
+	assert.NoError(t, err)
+	assert.Empty(t, av1TemporalUnit(b))
+
+	_, err = p.Unmarshal([]byte{0x00, 0x05, 0x30})
+	assert.Equal(t, errAV1Packet, err)
+	_, err = p.Unmarshal(nil)
+	assert.Equal(t, errAV1Packet, err)
+}
@@ -1,11 +1,38 @@
 package avp
 
+import log "github.com/pion/ion-log"
+
 var registry *Registry
 
 // Init avp with a registry of elements
 func Init(elems map[string]ElementFun) {
 	registry = NewRegistry()
 	for eid, elem := range elems {
+		if disabled(eid) {
+			log.Infof("element %s disabled in low memory mode", eid)
+			continue
+		}
 		registry.AddElement(eid, elem)
 	}
 }
+
+// CheckElement returns whether an element can be processed on this node,
+// ErrElementDisabled or ErrElementNotFound
+func CheckElement(eid string) error {
+	if disabled(eid) {
+		return ErrElementDisabled
+	}
+	if registry == nil || registry.GetElement(eid) == nil {
+		return ErrElementNotFound
+	}
+	return nil
+}
+
+// NewElement creates an element of the registry, as processes of tracks
+// are, or returns ErrElementDisabled or ErrElementNotFound
+func NewElement(eid, sid, pid, tid string, config []byte) (Element, error) {
+	if err := CheckElement(eid); err != nil {
+		return nil, err
+	}
+	return registry.GetElement(eid)(sid, pid, tid, config), nil
+}
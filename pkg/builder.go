@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/pion/ion-log"
 	"github.com/pion/rtp"
@@ -19,6 +20,7 @@ const (
 	MimeTypeOpus = "audio/opus"
 	MimeTypeVP8  = "video/vp8"
 	MimeTypeVP9  = "video/vp9"
+	MimeTypeAV1  = "video/AV1"
 	MimeTypeG722 = "audio/G722"
 	MimeTypePCMU = "audio/PCMU"
 	MimeTypePCMA = "audio/PCMA"
@@ -34,35 +36,55 @@ type Builder struct {
 	mu            sync.RWMutex
 	stopped       atomicBool
 	onStopHandler func()
+	onKeyframe    func()
 	builder       *samplebuilder.SampleBuilder
-	elements      []Element
+	elements      []*elementQueue
 	sequence      uint16
 	track         *webrtc.TrackRemote
+	sampleType    int
 	out           chan *Sample
+	quality       *quality
+	clock         *captureClock
+	levels        *levelReader
+	optOut        *optOut
 }
 
 // NewBuilder Initialize a new audio sample builder
 func NewBuilder(track *webrtc.TrackRemote, maxLate uint16) *Builder {
 	var depacketizer rtp.Depacketizer
 	var checker rtp.PartitionHeadChecker
+	// Other codecs are typed by their kind, as Opus or VP8
+	sampleType := int(track.Kind())
 	switch strings.ToLower(track.Codec().MimeType) {
 	case strings.ToLower(MimeTypeOpus):
 		depacketizer = &codecs.OpusPacket{}
 		checker = &codecs.OpusPartitionHeadChecker{}
+		sampleType = TypeOpus
 	case strings.ToLower(MimeTypeVP8):
 		depacketizer = &codecs.VP8Packet{}
 		checker = &codecs.VP8PartitionHeadChecker{}
+		sampleType = TypeVP8
 	case strings.ToLower(MimeTypeVP9):
 		depacketizer = &codecs.VP9Packet{}
 		checker = &codecs.VP9PartitionHeadChecker{}
+		sampleType = TypeVP9
 	case strings.ToLower(MimeTypeH264):
 		depacketizer = &codecs.H264Packet{}
+		sampleType = TypeH264
+	case strings.ToLower(MimeTypeAV1):
+		depacketizer = &av1Packet{}
+		sampleType = TypeAV1
 	}
 
 	b := &Builder{
-		builder: samplebuilder.New(maxLate, depacketizer, track.Codec().ClockRate),
-		track:   track,
-		out:     make(chan *Sample, maxSize),
+		builder:    samplebuilder.New(maxLate, depacketizer, track.Codec().ClockRate),
+		track:      track,
+		sampleType: sampleType,
+		out:        make(chan *Sample, queueCap(maxSize)),
+		quality:    newQuality(track.Codec().ClockRate),
+		clock:      newCaptureClock(track.Codec().ClockRate),
+		levels:     &levelReader{},
+		optOut:     newOptOut(track.ID()),
 	}
 
 	if checker != nil {
@@ -75,11 +97,41 @@ func NewBuilder(track *webrtc.TrackRemote, maxLate uint16) *Builder {
 	return b
 }
 
-// AttachElement attaches a element to a builder
+// AttachElement attaches a element to a builder. Each element is fed from
+// its own queue, sized and shed according to its Priority.
 func (b *Builder) AttachElement(e Element) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.elements = append(b.elements, e)
+	q := newElementQueue(e)
+	q.keyframe = b.requestKeyframe
+	if !b.stopped.get() {
+		q.start()
+	}
+	b.elements = append(b.elements, q)
+}
+
+// DetachElement stops feeding an attached element and closes it, once it
+// has caught up with its queue
+func (b *Builder) DetachElement(e Element) {
+	b.mu.Lock()
+	if b.stopped.get() {
+		// Stopping closed it already
+		b.mu.Unlock()
+		return
+	}
+	var q *elementQueue
+	for i, eq := range b.elements {
+		if eq.e == e {
+			q = eq
+			b.elements = append(b.elements[:i], b.elements[i+1:]...)
+			break
+		}
+	}
+	b.mu.Unlock()
+	if q != nil {
+		q.stop()
+		q.close()
+	}
 }
 
 // Track returns the builders underlying track
@@ -94,6 +146,22 @@ func (b *Builder) OnStop(f func()) {
 	b.onStopHandler = f
 }
 
+// OnKeyframeRequest is called when an element's queue drops video, so
+// the sender is asked for a keyframe to resume it from
+func (b *Builder) OnKeyframeRequest(f func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onKeyframe = f
+}
+
+// requestKeyframe is called by queues as samples are pushed, with b.mu
+// held
+func (b *Builder) requestKeyframe() {
+	if b.onKeyframe != nil {
+		b.onKeyframe()
+	}
+}
+
 func (b *Builder) build() {
 	log.Debugf("Reading rtp for track: %s", b.Track().ID())
 	for {
@@ -111,6 +179,10 @@ func (b *Builder) build() {
 			continue
 		}
 
+		b.quality.packet(pkt, time.Now())
+		b.clock.packet(pkt)
+		b.levels.packet(pkt)
+		b.optOut.packet(pkt)
 		b.builder.Push(pkt)
 
 		for {
@@ -126,12 +198,20 @@ func (b *Builder) build() {
 
 			log.Tracef("Sample from builder: %s sample: %v", b.Track().ID(), sample)
 
+			payload := sample.Data
+			if b.sampleType == TypeAV1 {
+				payload = av1TemporalUnit(payload)
+			}
+
 			b.out <- &Sample{
 				ID:             b.track.ID(),
-				Type:           int(b.track.Kind()),
+				Type:           b.sampleType,
 				SequenceNumber: b.sequence,
 				Timestamp:      timestamp,
-				Payload:        sample.Data,
+				ClockRate:      b.track.Codec().ClockRate,
+				CaptureTime:    b.clock.at(timestamp),
+				AudioLevel:     b.levels.at(timestamp),
+				Payload:        payload,
 			}
 			b.sequence++
 		}
@@ -147,32 +227,51 @@ func (b *Builder) forward() {
 			return
 		}
 
+		if b.optOut.active() {
+			continue
+		}
+
 		b.mu.RLock()
-		for _, e := range b.elements {
-			err := e.Write(sample)
-			if err != nil {
-				log.Errorf("error writing sample: %s", err)
-			}
+		if b.stopped.get() {
+			b.mu.RUnlock()
+			return
+		}
+		for _, q := range b.elements {
+			q.push(sample)
 		}
 		b.mu.RUnlock()
 	}
 }
 
-// Stop stop all buffer
+// Stop stop all buffer. Queues drain in parallel, without the lock, so
+// a slow element holds up neither the others nor callers of the builder.
 func (b *Builder) stop() {
 	if b.stopped.get() {
 		return
 	}
 
 	b.mu.Lock()
-	defer b.mu.Unlock()
-
+	if b.stopped.get() {
+		b.mu.Unlock()
+		return
+	}
 	b.stopped.set(true)
-	for _, e := range b.elements {
-		e.Close()
+	elements := b.elements
+	onStop := b.onStopHandler
+	b.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, q := range elements {
+		wg.Add(1)
+		go func(q *elementQueue) {
+			defer wg.Done()
+			q.stop()
+			q.close()
+		}(q)
 	}
-	if b.onStopHandler != nil {
-		b.onStopHandler()
+	wg.Wait()
+	if onStop != nil {
+		onStop()
 	}
 	close(b.out)
 }
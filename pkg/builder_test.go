@@ -246,3 +246,58 @@ func TestNewBuilder_WithH264Packet(t *testing.T) {
 	assert.NoError(t, err)
 	sendRTPUntilDone(onBuilderFired.Done(), t, []*webrtc.TrackLocalStaticSample{track})
 }
+
+type closedMock struct {
+	elementMock
+	closed bool
+}
+
+func (e *closedMock) Close() {
+	e.closed = true
+}
+
+func TestBuilder_DetachElement(t *testing.T) {
+	b := &Builder{}
+	kept, detached := &closedMock{}, &closedMock{}
+	b.AttachElement(kept)
+	b.AttachElement(detached)
+
+	b.DetachElement(detached)
+	assert.True(t, detached.closed)
+	assert.False(t, kept.closed)
+	if assert.Len(t, b.elements, 1) {
+		assert.Equal(t, Element(kept), b.elements[0].e)
+	}
+	b.DetachElement(detached)
+
+	b.DetachElement(kept)
+	assert.True(t, kept.closed)
+}
+
+func TestBuilder_StopDrainsInParallel(t *testing.T) {
+	defer func(d time.Duration) { drainTimeout = d }(drainTimeout)
+	drainTimeout = 100 * time.Millisecond
+
+	b := &Builder{out: make(chan *Sample)}
+	for i := 0; i < 3; i++ {
+		b.AttachElement(WithPriority(&stuckElement{blockingElement{release: make(chan struct{})}}, PriorityHigh))
+		b.elements[i].push(&Sample{})
+	}
+	start := time.Now()
+	stopped := make(chan struct{})
+	go func() {
+		b.stop()
+		close(stopped)
+	}()
+
+	// The lock is free while the stuck elements drain
+	time.Sleep(drainTimeout / 4)
+	locked := time.Now()
+	b.mu.Lock()
+	b.mu.Unlock()
+	assert.True(t, time.Since(locked) < drainTimeout/4)
+
+	// Which they do at once, not one after another
+	<-stopped
+	assert.True(t, time.Since(start) < 2*drainTimeout)
+}
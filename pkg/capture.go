@@ -0,0 +1,112 @@
+package avp
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+)
+
+// AbsCaptureTimeURI identifies the abs-capture-time RTP header extension,
+// carrying the NTP time a frame was captured
+const AbsCaptureTimeURI = "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time"
+
+const ntpEpochOffset = 2208988800
+
+// ntpTime converts a 64 bit NTP timestamp to a time
+func ntpTime(ntp uint64) time.Time {
+	secs := int64(ntp>>32) - ntpEpochOffset
+	nsecs := (ntp & 0xffffffff) * 1e9 >> 32
+	return time.Unix(secs, int64(nsecs))
+}
+
+// captureClock maps the RTP timestamps of a track to the time samples were
+// captured, so tracks of different participants can be aligned. The
+// abs-capture-time header extension, stamped at capture, is authoritative.
+// Until it is seen RTCP sender reports give the sender's clock instead.
+type captureClock struct {
+	mu        sync.Mutex
+	ext       uint8
+	clockRate float64
+	abs       bool
+	rtp       uint32
+	ntp       time.Time
+}
+
+func newCaptureClock(clockRate uint32) *captureClock {
+	return &captureClock{clockRate: float64(clockRate)}
+}
+
+// negotiated sets the abs-capture-time extension id from the receiver's
+// negotiated header extensions
+func (c *captureClock) negotiated(params webrtc.RTPParameters) {
+	for _, e := range params.HeaderExtensions {
+		if e.URI == AbsCaptureTimeURI {
+			c.mu.Lock()
+			c.ext = uint8(e.ID)
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (c *captureClock) packet(p *rtp.Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ext == 0 {
+		return
+	}
+	b := p.GetExtension(c.ext)
+	if len(b) < 8 {
+		return
+	}
+	t := ntpTime(binary.BigEndian.Uint64(b))
+	if len(b) >= 16 {
+		// The estimated offset from the capture system's clock to the
+		// sender's, as signed Q32.32 seconds
+		offset := int64(binary.BigEndian.Uint64(b[8:]))
+		t = t.Add(time.Duration(offset>>32)*time.Second + time.Duration((offset&0xffffffff)*1e9>>32))
+	}
+	c.abs, c.rtp, c.ntp = true, p.Timestamp, t
+}
+
+func (c *captureClock) rtcp(ssrc uint32, pkts []rtcp.Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.abs {
+		return
+	}
+	for _, p := range pkts {
+		if sr, ok := p.(*rtcp.SenderReport); ok && sr.SSRC == ssrc {
+			c.rtp, c.ntp = sr.RTPTime, ntpTime(sr.NTPTime)
+		}
+	}
+}
+
+// at returns the capture time of an RTP timestamp, zero before the clock
+// is known
+func (c *captureClock) at(ts uint32) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ntp.IsZero() || c.clockRate == 0 {
+		return time.Time{}
+	}
+	d := float64(int32(ts-c.rtp)) / c.clockRate
+	return c.ntp.Add(time.Duration(d * float64(time.Second)))
+}
+
+// readRTCP feeds RTCP from the receiver to the track's quality and
+// capture clock until the receiver is closed
+func (b *Builder) readRTCP(recv *webrtc.RTPReceiver) {
+	ssrc := uint32(b.track.SSRC())
+	for {
+		pkts, _, err := recv.ReadRTCP()
+		if err != nil {
+			return
+		}
+		b.quality.rtcp(pkts, time.Now())
+		b.clock.rtcp(ssrc, pkts)
+	}
+}
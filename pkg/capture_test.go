@@ -0,0 +1,53 @@
+package avp
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func ntp(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / 1e9
+	return secs<<32 | frac
+}
+
+func TestCaptureClock(t *testing.T) {
+	c := newCaptureClock(90000)
+	c.negotiated(webrtc.RTPParameters{HeaderExtensions: []webrtc.RTPHeaderExtensionParameter{
+		{URI: "urn:ietf:params:rtp-hdrext:sdes:mid", ID: 1},
+		{URI: AbsCaptureTimeURI, ID: 3},
+	}})
+	assert.True(t, c.at(1000).IsZero())
+
+	// Sender reports give the clock until abs-capture-time is seen
+	sr := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	c.rtcp(1234, []rtcp.Packet{&rtcp.SenderReport{SSRC: 1234, NTPTime: ntp(sr), RTPTime: 9000}})
+	c.rtcp(1234, []rtcp.Packet{&rtcp.SenderReport{SSRC: 99, NTPTime: ntp(sr.Add(time.Hour)), RTPTime: 9000}})
+	assert.WithinDuration(t, sr.Add(time.Second), c.at(9000+90000), time.Microsecond)
+	assert.WithinDuration(t, sr.Add(-100*time.Millisecond), c.at(0), time.Microsecond)
+
+	p := &rtp.Packet{Header: rtp.Header{Timestamp: 180000}}
+	c.packet(p)
+	assert.WithinDuration(t, sr.Add(1900*time.Millisecond), c.at(180000), time.Microsecond)
+
+	// Capture time with an offset of -1.5s to the sender's clock
+	captured := sr.Add(time.Minute)
+	ext := make([]byte, 16)
+	binary.BigEndian.PutUint64(ext, ntp(captured))
+	offset := int64(-3 << 31)
+	binary.BigEndian.PutUint64(ext[8:], uint64(offset))
+	assert.NoError(t, p.Header.SetExtension(3, ext))
+	c.packet(p)
+	assert.WithinDuration(t, captured.Add(-1500*time.Millisecond), c.at(180000), time.Microsecond)
+	assert.WithinDuration(t, captured.Add(-500*time.Millisecond), c.at(270000), time.Microsecond)
+
+	// Later sender reports don't override it
+	c.rtcp(1234, []rtcp.Packet{&rtcp.SenderReport{SSRC: 1234, NTPTime: ntp(sr), RTPTime: 9000}})
+	assert.WithinDuration(t, captured.Add(-1500*time.Millisecond), c.at(180000), time.Microsecond)
+}
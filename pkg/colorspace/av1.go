@@ -0,0 +1,272 @@
+package colorspace
+
+// AV1 OBU types
+const (
+	obuSequenceHeader = 1
+)
+
+// ParseAV1 reads the sequence header of an AV1 temporal unit, a sequence
+// of OBUs with their sizes as in the low overhead bitstream format. The
+// last OBU may leave its size out.
+func ParseAV1(tu []byte) (Info, error) {
+	_, header, err := sequenceHeader(tu)
+	if err != nil {
+		return Info{}, err
+	}
+	return parseSequenceHeader(header, nil)
+}
+
+// AV1CodecConfig returns the AV1CodecConfigurationRecord of the sequence
+// header of a temporal unit, as MP4's av1C box and Matroska's CodecPrivate
+// hold it, with the sequence header OBU
+func AV1CodecConfig(tu []byte) ([]byte, error) {
+	obu, header, err := sequenceHeader(tu)
+	if err != nil {
+		return nil, err
+	}
+	var seq av1Sequence
+	info, err := parseSequenceHeader(header, &seq)
+	if err != nil {
+		return nil, err
+	}
+	flag := func(b bool, shift uint) byte {
+		if b {
+			return 1 << shift
+		}
+		return 0
+	}
+	c := info.Colour
+	config := []byte{
+		0x81, // marker, version 1
+		byte(seq.profile<<5 | seq.level&0x1f),
+		byte(seq.tier<<7) | flag(info.BitDepth > 8, 6) | flag(info.BitDepth == 12, 5) | flag(seq.mono, 4) |
+			byte(c.ChromaSubsamplingHorz<<3|c.ChromaSubsamplingVert<<2|seq.samplePosition),
+		0, // no initial presentation delay
+	}
+	if obu[0]&0x2 == 0 {
+		// The configuration OBUs have their sizes
+		sized := []byte{obu[0] | 0x2}
+		n := 1
+		if obu[0]&0x4 != 0 {
+			sized = append(sized, obu[1])
+			n++
+		}
+		for v := len(obu) - n; ; v >>= 7 {
+			if v < 0x80 {
+				sized = append(sized, byte(v))
+				break
+			}
+			sized = append(sized, byte(v&0x7f|0x80))
+		}
+		obu = append(sized, obu[n:]...)
+	}
+	return append(config, obu...), nil
+}
+
+// sequenceHeader returns the first sequence header OBU of a temporal unit,
+// and its payload
+func sequenceHeader(tu []byte) (obu, payload []byte, err error) {
+	for len(tu) > 0 {
+		header := tu[0]
+		typ := header >> 3 & 0xf
+		n := 1
+		if header&0x4 != 0 {
+			n++
+		}
+		if len(tu) < n {
+			return nil, nil, ErrInvalid
+		}
+		size := len(tu) - n
+		if header&0x2 != 0 {
+			v, l := leb128(tu[n:])
+			if l == 0 {
+				return nil, nil, ErrInvalid
+			}
+			n += l
+			if v > uint64(len(tu)-n) {
+				return nil, nil, ErrInvalid
+			}
+			size = int(v)
+		}
+		if typ == obuSequenceHeader {
+			return tu[:n+size], tu[n : n+size], nil
+		}
+		tu = tu[n+size:]
+	}
+	return nil, nil, ErrNotKeyframe
+}
+
+// av1Sequence is what the codec configuration record takes from a
+// sequence header besides its colour
+type av1Sequence struct {
+	profile, level, tier uint64
+	mono                 bool
+	samplePosition       uint64
+}
+
+func leb128(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8 && i < len(b); i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func (r *reader) uvlc() uint64 {
+	zeros := 0
+	for !r.flag() {
+		if r.err != nil {
+			return 0
+		}
+		zeros++
+	}
+	if zeros >= 32 {
+		return 1<<32 - 1
+	}
+	return r.bits(zeros) + 1<<zeros - 1
+}
+
+// parseSequenceHeader reads a sequence_header_obu (AV1 section 5.5), and
+// into seq if not nil
+func parseSequenceHeader(b []byte, seq *av1Sequence) (Info, error) {
+	if seq == nil {
+		seq = &av1Sequence{}
+	}
+	r := &reader{b: b}
+	profile := r.bits(3)
+	seq.profile = profile
+	r.bits(1) // still_picture
+	reduced := r.flag()
+	if reduced {
+		seq.level = r.bits(5)
+	} else {
+		var decoderModel bool
+		var delayLength int
+		if r.flag() {
+			// timing_info
+			r.bits(64)
+			if r.flag() {
+				r.uvlc()
+			}
+			decoderModel = r.flag()
+			if decoderModel {
+				delayLength = int(r.bits(5)) + 1
+				r.bits(32 + 5 + 5)
+			}
+		}
+		displayDelay := r.flag()
+		points := int(r.bits(5)) + 1
+		for i := 0; i < points && r.err == nil; i++ {
+			r.bits(12)
+			level, tier := r.bits(5), uint64(0)
+			if level > 7 {
+				tier = r.bits(1)
+			}
+			if i == 0 {
+				seq.level, seq.tier = level, tier
+			}
+			if decoderModel && r.flag() {
+				r.bits(2*delayLength + 1)
+			}
+			if displayDelay && r.flag() {
+				r.bits(4)
+			}
+		}
+	}
+
+	var info Info
+	widthBits, heightBits := int(r.bits(4))+1, int(r.bits(4))+1
+	info.Width = int(r.bits(widthBits)) + 1
+	info.Height = int(r.bits(heightBits)) + 1
+	if !reduced && r.flag() {
+		r.bits(7)
+	}
+	r.bits(3) // use_128x128_superblock, enable_filter_intra, enable_intra_edge_filter
+	if !reduced {
+		r.bits(4) // enable_interintra_compound ... enable_dual_filter
+		orderHint := r.flag()
+		if orderHint {
+			r.bits(2)
+		}
+		screenContent := uint64(2)
+		if !r.flag() {
+			screenContent = r.bits(1)
+		}
+		if screenContent > 0 && !r.flag() {
+			r.bits(1)
+		}
+		if orderHint {
+			r.bits(3)
+		}
+	}
+	r.bits(3) // enable_superres, enable_cdef, enable_restoration
+	parseColorConfig(r, profile, &info, seq)
+	info.FilmGrain = r.flag()
+	if r.err != nil {
+		return Info{}, r.err
+	}
+	return info, nil
+}
+
+// parseColorConfig reads color_config (AV1 section 5.5.2)
+func parseColorConfig(r *reader, profile uint64, info *Info, seq *av1Sequence) {
+	info.BitDepth = 8
+	if r.flag() {
+		info.BitDepth = 10
+		if profile == 2 && r.flag() {
+			info.BitDepth = 12
+		}
+	}
+	c := &info.Colour
+	c.BitsPerChannel = uint64(info.BitDepth)
+	mono := profile != 1 && r.flag()
+	seq.mono = mono
+	primaries, transfer, matrix := uint64(unspecified), uint64(unspecified), uint64(unspecified)
+	if r.flag() {
+		primaries, transfer, matrix = r.bits(8), r.bits(8), r.bits(8)
+	}
+	c.Primaries, c.TransferCharacteristics, c.MatrixCoefficients = primaries, transfer, matrix
+
+	fullRange := func() {
+		c.Range = RangeLimited
+		if r.flag() {
+			c.Range = RangeFull
+		}
+	}
+	if mono {
+		fullRange()
+		return
+	}
+	// sRGB is 4:4:4 full range
+	if primaries == 1 && transfer == 13 && matrix == 0 {
+		c.Range = RangeFull
+		return
+	}
+	fullRange()
+	switch {
+	case profile == 0:
+		c.ChromaSubsamplingHorz, c.ChromaSubsamplingVert = 1, 1
+	case profile == 1:
+	case info.BitDepth == 12:
+		c.ChromaSubsamplingHorz = r.bits(1)
+		if c.ChromaSubsamplingHorz == 1 {
+			c.ChromaSubsamplingVert = r.bits(1)
+		}
+	default:
+		c.ChromaSubsamplingHorz = 1
+	}
+	if c.ChromaSubsamplingHorz == 1 && c.ChromaSubsamplingVert == 1 {
+		// chroma_sample_position, Matroska sites 1 collocated, 2 half
+		seq.samplePosition = r.bits(2)
+		switch seq.samplePosition {
+		case 1:
+			c.ChromaSitingHorz, c.ChromaSitingVert = 1, 2
+		case 2:
+			c.ChromaSitingHorz, c.ChromaSitingVert = 1, 1
+		}
+	}
+	r.bits(1) // separate_uv_delta_q
+}
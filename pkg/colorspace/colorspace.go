@@ -0,0 +1,144 @@
+// Package colorspace reads how video is coded for display, its colour
+// and bit depth, from VP8, VP9, H.264 and AV1 keyframes so recordings can keep it in
+// Matroska Colour elements. Without them players guess, and 10 bit or HDR
+// sources play back washed out. Payloads are only read, never changed.
+package colorspace
+
+import (
+	"errors"
+)
+
+var (
+	// ErrInvalid is returned for headers that are cut short or malformed
+	ErrInvalid = errors.New("colorspace: invalid header")
+	// ErrNotKeyframe is returned for frames without the colour of the
+	// stream
+	ErrNotKeyframe = errors.New("colorspace: not a keyframe")
+)
+
+// Colour is the Matroska Colour element. Codes are those of ITU-T H.273,
+// which Matroska uses, and zero fields are left out for players to
+// assume unspecified.
+type Colour struct {
+	MatrixCoefficients      uint64 `ebml:"MatrixCoefficients,omitempty" mapstructure:"matrix"`
+	BitsPerChannel          uint64 `ebml:"BitsPerChannel,omitempty" mapstructure:"bitdepth"`
+	ChromaSubsamplingHorz   uint64 `ebml:"ChromaSubsamplingHorz,omitempty" mapstructure:"-"`
+	ChromaSubsamplingVert   uint64 `ebml:"ChromaSubsamplingVert,omitempty" mapstructure:"-"`
+	ChromaSitingHorz        uint64 `ebml:"ChromaSitingHorz,omitempty" mapstructure:"-"`
+	ChromaSitingVert        uint64 `ebml:"ChromaSitingVert,omitempty" mapstructure:"-"`
+	Range                   uint64 `ebml:"Range,omitempty" mapstructure:"range"`
+	TransferCharacteristics uint64 `ebml:"TransferCharacteristics,omitempty" mapstructure:"transfer"`
+	Primaries               uint64 `ebml:"Primaries,omitempty" mapstructure:"primaries"`
+	// MaxCLL and MaxFALL are the brightest pixel and frame average of HDR
+	// content in cd/m², only known to the source
+	MaxCLL  uint64 `ebml:"MaxCLL,omitempty" mapstructure:"maxcll"`
+	MaxFALL uint64 `ebml:"MaxFALL,omitempty" mapstructure:"maxfall"`
+}
+
+// Ranges
+const (
+	RangeUnspecified = 0
+	RangeLimited     = 1
+	RangeFull        = 2
+)
+
+// unspecified is the H.273 code of unknown matrix, transfer and primaries
+const unspecified = 2
+
+// Info is what a keyframe says about the video
+type Info struct {
+	Width, Height int
+	BitDepth      int
+	// FilmGrain is set for AV1 streams with film grain the decoder
+	// synthesizes, which is kept by leaving the payload as is
+	FilmGrain bool
+	Colour    Colour
+}
+
+// reader reads bits most significant first
+type reader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *reader) bits(n int) uint64 {
+	var v uint64
+	for i := 0; i < n; i++ {
+		if r.pos >= len(r.b)*8 {
+			r.err = ErrInvalid
+			return 0
+		}
+		v = v<<1 | uint64(r.b[r.pos/8]>>(7-r.pos%8)&1)
+		r.pos++
+	}
+	return v
+}
+
+func (r *reader) flag() bool {
+	return r.bits(1) == 1
+}
+
+// vp9Matrices are the matrix coefficients of each VP9 color_space
+var vp9Matrices = [8]uint64{unspecified, 5, 1, 6, 7, 9, unspecified, 0}
+
+// vp9SRGB is the VP9 color_space of RGB video
+const vp9SRGB = 7
+
+// ParseVP9 reads the uncompressed header of a VP9 keyframe
+func ParseVP9(frame []byte) (Info, error) {
+	r := &reader{b: frame}
+	if r.bits(2) != 2 {
+		return Info{}, ErrInvalid
+	}
+	profile := int(r.bits(1))
+	profile |= int(r.bits(1)) << 1
+	if profile == 3 {
+		r.bits(1)
+	}
+	if r.flag() {
+		// show_existing_frame
+		return Info{}, ErrNotKeyframe
+	}
+	if r.bits(1) != 0 {
+		return Info{}, ErrNotKeyframe
+	}
+	r.bits(2) // show_frame, error_resilient_mode
+	if r.bits(24) != 0x498342 {
+		return Info{}, ErrInvalid
+	}
+
+	info := Info{BitDepth: 8}
+	if profile >= 2 {
+		info.BitDepth = 10
+		if r.flag() {
+			info.BitDepth = 12
+		}
+	}
+	c := &info.Colour
+	space := r.bits(3)
+	c.MatrixCoefficients = vp9Matrices[space]
+	if space != vp9SRGB {
+		c.Range = RangeLimited
+		if r.flag() {
+			c.Range = RangeFull
+		}
+		c.ChromaSubsamplingHorz, c.ChromaSubsamplingVert = 1, 1
+		if profile == 1 || profile == 3 {
+			c.ChromaSubsamplingHorz, c.ChromaSubsamplingVert = r.bits(1), r.bits(1)
+			r.bits(1)
+		}
+	} else {
+		c.Range = RangeFull
+		if profile == 1 || profile == 3 {
+			r.bits(1)
+		}
+	}
+	c.BitsPerChannel = uint64(info.BitDepth)
+	info.Width = int(r.bits(16)) + 1
+	info.Height = int(r.bits(16)) + 1
+	if r.err != nil {
+		return Info{}, r.err
+	}
+	return info, nil
+}
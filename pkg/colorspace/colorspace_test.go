@@ -0,0 +1,152 @@
+package colorspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bitWriter writes fields most significant bit first
+type bitWriter struct {
+	b []byte
+	n int
+}
+
+func (w *bitWriter) put(v uint64, bits int) *bitWriter {
+	for i := bits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.b = append(w.b, 0)
+		}
+		w.b[len(w.b)-1] |= byte(v>>i&1) << (7 - w.n%8)
+		w.n++
+	}
+	return w
+}
+
+// ue writes an Exp-Golomb code
+func (w *bitWriter) ue(v uint64) *bitWriter {
+	n := 0
+	for v+1 >= 1<<(n+1) {
+		n++
+	}
+	return w.put(0, n).put(v+1, n+1)
+}
+
+func TestParseVP8(t *testing.T) {
+	// 640x480 keyframe
+	info, err := ParseVP8([]byte{0x50, 0x42, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01, 0x00, 0x47})
+	assert.NoError(t, err)
+	assert.Equal(t, Info{
+		Width: 640, Height: 480, BitDepth: 8,
+		Colour: Colour{
+			MatrixCoefficients: 6, BitsPerChannel: 8, Range: RangeLimited,
+			ChromaSubsamplingHorz: 1, ChromaSubsamplingVert: 1,
+		},
+	}, info)
+
+	_, err = ParseVP8([]byte{0x51, 0x42, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01, 0x00, 0x47})
+	assert.Equal(t, ErrNotKeyframe, err)
+	_, err = ParseVP8([]byte{0x50, 0x42, 0x00})
+	assert.Equal(t, ErrInvalid, err)
+}
+
+func TestParseH264(t *testing.T) {
+	w := &bitWriter{}
+	// High 10 profile, level 4.0, 4:2:0
+	w.put(110, 8).put(0, 8).put(40, 8).ue(0).ue(1).ue(2).ue(2).put(0, 1).put(0, 1)
+	w.ue(0).ue(0).ue(2).ue(1).put(0, 1)
+	// 1920x1088 cropped to 1080
+	w.ue(119).ue(67).put(1, 1).put(1, 1).put(1, 1).ue(0).ue(0).ue(0).ue(4)
+	// VUI with BT.2020 PQ limited range, and an emulation prevention byte
+	w.put(1, 1).put(0, 1).put(0, 1).put(1, 1).put(5, 3).put(0, 1).put(1, 1).put(9, 8).put(16, 8).put(9, 8)
+	w.put(0, 32)
+	sps := w.b
+	for i := 0; i+2 < len(sps); i++ {
+		if sps[i] == 0 && sps[i+1] == 0 && sps[i+2] <= 3 {
+			sps = append(sps[:i+2], append([]byte{3}, sps[i+2:]...)...)
+		}
+	}
+
+	au := append([]byte{0, 0, 0, 1, 0x09, 0xf0, 0, 0, 0, 1, 0x67}, sps...)
+	au = append(au, 0, 0, 1, 0x68, 0xce, 0x3c, 0x80)
+	info, err := ParseH264(au)
+	assert.NoError(t, err)
+	assert.Equal(t, Info{
+		Width: 1920, Height: 1080, BitDepth: 10,
+		Colour: Colour{
+			MatrixCoefficients: 9, BitsPerChannel: 10, Range: RangeLimited,
+			TransferCharacteristics: 16, Primaries: 9,
+			ChromaSubsamplingHorz: 1, ChromaSubsamplingVert: 1,
+		},
+	}, info)
+
+	_, err = ParseH264([]byte{0, 0, 0, 1, 0x41, 0x9a})
+	assert.Equal(t, ErrNotKeyframe, err)
+}
+
+func TestParseVP9(t *testing.T) {
+	w := &bitWriter{}
+	// Profile 2 keyframe, 10 bit BT.2020 limited range, 1280x720
+	w.put(2, 2).put(0, 1).put(1, 1).put(0, 1).put(0, 1).put(1, 1).put(0, 1)
+	w.put(0x498342, 24).put(0, 1).put(5, 3).put(0, 1)
+	w.put(1279, 16).put(719, 16)
+
+	info, err := ParseVP9(w.b)
+	assert.NoError(t, err)
+	assert.Equal(t, Info{
+		Width: 1280, Height: 720, BitDepth: 10,
+		Colour: Colour{
+			MatrixCoefficients: 9, BitsPerChannel: 10, Range: RangeLimited,
+			ChromaSubsamplingHorz: 1, ChromaSubsamplingVert: 1,
+		},
+	}, info)
+
+	// Interframe
+	_, err = ParseVP9([]byte{0x86, 0x00})
+	assert.Equal(t, ErrNotKeyframe, err)
+	_, err = ParseVP9(w.b[:4])
+	assert.Equal(t, ErrInvalid, err)
+}
+
+func TestParseAV1(t *testing.T) {
+	w := &bitWriter{}
+	// Main profile, one operating point at level 4.0
+	w.put(0, 3).put(0, 1).put(0, 1).put(0, 1).put(0, 1).put(0, 5).put(0, 12).put(8, 5).put(0, 1)
+	// 1920x1080
+	w.put(10, 4).put(10, 4).put(1919, 11).put(1079, 11).put(0, 1)
+	// Tools, with order hints and screen content tools chosen per frame
+	w.put(0, 3).put(0, 4).put(1, 1).put(0, 2).put(1, 1).put(1, 1).put(6, 3).put(0, 3)
+	// 10 bit BT.2020 PQ limited range, colocated chroma
+	w.put(1, 1).put(0, 1).put(1, 1).put(9, 8).put(16, 8).put(9, 8).put(0, 1).put(2, 2).put(0, 1)
+	// Film grain
+	w.put(1, 1)
+
+	tu := []byte{0x12, 0x00, 0x0a, byte(len(w.b))}
+	tu = append(tu, w.b...)
+	info, err := ParseAV1(tu)
+	assert.NoError(t, err)
+	assert.Equal(t, Info{
+		Width: 1920, Height: 1080, BitDepth: 10, FilmGrain: true,
+		Colour: Colour{
+			MatrixCoefficients: 9, BitsPerChannel: 10, Range: RangeLimited,
+			TransferCharacteristics: 16, Primaries: 9,
+			ChromaSubsamplingHorz: 1, ChromaSubsamplingVert: 1,
+			ChromaSitingHorz: 1, ChromaSitingVert: 1,
+		},
+	}, info)
+
+	_, err = ParseAV1([]byte{0x12, 0x00})
+	assert.Equal(t, ErrNotKeyframe, err)
+	_, err = ParseAV1(tu[:10])
+	assert.Equal(t, ErrInvalid, err)
+
+	// Level 4.0 main tier, 10 bit 4:2:0 colocated chroma
+	config, err := AV1CodecConfig(tu)
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte{0x81, 0x08, 0x4e, 0x00}, tu[2:]...), config)
+	// The sequence header is given a size if it has none
+	unsized := append([]byte{0x08}, w.b...)
+	config, err = AV1CodecConfig(unsized)
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte{0x81, 0x08, 0x4e, 0x00}, tu[2:]...), config)
+}
@@ -0,0 +1,196 @@
+package colorspace
+
+// h264 NAL unit types
+const (
+	nalSPS = 7
+)
+
+// highProfiles are the H.264 profiles whose SPS has chroma format and bit
+// depth
+var highProfiles = map[uint64]bool{
+	100: true, 110: true, 122: true, 244: true, 44: true, 83: true,
+	86: true, 118: true, 128: true, 138: true, 139: true, 134: true, 135: true,
+}
+
+// ParseH264 reads the sequence parameter set of an H.264 access unit in
+// Annex B format, as WebRTC H.264 samples are
+func ParseH264(au []byte) (Info, error) {
+	for _, nal := range SplitAnnexB(au) {
+		if len(nal) > 0 && nal[0]&0x1f == nalSPS {
+			return parseSPS(unescape(nal[1:]))
+		}
+	}
+	return Info{}, ErrNotKeyframe
+}
+
+// SplitAnnexB splits an Annex B stream into NAL units at its start codes
+func SplitAnnexB(b []byte) [][]byte {
+	var nals [][]byte
+	start := -1
+	for i := 0; i+2 < len(b); i++ {
+		if b[i] != 0 || b[i+1] != 0 || b[i+2] != 1 {
+			continue
+		}
+		if start >= 0 {
+			end := i
+			for end > start && b[end-1] == 0 {
+				end--
+			}
+			nals = append(nals, b[start:end])
+		}
+		start = i + 3
+		i += 2
+	}
+	if start >= 0 {
+		nals = append(nals, b[start:])
+	}
+	return nals
+}
+
+// unescape removes emulation prevention bytes
+func unescape(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	zeros := 0
+	for _, c := range b {
+		if zeros >= 2 && c == 3 {
+			zeros = 0
+			continue
+		}
+		if c == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (r *reader) ue() uint64 {
+	return r.uvlc()
+}
+
+func (r *reader) se() int64 {
+	v := r.ue()
+	if v&1 == 1 {
+		return int64(v+1) / 2
+	}
+	return -int64(v / 2)
+}
+
+// parseSPS reads seq_parameter_set_data and its VUI (H.264 section 7.3.2.1)
+func parseSPS(b []byte) (Info, error) {
+	r := &reader{b: b}
+	profile := r.bits(8)
+	r.bits(16) // constraint flags and level_idc
+	r.ue()
+	chroma := uint64(1)
+	depth := uint64(8)
+	if highProfiles[profile] {
+		chroma = r.ue()
+		if chroma == 3 {
+			r.bits(1)
+		}
+		depth += r.ue()
+		r.ue()
+		r.bits(1)
+		if r.flag() {
+			lists := 8
+			if chroma == 3 {
+				lists = 12
+			}
+			for i := 0; i < lists && r.err == nil; i++ {
+				if r.flag() {
+					size := 16
+					if i >= 6 {
+						size = 64
+					}
+					last, next := int64(8), int64(8)
+					for j := 0; j < size && r.err == nil; j++ {
+						if next != 0 {
+							next = (last + r.se() + 256) % 256
+						}
+						if next != 0 {
+							last = next
+						}
+					}
+				}
+			}
+		}
+	}
+	r.ue()
+	switch r.ue() {
+	case 0:
+		r.ue()
+	case 1:
+		r.bits(1)
+		r.se()
+		r.se()
+		n := r.ue()
+		for i := uint64(0); i < n && r.err == nil; i++ {
+			r.se()
+		}
+	}
+	r.ue()
+	r.bits(1)
+	widthMbs, heightMaps := r.ue()+1, r.ue()+1
+	frameMbsOnly := r.bits(1)
+	if frameMbsOnly == 0 {
+		r.bits(1)
+	}
+	r.bits(1)
+	var cropLeft, cropRight, cropTop, cropBottom uint64
+	if r.flag() {
+		cropLeft, cropRight, cropTop, cropBottom = r.ue(), r.ue(), r.ue(), r.ue()
+	}
+	cropX, cropY := uint64(1), 2-frameMbsOnly
+	if chroma == 1 || chroma == 2 {
+		cropX = 2
+	}
+	if chroma == 1 {
+		cropY *= 2
+	}
+
+	info := Info{
+		Width:    int(widthMbs*16 - cropX*(cropLeft+cropRight)),
+		Height:   int((2-frameMbsOnly)*heightMaps*16 - cropY*(cropTop+cropBottom)),
+		BitDepth: int(depth),
+	}
+	c := &info.Colour
+	c.BitsPerChannel = depth
+	switch chroma {
+	case 1:
+		c.ChromaSubsamplingHorz, c.ChromaSubsamplingVert = 1, 1
+	case 2:
+		c.ChromaSubsamplingHorz = 1
+	}
+	if r.flag() {
+		parseVUI(r, c)
+	}
+	if r.err != nil {
+		return Info{}, r.err
+	}
+	return info, nil
+}
+
+// parseVUI reads the colour of vui_parameters (H.264 section E.1.1)
+func parseVUI(r *reader, c *Colour) {
+	if r.flag() {
+		if r.bits(8) == 255 {
+			r.bits(32)
+		}
+	}
+	if r.flag() {
+		r.bits(1)
+	}
+	if r.flag() {
+		r.bits(3)
+		c.Range = RangeLimited
+		if r.flag() {
+			c.Range = RangeFull
+		}
+		if r.flag() {
+			c.Primaries, c.TransferCharacteristics, c.MatrixCoefficients = r.bits(8), r.bits(8), r.bits(8)
+		}
+	}
+}
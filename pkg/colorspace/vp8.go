@@ -0,0 +1,45 @@
+package colorspace
+
+// ParseVP8 reads the frame header of a VP8 keyframe. VP8 is always 8 bit
+// 4:2:0, and its only colour space is BT.601 in limited range.
+func ParseVP8(frame []byte) (Info, error) {
+	if len(frame) < 10 {
+		return Info{}, ErrInvalid
+	}
+	if frame[0]&0x1 != 0 {
+		return Info{}, ErrNotKeyframe
+	}
+	if frame[3] != 0x9d || frame[4] != 0x01 || frame[5] != 0x2a {
+		return Info{}, ErrInvalid
+	}
+	info := Info{
+		Width:    int(uint16(frame[6])|uint16(frame[7])<<8) & 0x3fff,
+		Height:   int(uint16(frame[8])|uint16(frame[9])<<8) & 0x3fff,
+		BitDepth: 8,
+		Colour: Colour{
+			BitsPerChannel:        8,
+			ChromaSubsamplingHorz: 1,
+			ChromaSubsamplingVert: 1,
+			Range:                 RangeLimited,
+		},
+	}
+	// color_space, the first bit of the boolean coded first partition
+	// (RFC 6386 section 9.2), is reserved when set
+	if len(frame) < 12 {
+		return Info{}, ErrInvalid
+	}
+	if readBool(frame[10:12]) {
+		info.Colour.MatrixCoefficients = unspecified
+	} else {
+		info.Colour.MatrixCoefficients = 6
+	}
+	return info, nil
+}
+
+// readBool decodes the first boolean of a boolean coded partition at even
+// probability (RFC 6386 section 7.3)
+func readBool(b []byte) bool {
+	value := uint(b[0])<<8 | uint(b[1])
+	split := uint(1 + (255-1)*128>>8)
+	return value >= split<<8
+}
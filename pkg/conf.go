@@ -1,6 +1,17 @@
 package avp
 
-import log "github.com/pion/ion-log"
+import (
+	"time"
+
+	"github.com/pion/ion-avp/pkg/alert"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/export"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/redundancy"
+	"github.com/pion/ion-avp/pkg/retry"
+	"github.com/pion/ion-avp/pkg/storage"
+	log "github.com/pion/ion-log"
+)
 
 type Samplebuilderconf struct {
 	AudioMaxLate uint16 `mapstructure:"audiomaxlate"`
@@ -19,9 +30,125 @@ type webrtcconf struct {
 	ICEServers   []iceconf `mapstructure:"iceserver"`
 }
 
+type networkconf struct {
+	Family string `mapstructure:"family"`
+}
+
+type highlightsconf struct {
+	Enabled bool               `mapstructure:"enabled"`
+	Window  time.Duration      `mapstructure:"window"`
+	Count   int                `mapstructure:"count"`
+	Weights map[string]float64 `mapstructure:"weights"`
+}
+
+type previewconf struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Duration  time.Duration `mapstructure:"duration"`
+	FrameRate int           `mapstructure:"framerate"`
+	Width     int           `mapstructure:"width"`
+	Highlight bool          `mapstructure:"highlight"`
+}
+
+type storyboardconf struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Width    int           `mapstructure:"width"`
+	Columns  int           `mapstructure:"columns"`
+	MaxTiles int           `mapstructure:"maxtiles"`
+}
+
+type reviewconf struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Suffix    string `mapstructure:"suffix"`
+	Corner    string `mapstructure:"corner"`
+	TimeOfDay bool   `mapstructure:"timeofday"`
+}
+
+type vadconf struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Threshold float64       `mapstructure:"threshold"`
+	Hangover  time.Duration `mapstructure:"hangover"`
+	Gate      bool          `mapstructure:"gate"`
+}
+
+type httpconf struct {
+	Addr  string `mapstructure:"addr"`
+	Root  string `mapstructure:"root"`
+	Token string `mapstructure:"token"`
+}
+
+type sandboxconf struct {
+	Command  string   `mapstructure:"command"`
+	Args     []string `mapstructure:"args"`
+	Elements []string `mapstructure:"elements"`
+}
+
+type ingestpipeline struct {
+	Eid    string `mapstructure:"eid"`
+	Config string `mapstructure:"config"`
+	Kind   string `mapstructure:"kind"`
+}
+
+type ingestconf struct {
+	Dir       string           `mapstructure:"dir"`
+	Interval  time.Duration    `mapstructure:"interval"`
+	Settle    time.Duration    `mapstructure:"settle"`
+	Pipelines []ingestpipeline `mapstructure:"pipeline"`
+}
+
+type contactsheetconf struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Max      int           `mapstructure:"max"`
+	Columns  int           `mapstructure:"columns"`
+	Width    int           `mapstructure:"width"`
+}
+
+type podcastconf struct {
+	Level    float64       `mapstructure:"level"`
+	Silence  float64       `mapstructure:"silence"`
+	MaxGain  float64       `mapstructure:"maxgain"`
+	MaxPause time.Duration `mapstructure:"maxpause"`
+	Chapter  time.Duration `mapstructure:"chapter"`
+	Bitrate  int           `mapstructure:"bitrate"`
+	Artist   string        `mapstructure:"artist"`
+}
+
+type polywavconf struct {
+	Channels int `mapstructure:"channels"`
+}
+
+type remoteconf struct {
+	// Elements maps element ids to the remote address running them
+	Elements map[string]string `mapstructure:"elements"`
+	Window   uint32            `mapstructure:"window"`
+}
+
 // Config for base AVP
 type Config struct {
-	Log           log.Config        `mapstructure:"log"`
-	SampleBuilder Samplebuilderconf `mapstructure:"samplebuilder"`
-	WebRTC        webrtcconf        `mapstructure:"webrtc"`
+	Log           log.Config         `mapstructure:"log"`
+	SampleBuilder Samplebuilderconf  `mapstructure:"samplebuilder"`
+	WebRTC        webrtcconf         `mapstructure:"webrtc"`
+	Network       networkconf        `mapstructure:"network"`
+	HTTP          httpconf           `mapstructure:"http"`
+	Storage       storage.Config     `mapstructure:"storage"`
+	Export        export.Config      `mapstructure:"export"`
+	Recording     recording.Config   `mapstructure:"recording"`
+	Retry         retry.Policy       `mapstructure:"retry"`
+	Sandbox       sandboxconf        `mapstructure:"sandbox"`
+	Remote        remoteconf         `mapstructure:"remote"`
+	LowMemory     LowMemoryConfig    `mapstructure:"lowmemory"`
+	Quality       QualityConfig      `mapstructure:"quality"`
+	Alert         alert.Config       `mapstructure:"alert"`
+	Highlights    highlightsconf     `mapstructure:"highlights"`
+	Preview       previewconf        `mapstructure:"preview"`
+	Storyboard    storyboardconf     `mapstructure:"storyboard"`
+	Review        reviewconf         `mapstructure:"review"`
+	VAD           vadconf            `mapstructure:"vad"`
+	Redundancy    redundancy.Config  `mapstructure:"redundancy"`
+	Colour        *colorspace.Colour `mapstructure:"colour"`
+	Simulcast     SimulcastConfig    `mapstructure:"simulcast"`
+	Ingest        ingestconf         `mapstructure:"ingest"`
+	ContactSheet  contactsheetconf   `mapstructure:"contactsheet"`
+	Podcast       podcastconf        `mapstructure:"podcast"`
+	PolyWAV       polywavconf        `mapstructure:"polywav"`
 }
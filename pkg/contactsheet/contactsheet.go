@@ -0,0 +1,230 @@
+// Package contactsheet renders a recording as a contact sheet, a grid of
+// thumbnails taken at fixed intervals, so a recording can be scanned at a
+// glance in the archive without playing it. Each thumbnail is labelled
+// with its time from the start of the recording.
+//
+// Frames are decoded from the segments of the recording's first video
+// track, which needs the libvpx build tag.
+package contactsheet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/ingest"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/pixel"
+)
+
+var (
+	// ErrUnsupported is returned for output formats that aren't images
+	ErrUnsupported = errors.New("contactsheet: unsupported format")
+	// ErrNoVideo is returned for recordings without video
+	ErrNoVideo = errors.New("contactsheet: recording has no video")
+	// ErrNoDecoder is returned when built without a video decoder
+	ErrNoDecoder = errors.New("contactsheet: no video decoder, build with libvpx")
+)
+
+// newDecoder creates the default decoder of Config.Decoder, set when
+// built with libvpx
+var newDecoder func() avp.Element
+
+// gap is the space around thumbnails
+const gap = 4
+
+// Config configures contact sheets
+type Config struct {
+	// Interval: Time between thumbnails, defaults to 10s. Long recordings
+	// space them further apart to keep within Max.
+	Interval time.Duration
+	// Max: Most thumbnails on a sheet, defaults to 60
+	Max int
+	// Columns: Thumbnails per row, defaults to 6
+	Columns int
+	// Width: Width of a thumbnail, defaults to 240. Its height keeps the
+	// aspect ratio of the first frame.
+	Width int
+	// Decoder: Creates the element decoding the video to YCbCr frames,
+	// defaults to a VP8 decoder
+	Decoder func() avp.Element
+}
+
+// Supported reports whether a contact sheet can be written to a file
+// of name, by its extension
+func Supported(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+// sampler keeps a thumbnail of the first frame decoded at or after each
+// interval
+type sampler struct {
+	interval time.Duration
+	max      int
+	width    int
+	height   int
+
+	at     time.Duration // of the frame being decoded
+	next   time.Duration
+	thumbs []*image.YCbCr
+	err    error
+}
+
+func (s *sampler) Write(sample *avp.Sample) error {
+	if s.at < s.next || s.err != nil || len(s.thumbs) == s.max {
+		return nil
+	}
+	m, ok := sample.Payload.(*image.YCbCr)
+	if !ok || m.SubsampleRatio != image.YCbCrSubsampleRatio420 {
+		img, ok := sample.Payload.(image.Image)
+		if !ok {
+			return nil
+		}
+		m = pixel.FromImage(img)
+	}
+	if m.Rect.Empty() {
+		return nil
+	}
+	if s.height == 0 {
+		s.height = (s.width*m.Rect.Dy()/m.Rect.Dx() + 1) &^ 1
+		if s.height < 2 {
+			s.height = 2
+		}
+	}
+	t := image.NewYCbCr(image.Rect(0, 0, s.width, s.height), image.YCbCrSubsampleRatio420)
+	if err := pixel.ScaleFit(t, m, pixel.FitPad); err != nil {
+		s.err = err
+		return err
+	}
+	pixel.Label(t, timecode(s.at))
+	s.thumbs = append(s.thumbs, t)
+	s.next = (s.at/s.interval + 1) * s.interval
+	return nil
+}
+
+func (s *sampler) Attach(e avp.Element) {}
+
+func (s *sampler) Close() {}
+
+// timecode formats d as [h:]mm:ss
+func timecode(d time.Duration) string {
+	secs := int(d / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
+
+// Render writes a contact sheet of the recording described by m to w.
+// format is "jpeg", "png" or a file name with its extension. progress, if
+// not nil, is called with the fraction of segments read.
+func Render(ctx context.Context, w io.Writer, format string, m *manifest.Manifest, open manifest.OpenFunc, c Config, progress func(float64)) error {
+	if ext := path.Ext(format); ext != "" {
+		format = ext[1:]
+	}
+	var encode func(io.Writer, image.Image) error
+	switch strings.ToLower(format) {
+	case "jpg", "jpeg":
+		encode = func(w io.Writer, m image.Image) error { return jpeg.Encode(w, m, nil) }
+	case "png":
+		encode = png.Encode
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupported, format)
+	}
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	if c.Max <= 0 {
+		c.Max = 60
+	}
+	if c.Columns <= 0 {
+		c.Columns = 6
+	}
+	if c.Width <= 0 {
+		c.Width = 240
+	}
+	c.Width &^= 1
+	if c.Decoder == nil {
+		c.Decoder = newDecoder
+	}
+	if c.Decoder == nil {
+		return ErrNoDecoder
+	}
+	if d := m.Duration(); d/c.Interval >= time.Duration(c.Max) {
+		// Round up to whole seconds so labels stay regular
+		c.Interval = (d/time.Duration(c.Max) + time.Second - 1).Truncate(time.Second)
+	}
+
+	s := &sampler{interval: c.Interval, max: c.Max, width: c.Width}
+	tracks, err := ingest.FindTracks(m, open, ingest.KindVideo, 1)
+	if err != nil {
+		return err
+	}
+	if len(tracks) == 0 {
+		return ErrNoVideo
+	}
+	segs, track := tracks[0].Segments, tracks[0].ID
+	dec := c.Decoder()
+	if dec == nil {
+		return ErrNoDecoder
+	}
+	dec.Attach(s)
+	defer dec.Close()
+	for i, seg := range segs {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		media, err := ingest.DemuxSegment(open, seg.Name)
+		if err != nil {
+			return err
+		}
+		for _, sample := range media.Samples {
+			if sample.ID != track {
+				continue
+			}
+			s.at = seg.Offset + time.Duration(int64(sample.Timestamp)*int64(time.Second)/int64(sample.ClockRate))
+			if err := dec.Write(sample); err != nil {
+				return fmt.Errorf("contactsheet: segment %s: %w", seg.Name, err)
+			}
+			if s.err != nil {
+				return s.err
+			}
+		}
+		if progress != nil {
+			progress(float64(i+1) / float64(len(segs)))
+		}
+	}
+	if len(s.thumbs) == 0 {
+		return ErrNoVideo
+	}
+	return encode(w, s.sheet(c.Columns))
+}
+
+// sheet lays out the thumbnails in rows of columns
+func (s *sampler) sheet(columns int) *image.YCbCr {
+	if len(s.thumbs) < columns {
+		columns = len(s.thumbs)
+	}
+	rows := (len(s.thumbs) + columns - 1) / columns
+	m := image.NewYCbCr(image.Rect(0, 0, columns*(s.width+gap)+gap, rows*(s.height+gap)+gap), image.YCbCrSubsampleRatio420)
+	pixel.Fill(m, pixel.Black)
+	for i, t := range s.thumbs {
+		pt := image.Pt(gap+i%columns*(s.width+gap), gap+i/columns*(s.height+gap))
+		pixel.Copy(m.SubImage(t.Rect.Add(pt)).(*image.YCbCr), t) // nolint: errcheck
+	}
+	return m
+}
@@ -0,0 +1,122 @@
+package contactsheet
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/manifest/manifesttest"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+// lumaDecoder decodes frames to a uniform 64x36 picture of the luma of
+// their first byte
+type lumaDecoder struct {
+	out avp.Element
+}
+
+func (d *lumaDecoder) Write(sample *avp.Sample) error {
+	m := image.NewYCbCr(image.Rect(0, 0, 64, 36), image.YCbCrSubsampleRatio420)
+	pixel.Fill(m, color.YCbCr{Y: sample.Payload.([]byte)[0], Cb: 128, Cr: 128})
+	return d.out.Write(&avp.Sample{Payload: m})
+}
+
+func (d *lumaDecoder) Attach(e avp.Element) {
+	d.out = e
+}
+
+func (d *lumaDecoder) Close() {}
+
+// segment has a frame of luma 10*(first+i) every 500ms for 5s, with audio
+// alongside unless video only
+func segment(t *testing.T, first int, video bool) []byte {
+	tracks := []webm.TrackEntry{manifesttest.Mic}
+	if video {
+		tracks = append(tracks, webm.TrackEntry{Name: "cam", TrackNumber: 2, TrackUID: 2, CodecID: "V_VP8", TrackType: 1})
+	}
+	var blocks []manifesttest.Block
+	for i := 0; i < 10; i++ {
+		ms := int64(i * 500)
+		blocks = append(blocks, manifesttest.Block{Track: 1, Time: ms, Data: []byte{0xf8, 0xff, 0xfe}})
+		if video {
+			blocks = append(blocks, manifesttest.Block{Track: 2, Time: ms, Data: []byte{byte(10 * (first + i))}})
+		}
+	}
+	return manifesttest.Segment(t, tracks, blocks)
+}
+
+func recording(t *testing.T, video bool) (*manifest.Manifest, manifest.OpenFunc) {
+	files := manifesttest.Files{
+		"0.webm": segment(t, 0, video),
+		"1.webm": segment(t, 10, video),
+	}
+	m := manifest.New("rec", "sid", time.Now())
+	m.Segments = manifesttest.Consecutive("alice", 5*time.Second, "0.webm", "1.webm")
+	return m, files.Open
+}
+
+func TestSupported(t *testing.T) {
+	assert.True(t, Supported("sheet.JPG"))
+	assert.True(t, Supported("sheet.png"))
+	assert.False(t, Supported("out.webm"))
+}
+
+func TestRender(t *testing.T) {
+	m, open := recording(t, true)
+	c := Config{
+		Interval: 2 * time.Second,
+		Columns:  3,
+		Width:    64,
+		Decoder:  func() avp.Element { return &lumaDecoder{} },
+	}
+	var progress []float64
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Render(context.Background(), buf, "sheet.png", m, open, c, func(p float64) {
+		progress = append(progress, p)
+	}))
+	assert.Equal(t, []float64{0.5, 1}, progress)
+
+	sheet, err := png.Decode(buf)
+	assert.NoError(t, err)
+	// Thumbnails at 0, 2, 4, 6 and 8s in rows of 3
+	assert.Equal(t, image.Rect(0, 0, 3*(64+gap)+gap, 2*(36+gap)+gap), sheet.Bounds())
+	luma := func(i int) uint8 {
+		x, y := gap+i%3*(64+gap)+60, gap+i/3*(36+gap)+2
+		return color.GrayModel.Convert(sheet.At(x, y)).(color.Gray).Y
+	}
+	for i, want := range []uint8{0, 40, 80, 120, 160} {
+		assert.InDelta(t, want, luma(i), 2, "thumbnail %d", i)
+	}
+	assert.InDelta(t, pixel.Black.Y, luma(5), 2)
+
+	// Long recordings space thumbnails out
+	c.Max = 3
+	buf.Reset()
+	assert.NoError(t, Render(context.Background(), buf, "png", m, open, c, nil))
+	sheet, err = png.Decode(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*(64+gap)+gap, sheet.Bounds().Dx())
+	assert.InDelta(t, 80, luma(1), 2)
+	assert.InDelta(t, 160, luma(2), 2)
+
+	err = Render(context.Background(), buf, "sheet.gif", m, open, c, nil)
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	m, open = recording(t, false)
+	err = Render(context.Background(), buf, "sheet.jpg", m, open, c, nil)
+	assert.ErrorIs(t, err, ErrNoVideo)
+}
+
+func TestTimecode(t *testing.T) {
+	assert.Equal(t, "00:00", timecode(0))
+	assert.Equal(t, "01:05", timecode(65*time.Second+time.Millisecond))
+	assert.Equal(t, "1:00:01", timecode(time.Hour+time.Second))
+}
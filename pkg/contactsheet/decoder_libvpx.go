@@ -0,0 +1,18 @@
+//go:build libvpx
+// +build libvpx
+
+package contactsheet
+
+import (
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+)
+
+func init() {
+	newDecoder = func() avp.Element {
+		if d := elements.NewDecoder(0, elements.TypeYCbCr); d != nil {
+			return d
+		}
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package avp
+
+import "context"
+
+// Correlated is implemented by elements started on behalf of a request
+// with a correlation ID, so their stats can be traced back to it
+type Correlated interface {
+	Correlation() string
+}
+
+type correlated struct {
+	Element
+	id string
+}
+
+func (c *correlated) Correlation() string {
+	return c.id
+}
+
+func (c *correlated) Priority() Priority {
+	return priorityOf(c.Element)
+}
+
+func (c *correlated) WriteContext(ctx context.Context, s *Sample) error {
+	return WriteContext(ctx, c.Element, s)
+}
+
+// WithCorrelation sets the correlation ID of an element
+func WithCorrelation(e Element, id string) Element {
+	if id == "" {
+		return e
+	}
+	return &correlated{Element: e, id: id}
+}
+
+func correlationOf(e Element) string {
+	for {
+		switch w := e.(type) {
+		case Correlated:
+			return w.Correlation()
+		case *prioritized:
+			e = w.Element
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package counter persists counters, such as the numbers of the segments
+// of a recording, so they carry on from where they were after the node
+// restarts or a recording resumes, rather than repeating numbers players
+// and stitching have already seen.
+package counter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store keeps counters in a JSON file, written whole on every change
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	counts map[string]int
+}
+
+// Open loads the counters of the file at path, which is created on the
+// first change if it doesn't exist
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, counts: make(map[string]int)}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &s.counts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the value of counter key, 0 if never set
+func (s *Store) Get(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[key]
+}
+
+// Set raises counter key to v, persisting it before returning. Counters
+// never go back, so lower values are ignored.
+func (s *Store) Set(key string, v int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v <= s.counts[key] {
+		return nil
+	}
+	s.counts[key] = v
+	return s.save()
+}
+
+// save writes the file to a temporary one renamed over it, so a crash
+// leaves the old counters or the new, never half of them
+func (s *Store) save() error {
+	b, err := json.Marshal(s.counts)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Counter returns counter key of the store
+func (s *Store) Counter(key string) *Counter {
+	return &Counter{s: s, key: key}
+}
+
+// Counter is one counter of a Store
+type Counter struct {
+	s   *Store
+	key string
+}
+
+// Load returns the value of the counter
+func (c *Counter) Load() int {
+	return c.s.Get(c.key)
+}
+
+// Store raises the counter to v
+func (c *Counter) Store(v int) error {
+	return c.s.Set(c.key, v)
+}
@@ -0,0 +1,36 @@
+package counter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state", "counters.json")
+
+	s, err := Open(path)
+	assert.NoError(t, err)
+	c := s.Counter("hls:sid/live")
+	assert.Equal(t, 0, c.Load())
+	assert.NoError(t, c.Store(3))
+	assert.NoError(t, c.Store(2))
+	assert.Equal(t, 3, c.Load())
+	assert.NoError(t, s.Set("segments:sid/a.webm", 7))
+
+	// Reopened, as after a restart
+	s, err = Open(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.Get("hls:sid/live"))
+	assert.Equal(t, 7, s.Get("segments:sid/a.webm"))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+	_, err = Open(path)
+	assert.Error(t, err)
+}
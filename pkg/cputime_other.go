@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package avp
+
+import "time"
+
+// threadCPU is not supported, so CPU estimates are zero
+func threadCPU() time.Duration {
+	return 0
+}
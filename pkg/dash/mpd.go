@@ -0,0 +1,152 @@
+// Package dash writes MPEG-DASH manifests (ISO/IEC 23009-1) of fMP4
+// segments, for the ISO BMFF live profile.
+//
+// Each representation's segments are addressed by a template with their
+// number, and timed by a segment timeline:
+//
+//	<Representation id="video" bandwidth="1200000" codecs="avc1.42001e" width="640" height="480">
+//	  <SegmentTemplate timescale="90000" initialization="video-init.mp4" media="video-$Number$.m4s" startNumber="0">
+//	    <SegmentTimeline><S t="0" d="360000" r="2"></S></SegmentTimeline>
+//	  </SegmentTemplate>
+//	</Representation>
+//
+// A live manifest is dynamic, players reloading it for new segments, and a
+// VOD one static.
+package dash
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrManifest is returned for manifests that can't be written
+var ErrManifest = errors.New("dash: invalid manifest")
+
+// ProfileLive is the ISO BMFF live profile
+const ProfileLive = "urn:mpeg:dash:profile:isoff-live:2011"
+
+// RoleScheme is the scheme of the roles of adaptation sets, e.g. "main"
+// for the audio played by default and "alternate" for others
+const RoleScheme = "urn:mpeg:dash:role:2011"
+
+// MPD is a manifest of one period
+type MPD struct {
+	XMLName  xml.Name `xml:"urn:mpeg:dash:schema:mpd:2011 MPD"`
+	Profiles string   `xml:"profiles,attr"`
+	Type     string   `xml:"type,attr"`
+	// AvailabilityStartTime and PublishTime of dynamic manifests
+	AvailabilityStartTime *time.Time `xml:"availabilityStartTime,attr,omitempty"`
+	PublishTime           *time.Time `xml:"publishTime,attr,omitempty"`
+	// MediaPresentationDuration of static manifests
+	MediaPresentationDuration Duration `xml:"mediaPresentationDuration,attr,omitempty"`
+	MinimumUpdatePeriod       Duration `xml:"minimumUpdatePeriod,attr,omitempty"`
+	TimeShiftBufferDepth      Duration `xml:"timeShiftBufferDepth,attr,omitempty"`
+	MinBufferTime             Duration `xml:"minBufferTime,attr"`
+	Period                    Period   `xml:"Period"`
+}
+
+// Period of the media
+type Period struct {
+	ID             string          `xml:"id,attr"`
+	Start          Duration        `xml:"start,attr"`
+	AdaptationSets []AdaptationSet `xml:"AdaptationSet"`
+}
+
+// AdaptationSet groups the representations of a track. Lang is an RFC
+// 5646 tag, for players to pick between tracks of several languages.
+type AdaptationSet struct {
+	ContentType      string           `xml:"contentType,attr"`
+	MimeType         string           `xml:"mimeType,attr"`
+	Lang             string           `xml:"lang,attr,omitempty"`
+	SegmentAlignment bool             `xml:"segmentAlignment,attr"`
+	StartWithSAP     int              `xml:"startWithSAP,attr"`
+	Role             *Descriptor      `xml:"Role,omitempty"`
+	Representations  []Representation `xml:"Representation"`
+}
+
+// Descriptor is a property of a scheme, such as a Role
+type Descriptor struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr"`
+}
+
+// Representation is an encoding of a track
+type Representation struct {
+	ID                string          `xml:"id,attr"`
+	Bandwidth         int             `xml:"bandwidth,attr"`
+	Codecs            string          `xml:"codecs,attr"`
+	Width             int             `xml:"width,attr,omitempty"`
+	Height            int             `xml:"height,attr,omitempty"`
+	AudioSamplingRate int             `xml:"audioSamplingRate,attr,omitempty"`
+	SegmentTemplate   SegmentTemplate `xml:"SegmentTemplate"`
+}
+
+// SegmentTemplate addresses the segments of a representation
+type SegmentTemplate struct {
+	Timescale      uint32 `xml:"timescale,attr"`
+	Initialization string `xml:"initialization,attr"`
+	Media          string `xml:"media,attr"`
+	StartNumber    int    `xml:"startNumber,attr"`
+	Timeline       []S    `xml:"SegmentTimeline>S"`
+}
+
+// S is a run of segments of the same duration, R repeats after the first.
+// Times are in the timescale of the template.
+type S struct {
+	T uint64 `xml:"t,attr"`
+	D uint64 `xml:"d,attr"`
+	R int    `xml:"r,attr,omitempty"`
+}
+
+// Append adds a segment at t lasting d to a timeline
+func Append(timeline []S, t, d uint64) []S {
+	if n := len(timeline); n > 0 {
+		last := &timeline[n-1]
+		if last.D == d && last.T+uint64(last.R+1)*last.D == t {
+			last.R++
+			return timeline
+		}
+	}
+	return append(timeline, S{T: t, D: d})
+}
+
+// Trim removes the first segment of a timeline
+func Trim(timeline []S) []S {
+	if len(timeline) == 0 {
+		return timeline
+	}
+	if first := &timeline[0]; first.R > 0 {
+		first.T += first.D
+		first.R--
+		return timeline
+	}
+	return timeline[1:]
+}
+
+// Duration is an xs:duration, omitted if zero
+type Duration time.Duration
+
+// MarshalXMLAttr writes the duration in seconds, e.g. PT4.500S
+func (d Duration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if d == 0 {
+		return xml.Attr{}, nil
+	}
+	s := fmt.Sprintf("PT%.3fS", time.Duration(d).Seconds())
+	return xml.Attr{Name: name, Value: s}, nil
+}
+
+// WriteTo writes the manifest to w
+func (m *MPD) WriteTo(w io.Writer) (int64, error) {
+	if len(m.Period.AdaptationSets) == 0 {
+		return 0, fmt.Errorf("%w: no adaptation sets", ErrManifest)
+	}
+	b, err := xml.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.WriteString(w, xml.Header+string(b)+"\n")
+	return int64(n), err
+}
@@ -0,0 +1,86 @@
+package dash
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeline(t *testing.T) {
+	var tl []S
+	tl = Append(tl, 0, 90000)
+	tl = Append(tl, 90000, 90000)
+	tl = Append(tl, 180000, 45000)
+	tl = Append(tl, 225000, 90000)
+	assert.Equal(t, []S{{T: 0, D: 90000, R: 1}, {T: 180000, D: 45000}, {T: 225000, D: 90000}}, tl)
+
+	tl = Trim(tl)
+	assert.Equal(t, []S{{T: 90000, D: 90000}, {T: 180000, D: 45000}, {T: 225000, D: 90000}}, tl)
+	tl = Trim(Trim(tl))
+	assert.Equal(t, []S{{T: 225000, D: 90000}}, tl)
+}
+
+func TestMPD(t *testing.T) {
+	start := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	m := &MPD{
+		Profiles:              ProfileLive,
+		Type:                  "dynamic",
+		AvailabilityStartTime: &start,
+		PublishTime:           &start,
+		MinimumUpdatePeriod:   Duration(4 * time.Second),
+		MinBufferTime:         Duration(2 * time.Second),
+		Period: Period{ID: "0", AdaptationSets: []AdaptationSet{{
+			ContentType:      "audio",
+			MimeType:         "audio/mp4",
+			SegmentAlignment: true,
+			StartWithSAP:     1,
+			Representations: []Representation{{
+				ID:                "audio",
+				Bandwidth:         64000,
+				Codecs:            "opus",
+				AudioSamplingRate: 48000,
+				SegmentTemplate: SegmentTemplate{
+					Timescale:      48000,
+					Initialization: "audio-init.mp4",
+					Media:          "audio-$Number$.m4s",
+					Timeline:       []S{{T: 0, D: 192000, R: 2}},
+				},
+			}},
+		}}},
+	}
+	var b bytes.Buffer
+	_, err := m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011" type="dynamic" availabilityStartTime="2021-02-01T10:00:00Z" publishTime="2021-02-01T10:00:00Z" minimumUpdatePeriod="PT4.000S" minBufferTime="PT2.000S">
+  <Period id="0">
+    <AdaptationSet contentType="audio" mimeType="audio/mp4" segmentAlignment="true" startWithSAP="1">
+      <Representation id="audio" bandwidth="64000" codecs="opus" audioSamplingRate="48000">
+        <SegmentTemplate timescale="48000" initialization="audio-init.mp4" media="audio-$Number$.m4s" startNumber="0">
+          <SegmentTimeline>
+            <S t="0" d="192000" r="2"></S>
+          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`, b.String())
+
+	// Tracks of several languages
+	set := &m.Period.AdaptationSets[0]
+	set.Lang = "fr"
+	set.Role = &Descriptor{SchemeIDURI: RoleScheme, Value: "alternate"}
+	b.Reset()
+	_, err = m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), `<AdaptationSet contentType="audio" mimeType="audio/mp4" lang="fr" segmentAlignment="true" startWithSAP="1">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="alternate"></Role>
+      <Representation id="audio"`)
+
+	_, err = (&MPD{}).WriteTo(&b)
+	assert.True(t, errors.Is(err, ErrManifest))
+}
@@ -1,8 +1,27 @@
 package avp
 
+import "context"
+
 // Element interface
 type Element interface {
 	Write(*Sample) error
 	Attach(Element)
 	Close()
 }
+
+// ContextElement is an Element whose writes can be canceled, e.g. ones
+// uploading or encoding. Pipelines write to it with a context that is
+// canceled when a pipeline being torn down doesn't drain in time, so a
+// stuck write returns instead of holding up teardown.
+type ContextElement interface {
+	Element
+	WriteContext(ctx context.Context, sample *Sample) error
+}
+
+// WriteContext writes a sample to e, with ctx if e is a ContextElement
+func WriteContext(ctx context.Context, e Element, s *Sample) error {
+	if ce, ok := e.(ContextElement); ok {
+		return ce.WriteContext(ctx, s)
+	}
+	return e.Write(s)
+}
@@ -0,0 +1,80 @@
+package elements
+
+import (
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/storage"
+)
+
+// defaultGCSChunkSize is the size of the chunks uploaded unless configured
+const defaultGCSChunkSize = 8 * 1024 * 1024
+
+// GCSWriterConfig configures GCSWriter.
+// GCS: The bucket and credentials uploaded with. ChunkSize is the size of
+// each chunk, 8MiB by default, a multiple of 256KiB.
+// Name: Of the object, under the bucket's Prefix.
+// OnComplete: Optional callback with the name of the object once uploaded.
+// Recording: Optional state machine moved along as the upload progresses.
+type GCSWriterConfig struct {
+	GCS        storage.GCSConfig
+	Name       string
+	OnComplete func(name string)
+	Recording  *recording.Recording
+}
+
+// GCSWriter instance
+type GCSWriter struct {
+	objectWriter
+}
+
+// NewGCSWriter instance. GCSWriter writes the bytes it receives, as
+// FileWriter does to a file, to an object in a Google Cloud Storage bucket
+// as a resumable upload, a chunk at a time, so recordings never touch
+// local disk. The object is created when the writer is closed.
+func NewGCSWriter(c GCSWriterConfig) (*GCSWriter, error) {
+	if c.GCS.ChunkSize <= 0 {
+		c.GCS.ChunkSize = defaultGCSChunkSize
+	}
+	gcs, err := storage.NewGCS(c.GCS)
+	if err != nil {
+		return nil, err
+	}
+	w := &GCSWriter{objectWriter{kind: "GCSWriter", key: gcs.Key(c.Name), done: c.OnComplete, rec: c.Recording}}
+	if err := w.open(gcs, c.Name); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// AzureBlobWriterConfig configures AzureBlobWriter.
+// Azure: The container and credentials uploaded with. BlockSize is the
+// size of each block, 4MiB by default.
+// Name: Of the blob, under the container's Prefix.
+// OnComplete: Optional callback with the name of the blob once uploaded.
+// Recording: Optional state machine moved along as the upload progresses.
+type AzureBlobWriterConfig struct {
+	Azure      storage.AzureConfig
+	Name       string
+	OnComplete func(name string)
+	Recording  *recording.Recording
+}
+
+// AzureBlobWriter instance
+type AzureBlobWriter struct {
+	objectWriter
+}
+
+// NewAzureBlobWriter instance. AzureBlobWriter writes the bytes it
+// receives, as FileWriter does to a file, to a block blob in an Azure
+// storage container, a block at a time, so recordings never touch local
+// disk. The blob is committed when the writer is closed.
+func NewAzureBlobWriter(c AzureBlobWriterConfig) (*AzureBlobWriter, error) {
+	azure, err := storage.NewAzure(c.Azure)
+	if err != nil {
+		return nil, err
+	}
+	w := &AzureBlobWriter{objectWriter{kind: "AzureBlobWriter", key: azure.Key(c.Name), done: c.OnComplete, rec: c.Recording}}
+	if err := w.open(azure, c.Name); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
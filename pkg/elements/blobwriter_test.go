@@ -0,0 +1,74 @@
+package elements
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pion/ion-avp/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAzureBlobWriter(t *testing.T) {
+	var blocks [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rec/live/a.webm", r.URL.Path)
+		if r.URL.Query().Get("comp") == "block" {
+			b, _ := ioutil.ReadAll(r.Body)
+			blocks = append(blocks, b)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	var name string
+	w, err := NewAzureBlobWriter(AzureBlobWriterConfig{
+		Azure:      storage.AzureConfig{Container: "rec", Prefix: "live/", Endpoint: srv.URL, BlockSize: 4},
+		Name:       "a.webm",
+		OnComplete: func(n string) { name = n },
+	})
+	assert.NoError(t, err)
+
+	s := NewSampleWriter()
+	s.Attach(w)
+	_, err = s.Write([]byte("012345"))
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Empty(t, name)
+	assert.NoError(t, s.Close())
+	assert.Len(t, blocks, 2)
+	assert.Equal(t, "live/a.webm", name)
+}
+
+func TestGCSWriter(t *testing.T) {
+	_, err := NewGCSWriter(GCSWriterConfig{Name: "a.webm"})
+	assert.Error(t, err, "bucket is required")
+
+	var uploaded []byte
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Header().Set("Location", srv.URL+"/session")
+			return
+		}
+		assert.Equal(t, "bytes 0-4/5", r.Header.Get("Content-Range"))
+		uploaded, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	var name string
+	w, err := NewGCSWriter(GCSWriterConfig{
+		GCS:        storage.GCSConfig{Bucket: "rec", Token: "token", Endpoint: srv.URL},
+		Name:       "a.webm",
+		OnComplete: func(n string) { name = n },
+	})
+	assert.NoError(t, err)
+	s := NewSampleWriter()
+	s.Attach(w)
+	_, err = s.Write([]byte("small"))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Close())
+	assert.Equal(t, "small", string(uploaded))
+	assert.Equal(t, "a.webm", name)
+}
@@ -0,0 +1,431 @@
+package elements
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/at-wat/ebml-go"
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/colorspace"
+)
+
+// maxBlockDelay is the most blocks held back to interleave tracks by
+// timestamp, as ebml-go's default block interceptor does
+const maxBlockDelay = 16
+
+// maxRelativeTimecode is the furthest a block can be from its cluster
+const maxRelativeTimecode = 0x7FFF
+
+// blockDuration returns the duration of a block from its data, or 0 if
+// it can't tell
+type blockDuration func(b []byte) time.Duration
+
+type mkvSeek struct {
+	SeekID       []byte `ebml:"SeekID"`
+	SeekPosition uint64 `ebml:"SeekPosition,size=8"`
+}
+
+type mkvHeader struct {
+	Header  *webm.EBMLHeader `ebml:"EBML"`
+	Segment struct {
+		SeekHead struct {
+			Seek []mkvSeek `ebml:"Seek"`
+		} `ebml:"SeekHead"`
+		Info   *webm.Info `ebml:"Info"`
+		Tracks struct {
+			TrackEntry []mkvTrackEntry `ebml:"TrackEntry"`
+		} `ebml:"Tracks"`
+		Tags *mkvTags `ebml:"Tags"`
+	} `ebml:"Segment,size=unknown"`
+}
+
+// mkvTags are tags of the tracks of a stream, each a name and value
+type mkvTags struct {
+	Tag []mkvTag `ebml:"Tag"`
+}
+
+type mkvTag struct {
+	Targets struct {
+		TagTrackUID []uint64 `ebml:"TagTrackUID"`
+	} `ebml:"Targets"`
+	SimpleTag []mkvSimpleTag `ebml:"SimpleTag"`
+}
+
+type mkvSimpleTag struct {
+	TagName   string `ebml:"TagName"`
+	TagString string `ebml:"TagString"`
+}
+
+// mkvTrackEntry is webm.TrackEntry with the colour of its video
+type mkvTrackEntry struct {
+	Name            string      `ebml:"Name,omitempty"`
+	TrackNumber     uint64      `ebml:"TrackNumber"`
+	TrackUID        uint64      `ebml:"TrackUID"`
+	CodecID         string      `ebml:"CodecID"`
+	CodecPrivate    []byte      `ebml:"CodecPrivate,omitempty"`
+	CodecDelay      uint64      `ebml:"CodecDelay,omitempty"`
+	TrackType       uint64      `ebml:"TrackType"`
+	DefaultDuration uint64      `ebml:"DefaultDuration,omitempty"`
+	SeekPreRoll     uint64      `ebml:"SeekPreRoll,omitempty"`
+	Audio           *webm.Audio `ebml:"Audio"`
+	Video           *mkvVideo   `ebml:"Video"`
+}
+
+type mkvVideo struct {
+	PixelWidth  uint64             `ebml:"PixelWidth"`
+	PixelHeight uint64             `ebml:"PixelHeight"`
+	Colour      *colorspace.Colour `ebml:"Colour"`
+}
+
+func newTrackEntry(t webm.TrackEntry, colour *colorspace.Colour) mkvTrackEntry {
+	e := mkvTrackEntry{
+		Name:            t.Name,
+		TrackNumber:     t.TrackNumber,
+		TrackUID:        t.TrackUID,
+		CodecID:         t.CodecID,
+		CodecPrivate:    t.CodecPrivate,
+		CodecDelay:      t.CodecDelay,
+		TrackType:       t.TrackType,
+		DefaultDuration: t.DefaultDuration,
+		SeekPreRoll:     t.SeekPreRoll,
+		Audio:           t.Audio,
+	}
+	if t.Video != nil {
+		e.Video = &mkvVideo{
+			PixelWidth:  t.Video.PixelWidth,
+			PixelHeight: t.Video.PixelHeight,
+			Colour:      colour,
+		}
+	}
+	return e
+}
+
+// ebmlHeader returns the EBML header of a stream of tracks, Matroska's if
+// a track's codec isn't allowed in WebM
+func ebmlHeader(tracks []webm.TrackEntry) *webm.EBMLHeader {
+	for _, t := range tracks {
+		if t.CodecID == codecH264 {
+			h := *webm.DefaultEBMLHeader
+			h.DocType = "matroska"
+			return &h
+		}
+	}
+	return webm.DefaultEBMLHeader
+}
+
+type mkvBlock struct {
+	track    *trackWriter
+	keyframe bool
+	ts       int64
+	b        []byte
+	duration int64
+	last     bool
+}
+
+// blockWriter muxes tracks into a Matroska stream like ebml-go's
+// SimpleBlock writer, but holds back each track's latest block until its
+// duration is known. Blocks of tracks with a DefaultDuration that last
+// otherwise, and the last block of each track, are written as BlockGroups
+// with a BlockDuration so demuxers get durations right. Timecodes aren't
+// rebased on the first block, so tracks aligned to an epoch start where
+// they were placed.
+type blockWriter struct {
+	mu      sync.Mutex
+	w       io.WriteCloser
+	scale   int64
+	tracks  []*trackWriter
+	open    int
+	ready   []*mkvBlock
+	started bool
+	cluster int64
+	last    int64
+	err     error
+}
+
+// trackWriter is the webm.BlockWriteCloser of one track
+type trackWriter struct {
+	bw              *blockWriter
+	number          uint64
+	defaultDuration int64
+	duration        blockDuration
+	pending         *mkvBlock
+	prevTs          int64
+	hasPrev         bool
+	prevDuration    int64
+	written         bool
+	closed          bool
+}
+
+// newBlockWriter writes the header of a stream of tracks to w and returns
+// a writer for each track. durations gives the duration of blocks of a
+// track from their data, otherwise it is the time to the track's next
+// block. colours gives the colour of video tracks, when known. w is
+// closed once every track is.
+func newBlockWriter(w io.WriteCloser, tracks []webm.TrackEntry, info *webm.Info, durations map[uint64]blockDuration, colours map[uint64]*colorspace.Colour) ([]webm.BlockWriteCloser, error) {
+	if info == nil {
+		info = webm.DefaultSegmentInfo
+	}
+	if err := writeMkvHeader(w, tracks, info, colours, nil); err != nil {
+		return nil, err
+	}
+	bw := newClusterWriter(w, info)
+	ws := make([]webm.BlockWriteCloser, 0, len(tracks))
+	for _, t := range tracks {
+		ws = append(ws, bw.addTrack(t, durations[t.TrackNumber]))
+	}
+	return ws, nil
+}
+
+// writeMkvHeader writes the EBML header and the start of a segment of
+// tracks, up to its first cluster, with tags of the tracks if any
+func writeMkvHeader(w io.Writer, tracks []webm.TrackEntry, info *webm.Info, colours map[uint64]*colorspace.Colour, tags *mkvTags) error {
+	var header mkvHeader
+	header.Header = ebmlHeader(tracks)
+	header.Segment.Info = info
+	for _, t := range tracks {
+		header.Segment.Tracks.TrackEntry = append(header.Segment.Tracks.TrackEntry, newTrackEntry(t, colours[t.TrackNumber]))
+	}
+	header.Segment.SeekHead.Seek = []mkvSeek{
+		{SeekID: ebml.ElementInfo.Bytes()},
+		{SeekID: ebml.ElementTracks.Bytes()},
+	}
+	if tags != nil {
+		header.Segment.Tags = tags
+		header.Segment.SeekHead.Seek = append(header.Segment.SeekHead.Seek, mkvSeek{SeekID: ebml.ElementTags.Bytes()})
+	}
+	// Positions are relative to the segment's contents, where the SeekHead
+	// starts. The fixed size positions make the second pass identical.
+	var segment uint64
+	hook := ebml.WithElementWriteHooks(func(e *ebml.Element) {
+		switch e.Name {
+		case "SeekHead":
+			segment = e.Position
+		case "Info":
+			header.Segment.SeekHead.Seek[0].SeekPosition = e.Position - segment
+		case "Tracks":
+			header.Segment.SeekHead.Seek[1].SeekPosition = e.Position - segment
+		case "Tags":
+			header.Segment.SeekHead.Seek[2].SeekPosition = e.Position - segment
+		}
+	})
+	if err := ebml.Marshal(&header, &bytes.Buffer{}, hook); err != nil {
+		return err
+	}
+	return ebml.Marshal(&header, w)
+}
+
+// newClusterWriter returns a blockWriter of the clusters of a stream to w,
+// after its header, without tracks until they are added
+func newClusterWriter(w io.WriteCloser, info *webm.Info) *blockWriter {
+	scale := int64(info.TimecodeScale)
+	if scale <= 0 {
+		scale = int64(time.Millisecond)
+	}
+	return &blockWriter{w: w, scale: scale}
+}
+
+// addTrack returns the writer of a track of the stream. Tracks can be
+// added while others are written to streams whose header comes later.
+func (bw *blockWriter) addTrack(t webm.TrackEntry, duration blockDuration) *trackWriter {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	tw := &trackWriter{
+		bw:              bw,
+		number:          t.TrackNumber,
+		defaultDuration: int64(t.DefaultDuration) / bw.scale,
+		duration:        duration,
+	}
+	bw.tracks = append(bw.tracks, tw)
+	bw.open++
+	return tw
+}
+
+// Write queues a block. Timestamps are in units of the TimecodeScale and
+// must be in order within the track.
+func (t *trackWriter) Write(keyframe bool, timestamp int64, b []byte) (int, error) {
+	t.bw.mu.Lock()
+	defer t.bw.mu.Unlock()
+	if t.bw.err != nil {
+		return 0, t.bw.err
+	}
+	if t.pending != nil {
+		t.bw.push(t.finish(timestamp-t.pending.ts, false))
+	}
+	t.pending = &mkvBlock{track: t, keyframe: keyframe, ts: timestamp, b: b}
+	t.written = true
+	return len(b), t.bw.flush(false)
+}
+
+// finish sets the duration of the pending block, from its data if the
+// track can tell or else next, the time to the next block
+func (t *trackWriter) finish(next int64, last bool) *mkvBlock {
+	blk := t.pending
+	t.pending = nil
+	blk.duration = next
+	if t.duration != nil {
+		if d := int64(t.duration(blk.b)) / t.bw.scale; d > 0 {
+			blk.duration = d
+		}
+	}
+	blk.last = last
+	t.prevDuration = blk.duration
+	return blk
+}
+
+// Close writes the track's last block. The stream is finished once every
+// track is closed.
+func (t *trackWriter) Close() error {
+	t.bw.mu.Lock()
+	defer t.bw.mu.Unlock()
+	if t.closed {
+		return t.bw.err
+	}
+	t.closed = true
+	if t.pending != nil {
+		// Without a next block, assume it lasts as long as the one before
+		next := t.prevDuration
+		if next <= 0 {
+			next = t.defaultDuration
+		}
+		t.bw.push(t.finish(next, true))
+	}
+	return t.bw.done()
+}
+
+// hold keeps the stream open until release, whether or not tracks are,
+// for streams tracks join and leave
+func (bw *blockWriter) hold() {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.open++
+}
+
+// release lets go of the hold on the stream, ending it once every track
+// is closed
+func (bw *blockWriter) release() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.done()
+}
+
+// done notes a track or other holder of the stream is finished, and ends
+// the stream once every one is
+func (bw *blockWriter) done() error {
+	bw.open--
+	if bw.open > 0 {
+		return bw.flush(false)
+	}
+	err := bw.flush(true)
+	if err == nil {
+		err = bw.finish()
+	}
+	if cerr := bw.w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// push adds a block whose duration is known to those ready to write
+func (bw *blockWriter) push(blk *mkvBlock) {
+	i := sort.Search(len(bw.ready), func(i int) bool { return bw.ready[i].ts > blk.ts })
+	bw.ready = append(bw.ready, nil)
+	copy(bw.ready[i+1:], bw.ready[i:])
+	bw.ready[i] = blk
+}
+
+// flush writes ready blocks no later than what every track has written
+// since, or all of them
+func (bw *blockWriter) flush(all bool) error {
+	for len(bw.ready) > 0 {
+		if !all && len(bw.ready) <= maxBlockDelay && !bw.due(bw.ready[0].ts) {
+			return nil
+		}
+		blk := bw.ready[0]
+		bw.ready = bw.ready[1:]
+		if err := bw.write(blk); err != nil {
+			bw.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// due reports whether no open track that has started can still write a
+// block before ts
+func (bw *blockWriter) due(ts int64) bool {
+	for _, t := range bw.tracks {
+		if t.closed || !t.written {
+			continue
+		}
+		if t.pending == nil || t.pending.ts < ts {
+			return false
+		}
+	}
+	return true
+}
+
+func (bw *blockWriter) write(blk *mkvBlock) error {
+	if !bw.started {
+		bw.started = true
+		if err := bw.startCluster(blk.ts); err != nil {
+			return err
+		}
+	}
+	rel := blk.ts - bw.cluster
+	if rel >= maxRelativeTimecode {
+		if err := bw.startCluster(blk.ts); err != nil {
+			return err
+		}
+		rel = 0
+	}
+	if rel <= -maxRelativeTimecode {
+		// Too old for the cluster, as ebml-go drops them
+		return nil
+	}
+	bw.last = blk.ts
+
+	block := ebml.Block{
+		TrackNumber: blk.track.number,
+		Timecode:    int16(rel),
+		Keyframe:    blk.keyframe,
+		Data:        [][]byte{blk.b},
+	}
+	t := blk.track
+	defer func() { t.prevTs, t.hasPrev = blk.ts, true }()
+	// Without a DefaultDuration a block lasts until the next
+	if !blk.last && (t.defaultDuration == 0 || blk.duration <= 0 || blk.duration == t.defaultDuration) {
+		return ebml.Marshal(&struct {
+			Block ebml.Block `ebml:"SimpleBlock"`
+		}{block}, bw.w)
+	}
+
+	group := webm.BlockGroup{Block: block}
+	if blk.duration > 0 {
+		group.BlockDuration = uint64(blk.duration)
+	}
+	if !blk.keyframe && t.hasPrev && t.prevTs < blk.ts {
+		// Blocks in groups are keyframes unless they reference another
+		group.ReferenceBlock = t.prevTs - blk.ts
+	}
+	return ebml.Marshal(&struct {
+		BlockGroup webm.BlockGroup `ebml:"BlockGroup"`
+	}{group}, bw.w)
+}
+
+func (bw *blockWriter) startCluster(ts int64) error {
+	bw.cluster = ts
+	return ebml.Marshal(&struct {
+		Cluster webm.Cluster `ebml:"Cluster,size=unknown"`
+	}{webm.Cluster{Timecode: uint64(ts)}}, bw.w)
+}
+
+// finish ends the stream with an empty cluster at the last block, as
+// ebml-go does
+func (bw *blockWriter) finish() error {
+	return ebml.Marshal(&struct {
+		Cluster webm.Cluster `ebml:"Cluster,size=unknown"`
+	}{webm.Cluster{Timecode: uint64(bw.last)}}, bw.w)
+}
@@ -0,0 +1,99 @@
+package elements
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go"
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/stretchr/testify/assert"
+)
+
+type nopWriteCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *nopWriteCloser) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestBlockWriter(t *testing.T) {
+	out := &nopWriteCloser{}
+	ws, err := newBlockWriter(out, []webm.TrackEntry{
+		{TrackNumber: 1, CodecID: "A_OPUS", TrackType: 2, DefaultDuration: 20000000},
+		{TrackNumber: 2, CodecID: "V_VP8", TrackType: 1},
+	}, nil, map[uint64]blockDuration{
+		1: func(b []byte) time.Duration {
+			d, _ := opus.PacketDuration(b)
+			return d
+		},
+	}, nil)
+	assert.NoError(t, err)
+	audio, video := ws[0], ws[1]
+
+	opus20, opus60 := []byte{0xf8, 0xff, 0xfe}, []byte{0x18}
+	for _, w := range []struct {
+		audio    bool
+		keyframe bool
+		ts       int64
+		b        []byte
+	}{
+		{true, true, 0, opus20},
+		{false, true, 0, []byte{1}},
+		{true, true, 20, opus60},
+		{false, false, 33, []byte{2}},
+		{true, true, 80, opus20},
+		{false, false, 66, []byte{3}},
+		{true, true, 100, opus20},
+	} {
+		bw := video
+		if w.audio {
+			bw = audio
+		}
+		_, err := bw.Write(w.keyframe, w.ts, w.b)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, audio.Close())
+	assert.False(t, out.closed)
+	assert.NoError(t, video.Close())
+	assert.True(t, out.closed)
+
+	var header Header
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(out.Bytes()), &header))
+	assert.Len(t, header.Segment.Tracks.TrackEntry, 2)
+
+	type block struct {
+		track    uint64
+		ts       int64
+		duration uint64
+	}
+	var blocks []block
+	for _, c := range header.Segment.Cluster {
+		for _, b := range c.SimpleBlock {
+			blocks = append(blocks, block{b.TrackNumber, int64(c.Timecode) + int64(b.Timecode), 0})
+		}
+		for _, g := range c.BlockGroup {
+			blocks = append(blocks, block{g.Block.TrackNumber, int64(c.Timecode) + int64(g.Block.Timecode), g.BlockDuration})
+		}
+	}
+	assert.ElementsMatch(t, []block{
+		{1, 0, 0},
+		{2, 0, 0},
+		{1, 20, 60}, // 60ms in a 20ms track
+		{2, 33, 0},
+		{1, 80, 0},
+		{2, 66, 33},  // last video frame, as long as the one before
+		{1, 100, 20}, // last audio packet
+	}, blocks)
+
+	// The SeekHead points at the Info and Tracks
+	seek := header.Segment.SeekHead.Seek
+	assert.Len(t, seek, 2)
+	segment := out.Bytes()[bytes.Index(out.Bytes(), []byte{0x18, 0x53, 0x80, 0x67})+4+8:]
+	assert.Equal(t, ebml.ElementInfo.Bytes(), segment[seek[0].SeekPosition:][:4])
+	assert.Equal(t, ebml.ElementTracks.Bytes(), segment[seek[1].SeekPosition:][:4])
+}
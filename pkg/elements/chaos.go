@@ -0,0 +1,198 @@
+package elements
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+)
+
+// ChaosConfig configures a Chaos element. Probabilities are of each sample,
+// from 0 for never to 1 for always.
+// Seed: Of the random choices, so a failing run can be repeated.
+// Types: Sample types mistreated, every type by default.
+// Drop: Probability a sample is left out.
+// Reorder: Probability a sample is held back behind the next Depth.
+// Depth: How many samples reordered samples are held back behind,
+// defaults to 1.
+// Corrupt: Probability bytes of a sample's payload are flipped.
+// Truncate: Probability a sample's payload is cut short.
+// Delay: Probability writing a sample waits up to MaxDelay, holding up
+// the pipeline as a slow element would.
+// MaxDelay: Longest delay, defaults to 100ms.
+type ChaosConfig struct {
+	Seed     int64
+	Types    []int
+	Drop     float64
+	Reorder  float64
+	Depth    int
+	Corrupt  float64
+	Truncate float64
+	Delay    float64
+	MaxDelay time.Duration
+}
+
+// ChaosStats counts the samples a Chaos element has mistreated
+type ChaosStats struct {
+	Dropped   int
+	Reordered int
+	Corrupted int
+	Truncated int
+	Delayed   int
+}
+
+// heldSample is a reordered sample, written after the next samples
+type heldSample struct {
+	sample *avp.Sample
+	behind int // samples left to write before it
+}
+
+// Chaos instance
+type Chaos struct {
+	Node
+	mu    sync.Mutex
+	cfg   ChaosConfig
+	rand  *rand.Rand
+	types map[int]bool
+	held  []heldSample
+	stats ChaosStats
+	sleep func(time.Duration)
+}
+
+// NewChaos instance. Chaos is for tests: placed between elements, it
+// mistreats the samples passing through as a lossy network or a faulty
+// publisher would, dropping, reordering, corrupting, truncating and
+// delaying them at random, so the robustness of the elements after it,
+// such as their VP8 parsing, can be exercised in CI. Payloads are copied
+// before they are changed. Held samples are written on close.
+func NewChaos(c ChaosConfig) *Chaos {
+	if c.Depth <= 0 {
+		c.Depth = 1
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = 100 * time.Millisecond
+	}
+	ch := &Chaos{cfg: c, rand: rand.New(rand.NewSource(c.Seed)), sleep: time.Sleep}
+	if len(c.Types) > 0 {
+		ch.types = make(map[int]bool, len(c.Types))
+		for _, t := range c.Types {
+			ch.types[t] = true
+		}
+	}
+	return ch
+}
+
+// Stats returns the counts of samples mistreated so far
+func (c *Chaos) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func (c *Chaos) Write(sample *avp.Sample) error {
+	out, delay := c.mistreat(sample)
+	if delay > 0 {
+		c.sleep(delay)
+	}
+	for _, s := range out {
+		if err := c.Node.Write(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mistreat returns the samples to write in place of sample, and how long
+// to wait first
+func (c *Chaos) mistreat(sample *avp.Sample) ([]*avp.Sample, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var out []*avp.Sample
+	if c.types == nil || c.types[sample.Type] {
+		sample = c.change(sample)
+	}
+	// Samples held back go out once enough have overtaken them
+	held := c.held[:0]
+	for _, h := range c.held {
+		if sample != nil {
+			h.behind--
+		}
+		if h.behind < 0 {
+			out = append(out, h.sample)
+		} else {
+			held = append(held, h)
+		}
+	}
+	c.held = held
+	if sample == nil {
+		return out, 0
+	}
+	if c.types == nil || c.types[sample.Type] {
+		if c.chance(c.cfg.Reorder) {
+			c.stats.Reordered++
+			c.held = append(c.held, heldSample{sample: sample, behind: c.cfg.Depth - 1})
+			return out, c.delay()
+		}
+	}
+	// Written ahead of the samples it overtakes
+	return append([]*avp.Sample{sample}, out...), c.delay()
+}
+
+// change returns sample dropped, as nil, corrupted or truncated
+func (c *Chaos) change(sample *avp.Sample) *avp.Sample {
+	if c.chance(c.cfg.Drop) {
+		c.stats.Dropped++
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return sample
+	}
+	corrupt, truncate := c.chance(c.cfg.Corrupt), c.chance(c.cfg.Truncate)
+	if !corrupt && !truncate {
+		return sample
+	}
+	payload = append([]byte(nil), payload...)
+	if corrupt {
+		c.stats.Corrupted++
+		for n := 1 + c.rand.Intn(1+len(payload)/16); n > 0; n-- {
+			payload[c.rand.Intn(len(payload))] ^= byte(1 + c.rand.Intn(255))
+		}
+	}
+	if truncate {
+		c.stats.Truncated++
+		payload = payload[:c.rand.Intn(len(payload))]
+	}
+	out := *sample
+	out.Payload = payload
+	return &out
+}
+
+// delay returns how long to wait before writing, if at all
+func (c *Chaos) delay() time.Duration {
+	if !c.chance(c.cfg.Delay) {
+		return 0
+	}
+	c.stats.Delayed++
+	return time.Duration(c.rand.Int63n(int64(c.cfg.MaxDelay)) + 1)
+}
+
+func (c *Chaos) chance(p float64) bool {
+	return p > 0 && c.rand.Float64() < p
+}
+
+// Close writes the samples held back and closes the children
+func (c *Chaos) Close() {
+	c.mu.Lock()
+	held := c.held
+	c.held = nil
+	c.mu.Unlock()
+	for _, h := range held {
+		if err := c.Node.Write(h.sample); err != nil {
+			break
+		}
+	}
+	c.Node.Close()
+}
@@ -0,0 +1,75 @@
+package elements
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaos(t *testing.T) {
+	payloads := func(samples []*avp.Sample) []byte {
+		var b []byte
+		for _, s := range samples {
+			b = append(b, s.Payload.([]byte)[0])
+		}
+		return b
+	}
+
+	// Video held back behind the next sample
+	c := NewChaos(ChaosConfig{Types: []int{avp.TypeVP8}, Reorder: 1})
+	rec := &sampleRecorder{}
+	c.Attach(rec)
+	assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeVP8, Payload: []byte{1}}))
+	assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{2}}))
+	assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{3}}))
+	assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeVP8, Payload: []byte{4}}))
+	assert.Equal(t, []byte{2, 1, 3}, payloads(rec.samples))
+	c.Close()
+	assert.Equal(t, []byte{2, 1, 3, 4}, payloads(rec.samples))
+	assert.Equal(t, ChaosStats{Reordered: 2}, c.Stats())
+
+	// Corrupted and cut short copies
+	c = NewChaos(ChaosConfig{Corrupt: 1, Truncate: 1, Delay: 1, MaxDelay: time.Second})
+	var slept time.Duration
+	c.sleep = func(d time.Duration) { slept += d }
+	rec = &sampleRecorder{}
+	c.Attach(rec)
+	original := bytes.Repeat([]byte{0xaa}, 100)
+	assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeVP8, Payload: original}))
+	assert.Equal(t, bytes.Repeat([]byte{0xaa}, 100), original)
+	if assert.Len(t, rec.samples, 1) {
+		assert.Less(t, len(rec.samples[0].Payload.([]byte)), 100)
+	}
+	assert.True(t, slept > 0 && slept <= time.Second)
+	assert.Equal(t, ChaosStats{Corrupted: 1, Truncated: 1, Delayed: 1}, c.Stats())
+
+	c = NewChaos(ChaosConfig{Drop: 1})
+	rec = &sampleRecorder{}
+	c.Attach(rec)
+	assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	assert.Empty(t, rec.samples)
+	assert.Equal(t, 1, c.Stats().Dropped)
+}
+
+// TestChaosWebmSaver feeds a WebmSaver and its sidecar damaged VP8 and
+// Opus, which must be recorded as far as possible without panicking
+func TestChaosWebmSaver(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		c := NewChaos(ChaosConfig{Seed: seed, Drop: 0.1, Reorder: 0.1, Depth: 3, Corrupt: 0.3, Truncate: 0.3})
+		saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Video: true, Sidecar: NewBufWriter()})
+		saver.Attach(NewBufWriter())
+		c.Attach(saver)
+		for i := 0; i < 100; i++ {
+			video := []byte{0x01, 0x00}
+			if i%10 == 0 {
+				video = rawKeyframePkt
+			}
+			assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeVP8, Timestamp: uint32(i * 3000), Payload: video}))
+			assert.NoError(t, c.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: rawOpusPkt}))
+		}
+		c.Close()
+	}
+}
@@ -0,0 +1,193 @@
+package elements
+
+import (
+	"fmt"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+)
+
+// Default RTP clock rates, for samples that don't say
+const (
+	audioClockRate = 48000
+	videoClockRate = 90000
+)
+
+// trackClock times the samples of a track from its first, extending RTP
+// timestamps past wraparound
+type trackClock struct {
+	started bool
+	last    uint32
+	ticks   int64
+}
+
+// since returns the time from the first sample of the track to sample.
+// Timestamps are at the sample's clock rate, or rate if it doesn't say.
+func (c *trackClock) since(sample *avp.Sample, rate uint32) time.Duration {
+	if sample.ClockRate > 0 {
+		rate = sample.ClockRate
+	}
+	if !c.started {
+		c.started, c.last = true, sample.Timestamp
+	}
+	// Timestamps may go back a little when samples are reordered
+	c.ticks += int64(int32(sample.Timestamp - c.last))
+	c.last = sample.Timestamp
+	return time.Duration(c.ticks * int64(time.Second) / int64(rate))
+}
+
+// liveTrack times the samples of a track sent live from the first sent on
+// the connection, so streams start at zero after reconnecting
+type liveTrack struct {
+	clock   trackClock
+	started bool
+	base    time.Duration
+}
+
+// at returns the time on the connection of a sample d into the track
+func (t *liveTrack) at(d time.Duration) time.Duration {
+	if !t.started {
+		t.started, t.base = true, d
+	}
+	if d < t.base {
+		return 0
+	}
+	return d - t.base
+}
+
+// roundMs rounds to the nearest millisecond, so frames of rates that
+// don't divide a second into whole milliseconds, like 120fps, are evenly
+// spaced instead of juddering
+func roundMs(d time.Duration) int64 {
+	if d < 0 {
+		return -roundMs(-d)
+	}
+	return int64((d + time.Millisecond/2) / time.Millisecond)
+}
+
+// pauseClock takes pauses out of the timeline of a track, so it carries on
+// after one a block and an optional gap on from where it stopped
+type pauseClock struct {
+	paused  bool  // since the last block
+	written bool  // a block
+	last    int64 // ms of the last block
+	step    int64 // ms between the last two blocks
+	shift   int64 // ms of pauses taken out
+}
+
+// at returns the time in the file of a block at t ms, gap ms after the
+// last following a pause
+func (c *pauseClock) at(t, gap int64) int64 {
+	t -= c.shift
+	if c.written {
+		if c.paused {
+			resumed := c.last + c.step + gap
+			c.shift += t - resumed
+			t = resumed
+		} else if t > c.last {
+			c.step = t - c.last
+		}
+	}
+	c.paused, c.written, c.last = false, true, t
+	return t
+}
+
+// pause marks the track paused if it has started
+func (c *pauseClock) pause() {
+	c.paused = c.written
+}
+
+// GapPolicy is how a saver treats jumps in the timestamps of a track, as
+// lost packets or a publisher pausing its stream leave, which confuse
+// players
+type GapPolicy int
+
+const (
+	// GapKeep leaves the jumps in the file
+	GapKeep GapPolicy = iota
+	// GapFill fills audio gaps with silence. Video isn't filled, players
+	// show its last frame until the next.
+	GapFill
+	// GapClamp shortens gaps to the longest allowed
+	GapClamp
+	// GapRebase takes gaps out, the track carrying on a frame after it
+	// stopped. A gap in one track only puts it out of step with the
+	// others.
+	GapRebase
+)
+
+var gapPolicies = []string{"keep", "fill", "clamp", "rebase"}
+
+func (p GapPolicy) String() string {
+	if int(p) < len(gapPolicies) {
+		return gapPolicies[p]
+	}
+	return fmt.Sprintf("GapPolicy(%d)", int(p))
+}
+
+// ParseGapPolicy returns the policy named s, GapKeep if empty
+func ParseGapPolicy(s string) (GapPolicy, error) {
+	if s == "" {
+		return GapKeep, nil
+	}
+	for p, name := range gapPolicies {
+		if name == s {
+			return GapPolicy(p), nil
+		}
+	}
+	return GapKeep, fmt.Errorf("unknown gap policy %q", s)
+}
+
+// defaultMaxGap is the longest jump between blocks of a track that isn't a
+// gap, by default
+const defaultMaxGap = time.Second
+
+// gapClock applies a GapPolicy to the timeline of a track, in ms. Jumps
+// back, of a publisher restarting its timestamps, are rebased unless
+// gaps are kept.
+type gapClock struct {
+	policy  GapPolicy
+	max     int64 // ms of the longest jump that isn't a gap
+	written bool  // a block
+	skip    bool  // the next jump, of a pause
+	last    int64 // ms of the last block
+	step    int64 // ms between the last two blocks
+	shift   int64 // ms taken out
+}
+
+func newGapClock(policy GapPolicy, max time.Duration) gapClock {
+	if max <= 0 {
+		max = defaultMaxGap
+	}
+	return gapClock{policy: policy, max: max.Milliseconds()}
+}
+
+// at returns the time in the file of a block at t ms, and where a gap
+// before it to fill starts, t if there is none
+func (c *gapClock) at(t int64) (int64, int64) {
+	t -= c.shift
+	gap := t
+	if c.written && !c.skip && c.policy != GapKeep {
+		next := c.last + c.step
+		switch jump := t - next; {
+		case jump < -c.max || (jump > c.max && c.policy == GapRebase):
+			c.shift += jump
+			t, gap = next, next
+		case jump > c.max && c.policy == GapClamp:
+			c.shift += jump - c.max
+			t, gap = next+c.max, next+c.max
+		case jump > c.max && c.policy == GapFill:
+			gap = next
+		}
+	}
+	if c.written && t > c.last && t-c.last <= c.max {
+		c.step = t - c.last
+	}
+	c.written, c.skip, c.last = true, false, t
+	return t, gap
+}
+
+// pause lets the next jump through, for the pauseClock to take out
+func (c *gapClock) pause() {
+	c.skip = c.written
+}
@@ -0,0 +1,72 @@
+package elements
+
+import (
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackClock(t *testing.T) {
+	// 120fps at 90kHz across the wraparound
+	var c trackClock
+	var ms []int64
+	for i := 0; i < 6; i++ {
+		ts := uint32(1<<32-1500) + uint32(i*750)
+		ms = append(ms, roundMs(c.since(&avp.Sample{Timestamp: ts}, videoClockRate)))
+	}
+	assert.Equal(t, []int64{0, 8, 17, 25, 33, 42}, ms)
+
+	// The sample's clock rate wins, and reordered samples go back
+	c = trackClock{}
+	assert.Equal(t, time.Duration(0), c.since(&avp.Sample{Timestamp: 100, ClockRate: 1000}, videoClockRate))
+	assert.Equal(t, 50*time.Millisecond, c.since(&avp.Sample{Timestamp: 150, ClockRate: 1000}, videoClockRate))
+	assert.Equal(t, 40*time.Millisecond, c.since(&avp.Sample{Timestamp: 140, ClockRate: 1000}, videoClockRate))
+
+	assert.Equal(t, int64(-8), roundMs(-8333*time.Microsecond))
+}
+
+func TestGapClock(t *testing.T) {
+	// 20ms blocks with a 3s jump, then timestamps restarting
+	blocks := []int64{0, 20, 40, 3040, 3060, 100, 120}
+	run := func(policy GapPolicy) (times, gaps []int64) {
+		c := newGapClock(policy, 0)
+		for _, b := range blocks {
+			at, gap := c.at(b)
+			times = append(times, at)
+			gaps = append(gaps, at-gap)
+		}
+		return times, gaps
+	}
+
+	times, gaps := run(GapKeep)
+	assert.Equal(t, blocks, times)
+	assert.Equal(t, []int64{0, 0, 0, 0, 0, 0, 0}, gaps)
+
+	times, gaps = run(GapFill)
+	assert.Equal(t, []int64{0, 20, 40, 3040, 3060, 3080, 3100}, times)
+	// From the end of the last block
+	assert.Equal(t, []int64{0, 0, 0, 2980, 0, 0, 0}, gaps)
+
+	times, _ = run(GapClamp)
+	assert.Equal(t, []int64{0, 20, 40, 1060, 1080, 1100, 1120}, times)
+
+	times, _ = run(GapRebase)
+	assert.Equal(t, []int64{0, 20, 40, 60, 80, 100, 120}, times)
+
+	// Jumps of pauses are left to the pauseClock
+	c := newGapClock(GapRebase, 0)
+	c.at(0)
+	c.at(20)
+	c.pause()
+	at, _ := c.at(5000)
+	assert.Equal(t, int64(5000), at)
+
+	p, err := ParseGapPolicy("clamp")
+	assert.NoError(t, err)
+	assert.Equal(t, GapClamp, p)
+	assert.Equal(t, "clamp", p.String())
+	_, err = ParseGapPolicy("stretch")
+	assert.Error(t, err)
+}
@@ -0,0 +1,466 @@
+package elements
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/layout"
+	"github.com/pion/ion-avp/pkg/pixel"
+	log "github.com/pion/ion-log"
+)
+
+// ErrNoVideoCodec is returned by NewVideoCompositor when no encoder is
+// given and libvpx isn't built in
+var ErrNoVideoCodec = errors.New("no video encoder, build with libvpx or pass one")
+
+// newVideoDecoder creates the default decoder of sources' VP8, and
+// newVideoEncoder the default VP8 encoder of composites, set when libvpx
+// is built in
+var (
+	newVideoDecoder func() avp.Element
+	newVideoEncoder func(fps, bitrate int) avp.Element
+)
+
+// defaultHighlight is the border of the active speaker's tile
+var defaultHighlight = layout.Border{Width: 4, Colour: layout.Colour{R: 0xff, G: 0xc8, B: 0x00, A: 0xff}}
+
+// VideoCompositorConfig configures VideoCompositor.
+// Width, Height: Of the composite, 1280 by 720 by default.
+// FrameRate: Of the composite in frames per second, 15 by default.
+// Bitrate: Of the default encoder in bits per second, 1.5Mbps by default.
+// Columns, Rows: Of a fixed grid sources are shown in, in place of Layout.
+// With more sources than tiles, the active speaker takes the last tile.
+// Layout: Optional layout switched at runtime, e.g. the session's. By
+// default a grid growing with the sources.
+// Canvas: Optional background and safe margins, e.g. the session's.
+// Roster: Optional names and avatars of sources by track id, avatars shown
+// while their video is off.
+// Labels: Draw participants' names on every tile, not just those of
+// layouts asking for labels.
+// Speaker: Optional lookup of the tracks of the active speaker, e.g. the
+// session's Dominant, whose tile is highlighted. Speaker changes written
+// to the compositor, e.g. by an AudioLevelDetector, take its place.
+// Highlight: Border drawn inside the active speaker's tile, 4 pixels of
+// amber by default.
+// Fit: How frames are fitted to tiles of another aspect ratio.
+// Stale: How long a source's last frame is shown once its video stops,
+// before it is shown as off, 2s by default.
+// Decoder: Optional creator of the decoder of each source's video, whose
+// YCbCr frames are composed. By default libvpx's, when built with the
+// libvpx tag. Sources can also be sent decoded frames.
+// Encoder: Optional encoder of the composite, written YCbCr frames. By
+// default libvpx's VP8 encoder, when built with the libvpx tag.
+// Outputs: Optional outputs each encoding every composed frame at the
+// FrameRate and Bitrate, e.g. a clean archive copy and a review copy with
+// timecode burned in, through a Tee in place of Encoder. Children are
+// attached to an output, see Output().
+type VideoCompositorConfig struct {
+	Width     int
+	Height    int
+	FrameRate int
+	Bitrate   int
+	Columns   int
+	Rows      int
+	Layout    layout.Placer
+	Canvas    *layout.Canvas
+	Roster    *layout.Roster
+	Labels    bool
+	Speaker   func() []string
+	Highlight layout.Border
+	Fit       pixel.Fit
+	Stale     time.Duration
+	Decoder   func() avp.Element
+	Encoder   avp.Element
+	Outputs   []TeeOutput
+}
+
+// VideoCompositor instance
+type VideoCompositor struct {
+	mu      sync.Mutex
+	cfg     VideoCompositorConfig
+	encoder avp.Element
+	sources []*compositorSource // in the order they joined
+	start   time.Time           // of the first frame composed
+	started sync.Once
+	stop    chan struct{}
+	done    chan struct{}
+	closed  bool
+	speaker []string         // tracks of the last speaker change written
+	now     func() time.Time // when frames arrive
+}
+
+// NewVideoCompositor instance. VideoCompositor decodes the video of a
+// session's participants, lays it out in a grid or the session's layout
+// with the active speaker highlighted, and encodes the composite as a
+// single track for its children, e.g. a WebmSaver. Run it as a room of the
+// session so each participant's video joins as it arrives.
+func NewVideoCompositor(c VideoCompositorConfig) (*VideoCompositor, error) {
+	if c.Width <= 0 || c.Height <= 0 {
+		c.Width, c.Height = 1280, 720
+	}
+	// Even sizes, which encoders need for 4:2:0
+	c.Width, c.Height = c.Width&^1, c.Height&^1
+	if c.FrameRate <= 0 {
+		c.FrameRate = 15
+	}
+	if c.Bitrate <= 0 {
+		c.Bitrate = 1500000
+	}
+	if c.Canvas == nil {
+		c.Canvas = layout.NewCanvas()
+	}
+	if c.Highlight.Width == 0 {
+		c.Highlight = defaultHighlight
+	}
+	if c.Stale <= 0 {
+		c.Stale = 2 * time.Second
+	}
+	if c.Decoder == nil {
+		c.Decoder = newVideoDecoder
+	}
+	if c.Encoder == nil && len(c.Outputs) > 0 {
+		tee, err := NewTee(EncodeProfile{FrameRate: c.FrameRate, Bitrate: c.Bitrate}, c.Outputs...)
+		if err != nil {
+			return nil, err
+		}
+		c.Encoder = tee
+	}
+	if c.Encoder == nil {
+		if newVideoEncoder == nil {
+			return nil, ErrNoVideoCodec
+		}
+		// None in low memory mode
+		if c.Encoder = newVideoEncoder(c.FrameRate, c.Bitrate); c.Encoder == nil {
+			return nil, ErrNoVideoCodec
+		}
+	}
+	return &VideoCompositor{
+		cfg:     c,
+		encoder: c.Encoder,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		now:     time.Now,
+	}, nil
+}
+
+// Composite tells the session to request the active speaker's video at a
+// higher simulcast layer than the thumbnails'
+func (c *VideoCompositor) Composite() bool {
+	return true
+}
+
+// Join returns the element taking the video of a source, encoded or
+// decoded. Sources are shown from their first video sample, so audio
+// tracks joining aren't. Closing it removes the source from the
+// composite, as when the participant leaves.
+func (c *VideoCompositor) Join(tid string) avp.Element {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := &compositorSource{c: c, id: tid}
+	if !c.closed {
+		c.sources = append(c.sources, s)
+	}
+	return s
+}
+
+// Write samples of sources by their ID, for composites fed through a
+// single element rather than Join, and speaker changes
+func (c *VideoCompositor) Write(sample *avp.Sample) error {
+	if change, ok := sample.Payload.(*SpeakerChange); ok && sample.Type == TypeSpeaker {
+		c.mu.Lock()
+		c.speaker = change.Tracks
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Lock()
+	var s *compositorSource
+	for _, cs := range c.sources {
+		if cs.id == sample.ID {
+			s = cs
+		}
+	}
+	c.mu.Unlock()
+	if s == nil {
+		s = c.Join(sample.ID).(*compositorSource)
+	}
+	return s.Write(sample)
+}
+
+// Output returns the encoder of the named output of Outputs, to attach
+// its children to, nil if there's no such output
+func (c *VideoCompositor) Output(name string) avp.Element {
+	if tee, ok := c.encoder.(*Tee); ok {
+		return tee.Output(name)
+	}
+	return nil
+}
+
+// Attach attaches a child element, written the encoded composite
+func (c *VideoCompositor) Attach(e avp.Element) {
+	c.encoder.Attach(e)
+}
+
+// Close stops composing and closes the encoder
+func (c *VideoCompositor) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	sources := c.sources
+	c.sources = nil
+	c.mu.Unlock()
+
+	close(c.stop)
+	c.started.Do(func() { close(c.done) })
+	<-c.done
+	for _, s := range sources {
+		s.closeDecoder()
+	}
+	c.encoder.Close()
+}
+
+// run composes a frame every frame interval from the first frame a source
+// is sent
+func (c *VideoCompositor) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(time.Second / time.Duration(c.cfg.FrameRate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			c.tick(now)
+		}
+	}
+}
+
+// tick composes the frame of now and encodes it
+func (c *VideoCompositor) tick(now time.Time) {
+	frame, elapsed := c.compose(now)
+	err := c.encoder.Write(&avp.Sample{
+		Type:        TypeYCbCr,
+		Timestamp:   uint32(elapsed * videoClockRate / time.Second),
+		ClockRate:   videoClockRate,
+		CaptureTime: now,
+		Payload:     frame,
+	})
+	if err != nil {
+		log.Errorf("video compositor: encoding: %s", err)
+	}
+}
+
+// compose draws the sources on the canvas as of now, returning the frame
+// and its time from the first
+func (c *VideoCompositor) compose(now time.Time) (*image.YCbCr, time.Duration) {
+	// Looked up before locking, as the session joins sources holding its
+	// own lock
+	var tracks []string
+	if c.cfg.Speaker != nil {
+		tracks = c.cfg.Speaker()
+	}
+	placer := c.placer()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.speaker != nil {
+		tracks = c.speaker
+	}
+	speaking := make(map[string]bool, len(tracks))
+	for _, tid := range tracks {
+		speaking[tid] = true
+	}
+	if c.start.IsZero() {
+		c.start = now
+	}
+	elapsed := now.Sub(c.start)
+	dst := image.NewYCbCr(image.Rect(0, 0, c.cfg.Width, c.cfg.Height), image.YCbCrSubsampleRatio420)
+	c.cfg.Canvas.Draw(dst, elapsed)
+
+	sources := make([]layout.Source, 0, len(c.sources))
+	byID := make(map[string]*compositorSource, len(c.sources))
+	speaker := -1
+	for _, s := range c.sources {
+		if !s.video {
+			continue
+		}
+		if speaker < 0 && speaking[s.id] {
+			speaker = len(sources)
+		}
+		sources = append(sources, layout.Source{ID: s.id, Muted: s.frame == nil || now.Sub(s.at) > c.cfg.Stale})
+		byID[s.id] = s
+	}
+	// A speaker that wouldn't be shown takes the last tile
+	if l, ok := placer.(layout.Layout); ok {
+		if n := l.Capacity(); n > 0 && speaker >= n {
+			sources[n-1], sources[speaker] = sources[speaker], sources[n-1]
+		}
+	}
+
+	for _, t := range c.cfg.Canvas.Place(placer, c.cfg.Width, c.cfg.Height, sources) {
+		s := byID[t.ID]
+		if s == nil {
+			continue
+		}
+		tile := dst.SubImage(t.Rect).(*image.YCbCr)
+		var p layout.Participant
+		if c.cfg.Roster != nil {
+			p, _ = c.cfg.Roster.Get(t.ID)
+		}
+		muted := s.frame == nil || now.Sub(s.at) > c.cfg.Stale
+		switch {
+		case !muted:
+			if err := pixel.ScaleFit(tile, s.frame, c.cfg.Fit); err != nil {
+				log.Debugf("video compositor: drawing %s: %s", t.ID, err)
+			}
+		case p.Avatar != nil:
+			_ = pixel.ScaleFit(tile, p.Avatar, pixel.FitPad)
+		}
+		if t.Label || c.cfg.Labels || muted {
+			pixel.Label(tile, p.Name)
+		}
+		border := t.Border
+		if speaking[t.ID] {
+			border = c.cfg.Highlight
+		}
+		drawBorder(tile, border)
+	}
+	return dst, elapsed
+}
+
+// placer returns the layout sources are placed with
+func (c *VideoCompositor) placer() layout.Placer {
+	if c.cfg.Columns > 0 && c.cfg.Rows > 0 {
+		return layout.Layout{Columns: c.cfg.Columns, Rows: c.cfg.Rows}
+	}
+	switch l := c.cfg.Layout.(type) {
+	case nil:
+		return layout.Layout{}
+	case *layout.Current:
+		return l.Get()
+	default:
+		return l
+	}
+}
+
+// drawBorder draws b inside the edges of m, opaque
+func drawBorder(m *image.YCbCr, b layout.Border) {
+	w := b.Width
+	if w <= 0 || m.Rect.Empty() {
+		return
+	}
+	y, cb, cr := color.RGBToYCbCr(b.Colour.R, b.Colour.G, b.Colour.B)
+	c := pixel.Limited(color.YCbCr{Y: y, Cb: cb, Cr: cr})
+	r := m.Rect
+	for _, edge := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+w),
+		image.Rect(r.Min.X, r.Max.Y-w, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+w, r.Max.Y),
+		image.Rect(r.Max.X-w, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		pixel.Fill(m.SubImage(edge).(*image.YCbCr), c)
+	}
+}
+
+// compositorSource is a source of a VideoCompositor
+type compositorSource struct {
+	c       *VideoCompositor
+	id      string
+	decoder avp.Element
+	video   bool         // whether it has been sent video
+	frame   *image.YCbCr // copy of the last frame
+	at      time.Time    // when it arrived
+}
+
+func (s *compositorSource) Write(sample *avp.Sample) error {
+	if img, ok := sample.Payload.(*image.YCbCr); ok && sample.Type == TypeYCbCr {
+		s.c.setFrame(s, img)
+		return nil
+	}
+	switch sample.Type {
+	case avp.TypeVP8, avp.TypeVP9, avp.TypeAV1, avp.TypeH264:
+	default:
+		return nil
+	}
+	s.c.mu.Lock()
+	s.video = true
+	d := s.decoder
+	if d == nil && !s.c.closed && s.c.cfg.Decoder != nil {
+		if d = s.c.cfg.Decoder(); d != nil {
+			d.Attach(&compositorFrames{s})
+			s.decoder = d
+		}
+	}
+	s.c.mu.Unlock()
+	if d == nil {
+		return nil
+	}
+	return d.Write(sample)
+}
+
+func (s *compositorSource) Attach(e avp.Element) {}
+
+// Close removes the source from the composite
+func (s *compositorSource) Close() {
+	c := s.c
+	c.mu.Lock()
+	for i, cs := range c.sources {
+		if cs == s {
+			c.sources = append(c.sources[:i:i], c.sources[i+1:]...)
+			break
+		}
+	}
+	c.mu.Unlock()
+	s.closeDecoder()
+}
+
+func (s *compositorSource) closeDecoder() {
+	s.c.mu.Lock()
+	d := s.decoder
+	s.decoder = nil
+	s.c.mu.Unlock()
+	if d != nil {
+		d.Close()
+	}
+}
+
+// setFrame keeps a copy of a source's frame, as decoders reuse theirs,
+// and starts composing with the first
+func (c *VideoCompositor) setFrame(s *compositorSource, img *image.YCbCr) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if s.frame == nil || s.frame.Rect.Size() != img.Rect.Size() || s.frame.SubsampleRatio != img.SubsampleRatio {
+		s.frame = image.NewYCbCr(image.Rectangle{Max: img.Rect.Size()}, img.SubsampleRatio)
+	}
+	if err := pixel.Copy(s.frame, img); err != nil {
+		s.frame = nil
+		return
+	}
+	s.video, s.at = true, c.now()
+	c.started.Do(func() { go c.run() })
+}
+
+// compositorFrames takes the decoded frames of a source
+type compositorFrames struct {
+	s *compositorSource
+}
+
+func (f *compositorFrames) Write(sample *avp.Sample) error {
+	if img, ok := sample.Payload.(*image.YCbCr); ok {
+		f.s.c.setFrame(f.s, img)
+	}
+	return nil
+}
+
+func (f *compositorFrames) Attach(e avp.Element) {}
+
+func (f *compositorFrames) Close() {}
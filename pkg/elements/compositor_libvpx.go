@@ -0,0 +1,21 @@
+//go:build libvpx
+// +build libvpx
+
+package elements
+
+import avp "github.com/pion/ion-avp/pkg"
+
+func init() {
+	newVideoDecoder = func() avp.Element {
+		if d := NewDecoder(0, TypeYCbCr); d != nil {
+			return d
+		}
+		return nil
+	}
+	newVideoEncoder = func(fps, bitrate int) avp.Element {
+		if e := NewEncoder(EncoderConfig{FrameRate: fps, Bitrate: bitrate}); e != nil {
+			return e
+		}
+		return nil
+	}
+}
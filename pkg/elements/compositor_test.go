@@ -0,0 +1,97 @@
+package elements
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/layout"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+// lumaDecoder decodes frames to a uniform picture of the luma of their
+// first byte
+type lumaDecoder struct {
+	Node
+}
+
+func (d *lumaDecoder) Write(sample *avp.Sample) error {
+	return d.Node.Write(&avp.Sample{Type: TypeYCbCr, Payload: lumaFrame(sample.Payload.([]byte)[0])})
+}
+
+func lumaFrame(y uint8) *image.YCbCr {
+	m := image.NewYCbCr(image.Rect(0, 0, 64, 36), image.YCbCrSubsampleRatio420)
+	pixel.Fill(m, color.YCbCr{Y: y, Cb: 128, Cr: 128})
+	return m
+}
+
+func TestVideoCompositor(t *testing.T) {
+	if newVideoEncoder == nil {
+		_, err := NewVideoCompositor(VideoCompositorConfig{})
+		assert.Equal(t, ErrNoVideoCodec, err)
+	}
+
+	out := &sampleRecorder{}
+	roster := layout.NewRoster()
+	roster.Set("carol", layout.Participant{Name: "Carol"})
+	speaker := []string{"carol"}
+	c, err := NewVideoCompositor(VideoCompositorConfig{
+		Width:     200,
+		Height:    100,
+		FrameRate: 1,
+		Columns:   2,
+		Rows:      1,
+		Roster:    roster,
+		Speaker:   func() []string { return speaker },
+		Fit:       pixel.FitStretch,
+		Decoder:   func() avp.Element { return &lumaDecoder{} },
+		Encoder:   out,
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	// Alice's video is decoded, Bob's sent decoded and Alice's audio not
+	// shown
+	alice := c.Join("alice")
+	assert.NoError(t, alice.Write(&avp.Sample{Type: avp.TypeVP8, Payload: []byte{200}}))
+	assert.NoError(t, c.Join("alice-audio").Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	assert.NoError(t, c.Write(&avp.Sample{ID: "bob", Type: TypeYCbCr, Payload: lumaFrame(100)}))
+	// Carol is speaking but wouldn't fit, so takes Bob's tile
+	carol := c.Join("carol")
+	assert.NoError(t, carol.Write(&avp.Sample{Type: avp.TypeVP8, Payload: []byte{50}}))
+
+	frame, elapsed := c.compose(now)
+	assert.Equal(t, time.Duration(0), elapsed)
+	assert.Equal(t, image.Rect(0, 0, 200, 100), frame.Rect)
+	luma := func(x, y int) uint8 { return frame.Y[frame.YOffset(x, y)] }
+	assert.Equal(t, uint8(200), luma(50, 50))
+	assert.Equal(t, uint8(50), luma(150, 20))
+	// Highlighted
+	y, _, _ := color.RGBToYCbCr(defaultHighlight.Colour.R, defaultHighlight.Colour.G, defaultHighlight.Colour.B)
+	assert.Equal(t, pixel.Limited(color.YCbCr{Y: y}).Y, luma(101, 50))
+	assert.Equal(t, uint8(200), luma(1, 50))
+
+	// Carol leaves, and Alice's video stops
+	carol.Close()
+	speaker = nil
+	now = now.Add(3 * time.Second)
+	assert.NoError(t, c.Write(&avp.Sample{ID: "bob", Type: TypeYCbCr, Payload: lumaFrame(100)}))
+	frame, _ = c.compose(now)
+	assert.Equal(t, pixel.Black.Y, frame.Y[frame.YOffset(50, 20)])
+	assert.Equal(t, uint8(100), frame.Y[frame.YOffset(150, 20)])
+
+	c.tick(now.Add(time.Second))
+	if assert.Len(t, out.samples, 1) {
+		assert.Equal(t, TypeYCbCr, out.samples[0].Type)
+		assert.Equal(t, uint32(4*videoClockRate), out.samples[0].Timestamp)
+		assert.Equal(t, uint32(videoClockRate), out.samples[0].ClockRate)
+	}
+
+	c.Close()
+	assert.NoError(t, alice.Write(&avp.Sample{Type: avp.TypeVP8, Payload: []byte{200}}))
+}
@@ -6,6 +6,7 @@ import (
 	"image"
 	"image/color"
 	"image/jpeg"
+	"image/png"
 
 	avp "github.com/pion/ion-avp/pkg"
 )
@@ -21,6 +22,7 @@ type Converter struct {
 //
 // Currently supports:
 //     - YCbCR -> JPEG
+//     - YCbCR -> PNG
 func NewConverter(typ int) *Converter {
 	return &Converter{
 		typ: typ,
@@ -39,6 +41,12 @@ func (c *Converter) Write(sample *avp.Sample) error {
 				return err
 			}
 			out = buf.Bytes()
+		case TypePNG:
+			buf := new(bytes.Buffer)
+			if err := png.Encode(buf, img); err != nil {
+				return err
+			}
+			out = buf.Bytes()
 		default:
 			return errors.New("unsupported dest type")
 		}
@@ -0,0 +1,408 @@
+package elements
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/dash"
+	"github.com/pion/ion-avp/pkg/hls"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// dashManifest is the name of the manifest of a DASH stream
+const dashManifest = "manifest.mpd"
+
+// DashSaverConfig configures DashSaver.
+// Audio: Record the audio track, Opus.
+// Video: Record the video track, H.264.
+// Recording: Optional state machine moved along as the recording progresses.
+// SegmentDuration: How much media each segment holds, defaults to 4s.
+// Video segments start at a keyframe, so may be longer.
+// Live: Write a dynamic manifest, updated as each segment is written, for
+// players to follow the stream. Otherwise a static manifest is written
+// once the stream ends, for VOD.
+// Window: How many of the latest segments of each track live manifests
+// list, older ones being removed. By default they list every segment.
+// Sink: Where the stream is written, e.g. a DirSink.
+// HLS: Also list the segments in HLS playlists, index.m3u8 with
+// video.m3u8 and audio.m3u8. Segments are CMAF, so packaged once for both
+// protocols. Playlists are updated as segments are written.
+// AudioTracks: Optional audio tracks for viewers to pick between, e.g. the
+// floor audio and interpreters' channels, in place of the one audio track.
+type DashSaverConfig struct {
+	Audio           bool
+	Video           bool
+	Recording       *recording.Recording
+	SegmentDuration time.Duration
+	Live            bool
+	Window          int
+	Sink            Sink
+	HLS             bool
+	AudioTracks     []DashAudioTrack
+}
+
+// DashAudioTrack is one of several audio tracks of a DASH stream, packaged
+// apart as audio-{Name}-init.mp4 and audio-{Name}-0.m4s...
+// TrackID: The ID of the track whose samples it records.
+// Name: Of the track, shown to viewers, e.g. "floor" or "fr".
+// Language: An RFC 5646 tag, e.g. "fr".
+// Default: Played unless the viewer picks another, by default the first.
+// Autoselect: May be picked by players for the viewer's language.
+type DashAudioTrack struct {
+	TrackID    string
+	Name       string
+	Language   string
+	Default    bool
+	Autoselect bool
+}
+
+// dashTrack is a track of a DASH stream, packaged on its own
+type dashTrack struct {
+	name     string
+	kind     string          // audio or video
+	audio    *DashAudioTrack // of several
+	mp4      *Mp4Saver
+	rep      dash.Representation
+	playlist hls.Media
+	segments int           // listed in the timeline
+	next     int           // number of the next segment
+	end      uint64        // of the last segment, in the track's timescale
+	bits     int64         // of the segments, for the average bandwidth
+	duration time.Duration // of the segments
+}
+
+// DashSaver instance
+type DashSaver struct {
+	sync.Mutex
+	cfg    DashSaverConfig
+	tracks []*dashTrack
+	start  time.Time // of the stream, when the first track started
+	video  bool      // started
+	closed bool
+	failed bool
+}
+
+// NewDashSaver instance. DashSaver packages H.264 video and Opus audio as
+// fMP4 segments of each track with an MPEG-DASH manifest, manifest.mpd,
+// for standard CDN origins, and optionally HLS playlists of them. Tracks are packaged separately, as players
+// expect, as audio-init.mp4 and audio-0.m4s, audio-1.m4s... and similarly
+// for video.
+func NewDashSaver(c DashSaverConfig) *DashSaver {
+	if c.SegmentDuration <= 0 {
+		c.SegmentDuration = 4 * time.Second
+	}
+	s := &DashSaver{cfg: c}
+	// The recording is moved along by the track segments start with
+	if c.Video {
+		s.track("video", nil, Mp4SaverConfig{Video: true, Recording: c.Recording})
+	}
+	if c.Audio {
+		rec := c.Recording
+		if c.Video {
+			rec = nil
+		}
+		if len(c.AudioTracks) == 0 {
+			s.track("audio", nil, Mp4SaverConfig{Audio: true, Recording: rec})
+		}
+		def := false
+		for i := range c.AudioTracks {
+			def = def || c.AudioTracks[i].Default
+		}
+		for i := range c.AudioTracks {
+			a := c.AudioTracks[i]
+			a.Default = a.Default || (!def && i == 0)
+			s.track("audio-"+a.Name, &a, Mp4SaverConfig{Audio: true, Recording: rec})
+			rec = nil
+		}
+	}
+	return s
+}
+
+// track adds a track packaged by an Mp4Saver of c, one of several audio
+// tracks if audio is set
+func (s *DashSaver) track(name string, audio *DashAudioTrack, c Mp4SaverConfig) {
+	c.FragmentDuration = s.cfg.SegmentDuration
+	kind := "audio"
+	if c.Video {
+		kind = "video"
+	}
+	t := &dashTrack{name: name, kind: kind, audio: audio, mp4: NewMp4Saver(&c)}
+	t.mp4.segment = func(data []byte, start, d time.Duration) error {
+		return s.segment(t, data, start, d)
+	}
+	s.tracks = append(s.tracks, t)
+}
+
+func (s *DashSaver) Write(sample *avp.Sample) error {
+	if s.cfg.Video && sample.Type == avp.TypeOpus {
+		// Audio starts with video, and after resuming waits for it
+		s.Lock()
+		started := s.video
+		s.Unlock()
+		if !started || s.cfg.Recording.State() == recording.StateWaitingForKeyframe {
+			return nil
+		}
+	}
+	if s.cfg.Recording.State() == recording.StatePaused {
+		return nil
+	}
+	for _, t := range s.tracks {
+		if t.audio != nil && sample.ID != t.audio.TrackID {
+			continue
+		}
+		if err := t.mp4.Write(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Attach isn't supported, the stream is written to the sink
+func (s *DashSaver) Attach(e avp.Element) {
+	log.Warnf("DashSaver writes to its sink")
+}
+
+// Close writes the last segments and the final manifest
+func (s *DashSaver) Close() {
+	for _, t := range s.tracks {
+		t.mp4.Close()
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.failed || s.closed {
+		return
+	}
+	s.closed = true
+	if err := s.publish(); err != nil {
+		s.fail(err)
+		return
+	}
+	if s.cfg.HLS {
+		if err := s.publishHLS(); err != nil {
+			return
+		}
+	}
+	setState(s.cfg.Recording, recording.StateComplete)
+}
+
+// segment stores the initialization segment of a track, or a media segment
+// holding d from start and lists it in the manifest
+func (s *DashSaver) segment(t *dashTrack, data []byte, start, d time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.failed {
+		return nil
+	}
+
+	if d == 0 {
+		if s.start.IsZero() {
+			s.start = time.Now()
+		}
+		track := t.mp4.tracks[0]
+		t.rep = dash.Representation{
+			ID:     t.name,
+			Codecs: track.codec,
+			Width:  track.width,
+			Height: track.height,
+			SegmentTemplate: dash.SegmentTemplate{
+				Timescale:      track.timescale,
+				Initialization: t.name + "-init.mp4",
+				Media:          t.name + "-$Number$.m4s",
+			},
+		}
+		if track.video {
+			s.video = true
+		} else {
+			t.rep.AudioSamplingRate = int(track.timescale)
+		}
+		t.playlist = hls.Media{
+			Init:           t.rep.SegmentTemplate.Initialization,
+			Event:          !s.cfg.Live || s.cfg.Window <= 0,
+			TargetDuration: s.cfg.SegmentDuration,
+		}
+		return s.put(t.rep.SegmentTemplate.Initialization, data)
+	}
+
+	name := fmt.Sprintf("%s-%d.m4s", t.name, t.next)
+	if err := s.put(name, data); err != nil {
+		return err
+	}
+	t.next++
+	t.playlist.Segments = append(t.playlist.Segments, hls.Segment{URI: name, Duration: d})
+	tmpl := &t.rep.SegmentTemplate
+	at, ticks := timescaled(start, tmpl.Timescale), timescaled(d, tmpl.Timescale)
+	tmpl.Timeline = dash.Append(tmpl.Timeline, at, ticks)
+	t.end = at + ticks
+	t.segments++
+	t.bits += int64(len(data)) * 8
+	t.duration += d
+	if w := s.cfg.Window; s.cfg.Live && w > 0 && t.segments > w {
+		tmpl.Timeline = dash.Trim(tmpl.Timeline)
+		tmpl.StartNumber++
+		t.segments--
+		t.playlist.Segments = t.playlist.Segments[1:]
+		t.playlist.Sequence++
+		// Players may still fetch segments of the manifest they loaded
+		// last, so segments are kept a window after leaving it
+		if old := tmpl.StartNumber - w - 1; old >= 0 {
+			if err := s.cfg.Sink.Remove(fmt.Sprintf("%s-%d.m4s", t.name, old)); err != nil {
+				log.Warnf("DASH saver: removing %s segment %d: %s", t.name, old, err)
+			}
+		}
+	}
+	if s.cfg.Live {
+		if err := s.publish(); err != nil {
+			return err
+		}
+	}
+	if s.cfg.HLS {
+		return s.publishHLS()
+	}
+	return nil
+}
+
+// timescaled returns d in ticks of timescale
+func timescaled(d time.Duration, timescale uint32) uint64 {
+	return uint64((int64(d)*int64(timescale) + int64(time.Second)/2) / int64(time.Second))
+}
+
+// put stores a file of the stream
+func (s *DashSaver) put(name string, data []byte) error {
+	if err := s.cfg.Sink.Put(name, data); err != nil {
+		s.fail(err)
+		return err
+	}
+	return nil
+}
+
+// publish writes the manifest of the segments so far
+func (s *DashSaver) publish() error {
+	m := &dash.MPD{
+		Profiles:      dash.ProfileLive,
+		Type:          "static",
+		MinBufferTime: dash.Duration(s.cfg.SegmentDuration),
+		Period:        dash.Period{ID: "0"},
+	}
+	if s.cfg.Live && !s.closed {
+		now := time.Now()
+		m.Type = "dynamic"
+		m.AvailabilityStartTime = &s.start
+		m.PublishTime = &now
+		m.MinimumUpdatePeriod = dash.Duration(s.cfg.SegmentDuration)
+		if s.cfg.Window > 0 {
+			m.TimeShiftBufferDepth = dash.Duration(time.Duration(s.cfg.Window) * s.cfg.SegmentDuration)
+		}
+	}
+	for _, t := range s.tracks {
+		if len(t.rep.SegmentTemplate.Timeline) == 0 {
+			continue
+		}
+		rep := t.rep
+		rep.Bandwidth = int(float64(t.bits) / t.duration.Seconds())
+		end := dash.Duration(time.Duration(t.end) * time.Second / time.Duration(rep.SegmentTemplate.Timescale))
+		if m.Type == "static" && end > m.MediaPresentationDuration {
+			m.MediaPresentationDuration = end
+		}
+		set := dash.AdaptationSet{
+			ContentType:      t.kind,
+			MimeType:         t.kind + "/mp4",
+			SegmentAlignment: true,
+			StartWithSAP:     1,
+			Representations:  []dash.Representation{rep},
+		}
+		if a := t.audio; a != nil {
+			set.Lang = a.Language
+			set.Role = &dash.Descriptor{SchemeIDURI: dash.RoleScheme, Value: "alternate"}
+			if a.Default {
+				set.Role.Value = "main"
+			}
+		}
+		m.Period.AdaptationSets = append(m.Period.AdaptationSets, set)
+	}
+	var b bytes.Buffer
+	if _, err := m.WriteTo(&b); err != nil {
+		return err
+	}
+	if err := s.cfg.Sink.Put(dashManifest, b.Bytes()); err != nil {
+		s.fail(err)
+		return err
+	}
+	return nil
+}
+
+// publishHLS writes the HLS playlists of the segments so far. Audio
+// packaged apart from the video is an audio rendition of the variant, as
+// are the tracks of several audio languages.
+func (s *DashSaver) publishHLS() error {
+	m := &hls.Master{}
+	var variant *hls.Variant
+	// the most bandwidth of the audio renditions, and their codec
+	audio, codec := 0, ""
+	for _, t := range s.tracks {
+		if t.playlist.Init == "" {
+			continue
+		}
+		t.playlist.Ended = s.closed
+		var b bytes.Buffer
+		if _, err := t.playlist.WriteTo(&b); err != nil {
+			return err
+		}
+		uri := t.name + ".m3u8"
+		if err := s.put(uri, b.Bytes()); err != nil {
+			return err
+		}
+
+		bandwidth := 0
+		if t.duration > 0 {
+			bandwidth = int(float64(t.bits) / t.duration.Seconds())
+		}
+		if a := t.audio; a != nil {
+			m.Renditions = append(m.Renditions, hls.Rendition{Type: "AUDIO", GroupID: "audio", Name: a.Name, URI: uri, Language: a.Language, Default: a.Default, Autoselect: a.Autoselect})
+			if bandwidth > audio {
+				audio = bandwidth
+			}
+			codec = t.rep.Codecs
+			if variant == nil && a.Default {
+				// Audio only streams play the default track
+				variant = &hls.Variant{URI: uri}
+			}
+			continue
+		}
+		if variant == nil {
+			variant = &hls.Variant{URI: uri, Width: t.rep.Width, Height: t.rep.Height}
+		} else {
+			m.Renditions = append(m.Renditions, hls.Rendition{Type: "AUDIO", GroupID: "audio", Name: t.name, URI: uri, Default: true, Autoselect: true})
+			variant.Audio = "audio"
+		}
+		variant.Bandwidth += bandwidth
+		variant.Codecs = append(variant.Codecs, t.rep.Codecs)
+	}
+	if variant != nil && codec != "" {
+		// Bandwidth covers whichever audio rendition is played
+		variant.Audio = "audio"
+		variant.Bandwidth += audio
+		variant.Codecs = append(variant.Codecs, codec)
+	}
+	if variant == nil || variant.Bandwidth == 0 {
+		// Until the first segment
+		return nil
+	}
+	m.Variants = []hls.Variant{*variant}
+	var b bytes.Buffer
+	if _, err := m.WriteTo(&b); err != nil {
+		return err
+	}
+	return s.put(hlsPlaylist, b.Bytes())
+}
+
+// fail stops writing the stream
+func (s *DashSaver) fail(err error) {
+	log.Errorf("DASH saver: %s", err)
+	s.failed = true
+	s.cfg.Recording.Fail(err)
+}
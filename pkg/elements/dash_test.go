@@ -0,0 +1,180 @@
+package elements
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/dash"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashSaver(t *testing.T) {
+	for _, live := range []bool{true, false} {
+		rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "dash")
+		sink := &memSink{files: map[string][]byte{}}
+		saver := NewDashSaver(DashSaverConfig{
+			Audio:           true,
+			Video:           true,
+			Recording:       rec,
+			SegmentDuration: time.Second,
+			Live:            live,
+			Window:          2,
+			Sink:            sink,
+		})
+
+		// Audio before video is dropped, so the tracks start together
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: 100, Payload: opusSilence}))
+		// 4s of 30fps video with a keyframe a second, and 20ms audio packets
+		audio := 0
+		for i := 0; i < 120; i++ {
+			frame := h264Frame
+			if i%30 == 0 {
+				frame = h264Keyframe
+			}
+			assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Timestamp: uint32(i * 3000), Payload: frame}))
+			for ; audio*3 < (i+1)*5; audio++ {
+				assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(audio * 960), Payload: opusSilence}))
+			}
+			if i == 100 {
+				_, ok := sink.files[dashManifest]
+				assert.Equal(t, live, ok)
+			}
+		}
+		saver.Close()
+		assert.Equal(t, recording.StateComplete, rec.State())
+
+		var m struct {
+			Type     string `xml:"type,attr"`
+			Duration string `xml:"mediaPresentationDuration,attr"`
+			Sets     []struct {
+				Reps []dash.Representation `xml:"Representation"`
+			} `xml:"Period>AdaptationSet"`
+		}
+		assert.NoError(t, xml.Unmarshal(sink.files[dashManifest], &m))
+		// An ended live stream becomes static
+		assert.Equal(t, "static", m.Type)
+		assert.Equal(t, "PT4.000S", m.Duration)
+		assert.Len(t, m.Sets, 2)
+		video := m.Sets[0].Reps[0]
+		assert.Equal(t, "video", video.ID)
+		assert.Equal(t, "avc1.42001e", video.Codecs)
+		assert.Equal(t, 640, video.Width)
+		assert.Greater(t, video.Bandwidth, 0)
+		assert.Equal(t, "video-$Number$.m4s", video.SegmentTemplate.Media)
+		audioRep := m.Sets[1].Reps[0]
+		assert.Equal(t, "opus", audioRep.Codecs)
+		assert.Equal(t, 48000, audioRep.AudioSamplingRate)
+
+		if live {
+			// A window of the latest segments
+			assert.Equal(t, 2, video.SegmentTemplate.StartNumber)
+			assert.Equal(t, []dash.S{{T: 180000, D: 90000, R: 1}}, video.SegmentTemplate.Timeline)
+			// Segments are kept a window after leaving it
+			_, ok := sink.files["video-0.m4s"]
+			assert.True(t, ok)
+		} else {
+			assert.Equal(t, 0, video.SegmentTemplate.StartNumber)
+			assert.Equal(t, []dash.S{{T: 0, D: 90000, R: 3}}, video.SegmentTemplate.Timeline)
+		}
+		for _, name := range []string{"video-init.mp4", "audio-init.mp4", "video-3.m4s", "audio-3.m4s"} {
+			_, ok := sink.files[name]
+			assert.True(t, ok, fmt.Sprint(name))
+		}
+	}
+}
+
+func TestDashSaver_HLS(t *testing.T) {
+	sink := &memSink{files: map[string][]byte{}}
+	saver := NewDashSaver(DashSaverConfig{
+		Audio:           true,
+		Video:           true,
+		SegmentDuration: time.Second,
+		Live:            true,
+		Window:          2,
+		Sink:            sink,
+		HLS:             true,
+	})
+	audio := 0
+	for i := 0; i < 120; i++ {
+		frame := h264Frame
+		if i%30 == 0 {
+			frame = h264Keyframe
+		}
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Timestamp: uint32(i * 3000), Payload: frame}))
+		for ; audio*3 < (i+1)*5; audio++ {
+			assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(audio * 960), Payload: opusSilence}))
+		}
+	}
+	saver.Close()
+
+	// Both protocols list the same segments
+	master := string(sink.files["index.m3u8"])
+	assert.Contains(t, master, `#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="audio",DEFAULT=YES,AUTOSELECT=YES,URI="audio.m3u8"`)
+	assert.Contains(t, master, `RESOLUTION=640x480,CODECS="avc1.42001e,opus",AUDIO="audio"`+"\nvideo.m3u8\n")
+	video := string(sink.files["video.m3u8"])
+	assert.Contains(t, video, "#EXT-X-MAP:URI=\"video-init.mp4\"\n")
+	assert.Contains(t, video, "#EXT-X-MEDIA-SEQUENCE:2\n")
+	assert.Contains(t, video, "video-3.m4s\n#EXT-X-ENDLIST\n")
+	assert.Contains(t, string(sink.files["audio.m3u8"]), "#EXT-X-MAP:URI=\"audio-init.mp4\"\n")
+	assert.Contains(t, string(sink.files[dashManifest]), `media="video-$Number$.m4s" startNumber="2"`)
+
+	// Single track segments are CMAF
+	ftyp := mp4Child(t, sink.files["video-init.mp4"], "ftyp")
+	assert.Contains(t, string(ftyp), "cmfc")
+}
+
+func TestDashSaver_AudioTracks(t *testing.T) {
+	sink := &memSink{files: map[string][]byte{}}
+	saver := NewDashSaver(DashSaverConfig{
+		Audio:           true,
+		Video:           true,
+		SegmentDuration: time.Second,
+		Sink:            sink,
+		HLS:             true,
+		AudioTracks: []DashAudioTrack{
+			{TrackID: "floor-audio", Name: "floor", Language: "en"},
+			{TrackID: "interpreter-audio", Name: "fr", Language: "fr", Autoselect: true},
+		},
+	})
+	audio := 0
+	for i := 0; i < 120; i++ {
+		frame := h264Frame
+		if i%30 == 0 {
+			frame = h264Keyframe
+		}
+		assert.NoError(t, saver.Write(&avp.Sample{ID: "video", Type: avp.TypeH264, Timestamp: uint32(i * 3000), Payload: frame}))
+		for ; audio*3 < (i+1)*5; audio++ {
+			for _, id := range []string{"floor-audio", "interpreter-audio"} {
+				assert.NoError(t, saver.Write(&avp.Sample{ID: id, Type: avp.TypeOpus, Timestamp: uint32(audio * 960), Payload: opusSilence}))
+			}
+		}
+	}
+	saver.Close()
+
+	// Each track is packaged apart, the first played by default
+	for _, name := range []string{"audio-floor-init.mp4", "audio-fr-init.mp4", "audio-floor-3.m4s", "audio-fr-3.m4s"} {
+		_, ok := sink.files[name]
+		assert.True(t, ok, name)
+	}
+	master := string(sink.files["index.m3u8"])
+	assert.Contains(t, master, `#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="floor",LANGUAGE="en",DEFAULT=YES,AUTOSELECT=YES,URI="audio-floor.m3u8"`)
+	assert.Contains(t, master, `#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="fr",LANGUAGE="fr",DEFAULT=NO,AUTOSELECT=YES,URI="audio-fr.m3u8"`)
+	assert.Contains(t, master, `CODECS="avc1.42001e,opus",AUDIO="audio"`+"\nvideo.m3u8\n")
+
+	var m struct {
+		Sets []struct {
+			Lang string          `xml:"lang,attr"`
+			Role dash.Descriptor `xml:"Role"`
+		} `xml:"Period>AdaptationSet"`
+	}
+	assert.NoError(t, xml.Unmarshal(sink.files[dashManifest], &m))
+	assert.Len(t, m.Sets, 3)
+	assert.Equal(t, "en", m.Sets[1].Lang)
+	assert.Equal(t, "main", m.Sets[1].Role.Value)
+	assert.Equal(t, "fr", m.Sets[2].Lang)
+	assert.Equal(t, "alternate", m.Sets[2].Role.Value)
+}
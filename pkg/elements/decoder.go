@@ -3,10 +3,12 @@
 package elements
 
 import (
+	"image"
 	"sync"
 	"time"
 
 	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
 	log "github.com/pion/ion-log"
 	"github.com/xlab/libvpx-go/vpx"
 )
@@ -21,11 +23,20 @@ type Decoder struct {
 	typ   int
 	run   bool
 	async bool
+	// timestamp and captured of the last packet decoded
+	timestamp uint32
+	rate      uint32
+	captured  time.Time
 }
 
 // NewDecoder instance. Decoder takes as input VPX streams
-// and decodes it into a YCbCr image.
+// and decodes it into a YCbCr image. Decoding holds whole frames in
+// memory, so it isn't available in low memory mode.
 func NewDecoder(fps float32, outType int) *Decoder {
+	if avp.LowMemory() {
+		log.Errorf("decoder not available in low memory mode")
+		return nil
+	}
 	dec := &Decoder{
 		ctx: vpx.NewCodecCtx(),
 		typ: outType,
@@ -63,6 +74,7 @@ func (dec *Decoder) Write(sample *avp.Sample) error {
 
 		dec.Lock()
 		err := vpx.Error(vpx.CodecDecode(dec.ctx, string(payload), uint32(len(payload)), nil, 0))
+		dec.timestamp, dec.rate, dec.captured = sample.Timestamp, sample.ClockRate, sample.CaptureTime
 		dec.Unlock()
 		if err != nil {
 			return err
@@ -92,13 +104,24 @@ func (dec *Decoder) write() error {
 
 		if dec.typ == TypeYCbCr {
 			return dec.Node.Write(&avp.Sample{
-				Type:    TypeYCbCr,
-				Payload: img.ImageYCbCr(),
+				Type:        TypeYCbCr,
+				Timestamp:   dec.timestamp,
+				ClockRate:   dec.rate,
+				CaptureTime: dec.captured,
+				Payload:     img.ImageYCbCr(),
 			})
 		} else if dec.typ == TypeRGBA {
+			ycbcr := img.ImageYCbCr()
+			rgba := image.NewRGBA(ycbcr.Rect)
+			if err := pixel.ToRGBA(rgba, ycbcr); err != nil {
+				return err
+			}
 			return dec.Node.Write(&avp.Sample{
-				Type:    TypeRGBA,
-				Payload: img.ImageRGBA(),
+				Type:        TypeRGBA,
+				Timestamp:   dec.timestamp,
+				ClockRate:   dec.rate,
+				CaptureTime: dec.captured,
+				Payload:     rgba,
 			})
 		}
 	}
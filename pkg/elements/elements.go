@@ -16,6 +16,11 @@ const (
 	TypeYCbCr    = 104
 	TypeJPEG     = 105
 	TypeRGBA     = 106
+	TypePCM      = 107
+	TypeSpeaker  = 108
+	TypeVoice    = 109
+	TypeText     = 110
+	TypePNG      = 111
 )
 
 var (
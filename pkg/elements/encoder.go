@@ -0,0 +1,108 @@
+//go:build libvpx
+// +build libvpx
+
+package elements
+
+import (
+	"image"
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/vp8"
+	log "github.com/pion/ion-log"
+)
+
+// EncoderConfig configures Encoder.
+// FrameRate: Of the frames written in frames per second, 30 by default.
+// Bitrate: Targeted in bits per second, 1Mbps by default.
+// Keyframes: Most frames between keyframes, a second's worth by default.
+type EncoderConfig struct {
+	FrameRate int
+	Bitrate   int
+	Keyframes int
+}
+
+// Encoder instance
+type Encoder struct {
+	sync.Mutex
+	Node
+	cfg  EncoderConfig
+	enc  *vp8.Encoder
+	size image.Point
+	seq  uint16
+}
+
+// NewEncoder instance. Encoder takes as input YCbCr frames, e.g. of a
+// VideoCompositor, and encodes them to VP8 samples for a WebmSaver. Frames
+// changing size start again at a keyframe. Other samples pass through.
+// Encoding holds whole frames in memory, so it isn't available in low
+// memory mode.
+func NewEncoder(c EncoderConfig) *Encoder {
+	if avp.LowMemory() {
+		log.Errorf("encoder not available in low memory mode")
+		return nil
+	}
+	if c.FrameRate <= 0 {
+		c.FrameRate = 30
+	}
+	if c.Bitrate <= 0 {
+		c.Bitrate = 1000000
+	}
+	return &Encoder{cfg: c}
+}
+
+func (e *Encoder) Write(sample *avp.Sample) error {
+	img, ok := sample.Payload.(*image.YCbCr)
+	if sample.Type != TypeYCbCr || !ok {
+		return e.Node.Write(sample)
+	}
+	e.Lock()
+	defer e.Unlock()
+	if size := img.Rect.Size(); e.enc == nil || size != e.size {
+		if e.enc != nil {
+			e.enc.Close()
+			e.enc = nil
+		}
+		enc, err := vp8.NewEncoder(vp8.Config{
+			Width:     size.X,
+			Height:    size.Y,
+			FrameRate: e.cfg.FrameRate,
+			Bitrate:   e.cfg.Bitrate,
+			Keyframes: e.cfg.Keyframes,
+		})
+		if err != nil {
+			return err
+		}
+		e.enc, e.size = enc, size
+	}
+	frames, err := e.enc.Encode(img, false)
+	if err != nil {
+		return err
+	}
+	for _, f := range frames {
+		err := e.Node.Write(&avp.Sample{
+			ID:             sample.ID,
+			Type:           avp.TypeVP8,
+			SequenceNumber: e.seq,
+			Timestamp:      sample.Timestamp,
+			ClockRate:      sample.ClockRate,
+			CaptureTime:    sample.CaptureTime,
+			Payload:        f.Data,
+		})
+		e.seq++
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Encoder) Close() {
+	e.Lock()
+	if e.enc != nil {
+		e.enc.Close()
+		e.enc = nil
+	}
+	e.Unlock()
+	e.Node.Close()
+}
@@ -0,0 +1,72 @@
+package elements
+
+import (
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/seal"
+	log "github.com/pion/ion-log"
+)
+
+// EncryptWriterConfig configures an EncryptWriter.
+// Key: AES key of 16, 24 or 32 bytes, per session so one leaked key
+// doesn't open every recording.
+type EncryptWriterConfig struct {
+	Key []byte
+}
+
+// EncryptWriter instance
+type EncryptWriter struct {
+	sync.Mutex
+	sampleWriter *SampleWriter
+	seal         *seal.Writer
+	closed       bool
+}
+
+// NewEncryptWriter instance. EncryptWriter goes between a saver, e.g.
+// WebmSaver, and the writer of its file, such as a FileWriter or
+// StorageWriter, and encrypts the bytes it is given with AES-GCM, so
+// recordings never reach disk or storage in the clear. The output is the
+// format of package seal, read back with seal.NewReader.
+func NewEncryptWriter(c EncryptWriterConfig) (*EncryptWriter, error) {
+	w := &EncryptWriter{sampleWriter: NewSampleWriter()}
+	s, err := seal.NewWriter(w.sampleWriter, c.Key)
+	if err != nil {
+		return nil, err
+	}
+	w.seal = s
+	return w, nil
+}
+
+func (w *EncryptWriter) Write(sample *avp.Sample) error {
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return nil
+	}
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return nil
+	}
+	_, err := w.seal.Write(payload)
+	return err
+}
+
+// Attach attach a child element
+func (w *EncryptWriter) Attach(e avp.Element) {
+	w.sampleWriter.Attach(e)
+}
+
+// Close writes the last chunk and closes the children
+func (w *EncryptWriter) Close() {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	if err := w.seal.Close(); err != nil {
+		log.Errorf("EncryptWriter: writing last chunk: %s", err)
+	}
+	w.sampleWriter.Close()
+}
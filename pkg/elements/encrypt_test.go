@@ -0,0 +1,38 @@
+package elements
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/seal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptWriter(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	enc, err := NewEncryptWriter(EncryptWriterConfig{Key: key})
+	assert.NoError(t, err)
+	writer := NewBufWriter()
+	enc.Attach(writer)
+
+	saver := NewOggSaver(OggSaverConfig{})
+	saver.Attach(enc)
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt, Timestamp: 0}))
+	saver.Close()
+	assert.NotContains(t, writer.buf.String(), "OpusHead")
+
+	r, err := seal.NewReader(&writer.buf, key)
+	assert.NoError(t, err)
+	plain, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	pages := readOggPages(plain)
+	if assert.Len(t, pages, 4) {
+		assert.Equal(t, "OpusHead", string(pages[0].data[:8]))
+		assert.Equal(t, rawOpusPkt, pages[2].data)
+	}
+
+	_, err = NewEncryptWriter(EncryptWriterConfig{Key: []byte("short")})
+	assert.Equal(t, seal.ErrKeySize, err)
+}
@@ -4,38 +4,88 @@ import (
 	"bufio"
 	"io"
 	"os"
+	"path/filepath"
 
 	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
 	log "github.com/pion/ion-log"
 )
 
+// FileWriterConfig configures a FileWriter.
+// Path may use forward slashes on every platform; missing directories are created.
+// BufSize is the buffer size in bytes. Pass <=0 to disable buffering.
+// It is capped in low memory mode.
+// Exclusive fails instead of overwriting an existing file.
+// Sync flushes the file and its directory entry to disk on close, so a
+// finished recording survives power loss. It makes Close slower.
+// FS is the file system written to, defaults to OSFS.
+type FileWriterConfig struct {
+	Path      string
+	BufSize   int
+	Exclusive bool
+	Sync      bool
+	FS        FS
+}
+
 // FileWriter instance
 type FileWriter struct {
 	Leaf
+	cfg  FileWriterConfig
+	f    File
 	wr   io.Writer
 	path string
+	rec  *recording.Recording
 }
 
 // NewFileWriter instance
 // bufSize is the buffer size in bytes. Pass <=0 to disable buffering.
 func NewFileWriter(path string, bufSize int) *FileWriter {
-	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-
+	fw, err := NewFileWriterWithConfig(FileWriterConfig{Path: path, BufSize: bufSize})
 	if err != nil {
 		log.Errorf("error initializing filewriter: %s", err)
 		return nil
 	}
+	return fw
+}
+
+// NewFileWriterWithConfig creates the file and returns a writer to it
+func NewFileWriterWithConfig(c FileWriterConfig) (*FileWriter, error) {
+	if c.FS == nil {
+		c.FS = OSFS
+	}
+	path := filepath.Clean(filepath.FromSlash(c.Path))
+	if err := c.FS.MkdirAll(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE
+	if c.Exclusive {
+		flag |= os.O_EXCL
+	} else {
+		flag |= os.O_TRUNC
+	}
+	f, err := c.FS.OpenFile(path, flag, 0600)
+	if err != nil {
+		return nil, err
+	}
 
 	fw := &FileWriter{
+		cfg:  c,
+		f:    f,
+		wr:   f,
 		path: path,
 	}
-	if bufSize > 0 {
-		fw.wr = bufio.NewWriterSize(f, bufSize)
-	} else {
-		fw.wr = f
+	if c.BufSize = avp.WriteBufSize(c.BufSize); c.BufSize > 0 {
+		fw.wr = bufio.NewWriterSize(f, c.BufSize)
 	}
 	log.Infof("FileWriter opened %s", path)
-	return fw
+	return fw, nil
+}
+
+// SetRecording sets the recording moved to Complete or Failed once the
+// file is closed
+func (w *FileWriter) SetRecording(r *recording.Recording) {
+	w.rec = r
 }
 
 func (w *FileWriter) Write(sample *avp.Sample) error {
@@ -44,11 +94,29 @@ func (w *FileWriter) Write(sample *avp.Sample) error {
 }
 
 func (w *FileWriter) Close() {
-	if c, ok := w.wr.(*bufio.Writer); ok {
-		c.Flush()
-	}
-	if c, ok := w.wr.(io.Closer); ok {
-		c.Close()
+	setState(w.rec, recording.StateFinalizing)
+	if err := w.close(); err != nil {
+		log.Errorf("FileWriter error closing %s: %s", w.path, err)
+		w.rec.Fail(err)
+		return
 	}
+	setState(w.rec, recording.StateComplete)
 	log.Infof("FileWriter closed %s", w.path)
 }
+
+func (w *FileWriter) close() error {
+	var err error
+	if b, ok := w.wr.(*bufio.Writer); ok {
+		err = b.Flush()
+	}
+	if err == nil && w.cfg.Sync {
+		err = w.f.Sync()
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && w.cfg.Sync {
+		err = w.cfg.FS.SyncDir(filepath.Dir(w.path))
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package elements
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filewriter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Forward slashes work on every platform and missing dirs are created
+	path := filepath.ToSlash(dir) + "/a/b/rec.webm"
+	fw, err := NewFileWriterWithConfig(FileWriterConfig{Path: path, BufSize: 4, Exclusive: true, Sync: true})
+	assert.NoError(t, err)
+	assert.NoError(t, fw.Write(&avp.Sample{Payload: []byte("hello world")}))
+	fw.Close()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "a", "b", "rec.webm"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+
+	// Exclusive create doesn't overwrite
+	_, err = NewFileWriterWithConfig(FileWriterConfig{Path: path, Exclusive: true})
+	assert.True(t, os.IsExist(err))
+
+	fw, err = NewFileWriterWithConfig(FileWriterConfig{Path: path})
+	assert.NoError(t, err)
+	fw.Close()
+	data, err = ioutil.ReadFile(filepath.FromSlash(path))
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+type fakeFile struct {
+	bytes.Buffer
+	syncs  int
+	closed bool
+}
+
+func (f *fakeFile) Sync() error {
+	f.syncs++
+	return nil
+}
+
+func (f *fakeFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+type fakeFS struct {
+	files    map[string]*fakeFile
+	flags    map[string]int
+	dirSyncs []string
+	err      error
+}
+
+func (fs *fakeFS) MkdirAll(dir string) error {
+	return fs.err
+}
+
+func (fs *fakeFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if fs.err != nil {
+		return nil, fs.err
+	}
+	f := &fakeFile{}
+	fs.files[name] = f
+	fs.flags[name] = flag
+	return f, nil
+}
+
+func (fs *fakeFS) SyncDir(dir string) error {
+	fs.dirSyncs = append(fs.dirSyncs, dir)
+	return nil
+}
+
+func TestFileWriter_FS(t *testing.T) {
+	fs := &fakeFS{files: map[string]*fakeFile{}, flags: map[string]int{}}
+	path := filepath.FromSlash("rec/out.webm")
+
+	fw, err := NewFileWriterWithConfig(FileWriterConfig{Path: "rec/./out.webm", BufSize: 1024, Sync: true, FS: fs})
+	assert.NoError(t, err)
+	assert.NoError(t, fw.Write(&avp.Sample{Payload: []byte("data")}))
+	f := fs.files[path]
+	assert.Equal(t, 0, f.Len())
+	assert.Equal(t, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fs.flags[path])
+
+	fw.Close()
+	assert.Equal(t, "data", f.String())
+	assert.Equal(t, 1, f.syncs)
+	assert.True(t, f.closed)
+	assert.Equal(t, []string{"rec"}, fs.dirSyncs)
+
+	fs.err = errors.New("read-only")
+	_, err = NewFileWriterWithConfig(FileWriterConfig{Path: path, FS: fs})
+	assert.Equal(t, fs.err, err)
+}
@@ -0,0 +1,52 @@
+package elements
+
+import (
+	"io"
+	"os"
+)
+
+// File is a file opened by an FS
+type File interface {
+	io.WriteCloser
+	// Sync commits the file's contents to stable storage
+	Sync() error
+}
+
+// FS is the file system FileWriter writes to. Apps with sandboxed or
+// virtual storage, and tests simulating disk failures, can provide their own.
+type FS interface {
+	MkdirAll(dir string) error
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
+	// SyncDir commits directory entries, e.g. of a newly created file
+	SyncDir(dir string) error
+}
+
+// OSFS is the operating system's file system
+var OSFS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) MkdirAll(dir string) error {
+	return os.MkdirAll(dir, 0755)
+}
+
+func (osFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	f, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return syncFile{f}, nil
+}
+
+func (osFS) SyncDir(dir string) error {
+	return syncDir(dir)
+}
+
+// syncFile makes Sync reach the disk on platforms where fsync alone doesn't
+type syncFile struct {
+	*os.File
+}
+
+func (f syncFile) Sync() error {
+	return fullSync(f.File)
+}
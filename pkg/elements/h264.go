@@ -0,0 +1,67 @@
+package elements
+
+import "github.com/pion/ion-avp/pkg/colorspace"
+
+// H.264 NAL unit types
+const (
+	nalIDR = 5
+	nalSPS = 7
+	nalPPS = 8
+	nalAUD = 9
+)
+
+// avcFrame is an H.264 access unit converted from Annex B to the length
+// prefixed NAL units MP4 and Matroska store
+type avcFrame struct {
+	data     []byte
+	sps, pps []byte
+	key      bool
+}
+
+// readAVC converts an Annex B access unit. Parameter sets are returned
+// apart, for the decoder configuration, and access unit delimiters are
+// dropped.
+func readAVC(payload []byte) avcFrame {
+	var f avcFrame
+	for _, nal := range colorspace.SplitAnnexB(payload) {
+		if len(nal) == 0 {
+			continue
+		}
+		switch nal[0] & 0x1f {
+		case nalSPS:
+			f.sps = nal
+			continue
+		case nalPPS:
+			f.pps = nal
+			continue
+		case nalAUD:
+			continue
+		case nalIDR:
+			f.key = true
+		}
+		f.data = append(f.data, u32(uint32(len(nal)))...)
+		f.data = append(f.data, nal...)
+	}
+	return f
+}
+
+// configurable reports whether the frame starts a stream, a keyframe with
+// the parameter sets of its decoder configuration
+func (f avcFrame) configurable() bool {
+	return f.key && len(f.sps) >= 4 && f.pps != nil
+}
+
+// avcConfig returns the AVCDecoderConfigurationRecord of a sequence and
+// picture parameter set, with 4 byte NAL unit lengths
+func avcConfig(sps, pps []byte) []byte {
+	var b []byte
+	for _, f := range [][]byte{
+		u8(1), sps[1:4], // version, profile, compatibility, level
+		u8(0xff), // 4 byte NAL unit lengths
+		u8(0xe1), u16(uint16(len(sps))), sps,
+		u8(1), u16(uint16(len(pps))), pps,
+	} {
+		b = append(b, f...)
+	}
+	return b
+}
@@ -0,0 +1,201 @@
+package elements
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	log "github.com/pion/ion-log"
+)
+
+// Highlight signals
+const (
+	// SignalEnergy is audio activity, from the size of Opus frames. Opus
+	// spends more bits on loud, busy audio and almost none on silence.
+	SignalEnergy = "energy"
+	// SignalSpeaker counts speech starting after a pause
+	SignalSpeaker = "speaker"
+	// SignalReaction counts reactions passed to Signal, e.g. from chat
+	SignalReaction = "reaction"
+)
+
+// activeFrame is the Opus frame size from which audio counts as speech,
+// silence and comfort noise frames are a few bytes
+const activeFrame = 40
+
+// HighlightConfig configures a HighlightDetector.
+// Window is the length of the segments scored, defaults to 10s.
+// Count is the number of highlights kept, defaults to 12.
+// Weights weigh each signal in the score, signals missing from it weigh 1.
+type HighlightConfig struct {
+	Window  time.Duration
+	Count   int
+	Weights map[string]float64
+}
+
+// Highlight is a scored segment of the recording
+type Highlight struct {
+	Start   time.Duration      `json:"start"`
+	End     time.Duration      `json:"end"`
+	Score   float64            `json:"score"`
+	Signals map[string]float64 `json:"signals"`
+}
+
+// Highlights is the sidecar written by HighlightDetector
+type Highlights struct {
+	Track       string      `json:"track"`
+	Correlation string      `json:"correlation,omitempty"`
+	Highlights  []Highlight `json:"highlights"`
+}
+
+// HighlightDetector passes samples to its children while scoring windows
+// of the track by audio energy, speech starting and reactions. On close
+// it writes the best windows, in time order, as a JSON sidecar.
+type HighlightDetector struct {
+	Node
+	mu      sync.Mutex
+	cfg     HighlightConfig
+	track   string
+	corr    string
+	open    func() (io.WriteCloser, error)
+	clock   trackClock
+	quiet   time.Duration // start of the current pause, -1 while speaking
+	windows []map[string]float64
+}
+
+// NewHighlightDetector instance. open creates the sidecar on close.
+func NewHighlightDetector(c HighlightConfig, track string, open func() (io.WriteCloser, error)) *HighlightDetector {
+	if c.Window <= 0 {
+		c.Window = 10 * time.Second
+	}
+	if c.Count <= 0 {
+		c.Count = 12
+	}
+	return &HighlightDetector{cfg: c, track: track, open: open}
+}
+
+// SetCorrelation sets the correlation ID written to the sidecar
+func (d *HighlightDetector) SetCorrelation(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.corr = id
+}
+
+func (d *HighlightDetector) Write(sample *avp.Sample) error {
+	if sample.Type == avp.TypeOpus {
+		d.audio(sample)
+	}
+	return d.Node.Write(sample)
+}
+
+func (d *HighlightDetector) audio(sample *avp.Sample) {
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	at := d.clock.since(sample, audioClockRate)
+	if at < 0 {
+		return
+	}
+
+	if len(payload) < activeFrame {
+		if d.quiet < 0 {
+			d.quiet = at
+		}
+		return
+	}
+	d.add(SignalEnergy, at, float64(len(payload)))
+	if d.quiet >= 0 && at-d.quiet >= time.Second {
+		d.add(SignalSpeaker, at, 1)
+	}
+	d.quiet = -1
+}
+
+// Signal adds value to a signal at a time in the track, such as a
+// reaction or a speaker change known to the application
+func (d *HighlightDetector) Signal(name string, at time.Duration, value float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.add(name, at, value)
+}
+
+func (d *HighlightDetector) add(name string, at time.Duration, value float64) {
+	if at < 0 {
+		return
+	}
+	i := int(at / d.cfg.Window)
+	for len(d.windows) <= i {
+		d.windows = append(d.windows, map[string]float64{})
+	}
+	d.windows[i][name] += value
+}
+
+// Highlights scores the windows so far and returns the best, in time
+// order. Each signal is normalized to its busiest window before weighing.
+func (d *HighlightDetector) Highlights() Highlights {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	peak := map[string]float64{}
+	for _, w := range d.windows {
+		for name, v := range w {
+			if v > peak[name] {
+				peak[name] = v
+			}
+		}
+	}
+	var all []Highlight
+	for i, w := range d.windows {
+		h := Highlight{
+			Start:   time.Duration(i) * d.cfg.Window,
+			End:     time.Duration(i+1) * d.cfg.Window,
+			Signals: map[string]float64{},
+		}
+		for name, v := range w {
+			if peak[name] <= 0 {
+				continue
+			}
+			weight, ok := d.cfg.Weights[name]
+			if !ok {
+				weight = 1
+			}
+			h.Signals[name] = v / peak[name]
+			h.Score += weight * h.Signals[name]
+		}
+		if h.Score > 0 {
+			all = append(all, h)
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Score > all[j].Score })
+	if len(all) > d.cfg.Count {
+		all = all[:d.cfg.Count]
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Start < all[j].Start })
+	return Highlights{Track: d.track, Correlation: d.corr, Highlights: all}
+}
+
+func (d *HighlightDetector) Close() {
+	d.Node.Close()
+	if err := d.write(); err != nil {
+		log.Errorf("HighlightDetector error writing highlights of %s: %s", d.track, err)
+	}
+}
+
+func (d *HighlightDetector) write() error {
+	w, err := d.open()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d.Highlights()); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
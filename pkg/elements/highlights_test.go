@@ -0,0 +1,57 @@
+package elements
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightDetector(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewHighlightDetector(HighlightConfig{
+		Window:  time.Second,
+		Count:   2,
+		Weights: map[string]float64{SignalReaction: 3},
+	}, "audio", func() (io.WriteCloser, error) {
+		return nopCloser{&buf}, nil
+	})
+	child := NewBufWriter()
+	d.Attach(child)
+
+	silence, speech, loud := make([]byte, 3), make([]byte, 60), make([]byte, 120)
+	// 20ms frames: silence for 2s, speech for 1s, silence for 2s, loud for 1s
+	var frames [][]byte
+	for _, part := range []struct {
+		payload []byte
+		secs    int
+	}{{silence, 2}, {speech, 1}, {silence, 2}, {loud, 1}} {
+		for i := 0; i < part.secs*50; i++ {
+			frames = append(frames, part.payload)
+		}
+	}
+	for i, f := range frames {
+		assert.NoError(t, d.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(1000 + i*960), Payload: f}))
+	}
+	assert.Equal(t, len(frames)*3+50*57+50*117, child.buf.Len())
+
+	// The loud second scores highest, a reaction during the pause beats speech
+	d.Signal(SignalReaction, 3500*time.Millisecond, 1)
+	d.Close()
+
+	var h Highlights
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &h))
+	assert.Equal(t, "audio", h.Track)
+	assert.Len(t, h.Highlights, 2)
+	assert.Equal(t, 3*time.Second, h.Highlights[0].Start)
+	assert.Equal(t, 3.0, h.Highlights[0].Score)
+	assert.Equal(t, 5*time.Second, h.Highlights[1].Start)
+	assert.Equal(t, 6*time.Second, h.Highlights[1].End)
+	assert.Equal(t, 1.0, h.Highlights[1].Signals[SignalEnergy])
+	assert.Equal(t, 1.0, h.Highlights[1].Signals[SignalSpeaker])
+	assert.Equal(t, 2.0, h.Highlights[1].Score)
+}
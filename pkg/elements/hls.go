@@ -0,0 +1,318 @@
+package elements
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/hls"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// Names of the files of an HLS stream
+const (
+	hlsPlaylist = "index.m3u8"
+	hlsInit     = "init.mp4"
+)
+
+// HlsSaverConfig configures HlsSaver.
+// Audio: Record the audio track, Opus.
+// Video: Record the video track, H.264.
+// Recording: Optional state machine moved along as the recording progresses.
+// SegmentDuration: How much media each segment holds, defaults to 6s.
+// Segments start at a video keyframe, so may be longer.
+// Window: How many of the latest segments the playlist lists, older ones
+// being removed. By default it lists every segment so viewers can seek
+// back to the start.
+// Sink: Where the stream is written, e.g. a DirSink.
+// Encryption: Optional encryption of the segments.
+// VOD: When the stream ends, replace the playlist with a VOD one listing
+// every segment from the start, so its URL plays the stream back. Segments
+// leaving the live window are kept for it.
+// Compact: With VOD, join consecutive segments into ones of about this
+// duration for the VOD playlist, e.g. a minute, for fewer files to serve,
+// removing the live segments. Players still following the live playlist
+// as it ends may then skip or repeat some of the stream. Encrypted
+// segments aren't joined.
+// Counter: Optional persisted number of the next segment. A stream resumed
+// after a restart carries on the media sequence, its first segment marked
+// as a discontinuity, so players following it don't refetch old numbers.
+type HlsSaverConfig struct {
+	Audio           bool
+	Video           bool
+	Recording       *recording.Recording
+	SegmentDuration time.Duration
+	Window          int
+	Sink            Sink
+	Encryption      *HlsEncryption
+	VOD             bool
+	Compact         time.Duration
+	Counter         Counter
+}
+
+// HlsEncryption configures AES-128 encryption of the segments of an HLS
+// stream, e.g. to protect live previews. SAMPLE-AES isn't supported.
+// KeyURI: Template of the uri players fetch keys from, with "{key}"
+// replaced by the number of the key, by default key-{key}.key next to the
+// playlist.
+// Rotate: How many segments are encrypted with a key before the next, by
+// default one key encrypts the stream.
+// Keys: Optional callback storing each key for delivery, e.g. in a key
+// server. By default keys are written to the sink as key-N.key.
+type HlsEncryption struct {
+	KeyURI string
+	Rotate int
+	Keys   func(n int, key []byte) error
+}
+
+// HlsSaver instance
+type HlsSaver struct {
+	sync.Mutex
+	mp4      *Mp4Saver
+	cfg      HlsSaverConfig
+	playlist hls.Media
+	next     int // number of the next segment
+	first    int // of the segments of this stream
+	failed   bool
+	key      *hls.Key
+	keys     int // number of keys made
+	uses     int // of the key, by segments
+	// segments of the VOD playlist, and the data and duration of the next
+	// to compact
+	vod     []hls.Segment
+	joining []byte
+	joined  time.Duration
+}
+
+// NewHlsSaver instance. HlsSaver packages H.264 video and Opus audio as
+// fMP4 segments, listed in a live playlist index.m3u8 updated as each is
+// written, so a session can be watched while it is recorded by players
+// fetching them over plain HTTP. On closing the playlist is ended.
+// MPEG-TS segments aren't supported, as they can't carry Opus.
+func NewHlsSaver(c HlsSaverConfig) *HlsSaver {
+	if c.SegmentDuration <= 0 {
+		c.SegmentDuration = 6 * time.Second
+	}
+	if c.Compact > 0 && (!c.VOD || c.Encryption != nil) {
+		log.Warnf("HLS saver: only unencrypted VOD segments are compacted")
+		c.Compact = 0
+	}
+	s := &HlsSaver{
+		cfg: c,
+		playlist: hls.Media{
+			Init:           hlsInit,
+			Event:          c.Window <= 0,
+			TargetDuration: c.SegmentDuration,
+		},
+	}
+	if c.Counter != nil {
+		s.next = c.Counter.Load()
+		s.first = s.next
+		s.playlist.Sequence = s.next
+	}
+	s.mp4 = NewMp4Saver(&Mp4SaverConfig{
+		Audio:            c.Audio,
+		Video:            c.Video,
+		Recording:        c.Recording,
+		FragmentDuration: c.SegmentDuration,
+	})
+	s.mp4.segment = s.segment
+	return s
+}
+
+func (s *HlsSaver) Write(sample *avp.Sample) error {
+	return s.mp4.Write(sample)
+}
+
+// Attach isn't supported, the stream is written to the sink
+func (s *HlsSaver) Attach(e avp.Element) {
+	log.Warnf("HlsSaver writes to its sink")
+}
+
+// Close writes the last segment and ends the playlist
+func (s *HlsSaver) Close() {
+	s.mp4.Close()
+
+	s.Lock()
+	defer s.Unlock()
+	if s.failed || s.playlist.Ended {
+		return
+	}
+	s.playlist.Ended = true
+	if s.cfg.VOD {
+		if err := s.finalize(); err != nil {
+			s.fail(err)
+			return
+		}
+	}
+	if err := s.publish(); err != nil {
+		s.fail(err)
+		return
+	}
+	if s.cfg.Compact > 0 {
+		// The live segments were joined into the VOD playlist's
+		for i := s.first; i < s.next; i++ {
+			if err := s.cfg.Sink.Remove(fmt.Sprintf("segment-%d.m4s", i)); err != nil {
+				log.Warnf("HLS saver: removing segment %d: %s", i, err)
+			}
+		}
+	}
+	setState(s.cfg.Recording, recording.StateComplete)
+}
+
+// finalize replaces the live playlist with the VOD one, joining the last
+// segments compacted
+func (s *HlsSaver) finalize() error {
+	if len(s.joining) > 0 {
+		if err := s.join(); err != nil {
+			return err
+		}
+	}
+	s.playlist = hls.Media{
+		Init:           hlsInit,
+		Segments:       s.vod,
+		Ended:          true,
+		VOD:            true,
+		TargetDuration: s.cfg.SegmentDuration,
+	}
+	return nil
+}
+
+// join writes the segments joined so far as one of the VOD playlist
+func (s *HlsSaver) join() error {
+	// Numbered after the first segment, so those of a stream resumed
+	// don't overwrite the last's
+	name := fmt.Sprintf("vod-%d.m4s", s.first+len(s.vod))
+	if err := s.cfg.Sink.Put(name, s.joining); err != nil {
+		return err
+	}
+	s.vod = append(s.vod, hls.Segment{URI: name, Duration: s.joined})
+	s.joining, s.joined = nil, 0
+	return nil
+}
+
+// segment stores the initialization segment, or a media segment holding d
+// and lists it in the playlist
+func (s *HlsSaver) segment(data []byte, _, d time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.failed {
+		return nil
+	}
+
+	if d == 0 {
+		if err := s.cfg.Sink.Put(hlsInit, data); err != nil {
+			s.fail(err)
+			return err
+		}
+		return nil
+	}
+	if s.cfg.Encryption != nil {
+		var err error
+		if data, err = s.encrypt(data); err != nil {
+			s.fail(err)
+			return err
+		}
+	}
+	name := fmt.Sprintf("segment-%d.m4s", s.next)
+	if c := s.cfg.Counter; c != nil {
+		if err := c.Store(s.next + 1); err != nil {
+			log.Warnf("HLS saver: storing segment number: %s", err)
+		}
+	}
+	if err := s.cfg.Sink.Put(name, data); err != nil {
+		s.fail(err)
+		return err
+	}
+	s.next++
+	segment := hls.Segment{URI: name, Duration: d, Key: s.key}
+	// The stream resumed doesn't follow on from the segments before it
+	segment.Discontinuity = s.first > 0 && s.next == s.first+1
+	s.playlist.Segments = append(s.playlist.Segments, segment)
+	switch {
+	case s.cfg.Compact > 0:
+		// Fragments of a stream play one after another, so segments
+		// are joined whole
+		s.joining = append(s.joining, data...)
+		if s.joined += d; s.joined >= s.cfg.Compact {
+			if err := s.join(); err != nil {
+				s.fail(err)
+				return err
+			}
+		}
+	case s.cfg.VOD:
+		s.vod = append(s.vod, segment)
+	}
+	if w := s.cfg.Window; w > 0 && len(s.playlist.Segments) > w {
+		if s.playlist.Segments[0].Discontinuity {
+			s.playlist.DiscontinuitySequence++
+		}
+		s.playlist.Segments = s.playlist.Segments[1:]
+		s.playlist.Sequence++
+		// Players may still fetch segments of the playlist they loaded
+		// last, so segments are kept a window after leaving it
+		if old := s.playlist.Sequence - w - 1; old >= s.first && !s.cfg.VOD {
+			if err := s.cfg.Sink.Remove(fmt.Sprintf("segment-%d.m4s", old)); err != nil {
+				log.Warnf("HLS saver: removing segment %d: %s", old, err)
+			}
+		}
+	}
+	if err := s.publish(); err != nil {
+		s.fail(err)
+		return err
+	}
+	return nil
+}
+
+// encrypt encrypts the next segment, with a new key when the last has
+// encrypted enough
+func (s *HlsSaver) encrypt(data []byte) ([]byte, error) {
+	enc := s.cfg.Encryption
+	if s.key == nil || (enc.Rotate > 0 && s.uses >= enc.Rotate) {
+		key := make([]byte, hls.KeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		n := s.keys
+		uri := enc.KeyURI
+		if uri == "" {
+			uri = "key-{key}.key"
+		}
+		var err error
+		if enc.Keys != nil {
+			err = enc.Keys(n, key)
+		} else {
+			err = s.cfg.Sink.Put(fmt.Sprintf("key-%d.key", n), key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("storing key %d: %w", n, err)
+		}
+		s.key = &hls.Key{URI: strings.ReplaceAll(uri, "{key}", strconv.Itoa(n)), Key: key}
+		s.keys++
+		s.uses = 0
+	}
+	s.uses++
+	return hls.Encrypt(s.key.Key, s.next, data)
+}
+
+// publish writes the playlist
+func (s *HlsSaver) publish() error {
+	var b bytes.Buffer
+	if _, err := s.playlist.WriteTo(&b); err != nil {
+		return err
+	}
+	return s.cfg.Sink.Put(hlsPlaylist, b.Bytes())
+}
+
+// fail stops writing the stream
+func (s *HlsSaver) fail(err error) {
+	log.Errorf("HLS saver: %s", err)
+	s.failed = true
+	s.cfg.Recording.Fail(err)
+}
@@ -0,0 +1,180 @@
+package elements
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+// memSink is an hls.Sink keeping files in memory
+type memSink struct {
+	sync.Mutex
+	files map[string][]byte
+}
+
+func (s *memSink) Put(name string, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *memSink) Remove(name string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.files, name)
+	return nil
+}
+
+func TestHlsSaver(t *testing.T) {
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "live")
+	sink := &memSink{files: map[string][]byte{}}
+	saver := NewHlsSaver(HlsSaverConfig{
+		Audio:           true,
+		Video:           true,
+		Recording:       rec,
+		SegmentDuration: time.Second,
+		Window:          2,
+		Sink:            sink,
+	})
+
+	// 5s of 30fps video with a keyframe a second, and 20ms audio packets
+	audio := 0
+	for i := 0; i < 150; i++ {
+		frame := h264Frame
+		if i%30 == 0 {
+			frame = h264Keyframe
+		}
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Timestamp: uint32(i * 3000), Payload: frame}))
+		for ; audio*3 < (i+1)*5; audio++ {
+			assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(audio * 960), Payload: opusSilence}))
+		}
+		if i == 100 {
+			// A live window of the latest segments
+			playlist := string(sink.files["index.m3u8"])
+			assert.Contains(t, playlist, "#EXT-X-MEDIA-SEQUENCE:1\n")
+			assert.Contains(t, playlist, "#EXTINF:1.000,\nsegment-1.m4s\n#EXTINF:1.000,\nsegment-2.m4s\n")
+			assert.NotContains(t, playlist, "#EXT-X-ENDLIST")
+		}
+	}
+	saver.Close()
+	assert.Equal(t, recording.StateComplete, rec.State())
+
+	playlist := string(sink.files["index.m3u8"])
+	assert.Contains(t, playlist, "#EXT-X-MEDIA-SEQUENCE:3\n")
+	assert.True(t, strings.HasSuffix(playlist, "segment-4.m4s\n#EXT-X-ENDLIST\n"))
+	types, _ := mp4Children(t, sink.files["init.mp4"])
+	assert.Equal(t, []string{"ftyp", "moov"}, types)
+	// Segments are kept a window after leaving the playlist
+	for i := 0; i < 5; i++ {
+		_, ok := sink.files[fmt.Sprintf("segment-%d.m4s", i)]
+		assert.Equal(t, i > 0, ok, "segment %d", i)
+	}
+	types, _ = mp4Children(t, sink.files["segment-4.m4s"])
+	assert.Equal(t, []string{"moof", "mdat"}, types)
+}
+
+func TestHlsSaver_Encryption(t *testing.T) {
+	sink := &memSink{files: map[string][]byte{}}
+	saver := NewHlsSaver(HlsSaverConfig{
+		Audio:           true,
+		SegmentDuration: time.Second,
+		Sink:            sink,
+		Encryption:      &HlsEncryption{KeyURI: "https://keys.example.com/live/{key}", Rotate: 2},
+	})
+	// 3s of 20ms audio packets
+	for i := 0; i < 150; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: opusSilence}))
+	}
+	saver.Close()
+
+	playlist := string(sink.files["index.m3u8"])
+	assert.Contains(t, playlist, "#EXT-X-KEY:METHOD=AES-128,URI=\"https://keys.example.com/live/0\"\n#EXTINF:1.000,\nsegment-0.m4s\n#EXTINF:1.000,\nsegment-1.m4s\n")
+	assert.Contains(t, playlist, "#EXT-X-KEY:METHOD=AES-128,URI=\"https://keys.example.com/live/1\"\n#EXTINF:1.000,\nsegment-2.m4s\n")
+	assert.Len(t, sink.files["key-0.key"], 16)
+	assert.Len(t, sink.files["key-1.key"], 16)
+
+	// Segments decrypt with their key, the init segment isn't encrypted
+	types, _ := mp4Children(t, sink.files["init.mp4"])
+	assert.Equal(t, []string{"ftyp", "moov"}, types)
+	seg := sink.files["segment-2.m4s"]
+	block, err := aes.NewCipher(sink.files["key-1.key"])
+	assert.NoError(t, err)
+	iv := make([]byte, aes.BlockSize)
+	iv[15] = 2
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(seg, seg)
+	types, _ = mp4Children(t, seg[:len(seg)-int(seg[len(seg)-1])])
+	assert.Equal(t, []string{"moof", "mdat"}, types)
+}
+
+func TestHlsSaver_VOD(t *testing.T) {
+	sink := &memSink{files: map[string][]byte{}}
+	saver := NewHlsSaver(HlsSaverConfig{
+		Audio:           true,
+		SegmentDuration: time.Second,
+		Window:          2,
+		Sink:            sink,
+		VOD:             true,
+		Compact:         2 * time.Second,
+	})
+	// 5s of 20ms audio packets
+	for i := 0; i < 250; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: opusSilence}))
+		if i == 200 {
+			// Live until the stream ends
+			playlist := string(sink.files["index.m3u8"])
+			assert.NotContains(t, playlist, "VOD")
+			assert.NotContains(t, playlist, "#EXT-X-ENDLIST")
+		}
+	}
+	saver.Close()
+
+	playlist := string(sink.files["index.m3u8"])
+	assert.Contains(t, playlist, "#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n")
+	assert.Contains(t, playlist, "#EXTINF:2.000,\nvod-0.m4s\n#EXTINF:2.000,\nvod-1.m4s\n")
+	assert.True(t, strings.HasSuffix(playlist, "vod-2.m4s\n#EXT-X-ENDLIST\n"))
+	for i := 0; i < 5; i++ {
+		assert.NotContains(t, sink.files, fmt.Sprintf("segment-%d.m4s", i))
+	}
+	// Joined segments are their fragments one after another
+	types, _ := mp4Children(t, sink.files["vod-0.m4s"])
+	assert.Equal(t, []string{"moof", "mdat", "moof", "mdat"}, types)
+}
+
+func TestHlsSaver_Counter(t *testing.T) {
+	// Resumed after a restart, with segments 0 to 3 written before
+	counter := &memCounter{n: 4}
+	sink := &memSink{files: map[string][]byte{}}
+	saver := NewHlsSaver(HlsSaverConfig{
+		Audio:           true,
+		SegmentDuration: time.Second,
+		Window:          2,
+		Sink:            sink,
+		Counter:         counter,
+	})
+	// 3s of 20ms audio packets
+	for i := 0; i < 150; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: opusSilence}))
+		if i == 110 {
+			// The media sequence carries on, past a discontinuity
+			playlist := string(sink.files["index.m3u8"])
+			assert.Contains(t, playlist, "#EXT-X-MEDIA-SEQUENCE:4\n")
+			assert.Contains(t, playlist, "#EXT-X-DISCONTINUITY\n#EXTINF:1.000,\nsegment-4.m4s\n#EXTINF:1.000,\nsegment-5.m4s\n")
+		}
+	}
+	saver.Close()
+
+	playlist := string(sink.files["index.m3u8"])
+	assert.Contains(t, playlist, "#EXT-X-MEDIA-SEQUENCE:5\n#EXT-X-DISCONTINUITY-SEQUENCE:1\n")
+	assert.NotContains(t, playlist, "#EXT-X-DISCONTINUITY\n")
+	assert.True(t, strings.HasSuffix(playlist, "segment-6.m4s\n#EXT-X-ENDLIST\n"))
+	assert.Equal(t, 7, counter.Load())
+}
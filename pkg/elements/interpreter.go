@@ -0,0 +1,103 @@
+package elements
+
+import (
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/interpret"
+)
+
+// InterpreterRouterConfig configures an InterpreterRouter
+// Routes: Of the session's channels to interpreters, e.g.
+// interpret.Session(sid), set through the control API while it runs.
+type InterpreterRouterConfig struct {
+	Routes *interpret.Routes
+}
+
+// routedChannel keeps the timestamps of a channel running on across
+// handovers between interpreters, whose tracks each have their own
+type routedChannel struct {
+	source    string
+	started   bool
+	tsOffset  uint32
+	seqOffset uint16
+	last      uint32    // timestamp of the last sample out
+	lastSeq   uint16    // sequence number of the last sample out
+	frame     uint32    // ticks between the last two samples out
+	at        time.Time // the last sample went out
+}
+
+// InterpreterRouter instance
+type InterpreterRouter struct {
+	Node
+	mu       sync.Mutex
+	routes   *interpret.Routes
+	channels map[string]*routedChannel
+}
+
+// NewInterpreterRouter instance. InterpreterRouter takes the audio of the
+// tracks of a session, processed with one pid, and writes that of each
+// channel's interpreter as samples with the channel's name for ID, e.g. for
+// DashSaver's AudioTracks. Audio of tracks routed to no channel is dropped,
+// other samples, e.g. video, pass through.
+func NewInterpreterRouter(c InterpreterRouterConfig) *InterpreterRouter {
+	return &InterpreterRouter{
+		routes:   c.Routes,
+		channels: make(map[string]*routedChannel),
+	}
+}
+
+func (r *InterpreterRouter) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus && sample.Type != avp.TypeAAC {
+		return r.Node.Write(sample)
+	}
+	for _, name := range r.routes.Channels(sample.ID) {
+		if err := r.Node.Write(r.route(name, sample)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// route returns sample as one of a channel, continuing its timestamps and
+// sequence numbers from the last interpreter's after a handover
+func (r *InterpreterRouter) route(name string, sample *avp.Sample) *avp.Sample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rate := sample.ClockRate
+	if rate == 0 {
+		rate = audioClockRate
+	}
+	now := time.Now()
+	ch := r.channels[name]
+	if ch == nil {
+		ch = &routedChannel{frame: rate / 50}
+		r.channels[name] = ch
+	}
+	if ch.source != sample.ID {
+		if ch.started {
+			// Carry on a frame after the last interpreter, or as long after
+			// as the channel was quiet, to stay in sync with video
+			gap := ch.frame
+			if quiet := uint32(now.Sub(ch.at) * time.Duration(rate) / time.Second); quiet > gap {
+				gap = quiet
+			}
+			ch.tsOffset = ch.last + gap - sample.Timestamp
+			ch.seqOffset = ch.lastSeq + 1 - sample.SequenceNumber
+		}
+		ch.source = sample.ID
+	}
+
+	out := *sample
+	out.ID = name
+	out.Timestamp += ch.tsOffset
+	out.SequenceNumber += ch.seqOffset
+	// Frames are at most 120ms, longer steps are silence left out
+	if d := out.Timestamp - ch.last; ch.started && int32(d) > 0 && d <= rate/8 {
+		ch.frame = d
+	}
+	ch.started, ch.last, ch.lastSeq, ch.at = true, out.Timestamp, out.SequenceNumber, now
+	return &out
+}
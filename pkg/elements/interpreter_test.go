@@ -0,0 +1,44 @@
+package elements
+
+import (
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/interpret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreterRouter(t *testing.T) {
+	routes := interpret.NewRoutes()
+	routes.Set("fr", interpret.Route{Source: "anna-mic", Language: "fr"})
+	out := &sampleRecorder{}
+	r := NewInterpreterRouter(InterpreterRouterConfig{Routes: routes})
+	r.Attach(out)
+
+	write := func(id string, ts uint32, sn uint16) {
+		assert.NoError(t, r.Write(&avp.Sample{ID: id, Type: avp.TypeOpus, Timestamp: ts, SequenceNumber: sn}))
+	}
+	write("anna-mic", 1000, 10)
+	write("anna-mic", 1960, 11)
+	write("ben-mic", 50000, 500) // not routed
+	assert.NoError(t, r.Write(&avp.Sample{ID: "cam", Type: avp.TypeVP8, Timestamp: 9}))
+
+	// Hand over to the booth partner, whose track runs on its own clock
+	routes.Set("fr", interpret.Route{Source: "ben-mic", Language: "fr"})
+	write("anna-mic", 2920, 12)
+	write("ben-mic", 50960, 501)
+	write("ben-mic", 51920, 502)
+
+	if assert.Len(t, out.samples, 5) {
+		assert.Equal(t, "cam", out.samples[2].ID)
+		var ts []uint32
+		var sn []uint16
+		for _, s := range append(out.samples[:2:2], out.samples[3:]...) {
+			assert.Equal(t, "fr", s.ID)
+			ts = append(ts, s.Timestamp)
+			sn = append(sn, s.SequenceNumber)
+		}
+		assert.Equal(t, []uint32{1000, 1960, 2920, 3880}, ts)
+		assert.Equal(t, []uint16{10, 11, 12, 13}, sn)
+	}
+}
@@ -0,0 +1,132 @@
+package elements
+
+import (
+	"encoding/binary"
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// IVF FourCCs of the codecs of a stream
+var ivfCodecs = map[int]string{
+	avp.TypeVP8: "VP80",
+	avp.TypeVP9: "VP90",
+	avp.TypeAV1: "AV01",
+}
+
+// IvfSaverConfig configures IvfSaver.
+// Recording: Optional state machine moved along as the recording progresses.
+type IvfSaverConfig struct {
+	Recording *recording.Recording
+}
+
+// IvfSaver instance
+type IvfSaver struct {
+	sync.Mutex
+	cfg          IvfSaverConfig
+	sampleWriter *SampleWriter
+	typ          int // of the stream, once started
+	clock        trackClock
+	rate         uint32
+	closed       bool
+}
+
+// NewIvfSaver instance. IvfSaver writes VP8, VP9 or AV1 frames as they
+// are to an IVF stream, to its children such as a FileWriter, for
+// debugging codecs or feeding offline encoders. The stream starts at a
+// keyframe of the codec of the first, whose size the header gives;
+// frames of other codecs are dropped. Timestamps are the frames' RTP
+// timestamps from the first. The frame count isn't known while
+// streaming so is left at zero, which decoders ignore.
+func NewIvfSaver(c IvfSaverConfig) *IvfSaver {
+	return &IvfSaver{cfg: c, sampleWriter: NewSampleWriter()}
+}
+
+func (s *IvfSaver) Write(sample *avp.Sample) error {
+	fourcc, ok := ivfCodecs[sample.Type]
+	if !ok {
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || s.cfg.Recording.State() == recording.StatePaused {
+		return nil
+	}
+
+	if s.typ == 0 {
+		info, err := frameSize(sample.Type, payload)
+		if err != nil {
+			if err != colorspace.ErrNotKeyframe {
+				log.Debugf("IVF saver: reading keyframe: %s", err)
+			}
+			return nil
+		}
+		s.typ = sample.Type
+		s.rate = videoClockRate
+		if sample.ClockRate > 0 {
+			s.rate = sample.ClockRate
+		}
+		if err := s.write(ivfHeader(fourcc, info.Width, info.Height, s.rate)); err != nil {
+			return err
+		}
+		setState(s.cfg.Recording, recording.StateRecording)
+		log.Infof("IVF saver has started with %s %dx%d", fourcc, info.Width, info.Height)
+	}
+	if sample.Type != s.typ {
+		return nil
+	}
+
+	// Timestamps are in the timebase of the header, the track's clock
+	// rate, so its ticks
+	s.clock.since(sample, s.rate)
+	header := make([]byte, 12, 12+len(payload))
+	binary.LittleEndian.PutUint32(header, uint32(len(payload)))
+	binary.LittleEndian.PutUint64(header[4:], uint64(s.clock.ticks))
+	return s.write(append(header, payload...))
+}
+
+// ivfHeader returns the file header of a stream timed at rate
+func ivfHeader(fourcc string, width, height int, rate uint32) []byte {
+	b := make([]byte, 32)
+	copy(b, "DKIF")
+	binary.LittleEndian.PutUint16(b[4:], 0)  // version
+	binary.LittleEndian.PutUint16(b[6:], 32) // header size
+	copy(b[8:], fourcc)
+	binary.LittleEndian.PutUint16(b[12:], uint16(width))
+	binary.LittleEndian.PutUint16(b[14:], uint16(height))
+	binary.LittleEndian.PutUint32(b[16:], rate)
+	binary.LittleEndian.PutUint32(b[20:], 1)
+	return b
+}
+
+func (s *IvfSaver) write(b []byte) error {
+	if _, err := s.sampleWriter.Write(b); err != nil {
+		s.cfg.Recording.Fail(err)
+		return err
+	}
+	return nil
+}
+
+// Attach attach a child element
+func (s *IvfSaver) Attach(e avp.Element) {
+	s.sampleWriter.Attach(e)
+}
+
+// Close closes the children
+func (s *IvfSaver) Close() {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	setState(s.cfg.Recording, recording.StateFinalizing)
+	s.sampleWriter.Close()
+}
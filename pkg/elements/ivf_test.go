@@ -0,0 +1,46 @@
+package elements
+
+import (
+	"encoding/binary"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIvfSaver(t *testing.T) {
+	saver := NewIvfSaver(IvfSaverConfig{})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// Waits for a keyframe, here VP9 profile 0 at 640x480
+	interframe := []byte{0x86, 0x00}
+	keyframe := []byte{0x82, 0x49, 0x83, 0x42, 0x40, 0x27, 0xf0, 0x1d, 0xf0}
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP9, Payload: interframe, Timestamp: 4294961296}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP9, Payload: keyframe, Timestamp: 4294964296}))
+	// Timestamps wrap around
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP9, Payload: interframe, Timestamp: 0}))
+	// Frames of another codec don't belong in the stream
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt, Timestamp: 3000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt, Timestamp: 3000}))
+	saver.Close()
+
+	b := writer.buf.Bytes()
+	assert.Len(t, b, 32+12+len(keyframe)+12+len(interframe))
+	assert.Equal(t, "DKIF", string(b[:4]))
+	assert.Equal(t, uint16(32), binary.LittleEndian.Uint16(b[6:]))
+	assert.Equal(t, "VP90", string(b[8:12]))
+	assert.Equal(t, uint16(640), binary.LittleEndian.Uint16(b[12:]))
+	assert.Equal(t, uint16(480), binary.LittleEndian.Uint16(b[14:]))
+	assert.Equal(t, uint32(90000), binary.LittleEndian.Uint32(b[16:]))
+	assert.Equal(t, uint32(1), binary.LittleEndian.Uint32(b[20:]))
+
+	frame := b[32:]
+	assert.Equal(t, uint32(len(keyframe)), binary.LittleEndian.Uint32(frame))
+	assert.Equal(t, uint64(0), binary.LittleEndian.Uint64(frame[4:]))
+	assert.Equal(t, keyframe, frame[12:12+len(keyframe)])
+	frame = frame[12+len(keyframe):]
+	assert.Equal(t, uint32(len(interframe)), binary.LittleEndian.Uint32(frame))
+	assert.Equal(t, uint64(3000), binary.LittleEndian.Uint64(frame[4:]))
+	assert.Equal(t, interframe, frame[12:])
+}
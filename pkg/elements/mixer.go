@@ -0,0 +1,383 @@
+package elements
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+	log "github.com/pion/ion-log"
+)
+
+// ErrNoOpusEncoder is returned by NewAudioMixer when no encoder is given
+// and libopus isn't built in
+var ErrNoOpusEncoder = errors.New("no Opus encoder, build with libopus or pass one")
+
+// OpusPCMEncoder encodes interleaved 16 bit PCM at 48kHz to Opus packets,
+// like opus.Encoder. Encode takes one frame at a time.
+type OpusPCMEncoder interface {
+	Encode(pcm []int16) ([]byte, error)
+	Close()
+}
+
+// newOpusEncoder creates the default encoder, set when libopus is built in
+var newOpusEncoder func(channels, bitrate int) (OpusPCMEncoder, error)
+
+// AudioMixerConfig configures an AudioMixer.
+// ID: Of the mixed track's samples, defaults to "mix".
+// Channels: 1 or 2, defaults to 1.
+// Frame: Duration of the mixed frames, 10, 20, 40 or 60ms, defaults to
+// 20ms.
+// Bitrate: Of the mix, 0 leaves the encoder to choose.
+// Gains: Optional gain of sources by track ID, 1 for those missing.
+// SetGain changes them while mixing.
+// Jitter: Audio held of each source before it is mixed, absorbing network
+// jitter, defaults to 60ms. A source running out waits for as much again.
+// Decoder: Optional creator of the decoder of each source's Opus. By
+// default libopus, when built with the libopus tag.
+// Encoder: Optional encoder of the mix, closed with the mixer. By default
+// libopus, when built with the libopus tag.
+type AudioMixerConfig struct {
+	ID       string
+	Channels int
+	Frame    time.Duration
+	Bitrate  int
+	Gains    map[string]float64
+	Jitter   time.Duration
+	Decoder  func(channels int) (OpusPCMDecoder, error)
+	Encoder  OpusPCMEncoder
+}
+
+// AudioMixer instance
+type AudioMixer struct {
+	Node
+	mu      sync.Mutex
+	cfg     AudioMixerConfig
+	gains   map[string]float64
+	sources []*mixerSource // in the order they joined
+	limit   float64        // gain of the limiter
+	frames  int64          // mixed
+	started sync.Once
+	stop    chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+// NewAudioMixer instance. AudioMixer decodes the Opus audio of a session's
+// participants, mixes it with each source's gain and encodes the mix as a
+// single Opus track for its children, e.g. a WebmSaver or an RTMP
+// restream. Mixes that would clip are turned down until they don't. Run
+// it as a room of the session so each participant's audio joins as it
+// arrives.
+func NewAudioMixer(c AudioMixerConfig) (*AudioMixer, error) {
+	if c.ID == "" {
+		c.ID = "mix"
+	}
+	if c.Channels != 2 {
+		c.Channels = 1
+	}
+	switch c.Frame {
+	case 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 60 * time.Millisecond:
+	default:
+		c.Frame = 20 * time.Millisecond
+	}
+	if c.Jitter <= 0 {
+		c.Jitter = 60 * time.Millisecond
+	}
+	if c.Decoder == nil {
+		if newOpusDecoder == nil {
+			return nil, ErrNoOpusDecoder
+		}
+		c.Decoder = newOpusDecoder
+	}
+	if c.Encoder == nil {
+		if newOpusEncoder == nil {
+			return nil, ErrNoOpusEncoder
+		}
+		enc, err := newOpusEncoder(c.Channels, c.Bitrate)
+		if err != nil {
+			return nil, err
+		}
+		c.Encoder = enc
+	}
+	gains := make(map[string]float64, len(c.Gains))
+	for tid, g := range c.Gains {
+		gains[tid] = g
+	}
+	return &AudioMixer{
+		cfg:   c,
+		gains: gains,
+		limit: 1,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}, nil
+}
+
+// SetGain sets the gain of a source, 0 muting it
+func (m *AudioMixer) SetGain(tid string, gain float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gains[tid] = gain
+}
+
+// Join returns the element taking the audio of a source. Other samples,
+// such as video, are ignored. Closing it removes the source from the mix,
+// as when the participant leaves.
+func (m *AudioMixer) Join(tid string) avp.Element {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &mixerSource{m: m, id: tid}
+	if !m.closed {
+		m.sources = append(m.sources, s)
+	}
+	return s
+}
+
+// Write samples of sources by their ID, for mixes fed through a single
+// element rather than Join
+func (m *AudioMixer) Write(sample *avp.Sample) error {
+	m.mu.Lock()
+	var s *mixerSource
+	for _, ms := range m.sources {
+		if ms.id == sample.ID {
+			s = ms
+		}
+	}
+	m.mu.Unlock()
+	if s == nil {
+		s = m.Join(sample.ID).(*mixerSource)
+	}
+	return s.Write(sample)
+}
+
+// Close stops mixing and closes the encoder and children
+func (m *AudioMixer) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	sources := m.sources
+	m.sources = nil
+	m.mu.Unlock()
+
+	close(m.stop)
+	m.started.Do(func() { close(m.done) })
+	<-m.done
+	for _, s := range sources {
+		s.closeDecoder()
+	}
+	m.cfg.Encoder.Close()
+	m.Node.Close()
+}
+
+// run mixes a frame every frame interval from the first audio a source
+// is sent
+func (m *AudioMixer) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.cfg.Frame)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.tick(now)
+		}
+	}
+}
+
+// tick mixes the frame of now and encodes it
+func (m *AudioMixer) tick(now time.Time) {
+	pcm, n := m.mix()
+	payload, err := m.cfg.Encoder.Encode(pcm)
+	if err != nil {
+		log.Errorf("audio mixer: encoding: %s", err)
+		return
+	}
+	err = m.Node.Write(&avp.Sample{
+		ID:             m.cfg.ID,
+		Type:           avp.TypeOpus,
+		Timestamp:      uint32(n * m.frameSamples()),
+		ClockRate:      audioClockRate,
+		SequenceNumber: uint16(n),
+		CaptureTime:    now,
+		Payload:        payload,
+	})
+	if err != nil {
+		log.Errorf("audio mixer: %s", err)
+	}
+}
+
+// frameSamples is the samples per channel of a frame
+func (m *AudioMixer) frameSamples() int64 {
+	return int64(m.cfg.Frame * opus.SampleRate / time.Second)
+}
+
+// mix takes a frame of the sources playing and adds them up, returning
+// the frame and its number
+func (m *AudioMixer) mix() ([]int16, int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	size := int(m.frameSamples()) * m.cfg.Channels
+	jitter := int(m.cfg.Jitter*opus.SampleRate/time.Second) * m.cfg.Channels
+	sum := make([]float64, size)
+	for _, s := range m.sources {
+		if !s.playing {
+			if len(s.queue) < jitter || len(s.queue) == 0 {
+				continue
+			}
+			s.playing = true
+		}
+		gain, ok := m.gains[s.id]
+		if !ok {
+			gain = 1
+		}
+		n := len(s.queue)
+		if n > size {
+			n = size
+		}
+		for i, v := range s.queue[:n] {
+			sum[i] += float64(v) * gain
+		}
+		s.queue = s.queue[n:]
+		if len(s.queue) == 0 {
+			// Ran out, wait for the jitter buffer to fill again
+			s.playing = false
+		}
+	}
+
+	peak := 0.0
+	for _, v := range sum {
+		if a := math.Abs(v); a > peak {
+			peak = a
+		}
+	}
+	m.limit = limiterGain(m.limit, peak)
+	out := make([]int16, size)
+	for i, v := range sum {
+		out[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v*m.limit))))
+	}
+	n := m.frames
+	m.frames++
+	return out, n
+}
+
+// limiterRelease is how much the limiter's gain recovers a frame
+const limiterRelease = 1.05
+
+// limiterGain returns the gain after gain keeping a frame of peak within
+// 16 bits. It drops at once to stop clipping, and recovers gradually so
+// quiet audio after a loud burst doesn't jump in level.
+func limiterGain(gain, peak float64) float64 {
+	max := 1.0
+	if peak > math.MaxInt16 {
+		max = math.MaxInt16 / peak
+	}
+	if gain*limiterRelease < max {
+		return gain * limiterRelease
+	}
+	return max
+}
+
+// mixerSource is a source of an AudioMixer
+type mixerSource struct {
+	m       *AudioMixer
+	id      string
+	decoder OpusPCMDecoder
+	clock   trackClock
+	pcm     []int16
+	next    int64 // sample per channel expected next
+	// queue is decoded audio waiting to be mixed, shared with mix
+	queue   []int16
+	playing bool
+}
+
+func (s *mixerSource) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus {
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return nil
+	}
+	m := s.m
+	channels := m.cfg.Channels
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	if s.decoder == nil {
+		dec, err := m.cfg.Decoder(channels)
+		if err != nil {
+			m.mu.Unlock()
+			return err
+		}
+		s.decoder = dec
+		s.pcm = make([]int16, opus.MaxFrame*channels)
+	}
+	dec := s.decoder
+	m.mu.Unlock()
+
+	at := int64(s.clock.since(sample, audioClockRate) * opus.SampleRate / time.Second)
+	if at < s.next {
+		// Late, its place has been filled
+		return nil
+	}
+	n, err := dec.Decode(payload, s.pcm)
+	if err != nil {
+		log.Warnf("audio mixer: decoding %s: %s", s.id, err)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if gap := at - s.next; gap > 0 && s.next > 0 {
+		// Lost packets or silence suppression, filled with silence to
+		// stay in time, at most a jitter buffer of it
+		if max := int64(m.cfg.Jitter * opus.SampleRate / time.Second); gap > max {
+			gap = max
+		}
+		s.queue = append(s.queue, make([]int16, gap*int64(channels))...)
+	}
+	s.queue = append(s.queue, s.pcm[:n*channels]...)
+	s.next = at + int64(n)
+	// Sources sending faster than the mix is played are held to a few
+	// jitter buffers behind
+	if max := 4 * int(m.cfg.Jitter*opus.SampleRate/time.Second) * channels; len(s.queue) > max {
+		s.queue = s.queue[len(s.queue)-max:]
+	}
+	m.started.Do(func() { go m.run() })
+	return nil
+}
+
+func (s *mixerSource) Attach(e avp.Element) {}
+
+// Close removes the source from the mix
+func (s *mixerSource) Close() {
+	m := s.m
+	m.mu.Lock()
+	for i, ms := range m.sources {
+		if ms == s {
+			m.sources = append(m.sources[:i:i], m.sources[i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+	s.closeDecoder()
+}
+
+func (s *mixerSource) closeDecoder() {
+	s.m.mu.Lock()
+	d := s.decoder
+	s.decoder = nil
+	s.m.mu.Unlock()
+	if d != nil {
+		d.Close()
+	}
+}
@@ -0,0 +1,129 @@
+package elements
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// levelDecoder decodes packets to 20ms at 100 times their first byte
+type levelDecoder struct{}
+
+func (levelDecoder) Decode(packet []byte, pcm []int16) (int, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty")
+	}
+	for i := 0; i < 960; i++ {
+		pcm[i] = 100 * int16(int8(packet[0]))
+	}
+	return 960, nil
+}
+
+func (levelDecoder) Close() {}
+
+// pcmEncoder keeps the frames it's given
+type pcmEncoder struct {
+	frames [][]int16
+	closed bool
+}
+
+func (e *pcmEncoder) Encode(pcm []int16) ([]byte, error) {
+	e.frames = append(e.frames, pcm)
+	return []byte{byte(len(e.frames))}, nil
+}
+
+func (e *pcmEncoder) Close() {
+	e.closed = true
+}
+
+func TestAudioMixer(t *testing.T) {
+	if newOpusEncoder == nil {
+		_, err := NewAudioMixer(AudioMixerConfig{Decoder: func(int) (OpusPCMDecoder, error) { return levelDecoder{}, nil }})
+		assert.Equal(t, ErrNoOpusEncoder, err)
+	}
+
+	enc := &pcmEncoder{}
+	m, err := NewAudioMixer(AudioMixerConfig{
+		Gains:   map[string]float64{"bob": 0.5},
+		Jitter:  40 * time.Millisecond,
+		Decoder: func(int) (OpusPCMDecoder, error) { return levelDecoder{}, nil },
+		Encoder: enc,
+	})
+	assert.NoError(t, err)
+	// Mixed by hand rather than on a ticker
+	m.started.Do(func() { close(m.done) })
+	out := &sampleRecorder{}
+	m.Attach(out)
+
+	opus := func(e avp.Element, id string, ts uint32, level int8) {
+		assert.NoError(t, e.Write(&avp.Sample{ID: id, Type: avp.TypeOpus, Timestamp: ts, Payload: []byte{byte(level)}}))
+	}
+	alice := m.Join("alice")
+	opus(alice, "", 1000, 10)
+	opus(alice, "", 1960, 10)
+	assert.NoError(t, alice.Write(&avp.Sample{Type: avp.TypeVP8, Payload: []byte{1}}))
+	// Bob's through Write, at half gain
+	opus(m, "bob", 0, 40)
+
+	// Bob is still filling his jitter buffer
+	frame, n := m.mix()
+	assert.Equal(t, int64(0), n)
+	assert.Len(t, frame, 960)
+	assert.Equal(t, int16(1000), frame[0])
+	opus(m, "bob", 960, 40)
+	frame, _ = m.mix()
+	assert.Equal(t, int16(1000+2000), frame[959])
+	// Alice's third packet is lost, filled with silence
+	opus(alice, "", 3880, 10)
+	frame, _ = m.mix()
+	assert.Equal(t, int16(2000), frame[0])
+	frame, _ = m.mix()
+	assert.Equal(t, int16(1000), frame[0])
+
+	// Loud mixes are turned down rather than clip, then recover
+	m.SetGain("bob", 4)
+	opus(m, "bob", 1920, 100)
+	opus(m, "bob", 2880, 100)
+	opus(alice, "", 4840, 10)
+	opus(alice, "", 5800, 10)
+	for i := 0; i < 2; i++ {
+		frame, _ = m.mix()
+		assert.Equal(t, int16(math.MaxInt16), frame[0])
+		assert.InDelta(t, float64(math.MaxInt16)/41000, m.limit, 1e-9)
+	}
+	opus(alice, "", 6760, 10)
+	opus(alice, "", 7720, 10)
+	frame, _ = m.mix()
+	assert.Less(t, m.limit, 1.0)
+	assert.Equal(t, int16(math.Round(1000*m.limit)), frame[0])
+	for i := 0; i < 10; i++ {
+		m.mix()
+	}
+	assert.Equal(t, 1.0, m.limit)
+
+	m.tick(time.Now())
+	if assert.Len(t, out.samples, 1) {
+		s := out.samples[0]
+		assert.Equal(t, "mix", s.ID)
+		assert.Equal(t, avp.TypeOpus, s.Type)
+		assert.Equal(t, uint32(17*960), s.Timestamp)
+		assert.Equal(t, uint16(17), s.SequenceNumber)
+		assert.Equal(t, []byte{1}, s.Payload)
+	}
+
+	alice.Close()
+	m.Close()
+	assert.True(t, enc.closed)
+	opus(alice, "", 8680, 10)
+}
+
+func TestLimiterGain(t *testing.T) {
+	assert.Equal(t, 1.0, limiterGain(1, 1000))
+	assert.Equal(t, 0.5, limiterGain(1, 2*math.MaxInt16))
+	assert.InDelta(t, 0.525, limiterGain(0.5, 1000), 1e-9)
+	assert.InDelta(t, 0.6, limiterGain(0.8, math.MaxInt16/0.6), 1e-9)
+}
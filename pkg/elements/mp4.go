@@ -0,0 +1,330 @@
+package elements
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// Mp4Saver saves H.264 video and Opus audio as fragmented MP4, for
+// players that can't play WebM. The file is written to its children
+// through a SampleWriter like WebmSaver's: an initialization segment
+// followed by a fragment about every FragmentDuration, so it plays while
+// being written and is intact up to the last fragment if interrupted.
+type Mp4Saver struct {
+	sync.Mutex
+	closed       bool
+	started      bool
+	seq          uint32
+	tracks       []mp4Track
+	audio, video *mp4TrackState
+	sampleWriter *SampleWriter
+	meta         *sidecar
+	cfg          Mp4SaverConfig
+	// segment, if set, gets the initialization segment and each fragment
+	// with the time of its media and how much it holds, in place of the
+	// children, e.g. for HLS
+	segment func(data []byte, start, d time.Duration) error
+}
+
+// Mp4SaverConfig configures Mp4Saver.
+// Audio: Record the audio track, Opus.
+// Video: Record the video track, H.264.
+// Recording: Optional state machine moved along as the recording progresses.
+// FragmentDuration: How much media each fragment holds, defaults to 1s.
+// Fragments start at a video keyframe, so may be longer.
+// Sidecar: Optional element sent the Metadata of the recording as JSON on
+// closing.
+type Mp4SaverConfig struct {
+	Audio            bool
+	Video            bool
+	Recording        *recording.Recording
+	FragmentDuration time.Duration
+	Sidecar          avp.Element
+}
+
+// mp4TrackState collects the samples of a track for the next fragment
+type mp4TrackState struct {
+	idx     int
+	clock   trackClock
+	rate    uint32
+	samples []mp4Sample
+	// last is held until the next sample gives its duration
+	last *mp4Sample
+}
+
+// add appends a sample, completing the one before
+func (t *mp4TrackState) add(s mp4Sample) {
+	t.complete(s.time)
+	t.last = &s
+}
+
+// complete adds the last sample to the fragment, lasting until at
+func (t *mp4TrackState) complete(at int64) {
+	if t.last == nil {
+		return
+	}
+	if d := at - t.last.time; d > 0 {
+		t.last.duration = uint32(d)
+	}
+	t.samples = append(t.samples, *t.last)
+	t.last = nil
+}
+
+// flush completes the last sample with the duration it has, that of the
+// one before, or d
+func (t *mp4TrackState) flush(d time.Duration) {
+	if t.last == nil {
+		return
+	}
+	if t.last.duration == 0 {
+		t.last.duration = uint32(int64(d) * int64(t.rate) / int64(time.Second))
+		if n := len(t.samples); n > 0 {
+			t.last.duration = t.samples[n-1].duration
+		}
+	}
+	t.complete(t.last.time)
+}
+
+// until returns the time from the first sample of the fragment to at
+func (t *mp4TrackState) until(at int64) time.Duration {
+	first := t.last
+	if len(t.samples) > 0 {
+		first = &t.samples[0]
+	}
+	if first == nil {
+		return 0
+	}
+	return time.Duration((at - first.time) * int64(time.Second) / int64(t.rate))
+}
+
+// pending returns how much media the fragment holds so far
+func (t *mp4TrackState) pending() time.Duration {
+	if t == nil || t.last == nil {
+		return 0
+	}
+	return t.until(t.last.time + int64(t.last.duration))
+}
+
+// NewMp4Saver Initialize a new MP4 saver.
+// Pass nil to enable audio and video tracks, the normal case.
+func NewMp4Saver(cfg *Mp4SaverConfig) *Mp4Saver {
+	if cfg == nil {
+		cfg = &Mp4SaverConfig{Audio: true, Video: true}
+	}
+	if cfg.FragmentDuration <= 0 {
+		cfg.FragmentDuration = time.Second
+	}
+	return &Mp4Saver{
+		sampleWriter: NewSampleWriter(),
+		meta:         newSidecar(cfg.Sidecar, cfg.Recording),
+		cfg:          *cfg,
+	}
+}
+
+// Write sample to the MP4 saver
+func (s *Mp4Saver) Write(sample *avp.Sample) error {
+	if s.cfg.Recording.State() == recording.StatePaused {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return nil
+	}
+	switch sample.Type {
+	case avp.TypeH264:
+		s.pushH264(sample)
+	case avp.TypeOpus:
+		s.pushOpus(sample)
+	}
+	return nil
+}
+
+// Attach attach a child element
+func (s *Mp4Saver) Attach(e avp.Element) {
+	s.sampleWriter.Attach(e)
+}
+
+// Close writes the last fragment and closes the children
+func (s *Mp4Saver) Close() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	setState(s.cfg.Recording, recording.StateFinalizing)
+
+	if s.started {
+		if s.audio != nil {
+			s.audio.flush(20 * time.Millisecond)
+		}
+		if s.video != nil {
+			s.video.flush(time.Second / 30)
+		}
+		s.fragment()
+	}
+	s.sampleWriter.Close()
+	s.meta.close()
+}
+
+func (s *Mp4Saver) pushOpus(sample *avp.Sample) {
+	if !s.cfg.Audio {
+		return
+	}
+	if !s.started && !s.cfg.Video {
+		s.start(0, 0, nil, nil)
+	}
+	if s.audio == nil {
+		return
+	}
+	if s.cfg.Video && s.cfg.Recording.State() == recording.StateWaitingForKeyframe {
+		// Keep audio in step with video after resuming
+		s.meta.dropped(sample)
+		return
+	}
+	payload := sample.Payload.([]byte)
+	next := mp4Sample{
+		time: s.ticks(s.audio, sample, audioClockRate),
+		key:  true,
+		data: append([]byte(nil), payload...),
+	}
+	if !s.cfg.Video {
+		setState(s.cfg.Recording, recording.StateRecording)
+		if s.audio.until(next.time) >= s.cfg.FragmentDuration {
+			s.audio.complete(next.time)
+			s.fragment()
+		}
+	}
+	// Until the next packet, it lasts as long as its frames
+	if d, err := opus.PacketDuration(payload); err == nil {
+		next.duration = uint32(int64(d) * audioClockRate / int64(time.Second))
+	}
+	s.audio.add(next)
+	s.meta.recorded(sample)
+}
+
+func (s *Mp4Saver) pushH264(sample *avp.Sample) {
+	if !s.cfg.Video {
+		return
+	}
+	f := readAVC(sample.Payload.([]byte))
+	key, data := f.key, f.data
+
+	if !s.started && f.configurable() {
+		info, err := colorspace.ParseH264(sample.Payload.([]byte))
+		if err != nil {
+			log.Errorf("MP4 saver: reading SPS: %s", err)
+			s.meta.dropped(sample)
+			return
+		}
+		s.start(info.Width, info.Height, f.sps, f.pps)
+	}
+	if !s.started || (!key && s.cfg.Recording.State() == recording.StateWaitingForKeyframe) {
+		setState(s.cfg.Recording, recording.StateWaitingForKeyframe)
+		s.meta.dropped(sample)
+		return
+	}
+	if len(data) == 0 {
+		return
+	}
+
+	t := s.ticks(s.video, sample, videoClockRate)
+	if key && (s.video.until(t) >= s.cfg.FragmentDuration || s.audio.pending() >= 2*s.cfg.FragmentDuration) {
+		// The fragment ends with the frame before the keyframe
+		s.video.complete(t)
+		s.fragment()
+	}
+	s.video.add(mp4Sample{time: t, key: key, data: data})
+	s.meta.recorded(sample)
+	setState(s.cfg.Recording, recording.StateRecording)
+}
+
+// ticks returns the decode time of a sample in the track's timescale
+func (s *Mp4Saver) ticks(t *mp4TrackState, sample *avp.Sample, rate uint32) int64 {
+	d := t.clock.since(sample, rate)
+	return (int64(d)*int64(t.rate) + int64(time.Second)/2) / int64(time.Second)
+}
+
+// start writes the initialization segment
+func (s *Mp4Saver) start(width, height int, sps, pps []byte) {
+	s.started = true
+	if s.cfg.Audio {
+		s.audio = &mp4TrackState{idx: len(s.tracks), rate: audioClockRate}
+		s.tracks = append(s.tracks, mp4Track{
+			id:        uint32(len(s.tracks) + 1),
+			timescale: audioClockRate,
+			entry:     opusEntry(2),
+			codec:     "opus",
+		})
+	}
+	if s.cfg.Video {
+		s.video = &mp4TrackState{idx: len(s.tracks), rate: videoClockRate}
+		s.tracks = append(s.tracks, mp4Track{
+			id:        uint32(len(s.tracks) + 1),
+			video:     true,
+			timescale: videoClockRate,
+			width:     width,
+			height:    height,
+			entry:     avc1Entry(width, height, sps, pps),
+			codec:     fmt.Sprintf("avc1.%02x%02x%02x", sps[1], sps[2], sps[3]),
+		})
+	}
+	if err := s.emit(mp4Init(s.tracks), 0, 0); err != nil {
+		log.Errorf("MP4 saver init err: %s", err)
+		s.cfg.Recording.Fail(err)
+		return
+	}
+	if s.cfg.Video {
+		log.Infof("MP4 saver has started with video width=%d, height=%d", width, height)
+	} else {
+		log.Infof("MP4 saver has started with audio only")
+	}
+}
+
+// fragment writes the samples collected as a movie fragment
+func (s *Mp4Saver) fragment() {
+	samples := make([][]mp4Sample, len(s.tracks))
+	empty := true
+	var start, d time.Duration
+	for _, t := range []*mp4TrackState{s.audio, s.video} {
+		if t != nil {
+			samples[t.idx] = t.samples
+			if n := len(t.samples); n > 0 {
+				if empty {
+					start = time.Duration(t.samples[0].time * int64(time.Second) / int64(t.rate))
+				}
+				last := t.samples[n-1]
+				if td := t.until(last.time + int64(last.duration)); td > d {
+					d = td
+				}
+			}
+			empty = empty && len(t.samples) == 0
+			t.samples = nil
+		}
+	}
+	if empty {
+		return
+	}
+	s.seq++
+	if err := s.emit(mp4Fragment(s.seq, s.tracks, samples), start, d); err != nil {
+		log.Errorf("MP4 saver fragment err: %s", err)
+	}
+}
+
+// emit writes a segment of the file holding d of media from start
+func (s *Mp4Saver) emit(data []byte, start, d time.Duration) error {
+	if s.segment != nil {
+		return s.segment(data, start, d)
+	}
+	_, err := s.sampleWriter.Write(data)
+	return err
+}
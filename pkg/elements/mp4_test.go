@@ -0,0 +1,139 @@
+package elements
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+// H.264 access units of a 640x480 stream, a keyframe with its parameter
+// sets and a predicted frame
+var (
+	h264Keyframe = []byte{
+		0, 0, 0, 1, 0x09, 0xf0,
+		0, 0, 0, 1, 0x67, 0x42, 0x00, 0x1e, 0x95, 0xa8, 0x28, 0x0f, 0x64,
+		0, 0, 0, 1, 0x68, 0xce, 0x3c, 0x80,
+		0, 0, 0, 1, 0x65, 0x88, 0x84, 0x00,
+	}
+	h264Frame = []byte{0, 0, 0, 1, 0x41, 0x9a, 0x02}
+)
+
+// mp4Children returns the boxes in b by type, in order
+func mp4Children(t *testing.T, b []byte) (types []string, boxes [][]byte) {
+	for len(b) > 0 {
+		if !assert.True(t, len(b) >= 8) {
+			return
+		}
+		size := int(binary.BigEndian.Uint32(b))
+		if !assert.True(t, size >= 8 && size <= len(b), "box size") {
+			return
+		}
+		types = append(types, string(b[4:8]))
+		boxes = append(boxes, b[8:size])
+		b = b[size:]
+	}
+	return
+}
+
+// mp4Child returns the payload of the first box of a type in b
+func mp4Child(t *testing.T, b []byte, typ string) []byte {
+	types, boxes := mp4Children(t, b)
+	for i, ty := range types {
+		if ty == typ {
+			return boxes[i]
+		}
+	}
+	t.Fatalf("no %s box", typ)
+	return nil
+}
+
+func TestMp4Saver(t *testing.T) {
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "rec.mp4")
+	saver := NewMp4Saver(&Mp4SaverConfig{Audio: true, Video: true, Recording: rec})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// Nothing is written until a keyframe
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Payload: h264Frame}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: opusSilence}))
+	assert.Equal(t, 0, writer.buf.Len())
+	assert.Equal(t, recording.StateWaitingForKeyframe, rec.State())
+
+	// 2s of 30fps video with a keyframe a second, and 20ms audio packets
+	audio := 0
+	for i := 0; i < 60; i++ {
+		frame := h264Frame
+		if i%30 == 0 {
+			frame = h264Keyframe
+		}
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Timestamp: uint32(i * 3000), Payload: frame}))
+		for ; audio*3 < (i+1)*5; audio++ {
+			assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(audio * 960), Payload: opusSilence}))
+		}
+	}
+	assert.Equal(t, recording.StateRecording, rec.State())
+	saver.Close()
+
+	types, boxes := mp4Children(t, writer.buf.Bytes())
+	assert.Equal(t, []string{"ftyp", "moov", "moof", "mdat", "moof", "mdat"}, types)
+
+	// The video track is described by its SPS and PPS
+	moov := boxes[1]
+	moovTypes, traks := mp4Children(t, moov)
+	assert.Equal(t, []string{"mvhd", "trak", "trak", "mvex"}, moovTypes)
+	stsd := mp4Child(t, mp4Child(t, mp4Child(t, mp4Child(t, traks[2], "mdia"), "minf"), "stbl"), "stsd")
+	avc1 := mp4Child(t, stsd[8:], "avc1")
+	assert.Equal(t, []byte{0x02, 0x80, 0x01, 0xe0}, avc1[24:28]) // 640x480
+	avcC := mp4Child(t, avc1[78:], "avcC")
+	assert.Equal(t, []byte{1, 0x42, 0x00, 0x1e, 0xff, 0xe1}, avcC[:6])
+
+	// Each fragment holds a second of each track, video starting at its keyframe
+	for f, moof := range [][]byte{boxes[2], boxes[4]} {
+		_, trafs := mp4Children(t, moof)
+		assert.Len(t, trafs, 3)
+		for i, samples := range []int{50, 30} {
+			tfdt := mp4Child(t, trafs[i+1], "tfdt")
+			trun := mp4Child(t, trafs[i+1], "trun")
+			count := int(binary.BigEndian.Uint32(trun[4:]))
+			if i == 0 {
+				// The last audio packet of a fragment waits for the next
+				assert.InDelta(t, samples, count, 1)
+				continue
+			}
+			assert.Equal(t, samples, count)
+			assert.Equal(t, uint64(f*90000), binary.BigEndian.Uint64(tfdt[4:]))
+			// duration, size and flags of the first sample
+			assert.Equal(t, uint32(3000), binary.BigEndian.Uint32(trun[12:]))
+			assert.Equal(t, uint32(mp4SyncSample), binary.BigEndian.Uint32(trun[20:]))
+			assert.Equal(t, uint32(mp4NonSyncSample), binary.BigEndian.Uint32(trun[32:]))
+		}
+	}
+
+	// Samples are length prefixed, without parameter sets
+	mdat := boxes[3]
+	assert.Equal(t, []byte{0, 0, 0, 4, 0x65, 0x88, 0x84, 0x00}, mdat[len(mdat)-29*7-8:len(mdat)-29*7])
+}
+
+func TestMp4Saver_AudioOnly(t *testing.T) {
+	saver := NewMp4Saver(&Mp4SaverConfig{Audio: true, FragmentDuration: 500 * time.Millisecond})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: opusSilence}))
+	}
+	saver.Close()
+
+	types, boxes := mp4Children(t, writer.buf.Bytes())
+	assert.Equal(t, []string{"ftyp", "moov", "moof", "mdat", "moof", "mdat"}, types)
+	dOps := mp4Child(t, mp4Child(t, mp4Child(t, mp4Child(t, mp4Child(t, mp4Child(t, mp4Child(t, boxes[1], "trak"), "mdia"), "minf"), "stbl"), "stsd")[8:], "Opus")[28:], "dOps")
+	assert.Equal(t, []byte{0, 2, 0x01, 0x38, 0, 0, 0xbb, 0x80}, dOps[:8])
+	for _, moof := range [][]byte{boxes[2], boxes[4]} {
+		trun := mp4Child(t, mp4Child(t, moof, "traf"), "trun")
+		assert.Equal(t, uint32(25), binary.BigEndian.Uint32(trun[4:]))
+		assert.Equal(t, uint32(960), binary.BigEndian.Uint32(trun[12:]))
+	}
+}
@@ -0,0 +1,211 @@
+package elements
+
+import "encoding/binary"
+
+// ISO BMFF (MP4) boxes are written as byte slices, each box built from
+// its fields and child boxes
+
+// mp4Box returns a box of type typ containing the concatenated fields
+func mp4Box(typ string, fields ...[]byte) []byte {
+	size := 8
+	for _, f := range fields {
+		size += len(f)
+	}
+	b := make([]byte, 8, size)
+	binary.BigEndian.PutUint32(b, uint32(size))
+	copy(b[4:], typ)
+	for _, f := range fields {
+		b = append(b, f...)
+	}
+	return b
+}
+
+// mp4FullBox returns a box with a version and flags
+func mp4FullBox(typ string, version uint8, flags uint32, fields ...[]byte) []byte {
+	return mp4Box(typ, append([][]byte{u32(uint32(version)<<24 | flags&0xffffff)}, fields...)...)
+}
+
+func u8(v uint8) []byte {
+	return []byte{v}
+}
+
+func u16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	return b
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func u64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func zeros(n int) []byte {
+	return make([]byte, n)
+}
+
+// mp4Matrix is the identity transformation of movie and track headers
+var mp4Matrix = []byte{
+	0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0x40, 0, 0, 0,
+}
+
+// mp4Track describes a track of a fragmented MP4 file
+type mp4Track struct {
+	id        uint32
+	video     bool
+	timescale uint32
+	width     int
+	height    int
+	// entry is the sample entry box describing the codec
+	entry []byte
+	// codec is the RFC 6381 codec string, for manifests
+	codec string
+}
+
+// mp4Init returns the initialization segment of a fragmented MP4 file
+// with tracks, its ftyp and moov boxes. Sample tables are empty, samples
+// are described by the fragments. Files of a single track are CMAF
+// tracks.
+func mp4Init(tracks []mp4Track) []byte {
+	brands := [][]byte{[]byte("iso5"), u32(0), []byte("iso5"), []byte("iso6"), []byte("mp41")}
+	if len(tracks) == 1 {
+		brands = append(brands, []byte("cmfc"))
+	}
+	ftyp := mp4Box("ftyp", brands...)
+
+	moov := [][]byte{mp4FullBox("mvhd", 0, 0,
+		u32(0), u32(0), // creation and modification time
+		u32(1000), u32(0), // timescale, duration
+		u32(0x00010000), u16(0x0100), zeros(10), // rate, volume, reserved
+		mp4Matrix, zeros(24),
+		u32(uint32(len(tracks)+1)), // next track id
+	)}
+	var trex [][]byte
+	for _, t := range tracks {
+		moov = append(moov, t.trak())
+		trex = append(trex, mp4FullBox("trex", 0, 0, u32(t.id), u32(1), u32(0), u32(0), u32(0)))
+	}
+	moov = append(moov, mp4Box("mvex", trex...))
+	return append(ftyp, mp4Box("moov", moov...)...)
+}
+
+func (t mp4Track) trak() []byte {
+	var volume uint16
+	handler, name := "vide", "VideoHandler"
+	header := mp4FullBox("vmhd", 0, 1, zeros(8))
+	if !t.video {
+		volume = 0x0100
+		handler, name = "soun", "SoundHandler"
+		header = mp4FullBox("smhd", 0, 0, zeros(4))
+	}
+	tkhd := mp4FullBox("tkhd", 0, 3, // enabled, in movie
+		u32(0), u32(0), u32(t.id), zeros(4), u32(0), // times, id, reserved, duration
+		zeros(8), u16(0), u16(0), u16(volume), zeros(2), // reserved, layer, group, volume, reserved
+		mp4Matrix, u32(uint32(t.width)<<16), u32(uint32(t.height)<<16),
+	)
+	mdhd := mp4FullBox("mdhd", 0, 0, u32(0), u32(0), u32(t.timescale), u32(0), u16(0x55c4), u16(0)) // und
+	hdlr := mp4FullBox("hdlr", 0, 0, u32(0), []byte(handler), zeros(12), []byte(name), u8(0))
+	dinf := mp4Box("dinf", mp4FullBox("dref", 0, 0, u32(1), mp4FullBox("url ", 0, 1)))
+	stbl := mp4Box("stbl",
+		mp4FullBox("stsd", 0, 0, u32(1), t.entry),
+		mp4FullBox("stts", 0, 0, u32(0)),
+		mp4FullBox("stsc", 0, 0, u32(0)),
+		mp4FullBox("stsz", 0, 0, u32(0), u32(0)),
+		mp4FullBox("stco", 0, 0, u32(0)),
+	)
+	return mp4Box("trak", tkhd, mp4Box("mdia", mdhd, hdlr, mp4Box("minf", header, dinf, stbl)))
+}
+
+// avc1Entry returns the sample entry of H.264 video with length prefixed
+// NAL units, configured by a sequence and picture parameter set
+func avc1Entry(width, height int, sps, pps []byte) []byte {
+	avcC := mp4Box("avcC", avcConfig(sps, pps))
+	compressor := zeros(32)
+	return mp4Box("avc1",
+		zeros(6), u16(1), // reserved, data reference index
+		zeros(16), u16(uint16(width)), u16(uint16(height)),
+		u32(0x00480000), u32(0x00480000), zeros(4), u16(1), // 72dpi, reserved, frame count
+		compressor, u16(0x0018), u16(0xffff), // depth, pre defined
+		avcC,
+	)
+}
+
+// opusEntry returns the sample entry of Opus audio, as in "Encapsulation
+// of Opus in ISO Base Media File Format"
+func opusEntry(channels int) []byte {
+	dOps := mp4Box("dOps",
+		u8(0), u8(uint8(channels)), u16(opusPreSkip), u32(48000), // version, channels, pre-skip, input rate
+		u16(0), u8(0), // output gain, channel mapping family
+	)
+	return mp4Box("Opus",
+		zeros(6), u16(1), // reserved, data reference index
+		zeros(8), u16(uint16(channels)), u16(16), zeros(4), u32(48000<<16),
+		dOps,
+	)
+}
+
+// opusPreSkip is the encoder delay of libopus in 48kHz samples
+const opusPreSkip = 312
+
+// mp4Sample is a sample of a fragment
+type mp4Sample struct {
+	time     int64 // decode time in the track's timescale
+	duration uint32
+	key      bool
+	data     []byte
+}
+
+// Sample flags of sync and non-sync samples in track runs
+const (
+	mp4SyncSample    = 0x02000000
+	mp4NonSyncSample = 0x01010000
+)
+
+// mp4Fragment returns a movie fragment, its moof and mdat boxes, holding
+// the samples of each track
+func mp4Fragment(seq uint32, tracks []mp4Track, samples [][]mp4Sample) []byte {
+	// Track runs point into the mdat following the moof, so the moof is
+	// built once to size it and again with the offsets
+	var moof []byte
+	for pass := 0; pass < 2; pass++ {
+		offset := len(moof) + 8
+		trafs := [][]byte{mp4FullBox("mfhd", 0, 0, u32(seq))}
+		for i, t := range tracks {
+			if len(samples[i]) == 0 {
+				continue
+			}
+			run := [][]byte{u32(uint32(len(samples[i]))), u32(uint32(offset))}
+			for _, s := range samples[i] {
+				flags := uint32(mp4NonSyncSample)
+				if s.key || !t.video {
+					flags = mp4SyncSample
+				}
+				run = append(run, u32(s.duration), u32(uint32(len(s.data))), u32(flags))
+				offset += len(s.data)
+			}
+			trafs = append(trafs, mp4Box("traf",
+				mp4FullBox("tfhd", 0, 0x020000, u32(t.id)), // default base is moof
+				mp4FullBox("tfdt", 1, 0, u64(uint64(samples[i][0].time))),
+				mp4FullBox("trun", 0, 0x000701, run...), // data offset, duration, size, flags
+			))
+		}
+		moof = mp4Box("moof", trafs...)
+	}
+
+	var data [][]byte
+	for i := range tracks {
+		for _, s := range samples[i] {
+			data = append(data, s.data)
+		}
+	}
+	return append(moof, mp4Box("mdat", data...)...)
+}
@@ -0,0 +1,129 @@
+package elements
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/ogg"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// oggVendor names the writer in the comment header
+const oggVendor = "ion-avp"
+
+// OggSaverConfig configures OggSaver.
+// Channels: Of the stream, 1 or 2, defaults to 2 as WebRTC negotiates Opus.
+// Recording: Optional state machine moved along as the recording progresses.
+// Sidecar: Optional element sent the Metadata of the recording as JSON on
+// closing.
+type OggSaverConfig struct {
+	Channels  int
+	Recording *recording.Recording
+	Sidecar   avp.Element
+}
+
+// OggSaver instance
+type OggSaver struct {
+	sync.Mutex
+	cfg          OggSaverConfig
+	sampleWriter *SampleWriter
+	meta         *sidecar
+	ogg          *ogg.Writer
+	clock        trackClock
+	granule      uint64 // at the end of the last packet
+	started      bool
+	closed       bool
+}
+
+// NewOggSaver instance. OggSaver writes Opus packets as they are to an Ogg
+// stream, a .opus file, to its children such as a FileWriter, for speech
+// and research pipelines that take the codec's bitstream without a WebM
+// or MP4 demuxer. Packets are written a page each, so the file is
+// readable as far as it got, positioned by their RTP timestamps from the
+// first, which leaves out silence not sent.
+func NewOggSaver(c OggSaverConfig) *OggSaver {
+	if c.Channels != 1 {
+		c.Channels = 2
+	}
+	s := &OggSaver{cfg: c, sampleWriter: NewSampleWriter(), meta: newSidecar(c.Sidecar, c.Recording)}
+	s.ogg = ogg.NewWriter(s.sampleWriter, rand.Uint32())
+	return s
+}
+
+func (s *OggSaver) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus {
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || s.cfg.Recording.State() == recording.StatePaused {
+		return nil
+	}
+
+	if !s.started {
+		if err := s.write(ogg.First, 0, ogg.OpusHead(s.cfg.Channels, 0)); err != nil {
+			return err
+		}
+		if err := s.write(0, 0, ogg.OpusTags(oggVendor)); err != nil {
+			return err
+		}
+		s.started = true
+		setState(s.cfg.Recording, recording.StateRecording)
+		log.Infof("Ogg saver has started with %d channels", s.cfg.Channels)
+	}
+
+	// Granule positions are 48kHz samples, Opus' RTP clock rate, and may
+	// not go back for reordered packets
+	s.clock.since(sample, audioClockRate)
+	end := uint64(s.clock.ticks)
+	if d, err := opus.PacketDuration(payload); err == nil {
+		end += uint64(int64(d) * audioClockRate / int64(time.Second))
+	}
+	if end > s.granule {
+		s.granule = end
+	}
+	if err := s.write(0, s.granule, payload); err != nil {
+		return err
+	}
+	s.meta.recorded(sample)
+	return nil
+}
+
+func (s *OggSaver) write(typ byte, granule uint64, packets ...[]byte) error {
+	if err := s.ogg.WritePage(typ, granule, packets...); err != nil {
+		s.cfg.Recording.Fail(err)
+		return err
+	}
+	return nil
+}
+
+// Attach attach a child element
+func (s *OggSaver) Attach(e avp.Element) {
+	s.sampleWriter.Attach(e)
+}
+
+// Close ends the stream and closes the children
+func (s *OggSaver) Close() {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	setState(s.cfg.Recording, recording.StateFinalizing)
+	if s.started {
+		if err := s.write(ogg.Last, s.granule); err != nil {
+			log.Errorf("Ogg saver: ending stream: %s", err)
+		}
+	}
+	s.sampleWriter.Close()
+	s.meta.close()
+}
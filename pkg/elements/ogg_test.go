@@ -0,0 +1,64 @@
+package elements
+
+import (
+	"encoding/binary"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+type oggPage struct {
+	typ     byte
+	granule uint64
+	data    []byte
+}
+
+// readOggPages splits an Ogg stream into its pages
+func readOggPages(b []byte) []oggPage {
+	var pages []oggPage
+	for len(b) >= 27 {
+		n := int(b[26])
+		size := 0
+		for _, l := range b[27 : 27+n] {
+			size += int(l)
+		}
+		pages = append(pages, oggPage{
+			typ:     b[5],
+			granule: binary.LittleEndian.Uint64(b[6:]),
+			data:    b[27+n : 27+n+size],
+		})
+		b = b[27+n+size:]
+	}
+	return pages
+}
+
+func TestOggSaver(t *testing.T) {
+	saver := NewOggSaver(OggSaverConfig{})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// rawOpusPkt is a 10ms frame, the next is sent after a second of silence
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt, Timestamp: 4294966816}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt, Timestamp: 3000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt, Timestamp: 48000 - 480}))
+	saver.Close()
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt, Timestamp: 48480}))
+
+	pages := readOggPages(writer.buf.Bytes())
+	if assert.Len(t, pages, 5) {
+		assert.Equal(t, byte(0x02), pages[0].typ)
+		assert.Equal(t, "OpusHead", string(pages[0].data[:8]))
+		assert.Equal(t, byte(2), pages[0].data[9])
+		assert.Equal(t, "OpusTags", string(pages[1].data[:8]))
+
+		assert.Equal(t, rawOpusPkt, pages[2].data)
+		assert.Equal(t, uint64(480), pages[2].granule)
+		assert.Equal(t, rawOpusPkt, pages[3].data)
+		assert.Equal(t, uint64(48480), pages[3].granule)
+
+		assert.Equal(t, byte(0x04), pages[4].typ)
+		assert.Empty(t, pages[4].data)
+		assert.Equal(t, uint64(48480), pages[4].granule)
+	}
+}
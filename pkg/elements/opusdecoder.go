@@ -0,0 +1,66 @@
+//go:build libopus
+// +build libopus
+
+package elements
+
+import (
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+)
+
+// OpusDecoder instance
+type OpusDecoder struct {
+	Node
+	channels int
+	dec      *opus.Decoder
+	pcm      []int16
+}
+
+// NewOpusDecoder instance. OpusDecoder takes as input Opus streams and
+// decodes them into TypePCM samples at 48kHz. The PCM of a sample is
+// reused for the next one.
+func NewOpusDecoder(channels int) *OpusDecoder {
+	return &OpusDecoder{
+		channels: channels,
+		pcm:      make([]int16, opus.MaxFrame*channels),
+	}
+}
+
+func (d *OpusDecoder) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus {
+		return nil
+	}
+
+	if d.dec == nil {
+		dec, err := opus.NewDecoder(opus.SampleRate, d.channels)
+		if err != nil {
+			return err
+		}
+		d.dec = dec
+	}
+
+	n, err := d.dec.Decode(sample.Payload.([]byte), d.pcm)
+	if err != nil {
+		return err
+	}
+	return d.Node.Write(&avp.Sample{
+		ID:             sample.ID,
+		Type:           TypePCM,
+		Timestamp:      sample.Timestamp,
+		ClockRate:      sample.ClockRate,
+		SequenceNumber: sample.SequenceNumber,
+		CaptureTime:    sample.CaptureTime,
+		Payload: &PCM{
+			Data:     d.pcm[:n*d.channels],
+			Channels: d.channels,
+			Rate:     opus.SampleRate,
+		},
+	})
+}
+
+func (d *OpusDecoder) Close() {
+	if d.dec != nil {
+		d.dec.Close()
+	}
+	d.Node.Close()
+}
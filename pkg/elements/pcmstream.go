@@ -0,0 +1,170 @@
+package elements
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+	log "github.com/pion/ion-log"
+)
+
+// ErrPCMRate is returned by NewPCMStream for rates other than 16kHz or 48kHz
+var ErrPCMRate = errors.New("PCM rate must be 16000 or 48000")
+
+// PCMChunk is a block of decoded audio delivered by PCMStream
+type PCMChunk struct {
+	PCM
+	// Time is from the first audio of the track
+	Time        time.Duration
+	CaptureTime time.Time
+	// Dropped is how many chunks before this one weren't taken in time
+	Dropped uint64
+}
+
+// PCMStreamConfig configures a PCMStream.
+// Rate: Of the output, 48000 as decoded or 16000 as speech models take,
+// defaults to 48000.
+// Channels: 1 or 2, defaults to 1.
+// Decoder: Optional decoder of the Opus audio, closed with the stream. By
+// default libopus, when built with the libopus tag.
+// Buffer: How many chunks are held for a consumer falling behind before
+// they are dropped, defaults to 50, a second of 20ms frames.
+type PCMStreamConfig struct {
+	Rate     int
+	Channels int
+	Decoder  OpusPCMDecoder
+	Buffer   int
+}
+
+// PCMStream instance
+type PCMStream struct {
+	// dropped is updated atomically, so it comes first to be 64-bit
+	// aligned on 32-bit platforms
+	dropped uint64
+	Leaf
+	sync.Mutex
+	cfg    PCMStreamConfig
+	chunks chan PCMChunk
+	clock  trackClock
+	pcm    []int16
+	closed bool
+}
+
+// NewPCMStream instance. PCMStream decodes Opus audio to 16 bit PCM and
+// delivers it a frame at a time on a channel, for consumers outside the
+// pipeline such as speech to text or analytics. A consumer that can't keep
+// up doesn't hold up the track: chunks are dropped while the buffer is
+// full and the next one counts them. Gaps in the audio aren't filled,
+// chunks are placed by their Time instead.
+func NewPCMStream(c PCMStreamConfig) (*PCMStream, error) {
+	if c.Rate == 0 {
+		c.Rate = opus.SampleRate
+	}
+	if c.Rate != opus.SampleRate && c.Rate != 16000 {
+		return nil, ErrPCMRate
+	}
+	if c.Channels != 2 {
+		c.Channels = 1
+	}
+	if c.Buffer <= 0 {
+		c.Buffer = 50
+	}
+	if c.Decoder == nil {
+		if newOpusDecoder == nil {
+			return nil, ErrNoOpusDecoder
+		}
+		dec, err := newOpusDecoder(c.Channels)
+		if err != nil {
+			return nil, err
+		}
+		c.Decoder = dec
+	}
+	return &PCMStream{
+		cfg:    c,
+		chunks: make(chan PCMChunk, c.Buffer),
+		pcm:    make([]int16, opus.MaxFrame*c.Channels),
+	}, nil
+}
+
+// Chunks returns the channel of decoded audio, closed when the stream is
+func (s *PCMStream) Chunks() <-chan PCMChunk {
+	return s.chunks
+}
+
+// Dropped returns how many chunks have been dropped in all
+func (s *PCMStream) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *PCMStream) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus {
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	at := s.clock.since(sample, audioClockRate)
+	n, err := s.cfg.Decoder.Decode(payload, s.pcm)
+	if err != nil {
+		log.Warnf("PCM stream: decoding: %s", err)
+		return nil
+	}
+	data := decimate(s.pcm[:n*s.cfg.Channels], s.cfg.Channels, opus.SampleRate/s.cfg.Rate)
+	chunk := PCMChunk{
+		PCM:         PCM{Data: data, Channels: s.cfg.Channels, Rate: s.cfg.Rate},
+		Time:        at,
+		CaptureTime: sample.CaptureTime,
+		Dropped:     atomic.LoadUint64(&s.dropped),
+	}
+	select {
+	case s.chunks <- chunk:
+	default:
+		if dropped := atomic.AddUint64(&s.dropped, 1); dropped%100 == 1 {
+			log.Warnf("PCM stream: consumer behind, %d chunks dropped", dropped)
+		}
+	}
+	return nil
+}
+
+// decimate returns a copy of interleaved pcm with every factor samples
+// per channel averaged into one, which filters out most of what would
+// alias at the lower rate
+func decimate(pcm []int16, channels, factor int) []int16 {
+	if factor <= 1 {
+		return append([]int16(nil), pcm...)
+	}
+	frames := len(pcm) / channels / factor
+	out := make([]int16, frames*channels)
+	for i := 0; i < frames; i++ {
+		for c := 0; c < channels; c++ {
+			sum := 0
+			for j := 0; j < factor; j++ {
+				sum += int(pcm[(i*factor+j)*channels+c])
+			}
+			out[i*channels+c] = int16(sum / factor)
+		}
+	}
+	return out
+}
+
+// Close frees the decoder and closes the channel
+func (s *PCMStream) Close() {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.cfg.Decoder.Close()
+	close(s.chunks)
+}
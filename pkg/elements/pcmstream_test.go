@@ -0,0 +1,50 @@
+package elements
+
+import (
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPCMStream(t *testing.T) {
+	dec := &fakeOpusDecoder{}
+	s, err := NewPCMStream(PCMStreamConfig{Rate: 16000, Decoder: dec, Buffer: 2})
+	assert.NoError(t, err)
+
+	for i, ts := range []uint32{1000, 1960, 2920} {
+		assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{byte(i + 1)}, Timestamp: ts}))
+	}
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt, Timestamp: 3000}))
+	assert.Equal(t, uint64(1), s.Dropped())
+
+	// The consumer catches up and learns of the drop
+	c := <-s.Chunks()
+	assert.Equal(t, 16000, c.Rate)
+	assert.Equal(t, 1, c.Channels)
+	assert.Len(t, c.Data, 320)
+	assert.Equal(t, int16(1), c.Data[0])
+	assert.Equal(t, time.Duration(0), c.Time)
+	<-s.Chunks()
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{4}, Timestamp: 3880}))
+	c = <-s.Chunks()
+	assert.Equal(t, 60*time.Millisecond, c.Time)
+	assert.Equal(t, uint64(1), c.Dropped)
+
+	s.Close()
+	assert.True(t, dec.closed)
+	_, ok := <-s.Chunks()
+	assert.False(t, ok)
+
+	_, err = NewPCMStream(PCMStreamConfig{Rate: 8000, Decoder: dec})
+	assert.Equal(t, ErrPCMRate, err)
+}
+
+func TestDecimate(t *testing.T) {
+	assert.Equal(t, []int16{2, 20, 5, 50}, decimate([]int16{1, 10, 2, 20, 3, 30, 4, 40, 5, 50, 6, 60}, 2, 3))
+	pcm := []int16{1, 2}
+	out := decimate(pcm, 1, 1)
+	out[0] = 9
+	assert.Equal(t, int16(1), pcm[0])
+}
@@ -0,0 +1,254 @@
+package elements
+
+import (
+	"errors"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	log "github.com/pion/ion-log"
+)
+
+// ErrNoVideoDecoder is returned by NewAnimatedPreview when no decoder is
+// given and libvpx isn't built in
+var ErrNoVideoDecoder = errors.New("no video decoder, build with libvpx or pass one")
+
+// PreviewConfig configures an AnimatedPreview.
+// Duration: Length of the preview, defaults to 5s.
+// FrameRate: Frames per second of the preview, defaults to 10.
+// Width: Width of the preview, defaults to 320. Its height keeps the
+// aspect ratio of the video.
+// Highlights: Optional detector scoring the recording. The preview is then
+// of the start of its best window instead of the start of the recording,
+// which needs the whole video decoded rather than its first seconds.
+// Decoder: Optional creator of the decoder of the video, whose YCbCr
+// frames are kept. By default libvpx's, when built with the libvpx tag.
+type PreviewConfig struct {
+	Duration   time.Duration
+	FrameRate  int
+	Width      int
+	Highlights *HighlightDetector
+	Decoder    func() avp.Element
+}
+
+// previewClip is the frames kept of a span of the track
+type previewClip struct {
+	start  time.Duration
+	next   time.Duration // when the next frame is due
+	frames []*image.YCbCr
+}
+
+// AnimatedPreview passes samples to its children while keeping a short,
+// small clip of the video. On close it writes the clip as a looping GIF,
+// e.g. for previews shown on hovering over a recording.
+type AnimatedPreview struct {
+	Node
+	mu      sync.Mutex
+	cfg     PreviewConfig
+	track   string
+	open    func() (io.WriteCloser, error)
+	decoder avp.Element
+	clock   trackClock
+	at      time.Duration // of the frame being decoded
+	height  int
+	first   previewClip
+	window  previewClip // of the highlight window being played
+	best    previewClip // of the best scored window so far
+	closed  bool
+}
+
+// NewAnimatedPreview instance. open creates the GIF on close.
+func NewAnimatedPreview(c PreviewConfig, track string, open func() (io.WriteCloser, error)) (*AnimatedPreview, error) {
+	if c.Duration <= 0 {
+		c.Duration = 5 * time.Second
+	}
+	if c.FrameRate <= 0 {
+		c.FrameRate = 10
+	}
+	if c.Width <= 0 {
+		c.Width = 320
+	}
+	c.Width = (c.Width + 1) &^ 1
+	if c.Decoder == nil {
+		c.Decoder = newVideoDecoder
+	}
+	if c.Decoder == nil {
+		return nil, ErrNoVideoDecoder
+	}
+	return &AnimatedPreview{cfg: c, track: track, open: open}, nil
+}
+
+func (p *AnimatedPreview) Write(sample *avp.Sample) error {
+	if sample.Type == avp.TypeVP8 {
+		if err := p.video(sample); err != nil {
+			log.Debugf("AnimatedPreview of %s: %s", p.track, err)
+		}
+	}
+	return p.Node.Write(sample)
+}
+
+func (p *AnimatedPreview) video(sample *avp.Sample) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.at = p.clock.since(sample, videoClockRate)
+	if p.at < 0 {
+		return nil
+	}
+	if h := p.cfg.Highlights; h != nil {
+		if start := p.at / h.cfg.Window * h.cfg.Window; start != p.window.start {
+			p.scoreWindow()
+			p.window = previewClip{start: start, next: start}
+		}
+	} else if p.at >= p.cfg.Duration {
+		// Only the first seconds are kept
+		if p.decoder != nil {
+			p.decoder.Close()
+			p.decoder = nil
+		}
+		return nil
+	}
+	if p.decoder == nil {
+		if p.decoder = p.cfg.Decoder(); p.decoder == nil {
+			p.closed = true
+			return ErrNoVideoDecoder
+		}
+		p.decoder.Attach(&previewFrames{p})
+	}
+	return p.decoder.Write(sample)
+}
+
+// scoreWindow keeps the clip of the window just played if it's the best
+// scored so far
+func (p *AnimatedPreview) scoreWindow() {
+	var best *Highlight
+	hs := p.cfg.Highlights.Highlights().Highlights
+	for i := range hs {
+		if best == nil || hs[i].Score > best.Score {
+			best = &hs[i]
+		}
+	}
+	if best != nil && best.Start == p.window.start && len(p.window.frames) > 0 {
+		p.best = p.window
+	}
+}
+
+// frame keeps a scaled copy of a decoded frame in the clips it's due in.
+// It's called with the lock held, from the decoder.
+func (p *AnimatedPreview) frame(img *image.YCbCr) {
+	var thumb *image.YCbCr
+	for _, c := range []*previewClip{&p.first, &p.window} {
+		if c == &p.window && p.cfg.Highlights == nil {
+			continue
+		}
+		if p.at < c.next || p.at >= c.start+p.cfg.Duration {
+			continue
+		}
+		if thumb == nil {
+			if thumb = p.scale(img); thumb == nil {
+				return
+			}
+		}
+		c.frames = append(c.frames, thumb)
+		c.next = c.start + time.Duration(len(c.frames))*time.Second/time.Duration(p.cfg.FrameRate)
+	}
+}
+
+// scale returns img at the preview's size, the first frame setting its
+// aspect ratio
+func (p *AnimatedPreview) scale(img *image.YCbCr) *image.YCbCr {
+	if img.Rect.Empty() {
+		return nil
+	}
+	if img.SubsampleRatio != image.YCbCrSubsampleRatio420 {
+		img = pixel.FromImage(img)
+	}
+	if p.height == 0 {
+		p.height = (p.cfg.Width*img.Rect.Dy()/img.Rect.Dx() + 1) &^ 1
+		if p.height < 2 {
+			p.height = 2
+		}
+	}
+	m := image.NewYCbCr(image.Rect(0, 0, p.cfg.Width, p.height), image.YCbCrSubsampleRatio420)
+	if err := pixel.ScaleFit(m, img, pixel.FitPad); err != nil {
+		return nil
+	}
+	return m
+}
+
+func (p *AnimatedPreview) Close() {
+	p.Node.Close()
+	p.mu.Lock()
+	if p.closed && p.decoder == nil {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	if p.decoder != nil {
+		p.decoder.Close()
+		p.decoder = nil
+	}
+	if p.cfg.Highlights != nil {
+		p.scoreWindow()
+	}
+	clip := p.first
+	if len(p.best.frames) > 0 {
+		clip = p.best
+	}
+	p.mu.Unlock()
+
+	if len(clip.frames) == 0 {
+		return
+	}
+	if err := p.write(clip); err != nil {
+		log.Errorf("AnimatedPreview error writing preview of %s: %s", p.track, err)
+	}
+}
+
+func (p *AnimatedPreview) write(clip previewClip) error {
+	delay := 100 / p.cfg.FrameRate
+	if delay < 2 {
+		// Browsers slow down shorter delays
+		delay = 2
+	}
+	g := &gif.GIF{}
+	for _, f := range clip.frames {
+		m := image.NewPaletted(f.Rect, palette.Plan9)
+		draw.FloydSteinberg.Draw(m, f.Rect, f, image.Point{})
+		g.Image = append(g.Image, m)
+		g.Delay = append(g.Delay, delay)
+	}
+	w, err := p.open()
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(w, g); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// previewFrames takes the decoded frames of an AnimatedPreview
+type previewFrames struct {
+	p *AnimatedPreview
+}
+
+func (f *previewFrames) Write(sample *avp.Sample) error {
+	if img, ok := sample.Payload.(*image.YCbCr); ok {
+		f.p.frame(img)
+	}
+	return nil
+}
+
+func (f *previewFrames) Attach(e avp.Element) {}
+
+func (f *previewFrames) Close() {}
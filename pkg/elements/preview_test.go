@@ -0,0 +1,83 @@
+package elements
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"io"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// previewVideo writes 30s of 20fps video to e, dark but for the second
+// 10s, and audio only loud during it
+func previewVideo(t *testing.T, e avp.Element) {
+	for ms := 0; ms < 30000; ms += 50 {
+		y := byte(16)
+		if ms >= 10000 && ms < 20000 {
+			y = 235
+		}
+		assert.NoError(t, e.Write(&avp.Sample{Type: avp.TypeVP8, Timestamp: uint32(ms * 90), Payload: []byte{y}}))
+		size := 3
+		if y > 16 {
+			size = 100
+		}
+		assert.NoError(t, e.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(ms * 48), Payload: make([]byte, size)}))
+	}
+}
+
+func decodePreview(t *testing.T, b []byte) *gif.GIF {
+	g, err := gif.DecodeAll(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return &gif.GIF{}
+	}
+	return g
+}
+
+func TestAnimatedPreview(t *testing.T) {
+	out := &nopWriteCloser{}
+	open := func() (io.WriteCloser, error) { return out, nil }
+	cfg := PreviewConfig{
+		Duration: 2 * time.Second,
+		Width:    31,
+		Decoder:  func() avp.Element { return &lumaDecoder{} },
+	}
+	p, err := NewAnimatedPreview(cfg, "alice", open)
+	assert.NoError(t, err)
+	rec := &sampleRecorder{}
+	p.Attach(rec)
+	previewVideo(t, p)
+	p.Close()
+	p.Close()
+
+	// Every sample passes through
+	assert.Len(t, rec.samples, 1200)
+	g := decodePreview(t, out.Bytes())
+	if assert.Len(t, g.Image, 20) {
+		assert.Equal(t, 10, g.Delay[0])
+		assert.Equal(t, 0, g.LoopCount)
+		// Even sized, in the video's aspect ratio
+		assert.Equal(t, 32, g.Image[0].Rect.Dx())
+		assert.Equal(t, 18, g.Image[0].Rect.Dy())
+		y := color.GrayModel.Convert(g.Image[19].At(16, 9)).(color.Gray).Y
+		assert.Less(t, y, uint8(64))
+	}
+
+	// The start of the loudest window
+	out.Reset()
+	hd := NewHighlightDetector(HighlightConfig{}, "alice", func() (io.WriteCloser, error) { return &nopWriteCloser{}, nil })
+	cfg.Highlights = hd
+	p, err = NewAnimatedPreview(cfg, "alice", open)
+	assert.NoError(t, err)
+	hd.Attach(p)
+	previewVideo(t, hd)
+	hd.Close()
+	g = decodePreview(t, out.Bytes())
+	if assert.Len(t, g.Image, 20) {
+		y := color.GrayModel.Convert(g.Image[0].At(16, 9)).(color.Gray).Y
+		assert.Greater(t, y, uint8(192))
+	}
+}
@@ -0,0 +1,80 @@
+package elements
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	log "github.com/pion/ion-log"
+)
+
+// QualityTimeline is the sidecar written by QualityRecorder
+type QualityTimeline struct {
+	Track       string        `json:"track"`
+	Correlation string        `json:"correlation,omitempty"`
+	Entries     []avp.Quality `json:"entries"`
+}
+
+// QualityRecorder passes samples to its children while recording the
+// receive quality timeline of the track, written as a JSON sidecar when
+// it is closed. The builder only reports quality when its interval is
+// configured.
+type QualityRecorder struct {
+	Node
+	mu       sync.Mutex
+	timeline QualityTimeline
+	open     func() (io.WriteCloser, error)
+}
+
+// NewQualityRecorder instance. open creates the sidecar on close.
+func NewQualityRecorder(track string, open func() (io.WriteCloser, error)) *QualityRecorder {
+	return &QualityRecorder{
+		timeline: QualityTimeline{Track: track},
+		open:     open,
+	}
+}
+
+// SetCorrelation sets the correlation ID written to the sidecar
+func (r *QualityRecorder) SetCorrelation(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeline.Correlation = id
+}
+
+// ObserveQuality adds an entry to the timeline
+func (r *QualityRecorder) ObserveQuality(q avp.Quality) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeline.Entries = append(r.timeline.Entries, q)
+}
+
+// Timeline returns the entries recorded so far
+func (r *QualityRecorder) Timeline() QualityTimeline {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.timeline
+	t.Entries = append([]avp.Quality(nil), t.Entries...)
+	return t
+}
+
+func (r *QualityRecorder) Close() {
+	r.Node.Close()
+	if err := r.write(); err != nil {
+		log.Errorf("QualityRecorder error writing timeline of %s: %s", r.timeline.Track, err)
+	}
+}
+
+func (r *QualityRecorder) write() error {
+	w, err := r.open()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.Timeline()); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,44 @@
+package elements
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestQualityRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewQualityRecorder("video", func() (io.WriteCloser, error) {
+		return nopCloser{&buf}, nil
+	})
+	child := NewBufWriter()
+	r.Attach(child)
+
+	assert.NoError(t, r.Write(&avp.Sample{Payload: []byte{1, 2}}))
+	assert.Equal(t, []byte{1, 2}, child.buf.Bytes())
+
+	at := time.Unix(1600000000, 0).UTC()
+	var o avp.QualityObserver = r
+	o.ObserveQuality(avp.Quality{At: at, Packets: 90, Lost: 10, FractionLost: 0.1, Jitter: time.Millisecond})
+	o.ObserveQuality(avp.Quality{At: at.Add(time.Second), Packets: 100})
+	r.Close()
+
+	var timeline QualityTimeline
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &timeline))
+	assert.Equal(t, "video", timeline.Track)
+	assert.Len(t, timeline.Entries, 2)
+	assert.Equal(t, uint32(10), timeline.Entries[0].Lost)
+	assert.Equal(t, time.Millisecond, timeline.Entries[0].Jitter)
+	assert.True(t, at.Equal(timeline.Entries[0].At))
+}
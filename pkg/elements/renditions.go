@@ -0,0 +1,132 @@
+package elements
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/hls"
+	"github.com/pion/ion-avp/pkg/pixel"
+	log "github.com/pion/ion-log"
+)
+
+// ErrRenditions is returned for a ladder of renditions that can't be output
+var ErrRenditions = errors.New("invalid renditions")
+
+// Rendition is a size and bitrate frames are output at, a rung of an
+// adaptive bitrate ladder. With Width or Height zero it follows the
+// aspect ratio of the frames, as Scaler does.
+type Rendition struct {
+	Name    string
+	Width   int
+	Height  int
+	Bitrate int // bits per second the rendition is encoded at
+}
+
+// Renditions instance
+type Renditions struct {
+	sync.Mutex
+	ladder   []Rendition
+	branches []*Scaler
+	sizes    []image.Point // of the last frame of each rendition
+}
+
+// NewRenditions instance. Renditions takes YCbCr frames, e.g. of a
+// composition, and scales each to every rendition of ladder, so frames are
+// composed once and only encoded per rendition. Attach each rendition's
+// encoder to the element Rendition returns. Other samples, e.g. audio, go
+// to every rendition.
+func NewRenditions(ladder []Rendition, fit pixel.Fit) (*Renditions, error) {
+	if len(ladder) == 0 {
+		return nil, fmt.Errorf("%w: no renditions", ErrRenditions)
+	}
+	r := &Renditions{ladder: ladder, sizes: make([]image.Point, len(ladder))}
+	names := make(map[string]bool)
+	for _, l := range ladder {
+		if l.Name == "" || names[l.Name] {
+			return nil, fmt.Errorf("%w: rendition names must be unique, not %q", ErrRenditions, l.Name)
+		}
+		if l.Width < 0 || l.Height < 0 || l.Bitrate < 0 {
+			return nil, fmt.Errorf("%w: %s is negative", ErrRenditions, l.Name)
+		}
+		names[l.Name] = true
+		r.branches = append(r.branches, NewScaler(ScalerConfig{Width: l.Width, Height: l.Height, Fit: fit}))
+	}
+	return r, nil
+}
+
+// Ladder returns the renditions
+func (r *Renditions) Ladder() []Rendition {
+	return r.ladder
+}
+
+// Master returns the master playlist of HLS variants of the renditions,
+// each at its bitrate plus that of the audio. The variant of a rendition
+// is its media playlist at uri(name), codecs those it is encoded with.
+// Renditions following the aspect ratio of frames are listed at the size
+// of their last.
+func (r *Renditions) Master(uri func(name string) string, audioBitrate int, codecs ...string) *hls.Master {
+	r.Lock()
+	defer r.Unlock()
+
+	m := &hls.Master{}
+	for i, l := range r.ladder {
+		v := hls.Variant{
+			URI:       uri(l.Name),
+			Bandwidth: l.Bitrate + audioBitrate,
+			Width:     l.Width,
+			Height:    l.Height,
+			Codecs:    codecs,
+		}
+		if size := r.sizes[i]; size.X > 0 {
+			v.Width, v.Height = size.X, size.Y
+		}
+		m.Variants = append(m.Variants, v)
+	}
+	return m
+}
+
+// Rendition returns the element frames of the named rendition are written
+// to, nil if there's no such rendition
+func (r *Renditions) Rendition(name string) avp.Element {
+	for i, l := range r.ladder {
+		if l.Name == name {
+			return r.branches[i]
+		}
+	}
+	return nil
+}
+
+func (r *Renditions) Write(sample *avp.Sample) error {
+	src, ok := sample.Payload.(*image.YCbCr)
+	for i, b := range r.branches {
+		write := b.Write
+		if ok && sample.Type == TypeYCbCr {
+			w, h := b.size(src.Rect.Dx(), src.Rect.Dy())
+			if w == src.Rect.Dx() && h == src.Rect.Dy() {
+				// Already the rendition's size
+				write = b.Node.Write
+			}
+			r.Lock()
+			r.sizes[i] = image.Pt(w, h)
+			r.Unlock()
+		}
+		if err := write(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Attach isn't supported, elements are attached to a rendition
+func (r *Renditions) Attach(e avp.Element) {
+	log.Warnf("Renditions attach to a rendition, see Rendition()")
+}
+
+func (r *Renditions) Close() {
+	for _, b := range r.branches {
+		b.Close()
+	}
+}
@@ -0,0 +1,51 @@
+package elements
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/hls"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenditions(t *testing.T) {
+	r, err := NewRenditions([]Rendition{
+		{Name: "720p", Width: 1280, Height: 720, Bitrate: 2500000},
+		{Name: "360p", Height: 360, Bitrate: 800000},
+	}, pixel.FitPad)
+	assert.NoError(t, err)
+	high, low := &sampleRecorder{}, &sampleRecorder{}
+	r.Rendition("720p").Attach(high)
+	r.Rendition("360p").Attach(low)
+	assert.Nil(t, r.Rendition("1080p"))
+
+	frame := image.NewYCbCr(image.Rect(0, 0, 1280, 720), image.YCbCrSubsampleRatio420)
+	assert.NoError(t, r.Write(&avp.Sample{Type: TypeYCbCr, Timestamp: 90, Payload: frame}))
+	assert.NoError(t, r.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+
+	assert.Len(t, high.samples, 2)
+	assert.Len(t, low.samples, 2)
+	// The composition is shared where it is the rendition's size
+	assert.Same(t, frame, high.samples[0].Payload)
+	assert.Equal(t, image.Rect(0, 0, 640, 360), low.samples[0].Payload.(*image.YCbCr).Rect)
+	assert.Equal(t, uint32(90), low.samples[0].Timestamp)
+	assert.Equal(t, avp.TypeOpus, low.samples[1].Type)
+
+	m := r.Master(func(name string) string { return name + "/index.m3u8" }, 128000, "avc1.64001f", "opus")
+	assert.Equal(t, []hls.Variant{
+		{URI: "720p/index.m3u8", Bandwidth: 2628000, Width: 1280, Height: 720, Codecs: []string{"avc1.64001f", "opus"}},
+		{URI: "360p/index.m3u8", Bandwidth: 928000, Width: 640, Height: 360, Codecs: []string{"avc1.64001f", "opus"}},
+	}, m.Variants)
+
+	for _, ladder := range [][]Rendition{
+		nil,
+		{{Name: "a"}, {Name: "a"}},
+		{{Name: "a", Width: -1}},
+	} {
+		_, err := NewRenditions(ladder, pixel.FitPad)
+		assert.True(t, errors.Is(err, ErrRenditions))
+	}
+}
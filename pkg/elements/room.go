@@ -0,0 +1,414 @@
+package elements
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// Participant is who a track of a room recording is of, tagged on its
+// Matroska track
+type Participant struct {
+	ID   string
+	Name string
+}
+
+// Spool keeps the media of a RoomRecorder until its tracks are all known
+type Spool interface {
+	io.ReadWriteSeeker
+	io.Closer
+}
+
+// TempSpool returns a Spool of a temporary file in dir, or the system's if
+// empty, removed once closed
+func TempSpool(dir string) (Spool, error) {
+	f, err := ioutil.TempFile(dir, "room-*.mkv")
+	if err != nil {
+		return nil, err
+	}
+	return tempSpool{f}, nil
+}
+
+type tempSpool struct {
+	*os.File
+}
+
+func (f tempSpool) Close() error {
+	err := f.File.Close()
+	if rerr := os.Remove(f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// Configure RoomRecorder.
+// Recording: Optional state machine moved along as the recording progresses.
+// Epoch: Optional session start tracks are timed from, by default the
+// capture time of the room's first sample.
+// Participant: Optional lookup of who a track is of when the recording
+// closes, by default tracks are of participants with the track's id.
+// Spool: Optional opener of where media is kept until the recording
+// closes, by default a TempSpool of the system's temporary directory.
+type RoomRecorderConfig struct {
+	Recording   *recording.Recording
+	Epoch       time.Time
+	Participant func(tid string) Participant
+	Spool       func() (Spool, error)
+}
+
+// RoomRecorder records every track of a session into one WebM file, a
+// track of the file added for each as participants join, or Matroska if
+// any is H.264. Tracks can't be added to a file once its clusters are
+// written, so media is kept in a spool and the file is written on
+// closing, tracks first.
+type RoomRecorder struct {
+	mu           sync.Mutex
+	cfg          RoomRecorderConfig
+	epoch        time.Time
+	spool        Spool
+	clusters     *blockWriter
+	tracks       []*roomTrack
+	closed       bool
+	sampleWriter *SampleWriter
+}
+
+// NewRoomRecorder instance
+func NewRoomRecorder(cfg RoomRecorderConfig) *RoomRecorder {
+	if cfg.Participant == nil {
+		cfg.Participant = func(tid string) Participant {
+			return Participant{ID: tid}
+		}
+	}
+	if cfg.Spool == nil {
+		cfg.Spool = func() (Spool, error) {
+			return TempSpool("")
+		}
+	}
+	return &RoomRecorder{
+		cfg:          cfg,
+		epoch:        cfg.Epoch,
+		sampleWriter: NewSampleWriter(),
+	}
+}
+
+// Join returns the element recording a track of the room. Closing it ends
+// the track, as when the participant leaves.
+func (r *RoomRecorder) Join(tid string) avp.Element {
+	return &roomTrack{room: r, tid: tid}
+}
+
+// Write samples of tracks by their ID, for rooms fed through a single
+// element rather than Join
+func (r *RoomRecorder) Write(sample *avp.Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := &roomTrack{room: r, tid: sample.ID}
+	for _, rt := range r.tracks {
+		if rt.tid == sample.ID && !rt.closed {
+			t = rt
+		}
+	}
+	return t.push(sample)
+}
+
+// Attach attach a child element
+func (r *RoomRecorder) Attach(e avp.Element) {
+	r.sampleWriter.Attach(e)
+}
+
+// Close ends every track and writes the file
+func (r *RoomRecorder) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	setState(r.cfg.Recording, recording.StateFinalizing)
+	if r.clusters == nil {
+		r.sampleWriter.Close()
+		return
+	}
+	for _, t := range r.tracks {
+		t.end()
+	}
+	err := r.clusters.release()
+	if err == nil {
+		err = r.write()
+	}
+	if cerr := r.spool.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Errorf("room recorder: writing file: %s", err)
+		r.cfg.Recording.Fail(err)
+	}
+	r.sampleWriter.Close()
+}
+
+// write writes the header of the file with its tracks, then the clusters
+// of the spool
+func (r *RoomRecorder) write() error {
+	info := &webm.Info{
+		TimecodeScale: webm.DefaultSegmentInfo.TimecodeScale,
+		MuxingApp:     webm.DefaultSegmentInfo.MuxingApp,
+		WritingApp:    webm.DefaultSegmentInfo.WritingApp,
+		DateUTC:       r.epoch,
+	}
+	var entries []webm.TrackEntry
+	colours := make(map[uint64]*colorspace.Colour)
+	tags := &mkvTags{}
+	for _, t := range r.tracks {
+		p := r.cfg.Participant(t.tid)
+		if p.ID == "" {
+			p.ID = t.tid
+		}
+		entry := t.entry
+		entry.Name = p.Name
+		if entry.Name == "" {
+			entry.Name = p.ID
+		}
+		entries = append(entries, entry)
+		colours[entry.TrackNumber] = t.colour
+		tag := mkvTag{SimpleTag: []mkvSimpleTag{
+			{TagName: "PARTICIPANT_ID", TagString: p.ID},
+			{TagName: "TRACK_ID", TagString: t.tid},
+		}}
+		if p.Name != "" {
+			tag.SimpleTag = append(tag.SimpleTag, mkvSimpleTag{TagName: "PARTICIPANT_NAME", TagString: p.Name})
+		}
+		tag.Targets.TagTrackUID = []uint64{entry.TrackUID}
+		tags.Tag = append(tags.Tag, tag)
+	}
+	if err := writeMkvHeader(r.sampleWriter, entries, info, colours, tags); err != nil {
+		return err
+	}
+	if _, err := r.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	for {
+		// Elements down the line may hold on to what they're written
+		buf := make([]byte, 64*1024)
+		n, err := r.spool.Read(buf)
+		if n > 0 {
+			if _, werr := r.sampleWriter.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// add adds a track to the file from its first block, starting the spool
+// with the first track. Its samples are timed from the epoch.
+func (r *RoomRecorder) add(t *roomTrack, entry webm.TrackEntry, duration blockDuration, sample *avp.Sample) error {
+	at := sample.CaptureTime
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if r.clusters == nil {
+		spool, err := r.cfg.Spool()
+		if err != nil {
+			r.cfg.Recording.Fail(err)
+			return err
+		}
+		r.spool = spool
+		if r.epoch.IsZero() {
+			r.epoch = at
+		}
+		r.clusters = newClusterWriter(spoolWriter{spool}, webm.DefaultSegmentInfo)
+		// Held open until the recorder closes, whoever leaves
+		r.clusters.hold()
+	}
+	if d := at.Sub(r.epoch); d > 0 {
+		t.offset = d.Milliseconds()
+	}
+	entry.TrackNumber = uint64(len(r.tracks) + 1)
+	entry.TrackUID = entry.TrackNumber
+	t.entry = entry
+	t.w = r.clusters.addTrack(entry, duration)
+	r.tracks = append(r.tracks, t)
+	log.Infof("room recorder: track %s joined as %s track %d", t.tid, entry.CodecID, entry.TrackNumber)
+	return nil
+}
+
+// spoolWriter leaves the spool open to be read once its clusters are
+// written
+type spoolWriter struct {
+	io.Writer
+}
+
+func (spoolWriter) Close() error {
+	return nil
+}
+
+// roomTrack is a track of a RoomRecorder
+type roomTrack struct {
+	room   *RoomRecorder
+	tid    string
+	entry  webm.TrackEntry
+	colour *colorspace.Colour
+	w      *trackWriter
+	clock  trackClock
+	offset int64
+	closed bool
+}
+
+func (t *roomTrack) Write(sample *avp.Sample) error {
+	t.room.mu.Lock()
+	defer t.room.mu.Unlock()
+	return t.push(sample)
+}
+
+// push writes a sample to the track, adding the track to the file with
+// its first
+func (t *roomTrack) push(sample *avp.Sample) error {
+	if t.room.closed || t.closed {
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return nil
+	}
+	if sample.Type == avp.TypeOpus {
+		return t.writeAudio(sample, payload)
+	}
+	return t.writeVideo(sample, payload)
+}
+
+func (t *roomTrack) writeAudio(sample *avp.Sample, payload []byte) error {
+	if t.w == nil {
+		entry := webm.TrackEntry{
+			CodecID:         "A_OPUS",
+			TrackType:       2,
+			DefaultDuration: uint64(20 * time.Millisecond),
+			Audio: &webm.Audio{
+				SamplingFrequency: 48000.0,
+				Channels:          2,
+			},
+		}
+		if d, err := opus.PacketDuration(payload); err == nil {
+			entry.DefaultDuration = uint64(d)
+		}
+		duration := func(b []byte) time.Duration {
+			d, _ := opus.PacketDuration(b)
+			return d
+		}
+		if err := t.room.add(t, entry, duration, sample); err != nil {
+			return err
+		}
+	} else if t.entry.TrackType != 2 {
+		return nil
+	}
+	return t.write(true, audioClockRate, sample, payload)
+}
+
+// writeVideo writes a frame, starting the track at its first keyframe.
+// Frames of another codec than the track's are dropped.
+func (t *roomTrack) writeVideo(sample *avp.Sample, payload []byte) error {
+	var (
+		codec   string
+		private []byte
+		frame   = payload
+	)
+	info, err := frameSize(sample.Type, payload)
+	key := err == nil
+	switch sample.Type {
+	case avp.TypeVP8:
+		codec = codecVP8
+	case avp.TypeVP9:
+		codec = codecVP9
+	case avp.TypeAV1:
+		codec = codecAV1
+		if key && t.w == nil {
+			if private, err = colorspace.AV1CodecConfig(payload); err != nil {
+				log.Errorf("room recorder: reading AV1 sequence header: %s", err)
+				return nil
+			}
+		}
+	case avp.TypeH264:
+		codec = codecH264
+		f := readAVC(payload)
+		// The track starts at a keyframe with the parameter sets to
+		// decode it
+		key = f.key && (t.w != nil || f.configurable())
+		if key && t.w == nil {
+			private = avcConfig(f.sps, f.pps)
+		}
+		frame = f.data
+		if len(frame) == 0 {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if t.w == nil {
+		if !key {
+			return nil
+		}
+		entry := webm.TrackEntry{
+			CodecID:      codec,
+			CodecPrivate: private,
+			TrackType:    1,
+			Video: &webm.Video{
+				PixelWidth:  uint64(info.Width),
+				PixelHeight: uint64(info.Height),
+			},
+		}
+		t.colour = &info.Colour
+		if err := t.room.add(t, entry, nil, sample); err != nil {
+			return err
+		}
+	} else if t.entry.CodecID != codec {
+		return nil
+	}
+	return t.write(key, videoClockRate, sample, frame)
+}
+
+// write writes a block at its time from the epoch
+func (t *roomTrack) write(key bool, rate uint32, sample *avp.Sample, b []byte) error {
+	ts := t.offset + roundMs(t.clock.since(sample, rate))
+	if _, err := t.w.Write(key, ts, b); err != nil {
+		log.Errorf("room recorder: track %s: %s", t.tid, err)
+		return nil
+	}
+	setState(t.room.cfg.Recording, recording.StateRecording)
+	return nil
+}
+
+func (t *roomTrack) Attach(e avp.Element) {}
+
+// Close ends the track, the room recording carrying on
+func (t *roomTrack) Close() {
+	t.room.mu.Lock()
+	defer t.room.mu.Unlock()
+	t.end()
+}
+
+// end writes the last block of the track
+func (t *roomTrack) end() {
+	if t.closed {
+		return
+	}
+	t.closed = true
+	if t.w != nil {
+		if err := t.w.Close(); err != nil {
+			log.Errorf("room recorder: track %s: %s", t.tid, err)
+		}
+	}
+}
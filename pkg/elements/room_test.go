@@ -0,0 +1,128 @@
+package elements
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go"
+	"github.com/at-wat/ebml-go/webm"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+type roomFile struct {
+	Header  webm.EBMLHeader `ebml:"EBML"`
+	Segment struct {
+		Info    webm.Info      `ebml:"Info"`
+		Tracks  webm.Tracks    `ebml:"Tracks"`
+		Tags    mkvTags        `ebml:"Tags"`
+		Cluster []webm.Cluster `ebml:"Cluster"`
+	} `ebml:"Segment,size=unknown"`
+}
+
+func TestRoomRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "room")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "", "room.mkv")
+	t0 := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	room := NewRoomRecorder(RoomRecorderConfig{
+		Recording: rec,
+		Epoch:     t0,
+		Participant: func(tid string) Participant {
+			if tid == "alice-audio" {
+				return Participant{ID: "alice", Name: "Alice"}
+			}
+			return Participant{}
+		},
+		Spool: func() (Spool, error) {
+			return TempSpool(dir)
+		},
+	})
+	writer := NewBufWriter()
+	room.Attach(writer)
+
+	audio := func(e avp.Element, id string, ms int) {
+		assert.NoError(t, e.Write(&avp.Sample{ID: id, Type: avp.TypeOpus, Timestamp: uint32(ms * 48), CaptureTime: t0.Add(time.Duration(ms) * time.Millisecond), Payload: rawOpusPkt}))
+	}
+	video := func(e avp.Element, ms int, frame []byte) {
+		assert.NoError(t, e.Write(&avp.Sample{ID: "bob-video", Type: avp.TypeVP8, Timestamp: uint32(ms * 90), CaptureTime: t0.Add(time.Duration(ms) * time.Millisecond), Payload: frame}))
+	}
+	// Alice is in the room from the start and leaves, Bob's video joins
+	// from its first keyframe and Carol's audio joins late
+	alice, bob := room.Join("alice-audio"), room.Join("bob-video")
+	for ms := 0; ms < 200; ms += 20 {
+		audio(alice, "alice-audio", ms)
+	}
+	video(bob, 100, []byte{0x51, 0x42, 0x00, 0x00})
+	video(bob, 1000, vp8VGA)
+	video(bob, 1100, []byte{0x51, 0x42, 0x00, 0x00})
+	alice.Close()
+	audio(room, "carol-audio", 2000)
+	audio(room, "carol-audio", 2020)
+	assert.Equal(t, recording.StateRecording, rec.State())
+	room.Close()
+	bob.Close()
+	assert.Equal(t, recording.StateFinalizing, rec.State())
+
+	var file roomFile
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &file))
+	assert.Equal(t, "webm", file.Header.DocType)
+	tracks := file.Segment.Tracks.TrackEntry
+	if assert.Len(t, tracks, 3) {
+		assert.Equal(t, "Alice", tracks[0].Name)
+		assert.Equal(t, "A_OPUS", tracks[0].CodecID)
+		assert.Equal(t, "bob-video", tracks[1].Name)
+		assert.Equal(t, codecVP8, tracks[1].CodecID)
+		assert.Equal(t, uint64(640), tracks[1].Video.PixelWidth)
+		assert.Equal(t, "carol-audio", tracks[2].Name)
+	}
+	tags := file.Segment.Tags.Tag
+	if assert.Len(t, tags, 3) {
+		assert.Equal(t, []uint64{1}, tags[0].Targets.TagTrackUID)
+		assert.Equal(t, []mkvSimpleTag{
+			{TagName: "PARTICIPANT_ID", TagString: "alice"},
+			{TagName: "TRACK_ID", TagString: "alice-audio"},
+			{TagName: "PARTICIPANT_NAME", TagString: "Alice"},
+		}, tags[0].SimpleTag)
+		assert.Equal(t, mkvSimpleTag{TagName: "PARTICIPANT_ID", TagString: "bob-video"}, tags[1].SimpleTag[0])
+	}
+
+	// Blocks of each track from when it joined
+	first := map[uint64]int64{}
+	blocks := map[uint64]int{}
+	for _, c := range file.Segment.Cluster {
+		var bs []ebml.Block
+		bs = append(bs, c.SimpleBlock...)
+		for _, g := range c.BlockGroup {
+			bs = append(bs, g.Block)
+		}
+		for _, b := range bs {
+			if _, ok := first[b.TrackNumber]; !ok {
+				first[b.TrackNumber] = int64(c.Timecode) + int64(b.Timecode)
+			}
+			blocks[b.TrackNumber]++
+		}
+	}
+	assert.Equal(t, map[uint64]int64{1: 0, 2: 1000, 3: 2000}, first)
+	assert.Equal(t, map[uint64]int{1: 10, 2: 2, 3: 2}, blocks)
+
+	// The spool is gone
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestRoomRecorder_Empty(t *testing.T) {
+	room := NewRoomRecorder(RoomRecorderConfig{})
+	writer := NewBufWriter()
+	room.Attach(writer)
+	room.Join("tid").Close()
+	room.Close()
+	assert.Empty(t, writer.buf.Bytes())
+}
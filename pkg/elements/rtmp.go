@@ -0,0 +1,288 @@
+package elements
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/retry"
+	"github.com/pion/ion-avp/pkg/rtmp"
+	log "github.com/pion/ion-log"
+)
+
+// RtmpState is the state of an RtmpStreamer's connection
+type RtmpState int
+
+// States of the connection
+const (
+	RtmpConnecting RtmpState = iota
+	RtmpConnected
+	RtmpReconnecting
+	RtmpFailed
+	RtmpClosed
+)
+
+func (s RtmpState) String() string {
+	switch s {
+	case RtmpConnecting:
+		return "connecting"
+	case RtmpConnected:
+		return "connected"
+	case RtmpReconnecting:
+		return "reconnecting"
+	case RtmpFailed:
+		return "failed"
+	case RtmpClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// aacLC48kStereo is the AudioSpecificConfig of 48kHz stereo AAC-LC
+var aacLC48kStereo = []byte{0x11, 0x90}
+
+// rtmpBuffer is how many samples are buffered while sending
+const rtmpBuffer = 256
+
+// RtmpStreamerConfig configures RtmpStreamer.
+// URL: The ingest URL with the stream key, e.g.
+// rtmp://a.rtmp.youtube.com/live2/<key>, or rtmps:// for TLS.
+// Audio: Stream the audio track, AAC.
+// Video: Stream the video track, H.264.
+// AudioConfig: The AudioSpecificConfig of the AAC, defaults to 48kHz
+// stereo AAC-LC.
+// Encode: Optional encoder of samples RTMP can't carry, e.g. VP8 or Opus,
+// to H.264 or AAC samples. Without one they are dropped.
+// Retry: Backoff between attempts to connect, zero fields using the retry
+// defaults. The streamer fails once the attempts to reconnect run out, or
+// the server rejects the stream key.
+// OnState: Optional callback of the connection's states, with the error
+// that caused reconnecting or failing.
+type RtmpStreamerConfig struct {
+	URL         string
+	Audio       bool
+	Video       bool
+	AudioConfig []byte
+	Encode      func(sample *avp.Sample) ([]*avp.Sample, error)
+	Retry       retry.Policy
+	OnState     func(state RtmpState, err error)
+}
+
+// RtmpStreamer instance
+type RtmpStreamer struct {
+	cfg     RtmpStreamerConfig
+	retry   *retry.Retrier
+	samples chan *avp.Sample
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	close   sync.Once
+	// resync is set when video is dropped, so sending waits for a keyframe
+	resync int32
+
+	// Of the sending goroutine
+	conn         *rtmp.Conn
+	audio, video liveTrack
+	sps, pps     []byte
+	keyed        bool // video sent from a keyframe
+	aac          bool // sequence header sent
+}
+
+// NewRtmpStreamer instance. RtmpStreamer pushes H.264 video and AAC audio
+// to an RTMP ingest, e.g. YouTube or Twitch, reconnecting with backoff
+// when the connection drops. Samples are sent in the background, ones
+// written faster than they can be sent being dropped.
+func NewRtmpStreamer(c RtmpStreamerConfig) (*RtmpStreamer, error) {
+	addr, _, _, _, err := rtmp.Endpoint(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	if c.AudioConfig == nil {
+		c.AudioConfig = aacLC48kStereo
+	}
+	// Attempts run out rather than the circuit opening, as nothing else
+	// calls the ingest
+	c.Retry.Threshold = -1
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &RtmpStreamer{
+		cfg:     c,
+		retry:   retry.New("rtmp-"+addr, c.Retry),
+		samples: make(chan *avp.Sample, rtmpBuffer),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *RtmpStreamer) Write(sample *avp.Sample) error {
+	samples := []*avp.Sample{sample}
+	if sample.Type != avp.TypeH264 && sample.Type != avp.TypeAAC {
+		if s.cfg.Encode == nil {
+			return nil
+		}
+		var err error
+		if samples, err = s.cfg.Encode(sample); err != nil {
+			return err
+		}
+	}
+	for _, sample := range samples {
+		if (sample.Type != avp.TypeH264 || !s.cfg.Video) && (sample.Type != avp.TypeAAC || !s.cfg.Audio) {
+			continue
+		}
+		// Payloads are sent later, so mustn't be reused meanwhile
+		cp := *sample
+		cp.Payload = append([]byte(nil), sample.Payload.([]byte)...)
+		select {
+		case s.samples <- &cp:
+		default:
+			log.Debugf("RTMP streamer: dropping sample, sending is behind")
+			if sample.Type == avp.TypeH264 {
+				atomic.StoreInt32(&s.resync, 1)
+			}
+		}
+	}
+	return nil
+}
+
+// Attach isn't supported, the stream is sent to the ingest
+func (s *RtmpStreamer) Attach(e avp.Element) {
+	log.Warnf("RtmpStreamer sends to its ingest")
+}
+
+// Close ends the stream
+func (s *RtmpStreamer) Close() {
+	s.close.Do(func() {
+		s.cancel()
+		<-s.done
+	})
+}
+
+// run connects and sends samples until closed, reconnecting when the
+// connection drops
+func (s *RtmpStreamer) run() {
+	defer close(s.done)
+	s.state(RtmpConnecting, nil)
+	for {
+		if err := s.retry.Do(s.ctx, s.connect); err != nil {
+			if s.ctx.Err() != nil {
+				s.state(RtmpClosed, nil)
+				return
+			}
+			log.Errorf("RTMP streamer: giving up connecting: %s", err)
+			s.state(RtmpFailed, err)
+			return
+		}
+		s.state(RtmpConnected, nil)
+
+		err := s.stream()
+		s.conn.Close()
+		if s.ctx.Err() != nil {
+			s.state(RtmpClosed, nil)
+			return
+		}
+		log.Warnf("RTMP streamer: connection lost, reconnecting: %s", err)
+		s.state(RtmpReconnecting, err)
+	}
+}
+
+// connect connects to the ingest, starting the stream over
+func (s *RtmpStreamer) connect() error {
+	conn, err := rtmp.Dial(s.ctx, s.cfg.URL)
+	if err != nil {
+		if errors.Is(err, rtmp.ErrRejected) || errors.Is(err, rtmp.ErrURL) {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	s.conn = conn
+	s.audio.started, s.video.started = false, false
+	s.keyed, s.aac = false, false
+	// Samples queued while connecting are stale
+	for {
+		select {
+		case <-s.samples:
+		default:
+			return nil
+		}
+	}
+}
+
+// stream sends samples until the connection fails or the streamer closes
+func (s *RtmpStreamer) stream() error {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case <-s.conn.Done():
+			return s.conn.Err()
+		case sample := <-s.samples:
+			var err error
+			if sample.Type == avp.TypeH264 {
+				err = s.sendVideo(sample)
+			} else {
+				err = s.sendAudio(sample)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendVideo sends an H.264 access unit, starting at a keyframe with the
+// decoder configuration, sent again whenever the parameter sets change
+func (s *RtmpStreamer) sendVideo(sample *avp.Sample) error {
+	f := readAVC(sample.Payload.([]byte))
+	d := s.video.clock.since(sample, videoClockRate)
+	if atomic.CompareAndSwapInt32(&s.resync, 1, 0) {
+		s.keyed = false
+	}
+	changed := false
+	if len(f.sps) >= 4 && f.pps != nil && (!bytes.Equal(f.sps, s.sps) || !bytes.Equal(f.pps, s.pps)) {
+		s.sps, s.pps = f.sps, f.pps
+		changed = true
+	}
+	if !s.keyed && (!f.key || s.sps == nil) {
+		return nil
+	}
+	if len(f.data) == 0 {
+		return nil
+	}
+
+	ts := s.video.at(d)
+	if !s.keyed || changed {
+		if err := s.conn.WriteVideo(ts, rtmp.AVCSequenceHeader(avcConfig(s.sps, s.pps))); err != nil {
+			return err
+		}
+		s.keyed = true
+	}
+	return s.conn.WriteVideo(ts, rtmp.AVCFrame(f.key, f.data))
+}
+
+// sendAudio sends an AAC frame, starting with the video
+func (s *RtmpStreamer) sendAudio(sample *avp.Sample) error {
+	d := s.audio.clock.since(sample, audioClockRate)
+	if s.cfg.Video && !s.keyed {
+		return nil
+	}
+	ts := s.audio.at(d)
+	if !s.aac {
+		if err := s.conn.WriteAudio(ts, rtmp.AACSequenceHeader(s.cfg.AudioConfig)); err != nil {
+			return err
+		}
+		s.aac = true
+	}
+	return s.conn.WriteAudio(ts, rtmp.AACFrame(sample.Payload.([]byte)))
+}
+
+func (s *RtmpStreamer) state(state RtmpState, err error) {
+	log.Infof("RTMP streamer %s", state)
+	if s.cfg.OnState != nil {
+		s.cfg.OnState(state, err)
+	}
+}
@@ -0,0 +1,94 @@
+package elements
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/retry"
+	"github.com/pion/ion-avp/pkg/rtmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rtmpStates collects the states of a streamer
+type rtmpStates struct {
+	sync.Mutex
+	states []RtmpState
+	err    error
+	done   chan struct{}
+}
+
+func (r *rtmpStates) add(state RtmpState, err error) {
+	r.Lock()
+	defer r.Unlock()
+	r.states = append(r.states, state)
+	if err != nil {
+		r.err = err
+	}
+	if state == RtmpFailed || state == RtmpClosed {
+		close(r.done)
+	}
+}
+
+func TestRtmpStreamer_Reconnect(t *testing.T) {
+	// Nothing listens on the port once the listener closes
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "rtmp://" + ln.Addr().String() + "/live/key"
+	ln.Close()
+
+	states := &rtmpStates{done: make(chan struct{})}
+	var encoded []int
+	s, err := NewRtmpStreamer(RtmpStreamerConfig{
+		URL:   url,
+		Audio: true,
+		Video: true,
+		Encode: func(sample *avp.Sample) ([]*avp.Sample, error) {
+			encoded = append(encoded, sample.Type)
+			return []*avp.Sample{{Type: avp.TypeAAC, Payload: []byte{1}}}, nil
+		},
+		Retry:   retry.Policy{Attempts: 3, Initial: time.Millisecond},
+		OnState: states.add,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeH264, Payload: []byte{0, 0, 0, 1, 0x65}}))
+	assert.Equal(t, []int{avp.TypeOpus}, encoded)
+
+	select {
+	case <-states.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamer didn't give up")
+	}
+	s.Close()
+	assert.Equal(t, []RtmpState{RtmpConnecting, RtmpFailed}, states.states)
+	assert.Error(t, states.err)
+}
+
+func TestRtmpStreamer_Close(t *testing.T) {
+	// A listener that never answers keeps the streamer connecting
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	states := &rtmpStates{done: make(chan struct{})}
+	s, err := NewRtmpStreamer(RtmpStreamerConfig{
+		URL:     "rtmp://" + ln.Addr().String() + "/live/key",
+		Video:   true,
+		OnState: states.add,
+	})
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	s.Close()
+	<-states.done
+	assert.Equal(t, []RtmpState{RtmpConnecting, RtmpClosed}, states.states)
+	assert.NoError(t, states.err)
+	assert.Equal(t, "closed", RtmpClosed.String())
+
+	_, err = NewRtmpStreamer(RtmpStreamerConfig{URL: "http://host/live/key"})
+	assert.True(t, errors.Is(err, rtmp.ErrURL))
+}
@@ -0,0 +1,121 @@
+package elements
+
+import (
+	"io"
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/storage"
+	log "github.com/pion/ion-log"
+)
+
+// defaultS3PartSize is the size of the parts uploaded unless configured
+const defaultS3PartSize = 8 * 1024 * 1024
+
+// S3UploaderConfig configures S3Uploader.
+// S3: The bucket, credentials and endpoint uploaded to. PartSize is the
+// size of each part, 8MiB by default and at least 5MiB, and Retry the
+// backoff of each request.
+// Name: Of the object, under the bucket's Prefix.
+// OnComplete: Optional callback with the key of the object once uploaded.
+// Recording: Optional state machine moved along as the upload progresses.
+type S3UploaderConfig struct {
+	S3         storage.S3Config
+	Name       string
+	OnComplete func(key string)
+	Recording  *recording.Recording
+}
+
+// S3Uploader instance
+type S3Uploader struct {
+	objectWriter
+}
+
+// NewS3Uploader instance. S3Uploader streams the bytes it receives, e.g.
+// from WebmSaver's SampleWriter, to an object in an S3 compatible bucket
+// as a multipart upload, a part at a time, so recordings never touch local
+// disk. The upload is completed when the uploader is closed, or aborted
+// if a part fails after its retries.
+func NewS3Uploader(c S3UploaderConfig) (*S3Uploader, error) {
+	if c.S3.PartSize <= 0 {
+		c.S3.PartSize = defaultS3PartSize
+	}
+	s3, err := storage.NewS3(c.S3)
+	if err != nil {
+		return nil, err
+	}
+	u := &S3Uploader{objectWriter{kind: "S3Uploader", key: s3.Key(c.Name), done: c.OnComplete, rec: c.Recording}}
+	if err := u.open(s3, c.Name); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// objectWriter streams the bytes it receives to an object of a storage
+// backend, reporting its key once stored
+type objectWriter struct {
+	Leaf
+	sync.Mutex
+	kind   string // of element, for logs
+	key    string
+	done   func(key string)
+	rec    *recording.Recording
+	wr     io.WriteCloser
+	err    error
+	closed bool
+}
+
+func (w *objectWriter) open(store storage.Storage, name string) error {
+	wr, err := store.Open(name)
+	if err != nil {
+		return err
+	}
+	w.wr = wr
+	log.Infof("%s opened %s", w.kind, w.key)
+	return nil
+}
+
+func (w *objectWriter) Write(sample *avp.Sample) error {
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return nil
+	}
+	w.Lock()
+	defer w.Unlock()
+	if w.closed || w.err != nil {
+		return w.err
+	}
+	if _, err := w.wr.Write(payload); err != nil {
+		log.Errorf("%s error uploading %s: %s", w.kind, w.key, err)
+		w.err = err
+		w.rec.Fail(err)
+		return err
+	}
+	return nil
+}
+
+// Close completes the upload
+func (w *objectWriter) Close() {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	if w.err != nil {
+		return
+	}
+	setState(w.rec, recording.StateFinalizing)
+	setState(w.rec, recording.StateUploading)
+	if err := w.wr.Close(); err != nil {
+		log.Errorf("%s error completing %s: %s", w.kind, w.key, err)
+		w.rec.Fail(err)
+		return
+	}
+	setState(w.rec, recording.StateComplete)
+	log.Infof("%s uploaded %s", w.kind, w.key)
+	if w.done != nil {
+		w.done(w.key)
+	}
+}
@@ -0,0 +1,44 @@
+package elements
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3Uploader(t *testing.T) {
+	var uploaded []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/rec/live/a.webm", r.URL.Path)
+		uploaded, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "x")
+	var key string
+	u, err := NewS3Uploader(S3UploaderConfig{
+		S3:         storage.S3Config{Bucket: "rec", Prefix: "live/", Endpoint: srv.URL, PathStyle: true},
+		Name:       "a.webm",
+		OnComplete: func(k string) { key = k },
+		Recording:  rec,
+	})
+	assert.NoError(t, err)
+
+	w := NewSampleWriter()
+	w.Attach(u)
+	_, err = w.Write([]byte("recorded "))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("bytes"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, "live/a.webm", key)
+	assert.Equal(t, recording.StateComplete, rec.State())
+	assert.Equal(t, "recorded bytes", string(uploaded))
+}
@@ -0,0 +1,152 @@
+package elements
+
+import (
+	"image"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+)
+
+// PCM is the payload of TypePCM samples: interleaved 16 bit audio
+type PCM struct {
+	Data     []int16
+	Channels int
+	Rate     int
+}
+
+// Text is the payload of TypeText samples: timed text, such as a line of
+// a transcript or a caption
+type Text struct {
+	Text string
+	// Start and End are times in the track, from its first sample
+	Start time.Duration
+	End   time.Duration
+	// Speaker names who is speaking, if known
+	Speaker  string
+	Language string
+	// Final is false for interim text, revised by the text that follows
+	Final bool
+}
+
+// Frame is a decoded video frame delivered by SampleSink
+type Frame struct {
+	// Image is an *image.YCbCr or *image.RGBA, as output by the decoder
+	Image       image.Image
+	Timestamp   uint32
+	CaptureTime time.Time
+}
+
+// Audio is decoded audio delivered by SampleSink
+type Audio struct {
+	PCM
+	Timestamp   uint32
+	CaptureTime time.Time
+}
+
+// SampleSinkConfig configures a SampleSink.
+// OnFrame and OnAudio are called with each decoded frame and block of
+// audio, in order, on the pipeline's goroutine. What they are passed is
+// only valid until they return, decoders reuse their buffers, so copy
+// anything kept. A slow callback holds up the pipeline.
+// Buffer, if set, also delivers copies, which are safe to keep, on the
+// channels returned by Frames and Audio. Samples are dropped while a
+// channel is full.
+type SampleSinkConfig struct {
+	OnFrame func(Frame)
+	OnAudio func(Audio)
+	Buffer  int
+}
+
+// SampleSink hands decoded samples to Go code, for applications embedding
+// ion-avp that process media without writing an element. Attach it to a
+// Decoder for frames or an OpusDecoder for audio.
+type SampleSink struct {
+	Leaf
+	cfg    SampleSinkConfig
+	frames chan Frame
+	audio  chan Audio
+}
+
+// NewSampleSink instance
+func NewSampleSink(c SampleSinkConfig) *SampleSink {
+	s := &SampleSink{cfg: c}
+	if c.Buffer > 0 {
+		s.frames = make(chan Frame, c.Buffer)
+		s.audio = make(chan Audio, c.Buffer)
+	}
+	return s
+}
+
+// Frames returns the channel of copied frames, nil without a buffer. It
+// is closed when the sink is.
+func (s *SampleSink) Frames() <-chan Frame {
+	return s.frames
+}
+
+// Audio returns the channel of copied audio, nil without a buffer. It is
+// closed when the sink is.
+func (s *SampleSink) Audio() <-chan Audio {
+	return s.audio
+}
+
+func (s *SampleSink) Write(sample *avp.Sample) error {
+	switch sample.Type {
+	case TypeYCbCr, TypeRGBA:
+		img, ok := sample.Payload.(image.Image)
+		if !ok {
+			return nil
+		}
+		f := Frame{Image: img, Timestamp: sample.Timestamp, CaptureTime: sample.CaptureTime}
+		if s.cfg.OnFrame != nil {
+			s.cfg.OnFrame(f)
+		}
+		if s.frames != nil {
+			f.Image = copyImage(img)
+			select {
+			case s.frames <- f:
+			default:
+			}
+		}
+	case TypePCM:
+		pcm, ok := sample.Payload.(*PCM)
+		if !ok {
+			return nil
+		}
+		a := Audio{PCM: *pcm, Timestamp: sample.Timestamp, CaptureTime: sample.CaptureTime}
+		if s.cfg.OnAudio != nil {
+			s.cfg.OnAudio(a)
+		}
+		if s.audio != nil {
+			a.Data = append([]int16(nil), pcm.Data...)
+			select {
+			case s.audio <- a:
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the channels
+func (s *SampleSink) Close() {
+	if s.frames != nil {
+		close(s.frames)
+		close(s.audio)
+	}
+}
+
+func copyImage(img image.Image) image.Image {
+	switch src := img.(type) {
+	case *image.YCbCr:
+		dst := *src
+		dst.Y = append([]byte(nil), src.Y...)
+		dst.Cb = append([]byte(nil), src.Cb...)
+		dst.Cr = append([]byte(nil), src.Cr...)
+		return &dst
+	case *image.RGBA:
+		dst := *src
+		dst.Pix = append([]byte(nil), src.Pix...)
+		return &dst
+	}
+	return img
+}
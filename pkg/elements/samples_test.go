@@ -0,0 +1,46 @@
+package elements
+
+import (
+	"image"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleSink(t *testing.T) {
+	var frames []Frame
+	var audio []Audio
+	sink := NewSampleSink(SampleSinkConfig{
+		OnFrame: func(f Frame) { frames = append(frames, f) },
+		OnAudio: func(a Audio) { audio = append(audio, a) },
+		Buffer:  1,
+	})
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	pcm := &PCM{Data: []int16{1, 2, 3, 4}, Channels: 2, Rate: 48000}
+	assert.NoError(t, sink.Write(&avp.Sample{Type: TypeRGBA, Timestamp: 10, Payload: img}))
+	assert.NoError(t, sink.Write(&avp.Sample{Type: TypePCM, Timestamp: 20, Payload: pcm}))
+	// Dropped from the full channels, but still called back
+	assert.NoError(t, sink.Write(&avp.Sample{Type: TypeRGBA, Timestamp: 30, Payload: img}))
+	assert.NoError(t, sink.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+
+	assert.Len(t, frames, 2)
+	assert.Same(t, img, frames[0].Image)
+	assert.Equal(t, uint32(30), frames[1].Timestamp)
+	assert.Len(t, audio, 1)
+	assert.Equal(t, uint32(20), audio[0].Timestamp)
+
+	// Channels get copies, unchanged by the decoder reusing its buffers
+	img.Pix[0], pcm.Data[0] = 255, 9
+	f := <-sink.Frames()
+	assert.Equal(t, uint32(10), f.Timestamp)
+	assert.Equal(t, uint8(0), f.Image.(*image.RGBA).Pix[0])
+	a := <-sink.Audio()
+	assert.Equal(t, []int16{1, 2, 3, 4}, a.Data)
+	assert.Equal(t, 2, a.Channels)
+
+	sink.Close()
+	_, ok := <-sink.Frames()
+	assert.False(t, ok)
+}
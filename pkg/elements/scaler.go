@@ -0,0 +1,75 @@
+package elements
+
+import (
+	"image"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+)
+
+// ScalerConfig configures a Scaler.
+// Width and Height are the size of output frames. With one of them zero
+// it follows the aspect ratio of each frame, so portrait and landscape
+// video keep their shape.
+// Fit is how frames of another aspect ratio are fitted, letterboxed or
+// pillarboxed by default.
+type ScalerConfig struct {
+	Width  int
+	Height int
+	Fit    pixel.Fit
+}
+
+// Scaler instance
+type Scaler struct {
+	Node
+	cfg ScalerConfig
+}
+
+// NewScaler instance. Scaler takes as input YCbCr frames, e.g. from a
+// Decoder, and scales them to a fixed size. Frames may change size and
+// aspect ratio mid stream, as mobile publishers rotating do.
+func NewScaler(c ScalerConfig) *Scaler {
+	return &Scaler{cfg: c}
+}
+
+func (s *Scaler) Write(sample *avp.Sample) error {
+	src, ok := sample.Payload.(*image.YCbCr)
+	if sample.Type != TypeYCbCr || !ok {
+		return s.Node.Write(sample)
+	}
+	w, h := s.size(src.Rect.Dx(), src.Rect.Dy())
+	if w == 0 || h == 0 {
+		return nil
+	}
+	dst := image.NewYCbCr(image.Rect(0, 0, w, h), src.SubsampleRatio)
+	if err := pixel.ScaleFit(dst, src, s.cfg.Fit); err != nil {
+		return err
+	}
+	out := *sample
+	out.Payload = dst
+	return s.Node.Write(&out)
+}
+
+// size returns the output size of a frame of sw by sh
+func (s *Scaler) size(sw, sh int) (int, int) {
+	w, h := s.cfg.Width, s.cfg.Height
+	if sw == 0 || sh == 0 {
+		return 0, 0
+	}
+	switch {
+	case w == 0 && h == 0:
+		return sw, sh
+	case h == 0:
+		// Even sizes, which encoders need for 4:2:0
+		h = ((w*sh+sw/2)/sw + 1) &^ 1
+	case w == 0:
+		w = ((h*sw+sh/2)/sh + 1) &^ 1
+	}
+	if w < 2 {
+		w = 2
+	}
+	if h < 2 {
+		h = 2
+	}
+	return w, h
+}
@@ -0,0 +1,54 @@
+package elements
+
+import (
+	"image"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+type sampleRecorder struct {
+	samples []*avp.Sample
+}
+
+func (r *sampleRecorder) Write(sample *avp.Sample) error {
+	r.samples = append(r.samples, sample)
+	return nil
+}
+func (r *sampleRecorder) Attach(e avp.Element) {}
+func (r *sampleRecorder) Close()               {}
+
+func TestScaler(t *testing.T) {
+	out := &sampleRecorder{}
+	s := NewScaler(ScalerConfig{Width: 640, Height: 360, Fit: pixel.FitCrop})
+	s.Attach(out)
+
+	// Rotating from landscape to portrait mid stream
+	for _, r := range []image.Rectangle{image.Rect(0, 0, 1280, 720), image.Rect(0, 0, 719, 1279)} {
+		assert.NoError(t, s.Write(&avp.Sample{
+			Type:      TypeYCbCr,
+			Timestamp: 90,
+			Payload:   image.NewYCbCr(r, image.YCbCrSubsampleRatio420),
+		}))
+	}
+	assert.NoError(t, s.Write(&avp.Sample{Type: TypeJPEG, Payload: []byte{1}}))
+	assert.Len(t, out.samples, 3)
+	for _, sample := range out.samples[:2] {
+		assert.Equal(t, image.Rect(0, 0, 640, 360), sample.Payload.(*image.YCbCr).Rect)
+		assert.Equal(t, uint32(90), sample.Timestamp)
+	}
+	assert.Equal(t, TypeJPEG, out.samples[2].Type)
+
+	// Following the aspect ratio, at even sizes
+	s = NewScaler(ScalerConfig{Height: 320})
+	for _, tc := range []struct{ sw, sh, w, h int }{
+		{1280, 720, 570, 320},
+		{721, 1281, 180, 320},
+	} {
+		w, h := s.size(tc.sw, tc.sh)
+		assert.Equal(t, tc.w, w)
+		assert.Equal(t, tc.h, h)
+	}
+}
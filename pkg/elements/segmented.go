@@ -0,0 +1,253 @@
+package elements
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// SegmentedSaverConfig configures a SegmentedSaver.
+// Saver: Configuration of the WebmSaver of each segment. Its Recording is
+// moved along by the SegmentedSaver, and each finished segment reported to
+// it. Only the first segment is aligned to its Epoch. Its Sidecar is sent
+// the Metadata of the whole recording.
+// MaxSegmentDuration: Time after which a segment is finished at the next
+// keyframe, of the video if recorded. If 0, segments are only finished
+// where the video changes resolution.
+// Filename: Name of the recording, segments are named after it with their
+// index, e.g. rec-0.webm, rec-1.webm.
+// Open: Returns the element a segment is written to, e.g. a FileWriter.
+// OnSegment: Optional callback with each finished segment.
+// Counter: Optional persisted number of the next segment, so a recording
+// resumed after a restart carries on numbering its segments rather than
+// overwriting them.
+type SegmentedSaverConfig struct {
+	Saver              WebmSaverConfig
+	MaxSegmentDuration time.Duration
+	Filename           string
+	Open               func(name string) (avp.Element, error)
+	OnSegment          func(name string, start, end time.Time)
+	Counter            Counter
+}
+
+// Counter is a number persisted across restarts, such as a counter.Counter
+type Counter interface {
+	Load() int
+	// Store raises the number to v
+	Store(v int) error
+}
+
+// SegmentedSaver instance
+type SegmentedSaver struct {
+	sync.Mutex
+	cfg    SegmentedSaverConfig
+	saver  *WebmSaver
+	meta   *sidecar
+	name   string
+	index  int
+	first  int           // index of the first segment, aligned to the Epoch
+	width  int           // of the video of the segment
+	height int           // of the video of the segment
+	clock  trackClock    // of the track segments are timed by
+	start  time.Duration // of the segment, since the first sample
+	last   time.Duration // of the last sample
+	began  time.Time     // of the first sample
+	paused bool
+	closed bool
+}
+
+// NewSegmentedSaver instance. SegmentedSaver records like a WebmSaver,
+// rotating to a new file every MaxSegmentDuration so long recordings are
+// easier to upload and seek. Files are finished at keyframes, so each
+// plays on its own, and where the video changes resolution, e.g. on a
+// simulcast layer switch, as a file's tracks keep the size it started at.
+func NewSegmentedSaver(c SegmentedSaverConfig) *SegmentedSaver {
+	s := &SegmentedSaver{cfg: c, meta: newSidecar(c.Saver.Sidecar, c.Saver.Recording)}
+	if c.Counter != nil {
+		s.index = c.Counter.Load()
+		s.first = s.index
+	}
+	return s
+}
+
+// SegmentName returns the name of segment index of a recording named name
+func SegmentName(name string, index int) string {
+	ext := path.Ext(name)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), index, ext)
+}
+
+func (s *SegmentedSaver) Write(sample *avp.Sample) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed || !s.recorded(sample) {
+		return nil
+	}
+	rec := s.cfg.Saver.Recording
+	if paused := rec.State() == recording.StatePaused; paused != s.paused {
+		s.paused = paused
+		if s.saver != nil {
+			if paused {
+				s.saver.Pause()
+			} else {
+				s.saver.Resume()
+			}
+		}
+	}
+	if s.paused {
+		return nil
+	}
+
+	if s.began.IsZero() {
+		s.began = sample.CaptureTime
+		if s.began.IsZero() {
+			s.began = time.Now()
+		}
+	}
+	// Segments are timed by and start at keyframes of the video, audio
+	// following
+	key, resized := false, false
+	var width, height int
+	if timed := sample.Type != avp.TypeOpus || !s.cfg.Saver.Video; timed {
+		s.last = s.clock.since(sample, s.clockRate())
+		key = keyframe(sample)
+	}
+	if key && sample.Type != avp.TypeOpus {
+		if info, err := frameSize(sample.Type, sample.Payload.([]byte)); err == nil {
+			width, height = info.Width, info.Height
+			resized = s.saver != nil && (width != s.width || height != s.height)
+		}
+	}
+	if s.saver != nil && key && (resized || (s.cfg.MaxSegmentDuration > 0 && s.last-s.start >= s.cfg.MaxSegmentDuration)) {
+		if resized {
+			log.Infof("SegmentedSaver: video of %s resized from %dx%d to %dx%d, starting a new segment", s.cfg.Filename, s.width, s.height, width, height)
+		}
+		s.finish(s.last)
+	}
+	if s.saver == nil {
+		if !key {
+			setState(rec, recording.StateWaitingForKeyframe)
+			s.meta.dropped(sample)
+			return nil
+		}
+		if err := s.open(s.last); err != nil {
+			rec.Fail(err)
+			s.closed = true
+			return err
+		}
+		s.width, s.height = width, height
+	}
+	if key && rec.State() != recording.StateRecording {
+		setState(rec, recording.StateRecording)
+	}
+	s.meta.recorded(sample)
+	return s.saver.Write(sample)
+}
+
+// Attach isn't supported, segments are written to the elements Open returns
+func (s *SegmentedSaver) Attach(e avp.Element) {
+	log.Warnf("SegmentedSaver writes segments to Open()")
+}
+
+// Close finishes the last segment
+func (s *SegmentedSaver) Close() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	rec := s.cfg.Saver.Recording
+	setState(rec, recording.StateFinalizing)
+	if s.saver != nil {
+		s.finish(s.last)
+	}
+	s.meta.close()
+	setState(rec, recording.StateComplete)
+}
+
+// recorded reports whether samples of the type are recorded
+func (s *SegmentedSaver) recorded(sample *avp.Sample) bool {
+	if sample.Type == avp.TypeOpus {
+		return s.cfg.Saver.Audio
+	}
+	return s.cfg.Saver.Video
+}
+
+// clockRate returns the default rate of the track segments are timed by
+func (s *SegmentedSaver) clockRate() uint32 {
+	if s.cfg.Saver.Video {
+		return videoClockRate
+	}
+	return audioClockRate
+}
+
+// open starts a segment at t
+func (s *SegmentedSaver) open(t time.Duration) error {
+	name := SegmentName(s.cfg.Filename, s.index)
+	// The number is taken before the segment is written, so a crash
+	// while writing it doesn't reuse it
+	if c := s.cfg.Counter; c != nil {
+		if err := c.Store(s.index + 1); err != nil {
+			log.Warnf("SegmentedSaver: storing segment number of %s: %s", s.cfg.Filename, err)
+		}
+	}
+	w, err := s.cfg.Open(name)
+	if err != nil {
+		return fmt.Errorf("opening segment %s: %w", name, err)
+	}
+	cfg := s.cfg.Saver
+	cfg.Recording, cfg.Sidecar = nil, nil
+	if s.index > s.first {
+		cfg.Epoch = time.Time{}
+	}
+	s.saver = NewWebmSaver(&cfg)
+	s.saver.Attach(w)
+	s.name, s.start = name, t
+	return nil
+}
+
+// finish closes the segment, ending at t
+func (s *SegmentedSaver) finish(t time.Duration) {
+	s.saver.Close()
+	s.saver = nil
+	start, end := s.began.Add(s.start), s.began.Add(t)
+	s.cfg.Saver.Recording.Segment(s.name, start, end)
+	if s.cfg.OnSegment != nil {
+		s.cfg.OnSegment(s.name, start, end)
+	}
+	s.index++
+}
+
+// keyframe reports whether a sample can start a file, all audio can
+func keyframe(sample *avp.Sample) bool {
+	payload, ok := sample.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return false
+	}
+	switch sample.Type {
+	case avp.TypeOpus:
+		return true
+	case avp.TypeVP8:
+		return payload[0]&0x1 == 0
+	case avp.TypeVP9:
+		_, err := colorspace.ParseVP9(payload)
+		return err == nil
+	case avp.TypeAV1:
+		_, err := colorspace.ParseAV1(payload)
+		return err == nil
+	case avp.TypeH264:
+		// With the parameter sets the track is configured from
+		f := readAVC(payload)
+		return f.key && f.configurable()
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package elements
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentName(t *testing.T) {
+	assert.Equal(t, "/tmp/rec-0.webm", SegmentName("/tmp/rec.webm", 0))
+	assert.Equal(t, "rec-12", SegmentName("rec", 12))
+}
+
+func TestSegmentedSaver(t *testing.T) {
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "rec.webm")
+	files := map[string]*BufWriter{}
+	var names []string
+	var durations []time.Duration
+	saver := NewSegmentedSaver(SegmentedSaverConfig{
+		Saver:              WebmSaverConfig{Audio: true, Video: true, Recording: rec},
+		MaxSegmentDuration: time.Second,
+		Filename:           "rec.webm",
+		Open: func(name string) (avp.Element, error) {
+			files[name] = NewBufWriter()
+			return files[name], nil
+		},
+		OnSegment: func(name string, start, end time.Time) {
+			names = append(names, name)
+			durations = append(durations, end.Sub(start))
+		},
+	})
+
+	interframe := append([]byte{rawKeyframePkt[0] | 1}, rawKeyframePkt[1:]...)
+	// Keyframes every 800ms of 10fps video, from an interframe
+	for i := 0; i < 30; i++ {
+		payload := interframe
+		if i%8 == 1 {
+			payload = rawKeyframePkt
+		}
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Timestamp: uint32(i * 9000), Payload: payload}))
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 4800), Payload: rawOpusPkt}))
+		if i == 0 {
+			assert.Equal(t, recording.StateWaitingForKeyframe, rec.State())
+		}
+	}
+	assert.Equal(t, recording.StateRecording, rec.State())
+	saver.Close()
+	assert.Equal(t, recording.StateComplete, rec.State())
+
+	// Segments end at the first keyframe a second or more in
+	assert.Equal(t, []string{"rec-0.webm", "rec-1.webm"}, names)
+	assert.Equal(t, []time.Duration{1600 * time.Millisecond, 1200 * time.Millisecond}, durations)
+	assert.Len(t, files, 2)
+	for _, f := range files {
+		var header Header
+		assert.NoError(t, ebml.Unmarshal(bytes.NewReader(f.buf.Bytes()), &header))
+		assert.NotEmpty(t, header.Segment.Cluster)
+	}
+}
+
+// memCounter is a Counter kept in memory
+type memCounter struct {
+	n int
+}
+
+func (c *memCounter) Load() int {
+	return c.n
+}
+
+func (c *memCounter) Store(v int) error {
+	if v > c.n {
+		c.n = v
+	}
+	return nil
+}
+
+func TestSegmentedSaver_Counter(t *testing.T) {
+	// Resumed after a restart, with segments 0 to 4 written before
+	counter := &memCounter{n: 5}
+	var names []string
+	saver := NewSegmentedSaver(SegmentedSaverConfig{
+		Saver:              WebmSaverConfig{Video: true},
+		MaxSegmentDuration: time.Second,
+		Filename:           "rec.webm",
+		Open: func(name string) (avp.Element, error) {
+			names = append(names, name)
+			return NewBufWriter(), nil
+		},
+		Counter: counter,
+	})
+	for i := 0; i < 15; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Timestamp: uint32(i * 9000), Payload: rawKeyframePkt}))
+	}
+	saver.Close()
+	assert.Equal(t, []string{"rec-5.webm", "rec-6.webm"}, names)
+	assert.Equal(t, 7, counter.Load())
+}
+
+// VP8 keyframes of 640x480 and 320x240
+var (
+	vp8VGA  = []byte{0x50, 0x42, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01, 0x00, 0x47}
+	vp8QVGA = []byte{0x50, 0x42, 0x00, 0x9d, 0x01, 0x2a, 0x40, 0x01, 0xf0, 0x00, 0x00, 0x47}
+)
+
+func TestSegmentedSaver_Resize(t *testing.T) {
+	files := map[string]*BufWriter{}
+	var names []string
+	saver := NewSegmentedSaver(SegmentedSaverConfig{
+		Saver:    WebmSaverConfig{Video: true},
+		Filename: "rec.webm",
+		Open: func(name string) (avp.Element, error) {
+			names = append(names, name)
+			files[name] = NewBufWriter()
+			return files[name], nil
+		},
+	})
+	interframe := []byte{0x51, 0x42, 0x00, 0x00}
+	// Keyframes of the same size don't start segments without a
+	// MaxSegmentDuration, a new size does
+	for i, frame := range [][]byte{vp8VGA, interframe, vp8VGA, interframe, vp8QVGA, interframe} {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Timestamp: uint32(i * 3000), Payload: frame}))
+	}
+	saver.Close()
+
+	assert.Equal(t, []string{"rec-0.webm", "rec-1.webm"}, names)
+	for name, width := range map[string]uint64{"rec-0.webm": 640, "rec-1.webm": 320} {
+		var header Header
+		assert.NoError(t, ebml.Unmarshal(bytes.NewReader(files[name].buf.Bytes()), &header))
+		if assert.Len(t, header.Segment.Tracks.TrackEntry, 1, name) {
+			assert.Equal(t, width, header.Segment.Tracks.TrackEntry[0].Video.PixelWidth, name)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package elements
+
+import (
+	"encoding/json"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// Metadata describes a recording, written as a JSON sidecar by savers
+// configured with one so post-processing can line it up with events of
+// the application. Times are wall-clock, of capture when known.
+type Metadata struct {
+	Session    string   `json:"sid,omitempty"`
+	Tracks     []string `json:"tracks"`
+	AudioCodec string   `json:"audioCodec,omitempty"`
+	VideoCodec string   `json:"videoCodec,omitempty"`
+	// Resolutions lists the size of the video from its first frame, and
+	// from each keyframe changing it
+	Resolutions []Resolution `json:"resolutions,omitempty"`
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	// Dropped counts frames received but not recorded, such as video
+	// before its first keyframe or of another codec than the file's
+	Dropped DroppedFrames `json:"dropped"`
+}
+
+// Resolution is the size of the video from Time
+type Resolution struct {
+	Time   time.Time `json:"time"`
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+}
+
+// DroppedFrames counts the frames of each kind not recorded
+type DroppedFrames struct {
+	Audio int `json:"audio"`
+	Video int `json:"video"`
+}
+
+// metadataCodecs names the codecs of sample types in Metadata
+var metadataCodecs = map[int]string{
+	avp.TypeOpus: "opus",
+	avp.TypeVP8:  "vp8",
+	avp.TypeVP9:  "vp9",
+	avp.TypeAV1:  "av1",
+	avp.TypeH264: "h264",
+}
+
+// sidecar collects the Metadata of a recording, written to out on close.
+// A nil sidecar, of a saver configured without one, does nothing.
+type sidecar struct {
+	out  avp.Element
+	meta Metadata
+}
+
+func newSidecar(out avp.Element, rec *recording.Recording) *sidecar {
+	if out == nil {
+		return nil
+	}
+	m := &sidecar{out: out}
+	if rec != nil {
+		m.meta.Session = rec.Status().Session
+	}
+	return m
+}
+
+// recorded notes a sample written to the recording
+func (m *sidecar) recorded(sample *avp.Sample) {
+	if m == nil {
+		return
+	}
+	at := sample.CaptureTime
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if m.meta.Start.IsZero() {
+		m.meta.Start = at
+	}
+	if at.After(m.meta.End) {
+		m.meta.End = at
+	}
+	if sample.ID != "" && !m.track(sample.ID) {
+		m.meta.Tracks = append(m.meta.Tracks, sample.ID)
+	}
+	if sample.Type == avp.TypeOpus {
+		m.meta.AudioCodec = metadataCodecs[sample.Type]
+		return
+	}
+	m.meta.VideoCodec = metadataCodecs[sample.Type]
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return
+	}
+	info, err := frameSize(sample.Type, payload)
+	if err != nil {
+		return
+	}
+	if n := len(m.meta.Resolutions); n == 0 || m.meta.Resolutions[n-1].Width != info.Width || m.meta.Resolutions[n-1].Height != info.Height {
+		m.meta.Resolutions = append(m.meta.Resolutions, Resolution{Time: at, Width: info.Width, Height: info.Height})
+	}
+}
+
+func (m *sidecar) track(id string) bool {
+	for _, t := range m.meta.Tracks {
+		if t == id {
+			return true
+		}
+	}
+	return false
+}
+
+// dropped notes a sample received but not recorded
+func (m *sidecar) dropped(sample *avp.Sample) {
+	if m == nil {
+		return
+	}
+	if sample.Type == avp.TypeOpus {
+		m.meta.Dropped.Audio++
+	} else {
+		m.meta.Dropped.Video++
+	}
+}
+
+// close writes the metadata and closes out
+func (m *sidecar) close() {
+	if m == nil {
+		return
+	}
+	if m.meta.Tracks == nil {
+		m.meta.Tracks = []string{}
+	}
+	b, err := json.Marshal(m.meta)
+	if err == nil {
+		err = m.out.Write(&avp.Sample{Type: TypeBinary, Payload: b})
+	}
+	if err != nil {
+		log.Errorf("writing recording metadata: %s", err)
+	}
+	m.out.Close()
+}
+
+// frameSize returns the frame size of a keyframe, from its header or the
+// sequence parameters it carries
+func frameSize(typ int, payload []byte) (colorspace.Info, error) {
+	switch typ {
+	case avp.TypeVP8:
+		return colorspace.ParseVP8(payload)
+	case avp.TypeVP9:
+		return colorspace.ParseVP9(payload)
+	case avp.TypeAV1:
+		return colorspace.ParseAV1(payload)
+	case avp.TypeH264:
+		return colorspace.ParseH264(payload)
+	}
+	return colorspace.Info{}, colorspace.ErrNotKeyframe
+}
@@ -0,0 +1,68 @@
+package elements
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSidecar_Webm(t *testing.T) {
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "rec.webm")
+	meta := NewBufWriter()
+	saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Video: true, Recording: rec, Sidecar: meta})
+	saver.Attach(NewBufWriter())
+
+	interframe := []byte{0x51, 0x42, 0x00, 0x00}
+	t0 := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	video := func(ms int, payload []byte) {
+		assert.NoError(t, saver.Write(&avp.Sample{ID: "video", Type: avp.TypeVP8, Timestamp: uint32(ms * 90), CaptureTime: t0.Add(time.Duration(ms) * time.Millisecond), Payload: payload}))
+	}
+	audio := func(ms int) {
+		assert.NoError(t, saver.Write(&avp.Sample{ID: "audio", Type: avp.TypeOpus, Timestamp: uint32(ms * 48), CaptureTime: t0.Add(time.Duration(ms) * time.Millisecond), Payload: rawOpusPkt}))
+	}
+	// Nothing is recorded before the first keyframe, then 640x480 video
+	// changes to 320x240
+	video(0, interframe)
+	audio(0)
+	video(100, vp8VGA)
+	audio(100)
+	video(200, interframe)
+	video(1000, vp8QVGA)
+	audio(1020)
+	saver.Close()
+
+	var m Metadata
+	assert.NoError(t, json.Unmarshal(meta.buf.Bytes(), &m))
+	assert.Equal(t, Metadata{
+		Session:    "sid",
+		Tracks:     []string{"video", "audio"},
+		AudioCodec: "opus",
+		VideoCodec: "vp8",
+		Resolutions: []Resolution{
+			{Time: t0.Add(100 * time.Millisecond), Width: 640, Height: 480},
+			{Time: t0.Add(time.Second), Width: 320, Height: 240},
+		},
+		Start:   t0.Add(100 * time.Millisecond),
+		End:     t0.Add(1020 * time.Millisecond),
+		Dropped: DroppedFrames{Audio: 1, Video: 1},
+	}, m)
+}
+
+func TestSidecar_Ogg(t *testing.T) {
+	meta := NewBufWriter()
+	saver := NewOggSaver(OggSaverConfig{Sidecar: meta})
+	saver.Attach(NewBufWriter())
+	assert.NoError(t, saver.Write(&avp.Sample{ID: "audio", Type: avp.TypeOpus, Timestamp: 0, Payload: rawOpusPkt}))
+	saver.Close()
+
+	var m Metadata
+	assert.NoError(t, json.Unmarshal(meta.buf.Bytes(), &m))
+	assert.Equal(t, []string{"audio"}, m.Tracks)
+	assert.Equal(t, "opus", m.AudioCodec)
+	assert.Empty(t, m.VideoCodec)
+	assert.False(t, m.Start.IsZero())
+}
@@ -0,0 +1,140 @@
+package elements
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pion/ion-avp/pkg/seal"
+	"github.com/pion/ion-avp/pkg/storage"
+)
+
+// Sink stores the segments and manifests of a stream packaged for HTTP
+// streaming, e.g. in a directory served over HTTP or uploading them to a
+// CDN origin
+type Sink interface {
+	// Put stores a file whole, replacing any of the name
+	Put(name string, data []byte) error
+	Remove(name string) error
+}
+
+// DirSink stores files in a directory
+type DirSink string
+
+// Put writes the file to a temporary one renamed over it, so it is never
+// served half written
+func (d DirSink) Put(name string, data []byte) error {
+	path := filepath.Join(string(d), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Remove deletes the file, if it exists
+func (d DirSink) Remove(name string) error {
+	err := os.Remove(filepath.Join(string(d), name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// StorageSink stores files as objects of a storage backend under a prefix,
+// e.g. the recording's name, like the files of other savers
+type StorageSink struct {
+	store  storage.Storage
+	prefix string
+}
+
+// NewStorageSink returns a sink storing files in store as prefix/name
+func NewStorageSink(store storage.Storage, prefix string) *StorageSink {
+	return &StorageSink{store: store, prefix: prefix}
+}
+
+// Put writes the object whole and finalizes it, aborting it if writing
+// fails, so it is never served half written
+func (s *StorageSink) Put(name string, data []byte) error {
+	name = path.Join(s.prefix, name)
+	w, err := s.store.Open(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		s.store.Abort(name)
+		return err
+	}
+	return s.store.Finalize(name)
+}
+
+// Remove deletes the object, if it exists
+func (s *StorageSink) Remove(name string) error {
+	err := s.store.Delete(path.Join(s.prefix, name))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// SealedSink encrypts the files it stores in another sink with AES-GCM, as
+// an EncryptWriter does recordings, so streamed recordings never reach
+// disk or storage in the clear either. Files are in the format of package
+// seal, so they can't be played from the sink as they are.
+type SealedSink struct {
+	sink Sink
+	key  []byte
+}
+
+// NewSealedSink returns a sink sealing files with key into sink
+func NewSealedSink(sink Sink, key []byte) (*SealedSink, error) {
+	// Checks the key
+	if _, err := seal.NewWriter(ioutil.Discard, key); err != nil {
+		return nil, err
+	}
+	return &SealedSink{sink: sink, key: key}, nil
+}
+
+// Put seals the file whole and stores it
+func (s *SealedSink) Put(name string, data []byte) error {
+	var buf bytes.Buffer
+	w, err := seal.NewWriter(&buf, s.key)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return s.sink.Put(name, buf.Bytes())
+}
+
+// Remove deletes the file
+func (s *SealedSink) Remove(name string) error {
+	return s.sink.Remove(name)
+}
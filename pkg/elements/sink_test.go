@@ -0,0 +1,83 @@
+package elements
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/seal"
+	"github.com/pion/ion-avp/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hls")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sink := DirSink(dir)
+	assert.NoError(t, sink.Put("live/index.m3u8", []byte("a")))
+	assert.NoError(t, sink.Put("live/index.m3u8", []byte("b")))
+	b, err := ioutil.ReadFile(filepath.Join(dir, "live", "index.m3u8"))
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(b))
+	files, err := ioutil.ReadDir(filepath.Join(dir, "live"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	assert.NoError(t, sink.Remove("live/index.m3u8"))
+	assert.NoError(t, sink.Remove("live/index.m3u8"))
+}
+
+func TestStorageSink(t *testing.T) {
+	store := storage.NewMemory()
+	sink := NewStorageSink(store, "rooms/live")
+	assert.NoError(t, sink.Put("index.m3u8", []byte("a")))
+	assert.NoError(t, sink.Put("index.m3u8", []byte("b")))
+	b, err := store.Get("rooms/live/index.m3u8")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(b))
+
+	assert.NoError(t, sink.Remove("index.m3u8"))
+	assert.NoError(t, sink.Remove("index.m3u8"))
+	names, err := store.List("rooms/")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestSealedSink(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	mem := &memSink{files: map[string][]byte{}}
+	sink, err := NewSealedSink(mem, key)
+	assert.NoError(t, err)
+	saver := NewHlsSaver(HlsSaverConfig{Audio: true, SegmentDuration: time.Second, Sink: sink})
+	for i := 0; i < 150; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: opusSilence}))
+	}
+	saver.Close()
+
+	// No file is stored in the clear, and each opens with the key
+	assert.NotEmpty(t, mem.files)
+	for name, data := range mem.files {
+		assert.False(t, bytes.Contains(data, []byte("#EXTM3U")), name)
+		assert.False(t, bytes.Contains(data, []byte("ftyp")), name)
+		r, err := seal.NewReader(bytes.NewReader(data), key)
+		assert.NoError(t, err)
+		_, err = ioutil.ReadAll(r)
+		assert.NoError(t, err, name)
+	}
+	r, err := seal.NewReader(bytes.NewReader(mem.files["index.m3u8"]), key)
+	assert.NoError(t, err)
+	playlist, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Contains(t, string(playlist), "#EXT-X-ENDLIST")
+
+	assert.NoError(t, sink.Remove("index.m3u8"))
+	assert.NotContains(t, mem.files, "index.m3u8")
+	_, err = NewSealedSink(mem, []byte("short"))
+	assert.Equal(t, seal.ErrKeySize, err)
+}
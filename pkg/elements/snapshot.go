@@ -0,0 +1,254 @@
+package elements
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	log "github.com/pion/ion-log"
+)
+
+// Snapshot image formats
+const (
+	SnapshotJPEG = "jpeg"
+	SnapshotPNG  = "png"
+)
+
+// ErrSnapshotFormat is returned by NewSnapshot for formats other than JPEG
+// or PNG
+var ErrSnapshotFormat = errors.New("snapshot format must be jpeg or png")
+
+// Still is an image taken by a Snapshot
+type Still struct {
+	// Image is encoded in Format
+	Image  []byte
+	Format string
+	Width  int
+	Height int
+	// At is the time in the track, from its first frame
+	At          time.Duration
+	CaptureTime time.Time
+}
+
+// SnapshotConfig configures a Snapshot.
+// Interval: Least time between snapshots, by the video's timestamps,
+// defaults to 10s.
+// Format: SnapshotJPEG or SnapshotPNG, defaults to JPEG.
+// Quality: Of JPEG, 1 to 100, defaults to 75.
+// Width and Height: Size snapshots are scaled to. With one of them zero
+// it follows the aspect ratio of the frame, with both zero snapshots are
+// the size of the video.
+// Fit: How frames are fitted to a size of another aspect ratio.
+// OnSnapshot: Optional callback with each snapshot, on the pipeline's
+// goroutine.
+// Decoder: Optional creator of the decoder of encoded video, of which only
+// the keyframes snapshots are taken of are decoded. By default libvpx's,
+// when built with the libvpx tag. Decoded frames can be written instead.
+type SnapshotConfig struct {
+	Interval   time.Duration
+	Format     string
+	Quality    int
+	Width      int
+	Height     int
+	Fit        pixel.Fit
+	OnSnapshot func(Still)
+	Decoder    func() avp.Element
+}
+
+// Snapshot instance
+type Snapshot struct {
+	Node
+	mu      sync.Mutex
+	cfg     SnapshotConfig
+	scaler  *Scaler
+	decoder avp.Element
+	clock   trackClock
+	at      time.Duration // of the frame being decoded
+	next    time.Duration // when the next snapshot is due
+	taken   int
+	closed  bool
+}
+
+// NewSnapshot instance. Snapshot takes a still of a track's video every
+// Interval, e.g. for live preview thumbnails of ongoing sessions. Encoded
+// video is snapshot at its first keyframe once a snapshot is due, so
+// decoding costs a keyframe an interval; decoded YCbCr frames at the first
+// due. Stills go to OnSnapshot and to its children as TypeJPEG or TypePNG
+// samples, such as for a writer of the latest.
+func NewSnapshot(c SnapshotConfig) (*Snapshot, error) {
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	switch c.Format {
+	case "":
+		c.Format = SnapshotJPEG
+	case SnapshotJPEG, SnapshotPNG:
+	default:
+		return nil, ErrSnapshotFormat
+	}
+	if c.Quality <= 0 || c.Quality > 100 {
+		c.Quality = jpeg.DefaultQuality
+	}
+	if c.Decoder == nil {
+		c.Decoder = newVideoDecoder
+	}
+	return &Snapshot{cfg: c, scaler: NewScaler(ScalerConfig{Width: c.Width, Height: c.Height, Fit: c.Fit})}, nil
+}
+
+// Taken returns how many snapshots have been taken
+func (s *Snapshot) Taken() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.taken
+}
+
+func (s *Snapshot) Write(sample *avp.Sample) error {
+	switch sample.Type {
+	case TypeYCbCr:
+		img, ok := sample.Payload.(*image.YCbCr)
+		if !ok || !s.due(sample) {
+			return nil
+		}
+		return s.take(sample, img)
+	case avp.TypeVP8, avp.TypeVP9, avp.TypeAV1, avp.TypeH264:
+		if !keyframe(sample) || !s.due(sample) {
+			return nil
+		}
+		d, err := s.decoderOf()
+		if err != nil {
+			log.Warnf("snapshot: %s", err)
+		}
+		if d == nil {
+			return nil
+		}
+		return d.Write(sample)
+	}
+	return nil
+}
+
+// due reports whether a snapshot is due at a frame
+func (s *Snapshot) due(sample *avp.Sample) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.at = s.clock.since(sample, videoClockRate)
+	return s.at >= s.next
+}
+
+// decoderOf returns the decoder of the video, created with the first
+// keyframe
+func (s *Snapshot) decoderOf() (avp.Element, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.decoder == nil && !s.closed {
+		if s.cfg.Decoder == nil {
+			s.closed = true
+			return nil, ErrNoVideoDecoder
+		}
+		if s.decoder = s.cfg.Decoder(); s.decoder == nil {
+			s.closed = true
+			return nil, ErrNoVideoDecoder
+		}
+		s.decoder.Attach(&snapshotFrames{s})
+	}
+	return s.decoder, nil
+}
+
+// take encodes a still of a frame due, sending it to the callback and the
+// children
+func (s *Snapshot) take(sample *avp.Sample, img *image.YCbCr) error {
+	s.mu.Lock()
+	if s.closed || s.at < s.next {
+		s.mu.Unlock()
+		return nil
+	}
+	still, err := s.encode(img)
+	if err != nil {
+		s.mu.Unlock()
+		log.Warnf("snapshot: %s", err)
+		return nil
+	}
+	still.At, still.CaptureTime = s.at, sample.CaptureTime
+	s.next = s.at + s.cfg.Interval
+	s.taken++
+	s.mu.Unlock()
+
+	if s.cfg.OnSnapshot != nil {
+		s.cfg.OnSnapshot(still)
+	}
+	typ := TypeJPEG
+	if still.Format == SnapshotPNG {
+		typ = TypePNG
+	}
+	return s.Node.Write(&avp.Sample{
+		ID:          sample.ID,
+		Type:        typ,
+		Timestamp:   sample.Timestamp,
+		ClockRate:   sample.ClockRate,
+		CaptureTime: sample.CaptureTime,
+		Payload:     still.Image,
+	})
+}
+
+// encode returns a still of img at the configured size
+func (s *Snapshot) encode(img *image.YCbCr) (Still, error) {
+	w, h := s.scaler.size(img.Rect.Dx(), img.Rect.Dy())
+	if w == 0 || h == 0 {
+		return Still{}, errors.New("empty frame")
+	}
+	if w != img.Rect.Dx() || h != img.Rect.Dy() {
+		m := image.NewYCbCr(image.Rect(0, 0, w, h), img.SubsampleRatio)
+		if err := pixel.ScaleFit(m, img, s.cfg.Fit); err != nil {
+			return Still{}, err
+		}
+		img = m
+	}
+	buf := &bytes.Buffer{}
+	var err error
+	if s.cfg.Format == SnapshotPNG {
+		err = png.Encode(buf, img)
+	} else {
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: s.cfg.Quality})
+	}
+	if err != nil {
+		return Still{}, err
+	}
+	return Still{Image: buf.Bytes(), Format: s.cfg.Format, Width: w, Height: h}, nil
+}
+
+// Close closes the decoder and the children
+func (s *Snapshot) Close() {
+	s.mu.Lock()
+	s.closed = true
+	d := s.decoder
+	s.decoder = nil
+	s.mu.Unlock()
+	if d != nil {
+		d.Close()
+	}
+	s.Node.Close()
+}
+
+// snapshotFrames takes the decoded keyframes of a Snapshot
+type snapshotFrames struct {
+	s *Snapshot
+}
+
+func (f *snapshotFrames) Write(sample *avp.Sample) error {
+	if img, ok := sample.Payload.(*image.YCbCr); ok {
+		return f.s.take(sample, img)
+	}
+	return nil
+}
+
+func (f *snapshotFrames) Attach(e avp.Element) {}
+
+func (f *snapshotFrames) Close() {}
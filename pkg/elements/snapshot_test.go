@@ -0,0 +1,71 @@
+package elements
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	var stills []Still
+	s, err := NewSnapshot(SnapshotConfig{Width: 32, OnSnapshot: func(still Still) { stills = append(stills, still) }})
+	assert.NoError(t, err)
+	rec := &sampleRecorder{}
+	s.Attach(rec)
+
+	for _, sec := range []uint32{0, 1, 5, 10, 12, 21} {
+		assert.NoError(t, s.Write(&avp.Sample{Type: TypeYCbCr, Timestamp: sec * 90000, Payload: lumaFrame(100)}))
+	}
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	s.Close()
+
+	assert.Equal(t, 3, s.Taken())
+	if assert.Len(t, stills, 3) {
+		assert.Equal(t, []time.Duration{0, 10 * time.Second, 21 * time.Second}, []time.Duration{stills[0].At, stills[1].At, stills[2].At})
+		assert.Equal(t, SnapshotJPEG, stills[0].Format)
+		m, err := jpeg.Decode(bytes.NewReader(stills[0].Image))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 32, 18), m.Bounds())
+	}
+	if assert.Len(t, rec.samples, 3) {
+		assert.Equal(t, TypeJPEG, rec.samples[1].Type)
+		assert.Equal(t, stills[1].Image, rec.samples[1].Payload)
+	}
+
+	_, err = NewSnapshot(SnapshotConfig{Format: "gif"})
+	assert.Equal(t, ErrSnapshotFormat, err)
+}
+
+func TestSnapshotKeyframes(t *testing.T) {
+	decoded := 0
+	s, err := NewSnapshot(SnapshotConfig{Interval: 1500 * time.Millisecond, Format: SnapshotPNG, Decoder: func() avp.Element {
+		d := &lumaDecoder{}
+		d.Attach(&Map{fn: func(sample *avp.Sample) *avp.Sample {
+			decoded++
+			return sample
+		}})
+		return d
+	}})
+	assert.NoError(t, err)
+	rec := &sampleRecorder{}
+	s.Attach(rec)
+
+	// Only keyframes due are decoded
+	for i, payload := range [][]byte{{0x10}, {0x11}, {0x20}, {0x21}, {0x30}} {
+		assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeVP8, Timestamp: uint32(i * 45000), Payload: payload}))
+	}
+	s.Close()
+	assert.Equal(t, 2, decoded)
+	if assert.Len(t, rec.samples, 2) {
+		assert.Equal(t, TypePNG, rec.samples[1].Type)
+		m, err := png.Decode(bytes.NewReader(rec.samples[1].Payload.([]byte)))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 64, 36), m.Bounds())
+	}
+}
@@ -0,0 +1,311 @@
+package elements
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+	log "github.com/pion/ion-log"
+)
+
+// silentLevel is the -dBov level of silence
+const silentLevel = 127
+
+// SpeakerChange is the payload of TypeSpeaker samples, sent when the
+// active speaker changes
+type SpeakerChange struct {
+	// Speaker is the audio track of the new speaker
+	Speaker string
+	// Previous is the audio track of the last speaker, empty for the first
+	Previous string
+	// Tracks of the new speaker, e.g. their video as well as their audio
+	Tracks []string
+	// Level of the new speaker's audio in -dBov, 0 being the loudest
+	Level float64
+}
+
+// AudioLevelConfig configures an AudioLevelDetector.
+// Threshold: Level in -dBov audio must be louder than, i.e. below, to be
+// speech, defaults to 50.
+// Hold: How long another source must be the loudest before it becomes the
+// speaker, so short interruptions don't switch, defaults to 1s.
+// Smoothing: Time over which levels are averaged, defaults to 300ms.
+// Tracks: Optional lookup of the tracks of the participant of an audio
+// track, named in speaker changes so their video can be highlighted. By
+// default just the audio track.
+// Decoder: Optional creator of the decoder of audio arriving without the
+// ssrc-audio-level header extension, which is measured decoded. By default
+// libopus, when built with the libopus tag. Without one, such audio is
+// ignored.
+type AudioLevelConfig struct {
+	Threshold float64
+	Hold      time.Duration
+	Smoothing time.Duration
+	Tracks    func(tid string) []string
+	Decoder   func(channels int) (OpusPCMDecoder, error)
+}
+
+// AudioLevelDetector instance
+type AudioLevelDetector struct {
+	Node
+	mu        sync.Mutex
+	cfg       AudioLevelConfig
+	sources   map[string]*levelSource
+	speaker   *SpeakerChange
+	candidate string
+	since     time.Time // when the candidate became the loudest
+	closed    bool
+	now       func() time.Time
+}
+
+// NewAudioLevelDetector instance. AudioLevelDetector measures the level of
+// each track's audio, from the levels senders put in the ssrc-audio-level
+// RTP header extension or by decoding it, and writes a TypeSpeaker sample
+// to its children whenever the active speaker changes, e.g. for a
+// VideoCompositor to highlight them. Samples written to it are passed on
+// to its children; those of sources joined as a room are only measured.
+func NewAudioLevelDetector(c AudioLevelConfig) *AudioLevelDetector {
+	if c.Threshold <= 0 {
+		c.Threshold = 50
+	}
+	if c.Hold <= 0 {
+		c.Hold = time.Second
+	}
+	if c.Smoothing <= 0 {
+		c.Smoothing = 300 * time.Millisecond
+	}
+	if c.Tracks == nil {
+		c.Tracks = func(tid string) []string { return []string{tid} }
+	}
+	if c.Decoder == nil {
+		c.Decoder = newOpusDecoder
+	}
+	return &AudioLevelDetector{
+		cfg:     c,
+		sources: make(map[string]*levelSource),
+		now:     time.Now,
+	}
+}
+
+// Speaker returns the tracks of the active speaker, nil before anyone has
+// spoken. It can be a VideoCompositor's Speaker.
+func (d *AudioLevelDetector) Speaker() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.speaker == nil {
+		return nil
+	}
+	return d.speaker.Tracks
+}
+
+// Join returns the element measuring the audio of a source. Closing it
+// removes the source, as when the participant leaves.
+func (d *AudioLevelDetector) Join(tid string) avp.Element {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := &levelSource{d: d, id: tid, level: silentLevel}
+	if !d.closed {
+		d.sources[tid] = s
+	}
+	return s
+}
+
+// Write measures samples of sources by their ID and passes them on
+func (d *AudioLevelDetector) Write(sample *avp.Sample) error {
+	d.mu.Lock()
+	s := d.sources[sample.ID]
+	d.mu.Unlock()
+	if s == nil {
+		s = d.Join(sample.ID).(*levelSource)
+	}
+	if err := s.Write(sample); err != nil {
+		return err
+	}
+	return d.Node.Write(sample)
+}
+
+// Close closes the sources' decoders and the children
+func (d *AudioLevelDetector) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	sources := d.sources
+	d.sources = make(map[string]*levelSource)
+	d.mu.Unlock()
+
+	for _, s := range sources {
+		s.closeDecoder()
+	}
+	d.Node.Close()
+}
+
+// measured updates the level of a source, returning the speaker change it
+// leads to, if any, without its tracks
+func (d *AudioLevelDetector) measured(s *levelSource, level float64, duration time.Duration) *SpeakerChange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed || d.sources[s.id] != s {
+		return nil
+	}
+	now := d.now()
+	// Sources stopped sending, e.g. by silence suppression, are silent
+	// rather than as loud as their last packet
+	if now.Sub(s.heard) > d.cfg.Smoothing {
+		s.level = silentLevel
+	}
+	alpha := math.Min(1, float64(duration)/float64(d.cfg.Smoothing))
+	s.level += alpha * (level - s.level)
+	s.heard = now
+
+	var loudest *levelSource
+	for _, ls := range d.sources {
+		if ls.level >= d.cfg.Threshold || now.Sub(ls.heard) > d.cfg.Smoothing {
+			continue
+		}
+		if loudest == nil || ls.level < loudest.level {
+			loudest = ls
+		}
+	}
+	if loudest == nil || d.speaker != nil && loudest.id == d.speaker.Speaker {
+		d.candidate = ""
+		return nil
+	}
+	if d.speaker != nil {
+		if loudest.id != d.candidate {
+			d.candidate, d.since = loudest.id, now
+			return nil
+		}
+		if now.Sub(d.since) < d.cfg.Hold {
+			return nil
+		}
+	}
+	change := &SpeakerChange{Speaker: loudest.id, Level: loudest.level}
+	if d.speaker != nil {
+		change.Previous = d.speaker.Speaker
+	}
+	d.speaker, d.candidate = change, ""
+	return change
+}
+
+// levelSource is a source of an AudioLevelDetector
+type levelSource struct {
+	d       *AudioLevelDetector
+	id      string
+	decoder OpusPCMDecoder
+	pcm     []int16
+	level   float64   // smoothed, in -dBov
+	heard   time.Time // when its last sample was measured
+}
+
+func (s *levelSource) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus {
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return nil
+	}
+	duration, err := opus.PacketDuration(payload)
+	if err != nil || duration <= 0 {
+		duration = 20 * time.Millisecond
+	}
+	var level float64
+	if l := sample.AudioLevel; l != nil {
+		level = float64(l.Level)
+	} else if level, ok = s.decode(payload); !ok {
+		return nil
+	}
+
+	change := s.d.measured(s, level, duration)
+	if change == nil {
+		return nil
+	}
+	log.Debugf("audio level detector: %s is speaking", change.Speaker)
+	// Looked up without the lock, as sessions join sources holding their
+	// own
+	tracks := s.d.cfg.Tracks(change.Speaker)
+	s.d.mu.Lock()
+	change.Tracks = tracks
+	s.d.mu.Unlock()
+	at := sample.CaptureTime
+	if at.IsZero() {
+		at = s.d.now()
+	}
+	return s.d.Node.Write(&avp.Sample{
+		ID:          change.Speaker,
+		Type:        TypeSpeaker,
+		CaptureTime: at,
+		Payload:     change,
+	})
+}
+
+// decode returns the level of a packet from its decoded audio
+func (s *levelSource) decode(payload []byte) (float64, bool) {
+	d := s.d
+	d.mu.Lock()
+	if s.decoder == nil {
+		if d.closed || d.cfg.Decoder == nil {
+			d.mu.Unlock()
+			return 0, false
+		}
+		dec, err := d.cfg.Decoder(1)
+		if err != nil {
+			d.mu.Unlock()
+			log.Warnf("audio level detector: decoder of %s: %s", s.id, err)
+			return 0, false
+		}
+		s.decoder = dec
+		s.pcm = make([]int16, opus.MaxFrame)
+	}
+	dec := s.decoder
+	d.mu.Unlock()
+
+	n, err := dec.Decode(payload, s.pcm)
+	if err != nil || n == 0 {
+		return 0, false
+	}
+	return pcmLevel(s.pcm[:n]), true
+}
+
+// pcmLevel returns the level of audio in -dBov, as in the
+// ssrc-audio-level header extension: 0 for a full scale square wave, 127
+// for silence
+func pcmLevel(pcm []int16) float64 {
+	sum := 0.0
+	for _, v := range pcm {
+		sum += float64(v) * float64(v)
+	}
+	rms := math.Sqrt(sum/float64(len(pcm))) / -math.MinInt16
+	if rms == 0 {
+		return silentLevel
+	}
+	return math.Max(0, math.Min(silentLevel, -20*math.Log10(rms)))
+}
+
+func (s *levelSource) Attach(e avp.Element) {}
+
+// Close removes the source
+func (s *levelSource) Close() {
+	d := s.d
+	d.mu.Lock()
+	if d.sources[s.id] == s {
+		delete(d.sources, s.id)
+	}
+	d.mu.Unlock()
+	s.closeDecoder()
+}
+
+func (s *levelSource) closeDecoder() {
+	s.d.mu.Lock()
+	dec := s.decoder
+	s.decoder = nil
+	s.d.mu.Unlock()
+	if dec != nil {
+		dec.Close()
+	}
+}
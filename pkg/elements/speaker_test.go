@@ -0,0 +1,115 @@
+package elements
+
+import (
+	"image/color"
+	"math"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudioLevelDetector(t *testing.T) {
+	d := NewAudioLevelDetector(AudioLevelConfig{
+		Smoothing: 100 * time.Millisecond,
+		Tracks:    func(tid string) []string { return []string{tid, tid + "-video"} },
+		Decoder:   func(int) (OpusPCMDecoder, error) { return levelDecoder{}, nil },
+	})
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	out := &sampleRecorder{}
+	d.Attach(out)
+	c, err := NewVideoCompositor(VideoCompositorConfig{
+		Width:     200,
+		Height:    100,
+		FrameRate: 1,
+		Columns:   2,
+		Rows:      1,
+		Decoder:   func() avp.Element { return &lumaDecoder{} },
+		Encoder:   &sampleRecorder{},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+	d.Attach(c)
+	assert.NoError(t, c.Write(&avp.Sample{ID: "alice-video", Type: TypeYCbCr, Payload: lumaFrame(100)}))
+	assert.NoError(t, c.Write(&avp.Sample{ID: "bob-video", Type: TypeYCbCr, Payload: lumaFrame(100)}))
+	highlight, _, _ := color.RGBToYCbCr(defaultHighlight.Colour.R, defaultHighlight.Colour.G, defaultHighlight.Colour.B)
+	highlighted := func(x int) bool {
+		frame, _ := c.compose(now)
+		return frame.Y[frame.YOffset(x, 50)] == pixel.Limited(color.YCbCr{Y: highlight}).Y
+	}
+	changes := func() []*SpeakerChange {
+		var cs []*SpeakerChange
+		for _, s := range out.samples {
+			if s.Type == TypeSpeaker {
+				cs = append(cs, s.Payload.(*SpeakerChange))
+			}
+		}
+		return cs
+	}
+
+	// 20ms packets at the levels of each
+	alice, bob := d.Join("alice"), d.Join("bob")
+	speak := func(steps int, a, b uint8) {
+		for i := 0; i < steps; i++ {
+			now = now.Add(20 * time.Millisecond)
+			assert.NoError(t, alice.Write(&avp.Sample{Type: avp.TypeOpus, AudioLevel: &avp.AudioLevel{Level: a}, Payload: []byte{0x08}}))
+			assert.NoError(t, bob.Write(&avp.Sample{Type: avp.TypeOpus, AudioLevel: &avp.AudioLevel{Level: b}, Payload: []byte{0x08}}))
+		}
+	}
+
+	// Quiet audio isn't speech
+	speak(10, 60, 127)
+	assert.Nil(t, d.Speaker())
+	// The first speaker is taken at once
+	speak(10, 20, 127)
+	assert.Equal(t, []string{"alice", "alice-video"}, d.Speaker())
+	if cs := changes(); assert.Len(t, cs, 1) {
+		assert.Equal(t, "alice", cs[0].Speaker)
+		assert.Equal(t, "", cs[0].Previous)
+		assert.Less(t, cs[0].Level, 50.0)
+	}
+	assert.True(t, highlighted(1))
+	assert.False(t, highlighted(101))
+
+	// Bob interrupting briefly doesn't switch, talking for longer does
+	speak(20, 127, 10)
+	speak(20, 20, 127)
+	assert.Len(t, changes(), 1)
+	speak(40, 127, 10)
+	assert.Len(t, changes(), 1)
+	speak(20, 127, 10)
+	if cs := changes(); assert.Len(t, cs, 2) {
+		assert.Equal(t, "bob", cs[1].Speaker)
+		assert.Equal(t, "alice", cs[1].Previous)
+		assert.Equal(t, []string{"bob", "bob-video"}, cs[1].Tracks)
+	}
+	assert.False(t, highlighted(1))
+	assert.True(t, highlighted(101))
+	// Bob stays the speaker once everyone's quiet
+	speak(100, 127, 127)
+	assert.Equal(t, []string{"bob", "bob-video"}, d.Speaker())
+
+	// Audio without levels is decoded, and written samples passed on
+	for i := 0; i < 10; i++ {
+		now = now.Add(20 * time.Millisecond)
+		assert.NoError(t, d.Write(&avp.Sample{ID: "carol", Type: avp.TypeOpus, Payload: []byte{0x08}}))
+	}
+	assert.Equal(t, "carol", out.samples[len(out.samples)-1].ID)
+	assert.Equal(t, avp.TypeOpus, out.samples[len(out.samples)-1].Type)
+	d.mu.Lock()
+	assert.Less(t, d.sources["carol"].level, 50.0)
+	d.mu.Unlock()
+
+	alice.Close()
+	d.Close()
+	assert.NoError(t, bob.Write(&avp.Sample{Type: avp.TypeOpus, AudioLevel: &avp.AudioLevel{Level: 10}, Payload: []byte{0x08}}))
+}
+
+func TestPCMLevel(t *testing.T) {
+	assert.Equal(t, 127.0, pcmLevel(make([]int16, 960)))
+	assert.Equal(t, 0.0, pcmLevel([]int16{math.MinInt16, math.MinInt16}))
+	assert.InDelta(t, 6.02, pcmLevel([]int16{-16384, 16384}), 0.01)
+}
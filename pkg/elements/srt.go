@@ -0,0 +1,276 @@
+package elements
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/mpegts"
+	"github.com/pion/ion-avp/pkg/retry"
+	"github.com/pion/ion-avp/pkg/srt"
+	log "github.com/pion/ion-log"
+)
+
+// srtBuffer is how many samples are buffered while sending
+const srtBuffer = 256
+
+// SrtSenderConfig configures SrtSender.
+// Address: The host:port of the receiver to call, or to listen on.
+// Listen: Wait for receivers to call, e.g. for a broadcast facility to
+// pull the feed. One receiver is served at a time.
+// Audio: Send the audio track, Opus or AAC.
+// Video: Send the video track, H.264.
+// AAC: The audio is AAC samples, e.g. from an encoder, rather than Opus.
+// AudioConfig: The AudioSpecificConfig of AAC audio, defaults to 48kHz
+// stereo AAC-LC.
+// Latency: How long the receiver buffers for lost packets to be
+// retransmitted, defaults to 120ms. The larger of the peers' is used.
+// Passphrase: Optional passphrase encrypting the stream with AES, 10 to 79
+// characters. The receiver's must match.
+// KeyLength: Of the AES key, 16, 24 or 32 bytes, defaults to 16.
+// Retry: Backoff between attempts to call the receiver, zero fields using
+// the retry defaults. The sender gives up once the attempts to reconnect
+// run out, or the receiver rejects the passphrase.
+type SrtSenderConfig struct {
+	Address     string
+	Listen      bool
+	Audio       bool
+	Video       bool
+	AAC         bool
+	AudioConfig []byte
+	Latency     time.Duration
+	Passphrase  string
+	KeyLength   int
+	Retry       retry.Policy
+}
+
+// srtWriter writes a transport stream to a connection, seven packets at a
+// time
+type srtWriter struct {
+	conn *srt.Conn
+}
+
+func (w *srtWriter) Write(b []byte) (int, error) {
+	for i := 0; i < len(b); i += srt.PayloadSize {
+		end := i + srt.PayloadSize
+		if end > len(b) {
+			end = len(b)
+		}
+		if _, err := w.conn.Write(b[i:end]); err != nil {
+			return i, err
+		}
+	}
+	return len(b), nil
+}
+
+// SrtSender instance
+type SrtSender struct {
+	cfg      SrtSenderConfig
+	srt      srt.Config
+	listener *srt.Listener
+	retry    *retry.Retrier
+	samples  chan *avp.Sample
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	close    sync.Once
+	// resync is set when video is dropped, so sending waits for a keyframe
+	resync int32
+
+	// Of the sending goroutine
+	w            srtWriter
+	mux          *mpegts.Muxer
+	audio, video liveTrack
+	keyed        bool // video sent from a keyframe
+}
+
+// NewSrtSender instance. SrtSender muxes H.264 video and Opus or AAC audio
+// into an MPEG transport stream and sends it over SRT, calling a receiver
+// or listening for one to call, for broadcast contribution. Callers
+// reconnect with backoff when the connection drops, and listeners wait for
+// the next receiver. Samples are sent in the background, ones written
+// faster than they can be sent being dropped.
+func NewSrtSender(c SrtSenderConfig) (*SrtSender, error) {
+	s := &SrtSender{
+		cfg:     c,
+		srt:     srt.Config{Latency: c.Latency, Passphrase: c.Passphrase, KeyLength: c.KeyLength},
+		samples: make(chan *avp.Sample, srtBuffer),
+		done:    make(chan struct{}),
+	}
+	mc := mpegts.Config{}
+	if c.Video {
+		mc.Video = mpegts.H264
+	}
+	if c.Audio {
+		mc.Audio = mpegts.Opus
+		if c.AAC {
+			mc.Audio, mc.AudioConfig = mpegts.AAC, c.AudioConfig
+			if mc.AudioConfig == nil {
+				mc.AudioConfig = aacLC48kStereo
+			}
+		}
+	}
+	if err := s.srt.Check(); err != nil {
+		return nil, err
+	}
+	var err error
+	if s.mux, err = mpegts.NewMuxer(&s.w, mc); err != nil {
+		return nil, err
+	}
+	if c.Listen {
+		if s.listener, err = srt.Listen(c.Address, s.srt); err != nil {
+			return nil, err
+		}
+	} else {
+		// Attempts run out rather than the circuit opening, as nothing
+		// else calls the receiver
+		c.Retry.Threshold = -1
+		s.retry = retry.New("srt-"+c.Address, c.Retry)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.run()
+	return s, nil
+}
+
+// Addr returns the address a listening sender listens on
+func (s *SrtSender) Addr() string {
+	if s.listener == nil {
+		return s.cfg.Address
+	}
+	return s.listener.Addr().String()
+}
+
+func (s *SrtSender) Write(sample *avp.Sample) error {
+	switch {
+	case sample.Type == avp.TypeH264 && s.cfg.Video:
+	case sample.Type == avp.TypeOpus && s.cfg.Audio && !s.cfg.AAC:
+	case sample.Type == avp.TypeAAC && s.cfg.Audio && s.cfg.AAC:
+	default:
+		return nil
+	}
+	// Payloads are sent later, so mustn't be reused meanwhile
+	cp := *sample
+	cp.Payload = append([]byte(nil), sample.Payload.([]byte)...)
+	select {
+	case s.samples <- &cp:
+	default:
+		log.Debugf("SRT sender: dropping sample, sending is behind")
+		if sample.Type == avp.TypeH264 {
+			atomic.StoreInt32(&s.resync, 1)
+		}
+	}
+	return nil
+}
+
+// Attach isn't supported, the stream is sent to the receiver
+func (s *SrtSender) Attach(e avp.Element) {
+	log.Warnf("SrtSender sends to its receiver")
+}
+
+// Close ends the stream
+func (s *SrtSender) Close() {
+	s.close.Do(func() {
+		s.cancel()
+		<-s.done
+		if s.listener != nil {
+			s.listener.Close()
+		}
+	})
+}
+
+// run connects and sends samples until closed
+func (s *SrtSender) run() {
+	defer close(s.done)
+	for {
+		conn, err := s.connect()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				log.Errorf("SRT sender: giving up connecting: %s", err)
+			}
+			return
+		}
+		log.Infof("SRT sender connected to %s, latency %s", s.cfg.Address, conn.Latency())
+
+		err = s.stream(conn)
+		conn.Close()
+		if s.ctx.Err() != nil {
+			return
+		}
+		log.Warnf("SRT sender: connection ended, reconnecting: %s", err)
+	}
+}
+
+// connect calls the receiver, or waits for one to call
+func (s *SrtSender) connect() (*srt.Conn, error) {
+	var conn *srt.Conn
+	var err error
+	if s.listener != nil {
+		conn, err = s.listener.Accept(s.ctx)
+	} else {
+		err = s.retry.Do(s.ctx, func() error {
+			var err error
+			conn, err = srt.Dial(s.ctx, s.cfg.Address, s.srt)
+			if errors.Is(err, srt.ErrSecret) || errors.Is(err, srt.ErrRejected) {
+				return retry.Permanent(err)
+			}
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.w.conn = conn
+	s.audio.started, s.video.started = false, false
+	s.keyed = false
+	// Samples queued while connecting are stale
+	for {
+		select {
+		case <-s.samples:
+		default:
+			return conn, nil
+		}
+	}
+}
+
+// stream sends samples until the connection ends or the sender closes
+func (s *SrtSender) stream(conn *srt.Conn) error {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case <-conn.Done():
+			return conn.Err()
+		case sample := <-s.samples:
+			if err := s.send(sample); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send muxes a sample, the stream starting with a video keyframe
+func (s *SrtSender) send(sample *avp.Sample) error {
+	payload := sample.Payload.([]byte)
+	if sample.Type == avp.TypeH264 {
+		d := s.video.clock.since(sample, videoClockRate)
+		if atomic.CompareAndSwapInt32(&s.resync, 1, 0) {
+			s.keyed = false
+		}
+		if !s.keyed {
+			if !readAVC(payload).key {
+				return nil
+			}
+			s.keyed = true
+		}
+		return s.mux.WriteVideo(s.video.at(d), payload)
+	}
+
+	d := s.audio.clock.since(sample, audioClockRate)
+	if s.cfg.Video && !s.keyed {
+		return nil
+	}
+	return s.mux.WriteAudio(s.audio.at(d), payload)
+}
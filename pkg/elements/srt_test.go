@@ -0,0 +1,58 @@
+package elements
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/mpegts"
+	"github.com/pion/ion-avp/pkg/srt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSrtSender_Listen(t *testing.T) {
+	s, err := NewSrtSender(SrtSenderConfig{
+		Address:    "127.0.0.1:0",
+		Listen:     true,
+		Audio:      true,
+		Video:      true,
+		Latency:    200 * time.Millisecond,
+		Passphrase: "passphrase1",
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = srt.Dial(ctx, s.Addr(), srt.Config{Passphrase: "passphrase2"})
+	assert.Equal(t, srt.ErrSecret, err)
+	conn, err := srt.Dial(ctx, s.Addr(), srt.Config{Passphrase: "passphrase1"})
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, 200*time.Millisecond, conn.Latency())
+
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeH264, Payload: []byte{0, 0, 0, 1, 0x65, 1}}))
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	// AAC isn't sent when the audio is Opus
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeAAC, Payload: []byte{1}}))
+
+	s.Close()
+	select {
+	case <-conn.Done():
+		assert.Equal(t, srt.ErrClosed, conn.Err())
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not ended")
+	}
+}
+
+func TestSrtSender_Config(t *testing.T) {
+	_, err := NewSrtSender(SrtSenderConfig{Address: "127.0.0.1:0", Listen: true})
+	assert.True(t, errors.Is(err, mpegts.ErrConfig))
+	_, err = NewSrtSender(SrtSenderConfig{Address: "127.0.0.1:0", Video: true, Passphrase: "short"})
+	assert.True(t, errors.Is(err, srt.ErrConfig))
+	_, err = NewSrtSender(SrtSenderConfig{Address: "127.0.0.1:0", Audio: true, AAC: true, AudioConfig: []byte{0xff, 0xff}})
+	assert.True(t, errors.Is(err, mpegts.ErrConfig))
+}
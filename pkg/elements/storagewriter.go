@@ -0,0 +1,107 @@
+package elements
+
+import (
+	"io"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/storage"
+	log "github.com/pion/ion-log"
+)
+
+// StorageWriter instance
+type StorageWriter struct {
+	Leaf
+	store   storage.Storage
+	name    string
+	wr      io.WriteCloser
+	rec     *recording.Recording
+	expires time.Duration
+	err     error
+}
+
+// NewStorageWriter instance. StorageWriter writes the byte
+// stream it receives to the named object in store. The object is
+// aborted, discarding what was stored of it, if writing it fails.
+func NewStorageWriter(store storage.Storage, name string) *StorageWriter {
+	wr, err := store.Open(name)
+	if err != nil {
+		log.Errorf("error initializing storagewriter: %s", err)
+		return nil
+	}
+
+	log.Infof("StorageWriter opened %s", name)
+	return &StorageWriter{
+		store: store,
+		name:  name,
+		wr:    wr,
+	}
+}
+
+// SetRecording sets the recording moved to Uploading while the object is
+// written out to storage, and to Complete or Failed after
+func (w *StorageWriter) SetRecording(r *recording.Recording) {
+	w.rec = r
+}
+
+// SignURL sets how long the signed download url of the object, reported
+// in the recording's status once complete, is valid for
+func (w *StorageWriter) SignURL(expires time.Duration) {
+	w.expires = expires
+}
+
+func (w *StorageWriter) Write(sample *avp.Sample) error {
+	if w.err != nil {
+		return w.err
+	}
+	if _, err := w.wr.Write(sample.Payload.([]byte)); err != nil {
+		log.Errorf("StorageWriter error writing %s: %s", w.name, err)
+		w.err = err
+		w.wr.Close()
+		w.abort(err)
+		return err
+	}
+	return nil
+}
+
+// abort discards the object and fails the recording
+func (w *StorageWriter) abort(err error) {
+	w.rec.Fail(err)
+	if err := w.store.Abort(w.name); err != nil {
+		log.Warnf("StorageWriter error aborting %s: %s", w.name, err)
+	}
+}
+
+func (w *StorageWriter) Close() {
+	if w.err != nil {
+		return
+	}
+	setState(w.rec, recording.StateFinalizing)
+	setState(w.rec, recording.StateUploading)
+	if err := w.wr.Close(); err != nil {
+		log.Errorf("StorageWriter error closing %s: %s", w.name, err)
+		w.abort(err)
+		return
+	}
+	if err := w.store.Finalize(w.name); err != nil {
+		log.Errorf("StorageWriter error finalizing %s: %s", w.name, err)
+		w.abort(err)
+		return
+	}
+	if w.expires > 0 {
+		u, err := storage.SignURL(w.store, w.name, w.expires)
+		if err != nil {
+			log.Warnf("StorageWriter error signing url of %s: %s", w.name, err)
+		}
+		w.rec.SetURL(u)
+	}
+	setState(w.rec, recording.StateComplete)
+	log.Infof("StorageWriter closed %s", w.name)
+}
+
+func setState(r *recording.Recording, state recording.State) {
+	if err := r.Set(state); err != nil {
+		log.Warnf("%s", err)
+	}
+}
@@ -0,0 +1,64 @@
+package elements
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// brokenStorage fails writes after the first
+type brokenStorage struct {
+	*storage.Memory
+}
+
+func (s brokenStorage) Open(name string) (io.WriteCloser, error) {
+	w, err := s.Memory.Open(name)
+	return &brokenWriter{WriteCloser: w}, err
+}
+
+type brokenWriter struct {
+	io.WriteCloser
+	n int
+}
+
+func (w *brokenWriter) Write(p []byte) (int, error) {
+	if w.n++; w.n > 1 {
+		return 0, errors.New("disk full")
+	}
+	return w.WriteCloser.Write(p)
+}
+
+func TestStorageWriter(t *testing.T) {
+	store := storage.NewMemory()
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "a.webm")
+	w := NewStorageWriter(store, "sid/a.webm")
+	w.SetRecording(rec)
+	assert.NoError(t, w.Write(&avp.Sample{Payload: []byte("data")}))
+	w.Close()
+
+	assert.Equal(t, recording.StateComplete, rec.State())
+	b, err := store.Get("sid/a.webm")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(b))
+}
+
+func TestStorageWriter_Abort(t *testing.T) {
+	store := brokenStorage{storage.NewMemory()}
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "a.webm")
+	w := NewStorageWriter(store, "sid/a.webm")
+	w.SetRecording(rec)
+	assert.NoError(t, w.Write(&avp.Sample{Payload: []byte("data")}))
+	assert.Error(t, w.Write(&avp.Sample{Payload: []byte("more")}))
+	assert.Error(t, w.Write(&avp.Sample{Payload: []byte("more")}))
+	w.Close()
+
+	assert.Equal(t, recording.StateFailed, rec.State())
+	names, err := store.List("")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
@@ -0,0 +1,302 @@
+package elements
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	log "github.com/pion/ion-log"
+)
+
+// StoryboardConfig configures a Storyboard.
+// Interval: Least time between thumbnails, by the video's timestamps,
+// defaults to 5s.
+// Width: Of each thumbnail, defaults to 160. Its height keeps the aspect
+// ratio of the video.
+// Columns: Of thumbnails in the sprite sheet, defaults to 10.
+// MaxTiles: Most thumbnails kept, defaults to 100. Once a recording has
+// as many, every other one is dropped and the interval doubled, so sprite
+// sheets of long recordings stay a size players load.
+// Quality: Of the JPEG sprite sheet, 1 to 100, defaults to 75.
+// Sprite: URL of the sprite sheet in the thumbnail track, relative to the
+// track, defaults to "storyboard.jpg".
+// Epoch: Optional session start the recording is aligned to, as the
+// WebmSaver's, so cues are timed as its file is. Otherwise they're timed
+// from the track's first frame.
+// Decoder: Optional creator of the decoder of encoded video, of which only
+// the keyframes thumbnails are taken of are decoded. By default libvpx's,
+// when built with the libvpx tag. Decoded frames can be written instead.
+type StoryboardConfig struct {
+	Interval time.Duration
+	Width    int
+	Columns  int
+	MaxTiles int
+	Quality  int
+	Sprite   string
+	Epoch    time.Time
+	Decoder  func() avp.Element
+}
+
+// storyboardTile is a thumbnail of the sprite sheet
+type storyboardTile struct {
+	at    time.Duration
+	frame *image.YCbCr
+}
+
+// Storyboard instance
+type Storyboard struct {
+	Node
+	mu       sync.Mutex
+	cfg      StoryboardConfig
+	track    string
+	sprite   func() (io.WriteCloser, error)
+	vtt      func() (io.WriteCloser, error)
+	decoder  avp.Element
+	clock    trackClock
+	offset   time.Duration // of the first frame from the epoch
+	started  bool
+	at       time.Duration // of the frame being decoded
+	end      time.Duration // of the last frame
+	next     time.Duration // when the next thumbnail is due
+	interval time.Duration
+	height   int
+	tiles    []storyboardTile
+	closed   bool
+}
+
+// NewStoryboard instance. Storyboard passes samples to its children, such
+// as a WebmSaver, while keeping small thumbnails of the video every
+// Interval. On close it writes them as a JPEG sprite sheet, with sprite,
+// and a WebVTT thumbnail track of which part of the sheet shows each span
+// of the recording, with vtt, which video players use for seek previews.
+// As it's made while recording, there's no second pass over the file.
+// Encoded video is taken at its first keyframe once a thumbnail is due, so
+// decoding costs a keyframe an interval; decoded YCbCr frames at the first
+// due.
+func NewStoryboard(c StoryboardConfig, track string, sprite, vtt func() (io.WriteCloser, error)) *Storyboard {
+	if c.Interval <= 0 {
+		c.Interval = 5 * time.Second
+	}
+	if c.Width <= 0 {
+		c.Width = 160
+	}
+	c.Width = (c.Width + 1) &^ 1
+	if c.Columns <= 0 {
+		c.Columns = 10
+	}
+	if c.MaxTiles <= 1 {
+		c.MaxTiles = 100
+	}
+	if c.Quality <= 0 || c.Quality > 100 {
+		c.Quality = jpeg.DefaultQuality
+	}
+	if c.Sprite == "" {
+		c.Sprite = "storyboard.jpg"
+	}
+	if c.Decoder == nil {
+		c.Decoder = newVideoDecoder
+	}
+	return &Storyboard{cfg: c, track: track, sprite: sprite, vtt: vtt, interval: c.Interval}
+}
+
+func (s *Storyboard) Write(sample *avp.Sample) error {
+	switch sample.Type {
+	case TypeYCbCr, avp.TypeVP8, avp.TypeVP9, avp.TypeAV1, avp.TypeH264:
+		if err := s.video(sample); err != nil {
+			log.Warnf("Storyboard of %s: %s", s.track, err)
+		}
+	}
+	return s.Node.Write(sample)
+}
+
+func (s *Storyboard) video(sample *avp.Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.at = s.timeline(sample)
+	if s.at > s.end {
+		s.end = s.at
+	}
+	if s.at < s.next {
+		return nil
+	}
+	if img, ok := sample.Payload.(*image.YCbCr); ok {
+		s.frame(img)
+		return nil
+	}
+	if !keyframe(sample) {
+		return nil
+	}
+	if s.decoder == nil {
+		if s.cfg.Decoder == nil {
+			s.closed = true
+			return ErrNoVideoDecoder
+		}
+		if s.decoder = s.cfg.Decoder(); s.decoder == nil {
+			s.closed = true
+			return ErrNoVideoDecoder
+		}
+		s.decoder.Attach(&storyboardFrames{s})
+	}
+	return s.decoder.Write(sample)
+}
+
+// timeline returns the time of a frame in the recording
+func (s *Storyboard) timeline(sample *avp.Sample) time.Duration {
+	if !s.started {
+		s.started = true
+		if !s.cfg.Epoch.IsZero() && !sample.CaptureTime.IsZero() {
+			if s.offset = sample.CaptureTime.Sub(s.cfg.Epoch); s.offset < 0 {
+				s.offset = 0
+			}
+		}
+	}
+	return s.offset + s.clock.since(sample, videoClockRate)
+}
+
+// frame keeps a thumbnail of a frame due. It's called with the lock held,
+// from the decoder.
+func (s *Storyboard) frame(img *image.YCbCr) {
+	if s.at < s.next {
+		return
+	}
+	thumb := s.scale(img)
+	if thumb == nil {
+		return
+	}
+	s.tiles = append(s.tiles, storyboardTile{at: s.at, frame: thumb})
+	if len(s.tiles) >= s.cfg.MaxTiles {
+		// Every other one, twice as far apart
+		kept := s.tiles[:0]
+		for i := 0; i < len(s.tiles); i += 2 {
+			kept = append(kept, s.tiles[i])
+		}
+		for i := len(kept); i < len(s.tiles); i++ {
+			s.tiles[i] = storyboardTile{}
+		}
+		s.tiles = kept
+		s.interval *= 2
+	}
+	s.next = s.tiles[len(s.tiles)-1].at + s.interval
+}
+
+// scale returns img at the thumbnails' size, the first frame setting their
+// aspect ratio
+func (s *Storyboard) scale(img *image.YCbCr) *image.YCbCr {
+	if img.Rect.Empty() {
+		return nil
+	}
+	if s.height == 0 {
+		s.height = (s.cfg.Width*img.Rect.Dy()/img.Rect.Dx() + 1) &^ 1
+		if s.height < 2 {
+			s.height = 2
+		}
+	}
+	m := image.NewYCbCr(image.Rect(0, 0, s.cfg.Width, s.height), image.YCbCrSubsampleRatio420)
+	if err := pixel.ScaleFit(m, img, pixel.FitPad); err != nil {
+		return nil
+	}
+	return m
+}
+
+// Close closes the children, then writes the sprite sheet and thumbnail
+// track
+func (s *Storyboard) Close() {
+	s.Node.Close()
+	s.mu.Lock()
+	if s.closed && s.decoder == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	if s.decoder != nil {
+		s.decoder.Close()
+		s.decoder = nil
+	}
+	tiles, end := s.tiles, s.end
+	s.tiles = nil
+	s.mu.Unlock()
+
+	if len(tiles) == 0 {
+		return
+	}
+	if err := s.write(tiles, end); err != nil {
+		log.Errorf("Storyboard error writing storyboard of %s: %s", s.track, err)
+	}
+}
+
+func (s *Storyboard) write(tiles []storyboardTile, end time.Duration) error {
+	w, h := s.cfg.Width, s.height
+	cols := s.cfg.Columns
+	if len(tiles) < cols {
+		cols = len(tiles)
+	}
+	rows := (len(tiles) + cols - 1) / cols
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*w, rows*h))
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for i, t := range tiles {
+		x, y := i%cols*w, i/cols*h
+		draw.Draw(sheet, image.Rect(x, y, x+w, y+h), t.frame, t.frame.Rect.Min, draw.Src)
+
+		// Each shows from its frame until the next's, the first from the
+		// start and the last until the end
+		from, to := t.at, end
+		if i == 0 {
+			from = 0
+		}
+		if i+1 < len(tiles) {
+			to = tiles[i+1].at
+		}
+		if to <= from {
+			to = from + time.Millisecond
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s#xywh=%d,%d,%d,%d\n\n", cueTime(from, '.'), cueTime(to, '.'), s.cfg.Sprite, x, y, w, h)
+	}
+
+	f, err := s.sprite()
+	if err != nil {
+		return err
+	}
+	if err := jpeg.Encode(f, sheet, &jpeg.Options{Quality: s.cfg.Quality}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	f, err = s.vtt()
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// storyboardFrames takes the decoded keyframes of a Storyboard
+type storyboardFrames struct {
+	s *Storyboard
+}
+
+func (f *storyboardFrames) Write(sample *avp.Sample) error {
+	if img, ok := sample.Payload.(*image.YCbCr); ok {
+		f.s.frame(img)
+	}
+	return nil
+}
+
+func (f *storyboardFrames) Attach(e avp.Element) {}
+
+func (f *storyboardFrames) Close() {}
@@ -0,0 +1,93 @@
+package elements
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"io"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func storyboardFiles() (*bytes.Buffer, *bytes.Buffer, func() (io.WriteCloser, error), func() (io.WriteCloser, error)) {
+	sprite, vtt := &bytes.Buffer{}, &bytes.Buffer{}
+	return sprite, vtt,
+		func() (io.WriteCloser, error) { return nopCloser{sprite}, nil },
+		func() (io.WriteCloser, error) { return nopCloser{vtt}, nil }
+}
+
+func TestStoryboard(t *testing.T) {
+	sprite, vtt, openSprite, openVTT := storyboardFiles()
+	s := NewStoryboard(StoryboardConfig{Width: 32, Columns: 3, Sprite: "rec.storyboard.jpg"}, "track", openSprite, openVTT)
+	rec := &sampleRecorder{}
+	s.Attach(rec)
+
+	for _, sec := range []uint32{0, 1, 5, 10, 12, 20, 23} {
+		assert.NoError(t, s.Write(&avp.Sample{Type: TypeYCbCr, Timestamp: sec * 90000, Payload: lumaFrame(100)}))
+	}
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	s.Close()
+
+	// Samples pass through to the saver
+	assert.Len(t, rec.samples, 8)
+
+	m, err := jpeg.Decode(sprite)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 96, 36), m.Bounds())
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:00.000 --> 00:00:05.000\nrec.storyboard.jpg#xywh=0,0,32,18\n\n"+
+		"00:00:05.000 --> 00:00:10.000\nrec.storyboard.jpg#xywh=32,0,32,18\n\n"+
+		"00:00:10.000 --> 00:00:20.000\nrec.storyboard.jpg#xywh=64,0,32,18\n\n"+
+		"00:00:20.000 --> 00:00:23.000\nrec.storyboard.jpg#xywh=0,18,32,18\n\n", vtt.String())
+}
+
+func TestStoryboardKeyframes(t *testing.T) {
+	decoded := 0
+	epoch := time.Unix(1600000000, 0)
+	sprite, vtt, openSprite, openVTT := storyboardFiles()
+	s := NewStoryboard(StoryboardConfig{Interval: time.Second, Width: 16, MaxTiles: 4, Epoch: epoch, Decoder: func() avp.Element {
+		d := &lumaDecoder{}
+		d.Attach(&Map{fn: func(sample *avp.Sample) *avp.Sample {
+			decoded++
+			return sample
+		}})
+		return d
+	}}, "track", openSprite, openVTT)
+
+	// A keyframe and a delta frame a second, from 2s into the session
+	for i := 0; i < 6; i++ {
+		for _, payload := range []byte{0x10, 0x11} {
+			assert.NoError(t, s.Write(&avp.Sample{
+				Type:        avp.TypeVP8,
+				Timestamp:   uint32(i * 90000),
+				CaptureTime: epoch.Add(2*time.Second + time.Duration(i)*time.Second),
+				Payload:     []byte{payload},
+			}))
+		}
+	}
+	s.Close()
+
+	// Only keyframes due are decoded, and of the four kept at 2s to 5s every
+	// other is dropped once there are as many as MaxTiles
+	assert.Equal(t, 5, decoded)
+	m, err := jpeg.Decode(sprite)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 48, 10), m.Bounds())
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:00.000 --> 00:00:04.000\nstoryboard.jpg#xywh=0,0,16,10\n\n"+
+		"00:00:04.000 --> 00:00:06.000\nstoryboard.jpg#xywh=16,0,16,10\n\n"+
+		"00:00:06.000 --> 00:00:07.000\nstoryboard.jpg#xywh=32,0,16,10\n\n", vtt.String())
+}
+
+func TestStoryboardEmpty(t *testing.T) {
+	sprite, vtt, openSprite, openVTT := storyboardFiles()
+	s := NewStoryboard(StoryboardConfig{}, "track", openSprite, openVTT)
+	assert.NoError(t, s.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	s.Close()
+	s.Close()
+	assert.Zero(t, sprite.Len())
+	assert.Zero(t, vtt.Len())
+}
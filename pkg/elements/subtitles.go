@@ -0,0 +1,161 @@
+package elements
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	log "github.com/pion/ion-log"
+)
+
+// Subtitle formats
+const (
+	SubtitleWebVTT = "vtt"
+	SubtitleSRT    = "srt"
+)
+
+// ErrSubtitleFormat is returned by NewSubtitleWriter for formats other than
+// WebVTT or SRT
+var ErrSubtitleFormat = errors.New("subtitle format must be vtt or srt")
+
+// SubtitleWriterConfig configures a SubtitleWriter.
+// Format: SubtitleWebVTT or SubtitleSRT, defaults to WebVTT.
+// Speakers: Mark the speaker of each cue, with a voice span in WebVTT and a
+// "Speaker: " prefix in SRT.
+type SubtitleWriterConfig struct {
+	Format   string
+	Speakers bool
+}
+
+// SubtitleWriter instance
+type SubtitleWriter struct {
+	sync.Mutex
+	cfg          SubtitleWriterConfig
+	sampleWriter *SampleWriter
+	cues         int
+	started      bool
+	closed       bool
+}
+
+// NewSubtitleWriter instance. SubtitleWriter writes the final text of
+// TypeText samples, such as a Transcriber's, as the cues of a WebVTT or SRT
+// file to its children such as a FileWriter. Cues are written as they
+// arrive, so the file is readable as far as it got. Interim text and other
+// samples are left out. Cues are timed as their text is, in its track; as
+// the Subtitles of a WebmSaver they line up with its file instead.
+func NewSubtitleWriter(c SubtitleWriterConfig) (*SubtitleWriter, error) {
+	switch c.Format {
+	case "":
+		c.Format = SubtitleWebVTT
+	case SubtitleWebVTT, SubtitleSRT:
+	default:
+		return nil, ErrSubtitleFormat
+	}
+	return &SubtitleWriter{cfg: c, sampleWriter: NewSampleWriter()}, nil
+}
+
+func (s *SubtitleWriter) Write(sample *avp.Sample) error {
+	if sample.Type != TypeText {
+		return nil
+	}
+	text, ok := sample.Payload.(*Text)
+	if !ok || !text.Final {
+		return nil
+	}
+	lines := cueLines(text.Text)
+	if len(lines) == 0 {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return nil
+	}
+	if err := s.header(); err != nil {
+		return err
+	}
+
+	end := text.End
+	if end < text.Start {
+		end = text.Start
+	}
+	s.cues++
+	var b strings.Builder
+	if s.cfg.Format == SubtitleSRT {
+		if s.cfg.Speakers && text.Speaker != "" {
+			lines[0] = text.Speaker + ": " + lines[0]
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n", s.cues, cueTime(text.Start, ','), cueTime(end, ','))
+	} else {
+		for i, l := range lines {
+			lines[i] = vttEscaper.Replace(l)
+		}
+		if s.cfg.Speakers && text.Speaker != "" {
+			lines[0] = "<v " + vttEscaper.Replace(text.Speaker) + ">" + lines[0]
+		}
+		fmt.Fprintf(&b, "%s --> %s\n", cueTime(text.Start, '.'), cueTime(end, '.'))
+	}
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n\n")
+	_, err := s.sampleWriter.Write([]byte(b.String()))
+	return err
+}
+
+// header writes the header of the file, once
+func (s *SubtitleWriter) header() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	if s.cfg.Format != SubtitleWebVTT {
+		return nil
+	}
+	_, err := s.sampleWriter.Write([]byte("WEBVTT\n\n"))
+	return err
+}
+
+// vttEscaper escapes text for WebVTT cues, where these start markup
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// cueLines returns the lines of text, without the blank lines that would
+// end a cue
+func cueLines(text string) []string {
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// cueTime formats d as hh:mm:ss.ttt, with sep before the milliseconds
+func cueTime(d time.Duration, sep byte) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// Attach attach a child element
+func (s *SubtitleWriter) Attach(e avp.Element) {
+	s.sampleWriter.Attach(e)
+}
+
+// Close writes the header of a file without cues and closes the children
+func (s *SubtitleWriter) Close() {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if err := s.header(); err != nil {
+		log.Errorf("subtitle writer: %s", err)
+	}
+	s.sampleWriter.Close()
+}
@@ -0,0 +1,53 @@
+package elements
+
+import (
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubtitleWriter(t *testing.T) {
+	texts := []*avp.Sample{
+		{Type: TypeText, Payload: &Text{Text: "Hello <all>", Start: 1500 * time.Millisecond, End: 3 * time.Second, Speaker: "Alice", Final: true}},
+		{Type: TypeText, Payload: &Text{Text: "Ho", Start: 3 * time.Second, End: 4 * time.Second, Speaker: "Bob"}},
+		{Type: avp.TypeOpus, Payload: []byte{1}},
+		{Type: TypeText, Payload: &Text{Text: "How are\n\nyou?", Start: time.Hour + 3*time.Second, End: time.Hour + 5*time.Second, Speaker: "Bob", Final: true}},
+		{Type: TypeText, Payload: &Text{Text: " ", Final: true}},
+	}
+
+	s, err := NewSubtitleWriter(SubtitleWriterConfig{Speakers: true})
+	assert.NoError(t, err)
+	out := NewBufWriter()
+	s.Attach(out)
+	for _, sample := range texts {
+		assert.NoError(t, s.Write(sample))
+	}
+	s.Close()
+	s.Close()
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:01.500 --> 00:00:03.000\n<v Alice>Hello &lt;all&gt;\n\n"+
+		"01:00:03.000 --> 01:00:05.000\n<v Bob>How are\nyou?\n\n", out.buf.String())
+
+	s, err = NewSubtitleWriter(SubtitleWriterConfig{Format: SubtitleSRT})
+	assert.NoError(t, err)
+	out = NewBufWriter()
+	s.Attach(out)
+	for _, sample := range texts {
+		assert.NoError(t, s.Write(sample))
+	}
+	s.Close()
+	assert.Equal(t, "1\n00:00:01,500 --> 00:00:03,000\nHello <all>\n\n"+
+		"2\n01:00:03,000 --> 01:00:05,000\nHow are\nyou?\n\n", out.buf.String())
+
+	// An empty WebVTT file is still valid
+	s, _ = NewSubtitleWriter(SubtitleWriterConfig{})
+	out = NewBufWriter()
+	s.Attach(out)
+	s.Close()
+	assert.Equal(t, "WEBVTT\n\n", out.buf.String())
+
+	_, err = NewSubtitleWriter(SubtitleWriterConfig{Format: "ass"})
+	assert.Equal(t, ErrSubtitleFormat, err)
+}
@@ -0,0 +1,26 @@
+package elements
+
+import (
+	"os"
+	"syscall"
+)
+
+// fullSync asks the drive to flush its cache too. On macOS fsync only
+// hands data to the drive, which may reorder or lose it on power loss.
+func fullSync(f *os.File) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), syscall.F_FULLFSYNC, 0)
+	if errno != 0 {
+		// Not supported by every file system, e.g. network mounts
+		return f.Sync()
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return fullSync(d)
+}
@@ -0,0 +1,19 @@
+//go:build !darwin && !windows
+// +build !darwin,!windows
+
+package elements
+
+import "os"
+
+func fullSync(f *os.File) error {
+	return f.Sync()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,14 @@
+package elements
+
+import "os"
+
+// fullSync flushes the file with FlushFileBuffers
+func fullSync(f *os.File) error {
+	return f.Sync()
+}
+
+// syncDir is a no-op, directories can't be opened for syncing on Windows
+// and NTFS journals directory entries itself
+func syncDir(dir string) error {
+	return nil
+}
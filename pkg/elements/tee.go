@@ -0,0 +1,114 @@
+package elements
+
+import (
+	"errors"
+	"fmt"
+
+	avp "github.com/pion/ion-avp/pkg"
+	log "github.com/pion/ion-log"
+)
+
+// ErrTeeOutputs is returned for outputs a Tee can't have
+var ErrTeeOutputs = errors.New("invalid tee outputs")
+
+// EncodeProfile is the settings every output of a Tee is encoded with, so
+// its copies of a recording match.
+// FrameRate: Of the frames written in frames per second, 30 by default.
+// Bitrate: Targeted in bits per second, 1Mbps by default.
+// Encoder: Optional creator of each output's encoder, written YCbCr
+// frames. By default libvpx's VP8 encoder, when built with the libvpx tag.
+type EncodeProfile struct {
+	FrameRate int
+	Bitrate   int
+	Encoder   func(fps, bitrate int) avp.Element
+}
+
+// TeeOutput is an output of a Tee.
+// Name: Unique among the outputs.
+// Filters: Elements frames go through in order before the output's
+// encoder, such as a TimecodeBurner for a review copy, none for the clean
+// archive copy. Filters must draw on copies of frames, not the frames,
+// which are written to the other outputs too.
+type TeeOutput struct {
+	Name    string
+	Filters []avp.Element
+}
+
+// Tee instance
+type Tee struct {
+	outputs  []TeeOutput
+	branches []*Pipeline
+	encoders []avp.Element
+}
+
+// NewTee instance. Tee takes YCbCr frames decoded once, e.g. by a Decoder
+// or of a VideoCompositor as its Encoder, and encodes them for each of its
+// outputs with the same profile, such as a clean archive copy and a
+// review copy with timecode burned in. Attach each output's saver to the
+// element Output returns. Other samples, e.g. audio, go to every output.
+func NewTee(p EncodeProfile, outputs ...TeeOutput) (*Tee, error) {
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("%w: no outputs", ErrTeeOutputs)
+	}
+	if p.FrameRate <= 0 {
+		p.FrameRate = 30
+	}
+	if p.Bitrate <= 0 {
+		p.Bitrate = 1000000
+	}
+	if p.Encoder == nil {
+		p.Encoder = newVideoEncoder
+	}
+	if p.Encoder == nil {
+		return nil, ErrNoVideoCodec
+	}
+	t := &Tee{outputs: outputs}
+	names := make(map[string]bool)
+	for _, o := range outputs {
+		if o.Name == "" || names[o.Name] {
+			return nil, fmt.Errorf("%w: output names must be unique, not %q", ErrTeeOutputs, o.Name)
+		}
+		names[o.Name] = true
+		// None in low memory mode
+		enc := p.Encoder(p.FrameRate, p.Bitrate)
+		if enc == nil {
+			t.Close()
+			return nil, ErrNoVideoCodec
+		}
+		t.encoders = append(t.encoders, enc)
+		t.branches = append(t.branches, NewPipeline(append(append([]avp.Element{}, o.Filters...), enc)))
+	}
+	return t, nil
+}
+
+// Output returns the encoder of the named output, to attach its saver to,
+// nil if there's no such output
+func (t *Tee) Output(name string) avp.Element {
+	for i, o := range t.outputs {
+		if o.Name == name {
+			return t.encoders[i]
+		}
+	}
+	return nil
+}
+
+func (t *Tee) Write(sample *avp.Sample) error {
+	for _, b := range t.branches {
+		if err := b.Write(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Attach isn't supported, elements are attached to an output, see Output()
+func (t *Tee) Attach(e avp.Element) {
+	log.Warnf("Tee attach to an output, see Output()")
+}
+
+// Close closes every output's filters and encoder
+func (t *Tee) Close() {
+	for _, b := range t.branches {
+		b.Close()
+	}
+}
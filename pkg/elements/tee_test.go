@@ -0,0 +1,75 @@
+package elements
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+// profileEncoder passes frames through, keeping the settings it was
+// created with
+type profileEncoder struct {
+	Node
+	fps, bitrate int
+	closed       bool
+}
+
+func (e *profileEncoder) Close() {
+	e.closed = true
+	e.Node.Close()
+}
+
+func TestTee(t *testing.T) {
+	var encoders []*profileEncoder
+	profile := EncodeProfile{FrameRate: 15, Bitrate: 1500000, Encoder: func(fps, bitrate int) avp.Element {
+		e := &profileEncoder{fps: fps, bitrate: bitrate}
+		encoders = append(encoders, e)
+		return e
+	}}
+	tee, err := NewTee(profile,
+		TeeOutput{Name: "archive"},
+		TeeOutput{Name: "review", Filters: []avp.Element{NewTimecodeBurner(TimecodeBurnerConfig{Session: "sid", Corner: pixel.BottomRight})}},
+	)
+	assert.NoError(t, err)
+	archive, review := &sampleRecorder{}, &sampleRecorder{}
+	tee.Output("archive").Attach(archive)
+	tee.Output("review").Attach(review)
+	assert.Nil(t, tee.Output("proxy"))
+
+	assert.NoError(t, tee.Write(&avp.Sample{Type: TypeYCbCr, Payload: lumaFrame(16)}))
+	assert.NoError(t, tee.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+	tee.Close()
+
+	// Encoded alike
+	if assert.Len(t, encoders, 2) {
+		for _, e := range encoders {
+			assert.Equal(t, 15, e.fps)
+			assert.Equal(t, 1500000, e.bitrate)
+			assert.True(t, e.closed)
+		}
+	}
+	// The clean copy is left as decoded
+	if assert.Len(t, archive.samples, 2) && assert.Len(t, review.samples, 2) {
+		clean := archive.samples[0].Payload.(*image.YCbCr)
+		burned := review.samples[0].Payload.(*image.YCbCr)
+		assert.Equal(t, uint8(16), clean.Y[clean.YOffset(63, 35)])
+		assert.NotEqual(t, uint8(16), burned.Y[burned.YOffset(63, 35)])
+		assert.Equal(t, avp.TypeOpus, archive.samples[1].Type)
+		assert.Equal(t, avp.TypeOpus, review.samples[1].Type)
+	}
+
+	_, err = NewTee(profile)
+	assert.True(t, errors.Is(err, ErrTeeOutputs))
+	_, err = NewTee(profile, TeeOutput{Name: "a"}, TeeOutput{Name: "a"})
+	assert.True(t, errors.Is(err, ErrTeeOutputs))
+	if newVideoEncoder == nil {
+		_, err = NewTee(EncodeProfile{}, TeeOutput{Name: "a"})
+		assert.Equal(t, ErrNoVideoCodec, err)
+		_, err = NewVideoCompositor(VideoCompositorConfig{Outputs: []TeeOutput{{Name: "a"}}})
+		assert.Equal(t, ErrNoVideoCodec, err)
+	}
+}
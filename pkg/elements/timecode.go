@@ -0,0 +1,79 @@
+package elements
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+)
+
+// TimecodeBurnerConfig configures a TimecodeBurner.
+// Session: Drawn below the timecode, e.g. the session ID, so stills of
+// the review copy say where they're from.
+// FrameRate: Frames a second counted in the timecode, defaults to 30.
+// TimeOfDay: Run the timecode from the UTC time of day frames were
+// captured, which exported audio's Broadcast Wave timecode matches, rather
+// than from the first frame.
+// Corner: Of the frame the overlay is drawn in, top left by default.
+// Scale: Size of the font's pixels, by default sized to the frame.
+type TimecodeBurnerConfig struct {
+	Session   string
+	FrameRate int
+	TimeOfDay bool
+	Corner    pixel.Corner
+	Scale     int
+}
+
+// TimecodeBurner instance
+type TimecodeBurner struct {
+	Node
+	cfg   TimecodeBurnerConfig
+	clock trackClock
+}
+
+// NewTimecodeBurner instance. TimecodeBurner takes as input YCbCr frames,
+// e.g. from a Decoder, and burns a running SMPTE timecode and the session
+// into a corner of copies of them, for review and QC copies of recordings.
+// Frames are left as they are, so the Decoder's other children, such as
+// the encoder of the clean archive copy, get them without the overlay.
+// Other samples pass through.
+func NewTimecodeBurner(c TimecodeBurnerConfig) *TimecodeBurner {
+	if c.FrameRate <= 0 {
+		c.FrameRate = 30
+	}
+	return &TimecodeBurner{cfg: c}
+}
+
+func (b *TimecodeBurner) Write(sample *avp.Sample) error {
+	src, ok := sample.Payload.(*image.YCbCr)
+	if sample.Type != TypeYCbCr || !ok {
+		return b.Node.Write(sample)
+	}
+	at := b.clock.since(sample, videoClockRate)
+	if b.cfg.TimeOfDay && !sample.CaptureTime.IsZero() {
+		t := sample.CaptureTime.UTC()
+		at = t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+	}
+	lines := []string{Timecode(at, b.cfg.FrameRate)}
+	if b.cfg.Session != "" {
+		lines = append(lines, b.cfg.Session)
+	}
+	dst := copyImage(src).(*image.YCbCr)
+	pixel.Overlay(dst, b.cfg.Corner, b.cfg.Scale, lines...)
+	out := *sample
+	out.Payload = dst
+	return b.Node.Write(&out)
+}
+
+// Timecode formats d as a non-drop frame SMPTE timecode, HH:MM:SS:FF at
+// fps frames a second, wrapping at 24 hours
+func Timecode(d time.Duration, fps int) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int64(d / time.Second)
+	frame := int64(d%time.Second) * int64(fps) / int64(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", secs/3600%24, secs/60%60, secs%60, frame)
+}
@@ -0,0 +1,52 @@
+package elements
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimecodeBurner(t *testing.T) {
+	b := NewTimecodeBurner(TimecodeBurnerConfig{Session: "sid", Corner: pixel.BottomRight})
+	burned, clean := &sampleRecorder{}, &sampleRecorder{}
+	b.Attach(burned)
+
+	// Frames of one Decoder go to both copies
+	dec := &Node{}
+	dec.Attach(b)
+	dec.Attach(clean)
+	for _, ts := range []uint32{0, 3000, 90000} {
+		assert.NoError(t, dec.Write(&avp.Sample{Type: TypeYCbCr, Timestamp: ts, Payload: lumaFrame(16)}))
+	}
+	assert.NoError(t, dec.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{1}}))
+
+	if assert.Len(t, burned.samples, 4) {
+		m := burned.samples[0].Payload.(*image.YCbCr)
+		assert.NotEqual(t, uint8(16), m.Y[m.YOffset(63, 35)])
+		assert.Equal(t, uint8(16), m.Y[m.YOffset(0, 0)])
+		assert.Equal(t, avp.TypeOpus, burned.samples[3].Type)
+	}
+	for _, s := range clean.samples[:3] {
+		m := s.Payload.(*image.YCbCr)
+		assert.Equal(t, uint8(16), m.Y[m.YOffset(63, 35)])
+	}
+
+	// From the time of day frames were captured
+	b = NewTimecodeBurner(TimecodeBurnerConfig{TimeOfDay: true, FrameRate: 25})
+	b.Attach(burned)
+	at := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, b.Write(&avp.Sample{Type: TypeYCbCr, CaptureTime: at, Payload: lumaFrame(16)}))
+	m := burned.samples[4].Payload.(*image.YCbCr)
+	assert.NotEqual(t, uint8(16), m.Y[m.YOffset(0, 0)])
+}
+
+func TestTimecode(t *testing.T) {
+	assert.Equal(t, "00:00:00:00", Timecode(0, 30))
+	assert.Equal(t, "00:00:01:15", Timecode(1500*time.Millisecond, 30))
+	assert.Equal(t, "01:02:03:24", Timecode(time.Hour+2*time.Minute+3*time.Second+999*time.Millisecond, 25))
+	assert.Equal(t, "00:00:00:00", Timecode(24*time.Hour, 30))
+}
@@ -0,0 +1,369 @@
+package elements
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+	log "github.com/pion/ion-log"
+)
+
+// ErrNoTranscriptionBackend is returned by NewTranscriber without a backend
+var ErrNoTranscriptionBackend = errors.New("transcriber needs a backend")
+
+// TranscriptResult is speech recognized by a TranscriptionStream
+type TranscriptResult struct {
+	Text string
+	// Start and End are times in the audio sent on the stream
+	Start time.Duration
+	End   time.Duration
+	// Final results are the backend's last word on their audio, others
+	// are interim hypotheses it goes on to revise
+	Final    bool
+	Language string
+}
+
+// TranscriptionBackend opens streams to a speech to text service, such as
+// a gRPC streaming client of Google Speech-to-Text, Amazon Transcribe or
+// a Whisper server
+type TranscriptionBackend interface {
+	// Stream opens a stream recognizing 16 bit mono PCM at rate. language
+	// is a BCP-47 code, or empty for the backend to detect it.
+	Stream(ctx context.Context, rate int, language string) (TranscriptionStream, error)
+}
+
+// TranscriptionStream is a recognition in progress, shaped like the client
+// of a gRPC bidirectional stream: Send and Recv are called on goroutines
+// of their own.
+type TranscriptionStream interface {
+	// Send sends the next audio
+	Send(pcm []int16) error
+	// Recv returns the next result, io.EOF after the last
+	Recv() (*TranscriptResult, error)
+	// CloseSend ends the audio, after which the results left are received
+	CloseSend() error
+}
+
+// TranscriberConfig configures a Transcriber.
+// Backend: Recognizes the speech, required.
+// Rate: Of the audio sent to the backend, 16000 as speech models take or
+// 48000 as decoded, defaults to 16000.
+// Language: BCP-47 code of the speech, for the backend to detect if empty.
+// Speaker: Names who is speaking in the text, such as the participant of
+// the track.
+// Interim: Also write the backend's interim results, for live captions.
+// Buffer: How many frames of audio are held for a backend falling behind
+// before they are dropped, defaults to 50, a second of 20ms frames.
+// Timeout: How long Close waits for the last results, defaults to 5s.
+// Decoder: Optional creator of the decoder of Opus audio. By default
+// libopus, when built with the libopus tag. TypePCM audio, as from an
+// OpusDecoder, needs none.
+type TranscriberConfig struct {
+	Backend  TranscriptionBackend
+	Rate     int
+	Language string
+	Speaker  string
+	Interim  bool
+	Buffer   int
+	Timeout  time.Duration
+	Decoder  func(channels int) (OpusPCMDecoder, error)
+}
+
+// transcriptSpan is where audio sent to the backend after a gap in the
+// track starts, in the stream and in the track
+type transcriptSpan struct {
+	sent  time.Duration
+	track time.Duration
+}
+
+// Transcriber instance
+type Transcriber struct {
+	Node
+	mu      sync.Mutex
+	cfg     TranscriberConfig
+	id      string
+	clock   trackClock
+	start   time.Time // capture time of the first audio
+	decoder OpusPCMDecoder
+	pcm     []int16
+	audio   chan []int16
+	stream  TranscriptionStream
+	cancel  context.CancelFunc
+	done    chan struct{} // closed after the last result
+	spans   []transcriptSpan
+	sent    time.Duration // of audio queued for the backend
+	end     time.Duration // in the track, of the audio queued
+	results []*Text       // received, not yet written
+	dropped uint64
+	failed  bool
+	closed  bool
+}
+
+// NewTranscriber instance. Transcriber sends a track's audio, TypePCM or
+// Opus it decodes, to a speech to text backend and writes what it
+// recognizes to its children as TypeText samples, timed in the track,
+// such as for a SubtitleWriter. Audio and other samples pass through.
+// The backend's stream is opened with the first audio. A backend that
+// can't keep up doesn't hold up the track, audio is dropped while the
+// buffer is full. Results are written on the pipeline's goroutine, with
+// the sample following them, and on close.
+func NewTranscriber(c TranscriberConfig) (*Transcriber, error) {
+	if c.Backend == nil {
+		return nil, ErrNoTranscriptionBackend
+	}
+	if c.Rate == 0 {
+		c.Rate = 16000
+	}
+	if c.Rate != opus.SampleRate && c.Rate != 16000 {
+		return nil, ErrPCMRate
+	}
+	if c.Buffer <= 0 {
+		c.Buffer = 50
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.Decoder == nil {
+		c.Decoder = newOpusDecoder
+	}
+	return &Transcriber{cfg: c, audio: make(chan []int16, c.Buffer), done: make(chan struct{})}, nil
+}
+
+// Dropped returns how many frames of audio have been dropped in all
+func (t *Transcriber) Dropped() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dropped
+}
+
+func (t *Transcriber) Write(sample *avp.Sample) error {
+	if err := t.flush(); err != nil {
+		return err
+	}
+	if sample.Type == avp.TypeOpus || sample.Type == TypePCM {
+		t.send(sample)
+	}
+	return t.Node.Write(sample)
+}
+
+// send queues the audio of a sample for the backend
+func (t *Transcriber) send(sample *avp.Sample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed || t.failed {
+		return
+	}
+	at := t.clock.since(sample, audioClockRate)
+	pcm, ok := t.decode(sample)
+	if !ok {
+		return
+	}
+	if t.stream == nil && !t.open() {
+		return
+	}
+	if t.start.IsZero() {
+		t.id, t.start = sample.ID, sample.CaptureTime.Add(-at)
+	}
+
+	data := decimate(pcm.Data, pcm.Channels, pcm.Rate/t.cfg.Rate)
+	data = downmix(data, pcm.Channels)
+	duration := time.Duration(len(data)) * time.Second / time.Duration(t.cfg.Rate)
+	select {
+	case t.audio <- data:
+	default:
+		if t.dropped++; t.dropped%100 == 1 {
+			log.Warnf("transcriber: backend behind, %d frames dropped", t.dropped)
+		}
+		return
+	}
+	// Audio after a gap, of silence not sent or dropped, starts a span
+	if len(t.spans) == 0 || at > t.end+10*time.Millisecond {
+		t.spans = append(t.spans, transcriptSpan{sent: t.sent, track: at})
+		t.end = at
+	}
+	t.sent += duration
+	t.end += duration
+}
+
+// decode returns the audio of a sample
+func (t *Transcriber) decode(sample *avp.Sample) (*PCM, bool) {
+	if pcm, ok := sample.Payload.(*PCM); ok {
+		if pcm.Channels <= 0 || pcm.Rate%t.cfg.Rate != 0 || len(pcm.Data) == 0 {
+			return nil, false
+		}
+		return pcm, true
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return nil, false
+	}
+	if t.decoder == nil {
+		if t.cfg.Decoder == nil {
+			log.Warnf("transcriber: %s", ErrNoOpusDecoder)
+			t.failed = true
+			return nil, false
+		}
+		dec, err := t.cfg.Decoder(1)
+		if err != nil {
+			log.Warnf("transcriber: decoder: %s", err)
+			t.failed = true
+			return nil, false
+		}
+		t.decoder, t.pcm = dec, make([]int16, opus.MaxFrame)
+	}
+	n, err := t.decoder.Decode(payload, t.pcm)
+	if err != nil || n == 0 {
+		return nil, false
+	}
+	return &PCM{Data: t.pcm[:n], Channels: 1, Rate: opus.SampleRate}, true
+}
+
+// open opens the backend's stream and starts sending to and receiving
+// from it
+func (t *Transcriber) open() bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := t.cfg.Backend.Stream(ctx, t.cfg.Rate, t.cfg.Language)
+	if err != nil {
+		cancel()
+		log.Errorf("transcriber: opening stream: %s", err)
+		t.failed = true
+		return false
+	}
+	t.stream, t.cancel = stream, cancel
+	go t.sendLoop(stream)
+	go t.recvLoop(stream)
+	return true
+}
+
+func (t *Transcriber) sendLoop(stream TranscriptionStream) {
+	for pcm := range t.audio {
+		if err := stream.Send(pcm); err != nil {
+			log.Errorf("transcriber: sending audio: %s", err)
+			// Left to fill, the buffer drops what follows
+			return
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		log.Warnf("transcriber: ending audio: %s", err)
+	}
+}
+
+func (t *Transcriber) recvLoop(stream TranscriptionStream) {
+	defer close(t.done)
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, context.Canceled) {
+				log.Errorf("transcriber: receiving results: %s", err)
+			}
+			return
+		}
+		if res.Text == "" || !res.Final && !t.cfg.Interim {
+			continue
+		}
+		t.mu.Lock()
+		t.results = append(t.results, &Text{
+			Text:     res.Text,
+			Start:    t.trackTime(res.Start),
+			End:      t.trackTime(res.End),
+			Speaker:  t.cfg.Speaker,
+			Language: res.Language,
+			Final:    res.Final,
+		})
+		if res.Final {
+			t.prune(res.End)
+		}
+		t.mu.Unlock()
+	}
+}
+
+// trackTime returns the time in the track of a time in the audio sent
+func (t *Transcriber) trackTime(sent time.Duration) time.Duration {
+	if len(t.spans) == 0 {
+		return sent
+	}
+	span := t.spans[0]
+	for _, s := range t.spans[1:] {
+		if s.sent > sent {
+			break
+		}
+		span = s
+	}
+	return span.track + sent - span.sent
+}
+
+// prune forgets the spans before sent, which results have gone past
+func (t *Transcriber) prune(sent time.Duration) {
+	for len(t.spans) > 1 && t.spans[1].sent <= sent {
+		t.spans = t.spans[1:]
+	}
+}
+
+// flush writes the results received to the children
+func (t *Transcriber) flush() error {
+	t.mu.Lock()
+	results, id, start := t.results, t.id, t.start
+	t.results = nil
+	t.mu.Unlock()
+	for _, text := range results {
+		sample := &avp.Sample{ID: id, Type: TypeText, Payload: text}
+		if !start.IsZero() {
+			sample.CaptureTime = start.Add(text.Start)
+		}
+		if err := t.Node.Write(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close ends the audio, writes the results left once the backend has
+// returned them, or Timeout is up, and closes the children
+func (t *Transcriber) Close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	if t.decoder != nil {
+		t.decoder.Close()
+		t.decoder = nil
+	}
+	started := t.stream != nil
+	close(t.audio)
+	t.mu.Unlock()
+
+	if started {
+		select {
+		case <-t.done:
+		case <-time.After(t.cfg.Timeout):
+			log.Warnf("transcriber: timed out waiting for the last results")
+		}
+		t.cancel()
+	}
+	if err := t.flush(); err != nil {
+		log.Errorf("transcriber: writing results: %s", err)
+	}
+	t.Node.Close()
+}
+
+// downmix returns the mono average of interleaved pcm
+func downmix(pcm []int16, channels int) []int16 {
+	if channels <= 1 {
+		return pcm
+	}
+	out := make([]int16, len(pcm)/channels)
+	for i := range out {
+		sum := 0
+		for c := 0; c < channels; c++ {
+			sum += int(pcm[i*channels+c])
+		}
+		out[i] = int16(sum / channels)
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package elements
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTranscription keeps the audio it's sent, returning the results it's
+// given until the audio ends
+type fakeTranscription struct {
+	mu       sync.Mutex
+	rate     int
+	language string
+	sent     []int16
+	results  chan *TranscriptResult
+}
+
+func (f *fakeTranscription) Stream(ctx context.Context, rate int, language string) (TranscriptionStream, error) {
+	f.rate, f.language = rate, language
+	return f, nil
+}
+
+func (f *fakeTranscription) Send(pcm []int16) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, pcm...)
+	return nil
+}
+
+func (f *fakeTranscription) Recv() (*TranscriptResult, error) {
+	res, ok := <-f.results
+	if !ok {
+		return nil, io.EOF
+	}
+	return res, nil
+}
+
+func (f *fakeTranscription) CloseSend() error {
+	close(f.results)
+	return nil
+}
+
+func TestTranscriber(t *testing.T) {
+	backend := &fakeTranscription{results: make(chan *TranscriptResult, 3)}
+	tr, err := NewTranscriber(TranscriberConfig{Backend: backend, Language: "en-US", Speaker: "Alice"})
+	assert.NoError(t, err)
+	rec := &sampleRecorder{}
+	tr.Attach(rec)
+
+	start := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	// 200ms of stereo audio, then 200ms more after a gap of 1.8s
+	for _, ts := range []uint32{0, 96000} {
+		for i := uint32(0); i < 10; i++ {
+			pcm := &PCM{Data: make([]int16, 960*2), Channels: 2, Rate: 48000}
+			for j := range pcm.Data {
+				pcm.Data[j] = int16(300 * (j % 2))
+			}
+			at := ts + i*960
+			assert.NoError(t, tr.Write(&avp.Sample{ID: "mic", Type: TypePCM, Timestamp: at, CaptureTime: start.Add(time.Duration(at) * time.Second / 48000), Payload: pcm}))
+		}
+	}
+	assert.NoError(t, tr.Write(&avp.Sample{ID: "mic", Type: avp.TypeVP8, Payload: []byte{1}}))
+
+	backend.results <- &TranscriptResult{Text: "Hello", Start: 20 * time.Millisecond, End: 180 * time.Millisecond, Final: true}
+	backend.results <- &TranscriptResult{Text: "wor", Start: 200 * time.Millisecond, End: 300 * time.Millisecond}
+	backend.results <- &TranscriptResult{Text: "world", Start: 200 * time.Millisecond, End: 400 * time.Millisecond, Final: true, Language: "en-US"}
+	tr.Close()
+	tr.Close()
+
+	assert.Equal(t, 16000, backend.rate)
+	assert.Equal(t, "en-US", backend.language)
+	if assert.Len(t, backend.sent, 20*320) {
+		assert.Equal(t, int16(150), backend.sent[0])
+	}
+	assert.Len(t, rec.samples, 23)
+	var texts []*Text
+	for _, s := range rec.samples[21:] {
+		assert.Equal(t, TypeText, s.Type)
+		assert.Equal(t, "mic", s.ID)
+		texts = append(texts, s.Payload.(*Text))
+	}
+	assert.Equal(t, []*Text{
+		{Text: "Hello", Start: 20 * time.Millisecond, End: 180 * time.Millisecond, Speaker: "Alice", Final: true},
+		{Text: "world", Start: 2 * time.Second, End: 2200 * time.Millisecond, Speaker: "Alice", Language: "en-US", Final: true},
+	}, texts)
+	assert.Equal(t, start.Add(2*time.Second), rec.samples[22].CaptureTime)
+}
+
+func TestTranscriberOpus(t *testing.T) {
+	backend := &fakeTranscription{results: make(chan *TranscriptResult, 1)}
+	tr, err := NewTranscriber(TranscriberConfig{
+		Backend: backend,
+		Rate:    48000,
+		Interim: true,
+		Decoder: func(int) (OpusPCMDecoder, error) { return levelDecoder{}, nil },
+	})
+	assert.NoError(t, err)
+	rec := &sampleRecorder{}
+	tr.Attach(rec)
+	backend.results <- &TranscriptResult{Text: "hel", End: 20 * time.Millisecond}
+	assert.NoError(t, tr.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{10}}))
+	tr.Close()
+
+	assert.Equal(t, 48000, backend.rate)
+	if assert.Len(t, backend.sent, 960) {
+		assert.Equal(t, int16(1000), backend.sent[0])
+	}
+	if assert.Len(t, rec.samples, 2) {
+		assert.Equal(t, &Text{Text: "hel", End: 20 * time.Millisecond}, rec.samples[1].Payload)
+	}
+
+	_, err = NewTranscriber(TranscriberConfig{})
+	assert.Equal(t, ErrNoTranscriptionBackend, err)
+	_, err = NewTranscriber(TranscriberConfig{Backend: backend, Rate: 8000})
+	assert.True(t, errors.Is(err, ErrPCMRate))
+}
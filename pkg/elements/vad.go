@@ -0,0 +1,235 @@
+package elements
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+	log "github.com/pion/ion-log"
+)
+
+// VoiceActivity is the payload of TypeVoice samples, sent when speech
+// starts or stops
+type VoiceActivity struct {
+	Speech bool
+	// At is the time in the track speech started or stopped
+	At time.Duration
+}
+
+// SpeechSegment is a span of speech of a track
+type SpeechSegment struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
+// SpeechSegments is the sidecar written by VoiceDetector
+type SpeechSegments struct {
+	Track    string          `json:"track"`
+	Segments []SpeechSegment `json:"segments"`
+}
+
+// VoiceDetectorConfig configures a VoiceDetector.
+// Threshold: Level in -dBov audio must be louder than, i.e. below, to be
+// speech, defaults to 50.
+// Hangover: How long speech lasts after the last loud audio, so pauses
+// between words don't cut it up, defaults to 300ms.
+// Gate: Pass audio to children only while there is speech. Other samples,
+// such as video, pass regardless.
+// Decoder: Optional creator of the decoder of Opus arriving without the
+// ssrc-audio-level header extension, which is measured decoded. By default
+// libopus, when built with the libopus tag. Without one, frames as large
+// as Opus spends on speech count as speech.
+type VoiceDetectorConfig struct {
+	Threshold float64
+	Hangover  time.Duration
+	Gate      bool
+	Decoder   func(channels int) (OpusPCMDecoder, error)
+}
+
+// VoiceDetector instance
+type VoiceDetector struct {
+	Node
+	mu       sync.Mutex
+	cfg      VoiceDetectorConfig
+	track    string
+	open     func() (io.WriteCloser, error)
+	decoder  OpusPCMDecoder
+	pcm      []int16
+	clock    trackClock
+	speaking bool
+	start    time.Duration // of the speech
+	last     time.Duration // end of its last loud audio
+	segments []SpeechSegment
+	closed   bool
+}
+
+// NewVoiceDetector instance. VoiceDetector flags where a track's Opus or
+// PCM audio is speech, writing a TypeVoice sample to its children as
+// speech starts and stops. Gating, it leaves the silence out of what it
+// passes on, so archives of mostly silent channels keep only their
+// speech. open, if not nil, creates the sidecar of the speech's segments
+// on close, to place what was kept in time.
+func NewVoiceDetector(c VoiceDetectorConfig, track string, open func() (io.WriteCloser, error)) *VoiceDetector {
+	if c.Threshold <= 0 {
+		c.Threshold = 50
+	}
+	if c.Hangover <= 0 {
+		c.Hangover = 300 * time.Millisecond
+	}
+	if c.Decoder == nil {
+		c.Decoder = newOpusDecoder
+	}
+	return &VoiceDetector{cfg: c, track: track, open: open}
+}
+
+// Speaking reports whether the track is speaking
+func (v *VoiceDetector) Speaking() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.speaking
+}
+
+// Segments returns the segments of speech so far, the last still going
+// while speaking
+func (v *VoiceDetector) Segments() SpeechSegments {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.segmentsLocked()
+}
+
+func (v *VoiceDetector) segmentsLocked() SpeechSegments {
+	segs := append([]SpeechSegment{}, v.segments...)
+	if v.speaking {
+		segs = append(segs, SpeechSegment{Start: v.start, End: v.last})
+	}
+	return SpeechSegments{Track: v.track, Segments: segs}
+}
+
+func (v *VoiceDetector) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus && sample.Type != TypePCM {
+		return v.Node.Write(sample)
+	}
+	activity, speaking := v.audio(sample)
+	if activity != nil {
+		err := v.Node.Write(&avp.Sample{
+			ID:          sample.ID,
+			Type:        TypeVoice,
+			CaptureTime: sample.CaptureTime,
+			Payload:     activity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if v.cfg.Gate && !speaking {
+		return nil
+	}
+	return v.Node.Write(sample)
+}
+
+// audio measures a sample, returning the change of activity it leads to,
+// if any, and whether it is of speech
+func (v *VoiceDetector) audio(sample *avp.Sample) (*VoiceActivity, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return nil, false
+	}
+	at := v.clock.since(sample, audioClockRate)
+	loud, duration, ok := v.measure(sample)
+	if !ok {
+		return nil, v.speaking
+	}
+	switch {
+	case loud && !v.speaking:
+		v.speaking, v.start, v.last = true, at, at+duration
+		return &VoiceActivity{Speech: true, At: at}, true
+	case loud:
+		v.last = at + duration
+	case v.speaking && at >= v.last+v.cfg.Hangover:
+		v.speaking = false
+		v.segments = append(v.segments, SpeechSegment{Start: v.start, End: v.last})
+		return &VoiceActivity{Speech: false, At: v.last}, false
+	}
+	return nil, v.speaking
+}
+
+// measure returns whether a sample is loud enough to be speech, and its
+// duration
+func (v *VoiceDetector) measure(sample *avp.Sample) (bool, time.Duration, bool) {
+	if pcm, ok := sample.Payload.(*PCM); ok {
+		if pcm.Channels <= 0 || pcm.Rate <= 0 || len(pcm.Data) == 0 {
+			return false, 0, false
+		}
+		duration := time.Duration(len(pcm.Data)/pcm.Channels) * time.Second / time.Duration(pcm.Rate)
+		return pcmLevel(pcm.Data) < v.cfg.Threshold, duration, true
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return false, 0, false
+	}
+	duration, err := opus.PacketDuration(payload)
+	if err != nil {
+		duration = 20 * time.Millisecond
+	}
+	if l := sample.AudioLevel; l != nil {
+		return float64(l.Level) < v.cfg.Threshold, duration, true
+	}
+	if v.decoder == nil && v.cfg.Decoder != nil {
+		dec, err := v.cfg.Decoder(1)
+		if err != nil {
+			log.Warnf("VoiceDetector of %s: decoder: %s", v.track, err)
+			v.cfg.Decoder = nil
+		} else {
+			v.decoder, v.pcm = dec, make([]int16, opus.MaxFrame)
+		}
+	}
+	if v.decoder == nil {
+		return len(payload) >= activeFrame, duration, true
+	}
+	n, err := v.decoder.Decode(payload, v.pcm)
+	if err != nil || n == 0 {
+		return false, 0, false
+	}
+	return pcmLevel(v.pcm[:n]) < v.cfg.Threshold, duration, true
+}
+
+func (v *VoiceDetector) Close() {
+	v.Node.Close()
+	v.mu.Lock()
+	if v.closed {
+		v.mu.Unlock()
+		return
+	}
+	v.closed = true
+	if v.decoder != nil {
+		v.decoder.Close()
+		v.decoder = nil
+	}
+	segs := v.segmentsLocked()
+	v.mu.Unlock()
+
+	if v.open == nil {
+		return
+	}
+	if err := v.write(segs); err != nil {
+		log.Errorf("VoiceDetector error writing speech of %s: %s", v.track, err)
+	}
+}
+
+func (v *VoiceDetector) write(segs SpeechSegments) error {
+	w, err := v.open()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(segs); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
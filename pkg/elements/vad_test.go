@@ -0,0 +1,91 @@
+package elements
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoiceDetector(t *testing.T) {
+	out := &nopWriteCloser{}
+	v := NewVoiceDetector(VoiceDetectorConfig{Gate: true}, "alice", func() (io.WriteCloser, error) { return out, nil })
+	rec := &sampleRecorder{}
+	v.Attach(rec)
+
+	// 20ms packets from ms to end, speech or silence
+	talk := func(from, to int, level uint8) {
+		for ms := from; ms < to; ms += 20 {
+			assert.NoError(t, v.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(ms * 48), AudioLevel: &avp.AudioLevel{Level: level}, Payload: []byte{0x08}}))
+		}
+	}
+	talk(0, 200, 127)
+	talk(200, 400, 20)
+	assert.True(t, v.Speaking())
+	// A short pause is kept
+	talk(400, 600, 127)
+	talk(600, 800, 20)
+	assert.NoError(t, v.Write(&avp.Sample{Type: avp.TypeVP8, Payload: []byte{1}}))
+	talk(800, 1400, 127)
+	assert.False(t, v.Speaking())
+
+	var opus, video int
+	var activity []*VoiceActivity
+	for _, s := range rec.samples {
+		switch s.Type {
+		case avp.TypeOpus:
+			opus++
+		case avp.TypeVP8:
+			video++
+		case TypeVoice:
+			activity = append(activity, s.Payload.(*VoiceActivity))
+		}
+	}
+	// Speech with 300ms of hangover
+	assert.Equal(t, 45, opus)
+	assert.Equal(t, 1, video)
+	assert.Equal(t, []*VoiceActivity{
+		{Speech: true, At: 200 * time.Millisecond},
+		{Speech: false, At: 800 * time.Millisecond},
+	}, activity)
+
+	talk(1400, 1600, 20)
+	v.Close()
+	v.Close()
+	var segs SpeechSegments
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &segs))
+	assert.Equal(t, SpeechSegments{Track: "alice", Segments: []SpeechSegment{
+		{Start: 200 * time.Millisecond, End: 800 * time.Millisecond},
+		{Start: 1400 * time.Millisecond, End: 1600 * time.Millisecond},
+	}}, segs)
+	assert.True(t, out.closed)
+}
+
+func TestVoiceDetectorLevels(t *testing.T) {
+	v := NewVoiceDetector(VoiceDetectorConfig{Decoder: func(int) (OpusPCMDecoder, error) { return levelDecoder{}, nil }}, "alice", nil)
+	rec := &sampleRecorder{}
+	v.Attach(rec)
+
+	// Decoded PCM
+	pcm := &PCM{Data: make([]int16, 960), Channels: 1, Rate: 48000}
+	assert.NoError(t, v.Write(&avp.Sample{Type: TypePCM, Payload: pcm}))
+	assert.False(t, v.Speaking())
+	for i := range pcm.Data {
+		pcm.Data[i] = 1000
+	}
+	assert.NoError(t, v.Write(&avp.Sample{Type: TypePCM, Timestamp: 960, Payload: pcm}))
+	assert.True(t, v.Speaking())
+	// Ungated, everything passes
+	assert.Len(t, rec.samples, 3)
+
+	// Opus without levels is decoded
+	v = NewVoiceDetector(VoiceDetectorConfig{Decoder: func(int) (OpusPCMDecoder, error) { return levelDecoder{}, nil }}, "alice", nil)
+	assert.NoError(t, v.Write(&avp.Sample{Type: avp.TypeOpus, Payload: []byte{0}}))
+	assert.False(t, v.Speaking())
+	assert.NoError(t, v.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: 480, Payload: []byte{10}}))
+	assert.True(t, v.Speaking())
+	v.Close()
+}
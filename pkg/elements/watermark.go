@@ -0,0 +1,77 @@
+//go:build libopus
+// +build libopus
+
+package elements
+
+import (
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/watermark"
+)
+
+// AudioWatermark instance
+type AudioWatermark struct {
+	Node
+	channels int
+	bitrate  int
+	dec      *opus.Decoder
+	enc      *opus.Encoder
+	emb      *watermark.Embedder
+	pcm      []int16
+}
+
+// NewAudioWatermark instance. AudioWatermark decodes the Opus audio it
+// receives, embeds id, e.g. a session or tenant id, as an inaudible
+// watermark and encodes it again at bitrate, or libopus' choice if 0.
+// Other samples pass through. Watermark a track from its first sample
+// so the watermark can be detected from the start of the recording.
+func NewAudioWatermark(c watermark.Config, id string, channels, bitrate int) *AudioWatermark {
+	return &AudioWatermark{
+		channels: channels,
+		bitrate:  bitrate,
+		emb:      watermark.NewEmbedder(c, id, channels),
+		pcm:      make([]int16, opus.MaxFrame*channels),
+	}
+}
+
+func (w *AudioWatermark) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus {
+		return w.Node.Write(sample)
+	}
+
+	if w.dec == nil {
+		dec, err := opus.NewDecoder(opus.SampleRate, w.channels)
+		if err != nil {
+			return err
+		}
+		enc, err := opus.NewEncoder(opus.SampleRate, w.channels, w.bitrate)
+		if err != nil {
+			dec.Close()
+			return err
+		}
+		w.dec, w.enc = dec, enc
+	}
+
+	n, err := w.dec.Decode(sample.Payload.([]byte), w.pcm)
+	if err != nil {
+		return err
+	}
+	pcm := w.pcm[:n*w.channels]
+	w.emb.Embed(pcm)
+	packet, err := w.enc.Encode(pcm)
+	if err != nil {
+		return err
+	}
+
+	out := *sample
+	out.Payload = packet
+	return w.Node.Write(&out)
+}
+
+func (w *AudioWatermark) Close() {
+	if w.dec != nil {
+		w.dec.Close()
+		w.enc.Close()
+	}
+	w.Node.Close()
+}
@@ -0,0 +1,150 @@
+package elements
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/wav"
+	log "github.com/pion/ion-log"
+)
+
+// ErrNoOpusDecoder is returned by NewWavSaver when no decoder is given and
+// libopus isn't built in
+var ErrNoOpusDecoder = errors.New("no Opus decoder, build with libopus or pass one")
+
+// OpusPCMDecoder decodes Opus packets to interleaved 16 bit PCM at 48kHz,
+// like opus.Decoder. Decode returns the samples per channel, an empty
+// packet concealing a lost one.
+type OpusPCMDecoder interface {
+	Decode(packet []byte, pcm []int16) (int, error)
+	Close()
+}
+
+// newOpusDecoder creates the default decoder, set when libopus is built in
+var newOpusDecoder func(channels int) (OpusPCMDecoder, error)
+
+// WavSaverConfig configures WavSaver.
+// Channels: 1 or 2, defaults to 1. Opus decodes to either, whatever was
+// encoded.
+// Decoder: Optional decoder of the Opus audio, e.g. a pure Go one, closed
+// with the saver. By default libopus, when built with the libopus tag.
+// Recording: Optional state machine moved along as the recording progresses.
+type WavSaverConfig struct {
+	Channels  int
+	Decoder   OpusPCMDecoder
+	Recording *recording.Recording
+}
+
+// WavSaver instance
+type WavSaver struct {
+	sync.Mutex
+	cfg          WavSaverConfig
+	sampleWriter *SampleWriter
+	clock        trackClock
+	pcm          []int16
+	buf          []byte
+	written      int64 // samples per channel
+	closed       bool
+}
+
+// NewWavSaver instance. WavSaver decodes Opus audio and writes it as a
+// 16 bit PCM WAV stream at 48kHz to its children, e.g. a FileWriter, so
+// jobs that only read WAV can run off a pipeline. Gaps in the audio, from
+// lost packets or silence suppression, are filled with silence so it
+// stays in time. The stream's length isn't known until it ends, so its
+// header says it is unknown; wav.Fix fills it in on the finished file.
+func NewWavSaver(c WavSaverConfig) (*WavSaver, error) {
+	if c.Channels != 2 {
+		c.Channels = 1
+	}
+	if c.Decoder == nil {
+		if newOpusDecoder == nil {
+			return nil, ErrNoOpusDecoder
+		}
+		dec, err := newOpusDecoder(c.Channels)
+		if err != nil {
+			return nil, err
+		}
+		c.Decoder = dec
+	}
+	return &WavSaver{
+		cfg:          c,
+		sampleWriter: NewSampleWriter(),
+		pcm:          make([]int16, opus.MaxFrame*c.Channels),
+	}, nil
+}
+
+func (s *WavSaver) Write(sample *avp.Sample) error {
+	if sample.Type != avp.TypeOpus {
+		return nil
+	}
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || s.cfg.Recording.State() == recording.StatePaused {
+		return nil
+	}
+
+	if !s.clock.started {
+		if _, err := s.sampleWriter.Write(wav.Header(opus.SampleRate, s.cfg.Channels, wav.Unknown)); err != nil {
+			return err
+		}
+		setState(s.cfg.Recording, recording.StateRecording)
+	}
+	at := int64(s.clock.since(sample, audioClockRate) * opus.SampleRate / time.Second)
+	for gap := at - s.written; gap > 0; gap = at - s.written {
+		// A frame of silence at a time, gaps may be long
+		if gap > opus.MaxFrame {
+			gap = opus.MaxFrame
+		}
+		if err := s.write(make([]int16, gap*int64(s.cfg.Channels))); err != nil {
+			return err
+		}
+	}
+	n, err := s.cfg.Decoder.Decode(payload, s.pcm)
+	if err != nil {
+		// Carry on past a corrupt packet, the gap is filled by the next
+		log.Warnf("WAV saver: decoding: %s", err)
+		return nil
+	}
+	return s.write(s.pcm[:n*s.cfg.Channels])
+}
+
+// write writes interleaved samples to the stream
+func (s *WavSaver) write(pcm []int16) error {
+	s.buf = s.buf[:0]
+	for _, v := range pcm {
+		s.buf = append(s.buf, byte(v), byte(v>>8))
+	}
+	if _, err := s.sampleWriter.Write(s.buf); err != nil {
+		s.cfg.Recording.Fail(err)
+		return err
+	}
+	s.written += int64(len(pcm) / s.cfg.Channels)
+	return nil
+}
+
+// Attach attach a child element
+func (s *WavSaver) Attach(e avp.Element) {
+	s.sampleWriter.Attach(e)
+}
+
+// Close frees the decoder and closes the children
+func (s *WavSaver) Close() {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	setState(s.cfg.Recording, recording.StateFinalizing)
+	s.cfg.Decoder.Close()
+	s.sampleWriter.Close()
+}
@@ -0,0 +1,15 @@
+//go:build libopus
+// +build libopus
+
+package elements
+
+import "github.com/pion/ion-avp/pkg/opus"
+
+func init() {
+	newOpusDecoder = func(channels int) (OpusPCMDecoder, error) {
+		return opus.NewDecoder(opus.SampleRate, channels)
+	}
+	newOpusEncoder = func(channels, bitrate int) (OpusPCMEncoder, error) {
+		return opus.NewEncoder(opus.SampleRate, channels, bitrate)
+	}
+}
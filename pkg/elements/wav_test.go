@@ -0,0 +1,53 @@
+package elements
+
+import (
+	"encoding/binary"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/pion/ion-avp/pkg/wav"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOpusDecoder decodes packets to 20ms of their first byte
+type fakeOpusDecoder struct {
+	closed bool
+}
+
+func (d *fakeOpusDecoder) Decode(packet []byte, pcm []int16) (int, error) {
+	for i := 0; i < 960; i++ {
+		pcm[i] = int16(packet[0])
+	}
+	return 960, nil
+}
+
+func (d *fakeOpusDecoder) Close() {
+	d.closed = true
+}
+
+func TestWavSaver(t *testing.T) {
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "wav")
+	dec := &fakeOpusDecoder{}
+	saver, err := NewWavSaver(WavSaverConfig{Decoder: dec, Recording: rec})
+	assert.NoError(t, err)
+	out := NewBufWriter()
+	saver.Attach(out)
+
+	// The third packet is lost
+	for _, i := range []uint32{0, 1, 3} {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: 1000 + i*960, Payload: []byte{byte(i + 1)}}))
+	}
+	assert.Equal(t, recording.StateRecording, rec.State())
+	saver.Close()
+	assert.True(t, dec.closed)
+
+	b := out.buf.Bytes()
+	assert.Equal(t, wav.Header(48000, 1, wav.Unknown), b[:wav.HeaderSize])
+	pcm := b[wav.HeaderSize:]
+	assert.Len(t, pcm, 4*960*2)
+	// Lost audio is filled with silence
+	for i, want := range []int16{1, 2, 0, 4} {
+		assert.Equal(t, want, int16(binary.LittleEndian.Uint16(pcm[i*1920:])), "packet %d", i)
+	}
+}
@@ -2,6 +2,7 @@ package elements
 
 import (
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -9,26 +10,98 @@ import (
 	"github.com/at-wat/ebml-go/webm"
 
 	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/recording"
 	log "github.com/pion/ion-log"
 )
 
-// WebmSaver Module for saving rtp streams to webm
+// Matroska codec ids of video tracks. H.264 isn't allowed in WebM, files
+// with it are Matroska.
+const (
+	codecVP8  = "V_VP8"
+	codecVP9  = "V_VP9"
+	codecAV1  = "V_AV1"
+	codecH264 = "V_MPEG4/ISO/AVC"
+)
+
+// WebmSaver Module for saving rtp streams to webm. H.264 video is saved
+// as Matroska, WebM's parent format, for sessions negotiating it.
 type WebmSaver struct {
 	sync.Mutex
-	closed                         bool
-	audioWriter, videoWriter       webm.BlockWriteCloser
-	audioTimestamp, videoTimestamp uint32
-	sampleWriter                   *SampleWriter
-	cfg                            WebmSaverConfig
+	closed                   bool
+	paused                   bool
+	videoCodec               string
+	videoPrivate             []byte
+	width, height            int
+	audioWriter, videoWriter webm.BlockWriteCloser
+	audioClock, videoClock   trackClock
+	audioPause, videoPause   pauseClock
+	audioGaps, videoGaps     gapClock
+	audioOffset, videoOffset int64
+	audioDuration            time.Duration
+	start                    time.Time // the file is dated
+	sampleWriter             *SampleWriter
+	meta                     *sidecar
+	cfg                      WebmSaverConfig
 }
 
 // Configure WebmSaver.
 // e.g. pass just `Audio: true` to record an audio-only stream.
 // Audio: Record the audio track.
-// Video: Record the video track.
+// Video: Record the video track, VP8, VP9, AV1 or H.264.
+// Recording: Optional state machine moved along as the recording progresses.
+// Epoch: Optional session start the file is aligned to, so files of
+// different participants line up on an editor's timeline. The first
+// samples are placed at their capture time since the epoch, after
+// leading silence. Video shows black until its first frame.
+// Muxer: Optional muxer of the tracks, see SimpleBlockMuxer.
+// Colour: Optional colour of the video, written to the video track in
+// place of what its first keyframe says. Custom muxers don't write it.
+// PauseGap: Optional time left in the file where the recording was paused,
+// by default media carries on from where it stopped.
+// Gaps: What is done where a track's timestamps jump, from lost packets or
+// the publisher pausing, by default the jump is kept.
+// MaxGap: The longest jump that isn't a gap, 1s by default. Clamped gaps
+// are shortened to it.
+// Sidecar: Optional element sent the Metadata of the recording as JSON on
+// closing, e.g. a FileWriter of rec.webm.json.
+// Subtitles: Optional element sent the TypeText samples written to the
+// saver, e.g. a SubtitleWriter of rec.vtt for a Transcriber's text or live
+// captions. Text is placed on the file's timeline by its capture time,
+// from the epoch or the file's first sample.
 type WebmSaverConfig struct {
-	Audio bool
-	Video bool
+	Audio     bool
+	Video     bool
+	Recording *recording.Recording
+	Epoch     time.Time
+	Muxer     Muxer
+	Colour    *colorspace.Colour
+	PauseGap  time.Duration
+	Gaps      GapPolicy
+	MaxGap    time.Duration
+	Sidecar   avp.Element
+	Subtitles avp.Element
+}
+
+// Muxer creates a writer for each track of a WebM stream written to w,
+// with segment info. w must be closed once every track is. By default
+// WebmSaver writes BlockGroups with durations where SimpleBlocks would
+// get them wrong.
+type Muxer func(w io.WriteCloser, tracks []webm.TrackEntry, info *webm.Info) ([]webm.BlockWriteCloser, error)
+
+// SimpleBlockMuxer muxes with ebml-go's SimpleBlock writer and a SeekHead,
+// with opts applied after WebmSaver's, e.g. to replace the segment info or
+// to add Cues.
+func SimpleBlockMuxer(opts ...mkvcore.BlockWriterOption) Muxer {
+	return func(w io.WriteCloser, tracks []webm.TrackEntry, info *webm.Info) ([]webm.BlockWriteCloser, error) {
+		options := append([]mkvcore.BlockWriterOption{
+			mkvcore.WithEBMLHeader(ebmlHeader(tracks)),
+			mkvcore.WithSegmentInfo(info),
+			mkvcore.WithSeekHead(true),
+		}, opts...)
+		return webm.NewSimpleBlockWriter(w, tracks, options...)
+	}
 }
 
 // NewWebmSaver Initialize a new webm saver.
@@ -39,20 +112,76 @@ func NewWebmSaver(cfg *WebmSaverConfig) *WebmSaver {
 	}
 	return &WebmSaver{
 		sampleWriter: NewSampleWriter(),
+		meta:         newSidecar(cfg.Sidecar, cfg.Recording),
+		audioGaps:    newGapClock(cfg.Gaps, cfg.MaxGap),
+		videoGaps:    newGapClock(cfg.Gaps, cfg.MaxGap),
 		cfg:          *cfg,
 	}
 }
 
 // Write sample to webmsaver
 func (s *WebmSaver) Write(sample *avp.Sample) error {
-	if sample.Type == avp.TypeVP8 {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.paused || s.cfg.Recording.State() == recording.StatePaused {
+		s.pause()
+		return nil
+	}
+	switch sample.Type {
+	case avp.TypeVP8:
 		s.pushVP8(sample)
-	} else if sample.Type == avp.TypeOpus {
+	case avp.TypeVP9:
+		s.pushVP9(sample)
+	case avp.TypeAV1:
+		s.pushAV1(sample)
+	case avp.TypeH264:
+		s.pushH264(sample)
+	case avp.TypeOpus:
 		s.pushOpus(sample)
+	case TypeText:
+		return s.pushText(sample)
 	}
 	return nil
 }
 
+// Pause stops writing media, keeping the file open, until Resume
+func (s *WebmSaver) Pause() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed || s.paused {
+		return
+	}
+	s.paused = true
+	s.pause()
+	if s.cfg.Recording.State() != recording.StatePaused {
+		setState(s.cfg.Recording, recording.StatePaused)
+	}
+}
+
+// pause takes the time until the tracks resume out of the file
+func (s *WebmSaver) pause() {
+	s.audioPause.pause()
+	s.videoPause.pause()
+	s.audioGaps.pause()
+	s.videoGaps.pause()
+}
+
+// Resume writes media again after Pause, video from its next keyframe
+func (s *WebmSaver) Resume() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed || !s.paused {
+		return
+	}
+	s.paused = false
+	if s.cfg.Recording.State() == recording.StatePaused {
+		setState(s.cfg.Recording, recording.StateWaitingForKeyframe)
+	}
+}
+
 // Attach attach a child element
 func (s *WebmSaver) Attach(e avp.Element) {
 	s.sampleWriter.Attach(e)
@@ -68,6 +197,7 @@ func (s *WebmSaver) Close() {
 	}
 
 	s.closed = true
+	setState(s.cfg.Recording, recording.StateFinalizing)
 
 	hasWriter := false
 	if s.audioWriter != nil {
@@ -85,23 +215,67 @@ func (s *WebmSaver) Close() {
 	if !hasWriter {
 		s.sampleWriter.Close()
 	}
+	s.meta.close()
+	if s.cfg.Subtitles != nil {
+		s.cfg.Subtitles.Close()
+	}
+}
+
+// pushText sends timed text to the subtitles, moved from the time of its
+// track to the file's timeline. Text before the first sample, or without
+// a capture time, keeps its time.
+func (s *WebmSaver) pushText(sample *avp.Sample) error {
+	text, ok := sample.Payload.(*Text)
+	if s.cfg.Subtitles == nil || !ok || s.closed {
+		return nil
+	}
+	if !sample.CaptureTime.IsZero() && !s.start.IsZero() {
+		placed := *text
+		placed.Start = sample.CaptureTime.Sub(s.start)
+		placed.End = placed.Start + text.End - text.Start
+		out := *sample
+		out.Payload = &placed
+		sample = &out
+	}
+	return s.cfg.Subtitles.Write(sample)
 }
 
 func (s *WebmSaver) pushOpus(sample *avp.Sample) {
 	if !s.cfg.Audio {
 		return
 	}
+	if s.audioDuration == 0 {
+		// Sources may use 2.5 to 120ms of audio per packet
+		if d, err := opus.PacketDuration(sample.Payload.([]byte)); err == nil {
+			s.audioDuration = d
+		}
+	}
 	if s.audioWriter == nil && !s.cfg.Video {
-		s.initWriter(0, 0)
+		s.initWriter(0, 0, sample.CaptureTime)
+	}
+	if s.cfg.Video && (s.videoPause.paused || s.cfg.Recording.State() == recording.StateWaitingForKeyframe) {
+		// Keep audio in step with video after resuming
+		s.meta.dropped(sample)
+		return
 	}
 	if s.audioWriter != nil {
-		if s.audioTimestamp == 0 {
-			s.audioTimestamp = sample.Timestamp
+		if !s.cfg.Video {
+			setState(s.cfg.Recording, recording.StateRecording)
 		}
-		t := (sample.Timestamp - s.audioTimestamp) / 48
-		if _, err := s.audioWriter.Write(true, int64(t), sample.Payload.([]byte)); err != nil {
+		if !s.audioClock.started {
+			s.audioOffset = s.offset(sample)
+			s.padAudio(0, s.audioOffset)
+		}
+		t, gap := s.audioGaps.at(s.audioOffset + roundMs(s.audioClock.since(sample, audioClockRate)))
+		at := s.audioPause.at(t, s.cfg.PauseGap.Milliseconds())
+		s.padAudio(gap+at-t, at)
+		t = at
+		if _, err := s.audioWriter.Write(true, t, sample.Payload.([]byte)); err != nil {
 			log.Errorf("audio writer err: %s", err)
+			s.meta.dropped(sample)
+			return
 		}
+		s.meta.recorded(sample)
 	}
 }
 
@@ -109,58 +283,217 @@ func (s *WebmSaver) pushVP8(sample *avp.Sample) {
 	if !s.cfg.Video {
 		return
 	}
-	payload := sample.Payload.([]byte)
+	payload, _ := sample.Payload.([]byte)
 	// Read VP8 header.
-	videoKeyframe := (payload[0]&0x1 == 0)
+	videoKeyframe := len(payload) > 0 && payload[0]&0x1 == 0
+	if len(payload) == 0 || videoKeyframe && len(payload) < 10 {
+		// Too short for its frame header
+		s.meta.dropped(sample)
+		return
+	}
 
+	var width, height int
 	if videoKeyframe {
 		// Keyframe has frame information.
 		raw := uint(payload[6]) | uint(payload[7])<<8 | uint(payload[8])<<16 | uint(payload[9])<<24
-		width := int(raw & 0x3FFF)
-		height := int((raw >> 16) & 0x3FFF)
+		width = int(raw & 0x3FFF)
+		height = int((raw >> 16) & 0x3FFF)
+
+		if s.videoWriter == nil && s.cfg.Colour == nil {
+			if info, err := colorspace.ParseVP8(payload); err == nil {
+				s.cfg.Colour = &info.Colour
+			}
+		}
+	}
+	s.pushVideo(sample, codecVP8, videoKeyframe, width, height, payload)
+}
 
+func (s *WebmSaver) pushVP9(sample *avp.Sample) {
+	if !s.cfg.Video {
+		return
+	}
+	// Keyframes have an uncompressed header with the frame size. Of a
+	// superframe, the first frame is the base layer.
+	payload := sample.Payload.([]byte)
+	info, err := colorspace.ParseVP9(payload)
+	if err != nil && err != colorspace.ErrNotKeyframe {
+		log.Debugf("WebM saver: reading VP9 frame header: %s", err)
+	}
+	videoKeyframe := err == nil
+	if videoKeyframe && s.videoWriter == nil && s.cfg.Colour == nil {
+		s.cfg.Colour = &info.Colour
+	}
+	s.pushVideo(sample, codecVP9, videoKeyframe, info.Width, info.Height, payload)
+}
+
+func (s *WebmSaver) pushAV1(sample *avp.Sample) {
+	if !s.cfg.Video {
+		return
+	}
+	// Temporal units starting a sequence carry its header, which the
+	// track's codec configuration holds
+	payload := sample.Payload.([]byte)
+	info, err := colorspace.ParseAV1(payload)
+	if err != nil && err != colorspace.ErrNotKeyframe {
+		log.Debugf("WebM saver: reading AV1 sequence header: %s", err)
+	}
+	videoKeyframe := err == nil
+	if videoKeyframe && s.videoWriter == nil {
+		config, err := colorspace.AV1CodecConfig(payload)
+		if err != nil {
+			log.Errorf("WebM saver: reading AV1 sequence header: %s", err)
+			return
+		}
+		s.videoPrivate = config
+		if s.cfg.Colour == nil {
+			s.cfg.Colour = &info.Colour
+		}
+	}
+	s.pushVideo(sample, codecAV1, videoKeyframe, info.Width, info.Height, payload)
+}
+
+func (s *WebmSaver) pushH264(sample *avp.Sample) {
+	if !s.cfg.Video {
+		return
+	}
+	payload := sample.Payload.([]byte)
+	f := readAVC(payload)
+	var info colorspace.Info
+	if f.configurable() {
+		var err error
+		if info, err = colorspace.ParseH264(payload); err != nil && s.videoWriter == nil {
+			log.Errorf("WebM saver: reading SPS: %s", err)
+			s.meta.dropped(sample)
+			return
+		}
+	}
+	if s.videoWriter == nil && f.configurable() {
+		if s.cfg.Colour == nil {
+			s.cfg.Colour = &info.Colour
+		}
+		s.videoPrivate = avcConfig(f.sps, f.pps)
+	}
+	if len(f.data) == 0 {
+		return
+	}
+	// The file starts at a keyframe with the parameter sets to decode it
+	videoKeyframe := f.key && (s.videoWriter != nil || f.configurable())
+	s.pushVideo(sample, codecH264, videoKeyframe, info.Width, info.Height, f.data)
+}
+
+// pushVideo writes a frame of the codec, starting the file at the first
+// keyframe of width by height. Frames of another codec than the file's are
+// dropped.
+func (s *WebmSaver) pushVideo(sample *avp.Sample, codec string, videoKeyframe bool, width, height int, frame []byte) {
+	if s.videoWriter != nil && codec != s.videoCodec {
+		s.meta.dropped(sample)
+		return
+	}
+	if videoKeyframe {
 		if s.videoWriter == nil {
 			// Initialize WebM saver using received frame size.
-			s.initWriter(width, height)
+			s.videoCodec = codec
+			s.initWriter(width, height, sample.CaptureTime)
+			s.width, s.height = width, height
+		} else if width > 0 && (width != s.width || height != s.height) {
+			// The track can't be resized once written, SegmentedSaver
+			// starts a new file
+			log.Warnf("WebM saver: video resized from %dx%d to %dx%d, which the file's track can't follow", s.width, s.height, width, height)
+			s.width, s.height = width, height
 		}
+	} else if s.videoWriter == nil || s.videoPause.paused || s.cfg.Recording.State() == recording.StateWaitingForKeyframe {
+		setState(s.cfg.Recording, recording.StateWaitingForKeyframe)
+		s.meta.dropped(sample)
+		return
 	}
 
 	if s.videoWriter != nil {
-		if s.videoTimestamp == 0 {
-			s.videoTimestamp = sample.Timestamp
+		if !s.videoClock.started {
+			s.videoOffset = s.offset(sample)
 		}
-		t := (sample.Timestamp - s.videoTimestamp) / 90
-		if _, err := s.videoWriter.Write(videoKeyframe, int64(t), payload); err != nil {
+		// Video gaps aren't filled, players show the last frame
+		t, _ := s.videoGaps.at(s.videoOffset + roundMs(s.videoClock.since(sample, videoClockRate)))
+		t = s.videoPause.at(t, s.cfg.PauseGap.Milliseconds())
+		if _, err := s.videoWriter.Write(videoKeyframe, t, frame); err != nil {
 			log.Errorf("video write err: %s", err)
+			s.meta.dropped(sample)
+		} else {
+			s.meta.recorded(sample)
+		}
+		setState(s.cfg.Recording, recording.StateRecording)
+	}
+}
+
+// opusSilence is a 20ms Opus frame of silence
+var opusSilence = []byte{0xf8, 0xff, 0xfe}
+
+// offset returns the milliseconds from the epoch to the capture of the
+// first sample of a track, or its arrival when the capture time is unknown
+func (s *WebmSaver) offset(sample *avp.Sample) int64 {
+	if s.cfg.Epoch.IsZero() {
+		return 0
+	}
+	at := sample.CaptureTime
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if d := at.Sub(s.cfg.Epoch); d > 0 {
+		return d.Milliseconds()
+	}
+	return 0
+}
+
+// padAudio fills the audio track with silence from ms until to ms, e.g.
+// up to its first sample
+func (s *WebmSaver) padAudio(from, to int64) {
+	for t := from; t+20 <= to; t += 20 {
+		if _, err := s.audioWriter.Write(true, t, opusSilence); err != nil {
+			log.Errorf("audio writer err: %s", err)
+			return
 		}
 	}
 }
 
-func (s *WebmSaver) initWriter(width, height int) {
-	options := []mkvcore.BlockWriterOption{
-		mkvcore.WithSegmentInfo(&webm.Info{
-			TimecodeScale: webm.DefaultSegmentInfo.TimecodeScale,
-			MuxingApp:     webm.DefaultSegmentInfo.MuxingApp,
-			WritingApp:    webm.DefaultSegmentInfo.WritingApp,
-			DateUTC:       time.Now(),
-		}),
-		mkvcore.WithSeekHead(true),
+// initWriter starts the file, dated by the epoch it is aligned to or the
+// capture time of its first sample when known
+func (s *WebmSaver) initWriter(width, height int, captured time.Time) {
+	if !s.cfg.Epoch.IsZero() {
+		captured = s.cfg.Epoch
+	} else if captured.IsZero() {
+		captured = time.Now()
 	}
+	s.start = captured
+	info := &webm.Info{
+		TimecodeScale: webm.DefaultSegmentInfo.TimecodeScale,
+		MuxingApp:     webm.DefaultSegmentInfo.MuxingApp,
+		WritingApp:    webm.DefaultSegmentInfo.WritingApp,
+		DateUTC:       captured,
+	}
+	durations := map[uint64]blockDuration{}
 	var tracks []webm.TrackEntry
 	var audioIdx, videoIdx int
 	if s.cfg.Audio {
+		// Before any audio arrives, assume the usual 20ms packets
+		duration := s.audioDuration
+		if duration == 0 {
+			duration = 20 * time.Millisecond
+		}
 		tracks = append(tracks, webm.TrackEntry{
 			Name:            "Audio",
 			TrackNumber:     1,
 			TrackUID:        12345,
 			CodecID:         "A_OPUS",
 			TrackType:       2,
-			DefaultDuration: 20000000,
+			DefaultDuration: uint64(duration),
 			Audio: &webm.Audio{
 				SamplingFrequency: 48000.0,
 				Channels:          2,
 			},
 		})
+		durations[1] = func(b []byte) time.Duration {
+			d, _ := opus.PacketDuration(b)
+			return d
+		}
 		audioIdx = 0
 	}
 	if s.cfg.Video {
@@ -173,21 +506,33 @@ func (s *WebmSaver) initWriter(width, height int) {
 			videoIdx = 0
 		}
 		tracks = append(tracks, webm.TrackEntry{
-			Name:            "Video",
-			TrackNumber:     trackNum,
-			TrackUID:        67890,
-			CodecID:         "V_VP8",
-			TrackType:       1,
-			DefaultDuration: 20000000,
+			Name:         "Video",
+			TrackNumber:  trackNum,
+			TrackUID:     67890,
+			CodecID:      s.videoCodec,
+			CodecPrivate: s.videoPrivate,
+			TrackType:    1,
 			Video: &webm.Video{
 				PixelWidth:  uint64(width),
 				PixelHeight: uint64(height),
 			},
 		})
 	}
-	ws, err := webm.NewSimpleBlockWriter(s.sampleWriter, tracks, options...)
+	var ws []webm.BlockWriteCloser
+	var err error
+	if s.cfg.Muxer != nil {
+		ws, err = s.cfg.Muxer(s.sampleWriter, tracks, info)
+	} else {
+		colours := map[uint64]*colorspace.Colour{}
+		if s.cfg.Video {
+			colours[tracks[videoIdx].TrackNumber] = s.cfg.Colour
+		}
+		ws, err = newBlockWriter(s.sampleWriter, tracks, info, durations, colours)
+	}
 	if err != nil {
 		log.Errorf("init writer err: %s", err)
+		s.cfg.Recording.Fail(err)
+		return
 	}
 	var msg string
 	if s.cfg.Audio {
@@ -196,7 +541,7 @@ func (s *WebmSaver) initWriter(width, height int) {
 	}
 	if s.cfg.Video {
 		s.videoWriter = ws[videoIdx]
-		msg = fmt.Sprintf("video width=%d, height=%d", width, height)
+		msg = fmt.Sprintf("%s video width=%d, height=%d", s.videoCodec, width, height)
 	}
 	log.Infof("WebM saver has started with %s", msg)
 }
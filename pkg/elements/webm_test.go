@@ -2,12 +2,21 @@ package elements
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/at-wat/ebml-go"
+	"github.com/at-wat/ebml-go/mkvcore"
 	"github.com/at-wat/ebml-go/webm"
 	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/colorspace"
+	"github.com/pion/ion-avp/pkg/recording"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,3 +98,521 @@ func TestWebMSave_AudioOnly(t *testing.T) {
 
 	saver.Close()
 }
+
+func TestWebMSaver_OpusDuration(t *testing.T) {
+	for _, tc := range []struct {
+		cfg      WebmSaverConfig
+		duration uint64
+	}{
+		// 10ms SILK packets
+		{WebmSaverConfig{Audio: true}, 10000000},
+		// Video starts the file before audio arrives
+		{WebmSaverConfig{Audio: true, Video: true}, 20000000},
+	} {
+		saver := NewWebmSaver(&tc.cfg)
+		writer := NewBufWriter()
+		saver.Attach(writer)
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt}))
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt}))
+
+		var header Header
+		writer.Lock()
+		assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+		writer.Unlock()
+		assert.Equal(t, tc.duration, header.Segment.Tracks.TrackEntry[0].DefaultDuration)
+		saver.Close()
+	}
+}
+
+func TestWebMSaver_Colour(t *testing.T) {
+	colour := &colorspace.Colour{MatrixCoefficients: 1, Range: colorspace.RangeFull, Primaries: 1, TransferCharacteristics: 1}
+	saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Video: true, Colour: colour})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt}))
+
+	var header struct {
+		Segment struct {
+			Tracks struct {
+				TrackEntry []mkvTrackEntry `ebml:"TrackEntry"`
+			} `ebml:"Tracks"`
+		} `ebml:"Segment,size=unknown"`
+	}
+	writer.Lock()
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+	writer.Unlock()
+	tracks := header.Segment.Tracks.TrackEntry
+	assert.Len(t, tracks, 2)
+	assert.Nil(t, tracks[0].Video)
+	assert.Equal(t, colour, tracks[1].Video.Colour)
+	saver.Close()
+
+	// Read from the keyframe
+	saver = NewWebmSaver(nil)
+	writer = NewBufWriter()
+	saver.Attach(writer)
+	keyframe := []byte{0x50, 0x42, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01, 0x00, 0x47}
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: keyframe}))
+	header.Segment.Tracks.TrackEntry = nil
+	writer.Lock()
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+	writer.Unlock()
+	assert.Equal(t, &colorspace.Colour{
+		MatrixCoefficients: 6, BitsPerChannel: 8, Range: colorspace.RangeLimited,
+		ChromaSubsamplingHorz: 1, ChromaSubsamplingVert: 1,
+	}, header.Segment.Tracks.TrackEntry[1].Video.Colour)
+	saver.Close()
+}
+
+func TestWebMSaver_VP9(t *testing.T) {
+	saver := NewWebmSaver(&WebmSaverConfig{Video: true})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// Waits for a keyframe
+	interframe := []byte{0x86, 0x00}
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP9, Payload: interframe}))
+	writer.Lock()
+	assert.Zero(t, writer.buf.Len())
+	writer.Unlock()
+
+	// Profile 0 keyframe, BT.709 limited range, 640x480
+	keyframe := []byte{0x82, 0x49, 0x83, 0x42, 0x40, 0x27, 0xf0, 0x1d, 0xf0}
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP9, Payload: keyframe}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP9, Payload: interframe, Timestamp: 3000}))
+	// Frames of another codec don't belong in the track
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt, Timestamp: 6000}))
+	saver.Close()
+
+	var file struct {
+		Segment struct {
+			Tracks struct {
+				TrackEntry []mkvTrackEntry `ebml:"TrackEntry"`
+			} `ebml:"Tracks"`
+			Cluster []struct {
+				BlockGroup []struct {
+					Block ebml.Block `ebml:"Block"`
+				} `ebml:"BlockGroup"`
+				SimpleBlock []ebml.Block `ebml:"SimpleBlock"`
+			} `ebml:"Cluster"`
+		} `ebml:"Segment,size=unknown"`
+	}
+	writer.Lock()
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &file))
+	writer.Unlock()
+	tracks := file.Segment.Tracks.TrackEntry
+	assert.Len(t, tracks, 1)
+	assert.Equal(t, codecVP9, tracks[0].CodecID)
+	assert.Equal(t, uint64(640), tracks[0].Video.PixelWidth)
+	assert.Equal(t, uint64(480), tracks[0].Video.PixelHeight)
+	assert.Equal(t, uint64(1), tracks[0].Video.Colour.MatrixCoefficients)
+
+	var frames [][]byte
+	for _, c := range file.Segment.Cluster {
+		for _, g := range c.BlockGroup {
+			frames = append(frames, g.Block.Data...)
+		}
+		for _, b := range c.SimpleBlock {
+			frames = append(frames, b.Data...)
+		}
+	}
+	assert.ElementsMatch(t, [][]byte{keyframe, interframe}, frames)
+}
+
+func TestWebMSaver_AV1(t *testing.T) {
+	saver := NewWebmSaver(&WebmSaverConfig{Video: true})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// Reduced still picture sequence header of 320x240 8 bit 4:2:0 video,
+	// at level 2.0
+	sequence := []byte{0x0a, 0x07, 0x19, 0x21, 0xe7, 0xfd, 0xe0, 0x00, 0x00}
+	frame := []byte{0x32, 0x02, 0x10, 0x00}
+	keyframe := append(append([]byte(nil), sequence...), frame...)
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeAV1, Payload: frame}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeAV1, Payload: keyframe, Timestamp: 3000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeAV1, Payload: frame, Timestamp: 6000}))
+	saver.Close()
+
+	var file struct {
+		Header  webm.EBMLHeader `ebml:"EBML"`
+		Segment struct {
+			Tracks struct {
+				TrackEntry []mkvTrackEntry `ebml:"TrackEntry"`
+			} `ebml:"Tracks"`
+		} `ebml:"Segment,size=unknown"`
+	}
+	writer.Lock()
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &file))
+	writer.Unlock()
+	assert.Equal(t, "webm", file.Header.DocType)
+	tracks := file.Segment.Tracks.TrackEntry
+	assert.Len(t, tracks, 1)
+	assert.Equal(t, codecAV1, tracks[0].CodecID)
+	assert.Equal(t, uint64(320), tracks[0].Video.PixelWidth)
+	assert.Equal(t, uint64(240), tracks[0].Video.PixelHeight)
+	assert.Equal(t, append([]byte{0x81, 0x04, 0x0c, 0x00}, sequence...), tracks[0].CodecPrivate)
+}
+
+func TestWebMSaver_H264(t *testing.T) {
+	saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Video: true})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// A keyframe without its parameter sets can't start the file
+	idr := []byte{0, 0, 0, 1, 0x65, 0x88, 0x84, 0x00}
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Payload: idr}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Payload: h264Keyframe, Timestamp: 3000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Payload: h264Frame, Timestamp: 6000}))
+	saver.Close()
+
+	var file struct {
+		Header  webm.EBMLHeader `ebml:"EBML"`
+		Segment struct {
+			Tracks struct {
+				TrackEntry []mkvTrackEntry `ebml:"TrackEntry"`
+			} `ebml:"Tracks"`
+			Cluster []struct {
+				BlockGroup []struct {
+					Block ebml.Block `ebml:"Block"`
+				} `ebml:"BlockGroup"`
+				SimpleBlock []ebml.Block `ebml:"SimpleBlock"`
+			} `ebml:"Cluster"`
+		} `ebml:"Segment,size=unknown"`
+	}
+	writer.Lock()
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &file))
+	writer.Unlock()
+	assert.Equal(t, "matroska", file.Header.DocType)
+	tracks := file.Segment.Tracks.TrackEntry
+	assert.Len(t, tracks, 2)
+	assert.Equal(t, codecH264, tracks[1].CodecID)
+	assert.Equal(t, uint64(640), tracks[1].Video.PixelWidth)
+	assert.Equal(t, uint64(480), tracks[1].Video.PixelHeight)
+	// AVCDecoderConfigurationRecord of the parameter sets
+	assert.Equal(t, []byte{
+		1, 0x42, 0x00, 0x1e, 0xff,
+		0xe1, 0, 9, 0x67, 0x42, 0x00, 0x1e, 0x95, 0xa8, 0x28, 0x0f, 0x64,
+		1, 0, 4, 0x68, 0xce, 0x3c, 0x80,
+	}, tracks[1].CodecPrivate)
+
+	// Frames are length prefixed NAL units without parameter sets
+	var frames [][]byte
+	for _, c := range file.Segment.Cluster {
+		for _, g := range c.BlockGroup {
+			if g.Block.TrackNumber == 2 {
+				frames = append(frames, g.Block.Data...)
+			}
+		}
+		for _, b := range c.SimpleBlock {
+			if b.TrackNumber == 2 {
+				frames = append(frames, b.Data...)
+			}
+		}
+	}
+	assert.ElementsMatch(t, [][]byte{
+		{0, 0, 0, 4, 0x65, 0x88, 0x84, 0x00},
+		{0, 0, 0, 3, 0x41, 0x9a, 0x02},
+	}, frames)
+}
+
+func TestWebMSaver_Muxer(t *testing.T) {
+	var muxed []webm.TrackEntry
+	saver := NewWebmSaver(&WebmSaverConfig{
+		Audio: true,
+		Video: true,
+		Muxer: func(w io.WriteCloser, tracks []webm.TrackEntry, info *webm.Info) ([]webm.BlockWriteCloser, error) {
+			muxed = tracks
+			info.WritingApp = "test"
+			return SimpleBlockMuxer(mkvcore.WithSeekHead(false))(w, tracks, info)
+		},
+	})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Payload: rawOpusPkt}))
+	saver.Close()
+
+	assert.Len(t, muxed, 2)
+	assert.Equal(t, "V_VP8", muxed[1].CodecID)
+
+	var header Header
+	writer.Lock()
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+	writer.Unlock()
+	assert.Equal(t, "test", header.Segment.Info.WritingApp)
+	assert.Nil(t, header.Segment.SeekHead)
+}
+
+func TestWebMSaver_CaptureTime(t *testing.T) {
+	saver := NewWebmSaver(&WebmSaverConfig{Audio: true})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	captured := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, saver.Write(&avp.Sample{
+		Type:        avp.TypeOpus,
+		CaptureTime: captured,
+		Payload:     rawOpusPkt,
+	}))
+
+	var header Header
+	writer.Lock()
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+	writer.Unlock()
+	assert.True(t, captured.Equal(header.Segment.Info.DateUTC), header.Segment.Info.DateUTC)
+
+	saver.Close()
+}
+
+func TestWebMSaver_Epoch(t *testing.T) {
+	epoch := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Epoch: epoch})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{
+			Type:        avp.TypeOpus,
+			Timestamp:   uint32(1000 + i*960),
+			CaptureTime: epoch.Add(time.Second),
+			Payload:     rawOpusPkt,
+		}))
+	}
+	saver.Close()
+
+	var header Header
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+	assert.True(t, epoch.Equal(header.Segment.Info.DateUTC))
+
+	// A second of silence leads the first sample
+	var times []int64
+	for _, c := range header.Segment.Cluster {
+		for _, b := range c.SimpleBlock {
+			times = append(times, int64(c.Timecode)+int64(b.Timecode))
+		}
+		// 20ms silence in a 10ms track, and the last block, state durations
+		for _, g := range c.BlockGroup {
+			times = append(times, int64(c.Timecode)+int64(g.Block.Timecode))
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	assert.Len(t, times, 52)
+	assert.Equal(t, int64(0), times[0])
+	assert.Equal(t, int64(980), times[49])
+	assert.Equal(t, int64(1000), times[50])
+	assert.Equal(t, int64(1020), times[51])
+}
+
+func TestWebMSaver_EpochVideo(t *testing.T) {
+	epoch := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	saver := NewWebmSaver(&WebmSaverConfig{Video: true, Epoch: epoch})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, saver.Write(&avp.Sample{
+			Type:        avp.TypeVP8,
+			Timestamp:   uint32(5000 + i*3000),
+			CaptureTime: epoch.Add(time.Second),
+			Payload:     rawKeyframePkt,
+		}))
+	}
+	saver.Close()
+
+	var header Header
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+	// Without audio to pad, video starts a second into the file too
+	var times []int64
+	for _, c := range header.Segment.Cluster {
+		for _, b := range c.SimpleBlock {
+			times = append(times, int64(c.Timecode)+int64(b.Timecode))
+		}
+		for _, g := range c.BlockGroup {
+			times = append(times, int64(c.Timecode)+int64(g.Block.Timecode))
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	assert.Equal(t, []int64{1000, 1033, 1067}, times)
+}
+
+func TestWebMSaver_Subtitles(t *testing.T) {
+	captured := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	text := func(at time.Duration) *avp.Sample {
+		return &avp.Sample{Type: TypeText, CaptureTime: captured.Add(at - time.Second), Payload: &Text{Text: "Hi", Start: at - time.Second, End: at, Final: true}}
+	}
+	for _, c := range []struct {
+		epoch time.Time
+		want  string
+	}{
+		// From the first sample
+		{want: "00:00:01.000 --> 00:00:02.000\nHi\n\n00:00:03.000 --> 00:00:04.000\nHi\n\n"},
+		// From the session start
+		{epoch: captured.Add(-time.Minute), want: "00:00:01.000 --> 00:00:02.000\nHi\n\n00:01:03.000 --> 00:01:04.000\nHi\n\n"},
+	} {
+		subtitles, err := NewSubtitleWriter(SubtitleWriterConfig{})
+		assert.NoError(t, err)
+		vtt := NewBufWriter()
+		subtitles.Attach(vtt)
+		saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Epoch: c.epoch, Subtitles: subtitles})
+		saver.Attach(NewBufWriter())
+
+		// Before the file starts, text keeps its time
+		assert.NoError(t, saver.Write(text(2*time.Second)))
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, CaptureTime: captured, Payload: rawOpusPkt}))
+		assert.NoError(t, saver.Write(text(4*time.Second)))
+		saver.Close()
+		assert.Equal(t, "WEBVTT\n\n"+c.want, vtt.buf.String())
+	}
+}
+
+func TestWebMSaver_HighFrameRate(t *testing.T) {
+	saver := NewWebmSaver(&WebmSaverConfig{Video: true})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// 120fps at 90kHz from a timestamp of 0
+	for i := 0; i < 7; i++ {
+		payload := rawKeyframePkt
+		if i > 0 {
+			payload = []byte{0x01, 0x00}
+		}
+		assert.NoError(t, saver.Write(&avp.Sample{
+			Type:      avp.TypeVP8,
+			Timestamp: uint32(i * 750),
+			ClockRate: 90000,
+			Payload:   payload,
+		}))
+	}
+	saver.Close()
+
+	var header Header
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+	var times []int64
+	for _, c := range header.Segment.Cluster {
+		for _, b := range c.SimpleBlock {
+			times = append(times, int64(c.Timecode)+int64(b.Timecode))
+		}
+		for _, g := range c.BlockGroup {
+			times = append(times, int64(c.Timecode)+int64(g.Block.Timecode))
+		}
+	}
+	assert.Equal(t, []int64{0, 8, 17, 25, 33, 42, 50}, times)
+}
+
+func TestWebMSaver_Pause(t *testing.T) {
+	for _, tc := range []struct {
+		gap   time.Duration
+		times []int64
+	}{
+		{0, []int64{0, 20, 40, 60, 80}},
+		{time.Second, []int64{0, 20, 40, 1060, 1080}},
+	} {
+		saver := NewWebmSaver(&WebmSaverConfig{Audio: true, PauseGap: tc.gap})
+		writer := NewBufWriter()
+		saver.Attach(writer)
+		write := func(i int) {
+			assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: rawOpusPkt}))
+		}
+
+		for i := 0; i < 3; i++ {
+			write(i)
+		}
+		saver.Pause()
+		for i := 3; i < 250; i++ {
+			write(i)
+		}
+		saver.Resume()
+		write(250)
+		write(251)
+		saver.Close()
+
+		var header Header
+		assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+		var times []int64
+		for _, c := range header.Segment.Cluster {
+			for _, b := range c.SimpleBlock {
+				times = append(times, int64(c.Timecode)+int64(b.Timecode))
+			}
+			for _, g := range c.BlockGroup {
+				times = append(times, int64(c.Timecode)+int64(g.Block.Timecode))
+			}
+		}
+		assert.Equal(t, tc.times, times)
+	}
+}
+
+func TestWebMSaver_Gaps(t *testing.T) {
+	for _, tc := range []struct {
+		policy GapPolicy
+		blocks int
+		last   int64
+	}{
+		{GapKeep, 5, 3020},
+		// Silence from the end of the last block to the next
+		{GapFill, 152, 3020},
+		{GapClamp, 5, 1080},
+		{GapRebase, 5, 80},
+	} {
+		saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Gaps: tc.policy})
+		writer := NewBufWriter()
+		saver.Attach(writer)
+		// 3s of packets lost after the third
+		for _, i := range []int{0, 1, 2, 150, 151} {
+			assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(i * 960), Payload: rawOpusPkt}))
+		}
+		saver.Close()
+
+		var header Header
+		assert.NoError(t, ebml.Unmarshal(bytes.NewReader(writer.buf.Bytes()), &header))
+		var times []int64
+		for _, c := range header.Segment.Cluster {
+			for _, b := range c.SimpleBlock {
+				times = append(times, int64(c.Timecode)+int64(b.Timecode))
+			}
+			for _, g := range c.BlockGroup {
+				times = append(times, int64(c.Timecode)+int64(g.Block.Timecode))
+			}
+		}
+		if assert.Len(t, times, tc.blocks, tc.policy.String()) {
+			assert.Equal(t, tc.last, times[len(times)-1], tc.policy.String())
+		}
+	}
+}
+
+func TestWebMSaver_Recording(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webm")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rec := recording.NewTracker(recording.Config{}).Start("sid", "tid", "rec.webm")
+	saver := NewWebmSaver(&WebmSaverConfig{Audio: true, Video: true, Recording: rec})
+	writer := NewFileWriter(filepath.Join(dir, "rec.webm"), 0)
+	writer.SetRecording(rec)
+	saver.Attach(writer)
+
+	interframe := append([]byte{rawKeyframePkt[0] | 1}, rawKeyframePkt[1:]...)
+	write := func(payload []byte) {
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: payload}))
+	}
+
+	assert.Equal(t, recording.StatePending, rec.State())
+	write(interframe)
+	assert.Equal(t, recording.StateWaitingForKeyframe, rec.State())
+	write(rawKeyframePkt)
+	assert.Equal(t, recording.StateRecording, rec.State())
+
+	assert.NoError(t, rec.Pause())
+	write(rawKeyframePkt)
+	assert.Equal(t, recording.StatePaused, rec.State())
+
+	assert.NoError(t, rec.Resume())
+	write(interframe)
+	assert.Equal(t, recording.StateWaitingForKeyframe, rec.State())
+	write(rawKeyframePkt)
+	assert.Equal(t, recording.StateRecording, rec.State())
+
+	saver.Close()
+	assert.Equal(t, recording.StateComplete, rec.State())
+}
@@ -0,0 +1,202 @@
+package elements
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// y4mMaxRepeat bounds how long a frame is repeated for to fill a gap,
+// so a publisher gone quiet for an hour doesn't write an hour of copies
+const y4mMaxRepeat = 10 * time.Second
+
+// y4mFormats are the chroma subsampling ratios written, with their Y4M
+// colourspace and pixel format names
+var y4mFormats = map[image.YCbCrSubsampleRatio]struct{ y4m, pix string }{
+	image.YCbCrSubsampleRatio420: {"420jpeg", "yuv420p"},
+	image.YCbCrSubsampleRatio422: {"422", "yuv422p"},
+	image.YCbCrSubsampleRatio444: {"444", "yuv444p"},
+}
+
+// Y4mDescriptor describes the frames of a raw YUV stream, which unlike
+// Y4M has no header of its own
+type Y4mDescriptor struct {
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Format    string `json:"format"`
+	FrameRate int    `json:"fps"`
+}
+
+// Y4mSaverConfig configures Y4mSaver.
+// FrameRate: Of the output in frames per second, 30 by default.
+// Raw: Write the planes of each frame alone, without the Y4M header and
+// frame markers.
+// Descriptor: Optional element sent a Y4mDescriptor as JSON once the size
+// of frames is known, e.g. a FileWriter next to a raw stream's.
+// Recording: Optional state machine moved along as the recording progresses.
+type Y4mSaverConfig struct {
+	FrameRate  int
+	Raw        bool
+	Descriptor avp.Element
+	Recording  *recording.Recording
+}
+
+// Y4mSaver instance
+type Y4mSaver struct {
+	sync.Mutex
+	cfg          Y4mSaverConfig
+	sampleWriter *SampleWriter
+	clock        trackClock
+	rect         image.Rectangle
+	ratio        image.YCbCrSubsampleRatio
+	pending      []byte // planes of the frame shown at slot
+	slot         int64  // frame interval of pending
+	next         int64  // first interval not written
+	started      bool
+	closed       bool
+}
+
+// NewY4mSaver instance. Y4mSaver takes as input YCbCr frames, e.g. from a
+// Decoder or Scaler, and writes them uncompressed to its children, such
+// as a FileWriter, as a YUV4MPEG2 stream or raw planar YUV, for computer
+// vision pipelines reading frames without a decoder. Output is at a
+// constant frame rate: frames are placed by their RTP timestamps, repeated
+// to fill gaps and dropped when more than one falls in an interval. All
+// frames are scaled to the size of the first.
+func NewY4mSaver(c Y4mSaverConfig) *Y4mSaver {
+	if c.FrameRate <= 0 {
+		c.FrameRate = 30
+	}
+	return &Y4mSaver{cfg: c, sampleWriter: NewSampleWriter()}
+}
+
+func (s *Y4mSaver) Write(sample *avp.Sample) error {
+	src, ok := sample.Payload.(*image.YCbCr)
+	if sample.Type != TypeYCbCr || !ok || src.Rect.Empty() {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || s.cfg.Recording.State() == recording.StatePaused {
+		return nil
+	}
+
+	if !s.started {
+		if err := s.start(src); err != nil {
+			return err
+		}
+	}
+	if src.SubsampleRatio != s.ratio {
+		return nil
+	}
+	if src.Rect.Dx() != s.rect.Dx() || src.Rect.Dy() != s.rect.Dy() {
+		dst := image.NewYCbCr(s.rect, s.ratio)
+		if err := pixel.ScaleFit(dst, src, pixel.FitPad); err != nil {
+			return err
+		}
+		src = dst
+	}
+	frame, err := pixel.AppendPlanar(nil, src)
+	if err != nil {
+		return err
+	}
+
+	t := s.clock.since(sample, videoClockRate)
+	slot := (int64(t)*int64(s.cfg.FrameRate) + int64(time.Second)/2) / int64(time.Second)
+	if s.pending != nil && slot > s.slot {
+		if err := s.flush(slot); err != nil {
+			return err
+		}
+	}
+	if s.pending == nil || slot >= s.slot {
+		s.pending, s.slot = frame, slot
+	}
+	return nil
+}
+
+// start writes the header for frames the size and ratio of m
+func (s *Y4mSaver) start(m *image.YCbCr) error {
+	f, ok := y4mFormats[m.SubsampleRatio]
+	if !ok {
+		return nil
+	}
+	s.rect, s.ratio = image.Rect(0, 0, m.Rect.Dx(), m.Rect.Dy()), m.SubsampleRatio
+	s.started = true
+	if !s.cfg.Raw {
+		hdr := fmt.Sprintf("YUV4MPEG2 W%d H%d F%d:1 Ip A1:1 C%s\n", s.rect.Dx(), s.rect.Dy(), s.cfg.FrameRate, f.y4m)
+		if err := s.write([]byte(hdr)); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Descriptor != nil {
+		desc, _ := json.Marshal(Y4mDescriptor{Width: s.rect.Dx(), Height: s.rect.Dy(), Format: f.pix, FrameRate: s.cfg.FrameRate})
+		if err := s.cfg.Descriptor.Write(&avp.Sample{Type: TypeBinary, Payload: desc}); err != nil {
+			return err
+		}
+	}
+	setState(s.cfg.Recording, recording.StateRecording)
+	log.Infof("Y4M saver has started at %dx%d %s, %d fps", s.rect.Dx(), s.rect.Dy(), f.pix, s.cfg.FrameRate)
+	return nil
+}
+
+// flush writes the pending frame for each interval until end
+func (s *Y4mSaver) flush(end int64) error {
+	if s.next < s.slot {
+		s.next = s.slot
+	}
+	if max := s.slot + int64(y4mMaxRepeat.Seconds())*int64(s.cfg.FrameRate); end > max {
+		log.Debugf("Y4M saver: skipping %d frames of gap", end-max)
+		end = max
+	}
+	for ; s.next < end; s.next++ {
+		if !s.cfg.Raw {
+			if err := s.write([]byte("FRAME\n")); err != nil {
+				return err
+			}
+		}
+		if err := s.write(s.pending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Y4mSaver) write(b []byte) error {
+	if _, err := s.sampleWriter.Write(b); err != nil {
+		s.cfg.Recording.Fail(err)
+		return err
+	}
+	return nil
+}
+
+// Attach attach a child element
+func (s *Y4mSaver) Attach(e avp.Element) {
+	s.sampleWriter.Attach(e)
+}
+
+// Close writes the last frame and closes the children
+func (s *Y4mSaver) Close() {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	setState(s.cfg.Recording, recording.StateFinalizing)
+	if s.pending != nil {
+		if err := s.flush(s.slot + 1); err != nil {
+			log.Errorf("Y4M saver: writing last frame: %s", err)
+		}
+	}
+	if s.cfg.Descriptor != nil {
+		s.cfg.Descriptor.Close()
+	}
+	s.sampleWriter.Close()
+}
@@ -0,0 +1,67 @@
+package elements
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"testing"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// solidFrame returns a frame of w by h with luma y
+func solidFrame(w, h int, y byte) *image.YCbCr {
+	m := image.NewYCbCr(image.Rect(0, 0, w, h), image.YCbCrSubsampleRatio420)
+	for i := range m.Y {
+		m.Y[i] = y
+	}
+	return m
+}
+
+func TestY4mSaver(t *testing.T) {
+	saver := NewY4mSaver(Y4mSaverConfig{FrameRate: 10})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	// 10fps is 9000 ticks a frame: frame 2 replaces 1 in the first
+	// interval, 3 is repeated over a gap of two and 4 is scaled down
+	assert.NoError(t, saver.Write(&avp.Sample{Type: TypeYCbCr, Payload: solidFrame(4, 2, 1), Timestamp: 1000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: TypeYCbCr, Payload: solidFrame(4, 2, 2), Timestamp: 3000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: TypeYCbCr, Payload: solidFrame(4, 2, 3), Timestamp: 10000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeVP8, Payload: rawKeyframePkt, Timestamp: 12000}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: TypeYCbCr, Payload: solidFrame(8, 4, 4), Timestamp: 28000}))
+	saver.Close()
+
+	frames := bytes.Split(writer.buf.Bytes(), []byte("FRAME\n"))
+	assert.Equal(t, "YUV4MPEG2 W4 H2 F10:1 Ip A1:1 C420jpeg\n", string(frames[0]))
+	var lumas []byte
+	for _, f := range frames[1:] {
+		// 4x2 luma and 2x1 of each chroma
+		if assert.Len(t, f, 12) {
+			lumas = append(lumas, f[0])
+		}
+	}
+	assert.Equal(t, []byte{2, 3, 3, 4}, lumas)
+}
+
+func TestY4mSaver_Raw(t *testing.T) {
+	desc := NewBufWriter()
+	saver := NewY4mSaver(Y4mSaverConfig{Raw: true, Descriptor: desc})
+	writer := NewBufWriter()
+	saver.Attach(writer)
+
+	assert.NoError(t, saver.Write(&avp.Sample{Type: TypeYCbCr, Payload: solidFrame(4, 2, 1), Timestamp: 0}))
+	assert.NoError(t, saver.Write(&avp.Sample{Type: TypeYCbCr, Payload: solidFrame(4, 2, 2), Timestamp: 3000}))
+	saver.Close()
+
+	out := writer.buf.Bytes()
+	if assert.Len(t, out, 24) {
+		assert.Equal(t, bytes.Repeat([]byte{1}, 8), out[:8])
+		assert.Equal(t, bytes.Repeat([]byte{2}, 8), out[12:20])
+	}
+
+	var d Y4mDescriptor
+	assert.NoError(t, json.Unmarshal(desc.buf.Bytes(), &d))
+	assert.Equal(t, Y4mDescriptor{Width: 4, Height: 2, Format: "yuv420p", FrameRate: 30}, d)
+}
@@ -4,4 +4,12 @@ import "errors"
 
 var (
 	errPeerConnectionInitFailed = errors.New("pc init failed")
+
+	// ErrElementNotFound is returned for element ids that aren't registered
+	ErrElementNotFound = errors.New("element not found")
+	// ErrElementDisabled is returned for elements disabled in low memory
+	// mode
+	ErrElementDisabled = errors.New("element disabled in low memory mode")
+	// ErrTrackNotFound is returned for tracks that haven't arrived
+	ErrTrackNotFound = errors.New("track not found")
 )
@@ -0,0 +1,343 @@
+// Package export runs stitching of finished recordings as background jobs.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lucsky/cuid"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/retry"
+	"github.com/pion/ion-avp/pkg/stitch"
+	"github.com/pion/ion-avp/pkg/timeline"
+	log "github.com/pion/ion-log"
+)
+
+var (
+	// ErrNotFound is returned for unknown job ids
+	ErrNotFound = errors.New("export job not found")
+	// ErrQueueFull is returned when too many jobs are waiting
+	ErrQueueFull = errors.New("export queue full")
+	// ErrStopped is returned when submitting to a stopped manager
+	ErrStopped = errors.New("export manager stopped")
+)
+
+// retention is how long finished jobs can be polled
+const retention = 24 * time.Hour
+
+// State of a job
+type State int
+
+// Job states
+const (
+	Queued State = iota
+	Running
+	Done
+	Failed
+	Canceled
+)
+
+func (s State) String() string {
+	switch s {
+	case Queued:
+		return "queued"
+	case Running:
+		return "running"
+	case Done:
+		return "done"
+	case Failed:
+		return "failed"
+	case Canceled:
+		return "canceled"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// MarshalText encodes the state by name
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s State) finished() bool {
+	return s == Done || s == Failed || s == Canceled
+}
+
+// Config configures the export worker pool
+type Config struct {
+	// Workers is the number of jobs run at once, defaults to 1
+	Workers int `mapstructure:"workers"`
+	// Queue is the number of jobs that may wait for a worker, defaults to 100
+	Queue int `mapstructure:"queue"`
+}
+
+// Format writes recordings to files of another kind than the built in
+// ones, such as contact sheet images
+type Format struct {
+	// Supported reports whether the format writes a file of name
+	Supported func(name string) bool
+	// Export writes the recording described by m to w, calling progress,
+	// if not nil, with the fraction done
+	Export func(ctx context.Context, w io.Writer, name string, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error
+}
+
+// Request describes an export
+type Request struct {
+	// Manifest is the path of the recording manifest
+	Manifest string `json:"manifest"`
+	// Output is the path of the file to write, its extension selects the format
+	Output string `json:"output"`
+	// Webhook, if set, receives a POST with the job as JSON when it finishes
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// Job is the status of an export
+type Job struct {
+	Request
+	ID       string    `json:"id"`
+	State    State     `json:"state"`
+	Progress float64   `json:"progress"`
+	Error    string    `json:"error,omitempty"`
+	Created  time.Time `json:"created"`
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+type job struct {
+	Job
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Manager queues export jobs and runs them on a bounded pool of workers,
+// so exports don't compete with live processing for more than that.
+type Manager struct {
+	mu      sync.Mutex
+	jobs    map[string]*job
+	queue   chan *job
+	client  *http.Client
+	formats []Format
+	retry   *retry.Retrier
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// NewManager creates a manager and starts its workers. Outputs supported
+// by formats are written by the first of them, before editor projects and
+// stitching.
+func NewManager(c Config, formats ...Format) *Manager {
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+	if c.Queue <= 0 {
+		c.Queue = 100
+	}
+	m := &Manager{
+		jobs:    make(map[string]*job),
+		queue:   make(chan *job, c.Queue),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		formats: formats,
+		retry:   retry.New("export-webhook", retry.Policy{}),
+	}
+	for i := 0; i < c.Workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+	return m
+}
+
+// Submit queues an export
+func (m *Manager) Submit(r Request) (Job, error) {
+	if r.Manifest == "" || r.Output == "" {
+		return Job{}, errors.New("export: manifest and output are required")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		Job: Job{
+			Request: r,
+			ID:      cuid.New(),
+			Created: time.Now(),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		cancel()
+		return Job{}, ErrStopped
+	}
+	m.prune()
+	select {
+	case m.queue <- j:
+	default:
+		cancel()
+		return Job{}, ErrQueueFull
+	}
+	m.jobs[j.ID] = j
+	return j.Job, nil
+}
+
+// Get returns the status of a job
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return j.Job, nil
+}
+
+// Cancel stops a queued or running job. Canceling a finished job has no effect.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	}
+	j.cancel()
+	queued := j.State == Queued
+	if queued {
+		j.State = Canceled
+		j.Finished = time.Now()
+	}
+	snapshot := j.Job
+	m.mu.Unlock()
+
+	if queued {
+		m.notify(snapshot)
+	}
+	return snapshot, nil
+}
+
+// Stop cancels all jobs and waits for the workers to exit
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return
+	}
+	m.stopped = true
+	for _, j := range m.jobs {
+		j.cancel()
+	}
+	close(m.queue)
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// prune forgets jobs that finished more than retention ago
+func (m *Manager) prune() {
+	for id, j := range m.jobs {
+		if j.State.finished() && time.Since(j.Finished) > retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func (m *Manager) work() {
+	defer m.wg.Done()
+	for j := range m.queue {
+		m.mu.Lock()
+		if j.State != Queued {
+			m.mu.Unlock()
+			continue
+		}
+		j.State = Running
+		m.mu.Unlock()
+
+		err := m.run(j)
+
+		m.mu.Lock()
+		switch {
+		case err == nil:
+			j.State = Done
+			j.Progress = 1
+		case errors.Is(err, context.Canceled):
+			j.State = Canceled
+		default:
+			j.State = Failed
+			j.Error = err.Error()
+		}
+		j.Finished = time.Now()
+		snapshot := j.Job
+		m.mu.Unlock()
+
+		log.Infof("export %s %s: %s", j.ID, snapshot.State, j.Output)
+		m.notify(snapshot)
+	}
+}
+
+// run stitches, or writes an editor project or other format, to a
+// temporary file renamed on success, so consumers never see partial
+// exports
+func (m *Manager) run(j *job) error {
+	f, err := os.Open(j.Manifest)
+	if err != nil {
+		return err
+	}
+	man, err := manifest.Read(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	tmp := j.Output + ".part"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	open := manifest.Dir(filepath.Dir(j.Manifest))
+	progress := func(p float64) {
+		m.mu.Lock()
+		j.Progress = p
+		m.mu.Unlock()
+	}
+	switch f := m.format(j.Output); {
+	case f != nil:
+		err = f.Export(j.ctx, out, j.Output, man, open, progress)
+	case timeline.Supported(j.Output):
+		err = timeline.Export(j.ctx, out, j.Output, man, open)
+	default:
+		err = stitch.Stitch(j.ctx, out, j.Output, man, open, progress)
+	}
+	out.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, j.Output)
+}
+
+// format returns the format writing name, if any
+func (m *Manager) format(name string) *Format {
+	for i := range m.formats {
+		if m.formats[i].Supported(name) {
+			return &m.formats[i]
+		}
+	}
+	return nil
+}
+
+func (m *Manager) notify(j Job) {
+	if j.Webhook == "" {
+		return
+	}
+	body, err := json.Marshal(j)
+	if err != nil {
+		log.Errorf("export %s webhook: %v", j.ID, err)
+		return
+	}
+	if err := m.retry.PostJSON(context.Background(), m.client, j.Webhook, body); err != nil {
+		log.Errorf("export %s webhook: %v", j.ID, err)
+	}
+}
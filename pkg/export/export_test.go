@@ -0,0 +1,146 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSegment(t *testing.T, name string) manifest.File {
+	f, err := os.Create(name)
+	assert.NoError(t, err)
+	ws, err := webm.NewSimpleBlockWriter(f, []webm.TrackEntry{{
+		Name:        "Audio",
+		TrackNumber: 1,
+		TrackUID:    1,
+		CodecID:     "A_OPUS",
+		TrackType:   2,
+		Audio:       &webm.Audio{SamplingFrequency: 48000, Channels: 2},
+	}})
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := ws[0].Write(true, int64(i*20), []byte{byte(i)})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, ws[0].Close())
+
+	f, err = os.Open(name)
+	assert.NoError(t, err)
+	defer f.Close()
+	file, err := manifest.NewFile(filepath.Base(name), f)
+	assert.NoError(t, err)
+	return file
+}
+
+func TestManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m := manifest.New("rec", "sid", time.Now())
+	for i, name := range []string{"0.webm", "1.webm"} {
+		m.Segments = append(m.Segments, manifest.Segment{
+			File:  writeSegment(t, filepath.Join(dir, name)),
+			Track: "audio",
+			Seq:   i,
+		})
+	}
+	f, err := os.Create(filepath.Join(dir, manifest.FileName))
+	assert.NoError(t, err)
+	assert.NoError(t, m.Write(f))
+	f.Close()
+
+	hooks := make(chan Job, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var j struct {
+			ID    string `json:"id"`
+			State string `json:"state"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&j))
+		job := Job{ID: j.ID}
+		if j.State == "done" {
+			job.State = Done
+		} else {
+			job.State = Failed
+		}
+		hooks <- job
+	}))
+	defer srv.Close()
+
+	mgr := NewManager(Config{}, Format{
+		Supported: func(name string) bool { return filepath.Ext(name) == ".txt" },
+		Export: func(ctx context.Context, w io.Writer, name string, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+			_, err := fmt.Fprintf(w, "%s: %d segments", m.ID, len(m.Segments))
+			return err
+		},
+	})
+	defer mgr.Stop()
+
+	out := filepath.Join(dir, "out.webm")
+	j, err := mgr.Submit(Request{
+		Manifest: filepath.Join(dir, manifest.FileName),
+		Output:   out,
+		Webhook:  srv.URL,
+	})
+	assert.NoError(t, err)
+
+	select {
+	case hook := <-hooks:
+		assert.Equal(t, j.ID, hook.ID)
+		assert.Equal(t, Done, hook.State)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook")
+	}
+	j, err = mgr.Get(j.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, Done, j.State)
+	assert.Equal(t, float64(1), j.Progress)
+	_, err = os.Stat(out)
+	assert.NoError(t, err)
+
+	// Canceling a finished job has no effect
+	j, err = mgr.Cancel(j.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, Done, j.State)
+
+	j, err = mgr.Submit(Request{
+		Manifest: filepath.Join(dir, "missing.json"),
+		Output:   out,
+		Webhook:  srv.URL,
+	})
+	assert.NoError(t, err)
+	hook := <-hooks
+	assert.Equal(t, Failed, hook.State)
+	j, err = mgr.Get(j.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, Failed, j.State)
+	assert.True(t, j.Error != "")
+
+	// Other formats
+	_, err = mgr.Submit(Request{
+		Manifest: filepath.Join(dir, manifest.FileName),
+		Output:   filepath.Join(dir, "out.txt"),
+		Webhook:  srv.URL,
+	})
+	assert.NoError(t, err)
+	hook = <-hooks
+	assert.Equal(t, Done, hook.State)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "out.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "rec: 2 segments", string(b))
+
+	_, err = mgr.Get("unknown")
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,212 @@
+package hls
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// ErrRange is returned for clips outside the stream
+var ErrRange = errors.New("hls: clip out of range")
+
+// OpenFunc opens a file of a stream by its uri in the playlist
+type OpenFunc func(uri string) (io.ReadCloser, error)
+
+// Clip writes the part of the stream of m from start to end to w as one
+// fragmented MP4 file, the initialization segment followed by the
+// segments holding the range. Segments are copied without re-encoding,
+// decrypted if their key is set, so the clip runs from the keyframe
+// starting the first segment, at or before start, to the end of the last.
+// The clip's timestamps start at zero; the time of start in it is
+// returned, for players to seek to or an editor to trim.
+func Clip(w io.Writer, m *Media, start, end time.Duration, open OpenFunc) (time.Duration, error) {
+	if end <= start || start < 0 {
+		return 0, fmt.Errorf("%w: %s to %s", ErrRange, start, end)
+	}
+	first, last := -1, -1
+	var at, from time.Duration
+	for i, s := range m.Segments {
+		if at+s.Duration > start && at < end {
+			if first < 0 {
+				first, from = i, at
+			}
+			last = i
+		}
+		at += s.Duration
+	}
+	if first < 0 {
+		return 0, fmt.Errorf("%w: %s to %s of %s", ErrRange, start, end, at)
+	}
+
+	init, err := readFile(open, m.Init)
+	if err != nil {
+		return 0, err
+	}
+	scales := timescales(init)
+	if _, err := w.Write(init); err != nil {
+		return 0, err
+	}
+	var base map[uint32]uint64
+	for i := first; i <= last; i++ {
+		s := m.Segments[i]
+		data, err := readFile(open, s.URI)
+		if err != nil {
+			return 0, err
+		}
+		if s.Key != nil {
+			if s.Key.Key == nil {
+				return 0, fmt.Errorf("hls: segment %s is encrypted, its key unknown", s.URI)
+			}
+			if data, err = Decrypt(s.Key.Key, m.Sequence+i, data); err != nil {
+				return 0, err
+			}
+		}
+		if base == nil {
+			base = baseTimes(data, scales)
+		}
+		rebase(data, base)
+		if _, err := w.Write(data); err != nil {
+			return 0, err
+		}
+	}
+	return start - from, nil
+}
+
+func readFile(open OpenFunc, uri string) ([]byte, error) {
+	r, err := open(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// boxes calls f with the type and payload of each box in b
+func boxes(b []byte, f func(typ string, payload []byte)) {
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b))
+		if size < 8 || size > len(b) {
+			return
+		}
+		f(string(b[4:8]), b[8:size])
+		b = b[size:]
+	}
+}
+
+// timescales returns the timescale of each track of an initialization
+// segment by track ID
+func timescales(init []byte) map[uint32]uint32 {
+	scales := map[uint32]uint32{}
+	boxes(init, func(typ string, moov []byte) {
+		if typ != "moov" {
+			return
+		}
+		boxes(moov, func(typ string, trak []byte) {
+			if typ != "trak" {
+				return
+			}
+			var id, scale uint32
+			boxes(trak, func(typ string, b []byte) {
+				switch typ {
+				case "tkhd":
+					// Times are 64 bit in version 1
+					if len(b) > 0 && len(b) >= 16+8*int(b[0]) {
+						id = binary.BigEndian.Uint32(b[12+8*int(b[0]):])
+					}
+				case "mdia":
+					boxes(b, func(typ string, mdhd []byte) {
+						if typ == "mdhd" && len(mdhd) > 0 && len(mdhd) >= 16+8*int(mdhd[0]) {
+							scale = binary.BigEndian.Uint32(mdhd[12+8*int(mdhd[0]):])
+						}
+					})
+				}
+			})
+			if scale > 0 {
+				scales[id] = scale
+			}
+		})
+	})
+	return scales
+}
+
+// trackFragments calls f with the track ID and tfdt box payload of each
+// track fragment of a segment
+func trackFragments(segment []byte, f func(id uint32, tfdt []byte)) {
+	boxes(segment, func(typ string, moof []byte) {
+		if typ != "moof" {
+			return
+		}
+		boxes(moof, func(typ string, traf []byte) {
+			if typ != "traf" {
+				return
+			}
+			var id uint32
+			boxes(traf, func(typ string, b []byte) {
+				switch {
+				case typ == "tfhd" && len(b) >= 8:
+					id = binary.BigEndian.Uint32(b[4:])
+				case typ == "tfdt" && len(b) >= 8:
+					f(id, b)
+				}
+			})
+		})
+	})
+}
+
+func decodeTime(tfdt []byte) uint64 {
+	if tfdt[0] == 1 && len(tfdt) >= 12 {
+		return binary.BigEndian.Uint64(tfdt[4:])
+	}
+	return uint64(binary.BigEndian.Uint32(tfdt[4:]))
+}
+
+// baseTimes returns the decode time of each track to subtract for the
+// clip to start at zero, the earliest start of the tracks of its first
+// segment so they stay in sync
+func baseTimes(segment []byte, scales map[uint32]uint32) map[uint32]uint64 {
+	var earliest time.Duration = -1
+	trackFragments(segment, func(id uint32, tfdt []byte) {
+		scale := scales[id]
+		if scale == 0 {
+			return
+		}
+		t := time.Duration(scaleTime(decodeTime(tfdt), uint64(time.Second), uint64(scale)))
+		if earliest < 0 || t < earliest {
+			earliest = t
+		}
+	})
+	base := map[uint32]uint64{}
+	if earliest < 0 {
+		return base
+	}
+	for id, scale := range scales {
+		base[id] = scaleTime(uint64(earliest), uint64(scale), uint64(time.Second))
+	}
+	return base
+}
+
+// scaleTime returns t*to/from, without overflowing for long streams
+func scaleTime(t, to, from uint64) uint64 {
+	return t/from*to + t%from*to/from
+}
+
+// rebase subtracts the base time of each track from the decode times of
+// a segment, in place
+func rebase(segment []byte, base map[uint32]uint64) {
+	trackFragments(segment, func(id uint32, tfdt []byte) {
+		t := decodeTime(tfdt)
+		if b := base[id]; t >= b {
+			t -= b
+		} else {
+			t = 0
+		}
+		if tfdt[0] == 1 && len(tfdt) >= 12 {
+			binary.BigEndian.PutUint64(tfdt[4:], t)
+		} else {
+			binary.BigEndian.PutUint32(tfdt[4:], uint32(t))
+		}
+	})
+}
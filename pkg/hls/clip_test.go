@@ -0,0 +1,89 @@
+package hls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func box(typ string, payload ...[]byte) []byte {
+	b := append(make([]byte, 4), typ...)
+	for _, p := range payload {
+		b = append(b, p...)
+	}
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+	return b
+}
+
+func be32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// track is the trak box of a track with an ID and timescale
+func track(id, scale uint32) []byte {
+	tkhd := append(make([]byte, 12), be32(id)...)
+	mdhd := append(make([]byte, 12), be32(scale)...)
+	return box("trak", box("tkhd", tkhd, make([]byte, 4)), box("mdia", box("mdhd", mdhd, make([]byte, 4))))
+}
+
+// fragment is a movie fragment of a track starting at a decode time
+func fragment(id uint32, at uint64) []byte {
+	tfdt := make([]byte, 12)
+	tfdt[0] = 1
+	binary.BigEndian.PutUint64(tfdt[4:], at)
+	return append(box("moof", box("traf", box("tfhd", make([]byte, 4), be32(id)), box("tfdt", tfdt))), box("mdat", []byte("media"))...)
+}
+
+func TestClip(t *testing.T) {
+	key := &Key{URI: "key-0.key", Key: []byte("0123456789abcdef")}
+	files := map[string][]byte{"init.mp4": box("moov", track(1, 90000), track(2, 48000))}
+	m := &Media{Init: "init.mp4", Sequence: 10}
+	// Segments of 2s, the third encrypted
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("segment-%d.m4s", i)
+		data := append(fragment(1, uint64(i)*180000), fragment(2, uint64(i)*96000+960)...)
+		s := Segment{URI: name, Duration: 2 * time.Second}
+		if i == 2 {
+			var err error
+			data, err = Encrypt(key.Key, m.Sequence+i, data)
+			assert.NoError(t, err)
+			s.Key = key
+		}
+		files[name] = data
+		m.Segments = append(m.Segments, s)
+	}
+	var opened []string
+	open := func(uri string) (io.ReadCloser, error) {
+		opened = append(opened, uri)
+		return ioutil.NopCloser(bytes.NewReader(files[uri])), nil
+	}
+
+	var b bytes.Buffer
+	at, err := Clip(&b, m, 3*time.Second, 5*time.Second, open)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, at)
+	assert.Equal(t, []string{"init.mp4", "segment-1.m4s", "segment-2.m4s"}, opened)
+
+	// Tracks start at zero, keeping their offset
+	var times []uint64
+	clip := b.Bytes()[len(files["init.mp4"]):]
+	trackFragments(clip, func(id uint32, tfdt []byte) {
+		times = append(times, decodeTime(tfdt))
+	})
+	assert.Equal(t, []uint64{0, 960, 180000, 96960}, times)
+
+	_, err = Clip(&b, m, 8*time.Second, 9*time.Second, open)
+	assert.True(t, errors.Is(err, ErrRange))
+	key.Key = nil
+	_, err = Clip(&b, m, 4*time.Second, 5*time.Second, open)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package hls
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+)
+
+// KeySize is the size of AES-128 keys
+const KeySize = 16
+
+// Key encrypts segments with AES-128. Segments are encrypted whole with
+// AES-CBC, their IV the media sequence number of the segment.
+type Key struct {
+	URI string // players fetch the key from
+	Key []byte
+}
+
+// Encrypt returns segment number seq encrypted with key
+func Encrypt(key []byte, seq int, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("hls: %w", err)
+	}
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], uint64(seq))
+	// PKCS7 padding
+	pad := aes.BlockSize - len(data)%aes.BlockSize
+	out := append(append([]byte(nil), data...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, out)
+	return out, nil
+}
+
+// Decrypt returns segment number seq decrypted with key
+func Decrypt(key []byte, seq int, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("hls: %w", err)
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("hls: segment %d isn't whole blocks", seq)
+	}
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], uint64(seq))
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > aes.BlockSize {
+		return nil, fmt.Errorf("hls: segment %d has bad padding, wrong key?", seq)
+	}
+	return out[:len(out)-pad], nil
+}
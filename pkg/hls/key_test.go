@@ -0,0 +1,36 @@
+package hls
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypt(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	data := []byte("a segment of 26 bytes long")
+	out, err := Encrypt(key, 258, data)
+	assert.NoError(t, err)
+	assert.Len(t, out, 32)
+
+	// The IV is the media sequence number
+	block, _ := aes.NewCipher(key)
+	iv := make([]byte, aes.BlockSize)
+	iv[14], iv[15] = 1, 2
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, out)
+	assert.Equal(t, data, out[:26])
+	assert.Equal(t, []byte{6, 6, 6, 6, 6, 6}, out[26:])
+
+	out, err = Encrypt(key, 258, data)
+	assert.NoError(t, err)
+	dec, err := Decrypt(key, 258, out)
+	assert.NoError(t, err)
+	assert.Equal(t, data, dec)
+	_, err = Decrypt(key, 258, out[:20])
+	assert.Error(t, err)
+
+	_, err = Encrypt(key[:5], 0, data)
+	assert.Error(t, err)
+}
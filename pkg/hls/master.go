@@ -0,0 +1,122 @@
+// Package hls writes and reads HTTP Live Streaming playlists (RFC 8216).
+//
+// A master playlist lists the variants of a stream, one per rendition of
+// an adaptive bitrate ladder, for players to switch between as their
+// bandwidth allows:
+//
+//	#EXTM3U
+//	#EXT-X-VERSION:6
+//	#EXT-X-INDEPENDENT-SEGMENTS
+//	#EXT-X-STREAM-INF:BANDWIDTH=2628000,RESOLUTION=1280x720,CODECS="avc1.64001f,opus"
+//	720p/index.m3u8
+//	#EXT-X-STREAM-INF:BANDWIDTH=928000,RESOLUTION=640x360,CODECS="avc1.64001f,opus"
+//	360p/index.m3u8
+package hls
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrPlaylist is returned for playlists that can't be written or read
+var ErrPlaylist = errors.New("hls: invalid playlist")
+
+// version is the protocol version of the playlists written
+const version = 6
+
+// Variant is a rendition of a stream listed in a master playlist
+type Variant struct {
+	URI string // of the variant's media playlist
+	// Bandwidth is the peak bits per second of the variant, audio
+	// included. AverageBandwidth is optional.
+	Bandwidth        int
+	AverageBandwidth int
+	// Width and Height of the video, omitted if either is zero
+	Width     int
+	Height    int
+	FrameRate float64
+	// Codecs are RFC 6381 codec strings, e.g. "avc1.64001f" or "opus"
+	Codecs []string
+	// Audio is the group of audio renditions played with the variant
+	Audio string
+}
+
+// Rendition is an alternative rendition of a variant, e.g. its audio
+// packaged apart from the video, or one of several audio languages
+type Rendition struct {
+	// Type is AUDIO, VIDEO or SUBTITLES
+	Type    string
+	GroupID string
+	Name    string
+	URI     string // of the rendition's media playlist
+	// Language is an RFC 5646 tag, e.g. "en"
+	Language string
+	// Default is played unless the viewer picks another rendition of its
+	// group. Autoselect renditions may be picked by players for the
+	// viewer's language, default ones always are.
+	Default    bool
+	Autoselect bool
+}
+
+// Master is a master playlist
+type Master struct {
+	Renditions []Rendition
+	Variants   []Variant
+}
+
+// WriteTo writes the playlist to w
+func (m *Master) WriteTo(w io.Writer) (int64, error) {
+	if len(m.Variants) == 0 {
+		return 0, fmt.Errorf("%w: no variants", ErrPlaylist)
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "#EXTM3U\n#EXT-X-VERSION:%d\n#EXT-X-INDEPENDENT-SEGMENTS\n", version)
+	for _, r := range m.Renditions {
+		if r.Type == "" || r.GroupID == "" || r.Name == "" {
+			return 0, fmt.Errorf("%w: renditions need a type, group and name", ErrPlaylist)
+		}
+		def, auto := "NO", "NO"
+		if r.Default {
+			def = "YES"
+		}
+		if r.Default || r.Autoselect {
+			auto = "YES"
+		}
+		fmt.Fprintf(&b, "#EXT-X-MEDIA:TYPE=%s,GROUP-ID=%q,NAME=%q", r.Type, r.GroupID, r.Name)
+		if r.Language != "" {
+			fmt.Fprintf(&b, ",LANGUAGE=%q", r.Language)
+		}
+		fmt.Fprintf(&b, ",DEFAULT=%s,AUTOSELECT=%s", def, auto)
+		if r.URI != "" {
+			fmt.Fprintf(&b, ",URI=%q", r.URI)
+		}
+		b.WriteString("\n")
+	}
+	for _, v := range m.Variants {
+		if v.URI == "" || v.Bandwidth <= 0 {
+			return 0, fmt.Errorf("%w: variants need a uri and bandwidth", ErrPlaylist)
+		}
+		attrs := []string{"BANDWIDTH=" + strconv.Itoa(v.Bandwidth)}
+		if v.AverageBandwidth > 0 {
+			attrs = append(attrs, "AVERAGE-BANDWIDTH="+strconv.Itoa(v.AverageBandwidth))
+		}
+		if v.Width > 0 && v.Height > 0 {
+			attrs = append(attrs, fmt.Sprintf("RESOLUTION=%dx%d", v.Width, v.Height))
+		}
+		if v.FrameRate > 0 {
+			attrs = append(attrs, "FRAME-RATE="+strconv.FormatFloat(v.FrameRate, 'f', 3, 64))
+		}
+		if len(v.Codecs) > 0 {
+			attrs = append(attrs, `CODECS="`+strings.Join(v.Codecs, ",")+`"`)
+		}
+		if v.Audio != "" {
+			attrs = append(attrs, `AUDIO="`+v.Audio+`"`)
+		}
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:%s\n%s\n", strings.Join(attrs, ","), v.URI)
+	}
+	return b.WriteTo(w)
+}
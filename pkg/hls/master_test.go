@@ -0,0 +1,67 @@
+package hls
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaster(t *testing.T) {
+	m := &Master{Variants: []Variant{
+		{URI: "720p/index.m3u8", Bandwidth: 2628000, Width: 1280, Height: 720, FrameRate: 30, Codecs: []string{"avc1.64001f", "opus"}},
+		{URI: "audio/index.m3u8", Bandwidth: 128000, AverageBandwidth: 96000, Codecs: []string{"opus"}},
+	}}
+	var b bytes.Buffer
+	n, err := m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(b.Len()), n)
+	assert.Equal(t, `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-STREAM-INF:BANDWIDTH=2628000,RESOLUTION=1280x720,FRAME-RATE=30.000,CODECS="avc1.64001f,opus"
+720p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=128000,AVERAGE-BANDWIDTH=96000,CODECS="opus"
+audio/index.m3u8
+`, b.String())
+
+	m = &Master{
+		Renditions: []Rendition{{Type: "AUDIO", GroupID: "audio", Name: "audio", URI: "audio.m3u8", Default: true}},
+		Variants:   []Variant{{URI: "video.m3u8", Bandwidth: 1000000, Codecs: []string{"avc1.42001e", "opus"}, Audio: "audio"}},
+	}
+	b.Reset()
+	_, err = m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="audio",DEFAULT=YES,AUTOSELECT=YES,URI="audio.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=1000000,CODECS="avc1.42001e,opus",AUDIO="audio"
+video.m3u8
+`, b.String())
+
+	// Audio languages to pick from
+	m.Renditions = []Rendition{
+		{Type: "AUDIO", GroupID: "audio", Name: "Floor", URI: "floor.m3u8", Language: "en", Default: true},
+		{Type: "AUDIO", GroupID: "audio", Name: "Français", URI: "fr.m3u8", Language: "fr", Autoselect: true},
+		{Type: "AUDIO", GroupID: "audio", Name: "Commentary", URI: "commentary.m3u8", Language: "en"},
+	}
+	b.Reset()
+	_, err = m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), `#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="Floor",LANGUAGE="en",DEFAULT=YES,AUTOSELECT=YES,URI="floor.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="Français",LANGUAGE="fr",DEFAULT=NO,AUTOSELECT=YES,URI="fr.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="Commentary",LANGUAGE="en",DEFAULT=NO,AUTOSELECT=NO,URI="commentary.m3u8"
+`)
+
+	for _, m := range []*Master{
+		{},
+		{Renditions: []Rendition{{Type: "AUDIO"}}, Variants: m.Variants},
+		{Variants: []Variant{{URI: "a.m3u8"}}},
+		{Variants: []Variant{{Bandwidth: 1}}},
+	} {
+		_, err := m.WriteTo(&b)
+		assert.True(t, errors.Is(err, ErrPlaylist))
+	}
+}
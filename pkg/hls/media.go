@@ -0,0 +1,90 @@
+package hls
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Segment is a media segment listed in a media playlist
+type Segment struct {
+	URI      string
+	Duration time.Duration
+	// Key the segment is encrypted with, if it is
+	Key *Key
+	// Discontinuity marks a segment not following on from the last, e.g.
+	// the first of a stream resumed after a restart
+	Discontinuity bool
+}
+
+// Media is a media playlist of fMP4 segments. A live playlist lists a
+// window of the latest segments, Sequence being the number of the first;
+// one listing every segment from the start is an event playlist, which
+// viewers can seek back in. Ended playlists get no more segments, and VOD
+// playlists, of finished streams, never change.
+type Media struct {
+	// Init is the uri of the initialization segment
+	Init     string
+	Sequence int
+	// DiscontinuitySequence counts the discontinuities of segments that
+	// have left the window
+	DiscontinuitySequence int
+	Segments              []Segment
+	// Event marks a playlist keeping all its segments
+	Event bool
+	Ended bool
+	VOD   bool
+	// TargetDuration is the longest segment duration, rounded, that the
+	// playlist may ever list
+	TargetDuration time.Duration
+}
+
+// WriteTo writes the playlist to w
+func (m *Media) WriteTo(w io.Writer) (int64, error) {
+	if m.Init == "" {
+		return 0, fmt.Errorf("%w: no initialization segment", ErrPlaylist)
+	}
+	target := m.TargetDuration
+	for _, s := range m.Segments {
+		if s.Duration > target {
+			target = s.Duration
+		}
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "#EXTM3U\n#EXT-X-VERSION:%d\n#EXT-X-TARGETDURATION:%d\n", version, int(math.Round(target.Seconds())))
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", m.Sequence)
+	if m.DiscontinuitySequence > 0 {
+		fmt.Fprintf(&b, "#EXT-X-DISCONTINUITY-SEQUENCE:%d\n", m.DiscontinuitySequence)
+	}
+	switch {
+	case m.VOD:
+		b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	case m.Event:
+		b.WriteString("#EXT-X-PLAYLIST-TYPE:EVENT\n")
+	}
+	b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	fmt.Fprintf(&b, "#EXT-X-MAP:URI=%q\n", m.Init)
+	var key *Key
+	for _, s := range m.Segments {
+		// Keys apply to the segments following them
+		if s.Key != key {
+			if s.Key == nil {
+				b.WriteString("#EXT-X-KEY:METHOD=NONE\n")
+			} else {
+				fmt.Fprintf(&b, "#EXT-X-KEY:METHOD=AES-128,URI=%q\n", s.Key.URI)
+			}
+			key = s.Key
+		}
+		if s.Discontinuity {
+			b.WriteString("#EXT-X-DISCONTINUITY\n")
+		}
+		fmt.Fprintf(&b, "#EXTINF:%s,\n%s\n", strconv.FormatFloat(s.Duration.Seconds(), 'f', 3, 64), s.URI)
+	}
+	if m.Ended {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+	return b.WriteTo(w)
+}
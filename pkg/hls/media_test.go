@@ -0,0 +1,97 @@
+package hls
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedia(t *testing.T) {
+	m := &Media{
+		Init:           "init.mp4",
+		Sequence:       3,
+		TargetDuration: 6 * time.Second,
+		Segments: []Segment{
+			{URI: "segment-3.m4s", Duration: 6006 * time.Millisecond},
+			{URI: "segment-4.m4s", Duration: 6800 * time.Millisecond},
+		},
+	}
+	var b bytes.Buffer
+	_, err := m.WriteTo(&b)
+	assert.NoError(t, err)
+	// The target covers the longest segment
+	assert.Equal(t, `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-TARGETDURATION:7
+#EXT-X-MEDIA-SEQUENCE:3
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:6.006,
+segment-3.m4s
+#EXTINF:6.800,
+segment-4.m4s
+`, b.String())
+
+	m = &Media{Init: "init.mp4", Event: true, Ended: true, TargetDuration: 2 * time.Second}
+	b.Reset()
+	_, err = m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "#EXT-X-PLAYLIST-TYPE:EVENT\n")
+	assert.Contains(t, b.String(), "#EXT-X-ENDLIST\n")
+
+	m.VOD = true
+	b.Reset()
+	_, err = m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "#EXT-X-PLAYLIST-TYPE:VOD\n")
+	assert.NotContains(t, b.String(), "EVENT")
+
+	_, err = (&Media{}).WriteTo(&b)
+	assert.True(t, errors.Is(err, ErrPlaylist))
+}
+
+func TestMedia_Keys(t *testing.T) {
+	k0, k1 := &Key{URI: "https://keys/0"}, &Key{URI: "https://keys/1"}
+	m := &Media{Init: "init.mp4", Sequence: 4, TargetDuration: time.Second, Segments: []Segment{
+		{URI: "segment-4.m4s", Duration: time.Second, Key: k0},
+		{URI: "segment-5.m4s", Duration: time.Second, Key: k1},
+		{URI: "segment-6.m4s", Duration: time.Second, Key: k1},
+		{URI: "segment-7.m4s", Duration: time.Second},
+	}}
+	var b bytes.Buffer
+	_, err := m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), `#EXT-X-MAP:URI="init.mp4"
+#EXT-X-KEY:METHOD=AES-128,URI="https://keys/0"
+#EXTINF:1.000,
+segment-4.m4s
+#EXT-X-KEY:METHOD=AES-128,URI="https://keys/1"
+#EXTINF:1.000,
+segment-5.m4s
+#EXTINF:1.000,
+segment-6.m4s
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:1.000,
+segment-7.m4s
+`)
+}
+
+func TestMedia_Discontinuity(t *testing.T) {
+	m := &Media{Init: "init.mp4", Sequence: 9, DiscontinuitySequence: 1, TargetDuration: time.Second, Segments: []Segment{
+		{URI: "segment-9.m4s", Duration: time.Second},
+		{URI: "segment-10.m4s", Duration: time.Second, Discontinuity: true},
+	}}
+	var b bytes.Buffer
+	_, err := m.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "#EXT-X-MEDIA-SEQUENCE:9\n#EXT-X-DISCONTINUITY-SEQUENCE:1\n")
+	assert.Contains(t, b.String(), "segment-9.m4s\n#EXT-X-DISCONTINUITY\n#EXTINF:1.000,\nsegment-10.m4s\n")
+
+	parsed, err := ParseMedia(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, parsed.DiscontinuitySequence)
+	assert.Equal(t, m.Segments, parsed.Segments)
+}
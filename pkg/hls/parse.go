@@ -0,0 +1,131 @@
+package hls
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseMedia reads a media playlist, such as one written by Media.WriteTo.
+// Keys of encrypted segments are listed by uri only.
+func ParseMedia(r io.Reader) (*Media, error) {
+	m := &Media{}
+	scanner := bufio.NewScanner(r)
+	var (
+		header   bool
+		duration time.Duration
+		inf      bool
+		key      *Key
+		disc     bool
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !header {
+			if line != "#EXTM3U" {
+				return nil, fmt.Errorf("%w: no #EXTM3U header", ErrPlaylist)
+			}
+			header = true
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			if !inf {
+				return nil, fmt.Errorf("%w: segment %s has no duration", ErrPlaylist, line)
+			}
+			m.Segments = append(m.Segments, Segment{URI: line, Duration: duration, Key: key, Discontinuity: disc})
+			inf, disc = false, false
+			continue
+		}
+		tag, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			tag, value = line[:i], line[i+1:]
+		}
+		var err error
+		switch tag {
+		case "#EXT-X-TARGETDURATION":
+			var n int
+			n, err = strconv.Atoi(value)
+			m.TargetDuration = time.Duration(n) * time.Second
+		case "#EXT-X-MEDIA-SEQUENCE":
+			m.Sequence, err = strconv.Atoi(value)
+		case "#EXT-X-DISCONTINUITY-SEQUENCE":
+			m.DiscontinuitySequence, err = strconv.Atoi(value)
+		case "#EXT-X-DISCONTINUITY":
+			disc = true
+		case "#EXT-X-PLAYLIST-TYPE":
+			m.Event = value == "EVENT"
+			m.VOD = value == "VOD"
+		case "#EXT-X-ENDLIST":
+			m.Ended = true
+		case "#EXT-X-MAP":
+			m.Init = attributes(value)["URI"]
+		case "#EXT-X-KEY":
+			attrs := attributes(value)
+			switch attrs["METHOD"] {
+			case "NONE":
+				key = nil
+			case "AES-128":
+				key = &Key{URI: attrs["URI"]}
+			default:
+				return nil, fmt.Errorf("%w: unsupported key method %s", ErrPlaylist, attrs["METHOD"])
+			}
+		case "#EXTINF":
+			var seconds float64
+			if i := strings.IndexByte(value, ','); i >= 0 {
+				value = value[:i]
+			}
+			seconds, err = strconv.ParseFloat(value, 64)
+			duration = time.Duration(math.Round(seconds*1e6)) * time.Microsecond
+			inf = true
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrPlaylist, tag, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("%w: no #EXTM3U header", ErrPlaylist)
+	}
+	return m, nil
+}
+
+// attributes parses an attribute list, NAME=value pairs separated by
+// commas, values optionally quoted
+func attributes(list string) map[string]string {
+	attrs := map[string]string{}
+	for list != "" {
+		i := strings.IndexByte(list, '=')
+		if i < 0 {
+			break
+		}
+		name := strings.TrimSpace(list[:i])
+		list = list[i+1:]
+		var value string
+		if strings.HasPrefix(list, `"`) {
+			end := strings.IndexByte(list[1:], '"')
+			if end < 0 {
+				end = len(list) - 1
+			}
+			value = list[1 : end+1]
+			list = list[end+1:]
+			if list != "" {
+				list = list[1:]
+			}
+		} else if j := strings.IndexByte(list, ','); j >= 0 {
+			value, list = list[:j], list[j:]
+		} else {
+			value, list = list, ""
+		}
+		attrs[name] = value
+		list = strings.TrimPrefix(list, ",")
+	}
+	return attrs
+}
@@ -0,0 +1,51 @@
+package hls
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMedia(t *testing.T) {
+	key := &Key{URI: "https://keys.example.com/0", Key: []byte("0123456789abcdef")}
+	m := &Media{
+		Init:           "init.mp4",
+		Sequence:       3,
+		TargetDuration: 6 * time.Second,
+		Segments: []Segment{
+			{URI: "segment-3.m4s", Duration: 6006 * time.Millisecond},
+			{URI: "segment-4.m4s", Duration: 6800 * time.Millisecond, Key: key},
+			{URI: "segment-5.m4s", Duration: 2 * time.Second, Key: key},
+		},
+		VOD:   true,
+		Ended: true,
+	}
+	var b bytes.Buffer
+	_, err := m.WriteTo(&b)
+	assert.NoError(t, err)
+
+	parsed, err := ParseMedia(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, "init.mp4", parsed.Init)
+	assert.Equal(t, 3, parsed.Sequence)
+	assert.Equal(t, 7*time.Second, parsed.TargetDuration)
+	assert.True(t, parsed.VOD)
+	assert.True(t, parsed.Ended)
+	assert.Len(t, parsed.Segments, 3)
+	assert.Equal(t, 6006*time.Millisecond, parsed.Segments[0].Duration)
+	assert.Nil(t, parsed.Segments[0].Key)
+	// Segments following a key share it, known by uri
+	assert.Equal(t, &Key{URI: "https://keys.example.com/0"}, parsed.Segments[1].Key)
+	assert.Same(t, parsed.Segments[1].Key, parsed.Segments[2].Key)
+
+	_, err = ParseMedia(strings.NewReader("segment-0.m4s\n"))
+	assert.True(t, errors.Is(err, ErrPlaylist))
+	_, err = ParseMedia(strings.NewReader("#EXTM3U\nsegment-0.m4s\n"))
+	assert.True(t, errors.Is(err, ErrPlaylist))
+	_, err = ParseMedia(strings.NewReader("#EXTM3U\n#EXT-X-KEY:METHOD=SAMPLE-AES,URI=\"k\"\n"))
+	assert.True(t, errors.Is(err, ErrPlaylist))
+}
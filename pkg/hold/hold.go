@@ -0,0 +1,177 @@
+// Package hold places recordings under legal hold. A held recording and
+// its sidecars can't be deleted, by retention cleanup or erasure requests,
+// or overwritten, by a recording started with the same name, until the
+// hold is released.
+//
+// Holds are persisted as a "<recording>.hold.json" sidecar next to the
+// recording, so they survive restarts and travel with the recording.
+package hold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/storage"
+	log "github.com/pion/ion-log"
+)
+
+// ErrHeld is returned when deleting or overwriting a recording under legal
+// hold
+var ErrHeld = errors.New("recording is under legal hold")
+
+// suffix is appended to the recording name for its hold sidecar
+const suffix = ".hold.json"
+
+// Hold on a recording
+type Hold struct {
+	Recording string    `json:"recording"`
+	Reason    string    `json:"reason,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+// Name returns the name of the hold sidecar of a recording
+func Name(recording string) string {
+	return recording + suffix
+}
+
+// Holds places and checks holds on recordings, in storage or as local
+// files if store is nil
+type Holds struct {
+	store storage.Storage
+}
+
+// New creates holds on recordings in store, or local files if nil
+func New(store storage.Storage) *Holds {
+	return &Holds{store: store}
+}
+
+// Place puts a recording under legal hold. Placing a hold on a held
+// recording replaces its reason.
+func (h *Holds) Place(recording, reason string) (Hold, error) {
+	hold := Hold{Recording: recording, Reason: reason, Since: time.Now()}
+	var w io.WriteCloser
+	var err error
+	if h.store != nil {
+		w, err = h.store.Open(Name(recording))
+	} else {
+		w, err = os.Create(Name(recording))
+	}
+	if err != nil {
+		return Hold{}, err
+	}
+	if err := json.NewEncoder(w).Encode(hold); err != nil {
+		w.Close()
+		return Hold{}, err
+	}
+	if err := w.Close(); err != nil {
+		return Hold{}, err
+	}
+	if h.store != nil {
+		if err := h.store.Finalize(Name(recording)); err != nil {
+			return Hold{}, err
+		}
+	}
+	log.Infof("recording %s placed under legal hold: %s", recording, reason)
+	return hold, nil
+}
+
+// Release lifts the legal hold of a recording, so it can be deleted again
+func (h *Holds) Release(recording string) error {
+	var err error
+	if h.store != nil {
+		err = h.store.Delete(Name(recording))
+	} else if err = os.Remove(Name(recording)); os.IsNotExist(err) {
+		err = storage.ErrNotFound
+	}
+	if err == storage.ErrNotFound {
+		return fmt.Errorf("recording %s is not under legal hold", recording)
+	}
+	if err == nil {
+		log.Infof("recording %s released from legal hold", recording)
+	}
+	return err
+}
+
+// Held reports whether a recording is under legal hold
+func (h *Holds) Held(recording string) (bool, error) {
+	name := Name(recording)
+	if h.store == nil {
+		_, err := os.Stat(name)
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	names, err := h.store.List(name)
+	if err != nil {
+		return false, err
+	}
+	for _, n := range names {
+		if n == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Check returns ErrHeld if the file name is a recording under legal hold,
+// one of its sidecars, or a hold itself. Errors checking are returned, so
+// deletions fail safe.
+func (h *Holds) Check(name string) error {
+	if strings.HasSuffix(name, suffix) {
+		return fmt.Errorf("%w: %s", ErrHeld, strings.TrimSuffix(name, suffix))
+	}
+	// The recording of a sidecar is a prefix ending before one of its dots
+	for recording := name; recording != ""; {
+		held, err := h.Held(recording)
+		if err != nil {
+			return fmt.Errorf("checking legal hold of %s: %w", recording, err)
+		}
+		if held {
+			return fmt.Errorf("%w: %s", ErrHeld, recording)
+		}
+		i := strings.LastIndex(recording, ".")
+		if i <= 0 || strings.ContainsAny(recording[i:], "/\\") {
+			break
+		}
+		recording = recording[:i]
+	}
+	return nil
+}
+
+// Protect wraps store so objects of recordings under legal hold can't be
+// deleted or overwritten through it
+func Protect(store storage.Storage) storage.Storage {
+	return &protected{Storage: store, holds: New(store)}
+}
+
+type protected struct {
+	storage.Storage
+	holds *Holds
+}
+
+func (p *protected) Open(name string) (io.WriteCloser, error) {
+	if err := p.holds.Check(name); err != nil {
+		return nil, err
+	}
+	return p.Storage.Open(name)
+}
+
+func (p *protected) Abort(name string) error {
+	if err := p.holds.Check(name); err != nil {
+		return err
+	}
+	return p.Storage.Abort(name)
+}
+
+func (p *protected) Delete(name string) error {
+	if err := p.holds.Check(name); err != nil {
+		return err
+	}
+	return p.Storage.Delete(name)
+}
@@ -0,0 +1,69 @@
+package hold
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/ion-avp/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hold")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := storage.NewLocal(storage.LocalConfig{Root: dir})
+	for _, name := range []string{"rooms/a.webm", "rooms/a.webm.quality.json", "rooms/b.webm"} {
+		w, err := store.Open(name)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+	}
+
+	holds := New(store)
+	protected := Protect(store)
+	h, err := holds.Place("rooms/a.webm", "case 42")
+	assert.NoError(t, err)
+	assert.Equal(t, "case 42", h.Reason)
+	held, err := holds.Held("rooms/a.webm")
+	assert.NoError(t, err)
+	assert.True(t, held)
+
+	// The recording, its sidecars and the hold itself can't be deleted
+	for _, name := range []string{"rooms/a.webm", "rooms/a.webm.quality.json", Name("rooms/a.webm")} {
+		assert.True(t, errors.Is(protected.Delete(name), ErrHeld), name)
+	}
+	assert.NoError(t, protected.Delete("rooms/b.webm"))
+	// Nor overwritten by a recording of the same name
+	for _, name := range []string{"rooms/a.webm", "rooms/a.webm.quality.json"} {
+		_, err := protected.Open(name)
+		assert.True(t, errors.Is(err, ErrHeld), name)
+	}
+	w, err := protected.Open("rooms/c.webm")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	assert.NoError(t, holds.Release("rooms/a.webm"))
+	assert.Error(t, holds.Release("rooms/a.webm"))
+	assert.NoError(t, protected.Delete("rooms/a.webm.quality.json"))
+	assert.NoError(t, protected.Delete("rooms/a.webm"))
+}
+
+func TestHolds_Local(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hold")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "a.webm")
+
+	holds := New(nil)
+	assert.NoError(t, holds.Check(name))
+	_, err = holds.Place(name, "")
+	assert.NoError(t, err)
+	assert.FileExists(t, Name(name))
+	assert.True(t, errors.Is(holds.Check(name+".highlights.json"), ErrHeld))
+	assert.NoError(t, holds.Release(name))
+	assert.NoError(t, holds.Check(name))
+}
@@ -0,0 +1,366 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/at-wat/ebml-go"
+	"github.com/at-wat/ebml-go/webm"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/opus"
+)
+
+// ErrFormat is returned for files that aren't WebM, Matroska, IVF or Ogg
+// Opus
+var ErrFormat = errors.New("ingest: unsupported file format")
+
+// Track kinds
+const (
+	KindAudio = "audio"
+	KindVideo = "video"
+)
+
+// Track of an ingested file
+type Track struct {
+	// ID is the track's name in the file, or its kind and number
+	ID   string
+	Kind string
+	// Type of its samples, e.g. avp.TypeOpus
+	Type int
+}
+
+// Media is what a file holds, its samples as a track's builder would
+// produce them
+type Media struct {
+	// Start is when the file says it was recorded, zero if it doesn't
+	Start    time.Time
+	Duration time.Duration
+	Tracks   []Track
+	// Samples of every track by time, with the ID of their track
+	Samples []*avp.Sample
+}
+
+// Demux reads the media of a file, of the format its name's extension
+// says
+func Demux(name string, r io.Reader) (*Media, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".webm", ".mkv":
+		return demuxMatroska(r)
+	case ".ivf":
+		return demuxIVF(r)
+	case ".ogg", ".opus":
+		return demuxOgg(r)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFormat, name)
+}
+
+// frame is a frame of a track with its time from the start of the file
+type frame struct {
+	track int
+	time  time.Duration
+	data  []byte
+}
+
+// samples builds the samples of frames of tracks, in time order
+func (m *Media) samples(frames []frame) {
+	sort.SliceStable(frames, func(i, j int) bool { return frames[i].time < frames[j].time })
+	seq := make([]uint16, len(m.Tracks))
+	for _, f := range frames {
+		t := m.Tracks[f.track]
+		rate := uint32(90000)
+		if t.Kind == KindAudio {
+			rate = 48000
+		}
+		s := &avp.Sample{
+			ID:             t.ID,
+			Type:           t.Type,
+			SequenceNumber: seq[f.track],
+			Timestamp:      uint32((int64(f.time)*int64(rate) + int64(time.Second)/2) / int64(time.Second)),
+			ClockRate:      rate,
+			Payload:        f.data,
+		}
+		if !m.Start.IsZero() {
+			s.CaptureTime = m.Start.Add(f.time)
+		}
+		seq[f.track]++
+		m.Samples = append(m.Samples, s)
+		if f.time > m.Duration {
+			m.Duration = f.time
+		}
+	}
+}
+
+// matroskaTypes are the sample types of Matroska codec ids
+var matroskaTypes = map[string]int{
+	"A_OPUS":          avp.TypeOpus,
+	"V_VP8":           avp.TypeVP8,
+	"V_VP9":           avp.TypeVP9,
+	"V_AV1":           avp.TypeAV1,
+	"V_MPEG4/ISO/AVC": avp.TypeH264,
+}
+
+func demuxMatroska(r io.Reader) (*Media, error) {
+	var doc struct {
+		Header  webm.EBMLHeader `ebml:"EBML"`
+		Segment webm.Segment    `ebml:"Segment"`
+	}
+	if err := ebml.Unmarshal(r, &doc); err != nil {
+		return nil, err
+	}
+	s := doc.Segment
+	m := &Media{}
+	if !s.Info.DateUTC.IsZero() {
+		m.Start = s.Info.DateUTC
+	}
+	scale := time.Duration(s.Info.TimecodeScale)
+	if scale == 0 {
+		scale = time.Millisecond
+	}
+	tracks := make(map[uint64]int)
+	avc := make(map[uint64]*avcParams)
+	for _, t := range s.Tracks.TrackEntry {
+		typ, ok := matroskaTypes[t.CodecID]
+		if !ok {
+			continue
+		}
+		kind := KindVideo
+		if t.TrackType == 2 {
+			kind = KindAudio
+		}
+		id := t.Name
+		if id == "" {
+			id = fmt.Sprintf("%s%d", kind, t.TrackNumber)
+		}
+		if typ == avp.TypeH264 {
+			p, err := readAVCConfig(t.CodecPrivate)
+			if err != nil {
+				return nil, fmt.Errorf("track %s: %w", id, err)
+			}
+			avc[t.TrackNumber] = p
+		}
+		tracks[t.TrackNumber] = len(m.Tracks)
+		m.Tracks = append(m.Tracks, Track{ID: id, Kind: kind, Type: typ})
+	}
+	if len(m.Tracks) == 0 {
+		return nil, fmt.Errorf("%w: no Opus, VP8, VP9, AV1 or H.264 tracks", ErrFormat)
+	}
+
+	var frames []frame
+	add := func(cluster uint64, b ebml.Block) {
+		i, ok := tracks[b.TrackNumber]
+		if !ok {
+			return
+		}
+		t := time.Duration(int64(cluster)+int64(b.Timecode)) * scale
+		for _, data := range b.Data {
+			if p := avc[b.TrackNumber]; p != nil {
+				data = p.annexB(data, b.Keyframe)
+			}
+			frames = append(frames, frame{track: i, time: t, data: data})
+		}
+	}
+	for _, c := range s.Cluster {
+		for _, b := range c.SimpleBlock {
+			add(c.Timecode, b)
+		}
+		for _, g := range c.BlockGroup {
+			g.Block.Keyframe = g.ReferenceBlock == 0
+			add(c.Timecode, g.Block)
+		}
+	}
+	m.samples(frames)
+	return m, nil
+}
+
+// avcParams are the parameter sets of an H.264 track of a Matroska file,
+// whose frames are NAL units prefixed by their length
+type avcParams struct {
+	lengthSize int
+	sets       [][]byte // SPS then PPS
+}
+
+// readAVCConfig reads an AVCDecoderConfigurationRecord
+func readAVCConfig(b []byte) (*avcParams, error) {
+	errConfig := errors.New("invalid H.264 codec private data")
+	if len(b) < 6 {
+		return nil, errConfig
+	}
+	p := &avcParams{lengthSize: int(b[4]&3) + 1}
+	n := int(b[5] & 0x1f)
+	b = b[6:]
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i < n; i++ {
+			if len(b) < 2 {
+				return nil, errConfig
+			}
+			size := int(binary.BigEndian.Uint16(b))
+			if len(b) < 2+size {
+				return nil, errConfig
+			}
+			p.sets = append(p.sets, b[2:2+size])
+			b = b[2+size:]
+		}
+		if pass == 0 {
+			if len(b) < 1 {
+				return nil, errConfig
+			}
+			n, b = int(b[0]), b[1:]
+		}
+	}
+	return p, nil
+}
+
+var startCode = []byte{0, 0, 0, 1}
+
+// annexB converts a frame to NAL units with start codes, as the builder
+// produces them, the parameter sets leading keyframes
+func (p *avcParams) annexB(data []byte, keyframe bool) []byte {
+	var out []byte
+	if keyframe {
+		for _, set := range p.sets {
+			out = append(append(out, startCode...), set...)
+		}
+	}
+	for len(data) >= p.lengthSize {
+		var size int
+		for _, c := range data[:p.lengthSize] {
+			size = size<<8 | int(c)
+		}
+		data = data[p.lengthSize:]
+		if size > len(data) {
+			break
+		}
+		out = append(append(out, startCode...), data[:size]...)
+		data = data[size:]
+	}
+	return out
+}
+
+// ivfTypes are the sample types of IVF fourccs
+var ivfTypes = map[string]int{
+	"VP80": avp.TypeVP8,
+	"VP90": avp.TypeVP9,
+	"AV01": avp.TypeAV1,
+}
+
+func demuxIVF(r io.Reader) (*Media, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 32)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != "DKIF" {
+		return nil, fmt.Errorf("%w: not an IVF file", ErrFormat)
+	}
+	typ, ok := ivfTypes[string(header[8:12])]
+	if !ok {
+		return nil, fmt.Errorf("%w: IVF of %q", ErrFormat, header[8:12])
+	}
+	// Skip the rest of longer headers
+	if size := int(binary.LittleEndian.Uint16(header[6:])); size > 32 {
+		if _, err := io.CopyN(ioutil.Discard, br, int64(size-32)); err != nil {
+			return nil, err
+		}
+	}
+	rate := int64(binary.LittleEndian.Uint32(header[16:]))
+	scale := int64(binary.LittleEndian.Uint32(header[20:]))
+	if rate == 0 || scale == 0 {
+		rate, scale = 1000, 1
+	}
+	m := &Media{Tracks: []Track{{ID: "video", Kind: KindVideo, Type: typ}}}
+	var frames []frame
+	for {
+		fh := make([]byte, 12)
+		if _, err := io.ReadFull(br, fh); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		data := make([]byte, binary.LittleEndian.Uint32(fh))
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, err
+		}
+		pts := int64(binary.LittleEndian.Uint64(fh[4:]))
+		frames = append(frames, frame{time: time.Duration(pts * scale * int64(time.Second) / rate), data: data})
+	}
+	m.samples(frames)
+	return m, nil
+}
+
+// demuxOgg reads the first logical stream of an Ogg file, which must be
+// Opus
+func demuxOgg(r io.Reader) (*Media, error) {
+	br := bufio.NewReader(r)
+	m := &Media{Tracks: []Track{{ID: "audio", Kind: KindAudio, Type: avp.TypeOpus}}}
+	var (
+		frames  []frame
+		packet  []byte
+		serial  uint32
+		started bool
+		headers int
+		at      time.Duration
+	)
+	for {
+		header := make([]byte, 27)
+		if _, err := io.ReadFull(br, header); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if string(header[:4]) != "OggS" {
+			return nil, fmt.Errorf("%w: not an Ogg file", ErrFormat)
+		}
+		lacing := make([]byte, header[26])
+		if _, err := io.ReadFull(br, lacing); err != nil {
+			return nil, err
+		}
+		var size int
+		for _, l := range lacing {
+			size += int(l)
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(br, body); err != nil {
+			return nil, err
+		}
+		s := binary.LittleEndian.Uint32(header[14:])
+		if !started {
+			started, serial = true, s
+		}
+		if s != serial {
+			continue
+		}
+		for _, l := range lacing {
+			packet = append(packet, body[:l]...)
+			body = body[l:]
+			if l == 255 {
+				continue
+			}
+			// The identification and comment headers come first
+			switch {
+			case headers == 0 && !bytes.HasPrefix(packet, []byte("OpusHead")):
+				return nil, fmt.Errorf("%w: Ogg stream isn't Opus", ErrFormat)
+			case headers < 2:
+				headers++
+			default:
+				frames = append(frames, frame{time: at, data: packet})
+				d, err := opus.PacketDuration(packet)
+				if err != nil {
+					d = 20 * time.Millisecond
+				}
+				at += d
+			}
+			packet = nil
+		}
+	}
+	m.samples(frames)
+	return m, nil
+}
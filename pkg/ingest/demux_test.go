@@ -0,0 +1,102 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/manifest/manifesttest"
+	"github.com/pion/ion-avp/pkg/ogg"
+	"github.com/stretchr/testify/assert"
+)
+
+// opusPkt is a 20ms CELT packet
+var opusPkt = []byte{0xf8, 0xff, 0xfe}
+
+func webmFile(t *testing.T) []byte {
+	blocks := manifesttest.Every(1, 5, 20, func(int) []byte {
+		return opusPkt
+	})
+	blocks = append(blocks, manifesttest.Block{Track: 2, Time: 50, Data: []byte{0x10, 0x02, 0x00}})
+	return manifesttest.Segment(t, []webm.TrackEntry{
+		manifesttest.Mic,
+		{TrackNumber: 2, TrackUID: 2, CodecID: "V_VP8", TrackType: 1},
+	}, blocks)
+}
+
+func TestDemux_WebM(t *testing.T) {
+	m, err := Demux("call.webm", bytes.NewReader(webmFile(t)))
+	assert.NoError(t, err)
+	assert.Equal(t, []Track{
+		{ID: "mic", Kind: KindAudio, Type: avp.TypeOpus},
+		{ID: "video2", Kind: KindVideo, Type: avp.TypeVP8},
+	}, m.Tracks)
+	assert.Equal(t, 80*time.Millisecond, m.Duration)
+	if assert.Len(t, m.Samples, 6) {
+		assert.Equal(t, "mic", m.Samples[0].ID)
+		assert.Equal(t, uint32(48000), m.Samples[0].ClockRate)
+		assert.Equal(t, "video2", m.Samples[3].ID)
+		assert.Equal(t, uint32(50*90), m.Samples[3].Timestamp)
+		assert.Equal(t, uint16(3), m.Samples[4].SequenceNumber)
+		assert.Equal(t, uint32(60*48), m.Samples[4].Timestamp)
+	}
+}
+
+// ivfFile is three VP8 frames at 30fps
+func ivfFile() []byte {
+	header := make([]byte, 32)
+	copy(header, "DKIF")
+	binary.LittleEndian.PutUint16(header[6:], 32)
+	copy(header[8:], "VP80")
+	binary.LittleEndian.PutUint32(header[16:], 30)
+	binary.LittleEndian.PutUint32(header[20:], 1)
+	buf := bytes.NewBuffer(header)
+	for pts := uint64(0); pts < 3; pts++ {
+		fh := make([]byte, 12)
+		binary.LittleEndian.PutUint32(fh, 2)
+		binary.LittleEndian.PutUint64(fh[4:], pts)
+		buf.Write(fh)
+		buf.Write([]byte{byte(pts), 0})
+	}
+	return buf.Bytes()
+}
+
+func TestDemux_IVF(t *testing.T) {
+	m, err := Demux("clip.IVF", bytes.NewReader(ivfFile()))
+	assert.NoError(t, err)
+	assert.Equal(t, []Track{{ID: "video", Kind: KindVideo, Type: avp.TypeVP8}}, m.Tracks)
+	if assert.Len(t, m.Samples, 3) {
+		assert.Equal(t, uint32(6000), m.Samples[2].Timestamp)
+		assert.Equal(t, []byte{2, 0}, m.Samples[2].Payload)
+	}
+	assert.Equal(t, 2*time.Second/30, m.Duration)
+}
+
+func TestDemux_Ogg(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := ogg.NewWriter(buf, 1)
+	assert.NoError(t, w.WritePage(ogg.First, 0, ogg.OpusHead(2, 312)))
+	assert.NoError(t, w.WritePage(0, 0, ogg.OpusTags("test")))
+	// Another stream's pages are skipped
+	assert.NoError(t, ogg.NewWriter(buf, 2).WritePage(ogg.First, 0, []byte("other")))
+	assert.NoError(t, w.WritePage(ogg.Last, 3*960, opusPkt, opusPkt, opusPkt))
+
+	m, err := Demux("talk.opus", buf)
+	assert.NoError(t, err)
+	if assert.Len(t, m.Samples, 3) {
+		assert.Equal(t, uint32(2*960), m.Samples[2].Timestamp)
+		assert.Equal(t, opusPkt, m.Samples[2].Payload)
+	}
+	assert.Equal(t, 40*time.Millisecond, m.Duration)
+
+	_, err = Demux("talk.ogg", bytes.NewReader([]byte("nope")))
+	assert.Error(t, err)
+}
+
+func TestDemux_Format(t *testing.T) {
+	_, err := Demux("movie.mp4", bytes.NewReader(nil))
+	assert.ErrorIs(t, err, ErrFormat)
+}
@@ -0,0 +1,253 @@
+// Package ingest runs media files produced elsewhere through pipelines of
+// elements, as the tracks of sessions are, e.g. to transcode, thumbnail,
+// upload or index offline assets. Files are ingested on request, or as
+// they appear in a watched directory.
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucsky/cuid"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/archive"
+	"github.com/pion/ion-avp/pkg/recording"
+	log "github.com/pion/ion-log"
+)
+
+// Subdirectories of the watched directory files are moved to once ingested
+const (
+	DoneDir   = "done"
+	FailedDir = "failed"
+)
+
+// Pipeline is an element of the registry run on the tracks of files
+// Eid: Of the element.
+// Config: Passed to the element, as a Process's is.
+// Kind: Of the tracks it runs on, KindAudio or KindVideo, all if empty.
+type Pipeline struct {
+	Eid    string
+	Config []byte
+	Kind   string
+}
+
+// Config configures an Ingester
+// Dir: Directory watched for files, none if empty. Files are moved to its
+// DoneDir once ingested, or FailedDir.
+// Interval: How often the directory is scanned, 5s by default.
+// Settle: How long a file must go unchanged before it is ingested, so those
+// still being copied in aren't, 2s by default.
+// Pipelines: Run on the tracks of each file.
+// Index: Optional archive files are listed in once ingested.
+// NewElement: Creates the elements of pipelines, avp.NewElement by default.
+type Config struct {
+	Dir        string
+	Interval   time.Duration
+	Settle     time.Duration
+	Pipelines  []Pipeline
+	Index      *archive.Index
+	NewElement func(eid, sid, pid, tid string, config []byte) (avp.Element, error)
+}
+
+// Result of ingesting a file
+type Result struct {
+	// ID is the session of the file's tracks in pipelines, and of its
+	// entry in the archive
+	ID       string
+	Name     string
+	Tracks   []Track
+	Duration time.Duration
+}
+
+// Ingester runs files through pipelines
+type Ingester struct {
+	cfg     Config
+	seen    map[string]os.FileInfo // files of the directory, as last scanned
+	stop    chan struct{}
+	stopped sync.Once
+	done    chan struct{}
+}
+
+// New returns an Ingester, which watches its directory if it has one
+func New(cfg Config) *Ingester {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.Settle <= 0 {
+		cfg.Settle = 2 * time.Second
+	}
+	if cfg.NewElement == nil {
+		cfg.NewElement = avp.NewElement
+	}
+	in := &Ingester{
+		cfg:  cfg,
+		seen: make(map[string]os.FileInfo),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if cfg.Dir == "" {
+		close(in.done)
+		return in
+	}
+	go in.watch()
+	return in
+}
+
+// Ingest runs the tracks of a file through the pipelines that take them,
+// then lists it in the archive
+func (in *Ingester) Ingest(ctx context.Context, name string) (Result, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return Result{}, err
+	}
+	m, err := Demux(name, f)
+	f.Close()
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{ID: cuid.New(), Name: name, Tracks: m.Tracks, Duration: m.Duration}
+
+	elements := make(map[string][]avp.Element)
+	closeAll := func() {
+		for _, es := range elements {
+			for _, e := range es {
+				e.Close()
+			}
+		}
+	}
+	for _, t := range m.Tracks {
+		for _, p := range in.cfg.Pipelines {
+			if p.Kind != "" && p.Kind != t.Kind {
+				continue
+			}
+			e, err := in.cfg.NewElement(p.Eid, res.ID, p.Eid+"/"+t.ID, t.ID, p.Config)
+			if err != nil {
+				closeAll()
+				return res, fmt.Errorf("element %s: %w", p.Eid, err)
+			}
+			elements[t.ID] = append(elements[t.ID], e)
+		}
+	}
+	for _, s := range m.Samples {
+		if err := ctx.Err(); err != nil {
+			closeAll()
+			return res, err
+		}
+		for _, e := range elements[s.ID] {
+			if err := avp.WriteContext(ctx, e, s); err != nil {
+				closeAll()
+				return res, fmt.Errorf("track %s: %w", s.ID, err)
+			}
+		}
+	}
+	closeAll()
+	log.Infof("ingested %s as %s, %d tracks of %s", name, res.ID, len(m.Tracks), m.Duration)
+
+	if in.cfg.Index != nil {
+		start := m.Start
+		if start.IsZero() {
+			start = time.Now().Add(-m.Duration)
+		}
+		err := in.cfg.Index.Update(res.ID, func(e *archive.Entry) {
+			e.Session, e.Name, e.State = res.ID, name, recording.StateComplete
+			e.Start, e.End = start, start.Add(m.Duration)
+			e.Locations = []string{name}
+		})
+		if err != nil {
+			log.Errorf("ingest: indexing %s: %s", name, err)
+		}
+	}
+	return res, nil
+}
+
+// Close stops watching the directory
+func (in *Ingester) Close() {
+	in.stopped.Do(func() { close(in.stop) })
+	<-in.done
+}
+
+func (in *Ingester) watch() {
+	defer close(in.done)
+	ticker := time.NewTicker(in.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		in.scan()
+		select {
+		case <-in.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan ingests the files of the directory unchanged since the last scan
+// and for the settle time, one at a time
+func (in *Ingester) scan() {
+	infos, err := ioutil.ReadDir(in.cfg.Dir)
+	if err != nil {
+		log.Errorf("ingest: reading %s: %s", in.cfg.Dir, err)
+		return
+	}
+	seen := make(map[string]os.FileInfo)
+	for _, fi := range infos {
+		name := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(name, ".") || !supported(name) {
+			continue
+		}
+		seen[name] = fi
+		last, ok := in.seen[name]
+		if !ok || last.Size() != fi.Size() || !last.ModTime().Equal(fi.ModTime()) || time.Since(fi.ModTime()) < in.cfg.Settle {
+			continue
+		}
+		select {
+		case <-in.stop:
+			return
+		default:
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-in.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		path := filepath.Join(in.cfg.Dir, name)
+		_, err := in.Ingest(ctx, path)
+		cancel()
+		dir := DoneDir
+		if err != nil {
+			log.Errorf("ingest: %s: %s", path, err)
+			dir = FailedDir
+		}
+		if err := in.move(name, dir); err != nil {
+			log.Errorf("ingest: moving %s to %s: %s", path, dir, err)
+		}
+		delete(seen, name)
+	}
+	in.seen = seen
+}
+
+// move moves a file of the directory to a subdirectory of it
+func (in *Ingester) move(name, dir string) error {
+	dir = filepath.Join(in.cfg.Dir, dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(in.cfg.Dir, name), filepath.Join(dir, name))
+}
+
+// supported reports whether Demux reads files of name's extension
+func supported(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".webm", ".mkv", ".ivf", ".ogg", ".opus":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/archive"
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+// collector records the elements created and what they're written
+type collector struct {
+	mu       sync.Mutex
+	elements map[string]*collected // by pid
+}
+
+type collected struct {
+	sid, tid string
+	config   []byte
+	samples  []*avp.Sample
+	closed   bool
+}
+
+func (c *collected) Write(s *avp.Sample) error {
+	c.samples = append(c.samples, s)
+	return nil
+}
+
+func (c *collected) Attach(e avp.Element) {}
+
+func (c *collected) Close() {
+	c.closed = true
+}
+
+func (c *collector) newElement(eid, sid, pid, tid string, config []byte) (avp.Element, error) {
+	if eid == "missing" {
+		return nil, avp.ErrElementNotFound
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &collected{sid: sid, tid: tid, config: config}
+	c.elements[pid] = e
+	return e, nil
+}
+
+func (c *collector) get(pid string) *collected {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.elements[pid]
+}
+
+func TestIngester_Ingest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ingest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "call.webm")
+	assert.NoError(t, ioutil.WriteFile(name, webmFile(t), 0644))
+	x, err := archive.Open(filepath.Join(dir, "index.jsonl"))
+	assert.NoError(t, err)
+	defer x.Close()
+
+	c := &collector{elements: map[string]*collected{}}
+	in := New(Config{
+		Pipelines: []Pipeline{
+			{Eid: "transcode", Config: []byte("{}")},
+			{Eid: "thumbnail", Kind: KindVideo},
+		},
+		Index:      x,
+		NewElement: c.newElement,
+	})
+	defer in.Close()
+	res, err := in.Ingest(context.Background(), name)
+	assert.NoError(t, err)
+	assert.Len(t, res.Tracks, 2)
+	assert.Equal(t, 80*time.Millisecond, res.Duration)
+
+	// Every pipeline of the tracks of its kind
+	assert.Len(t, c.elements, 3)
+	mic := c.get("transcode/mic")
+	if assert.NotNil(t, mic) {
+		assert.Equal(t, res.ID, mic.sid)
+		assert.Equal(t, "mic", mic.tid)
+		assert.Equal(t, []byte("{}"), mic.config)
+		assert.Len(t, mic.samples, 5)
+		assert.True(t, mic.closed)
+	}
+	assert.Nil(t, c.get("thumbnail/mic"))
+	if thumbnail := c.get("thumbnail/video2"); assert.NotNil(t, thumbnail) {
+		assert.Len(t, thumbnail.samples, 1)
+	}
+
+	e, ok := x.Get(res.ID)
+	if assert.True(t, ok) {
+		assert.Equal(t, recording.StateComplete, e.State)
+		assert.Equal(t, []string{name}, e.Locations)
+		assert.Equal(t, 80*time.Millisecond, e.Duration())
+	}
+
+	in = New(Config{Pipelines: []Pipeline{{Eid: "missing"}}, NewElement: c.newElement})
+	_, err = in.Ingest(context.Background(), name)
+	assert.True(t, errors.Is(err, avp.ErrElementNotFound))
+}
+
+func TestIngester_Watch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ingest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := &collector{elements: map[string]*collected{}}
+	in := New(Config{
+		Dir:        dir,
+		Interval:   10 * time.Millisecond,
+		Settle:     time.Millisecond,
+		Pipelines:  []Pipeline{{Eid: "transcode"}},
+		NewElement: c.newElement,
+	})
+	defer in.Close()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "call.webm"), webmFile(t), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "broken.ivf"), []byte("nope"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skipped"), 0644))
+
+	moved := func(sub, name string) func() bool {
+		return func() bool {
+			_, err := os.Stat(filepath.Join(dir, sub, name))
+			return err == nil
+		}
+	}
+	assert.Eventually(t, moved(DoneDir, "call.webm"), time.Second, 10*time.Millisecond)
+	assert.Eventually(t, moved(FailedDir, "broken.ivf"), time.Second, 10*time.Millisecond)
+	assert.NotNil(t, c.get("transcode/mic"))
+	_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+	assert.NoError(t, err)
+}
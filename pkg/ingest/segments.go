@@ -0,0 +1,54 @@
+package ingest
+
+import (
+	"fmt"
+
+	"github.com/pion/ion-avp/pkg/manifest"
+)
+
+// SegmentTrack is a track of a recording's manifest with media of a kind
+type SegmentTrack struct {
+	// Name is the track's name in the manifest
+	Name string
+	// ID is the ID of its media of the kind in its segments
+	ID       string
+	Segments []manifest.Segment
+}
+
+// FindTracks finds the tracks of m with media of kind, by the first
+// media track of the kind in each one's first segment. At most max are
+// found, unless max is 0.
+func FindTracks(m *manifest.Manifest, open manifest.OpenFunc, kind string, max int) ([]SegmentTrack, error) {
+	var tracks []SegmentTrack
+	for _, name := range m.Tracks() {
+		if max > 0 && len(tracks) == max {
+			break
+		}
+		segs := m.TrackSegments(name)
+		media, err := DemuxSegment(open, segs[0].Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range media.Tracks {
+			if t.Kind == kind {
+				tracks = append(tracks, SegmentTrack{Name: name, ID: t.ID, Segments: segs})
+				break
+			}
+		}
+	}
+	return tracks, nil
+}
+
+// DemuxSegment opens and demuxes the segment of a recording called name
+func DemuxSegment(open manifest.OpenFunc, name string) (*Media, error) {
+	r, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	media, err := Demux(name, r)
+	if err != nil {
+		return nil, fmt.Errorf("segment %s: %w", name, err)
+	}
+	return media, nil
+}
@@ -0,0 +1,51 @@
+package ingest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/manifest/manifesttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindTracks(t *testing.T) {
+	files := manifesttest.Files{
+		"cam-0.webm": webmFile(t),
+		"screen.ivf": ivfFile(),
+		"notes.txt":  []byte("not media"),
+	}
+	open := files.Open
+	m := manifest.New("rec", "sid", time.Time{})
+	m.Segments = []manifest.Segment{
+		{File: manifest.File{Name: "screen.ivf"}, Track: "screen"},
+		{File: manifest.File{Name: "cam-1.webm"}, Track: "cam", Seq: 1},
+		{File: manifest.File{Name: "cam-0.webm"}, Track: "cam"},
+	}
+
+	// By the first segment of each track, in track order
+	tracks, err := FindTracks(m, open, KindAudio, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, tracks, 1) {
+		assert.Equal(t, "cam", tracks[0].Name)
+		assert.Equal(t, "mic", tracks[0].ID)
+		assert.Equal(t, m.TrackSegments("cam"), tracks[0].Segments)
+	}
+	tracks, err = FindTracks(m, open, KindVideo, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []SegmentTrack{
+		{Name: "cam", ID: "video2", Segments: m.TrackSegments("cam")},
+		{Name: "screen", ID: "video", Segments: m.TrackSegments("screen")},
+	}, tracks)
+	tracks, err = FindTracks(m, open, KindVideo, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, tracks, 1) {
+		assert.Equal(t, "cam", tracks[0].Name)
+	}
+
+	m.Segments = append(m.Segments, manifest.Segment{File: manifest.File{Name: "notes.txt"}, Track: "notes"})
+	_, err = FindTracks(m, open, KindAudio, 0)
+	assert.True(t, errors.Is(err, ErrFormat))
+	assert.Contains(t, err.Error(), "segment notes.txt")
+}
@@ -0,0 +1,91 @@
+// Package interpret routes the audio of interpreters to the channels of
+// the languages they interpret into, for multilingual events.
+package interpret
+
+import (
+	"sort"
+	"sync"
+)
+
+// Route of a channel, the interpreter whose audio it carries
+type Route struct {
+	// Source is the audio track id of the interpreter
+	Source string
+	// Language is an RFC 5646 tag of the channel, e.g. "fr"
+	Language string
+}
+
+// Routes of a session by channel name, supplied through the control API
+// as interpreters hand over to each other in the booth. A channel carries
+// one interpreter at a time, a source may feed several channels, e.g. the
+// floor's.
+type Routes struct {
+	mu sync.RWMutex
+	m  map[string]Route
+}
+
+// NewRoutes returns routes with no channels
+func NewRoutes() *Routes {
+	return &Routes{m: make(map[string]Route)}
+}
+
+// Set the route of a channel, replacing its interpreter
+func (r *Routes) Set(channel string, route Route) {
+	r.mu.Lock()
+	r.m[channel] = route
+	r.mu.Unlock()
+}
+
+// Remove a channel
+func (r *Routes) Remove(channel string) {
+	r.mu.Lock()
+	delete(r.m, channel)
+	r.mu.Unlock()
+}
+
+// Get the route of a channel
+func (r *Routes) Get(channel string) (Route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	route, ok := r.m[channel]
+	return route, ok
+}
+
+// Channels returns the names of the channels a source feeds, sorted
+func (r *Routes) Channels(source string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var channels []string
+	for name, route := range r.m {
+		if route.Source == source {
+			channels = append(channels, name)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+var sessions = struct {
+	sync.Mutex
+	m map[string]*Routes
+}{m: make(map[string]*Routes)}
+
+// Session returns the routes of a session, created on first use, so
+// routing elements built for it and the control API share them
+func Session(sid string) *Routes {
+	sessions.Lock()
+	defer sessions.Unlock()
+	r := sessions.m[sid]
+	if r == nil {
+		r = NewRoutes()
+		sessions.m[sid] = r
+	}
+	return r
+}
+
+// End forgets the routes of a session
+func End(sid string) {
+	sessions.Lock()
+	delete(sessions.m, sid)
+	sessions.Unlock()
+}
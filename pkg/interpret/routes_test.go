@@ -0,0 +1,38 @@
+package interpret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutes(t *testing.T) {
+	r := NewRoutes()
+	assert.Empty(t, r.Channels("anna-mic"))
+
+	r.Set("fr", Route{Source: "anna-mic", Language: "fr"})
+	r.Set("relay", Route{Source: "anna-mic", Language: "fr"})
+	r.Set("de", Route{Source: "ben-mic", Language: "de"})
+	assert.Equal(t, []string{"fr", "relay"}, r.Channels("anna-mic"))
+
+	// Hand over to the booth partner
+	r.Set("fr", Route{Source: "carla-mic", Language: "fr"})
+	assert.Equal(t, []string{"relay"}, r.Channels("anna-mic"))
+	route, ok := r.Get("fr")
+	assert.True(t, ok)
+	assert.Equal(t, "carla-mic", route.Source)
+
+	r.Remove("fr")
+	_, ok = r.Get("fr")
+	assert.False(t, ok)
+}
+
+func TestSession(t *testing.T) {
+	r := Session("s1")
+	assert.Same(t, r, Session("s1"))
+	assert.NotSame(t, r, Session("s2"))
+	End("s1")
+	assert.NotSame(t, r, Session("s1"))
+	End("s1")
+	End("s2")
+}
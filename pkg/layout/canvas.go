@@ -0,0 +1,108 @@
+package layout
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/pixel"
+)
+
+// Margins keep tiles off the edges of the canvas, as fractions of its
+// width and height, so branding in the background stays visible and
+// titles stay clear of players' controls
+type Margins struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+// Canvas is what a composite draws its tiles on: a background colour,
+// image or animated slate, with safe margins tiles are placed within
+type Canvas struct {
+	mu         sync.Mutex
+	background color.YCbCr
+	// frames of the background, one for an image, cycled every interval
+	// for a slate
+	frames   []*image.YCbCr
+	interval time.Duration
+	margins  Margins
+	// scaled are the frames at the size last drawn
+	scaled []*image.YCbCr
+}
+
+// NewCanvas returns a black canvas without margins
+func NewCanvas() *Canvas {
+	return &Canvas{background: pixel.Black}
+}
+
+// SetBackground sets the colour of the canvas, and the frames of a
+// background image or slate drawn over it, scaled to fill the canvas.
+// Frames of a slate are shown for interval each, in a loop.
+func (c *Canvas) SetBackground(colour Colour, frames []*image.YCbCr, interval time.Duration) error {
+	if len(frames) > 1 && interval <= 0 {
+		return fmt.Errorf("%w: slates need a frame interval", ErrDefinition)
+	}
+	r, g, b := colour.R, colour.G, colour.B
+	y, cb, cr := color.RGBToYCbCr(r, g, b)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.background = pixel.Limited(color.YCbCr{Y: y, Cb: cb, Cr: cr})
+	c.frames, c.interval, c.scaled = frames, interval, nil
+	return nil
+}
+
+// SetMargins sets the safe margins
+func (c *Canvas) SetMargins(m Margins) error {
+	if m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0 || m.Left+m.Right >= 1 || m.Top+m.Bottom >= 1 {
+		return fmt.Errorf("%w: margins must leave room for tiles", ErrDefinition)
+	}
+	c.mu.Lock()
+	c.margins = m
+	c.mu.Unlock()
+	return nil
+}
+
+// Place places sources with p within the safe area of a canvas of width
+// by height
+func (c *Canvas) Place(p Placer, width, height int, sources []Source) []Tile {
+	c.mu.Lock()
+	m := c.margins
+	c.mu.Unlock()
+	safe := image.Rect(fraction(m.Left, width), fraction(m.Top, height),
+		fraction(1-m.Right, width), fraction(1-m.Bottom, height))
+	tiles := p.Place(safe.Dx(), safe.Dy(), sources)
+	for i := range tiles {
+		tiles[i].Rect = tiles[i].Rect.Add(safe.Min)
+	}
+	return tiles
+}
+
+// Draw paints the background onto dst, at elapsed into the composite for
+// slates
+func (c *Canvas) Draw(dst *image.YCbCr, elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pixel.Fill(dst, c.background)
+	if len(c.frames) == 0 {
+		return
+	}
+	i := 0
+	if len(c.frames) > 1 {
+		i = int(elapsed/c.interval) % len(c.frames)
+	}
+	if c.scaled == nil || c.scaled[i] != nil && c.scaled[i].Rect.Size() != dst.Rect.Size() {
+		c.scaled = make([]*image.YCbCr, len(c.frames))
+	}
+	if c.scaled[i] == nil {
+		m := image.NewYCbCr(image.Rectangle{Max: dst.Rect.Size()}, dst.SubsampleRatio)
+		if err := pixel.ScaleFit(m, c.frames[i], pixel.FitCrop); err != nil {
+			return
+		}
+		c.scaled[i] = m
+	}
+	_ = pixel.Copy(dst, c.scaled[i])
+}
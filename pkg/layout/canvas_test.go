@@ -0,0 +1,52 @@
+package layout
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/pixel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanvas_Place(t *testing.T) {
+	c := NewCanvas()
+	assert.NoError(t, c.SetMargins(Margins{Top: 0.1, Right: 0.05, Bottom: 0.1, Left: 0.05}))
+	tiles := c.Place(Layout{}, 1280, 720, []Source{{ID: "alice"}})
+	assert.Equal(t, []Tile{{ID: "alice", Rect: image.Rect(64, 72, 1216, 648)}}, tiles)
+
+	err := c.SetMargins(Margins{Left: 0.5, Right: 0.5})
+	assert.True(t, errors.Is(err, ErrDefinition))
+}
+
+func TestCanvas_Draw(t *testing.T) {
+	c := NewCanvas()
+	dst := image.NewYCbCr(image.Rect(0, 0, 64, 36), image.YCbCrSubsampleRatio420)
+	c.Draw(dst, 0)
+	assert.Equal(t, pixel.Black, dst.YCbCrAt(10, 10))
+
+	assert.NoError(t, c.SetBackground(Colour{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, nil, 0))
+	c.Draw(dst, 0)
+	assert.Equal(t, color.YCbCr{Y: 235, Cb: 128, Cr: 128}, dst.YCbCrAt(10, 10))
+
+	// A slate cycles its frames, scaled to the canvas
+	grey := color.YCbCr{Y: 128, Cb: 128, Cr: 128}
+	frames := []*image.YCbCr{
+		image.NewYCbCr(image.Rect(0, 0, 16, 9), image.YCbCrSubsampleRatio420),
+		image.NewYCbCr(image.Rect(0, 0, 16, 9), image.YCbCrSubsampleRatio420),
+	}
+	pixel.Fill(frames[0], pixel.Black)
+	pixel.Fill(frames[1], grey)
+	assert.NoError(t, c.SetBackground(Colour{A: 0xff}, frames, time.Second))
+	c.Draw(dst, 500*time.Millisecond)
+	assert.Equal(t, pixel.Black, dst.YCbCrAt(63, 35))
+	c.Draw(dst, 1500*time.Millisecond)
+	assert.Equal(t, grey, dst.YCbCrAt(63, 35))
+	c.Draw(dst, 2500*time.Millisecond)
+	assert.Equal(t, pixel.Black, dst.YCbCrAt(63, 35))
+
+	err := c.SetBackground(Colour{}, frames, 0)
+	assert.True(t, errors.Is(err, ErrDefinition))
+}
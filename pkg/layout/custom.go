@@ -0,0 +1,231 @@
+package layout
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrDefinition is returned by Parse for invalid layout definitions
+var ErrDefinition = errors.New("layout: invalid definition")
+
+// SourceScreen selects the first screen share for a region
+const SourceScreen = "screen"
+
+// Custom is a layout defined declaratively, as JSON like
+//
+//	{
+//	  "background": "#1a1a1a",
+//	  "regions": [
+//	    {"source": "screen", "x": 0, "y": 0, "width": 0.75, "height": 1},
+//	    {"x": 0.75, "y": 0, "width": 0.25, "height": 0.25, "z": 1, "label": true,
+//	     "border": {"width": 2, "colour": "#ffffff"}}
+//	  ]
+//	}
+//
+// Sources not matched to a region aren't shown.
+type Custom struct {
+	Background Colour   `json:"background"`
+	Regions    []Region `json:"regions"`
+}
+
+// Region of the canvas a source is shown in. Its position and size are
+// fractions of the canvas.
+type Region struct {
+	// Source is the id of the source shown, SourceScreen for the first
+	// screen share, or empty for the next source not shown elsewhere
+	Source string  `json:"source,omitempty"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Z      int     `json:"z,omitempty"`
+	Border Border  `json:"border"`
+	Label  bool    `json:"label,omitempty"`
+}
+
+// Border drawn inside a tile, none if its width is zero
+type Border struct {
+	Width  int    `json:"width,omitempty"`
+	Colour Colour `json:"colour"`
+}
+
+// Colour is an RGB colour, "#rrggbb" or "#rrggbbaa" in JSON
+type Colour color.RGBA
+
+// UnmarshalJSON parses a hex colour
+func (c *Colour) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	s = strings.TrimPrefix(s, "#")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || (len(s) != 6 && len(s) != 8) {
+		return fmt.Errorf("%w: colour %q isn't #rrggbb", ErrDefinition, s)
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xff
+	}
+	*c = Colour{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}
+	return nil
+}
+
+// MarshalJSON writes a hex colour
+func (c Colour) MarshalJSON() ([]byte, error) {
+	s := fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	if c.A != 0xff {
+		s += fmt.Sprintf("%02x", c.A)
+	}
+	return json.Marshal(s)
+}
+
+// Parse reads a layout definition
+func Parse(b []byte) (*Custom, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	c := &Custom{Background: Colour{A: 0xff}}
+	if err := d.Decode(c); err != nil {
+		if errors.Is(err, ErrDefinition) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDefinition, err)
+	}
+	if len(c.Regions) == 0 {
+		return nil, fmt.Errorf("%w: no regions", ErrDefinition)
+	}
+	const epsilon = 1e-9
+	for i, r := range c.Regions {
+		switch {
+		case r.X < 0 || r.Y < 0 || r.Width <= 0 || r.Height <= 0:
+			return nil, fmt.Errorf("%w: region %d has a negative position or no size", ErrDefinition, i)
+		case r.X+r.Width > 1+epsilon || r.Y+r.Height > 1+epsilon:
+			return nil, fmt.Errorf("%w: region %d is outside the canvas", ErrDefinition, i)
+		case r.Border.Width < 0:
+			return nil, fmt.Errorf("%w: region %d has a negative border", ErrDefinition, i)
+		}
+	}
+	return c, nil
+}
+
+// Define returns the layout of a preset mode's name, see ParseMode, of a
+// fixed grid of columns by rows like "3x2", or of a JSON definition, see
+// Custom
+func Define(s string) (Placer, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		return Parse([]byte(s))
+	}
+	var cols, rows int
+	grid := strings.ToLower(strings.TrimSpace(s))
+	if _, err := fmt.Sscanf(grid, "%dx%d", &cols, &rows); err == nil && fmt.Sprintf("%dx%d", cols, rows) == grid {
+		if cols <= 0 || rows <= 0 {
+			return nil, fmt.Errorf("%w: grid %q has no tiles", ErrDefinition, s)
+		}
+		return Layout{Mode: ModeGrid, Columns: cols, Rows: rows}, nil
+	}
+	m, err := ParseMode(s)
+	if err != nil {
+		return nil, err
+	}
+	return Layout{Mode: m}, nil
+}
+
+// Place returns a tile for each region with a source, lowest z first
+func (c *Custom) Place(width, height int, sources []Source) []Tile {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	shown := make([]bool, len(sources))
+	assigned := make([]int, len(c.Regions))
+	// Regions naming their source pick first, the rest fill in order
+	for i, r := range c.Regions {
+		assigned[i] = -1
+		for j, s := range sources {
+			if shown[j] {
+				continue
+			}
+			if r.Source == s.ID || (r.Source == SourceScreen && s.Screen) {
+				assigned[i], shown[j] = j, true
+				break
+			}
+		}
+	}
+	next := 0
+	for i, r := range c.Regions {
+		if r.Source != "" {
+			continue
+		}
+		for next < len(sources) && shown[next] {
+			next++
+		}
+		if next == len(sources) {
+			break
+		}
+		assigned[i], shown[next] = next, true
+	}
+
+	var tiles []Tile
+	for i, r := range c.Regions {
+		if assigned[i] < 0 {
+			continue
+		}
+		x0, y0 := fraction(r.X, width), fraction(r.Y, height)
+		x1, y1 := fraction(r.X+r.Width, width), fraction(r.Y+r.Height, height)
+		tiles = append(tiles, Tile{
+			ID:     sources[assigned[i]].ID,
+			Rect:   image.Rect(x0, y0, x1, y1),
+			Z:      r.Z,
+			Border: r.Border,
+			Label:  r.Label,
+		})
+	}
+	sort.SliceStable(tiles, func(i, j int) bool { return tiles[i].Z < tiles[j].Z })
+	return tiles
+}
+
+// fraction returns the even pixel f of the way along n
+func fraction(f float64, n int) int {
+	v := int(math.Round(f * float64(n)))
+	if v > n {
+		v = n
+	}
+	return even(v)
+}
+
+// Current is the layout of a composite, switched at runtime
+type Current struct {
+	mu sync.RWMutex
+	p  Placer
+}
+
+// NewCurrent returns the layout p, until switched
+func NewCurrent(p Placer) *Current {
+	return &Current{p: p}
+}
+
+// Set switches the layout, from the next frame placed
+func (c *Current) Set(p Placer) {
+	c.mu.Lock()
+	c.p = p
+	c.mu.Unlock()
+}
+
+// Get returns the layout
+func (c *Current) Get() Placer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.p
+}
+
+// Place places sources with the layout
+func (c *Current) Place(width, height int, sources []Source) []Tile {
+	return c.Get().Place(width, height, sources)
+}
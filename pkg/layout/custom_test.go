@@ -0,0 +1,82 @@
+package layout
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const definition = `{
+	"background": "#1a1a1a",
+	"regions": [
+		{"x": 0.75, "y": 0, "width": 0.25, "height": 0.25, "z": 1, "label": true,
+		 "border": {"width": 2, "colour": "#ffffff80"}},
+		{"source": "screen", "x": 0, "y": 0, "width": 1, "height": 1},
+		{"source": "bob", "x": 0.75, "y": 0.75, "width": 0.25, "height": 0.25, "z": 1}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(definition))
+	assert.NoError(t, err)
+	assert.Equal(t, Colour{R: 0x1a, G: 0x1a, B: 0x1a, A: 0xff}, c.Background)
+	assert.Len(t, c.Regions, 3)
+	assert.Equal(t, Border{Width: 2, Colour: Colour{R: 0xff, G: 0xff, B: 0xff, A: 0x80}}, c.Regions[0].Border)
+
+	b, err := json.Marshal(c.Regions[0].Border)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"width": 2, "colour": "#ffffff80"}`, string(b))
+
+	for _, invalid := range []string{
+		`{}`,
+		`{"regions": [{"x": 0.5, "y": 0, "width": 0.75, "height": 1}]}`,
+		`{"regions": [{"x": 0, "y": 0, "width": 0, "height": 1}]}`,
+		`{"background": "red", "regions": [{"x": 0, "y": 0, "width": 1, "height": 1}]}`,
+		`{"regions": [{"x": 0, "y": 0, "width": 1, "height": 1, "opacity": 1}]}`,
+	} {
+		_, err := Parse([]byte(invalid))
+		assert.True(t, errors.Is(err, ErrDefinition), invalid)
+	}
+}
+
+func TestCustom_Place(t *testing.T) {
+	c, err := Parse([]byte(definition))
+	assert.NoError(t, err)
+
+	tiles := c.Place(1280, 720, []Source{{ID: "alice"}, {ID: "bob"}, {ID: "screen", Screen: true}, {ID: "carol"}})
+	assert.Equal(t, []Tile{
+		{ID: "screen", Rect: image.Rect(0, 0, 1280, 720)},
+		{ID: "alice", Rect: image.Rect(960, 0, 1280, 180), Z: 1, Label: true, Border: c.Regions[0].Border},
+		{ID: "bob", Rect: image.Rect(960, 540, 1280, 720), Z: 1},
+	}, tiles)
+
+	// Regions without a source to show are left out
+	tiles = c.Place(1280, 720, []Source{{ID: "bob"}})
+	assert.Equal(t, []Tile{{ID: "bob", Rect: image.Rect(960, 540, 1280, 720), Z: 1}}, tiles)
+}
+
+func TestCurrent(t *testing.T) {
+	sources := []Source{{ID: "alice"}, {ID: "screen", Screen: true}}
+	current := NewCurrent(Layout{})
+	assert.Len(t, current.Place(1280, 720, sources), 2)
+
+	p, err := Define(`{"regions": [{"source": "screen", "x": 0, "y": 0, "width": 1, "height": 1}]}`)
+	assert.NoError(t, err)
+	current.Set(p)
+	assert.Equal(t, []Tile{{ID: "screen", Rect: image.Rect(0, 0, 1280, 720)}}, current.Place(1280, 720, sources))
+
+	p, err = Define("screenshare")
+	assert.NoError(t, err)
+	assert.Equal(t, Layout{Mode: ModeScreenShare}, p)
+	_, err = Define("mosaic")
+	assert.Equal(t, ErrMode, err)
+
+	p, err = Define("3X2")
+	assert.NoError(t, err)
+	assert.Equal(t, Layout{Columns: 3, Rows: 2}, p)
+	_, err = Define("0x2")
+	assert.True(t, errors.Is(err, ErrDefinition))
+}
@@ -0,0 +1,178 @@
+// Package layout places the video of a session's participants on the
+// canvas of a composite recording. Layouts are computed from the sources
+// present on each frame, so they change as participants and screen shares
+// come and go.
+package layout
+
+import (
+	"errors"
+	"image"
+	"math"
+	"strings"
+)
+
+// Mode is how sources are arranged on the canvas
+type Mode int
+
+// Modes
+const (
+	// ModeGrid gives every source an equal tile
+	ModeGrid Mode = iota
+	// ModeScreenShare gives an active screen share most of the canvas,
+	// with participants as thumbnails in a strip beside it. Without a
+	// screen share it is a grid.
+	ModeScreenShare
+)
+
+// ErrMode is returned by ParseMode for unknown modes
+var ErrMode = errors.New("layout: unknown mode")
+
+// ParseMode parses "grid" or "screenshare", the empty string is grid
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "grid":
+		return ModeGrid, nil
+	case "screenshare", "screen-share", "screen":
+		return ModeScreenShare, nil
+	}
+	return 0, ErrMode
+}
+
+func (m Mode) String() string {
+	if m == ModeScreenShare {
+		return "screenshare"
+	}
+	return "grid"
+}
+
+// Source is a video placed on the canvas
+type Source struct {
+	ID string
+	// Screen is set for screen shares rather than cameras
+	Screen bool
+	// Muted is set while the participant's video is off, when their tile
+	// shows their avatar
+	Muted bool
+}
+
+// Tile is where a source is drawn on the canvas
+type Tile struct {
+	ID   string
+	Rect image.Rectangle
+	// Z orders overlapping tiles, higher is drawn over lower
+	Z      int
+	Border Border
+	// Label is set to draw the participant's name on the tile
+	Label bool
+}
+
+// Placer places sources on a canvas, one of the preset Layouts or a
+// Custom definition
+type Placer interface {
+	// Place returns the tiles of sources on a canvas of width by height,
+	// in the order they are drawn
+	Place(width, height int, sources []Source) []Tile
+}
+
+// Layout arranges sources on a canvas
+type Layout struct {
+	Mode Mode
+	// Strip is the fraction of the canvas width given to thumbnails
+	// beside a screen share, defaults to 0.2
+	Strip float64
+	// Columns and Rows fix the size of the grid, sources beyond its tiles
+	// not shown. With either zero the grid grows with the sources.
+	Columns int
+	Rows    int
+}
+
+// Capacity returns how many sources the grid shows, 0 if it grows with
+// them
+func (l Layout) Capacity() int {
+	if l.Columns <= 0 || l.Rows <= 0 {
+		return 0
+	}
+	return l.Columns * l.Rows
+}
+
+// thumbAspect is the width to height ratio of thumbnails in a strip
+const thumbAspect = 16.0 / 9
+
+// Place returns the tiles of sources on a canvas of width by height, in
+// the order of sources except that a screen share shown large comes first.
+// Tile edges are even, so 4:2:0 chroma stays aligned.
+func (l Layout) Place(width, height int, sources []Source) []Tile {
+	if len(sources) == 0 || width <= 0 || height <= 0 {
+		return nil
+	}
+	if l.Mode == ModeScreenShare {
+		for i, s := range sources {
+			if s.Screen {
+				return l.screenShare(width, height, i, sources)
+			}
+		}
+	}
+	return l.grid(image.Rect(0, 0, width, height), sources)
+}
+
+// screenShare places the screen share sources[main] large, and the other
+// sources in a strip to its right
+func (l Layout) screenShare(width, height, main int, sources []Source) []Tile {
+	if len(sources) == 1 {
+		return []Tile{{ID: sources[0].ID, Rect: image.Rect(0, 0, width, height)}}
+	}
+	strip := l.Strip
+	if strip <= 0 || strip >= 1 {
+		strip = 0.2
+	}
+	sw := even(int(float64(width) * strip))
+	tiles := []Tile{{ID: sources[main].ID, Rect: image.Rect(0, 0, width-sw, height)}}
+
+	n := len(sources) - 1
+	th := even(int(float64(sw) / thumbAspect))
+	if th*n > height {
+		th = even(height / n)
+	}
+	y := even((height - th*n) / 2)
+	for i, s := range sources {
+		if i == main {
+			continue
+		}
+		tiles = append(tiles, Tile{ID: s.ID, Rect: image.Rect(width-sw, y, width, y+th)})
+		y += th
+	}
+	return tiles
+}
+
+// grid places sources in equal tiles, rows filled left to right
+func (l Layout) grid(canvas image.Rectangle, sources []Source) []Tile {
+	n := len(sources)
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	rows := (n + cols - 1) / cols
+	if c := l.Capacity(); c > 0 {
+		cols, rows = l.Columns, l.Rows
+		if n > c {
+			n, sources = c, sources[:c]
+		}
+	}
+	w, h := canvas.Dx(), canvas.Dy()
+	tiles := make([]Tile, 0, n)
+	for i, s := range sources {
+		c, r := i%cols, i/cols
+		x0, x1 := even(w*c/cols), even(w*(c+1)/cols)
+		y0, y1 := even(h*r/rows), even(h*(r+1)/rows)
+		if c == cols-1 {
+			x1 = w
+		}
+		if r == rows-1 {
+			y1 = h
+		}
+		tiles = append(tiles, Tile{ID: s.ID, Rect: image.Rect(x0, y0, x1, y1).Add(canvas.Min)})
+	}
+	return tiles
+}
+
+// even rounds v down to an even number
+func even(v int) int {
+	return v &^ 1
+}
@@ -0,0 +1,66 @@
+package layout
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMode(t *testing.T) {
+	m, err := ParseMode("ScreenShare")
+	assert.NoError(t, err)
+	assert.Equal(t, ModeScreenShare, m)
+	m, err = ParseMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, ModeGrid, m)
+	_, err = ParseMode("mosaic")
+	assert.Equal(t, ErrMode, err)
+}
+
+func TestLayout_Grid(t *testing.T) {
+	tiles := Layout{}.Place(1280, 720, []Source{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+	assert.Equal(t, []Tile{
+		{ID: "a", Rect: image.Rect(0, 0, 640, 360)},
+		{ID: "b", Rect: image.Rect(640, 0, 1280, 360)},
+		{ID: "c", Rect: image.Rect(0, 360, 640, 720)},
+	}, tiles)
+	assert.Nil(t, Layout{}.Place(1280, 720, nil))
+
+	// A fixed grid keeps its tiles' size, showing as many sources as fit
+	fixed := Layout{Columns: 2, Rows: 1}
+	assert.Equal(t, 2, fixed.Capacity())
+	assert.Equal(t, []Tile{{ID: "a", Rect: image.Rect(0, 0, 640, 720)}}, fixed.Place(1280, 720, []Source{{ID: "a"}}))
+	tiles = fixed.Place(1280, 720, []Source{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+	assert.Equal(t, []Tile{
+		{ID: "a", Rect: image.Rect(0, 0, 640, 720)},
+		{ID: "b", Rect: image.Rect(640, 0, 1280, 720)},
+	}, tiles)
+}
+
+func TestLayout_ScreenShare(t *testing.T) {
+	l := Layout{Mode: ModeScreenShare}
+	cameras := []Source{{ID: "alice"}, {ID: "bob"}}
+
+	// Without a screen share, everyone is in a grid
+	tiles := l.Place(1280, 720, cameras)
+	assert.Equal(t, image.Rect(0, 0, 640, 720), tiles[0].Rect)
+
+	// Sharing a screen switches to it large with a strip of thumbnails
+	tiles = l.Place(1280, 720, append(cameras, Source{ID: "screen", Screen: true}))
+	assert.Equal(t, []Tile{
+		{ID: "screen", Rect: image.Rect(0, 0, 1024, 720)},
+		{ID: "alice", Rect: image.Rect(1024, 216, 1280, 360)},
+		{ID: "bob", Rect: image.Rect(1024, 360, 1280, 504)},
+	}, tiles)
+
+	// Thumbnails shrink to fit the strip
+	var many []Source
+	for i := 0; i < 10; i++ {
+		many = append(many, Source{ID: string(rune('a' + i))})
+	}
+	tiles = l.Place(1280, 720, append(many, Source{ID: "screen", Screen: true}))
+	assert.Len(t, tiles, 11)
+	assert.Equal(t, image.Rect(1024, 0, 1280, 72), tiles[1].Rect)
+	assert.Equal(t, 720, tiles[10].Rect.Max.Y)
+}
@@ -0,0 +1,48 @@
+package layout
+
+import (
+	"image"
+	"sync"
+)
+
+// Participant shown on the tiles of their sources
+type Participant struct {
+	// Name is drawn on tiles placed with a label
+	Name string
+	// Avatar is shown in place of video while it is muted, if set
+	Avatar *image.YCbCr
+}
+
+// Roster of the participants of a session by source id, supplied through
+// the control API as the SFU doesn't know display names
+type Roster struct {
+	mu sync.RWMutex
+	m  map[string]Participant
+}
+
+// NewRoster returns an empty roster
+func NewRoster() *Roster {
+	return &Roster{m: make(map[string]Participant)}
+}
+
+// Set the participant of a source
+func (r *Roster) Set(id string, p Participant) {
+	r.mu.Lock()
+	r.m[id] = p
+	r.mu.Unlock()
+}
+
+// Remove the participant of a source
+func (r *Roster) Remove(id string) {
+	r.mu.Lock()
+	delete(r.m, id)
+	r.mu.Unlock()
+}
+
+// Get the participant of a source
+func (r *Roster) Get(id string) (Participant, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.m[id]
+	return p, ok
+}
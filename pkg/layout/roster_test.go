@@ -0,0 +1,22 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoster(t *testing.T) {
+	r := NewRoster()
+	_, ok := r.Get("alice-cam")
+	assert.False(t, ok)
+
+	r.Set("alice-cam", Participant{Name: "Alice"})
+	p, ok := r.Get("alice-cam")
+	assert.True(t, ok)
+	assert.Equal(t, "Alice", p.Name)
+
+	r.Remove("alice-cam")
+	_, ok = r.Get("alice-cam")
+	assert.False(t, ok)
+}
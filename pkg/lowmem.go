@@ -0,0 +1,106 @@
+package avp
+
+import (
+	"sync"
+
+	log "github.com/pion/ion-log"
+)
+
+// LowMemoryConfig is a constrained resources profile for edge devices,
+// such as single board computers recording the cameras next to them.
+// It trades tolerance of late packets and slow elements for memory.
+type LowMemoryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxLate caps the samplebuilder max late of audio and video, defaults to 50
+	MaxLate uint16 `mapstructure:"maxlate"`
+	// BufSize caps file write buffers in bytes, defaults to 4096
+	BufSize int `mapstructure:"bufsize"`
+	// Unbuffered writes samples straight to files, without write buffers
+	Unbuffered bool `mapstructure:"unbuffered"`
+	// Disable lists element ids not registered, e.g. ones decoding video
+	Disable []string `mapstructure:"disable"`
+}
+
+// queueDivisor shrinks element and builder queues
+const queueDivisor = 4
+
+var (
+	lowMemoryMu sync.RWMutex
+	lowMemory   LowMemoryConfig
+)
+
+// SetLowMemory sets the low memory profile. Call it before Init, it
+// applies to elements and tracks created afterwards.
+func SetLowMemory(c LowMemoryConfig) {
+	if c.MaxLate == 0 {
+		c.MaxLate = 50
+	}
+	if c.BufSize <= 0 {
+		c.BufSize = 4096
+	}
+	lowMemoryMu.Lock()
+	defer lowMemoryMu.Unlock()
+	lowMemory = c
+	if c.Enabled {
+		log.Infof("low memory mode enabled")
+	}
+}
+
+func lowMemoryConfig() LowMemoryConfig {
+	lowMemoryMu.RLock()
+	defer lowMemoryMu.RUnlock()
+	return lowMemory
+}
+
+// LowMemory reports whether the low memory profile is enabled
+func LowMemory() bool {
+	return lowMemoryConfig().Enabled
+}
+
+// WriteBufSize returns the write buffer size to use in place of n, which
+// is capped in low memory mode. <=0 means unbuffered.
+func WriteBufSize(n int) int {
+	c := lowMemoryConfig()
+	switch {
+	case !c.Enabled:
+		return n
+	case c.Unbuffered:
+		return 0
+	case n > c.BufSize:
+		return c.BufSize
+	}
+	return n
+}
+
+// maxLate caps a samplebuilder max late in low memory mode
+func maxLate(n uint16) uint16 {
+	if c := lowMemoryConfig(); c.Enabled && n > c.MaxLate {
+		return c.MaxLate
+	}
+	return n
+}
+
+// queueCap shrinks a queue size in low memory mode
+func queueCap(n int) int {
+	if !LowMemory() {
+		return n
+	}
+	if n /= queueDivisor; n < 1 {
+		return 1
+	}
+	return n
+}
+
+// disabled reports whether the element id is disabled in low memory mode
+func disabled(eid string) bool {
+	c := lowMemoryConfig()
+	if !c.Enabled {
+		return false
+	}
+	for _, id := range c.Disable {
+		if id == eid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package avp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowMemory(t *testing.T) {
+	defer SetLowMemory(LowMemoryConfig{})
+
+	assert.False(t, LowMemory())
+	assert.Equal(t, 1<<20, WriteBufSize(1<<20))
+	assert.Equal(t, uint16(200), maxLate(200))
+	assert.Equal(t, 100, queueCap(100))
+
+	SetLowMemory(LowMemoryConfig{Enabled: true, Disable: []string{"decoder"}})
+	assert.True(t, LowMemory())
+	assert.Equal(t, 4096, WriteBufSize(1<<20))
+	assert.Equal(t, 1024, WriteBufSize(1024))
+	assert.Equal(t, uint16(50), maxLate(200))
+	assert.Equal(t, uint16(20), maxLate(20))
+	assert.Equal(t, 25, queueCap(100))
+	assert.Equal(t, 1, queueCap(2))
+
+	q := newElementQueue(WithPriority(&blockingElement{}, PriorityHigh))
+	assert.Equal(t, PriorityHigh.queueSize()/queueDivisor, cap(q.ch))
+
+	Init(map[string]ElementFun{
+		"decoder": func(sid, pid, tid string, config []byte) Element { return nil },
+		"webm":    func(sid, pid, tid string, config []byte) Element { return nil },
+	})
+	assert.Nil(t, registry.GetElement("decoder"))
+	assert.NotNil(t, registry.GetElement("webm"))
+	assert.Equal(t, ErrElementDisabled, CheckElement("decoder"))
+	assert.NoError(t, CheckElement("webm"))
+	assert.Equal(t, ErrElementNotFound, CheckElement("jpeg"))
+
+	SetLowMemory(LowMemoryConfig{Enabled: true, Unbuffered: true})
+	assert.Equal(t, 0, WriteBufSize(4096))
+}
@@ -0,0 +1,282 @@
+// Package manifest describes the files making up one logical recording.
+//
+// A manifest is a JSON document stored next to the recording, by
+// convention as "<recording>/manifest.json". It lists the media segments
+// of each track in order, together with sidecar metadata, thumbnails and
+// transcripts, and the size and sha256 checksum of every file:
+//
+//	{
+//	  "version": 1,
+//	  "id": "rec-1",
+//	  "sid": "session",
+//	  "start": "2021-02-01T10:00:00Z",
+//	  "segments": [
+//	    {"name": "video/0.webm", "size": 1024, "sha256": "...", "track": "video", "seq": 0, "offset": 0, "duration": 10000000000}
+//	  ],
+//	  "sidecars": [{"name": "meta.json", "size": 12, "sha256": "...", "kind": "metadata"}],
+//	  "thumbnails": [{"name": "thumb/0.jpg", "size": 2048, "sha256": "...", "at": 0, "width": 320, "height": 180}],
+//	  "transcripts": [{"name": "en.vtt", "size": 100, "sha256": "...", "language": "en"}]
+//	}
+//
+// File names are relative to the manifest. Offsets and durations are
+// nanoseconds from the start of the recording.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the manifest format version written by this package
+const Version = 1
+
+// FileName is the conventional name of a manifest within a recording
+const FileName = "manifest.json"
+
+var (
+	// ErrVersion is returned when reading a manifest with a newer format
+	ErrVersion = errors.New("manifest: unsupported version")
+	// ErrChecksum is returned when a file does not match its checksum
+	ErrChecksum = errors.New("manifest: checksum mismatch")
+)
+
+// File is a file belonging to a recording
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Segment is a contiguous piece of media of one track
+type Segment struct {
+	File
+	Track    string        `json:"track"`
+	Seq      int           `json:"seq"`
+	Offset   time.Duration `json:"offset"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Sidecar is a metadata file, such as participant or event information
+type Sidecar struct {
+	File
+	Kind string `json:"kind"`
+}
+
+// Thumbnail is a still image taken from the recording
+type Thumbnail struct {
+	File
+	At     time.Duration `json:"at"`
+	Width  int           `json:"width,omitempty"`
+	Height int           `json:"height,omitempty"`
+}
+
+// Transcript is a text track, such as WebVTT captions
+type Transcript struct {
+	File
+	Language string `json:"language,omitempty"`
+}
+
+// Manifest ties together the files of one recording
+type Manifest struct {
+	Version     int          `json:"version"`
+	ID          string       `json:"id"`
+	SessionID   string       `json:"sid,omitempty"`
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	Segments    []Segment    `json:"segments"`
+	Sidecars    []Sidecar    `json:"sidecars,omitempty"`
+	Thumbnails  []Thumbnail  `json:"thumbnails,omitempty"`
+	Transcripts []Transcript `json:"transcripts,omitempty"`
+}
+
+// New creates an empty manifest for a recording
+func New(id, sid string, start time.Time) *Manifest {
+	return &Manifest{
+		Version:   Version,
+		ID:        id,
+		SessionID: sid,
+		Start:     start,
+	}
+}
+
+// NewFile reads r to describe the file name
+func NewFile(name string, r io.Reader) (File, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Name: name, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Read decodes and validates a manifest
+func Read(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	if m.Version < 1 || m.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, m.Version)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Write encodes the manifest
+func (m *Manifest) Write(w io.Writer) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// Validate checks file names are relative and unique, and that each
+// track has at most one segment per sequence number
+func (m *Manifest) Validate() error {
+	names := make(map[string]bool)
+	for _, f := range m.Files() {
+		if f.Name == "" || path.IsAbs(f.Name) || path.Clean(f.Name) != f.Name || strings.HasPrefix(f.Name, "../") {
+			return fmt.Errorf("manifest: invalid file name %q", f.Name)
+		}
+		if names[f.Name] {
+			return fmt.Errorf("manifest: duplicate file %q", f.Name)
+		}
+		names[f.Name] = true
+	}
+	seqs := make(map[string]map[int]bool)
+	for _, s := range m.Segments {
+		if seqs[s.Track] == nil {
+			seqs[s.Track] = make(map[int]bool)
+		}
+		if seqs[s.Track][s.Seq] {
+			return fmt.Errorf("manifest: duplicate segment %d of track %q", s.Seq, s.Track)
+		}
+		seqs[s.Track][s.Seq] = true
+	}
+	return nil
+}
+
+// Files returns every file referenced by the manifest
+func (m *Manifest) Files() []File {
+	var files []File
+	for _, s := range m.Segments {
+		files = append(files, s.File)
+	}
+	for _, s := range m.Sidecars {
+		files = append(files, s.File)
+	}
+	for _, t := range m.Thumbnails {
+		files = append(files, t.File)
+	}
+	for _, t := range m.Transcripts {
+		files = append(files, t.File)
+	}
+	return files
+}
+
+// Tracks returns the ids of tracks with segments, sorted
+func (m *Manifest) Tracks() []string {
+	seen := make(map[string]bool)
+	var tracks []string
+	for _, s := range m.Segments {
+		if !seen[s.Track] {
+			seen[s.Track] = true
+			tracks = append(tracks, s.Track)
+		}
+	}
+	sort.Strings(tracks)
+	return tracks
+}
+
+// TrackSegments returns the segments of a track in sequence order
+func (m *Manifest) TrackSegments(track string) []Segment {
+	var segs []Segment
+	for _, s := range m.Segments {
+		if s.Track == track {
+			segs = append(segs, s)
+		}
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].Seq < segs[j].Seq })
+	return segs
+}
+
+// Duration returns the span covered by segments
+func (m *Manifest) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range m.Segments {
+		if end := s.Offset + s.Duration; end > d {
+			d = end
+		}
+	}
+	return d
+}
+
+// OpenFunc opens a file of the recording by its manifest name
+type OpenFunc func(name string) (io.ReadCloser, error)
+
+// Dir opens recording files below dir on the local filesystem
+func Dir(dir string) OpenFunc {
+	return func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+}
+
+// Verify checks the size and checksum of every file
+func (m *Manifest) Verify(open OpenFunc) error {
+	for _, f := range m.Files() {
+		if err := copyFile(ioutil.Discard, open, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stitch writes the segments of a track to w in order, verifying each
+// segment as it is copied. Segments are concatenated byte for byte, so
+// the track's container must allow it, e.g. MPEG-TS or fragmented MP4
+// following an init segment.
+func (m *Manifest) Stitch(w io.Writer, open OpenFunc, track string) error {
+	segs := m.TrackSegments(track)
+	if len(segs) == 0 {
+		return fmt.Errorf("manifest: no segments for track %q", track)
+	}
+	for _, s := range segs {
+		if err := copyFile(w, open, s.File); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(w io.Writer, open OpenFunc, f File) error {
+	r, err := open(f.Name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, h), r)
+	if err != nil {
+		return err
+	}
+	if n != f.Size || hex.EncodeToString(h.Sum(nil)) != f.SHA256 {
+		return fmt.Errorf("%w: %s", ErrChecksum, f.Name)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifest(t *testing.T) {
+	files := map[string]string{
+		"video/0.webm": "seg0",
+		"video/1.webm": "seg1",
+		"audio/0.webm": "aud0",
+		"meta.json":    "{}",
+		"en.vtt":       "WEBVTT",
+	}
+	open := func(name string) (io.ReadCloser, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(strings.NewReader(data)), nil
+	}
+	file := func(name string) File {
+		f, err := NewFile(name, strings.NewReader(files[name]))
+		assert.NoError(t, err)
+		return f
+	}
+
+	m := New("rec", "sid", time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC))
+	// Out of order on purpose, readers order by sequence
+	m.Segments = []Segment{
+		{File: file("video/1.webm"), Track: "video", Seq: 1, Offset: 10 * time.Second, Duration: 10 * time.Second},
+		{File: file("video/0.webm"), Track: "video", Seq: 0, Duration: 10 * time.Second},
+		{File: file("audio/0.webm"), Track: "audio", Seq: 0, Duration: 15 * time.Second},
+	}
+	m.Sidecars = []Sidecar{{File: file("meta.json"), Kind: "metadata"}}
+	m.Transcripts = []Transcript{{File: file("en.vtt"), Language: "en"}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, m.Write(&buf))
+	m, err := Read(&buf)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"audio", "video"}, m.Tracks())
+	assert.Equal(t, 20*time.Second, m.Duration())
+	assert.Len(t, m.Files(), 5)
+	assert.NoError(t, m.Verify(open))
+
+	var out bytes.Buffer
+	assert.NoError(t, m.Stitch(&out, open, "video"))
+	assert.Equal(t, "seg0seg1", out.String())
+
+	files["video/1.webm"] = "corrupt"
+	err = m.Stitch(ioutil.Discard, open, "video")
+	assert.True(t, errors.Is(err, ErrChecksum))
+}
+
+func TestRead_Invalid(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version": 2}`))
+	assert.True(t, errors.Is(err, ErrVersion))
+
+	_, err = Read(strings.NewReader(`{"version": 1, "segments": [{"name": "../x.webm"}]}`))
+	assert.Error(t, err)
+
+	_, err = Read(strings.NewReader(`{"version": 1, "segments": [{"name": "a", "track": "v"}, {"name": "b", "track": "v"}]}`))
+	assert.Error(t, err)
+}
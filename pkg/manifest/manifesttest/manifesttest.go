@@ -0,0 +1,99 @@
+// Package manifesttest builds recordings in memory, WebM segments and the
+// manifests tying them together, for tests of the packages that read them.
+package manifesttest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/manifest"
+)
+
+// Mic is an Opus track numbered 1
+var Mic = webm.TrackEntry{Name: "mic", TrackNumber: 1, TrackUID: 1, CodecID: "A_OPUS", TrackType: 2}
+
+// Buffer is a bytes.Buffer that can be closed, as WebM writers do
+type Buffer struct {
+	bytes.Buffer
+}
+
+// Close does nothing
+func (*Buffer) Close() error {
+	return nil
+}
+
+// Block is a keyframe of the track numbered Track at Time milliseconds
+type Block struct {
+	Track uint64
+	Time  int64
+	Data  []byte
+}
+
+// Every returns n blocks of the track, every interval milliseconds from
+// 0, with the data of block i
+func Every(track uint64, n int, interval int64, data func(i int) []byte) []Block {
+	blocks := make([]Block, n)
+	for i := range blocks {
+		blocks[i] = Block{Track: track, Time: int64(i) * interval, Data: data(i)}
+	}
+	return blocks
+}
+
+// Segment writes a WebM file of the tracks with the blocks, in order
+func Segment(t testing.TB, tracks []webm.TrackEntry, blocks []Block) []byte {
+	t.Helper()
+	buf := &Buffer{}
+	ws, err := webm.NewSimpleBlockWriter(buf, tracks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writers := make(map[uint64]webm.BlockWriteCloser)
+	for i, track := range tracks {
+		writers[track.TrackNumber] = ws[i]
+	}
+	for _, b := range blocks {
+		if _, err := writers[b.Track].Write(true, b.Time, b.Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, w := range ws {
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Files are the files of a recording by name
+type Files map[string][]byte
+
+// Open opens a file, as a manifest.OpenFunc
+func (f Files) Open(name string) (io.ReadCloser, error) {
+	b, ok := f[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+// Consecutive returns segments of the track in the files called names,
+// each lasting d from the end of the one before
+func Consecutive(track string, d time.Duration, names ...string) []manifest.Segment {
+	segs := make([]manifest.Segment, len(names))
+	for i, name := range names {
+		segs[i] = manifest.Segment{
+			File:     manifest.File{Name: name},
+			Track:    track,
+			Seq:      i,
+			Offset:   time.Duration(i) * d,
+			Duration: d,
+		}
+	}
+	return segs
+}
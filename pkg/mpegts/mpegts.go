@@ -0,0 +1,357 @@
+// Package mpegts muxes H.264 video with AAC or Opus audio into an MPEG-2
+// transport stream (ISO/IEC 13818-1), as broadcast contribution links
+// such as SRT carry.
+//
+// Each access unit and audio frame is a PES packet, timed by its PTS.
+// The stream's clock reference is carried by the video, or by the audio
+// of streams without video, and the program tables are repeated before
+// each keyframe and at least every half second so receivers can join at
+// any time. Opus is carried as a private stream, as its mapping to
+// transport streams specifies.
+package mpegts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/colorspace"
+)
+
+// ErrConfig is returned for streams that can't be muxed
+var ErrConfig = errors.New("mpegts: invalid config")
+
+// PacketSize is the size of a transport stream packet
+const PacketSize = 188
+
+// Codec of an elementary stream
+type Codec int
+
+// Codecs muxed
+const (
+	None Codec = iota
+	H264
+	AAC
+	Opus
+)
+
+// PIDs of the program tables and elementary streams
+const (
+	pidPAT   = 0x0000
+	pidPMT   = 0x1000
+	pidVideo = 0x0100
+	pidAudio = 0x0101
+)
+
+// Stream types and ids of the program map table and PES packets
+const (
+	streamTypeH264    = 0x1b
+	streamTypeAAC     = 0x0f
+	streamTypePrivate = 0x06
+
+	streamIDVideo   = 0xe0
+	streamIDAudio   = 0xc0
+	streamIDPrivate = 0xbd
+)
+
+// ptsDelay is how far presentation times run ahead of the clock
+// reference, for receivers to buffer frames before presenting them
+const ptsDelay = 100 * time.Millisecond
+
+// psiInterval is the longest the program tables go unrepeated
+const psiInterval = 500 * time.Millisecond
+
+// Config of the streams muxed.
+// Video: H264, or None.
+// Audio: AAC, Opus or None.
+// AudioConfig: The AudioSpecificConfig of AAC audio, for its ADTS headers.
+// Channels: Of Opus audio, defaults to 2.
+type Config struct {
+	Video       Codec
+	Audio       Codec
+	AudioConfig []byte
+	Channels    int
+}
+
+// Muxer writes a transport stream
+type Muxer struct {
+	w   io.Writer
+	cfg Config
+	// ADTS header fields of AAC audio
+	profile, frequency, channels byte
+
+	cc       map[uint16]byte // continuity counters of the PIDs
+	psi      bool            // tables written
+	lastPSI  time.Duration
+	sps, pps []byte
+}
+
+// NewMuxer creates a muxer writing to w. Each PES packet and the tables
+// before it are written by one call to w.
+func NewMuxer(w io.Writer, c Config) (*Muxer, error) {
+	if c.Video != None && c.Video != H264 {
+		return nil, fmt.Errorf("%w: video must be H.264", ErrConfig)
+	}
+	m := &Muxer{w: w, cfg: c, cc: map[uint16]byte{}}
+	switch c.Audio {
+	case None:
+		if c.Video == None {
+			return nil, fmt.Errorf("%w: no streams", ErrConfig)
+		}
+	case AAC:
+		if len(c.AudioConfig) < 2 {
+			return nil, fmt.Errorf("%w: AAC needs an AudioSpecificConfig", ErrConfig)
+		}
+		object := c.AudioConfig[0] >> 3
+		m.frequency = (c.AudioConfig[0]&7)<<1 | c.AudioConfig[1]>>7
+		m.channels = (c.AudioConfig[1] >> 3) & 0xf
+		if object < 1 || object > 4 || m.frequency > 12 || m.channels == 0 || m.channels > 7 {
+			return nil, fmt.Errorf("%w: AAC can't be carried in ADTS", ErrConfig)
+		}
+		m.profile = object - 1
+	case Opus:
+		if m.cfg.Channels == 0 {
+			m.cfg.Channels = 2
+		}
+		if m.cfg.Channels > 2 {
+			return nil, fmt.Errorf("%w: Opus must be mono or stereo", ErrConfig)
+		}
+	default:
+		return nil, fmt.Errorf("%w: audio must be AAC or Opus", ErrConfig)
+	}
+	return m, nil
+}
+
+// WriteVideo writes an H.264 access unit in Annex B format, presented at
+// pts from the start of the stream. Access unit delimiters are added, and
+// the last parameter sets are repeated before keyframes without them.
+func (m *Muxer) WriteVideo(pts time.Duration, au []byte) error {
+	if m.cfg.Video != H264 {
+		return fmt.Errorf("%w: no video stream", ErrConfig)
+	}
+	var nals [][]byte
+	key, params := false, false
+	for _, nal := range colorspace.SplitAnnexB(au) {
+		if len(nal) == 0 {
+			continue
+		}
+		switch nal[0] & 0x1f {
+		case 5:
+			key = true
+		case 7:
+			m.sps, params = nal, true
+		case 8:
+			m.pps = nal
+		case 9:
+			continue
+		}
+		nals = append(nals, nal)
+	}
+	if len(nals) == 0 {
+		return nil
+	}
+	if key && !params && m.sps != nil && m.pps != nil {
+		nals = append([][]byte{m.sps, m.pps}, nals...)
+	}
+	es := []byte{0, 0, 0, 1, 9, 0xf0}
+	for _, nal := range nals {
+		es = append(es, 0, 0, 0, 1)
+		es = append(es, nal...)
+	}
+
+	var b []byte
+	if key || m.due(pts) {
+		b = m.tables(pts)
+	}
+	b = append(b, m.pes(pidVideo, streamIDVideo, pts, es, true, key)...)
+	_, err := m.w.Write(b)
+	return err
+}
+
+// WriteAudio writes a raw AAC frame or an Opus packet, presented at pts
+// from the start of the stream
+func (m *Muxer) WriteAudio(pts time.Duration, frame []byte) error {
+	var es []byte
+	id := byte(streamIDAudio)
+	switch m.cfg.Audio {
+	case AAC:
+		n := len(frame) + 7
+		es = append([]byte{
+			0xff, 0xf1, // sync, MPEG-4, no CRC
+			m.profile<<6 | m.frequency<<2 | m.channels>>2,
+			(m.channels&3)<<6 | byte(n>>11),
+			byte(n >> 3),
+			byte(n&7)<<5 | 0x1f, // buffer fullness, variable rate
+			0xfc,
+		}, frame...)
+	case Opus:
+		// The control header, then the size of the packet in 255s
+		id = streamIDPrivate
+		es = []byte{0x7f, 0xe0}
+		n := len(frame)
+		for ; n >= 255; n -= 255 {
+			es = append(es, 0xff)
+		}
+		es = append(es, byte(n))
+		es = append(es, frame...)
+	default:
+		return fmt.Errorf("%w: no audio stream", ErrConfig)
+	}
+
+	var b []byte
+	if m.cfg.Video == None && m.due(pts) {
+		b = m.tables(pts)
+	}
+	b = append(b, m.pes(pidAudio, id, pts, es, m.cfg.Video == None, false)...)
+	_, err := m.w.Write(b)
+	return err
+}
+
+// due reports whether the tables should be repeated
+func (m *Muxer) due(pts time.Duration) bool {
+	return !m.psi || pts-m.lastPSI >= psiInterval || pts < m.lastPSI
+}
+
+// tables returns the packets of the program association and map tables
+func (m *Muxer) tables(pts time.Duration) []byte {
+	m.psi, m.lastPSI = true, pts
+
+	pat := []byte{
+		0, 1, // program number
+		0xe0 | pidPMT>>8, pidPMT & 0xff,
+	}
+	pcr := pidVideo
+	if m.cfg.Video == None {
+		pcr = pidAudio
+	}
+	pmt := []byte{0xe0 | byte(pcr>>8), byte(pcr), 0xf0, 0}
+	if m.cfg.Video == H264 {
+		pmt = append(pmt, streamTypeH264, 0xe0|pidVideo>>8, pidVideo&0xff, 0xf0, 0)
+	}
+	switch m.cfg.Audio {
+	case AAC:
+		pmt = append(pmt, streamTypeAAC, 0xe0|pidAudio>>8, pidAudio&0xff, 0xf0, 0)
+	case Opus:
+		pmt = append(pmt, streamTypePrivate, 0xe0|pidAudio>>8, pidAudio&0xff, 0xf0, 10,
+			0x05, 4, 'O', 'p', 'u', 's', // registration
+			0x7f, 2, 0x80, byte(m.cfg.Channels), // Opus extension, channel config
+		)
+	}
+	b := m.packets(pidPAT, psi(0x00, 1, pat), -1, false)
+	return append(b, m.packets(pidPMT, psi(0x02, 1, pmt), -1, false)...)
+}
+
+// psi returns the payload of a table section, with its pointer field
+func psi(table byte, id uint16, data []byte) []byte {
+	n := 5 + len(data) + 4 // after the length, to the CRC
+	s := []byte{
+		0, // pointer
+		table, 0xb0 | byte(n>>8), byte(n),
+		byte(id >> 8), byte(id),
+		0xc1, // version 0, current
+		0, 0, // section, last section
+	}
+	s = append(s, data...)
+	crc := crc32(s[1:])
+	return append(s, byte(crc>>24), byte(crc>>16), byte(crc>>8), byte(crc))
+}
+
+// pes returns the packets of a PES packet, with the clock reference if
+// the PID carries it
+func (m *Muxer) pes(pid uint16, id byte, pts time.Duration, es []byte, clock, key bool) []byte {
+	ticks := uint64(pts+ptsDelay) * 90000 / uint64(time.Second) & (1<<33 - 1)
+	h := []byte{0, 0, 1, id, 0, 0, 0x84, 0x80, 5,
+		0x21 | byte(ticks>>29)&0x0e,
+		byte(ticks >> 22),
+		byte(ticks>>14) | 1,
+		byte(ticks >> 7),
+		byte(ticks<<1) | 1,
+	}
+	// Video's length is left unset, as it may not fit
+	if n := len(es) + 8; id != streamIDVideo && n <= 0xffff {
+		h[4], h[5] = byte(n>>8), byte(n)
+	}
+	pcr := int64(-1)
+	if clock {
+		pcr = int64(uint64(pts) * 90000 / uint64(time.Second) & (1<<33 - 1))
+	}
+	return m.packets(pid, append(h, es...), pcr, key)
+}
+
+// packets splits a payload into packets of a PID, the first carrying the
+// clock reference if pcr isn't negative, and the last stuffed to fill it
+func (m *Muxer) packets(pid uint16, payload []byte, pcr int64, random bool) []byte {
+	var b []byte
+	for first := true; first || len(payload) > 0; first = false {
+		var af []byte
+		if first && (pcr >= 0 || random) {
+			af = []byte{1, 0}
+			if random {
+				af[1] |= 0x40
+			}
+			if pcr >= 0 {
+				af[1] |= 0x10
+				af = append(af, byte(pcr>>25), byte(pcr>>17), byte(pcr>>9), byte(pcr>>1), byte(pcr<<7)|0x7e, 0)
+			}
+			af[0] = byte(len(af) - 1)
+		}
+		n := PacketSize - 4 - len(af)
+		if len(payload) < n {
+			stuffing := n - len(payload)
+			switch {
+			case af != nil:
+				af[0] += byte(stuffing)
+			case stuffing == 1:
+				af = []byte{0}
+				stuffing = 0
+			default:
+				af = []byte{byte(stuffing - 1), 0}
+				stuffing -= 2
+			}
+			for i := 0; i < stuffing; i++ {
+				af = append(af, 0xff)
+			}
+			n = len(payload)
+		}
+
+		control := byte(0x10)
+		if af != nil {
+			control = 0x30
+		}
+		start := byte(0)
+		if first {
+			start = 0x40
+		}
+		b = append(b, 0x47, start|byte(pid>>8), byte(pid), control|m.cc[pid])
+		m.cc[pid] = (m.cc[pid] + 1) & 0xf
+		b = append(b, af...)
+		b = append(b, payload[:n]...)
+		payload = payload[n:]
+	}
+	return b
+}
+
+var crcTable = func() (t [256]uint32) {
+	for i := range t {
+		c := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if c&0x80000000 != 0 {
+				c = c<<1 ^ 0x04c11db7
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return t
+}()
+
+// crc32 is the MPEG-2 CRC of table sections
+func crc32(b []byte) uint32 {
+	c := uint32(0xffffffff)
+	for _, v := range b {
+		c = c<<8 ^ crcTable[byte(c>>24)^v]
+	}
+	return c
+}
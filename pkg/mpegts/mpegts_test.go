@@ -0,0 +1,161 @@
+package mpegts
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tsPacket is a parsed transport stream packet
+type tsPacket struct {
+	pid     uint16
+	start   bool
+	cc      byte
+	pcr     int64
+	random  bool
+	payload []byte
+}
+
+func parse(t *testing.T, b []byte) []tsPacket {
+	require.Equal(t, 0, len(b)%PacketSize)
+	var packets []tsPacket
+	for ; len(b) > 0; b = b[PacketSize:] {
+		p := b[:PacketSize]
+		require.Equal(t, byte(0x47), p[0])
+		pkt := tsPacket{
+			pid:   uint16(p[1]&0x1f)<<8 | uint16(p[2]),
+			start: p[1]&0x40 != 0,
+			cc:    p[3] & 0xf,
+			pcr:   -1,
+		}
+		payload := p[4:]
+		if p[3]&0x20 != 0 {
+			n := int(payload[0])
+			if n > 0 {
+				pkt.random = payload[1]&0x40 != 0
+				if payload[1]&0x10 != 0 {
+					pkt.pcr = int64(payload[2])<<25 | int64(payload[3])<<17 | int64(payload[4])<<9 | int64(payload[5])<<1 | int64(payload[6]>>7)
+				}
+			}
+			payload = payload[1+n:]
+		}
+		pkt.payload = payload
+		packets = append(packets, pkt)
+	}
+	return packets
+}
+
+// pesPayload joins the payloads of a PID's packets, returning the PTS and
+// data of the PES packet
+func pesPayload(packets []tsPacket, pid uint16) (int64, []byte) {
+	var b []byte
+	for _, p := range packets {
+		if p.pid == pid {
+			b = append(b, p.payload...)
+		}
+	}
+	if len(b) < 14 {
+		return -1, nil
+	}
+	pts := int64(b[9]&0x0e)<<29 | int64(b[10])<<22 | int64(b[11]>>1)<<15 | int64(b[12])<<7 | int64(b[13]>>1)
+	return pts, b[9+int(b[8]):]
+}
+
+func TestMuxer(t *testing.T) {
+	var b bytes.Buffer
+	m, err := NewMuxer(&b, Config{Video: H264, Audio: AAC, AudioConfig: []byte{0x11, 0x90}})
+	require.NoError(t, err)
+
+	sps, pps := []byte{0x67, 0x42, 0xc0, 0x1e}, []byte{0x68, 0xce}
+	idr := append([]byte{0x65}, bytes.Repeat([]byte{0xaa}, 500)...)
+	au := bytes.Join([][]byte{nil, sps, pps, idr}, []byte{0, 0, 0, 1})
+	require.NoError(t, m.WriteVideo(time.Second, au))
+
+	packets := parse(t, b.Bytes())
+	assert.Equal(t, uint16(pidPAT), packets[0].pid)
+	assert.Equal(t, uint16(pidPMT), packets[1].pid)
+	for _, p := range packets[:2] {
+		// The CRC of a section with its CRC is zero
+		n := int(p.payload[2]&0xf)<<8 | int(p.payload[3])
+		assert.Equal(t, uint32(0), crc32(p.payload[1:4+n]))
+	}
+	pmt := packets[1].payload
+	assert.Equal(t, []byte{streamTypeH264, 0xe1, 0x00, 0xf0, 0, streamTypeAAC, 0xe1, 0x01, 0xf0, 0}, pmt[13:23])
+
+	video := packets[2:]
+	assert.Equal(t, 3, len(video))
+	assert.True(t, video[0].start)
+	assert.True(t, video[0].random)
+	assert.Equal(t, int64(90000), video[0].pcr)
+	for i, p := range video {
+		assert.Equal(t, byte(i), p.cc)
+	}
+	pts, es := pesPayload(packets, pidVideo)
+	assert.Equal(t, int64(99000), pts)
+	assert.Equal(t, append([]byte{0, 0, 0, 1, 9, 0xf0}, au...), es)
+
+	// Parameter sets are repeated before keyframes without them, along
+	// with the tables
+	b.Reset()
+	require.NoError(t, m.WriteVideo(2*time.Second, append([]byte{0, 0, 0, 1}, idr...)))
+	packets = parse(t, b.Bytes())
+	assert.Equal(t, uint16(pidPAT), packets[0].pid)
+	assert.Equal(t, byte(1), packets[0].cc)
+	_, es = pesPayload(packets, pidVideo)
+	assert.Equal(t, append([]byte{0, 0, 0, 1, 9, 0xf0}, au...), es)
+
+	b.Reset()
+	require.NoError(t, m.WriteAudio(2*time.Second, []byte{1, 2, 3}))
+	packets = parse(t, b.Bytes())
+	assert.Equal(t, 1, len(packets))
+	assert.Equal(t, int64(-1), packets[0].pcr)
+	pts, es = pesPayload(packets, pidAudio)
+	assert.Equal(t, int64(189000), pts)
+	assert.Equal(t, []byte{0xff, 0xf1, 0x4c, 0x80, 0x01, 0x5f, 0xfc, 1, 2, 3}, es)
+}
+
+func TestMuxer_Opus(t *testing.T) {
+	var b bytes.Buffer
+	m, err := NewMuxer(&b, Config{Audio: Opus})
+	require.NoError(t, err)
+	frame := bytes.Repeat([]byte{7}, 300)
+	require.NoError(t, m.WriteAudio(0, frame))
+
+	packets := parse(t, b.Bytes())
+	pmt := packets[1].payload
+	// The audio carries the clock without video
+	assert.Equal(t, []byte{0xe1, 0x01}, pmt[9:11])
+	assert.Equal(t, []byte{streamTypePrivate, 0xe1, 0x01, 0xf0, 10, 0x05, 4, 'O', 'p', 'u', 's', 0x7f, 2, 0x80, 2}, pmt[13:28])
+	assert.Equal(t, int64(0), packets[2].pcr)
+	_, es := pesPayload(packets, pidAudio)
+	assert.Equal(t, append([]byte{0x7f, 0xe0, 0xff, 45}, frame...), es)
+
+	// Tables are repeated without keyframes
+	b.Reset()
+	require.NoError(t, m.WriteAudio(200*time.Millisecond, frame))
+	assert.Equal(t, uint16(pidAudio), parse(t, b.Bytes())[0].pid)
+	b.Reset()
+	require.NoError(t, m.WriteAudio(500*time.Millisecond, frame))
+	assert.Equal(t, uint16(pidPAT), parse(t, b.Bytes())[0].pid)
+}
+
+func TestNewMuxer(t *testing.T) {
+	for _, c := range []Config{
+		{},
+		{Video: AAC},
+		{Audio: H264},
+		{Audio: AAC},
+		{Audio: AAC, AudioConfig: []byte{0xf8, 0}},
+		{Audio: Opus, Channels: 6},
+	} {
+		_, err := NewMuxer(nil, c)
+		assert.True(t, errors.Is(err, ErrConfig))
+	}
+	m, err := NewMuxer(nil, Config{Video: H264})
+	require.NoError(t, err)
+	assert.True(t, errors.Is(m.WriteAudio(0, nil), ErrConfig))
+}
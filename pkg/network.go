@@ -0,0 +1,82 @@
+package avp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	log "github.com/pion/ion-log"
+	"github.com/pion/webrtc/v3"
+)
+
+// Family selects the IP versions used by listeners, outgoing connections
+// and WebRTC candidates. Dual stack is the default; nodes on single stack
+// networks can restrict to the family they have so nothing waits on the
+// other.
+type Family string
+
+// IP families
+const (
+	FamilyDual Family = "dual"
+	FamilyIPv4 Family = "ipv4"
+	FamilyIPv6 Family = "ipv6"
+)
+
+// ParseFamily parses a configured family, empty meaning dual stack
+func ParseFamily(s string) (Family, error) {
+	switch f := Family(strings.ToLower(s)); f {
+	case "", FamilyDual:
+		return FamilyDual, nil
+	case FamilyIPv4, FamilyIPv6:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown ip family %q, want dual, ipv4 or ipv6", s)
+}
+
+// Family returns the configured IP family, dual stack if it is invalid
+func (c Config) Family() Family {
+	f, err := ParseFamily(c.Network.Family)
+	if err != nil {
+		log.Warnf("%v, using dual stack", err)
+		return FamilyDual
+	}
+	return f
+}
+
+// network returns the network name for proto, "tcp" or "udp", restricted
+// to the family
+func (f Family) network(proto string) string {
+	switch f {
+	case FamilyIPv4:
+		return proto + "4"
+	case FamilyIPv6:
+		return proto + "6"
+	}
+	return proto
+}
+
+// Listen listens for tcp connections on addr. An unspecified host, such as
+// in ":50052", listens on all addresses of the family.
+func (f Family) Listen(addr string) (net.Listener, error) {
+	return net.Listen(f.network("tcp"), addr)
+}
+
+// Dialer dials tcp connections restricted to the family, e.g. for
+// grpc.WithContextDialer. Dual stack dials race IPv6 and IPv4 addresses.
+func (f Family) Dialer() func(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return d.DialContext(ctx, f.network("tcp"), addr)
+	}
+}
+
+func (f Family) networkTypes() []webrtc.NetworkType {
+	switch f {
+	case FamilyIPv4:
+		return []webrtc.NetworkType{webrtc.NetworkTypeUDP4}
+	case FamilyIPv6:
+		return []webrtc.NetworkType{webrtc.NetworkTypeUDP6}
+	}
+	return []webrtc.NetworkType{webrtc.NetworkTypeUDP4, webrtc.NetworkTypeUDP6}
+}
@@ -0,0 +1,57 @@
+package avp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFamily(t *testing.T) {
+	for in, want := range map[string]Family{
+		"":     FamilyDual,
+		"dual": FamilyDual,
+		"IPv4": FamilyIPv4,
+		"ipv6": FamilyIPv6,
+	} {
+		f, err := ParseFamily(in)
+		assert.NoError(t, err)
+		assert.Equal(t, want, f)
+	}
+	_, err := ParseFamily("ipx")
+	assert.Error(t, err)
+
+	assert.Equal(t, FamilyDual, Config{Network: networkconf{Family: "ipx"}}.Family())
+	assert.Equal(t, []webrtc.NetworkType{webrtc.NetworkTypeUDP6}, FamilyIPv6.networkTypes())
+	assert.Len(t, FamilyDual.networkTypes(), 2)
+}
+
+func TestFamily_Listen(t *testing.T) {
+	l, err := FamilyIPv4.Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+	c, err := FamilyDual.Dialer()(context.Background(), l.Addr().String())
+	assert.NoError(t, err)
+	c.Close()
+
+	_, err = FamilyIPv6.Dialer()(context.Background(), l.Addr().String())
+	assert.Error(t, err)
+	_, err = FamilyIPv4.Listen("[::1]:0")
+	assert.Error(t, err)
+
+	// Only where the host has IPv6
+	if l6, err := net.Listen("tcp6", "[::1]:0"); err == nil {
+		l6.Close()
+		l6, err = FamilyIPv6.Listen("[::1]:0")
+		assert.NoError(t, err)
+		l6.Close()
+	}
+}
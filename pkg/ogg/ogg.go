@@ -0,0 +1,127 @@
+// Package ogg writes Ogg streams of Opus packets, .opus files, which
+// most audio tools and speech pipelines read without a demuxer for
+// WebM or MP4.
+package ogg
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Header types of pages
+const (
+	Continued = 0x01 // the page continues a packet of the last
+	First     = 0x02 // beginning of the stream
+	Last      = 0x04 // end of the stream
+)
+
+// maxSegments of a page, each of up to 255 bytes of its packets
+const maxSegments = 255
+
+// ErrPacketSize is returned for packets too large for a page
+var ErrPacketSize = errors.New("ogg: packet too large")
+
+// Writer writes the pages of a logical stream
+type Writer struct {
+	w      io.Writer
+	serial uint32
+	seq    uint32
+}
+
+// NewWriter returns a writer of pages of the stream serial to w
+func NewWriter(w io.Writer, serial uint32) *Writer {
+	return &Writer{w: w, serial: serial}
+}
+
+// WritePage writes packets as one page of header type typ, a combination
+// of First and Last. granule is the position of the stream at the end of
+// the last packet, for Opus the number of 48kHz samples.
+func (w *Writer) WritePage(typ byte, granule uint64, packets ...[]byte) error {
+	var lacing []byte
+	size := 0
+	for _, p := range packets {
+		// A packet's segments are 255 bytes but for the last, which is
+		// shorter, empty for packets of a multiple of 255
+		n := len(p)/255 + 1
+		if len(lacing)+n > maxSegments {
+			return ErrPacketSize
+		}
+		for i := 0; i < n-1; i++ {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(len(p)%255))
+		size += len(p)
+	}
+
+	page := make([]byte, 27, 27+len(lacing)+size)
+	copy(page, "OggS")
+	page[5] = typ
+	binary.LittleEndian.PutUint64(page[6:], granule)
+	binary.LittleEndian.PutUint32(page[14:], w.serial)
+	binary.LittleEndian.PutUint32(page[18:], w.seq)
+	page[26] = byte(len(lacing))
+	page = append(page, lacing...)
+	for _, p := range packets {
+		page = append(page, p...)
+	}
+	binary.LittleEndian.PutUint32(page[22:], checksum(page))
+	w.seq++
+	_, err := w.w.Write(page)
+	return err
+}
+
+// OpusHead returns the identification header of an Opus stream of
+// channels, whose decoders discard the first preSkip samples
+func OpusHead(channels int, preSkip uint16) []byte {
+	b := make([]byte, 19)
+	copy(b, "OpusHead")
+	b[8] = 1 // version
+	b[9] = byte(channels)
+	binary.LittleEndian.PutUint16(b[10:], preSkip)
+	binary.LittleEndian.PutUint32(b[12:], 48000) // of the input, informational
+	// Output gain and channel mapping family 0, mono or stereo, are zero
+	return b
+}
+
+// OpusTags returns the comment header of an Opus stream, naming the
+// vendor, with comments of the form "NAME=value" such as "TITLE=Episode 1"
+func OpusTags(vendor string, comments ...string) []byte {
+	b := make([]byte, 8+4+len(vendor)+4, 64)
+	copy(b, "OpusTags")
+	binary.LittleEndian.PutUint32(b[8:], uint32(len(vendor)))
+	copy(b[12:], vendor)
+	binary.LittleEndian.PutUint32(b[12+len(vendor):], uint32(len(comments)))
+	for _, c := range comments {
+		var n [4]byte
+		binary.LittleEndian.PutUint32(n[:], uint32(len(c)))
+		b = append(b, n[:]...)
+		b = append(b, c...)
+	}
+	return b
+}
+
+var crcTable = func() (t [256]uint32) {
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return
+}()
+
+// checksum of a page with its checksum field zero, a CRC-32 without the
+// reflection or final XOR of the usual one
+func checksum(page []byte) uint32 {
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^b]
+	}
+	return crc
+}
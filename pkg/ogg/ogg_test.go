@@ -0,0 +1,53 @@
+package ogg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePage(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, 1234)
+	assert.NoError(t, w.WritePage(First, 0, OpusHead(2, 0)))
+
+	page := buf.Bytes()
+	assert.Equal(t, "OggS", string(page[:4]))
+	assert.Equal(t, byte(First), page[5])
+	assert.Equal(t, uint32(1234), binary.LittleEndian.Uint32(page[14:]))
+	assert.Equal(t, uint32(0), binary.LittleEndian.Uint32(page[18:]))
+	assert.Equal(t, uint32(0x201e57bf), binary.LittleEndian.Uint32(page[22:]))
+	assert.Equal(t, []byte{1, 19}, page[26:28])
+	assert.Equal(t, "OpusHead", string(page[28:36]))
+	assert.Equal(t, byte(2), page[37])
+
+	// Packets of a multiple of 255 bytes end with an empty segment
+	buf.Reset()
+	assert.NoError(t, w.WritePage(Last, 960, make([]byte, 255), []byte{1}))
+	page = buf.Bytes()
+	assert.Equal(t, uint64(960), binary.LittleEndian.Uint64(page[6:]))
+	assert.Equal(t, uint32(1), binary.LittleEndian.Uint32(page[18:]))
+	assert.Equal(t, []byte{3, 255, 0, 1}, page[26:30])
+	assert.Len(t, page, 30+256)
+
+	assert.Equal(t, ErrPacketSize, w.WritePage(0, 0, make([]byte, 255*255)))
+}
+
+func TestOpusTags(t *testing.T) {
+	b := OpusTags("ion-avp")
+	assert.Equal(t, "OpusTags", string(b[:8]))
+	assert.Equal(t, uint32(7), binary.LittleEndian.Uint32(b[8:]))
+	assert.Equal(t, "ion-avp", string(b[12:19]))
+	assert.Equal(t, uint32(0), binary.LittleEndian.Uint32(b[19:]))
+}
+
+func TestOpusTagsComments(t *testing.T) {
+	b := OpusTags("ion-avp", "TITLE=rec", "ARTIST=")
+	assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(b[19:]))
+	assert.Equal(t, uint32(9), binary.LittleEndian.Uint32(b[23:]))
+	assert.Equal(t, "TITLE=rec", string(b[27:36]))
+	assert.Equal(t, uint32(7), binary.LittleEndian.Uint32(b[36:]))
+	assert.Equal(t, "ARTIST=", string(b[40:]))
+}
@@ -0,0 +1,81 @@
+package avp
+
+import (
+	"sync"
+
+	log "github.com/pion/ion-log"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+)
+
+// DoNotRecordURI identifies an RTP header extension senders set on tracks
+// that mustn't be recorded: one byte, 1 while the track is opted out and
+// 0 once it may be recorded again
+const DoNotRecordURI = "urn:ion-avp:rtp-hdrext:do-not-record"
+
+// optOut is whether a track is opted out of recording, by the application
+// over the control API or by the sender in the header extension. While it
+// is, the track's samples reach no element, so no pipeline can persist
+// them whatever it was configured to do.
+type optOut struct {
+	mu     sync.Mutex
+	id     string // of the track, for logs
+	ext    uint8
+	signal bool // set by the application
+	header bool // set by the last packet carrying the extension
+	on     atomicBool
+}
+
+func newOptOut(id string) *optOut {
+	return &optOut{id: id}
+}
+
+// negotiated sets the do-not-record extension id from the receiver's
+// negotiated header extensions
+func (o *optOut) negotiated(params webrtc.RTPParameters) {
+	for _, e := range params.HeaderExtensions {
+		if e.URI == DoNotRecordURI {
+			o.mu.Lock()
+			o.ext = uint8(e.ID)
+			o.mu.Unlock()
+		}
+	}
+}
+
+func (o *optOut) packet(p *rtp.Packet) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.ext == 0 {
+		return
+	}
+	if b := p.GetExtension(o.ext); len(b) > 0 {
+		o.header = b[0] != 0
+		o.update()
+	}
+}
+
+// set opts the track out, or back in, for the application
+func (o *optOut) set(v bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.signal = v
+	o.update()
+}
+
+func (o *optOut) update() {
+	v := o.signal || o.header
+	if v == o.on.get() {
+		return
+	}
+	o.on.set(v)
+	if v {
+		log.Infof("track %s opted out of recording, holding back its samples", o.id)
+	} else {
+		log.Infof("track %s may be recorded again", o.id)
+	}
+}
+
+// active reports whether the track is opted out
+func (o *optOut) active() bool {
+	return o.on.get()
+}
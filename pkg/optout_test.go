@@ -0,0 +1,37 @@
+package avp
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptOut(t *testing.T) {
+	o := newOptOut("tid")
+	p := &rtp.Packet{}
+	assert.NoError(t, p.Header.SetExtension(5, []byte{1}))
+
+	// Not negotiated, the extension isn't read
+	o.packet(p)
+	assert.False(t, o.active())
+
+	o.negotiated(webrtc.RTPParameters{HeaderExtensions: []webrtc.RTPHeaderExtensionParameter{
+		{URI: AbsCaptureTimeURI, ID: 3},
+		{URI: DoNotRecordURI, ID: 5},
+	}})
+	o.packet(p)
+	assert.True(t, o.active())
+	// Packets without it leave it as it was
+	o.packet(&rtp.Packet{})
+	assert.True(t, o.active())
+
+	// Either the sender or the application keeps it opted out
+	o.set(true)
+	assert.NoError(t, p.Header.SetExtension(5, []byte{0}))
+	o.packet(p)
+	assert.True(t, o.active())
+	o.set(false)
+	assert.False(t, o.active())
+}
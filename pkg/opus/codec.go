@@ -0,0 +1,111 @@
+//go:build libopus
+// +build libopus
+
+package opus
+
+/*
+#cgo pkg-config: opus
+#include <opus.h>
+
+static int opus_set_bitrate(OpusEncoder *e, opus_int32 bitrate) {
+	return opus_encoder_ctl(e, OPUS_SET_BITRATE(bitrate));
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// maxPacket is the largest Opus packet encoded
+const maxPacket = 4000
+
+var errShortBuffer = errors.New("opus: buffer too short")
+
+func opusError(code C.int) error {
+	return fmt.Errorf("opus: %s", C.GoString(C.opus_strerror(code)))
+}
+
+// Decoder decodes Opus packets to interleaved 16 bit PCM
+type Decoder struct {
+	d        *C.OpusDecoder
+	channels int
+}
+
+// NewDecoder creates a decoder at rate with 1 or 2 channels
+func NewDecoder(rate, channels int) (*Decoder, error) {
+	var code C.int
+	d := C.opus_decoder_create(C.opus_int32(rate), C.int(channels), &code)
+	if code != C.OPUS_OK {
+		return nil, opusError(code)
+	}
+	return &Decoder{d: d, channels: channels}, nil
+}
+
+// Decode decodes packet into pcm, returning the number of samples per
+// channel. An empty packet conceals a lost one. pcm should hold MaxFrame
+// samples per channel.
+func (d *Decoder) Decode(packet []byte, pcm []int16) (int, error) {
+	if len(pcm) < d.channels {
+		return 0, errShortBuffer
+	}
+	var data *C.uchar
+	if len(packet) > 0 {
+		data = (*C.uchar)(unsafe.Pointer(&packet[0]))
+	}
+	n := C.opus_decode(d.d, data, C.opus_int32(len(packet)),
+		(*C.opus_int16)(unsafe.Pointer(&pcm[0])), C.int(len(pcm)/d.channels), 0)
+	if n < 0 {
+		return 0, opusError(n)
+	}
+	return int(n), nil
+}
+
+// Close frees the decoder
+func (d *Decoder) Close() {
+	C.opus_decoder_destroy(d.d)
+}
+
+// Encoder encodes interleaved 16 bit PCM to Opus packets
+type Encoder struct {
+	e        *C.OpusEncoder
+	channels int
+	buf      []byte
+}
+
+// NewEncoder creates an encoder for general audio at rate with 1 or 2
+// channels. A bitrate of 0 leaves libopus to choose.
+func NewEncoder(rate, channels, bitrate int) (*Encoder, error) {
+	var code C.int
+	e := C.opus_encoder_create(C.opus_int32(rate), C.int(channels), C.OPUS_APPLICATION_AUDIO, &code)
+	if code != C.OPUS_OK {
+		return nil, opusError(code)
+	}
+	if bitrate > 0 {
+		if code = C.opus_set_bitrate(e, C.opus_int32(bitrate)); code != C.OPUS_OK {
+			C.opus_encoder_destroy(e)
+			return nil, opusError(code)
+		}
+	}
+	return &Encoder{e: e, channels: channels, buf: make([]byte, maxPacket)}, nil
+}
+
+// Encode encodes one frame of pcm, of 2.5 to 120ms, into a new packet
+func (e *Encoder) Encode(pcm []int16) ([]byte, error) {
+	if len(pcm) < e.channels {
+		return nil, errShortBuffer
+	}
+	n := C.opus_encode(e.e, (*C.opus_int16)(unsafe.Pointer(&pcm[0])), C.int(len(pcm)/e.channels),
+		(*C.uchar)(unsafe.Pointer(&e.buf[0])), C.opus_int32(len(e.buf)))
+	if n < 0 {
+		return nil, opusError(n)
+	}
+	return append([]byte(nil), e.buf[:n]...), nil
+}
+
+// Close frees the encoder
+func (e *Encoder) Close() {
+	C.opus_encoder_destroy(e.e)
+}
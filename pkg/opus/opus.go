@@ -0,0 +1,50 @@
+// Package opus binds libopus to decode and encode Opus audio, for
+// elements that process PCM. Like the VP8 decoder, the codec is only
+// built with a build tag, libopus, as it needs the C library.
+package opus
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidPacket is returned for packets too short for their table of
+// contents
+var ErrInvalidPacket = errors.New("opus: invalid packet")
+
+// SampleRate is the rate WebRTC Opus streams are coded at
+const SampleRate = 48000
+
+// MaxFrame is the most samples per channel one Opus packet decodes to,
+// 120ms at 48kHz
+const MaxFrame = 5760
+
+// frameSizes are the frame durations of each configuration, in tenths of
+// a millisecond (RFC 6716 section 3.1)
+var frameSizes = [32]time.Duration{
+	// SILK narrowband, mediumband and wideband
+	100, 200, 400, 600, 100, 200, 400, 600, 100, 200, 400, 600,
+	// Hybrid super-wideband and fullband
+	100, 200, 100, 200,
+	// CELT narrowband, wideband, super-wideband and fullband
+	25, 50, 100, 200, 25, 50, 100, 200, 25, 50, 100, 200, 25, 50, 100, 200,
+}
+
+// PacketDuration returns the duration of the audio in a packet, from its
+// table of contents byte
+func PacketDuration(packet []byte) (time.Duration, error) {
+	if len(packet) < 1 {
+		return 0, ErrInvalidPacket
+	}
+	frame := frameSizes[packet[0]>>3] * time.Millisecond / 10
+	switch packet[0] & 3 {
+	case 0:
+		return frame, nil
+	case 1, 2:
+		return 2 * frame, nil
+	}
+	if len(packet) < 2 {
+		return 0, ErrInvalidPacket
+	}
+	return time.Duration(packet[1]&0x3f) * frame, nil
+}
@@ -0,0 +1,33 @@
+package opus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacketDuration(t *testing.T) {
+	for _, tc := range []struct {
+		packet []byte
+		d      time.Duration
+	}{
+		{[]byte{0x00}, 10 * time.Millisecond},             // SILK NB 10ms
+		{[]byte{0x18}, 60 * time.Millisecond},             // SILK NB 60ms
+		{[]byte{0x78}, 20 * time.Millisecond},             // Hybrid FB 20ms
+		{[]byte{0xf8, 0xff, 0xfe}, 20 * time.Millisecond}, // CELT FB 20ms
+		{[]byte{0xe0}, 2500 * time.Microsecond},           // CELT FB 2.5ms
+		{[]byte{0xf9}, 40 * time.Millisecond},             // two 20ms frames
+		{[]byte{0xfb, 0x03}, 60 * time.Millisecond},       // three 20ms frames
+		{[]byte{0x1b, 0x02}, 120 * time.Millisecond},      // two 60ms frames
+	} {
+		d, err := PacketDuration(tc.packet)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.d, d, "%x", tc.packet)
+	}
+
+	_, err := PacketDuration(nil)
+	assert.Equal(t, ErrInvalidPacket, err)
+	_, err = PacketDuration([]byte{0x03})
+	assert.Equal(t, ErrInvalidPacket, err)
+}
@@ -0,0 +1,121 @@
+package pixel
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Fit is how a frame is scaled to a size of another aspect ratio
+type Fit int
+
+// Fits
+const (
+	// FitPad scales the whole frame to fit, with black bars above and
+	// below (letterbox) or to the sides (pillarbox)
+	FitPad Fit = iota
+	// FitCrop scales the frame to fill, cropping its edges
+	FitCrop
+	// FitStretch scales the frame to fill, distorting it
+	FitStretch
+)
+
+// ErrFit is returned by ParseFit for unknown fits
+var ErrFit = errors.New("pixel: unknown fit")
+
+// ParseFit parses "pad", "crop" or "stretch", the empty string is pad
+func ParseFit(s string) (Fit, error) {
+	switch strings.ToLower(s) {
+	case "", "pad", "letterbox", "pillarbox":
+		return FitPad, nil
+	case "crop":
+		return FitCrop, nil
+	case "stretch":
+		return FitStretch, nil
+	}
+	return 0, ErrFit
+}
+
+func (f Fit) String() string {
+	switch f {
+	case FitCrop:
+		return "crop"
+	case FitStretch:
+		return "stretch"
+	}
+	return "pad"
+}
+
+// ScaleFit resizes src to dst, keeping its aspect ratio as f says. Frames
+// may be portrait or landscape and of odd sizes. Bars and crops are
+// aligned to the chroma subsampling so colour stays in place. Both images
+// must have the same subsample ratio.
+func ScaleFit(dst, src *image.YCbCr, f Fit) error {
+	if dst.SubsampleRatio != src.SubsampleRatio {
+		return ErrUnsupported
+	}
+	hd, vd, ok := subsample(src.SubsampleRatio)
+	if !ok {
+		return ErrUnsupported
+	}
+	dw, dh := dst.Rect.Dx(), dst.Rect.Dy()
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if f == FitStretch || dw == 0 || dh == 0 || sw == 0 || sh == 0 || dw*sh == sw*dh {
+		return Scale(dst, src)
+	}
+
+	if f == FitCrop {
+		// Crop the source to the destination's aspect ratio
+		r := src.Rect
+		if sw*dh > dw*sh {
+			w := (sh*dw + dh/2) / dh
+			r.Min.X += align((sw-w)/2, hd)
+			r.Max.X = r.Min.X + w
+		} else {
+			h := (sw*dh + dw/2) / dw
+			r.Min.Y += align((sh-h)/2, vd)
+			r.Max.Y = r.Min.Y + h
+		}
+		return Scale(dst, src.SubImage(r).(*image.YCbCr))
+	}
+
+	Fill(dst, Black)
+	w, h := dw, (dw*sh+sw/2)/sw
+	if h > dh {
+		w, h = (dh*sw+sh/2)/sh, dh
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	r := image.Rect(0, 0, w, h).Add(dst.Rect.Min)
+	r = r.Add(image.Pt(align((dw-w)/2, hd), align((dh-h)/2, vd)))
+	return Scale(dst.SubImage(r).(*image.YCbCr), src)
+}
+
+// align rounds v down to a multiple of n
+func align(v, n int) int {
+	return v - v%n
+}
+
+// Black in limited range
+var Black = color.YCbCr{Y: 16, Cb: 128, Cr: 128}
+
+// Fill sets every pixel of m's bounds to c
+func Fill(m *image.YCbCr, c color.YCbCr) {
+	p, ok := planes(m)
+	if !ok {
+		return
+	}
+	for i, v := range [3]byte{c.Y, c.Cb, c.Cr} {
+		for y := 0; y < p[i].h; y++ {
+			row := p[i].pix[y*p[i].stride : y*p[i].stride+p[i].w]
+			for x := range row {
+				row[x] = v
+			}
+		}
+	}
+}
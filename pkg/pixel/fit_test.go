@@ -0,0 +1,70 @@
+package pixel
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uniform(r image.Rectangle, c color.YCbCr) *image.YCbCr {
+	m := image.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	Fill(m, c)
+	return m
+}
+
+func TestParseFit(t *testing.T) {
+	for _, f := range []Fit{FitPad, FitCrop, FitStretch} {
+		parsed, err := ParseFit(f.String())
+		assert.NoError(t, err)
+		assert.Equal(t, f, parsed)
+	}
+	_, err := ParseFit("zoom")
+	assert.Equal(t, ErrFit, err)
+}
+
+func TestScaleFit(t *testing.T) {
+	white := color.YCbCr{Y: 235, Cb: 128, Cr: 128}
+
+	// Portrait into landscape is pillarboxed
+	src := uniform(image.Rect(0, 0, 360, 640), white)
+	dst := image.NewYCbCr(image.Rect(0, 0, 640, 360), image.YCbCrSubsampleRatio420)
+	assert.NoError(t, ScaleFit(dst, src, FitPad))
+	// 203 wide, centered from an even column
+	assert.Equal(t, Black, dst.YCbCrAt(217, 180))
+	assert.Equal(t, white, dst.YCbCrAt(218, 0))
+	assert.Equal(t, white, dst.YCbCrAt(420, 359))
+	assert.Equal(t, Black, dst.YCbCrAt(421, 180))
+
+	// Landscape into portrait is letterboxed
+	src = uniform(image.Rect(0, 0, 640, 360), white)
+	dst = image.NewYCbCr(image.Rect(0, 0, 360, 640), image.YCbCrSubsampleRatio420)
+	assert.NoError(t, ScaleFit(dst, src, FitPad))
+	assert.Equal(t, Black, dst.YCbCrAt(180, 0))
+	assert.Equal(t, white, dst.YCbCrAt(180, 320))
+	assert.Equal(t, Black, dst.YCbCrAt(180, 639))
+
+	// Cropping fills, keeping the middle of odd sized frames
+	src = uniform(image.Rect(0, 0, 101, 33), Black)
+	for x := 30; x < 70; x++ {
+		for y := 0; y < 33; y++ {
+			src.Y[src.YOffset(x, y)] = 235
+		}
+	}
+	dst = image.NewYCbCr(image.Rect(0, 0, 17, 15), image.YCbCrSubsampleRatio420)
+	assert.NoError(t, ScaleFit(dst, src, FitCrop))
+	for y := 0; y < 15; y++ {
+		for x := 0; x < 17; x++ {
+			assert.Equal(t, uint8(235), dst.Y[dst.YOffset(x, y)])
+		}
+	}
+
+	// Odd sizes pad too
+	dst = image.NewYCbCr(image.Rect(0, 0, 33, 31), image.YCbCrSubsampleRatio420)
+	assert.NoError(t, ScaleFit(dst, src, FitPad))
+	assert.Equal(t, Black, dst.YCbCrAt(16, 0))
+	assert.Equal(t, uint8(235), dst.Y[dst.YOffset(16, 15)])
+
+	assert.Equal(t, ErrUnsupported, ScaleFit(dst, image.NewYCbCr(src.Rect, image.YCbCrSubsampleRatio444), FitPad))
+}
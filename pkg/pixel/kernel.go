@@ -0,0 +1,43 @@
+package pixel
+
+// Row kernels, replaced by SIMD ones where the CPU has them. Both give
+// results identical to the Go ones.
+var (
+	// rgbaRow converts a row of pixels with a Cb and Cr sample each to
+	// RGBA. out holds 4 bytes a pixel of y, cb and cr at least as long.
+	rgbaRow = rgbaRowGo
+	// blendRow blends rows t and b of 8.8 fixed point samples, weighting b
+	// by fy/256, into out. t and b are at least as long as out.
+	blendRow = blendRowGo
+)
+
+func rgbaRowGo(out, y, cb, cr []byte) {
+	for i, yy := range y {
+		yy1 := int32(yy) * 0x10101
+		cb1 := int32(cb[i]) - 128
+		cr1 := int32(cr[i]) - 128
+
+		red := yy1 + 91881*cr1
+		green := yy1 - 22554*cb1 - 46802*cr1
+		blue := yy1 + 116130*cb1
+		o := out[4*i : 4*i+4]
+		o[0] = clamp(red)
+		o[1] = clamp(green)
+		o[2] = clamp(blue)
+		o[3] = 0xff
+	}
+}
+
+// clamp converts a 16.16 fixed point channel to a byte
+func clamp(v int32) uint8 {
+	if uint32(v)&0xff000000 == 0 {
+		return uint8(v >> 16)
+	}
+	return uint8(^(v >> 31))
+}
+
+func blendRowGo(out []byte, t, b []uint16, fy int32) {
+	for x := range out {
+		out[x] = uint8((int32(t[x])*(256-fy) + int32(b[x])*fy + 1<<15) >> 16)
+	}
+}
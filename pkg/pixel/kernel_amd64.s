@@ -0,0 +1,150 @@
+//go:build !purego
+// +build !purego
+
+#include "textflag.h"
+
+// func rgbaRowSSE41(out, y, cb, cr *byte, n int)
+// Four pixels at a time, in 16.16 fixed point as rgbaRowGo.
+TEXT ·rgbaRowSSE41(SB), NOSPLIT, $0-40
+	MOVQ out+0(FP), DI
+	MOVQ y+8(FP), SI
+	MOVQ cb+16(FP), BX
+	MOVQ cr+24(FP), DX
+	MOVQ n+32(FP), CX
+
+	// X7 opaque alpha, X8 0x10101, X9 128, X10-X13 chroma factors, X14
+	// zero and X15 255, each in every lane
+	PCMPEQL X7, X7
+	PSLLL   $24, X7
+	MOVL    $0x10101, AX
+	MOVQ    AX, X8
+	PSHUFD  $0, X8, X8
+	MOVL    $128, AX
+	MOVQ    AX, X9
+	PSHUFD  $0, X9, X9
+	MOVL    $91881, AX
+	MOVQ    AX, X10
+	PSHUFD  $0, X10, X10
+	MOVL    $22554, AX
+	MOVQ    AX, X11
+	PSHUFD  $0, X11, X11
+	MOVL    $46802, AX
+	MOVQ    AX, X12
+	PSHUFD  $0, X12, X12
+	MOVL    $116130, AX
+	MOVQ    AX, X13
+	PSHUFD  $0, X13, X13
+	PXOR    X14, X14
+	PCMPEQL X15, X15
+	PSRLL   $24, X15
+
+loop:
+	PMOVZXBD (SI), X0
+	PMOVZXBD (BX), X1
+	PMOVZXBD (DX), X2
+	PMULLD   X8, X0
+	PSUBL    X9, X1
+	PSUBL    X9, X2
+
+	// red
+	MOVO   X2, X3
+	PMULLD X10, X3
+	PADDL  X0, X3
+
+	// green
+	MOVO   X1, X4
+	PMULLD X11, X4
+	MOVO   X2, X5
+	PMULLD X12, X5
+	PADDL  X5, X4
+	MOVO   X0, X5
+	PSUBL  X4, X5
+
+	// blue
+	PMULLD X13, X1
+	PADDL  X0, X1
+
+	// Clamp each to a byte, then pack them with alpha
+	PSRAL  $16, X3
+	PSRAL  $16, X5
+	PSRAL  $16, X1
+	PMAXSD X14, X3
+	PMAXSD X14, X5
+	PMAXSD X14, X1
+	PMINSD X15, X3
+	PMINSD X15, X5
+	PMINSD X15, X1
+	PSLLL  $8, X5
+	PSLLL  $16, X1
+	POR    X5, X3
+	POR    X1, X3
+	POR    X7, X3
+	MOVOU  X3, (DI)
+
+	ADDQ $16, DI
+	ADDQ $4, SI
+	ADDQ $4, BX
+	ADDQ $4, DX
+	SUBQ $4, CX
+	JNZ  loop
+	RET
+
+// func blendRowSSE2(out *byte, t, b *uint16, n int, fy int32)
+// Eight samples at a time, the 32 bit products of the weights taken from
+// their low and high halves.
+TEXT ·blendRowSSE2(SB), NOSPLIT, $0-36
+	MOVQ out+0(FP), DI
+	MOVQ t+8(FP), SI
+	MOVQ b+16(FP), BX
+	MOVQ n+24(FP), CX
+	MOVL fy+32(FP), AX
+
+	// X6 256-fy and X7 fy in every word, X8 1<<15 in every long
+	MOVQ    AX, X7
+	PSHUFLW $0, X7, X7
+	PSHUFD  $0, X7, X7
+	MOVL    $256, DX
+	SUBL    AX, DX
+	MOVQ    DX, X6
+	PSHUFLW $0, X6, X6
+	PSHUFD  $0, X6, X6
+	MOVL    $0x8000, DX
+	MOVQ    DX, X8
+	PSHUFD  $0, X8, X8
+
+loop:
+	MOVOU (SI), X0
+	MOVOU (BX), X1
+
+	// t*(256-fy), low and high four
+	MOVO      X0, X2
+	PMULLW    X6, X0
+	PMULHUW   X6, X2
+	MOVO      X0, X3
+	PUNPCKLWL X2, X0
+	PUNPCKHWL X2, X3
+
+	// b*fy
+	MOVO      X1, X4
+	PMULLW    X7, X1
+	PMULHUW   X7, X4
+	MOVO      X1, X5
+	PUNPCKLWL X4, X1
+	PUNPCKHWL X4, X5
+
+	PADDL    X1, X0
+	PADDL    X5, X3
+	PADDL    X8, X0
+	PADDL    X8, X3
+	PSRLL    $16, X0
+	PSRLL    $16, X3
+	PACKSSLW X3, X0
+	PACKUSWB X0, X0
+	MOVQ     X0, (DI)
+
+	ADDQ $8, DI
+	ADDQ $16, SI
+	ADDQ $16, BX
+	SUBQ $8, CX
+	JNZ  loop
+	RET
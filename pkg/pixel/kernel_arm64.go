@@ -0,0 +1,41 @@
+//go:build !purego
+// +build !purego
+
+package pixel
+
+import "golang.org/x/sys/cpu"
+
+func init() {
+	if cpu.ARM64.HasASIMD {
+		rgbaRow = rgbaRowSIMD
+		blendRow = blendRowSIMD
+	}
+}
+
+// rgbaRowNEON converts n pixels, a multiple of 8
+//
+//go:noescape
+func rgbaRowNEON(out, y, cb, cr *byte, n int)
+
+// blendRowNEON blends n samples, a multiple of 8
+//
+//go:noescape
+func blendRowNEON(out *byte, t, b *uint16, n int, fy int32)
+
+func rgbaRowSIMD(out, y, cb, cr []byte) {
+	if n := len(y) &^ 7; n > 0 {
+		_, _, _ = out[4*n-1], cb[n-1], cr[n-1]
+		rgbaRowNEON(&out[0], &y[0], &cb[0], &cr[0], n)
+		out, y, cb, cr = out[4*n:], y[n:], cb[n:], cr[n:]
+	}
+	rgbaRowGo(out, y, cb, cr)
+}
+
+func blendRowSIMD(out []byte, t, b []uint16, fy int32) {
+	if n := len(out) &^ 7; n > 0 {
+		_, _ = t[n-1], b[n-1]
+		blendRowNEON(&out[0], &t[0], &b[0], n, fy)
+		out, t, b = out[n:], t[n:], b[n:]
+	}
+	blendRowGo(out, t, b, fy)
+}
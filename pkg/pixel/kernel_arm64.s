@@ -0,0 +1,126 @@
+//go:build !purego
+// +build !purego
+
+#include "textflag.h"
+
+// func rgbaRowNEON(out, y, cb, cr *byte, n int)
+// Eight pixels at a time, in 16.16 fixed point as rgbaRowGo, stored
+// interleaved by VST4.
+TEXT ·rgbaRowNEON(SB), NOSPLIT, $0-40
+	MOVD out+0(FP), R0
+	MOVD y+8(FP), R1
+	MOVD cb+16(FP), R2
+	MOVD cr+24(FP), R3
+	MOVD n+32(FP), R4
+
+	// V16 0x10101, V17 128, V18, V19, V24 and V25 the chroma factors and
+	// V23 opaque alpha, each in every lane
+	MOVD  $0x10101, R5
+	VDUP  R5, V16.S4
+	MOVD  $128, R5
+	VDUP  R5, V17.H8
+	MOVD  $91881, R5
+	VDUP  R5, V18.S4
+	MOVD  $22554, R5
+	VDUP  R5, V19.S4
+	MOVD  $46802, R5
+	VDUP  R5, V24.S4
+	MOVD  $116130, R5
+	VDUP  R5, V25.S4
+	VMOVI $255, V23.B8
+
+loop:
+	VLD1.P 8(R1), [V0.B8]
+	VLD1.P 8(R2), [V1.B8]
+	VLD1.P 8(R3), [V2.B8]
+	VUXTL  V0.B8, V0.H8
+	VUXTL  V1.B8, V1.H8
+	VUXTL  V2.B8, V2.H8
+	VSUB   V17.H8, V1.H8, V1.H8
+	VSUB   V17.H8, V2.H8, V2.H8
+
+	// Y*0x10101 in V4 and V5, Cb in V6 and V7, Cr in V26 and V27, the low
+	// and high four
+	VUXTL  V0.H4, V4.S4
+	VUXTL2 V0.H8, V5.S4
+	VMUL   V16.S4, V4.S4, V4.S4
+	VMUL   V16.S4, V5.S4, V5.S4
+	VSXTL  V1.H4, V6.S4
+	VSXTL2 V1.H8, V7.S4
+	VSXTL  V2.H4, V26.S4
+	VSXTL2 V2.H8, V27.S4
+
+	// red
+	VMOV V4.B16, V8.B16
+	VMOV V5.B16, V9.B16
+	VMLA V18.S4, V26.S4, V8.S4
+	VMLA V18.S4, V27.S4, V9.S4
+
+	// green
+	VMOV V4.B16, V10.B16
+	VMOV V5.B16, V11.B16
+	VMLS V19.S4, V6.S4, V10.S4
+	VMLS V19.S4, V7.S4, V11.S4
+	VMLS V24.S4, V26.S4, V10.S4
+	VMLS V24.S4, V27.S4, V11.S4
+
+	// blue
+	VMLA V25.S4, V6.S4, V4.S4
+	VMLA V25.S4, V7.S4, V5.S4
+
+	// Clamp each to a byte, narrowing with saturation
+	VSSHR    $16, V8.S4, V8.S4
+	VSSHR    $16, V9.S4, V9.S4
+	VSSHR    $16, V10.S4, V10.S4
+	VSSHR    $16, V11.S4, V11.S4
+	VSSHR    $16, V4.S4, V4.S4
+	VSSHR    $16, V5.S4, V5.S4
+	VSQXTN   V8.S4, V20.H4
+	VSQXTN2  V9.S4, V20.H8
+	VSQXTN   V10.S4, V21.H4
+	VSQXTN2  V11.S4, V21.H8
+	VSQXTN   V4.S4, V22.H4
+	VSQXTN2  V5.S4, V22.H8
+	VSQXTUN  V20.H8, V20.B8
+	VSQXTUN  V21.H8, V21.B8
+	VSQXTUN  V22.H8, V22.B8
+	VST4.P   [V20.B8, V21.B8, V22.B8, V23.B8], 32(R0)
+
+	SUBS $8, R4, R4
+	BNE  loop
+	RET
+
+// func blendRowNEON(out *byte, t, b *uint16, n int, fy int32)
+// Eight samples at a time, widening to 32 bits as they're weighted.
+TEXT ·blendRowNEON(SB), NOSPLIT, $0-36
+	MOVD  out+0(FP), R0
+	MOVD  t+8(FP), R1
+	MOVD  b+16(FP), R2
+	MOVD  n+24(FP), R3
+	MOVWU fy+32(FP), R4
+
+	// V16 256-fy and V17 fy in every half, V18 1<<15 in every word
+	MOVD $256, R5
+	SUB  R4, R5, R5
+	VDUP R5, V16.H8
+	VDUP R4, V17.H8
+	MOVD $0x8000, R5
+	VDUP R5, V18.S4
+
+loop:
+	VLD1.P  16(R1), [V0.H8]
+	VLD1.P  16(R2), [V1.H8]
+	VMOV    V18.B16, V2.B16
+	VMOV    V18.B16, V3.B16
+	VUMLAL  V16.H4, V0.H4, V2.S4
+	VUMLAL2 V16.H8, V0.H8, V3.S4
+	VUMLAL  V17.H4, V1.H4, V2.S4
+	VUMLAL2 V17.H8, V1.H8, V3.S4
+	VSHRN   $16, V2.S4, V4.H4
+	VSHRN2  $16, V3.S4, V4.H8
+	VXTN    V4.H8, V4.B8
+	VST1.P  [V4.B8], 8(R0)
+
+	SUBS $8, R3, R3
+	BNE  loop
+	RET
@@ -0,0 +1,62 @@
+package pixel
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The kernels in use, SIMD ones where the CPU has them, give the results
+// of the Go ones for every length, so the vector loops and their tails.
+func TestRGBARow(t *testing.T) {
+	for n := 0; n < 70; n++ {
+		y, cb, cr := make([]byte, n), make([]byte, n), make([]byte, n)
+		rand.Read(y)
+		rand.Read(cb)
+		rand.Read(cr)
+		if n > 3 {
+			// The extremes, which clamp
+			copy(y, []byte{0, 255, 0, 255})
+			copy(cb, []byte{0, 255, 255, 0})
+			copy(cr, []byte{0, 255, 0, 255})
+		}
+		want, got := make([]byte, 4*n), make([]byte, 4*n)
+		rgbaRowGo(want, y, cb, cr)
+		rgbaRow(got, y, cb, cr)
+		assert.Equal(t, want, got, "%d pixels", n)
+	}
+
+	// Every Y, Cb and Cr
+	y, cb, cr := make([]byte, 1<<16), make([]byte, 1<<16), make([]byte, 1<<16)
+	want, got := make([]byte, 1<<18), make([]byte, 1<<18)
+	for c := 0; c < 256; c++ {
+		for i := range y {
+			y[i], cb[i], cr[i] = byte(i), byte(i>>8), byte(c)
+		}
+		rgbaRowGo(want, y, cb, cr)
+		rgbaRow(got, y, cb, cr)
+		if !assert.Equal(t, want, got, "Cr %d", c) {
+			return
+		}
+	}
+}
+
+func TestBlendRow(t *testing.T) {
+	for n := 0; n < 70; n++ {
+		top, bottom := make([]uint16, n), make([]uint16, n)
+		for i := range top {
+			top[i], bottom[i] = uint16(rand.Intn(255*256+1)), uint16(rand.Intn(255*256+1))
+		}
+		if n > 1 {
+			top[0], bottom[0] = 255*256, 255*256
+			top[1], bottom[1] = 0, 255*256
+		}
+		for _, fy := range []int32{0, 1, 127, 128, 255} {
+			want, got := make([]byte, n), make([]byte, n)
+			blendRowGo(want, top, bottom, fy)
+			blendRow(got, top, bottom, fy)
+			assert.Equal(t, want, got, "%d samples at %d", n, fy)
+		}
+	}
+}
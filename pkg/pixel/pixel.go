@@ -0,0 +1,246 @@
+// Package pixel converts and scales decoded video frames. These loops are
+// the bottleneck of snapshot and decode pipelines on ARM edge devices, so
+// they use fixed point arithmetic on whole rows and split large frames
+// across cores, picked at runtime by frame size and CPU count. Rows are
+// converted and blended by SIMD kernels on amd64 (SSE4.1) and arm64
+// (NEON), picked at runtime by CPU features, and by portable Go elsewhere
+// or when built with the purego tag.
+package pixel
+
+import (
+	"errors"
+	"image"
+	"runtime"
+	"sync"
+)
+
+// ErrUnsupported is returned for chroma subsampling ratios that aren't
+// supported, or that differ between source and destination
+var ErrUnsupported = errors.New("pixel: unsupported subsample ratio")
+
+// Workers is the most goroutines a conversion is split across, defaults
+// to the number of CPUs
+var Workers = runtime.NumCPU()
+
+// parallelMin is the fewest pixels worth splitting across goroutines
+const parallelMin = 1 << 16
+
+// rows calls f on bands of the rows [0, h), in parallel for large frames
+func rows(w, h int, f func(y0, y1 int)) {
+	n := Workers
+	if w*h < parallelMin || n < 2 {
+		f(0, h)
+		return
+	}
+	if n > h {
+		n = h
+	}
+	var wg sync.WaitGroup
+	band := (h + n - 1) / n
+	for y := 0; y < h; y += band {
+		y1 := y + band
+		if y1 > h {
+			y1 = h
+		}
+		wg.Add(1)
+		go func(y0, y1 int) {
+			defer wg.Done()
+			f(y0, y1)
+		}(y, y1)
+	}
+	wg.Wait()
+}
+
+// subsample returns the horizontal and vertical chroma subsampling divisors
+func subsample(r image.YCbCrSubsampleRatio) (int, int, bool) {
+	switch r {
+	case image.YCbCrSubsampleRatio444:
+		return 1, 1, true
+	case image.YCbCrSubsampleRatio422:
+		return 2, 1, true
+	case image.YCbCrSubsampleRatio420:
+		return 2, 2, true
+	case image.YCbCrSubsampleRatio440:
+		return 1, 2, true
+	}
+	return 0, 0, false
+}
+
+// ToRGBA converts the intersection of dst and src bounds from src into
+// dst. Results are identical to color.YCbCrToRGB.
+func ToRGBA(dst *image.RGBA, src *image.YCbCr) error {
+	hd, vd, ok := subsample(src.SubsampleRatio)
+	if !ok {
+		return ErrUnsupported
+	}
+	r := dst.Rect.Intersect(src.Rect)
+	if r.Empty() {
+		return nil
+	}
+
+	w := r.Dx()
+	cx0 := src.Rect.Min.X / hd
+	rows(w, r.Dy(), func(y0, y1 int) {
+		// Subsampled chroma is spread to a sample a pixel for the kernel
+		var cb, cr []byte
+		if hd > 1 {
+			cb, cr = make([]byte, w), make([]byte, w)
+		}
+		for y := r.Min.Y + y0; y < r.Min.Y+y1; y++ {
+			yi := src.YOffset(r.Min.X, y)
+			cy := (y/vd - src.Rect.Min.Y/vd) * src.CStride
+			if hd > 1 {
+				for i := range cb {
+					ci := cy + (r.Min.X+i)/hd - cx0
+					cb[i], cr[i] = src.Cb[ci], src.Cr[ci]
+				}
+			} else {
+				ci := cy + r.Min.X - cx0
+				cb, cr = src.Cb[ci:ci+w], src.Cr[ci:ci+w]
+			}
+			out := dst.Pix[dst.PixOffset(r.Min.X, y):]
+			rgbaRow(out[:4*w], src.Y[yi:yi+w], cb, cr)
+		}
+	})
+	return nil
+}
+
+// plane is one channel of an image
+type plane struct {
+	pix    []byte
+	stride int
+	w, h   int
+}
+
+// planes returns the Y, Cb and Cr planes of the image's bounds
+func planes(m *image.YCbCr) ([3]plane, bool) {
+	hd, vd, ok := subsample(m.SubsampleRatio)
+	if !ok {
+		return [3]plane{}, false
+	}
+	r := m.Rect
+	cw := (r.Max.X+hd-1)/hd - r.Min.X/hd
+	ch := (r.Max.Y+vd-1)/vd - r.Min.Y/vd
+	yi, ci := m.YOffset(r.Min.X, r.Min.Y), m.COffset(r.Min.X, r.Min.Y)
+	return [3]plane{
+		{m.Y[yi:], m.YStride, r.Dx(), r.Dy()},
+		{m.Cb[ci:], m.CStride, cw, ch},
+		{m.Cr[ci:], m.CStride, cw, ch},
+	}, true
+}
+
+// AppendPlanar appends the Y, Cb and Cr planes of m to b, each row after
+// row without padding, as raw planar YUV files and Y4M frames hold them
+func AppendPlanar(b []byte, m *image.YCbCr) ([]byte, error) {
+	ps, ok := planes(m)
+	if !ok {
+		return b, ErrUnsupported
+	}
+	for _, p := range ps {
+		for y := 0; y < p.h; y++ {
+			b = append(b, p.pix[y*p.stride:y*p.stride+p.w]...)
+		}
+	}
+	return b, nil
+}
+
+// Scale resizes src to fill dst with bilinear filtering, e.g. for
+// thumbnails. Both images must have the same subsample ratio.
+func Scale(dst, src *image.YCbCr) error {
+	if dst.SubsampleRatio != src.SubsampleRatio {
+		return ErrUnsupported
+	}
+	dp, ok := planes(dst)
+	if !ok {
+		return ErrUnsupported
+	}
+	sp, _ := planes(src)
+	for i := range dp {
+		scale(dp[i], sp[i])
+	}
+	return nil
+}
+
+// Copy copies src onto dst, from their top left corners, as far as both
+// extend. Both images must have the same subsample ratio.
+func Copy(dst, src *image.YCbCr) error {
+	if dst.SubsampleRatio != src.SubsampleRatio {
+		return ErrUnsupported
+	}
+	dp, ok := planes(dst)
+	if !ok {
+		return ErrUnsupported
+	}
+	sp, _ := planes(src)
+	for i := range dp {
+		w, h := dp[i].w, dp[i].h
+		if sp[i].w < w {
+			w = sp[i].w
+		}
+		if sp[i].h < h {
+			h = sp[i].h
+		}
+		for y := 0; y < h; y++ {
+			copy(dp[i].pix[y*dp[i].stride:y*dp[i].stride+w], sp[i].pix[y*sp[i].stride:])
+		}
+	}
+	return nil
+}
+
+// axis maps each destination coordinate to the source index before it
+// and the 8 bit weight of the one after, sampling at pixel centers
+func axis(dn, sn int) ([]int, []int32) {
+	idx := make([]int, dn)
+	frac := make([]int32, dn)
+	last := (sn - 1) << 8
+	for d := range idx {
+		s := ((2*d+1)*sn<<8)/(2*dn) - 128
+		if s < 0 {
+			s = 0
+		} else if s > last {
+			s = last
+		}
+		idx[d], frac[d] = s>>8, int32(s&0xff)
+	}
+	return idx, frac
+}
+
+func scale(dst, src plane) {
+	if dst.w == 0 || dst.h == 0 || src.w == 0 || src.h == 0 {
+		return
+	}
+	xs, xf := axis(dst.w, src.w)
+	ys, yf := axis(dst.h, src.h)
+
+	rows(dst.w, dst.h, func(y0, y1 int) {
+		top, bottom := make([]uint16, dst.w), make([]uint16, dst.w)
+		last := -1
+		for y := y0; y < y1; y++ {
+			// Rows filtered for the one before are kept when upscaling
+			if ys[y] != last {
+				last = ys[y]
+				next := last
+				if next+1 < src.h {
+					next++
+				}
+				filter(top, src.pix[last*src.stride:], xs, xf, src.w)
+				filter(bottom, src.pix[next*src.stride:], xs, xf, src.w)
+			}
+			blendRow(dst.pix[y*dst.stride:y*dst.stride+dst.w], top, bottom, yf[y])
+		}
+	})
+}
+
+// filter interpolates a source row of width w at the destination's
+// columns, to 8.8 fixed point
+func filter(out []uint16, row []byte, xs []int, xf []int32, w int) {
+	for x := range out {
+		x0 := xs[x]
+		x1 := x0
+		if x0+1 < w {
+			x1++
+		}
+		fx := xf[x]
+		out[x] = uint16(int32(row[x0])*(256-fx) + int32(row[x1])*fx)
+	}
+}
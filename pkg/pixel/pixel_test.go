@@ -0,0 +1,112 @@
+package pixel
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func random(r image.Rectangle, ratio image.YCbCrSubsampleRatio) *image.YCbCr {
+	m := image.NewYCbCr(r, ratio)
+	rand.Read(m.Y)
+	rand.Read(m.Cb)
+	rand.Read(m.Cr)
+	return m
+}
+
+func TestToRGBA(t *testing.T) {
+	ratios := []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440,
+	}
+	// Odd bounds and a large frame split across workers
+	rects := []image.Rectangle{image.Rect(1, 3, 38, 28), image.Rect(0, 0, 320, 241)}
+	for _, ratio := range ratios {
+		for _, r := range rects {
+			src := random(r, ratio)
+			dst := image.NewRGBA(r)
+			assert.NoError(t, ToRGBA(dst, src))
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					want := color.RGBAModel.Convert(src.YCbCrAt(x, y))
+					if !assert.Equal(t, want, dst.RGBAAt(x, y), "%s %v at %d,%d", ratio, r, x, y) {
+						return
+					}
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, ErrUnsupported, ToRGBA(image.NewRGBA(image.Rect(0, 0, 4, 4)),
+		image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio410)))
+}
+
+func TestScale(t *testing.T) {
+	// A uniform image stays uniform at any size
+	src := image.NewYCbCr(image.Rect(0, 0, 64, 48), image.YCbCrSubsampleRatio420)
+	for i := range src.Y {
+		src.Y[i] = 200
+	}
+	for i := range src.Cb {
+		src.Cb[i], src.Cr[i] = 50, 100
+	}
+	for _, r := range []image.Rectangle{image.Rect(0, 0, 16, 12), image.Rect(0, 0, 129, 97), image.Rect(0, 0, 1, 1)} {
+		dst := image.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+		assert.NoError(t, Scale(dst, src))
+		for y := 0; y < r.Dy(); y++ {
+			for x := 0; x < r.Dx(); x++ {
+				assert.Equal(t, color.YCbCr{Y: 200, Cb: 50, Cr: 100}, dst.YCbCrAt(x, y))
+			}
+		}
+	}
+
+	// Halving averages neighbouring pixels
+	src = image.NewYCbCr(image.Rect(0, 0, 4, 2), image.YCbCrSubsampleRatio444)
+	copy(src.Y, []byte{0, 100, 200, 250, 0, 100, 200, 250})
+	dst := image.NewYCbCr(image.Rect(0, 0, 2, 1), image.YCbCrSubsampleRatio444)
+	assert.NoError(t, Scale(dst, src))
+	assert.Equal(t, []byte{50, 225}, dst.Y)
+
+	// Same size is a copy
+	src = random(image.Rect(0, 0, 33, 17), image.YCbCrSubsampleRatio420)
+	dst = image.NewYCbCr(src.Rect, image.YCbCrSubsampleRatio420)
+	assert.NoError(t, Scale(dst, src))
+	assert.Equal(t, src.Y, dst.Y)
+	assert.Equal(t, src.Cb, dst.Cb)
+
+	assert.Equal(t, ErrUnsupported, Scale(dst, image.NewYCbCr(src.Rect, image.YCbCrSubsampleRatio444)))
+}
+
+func TestCopy(t *testing.T) {
+	white := color.YCbCr{Y: 235, Cb: 128, Cr: 128}
+	dst := uniform(image.Rect(0, 0, 8, 8), Black)
+	src := uniform(image.Rect(0, 0, 4, 4), white)
+	assert.NoError(t, Copy(dst.SubImage(image.Rect(2, 2, 8, 8)).(*image.YCbCr), src))
+	assert.Equal(t, Black, dst.YCbCrAt(1, 1))
+	assert.Equal(t, white, dst.YCbCrAt(2, 2))
+	assert.Equal(t, white, dst.YCbCrAt(5, 5))
+	assert.Equal(t, Black, dst.YCbCrAt(6, 6))
+
+	assert.Equal(t, ErrUnsupported, Copy(dst, image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio444)))
+}
+
+func TestAppendPlanar(t *testing.T) {
+	m := random(image.Rect(0, 0, 5, 3), image.YCbCrSubsampleRatio420)
+	b, err := AppendPlanar([]byte{9}, m.SubImage(image.Rect(2, 0, 5, 2)).(*image.YCbCr))
+	assert.NoError(t, err)
+	// 3x2 luma, 2x1 of each chroma covering the odd column
+	assert.Len(t, b, 1+6+2+2)
+	assert.Equal(t, byte(9), b[0])
+	assert.Equal(t, m.Y[m.YOffset(2, 0):m.YOffset(2, 0)+3], b[1:4])
+	assert.Equal(t, m.Y[m.YOffset(2, 1):m.YOffset(2, 1)+3], b[4:7])
+	assert.Equal(t, m.Cb[m.COffset(2, 0):m.COffset(2, 0)+2], b[7:9])
+	assert.Equal(t, m.Cr[m.COffset(2, 0):m.COffset(2, 0)+2], b[9:11])
+
+	_, err = AppendPlanar(nil, image.NewYCbCr(image.Rect(0, 0, 2, 2), image.YCbCrSubsampleRatio411))
+	assert.Equal(t, ErrUnsupported, err)
+}
@@ -0,0 +1,211 @@
+package pixel
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Glyphs of the built in font are 5 by 7 pixels, drawn with a pixel of
+// spacing to the right and two below
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+	advance     = glyphWidth + 1
+	lineHeight  = glyphHeight + 2
+)
+
+// font holds the columns of printable ASCII glyphs, least significant bit
+// at the top
+var font = [95][glyphWidth]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00}, {0x00, 0x00, 0x5f, 0x00, 0x00}, {0x00, 0x07, 0x00, 0x07, 0x00}, {0x14, 0x7f, 0x14, 0x7f, 0x14}, // space ! " #
+	{0x24, 0x2a, 0x7f, 0x2a, 0x12}, {0x23, 0x13, 0x08, 0x64, 0x62}, {0x36, 0x49, 0x55, 0x22, 0x50}, {0x00, 0x05, 0x03, 0x00, 0x00}, // $ % & '
+	{0x00, 0x1c, 0x22, 0x41, 0x00}, {0x00, 0x41, 0x22, 0x1c, 0x00}, {0x08, 0x2a, 0x1c, 0x2a, 0x08}, {0x08, 0x08, 0x3e, 0x08, 0x08}, // ( ) * +
+	{0x00, 0x50, 0x30, 0x00, 0x00}, {0x08, 0x08, 0x08, 0x08, 0x08}, {0x00, 0x60, 0x60, 0x00, 0x00}, {0x20, 0x10, 0x08, 0x04, 0x02}, // , - . /
+	{0x3e, 0x51, 0x49, 0x45, 0x3e}, {0x00, 0x42, 0x7f, 0x40, 0x00}, {0x42, 0x61, 0x51, 0x49, 0x46}, {0x21, 0x41, 0x45, 0x4b, 0x31}, // 0 1 2 3
+	{0x18, 0x14, 0x12, 0x7f, 0x10}, {0x27, 0x45, 0x45, 0x45, 0x39}, {0x3c, 0x4a, 0x49, 0x49, 0x30}, {0x01, 0x71, 0x09, 0x05, 0x03}, // 4 5 6 7
+	{0x36, 0x49, 0x49, 0x49, 0x36}, {0x06, 0x49, 0x49, 0x29, 0x1e}, {0x00, 0x36, 0x36, 0x00, 0x00}, {0x00, 0x56, 0x36, 0x00, 0x00}, // 8 9 : ;
+	{0x08, 0x14, 0x22, 0x41, 0x00}, {0x14, 0x14, 0x14, 0x14, 0x14}, {0x00, 0x41, 0x22, 0x14, 0x08}, {0x02, 0x01, 0x51, 0x09, 0x06}, // < = > ?
+	{0x32, 0x49, 0x79, 0x41, 0x3e}, {0x7e, 0x11, 0x11, 0x11, 0x7e}, {0x7f, 0x49, 0x49, 0x49, 0x36}, {0x3e, 0x41, 0x41, 0x41, 0x22}, // @ A B C
+	{0x7f, 0x41, 0x41, 0x22, 0x1c}, {0x7f, 0x49, 0x49, 0x49, 0x41}, {0x7f, 0x09, 0x09, 0x09, 0x01}, {0x3e, 0x41, 0x49, 0x49, 0x7a}, // D E F G
+	{0x7f, 0x08, 0x08, 0x08, 0x7f}, {0x00, 0x41, 0x7f, 0x41, 0x00}, {0x20, 0x40, 0x41, 0x3f, 0x01}, {0x7f, 0x08, 0x14, 0x22, 0x41}, // H I J K
+	{0x7f, 0x40, 0x40, 0x40, 0x40}, {0x7f, 0x02, 0x0c, 0x02, 0x7f}, {0x7f, 0x04, 0x08, 0x10, 0x7f}, {0x3e, 0x41, 0x41, 0x41, 0x3e}, // L M N O
+	{0x7f, 0x09, 0x09, 0x09, 0x06}, {0x3e, 0x41, 0x51, 0x21, 0x5e}, {0x7f, 0x09, 0x19, 0x29, 0x46}, {0x46, 0x49, 0x49, 0x49, 0x31}, // P Q R S
+	{0x01, 0x01, 0x7f, 0x01, 0x01}, {0x3f, 0x40, 0x40, 0x40, 0x3f}, {0x1f, 0x20, 0x40, 0x20, 0x1f}, {0x3f, 0x40, 0x38, 0x40, 0x3f}, // T U V W
+	{0x63, 0x14, 0x08, 0x14, 0x63}, {0x07, 0x08, 0x70, 0x08, 0x07}, {0x61, 0x51, 0x49, 0x45, 0x43}, {0x00, 0x7f, 0x41, 0x41, 0x00}, // X Y Z [
+	{0x02, 0x04, 0x08, 0x10, 0x20}, {0x00, 0x41, 0x41, 0x7f, 0x00}, {0x04, 0x02, 0x01, 0x02, 0x04}, {0x40, 0x40, 0x40, 0x40, 0x40}, // \ ] ^ _
+	{0x00, 0x01, 0x02, 0x04, 0x00}, {0x20, 0x54, 0x54, 0x54, 0x78}, {0x7f, 0x48, 0x44, 0x44, 0x38}, {0x38, 0x44, 0x44, 0x44, 0x20}, // ` a b c
+	{0x38, 0x44, 0x44, 0x48, 0x7f}, {0x38, 0x54, 0x54, 0x54, 0x18}, {0x08, 0x7e, 0x09, 0x01, 0x02}, {0x0c, 0x52, 0x52, 0x52, 0x3e}, // d e f g
+	{0x7f, 0x08, 0x04, 0x04, 0x78}, {0x00, 0x44, 0x7d, 0x40, 0x00}, {0x20, 0x40, 0x44, 0x3d, 0x00}, {0x7f, 0x10, 0x28, 0x44, 0x00}, // h i j k
+	{0x00, 0x41, 0x7f, 0x40, 0x00}, {0x7c, 0x04, 0x18, 0x04, 0x78}, {0x7c, 0x08, 0x04, 0x04, 0x78}, {0x38, 0x44, 0x44, 0x44, 0x38}, // l m n o
+	{0x7c, 0x14, 0x14, 0x14, 0x08}, {0x08, 0x14, 0x14, 0x18, 0x7c}, {0x7c, 0x08, 0x04, 0x04, 0x08}, {0x48, 0x54, 0x54, 0x54, 0x20}, // p q r s
+	{0x04, 0x3f, 0x44, 0x40, 0x20}, {0x3c, 0x40, 0x40, 0x20, 0x7c}, {0x1c, 0x20, 0x40, 0x20, 0x1c}, {0x3c, 0x40, 0x30, 0x40, 0x3c}, // t u v w
+	{0x44, 0x28, 0x10, 0x28, 0x44}, {0x0c, 0x50, 0x50, 0x50, 0x3c}, {0x44, 0x64, 0x54, 0x4c, 0x44}, {0x00, 0x08, 0x36, 0x41, 0x00}, // x y z {
+	{0x00, 0x00, 0x7f, 0x00, 0x00}, {0x00, 0x41, 0x36, 0x08, 0x00}, {0x08, 0x04, 0x08, 0x10, 0x08}, // | } ~
+}
+
+// White in limited range
+var White = color.YCbCr{Y: 235, Cb: 128, Cr: 128}
+
+// labelBackground is the dark grey behind labels
+var labelBackground = color.YCbCr{Y: 40, Cb: 128, Cr: 128}
+
+// TextSize returns the size of s drawn at scale
+func TextSize(s string, scale int) image.Point {
+	n := len([]rune(s))
+	if n == 0 {
+		return image.Point{}
+	}
+	return image.Pt((n*advance-1)*scale, glyphHeight*scale)
+}
+
+// Text draws s onto m with its top left at pt, each pixel of the built in
+// font scale pixels square, clipped to m's bounds. Characters other than
+// printable ASCII are drawn as '?'.
+func Text(m *image.YCbCr, pt image.Point, s string, scale int, c color.YCbCr) {
+	if scale < 1 {
+		scale = 1
+	}
+	x := pt.X
+	for _, r := range s {
+		if r < ' ' || r > '~' {
+			r = '?'
+		}
+		for col, bits := range font[r-' '] {
+			for row := 0; row < glyphHeight; row++ {
+				if bits&(1<<uint(row)) == 0 {
+					continue
+				}
+				p := image.Rect(x+col*scale, pt.Y+row*scale, x+(col+1)*scale, pt.Y+(row+1)*scale)
+				if p = p.Intersect(m.Rect); !p.Empty() {
+					Fill(m.SubImage(p).(*image.YCbCr), c)
+				}
+			}
+		}
+		x += advance * scale
+	}
+}
+
+// Label draws s in white on a dark box at the bottom left of m, e.g. a
+// participant's name on their tile. Text is sized to m's height and cut
+// short to fit its width.
+func Label(m *image.YCbCr, s string) {
+	if s == "" || m.Rect.Empty() {
+		return
+	}
+	scale := m.Rect.Dy() / 90
+	if scale < 1 {
+		scale = 1
+	}
+	pad := 2 * scale
+	runes := []rune(s)
+	fits := (m.Rect.Dx() - 2*pad + scale) / (advance * scale)
+	if fits < 1 {
+		return
+	}
+	if len(runes) > fits {
+		runes = runes[:fits]
+	}
+	text := string(runes)
+	size := TextSize(text, scale)
+	box := image.Rect(0, 0, size.X+2*pad, lineHeight*scale+pad).
+		Add(image.Pt(m.Rect.Min.X, m.Rect.Max.Y-lineHeight*scale-pad)).
+		Intersect(m.Rect)
+	Fill(m.SubImage(box).(*image.YCbCr), labelBackground)
+	Text(m, box.Min.Add(image.Pt(pad, pad)), text, scale, White)
+}
+
+// Corner of an image
+type Corner int
+
+// Corners
+const (
+	TopLeft Corner = iota
+	TopRight
+	BottomLeft
+	BottomRight
+)
+
+// ErrCorner is returned by ParseCorner for unknown corners
+var ErrCorner = errors.New("pixel: unknown corner")
+
+// ParseCorner parses "top-left", "top-right", "bottom-left" or
+// "bottom-right", the empty string is top left
+func ParseCorner(s string) (Corner, error) {
+	switch strings.ToLower(s) {
+	case "", "top-left":
+		return TopLeft, nil
+	case "top-right":
+		return TopRight, nil
+	case "bottom-left":
+		return BottomLeft, nil
+	case "bottom-right":
+		return BottomRight, nil
+	}
+	return 0, ErrCorner
+}
+
+// Overlay draws lines of text in white on a dark box in a corner of m,
+// e.g. a timecode burned into review copies. Pixels of the font are scale
+// pixels square, or sized to m's height if scale is 0, so the box takes
+// the same share of frames of any resolution.
+func Overlay(m *image.YCbCr, c Corner, scale int, lines ...string) {
+	if len(lines) == 0 || m.Rect.Empty() {
+		return
+	}
+	if scale < 1 {
+		scale = m.Rect.Dy() / 180
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	pad := 2 * scale
+	width := 0
+	for _, l := range lines {
+		if w := TextSize(l, scale).X; w > width {
+			width = w
+		}
+	}
+	box := image.Rect(0, 0, width+2*pad, len(lines)*lineHeight*scale+pad)
+	at := m.Rect.Min
+	if c == TopRight || c == BottomRight {
+		at.X = m.Rect.Max.X - box.Dx()
+	}
+	if c == BottomLeft || c == BottomRight {
+		at.Y = m.Rect.Max.Y - box.Dy()
+	}
+	box = box.Add(at)
+	Fill(m.SubImage(box.Intersect(m.Rect)).(*image.YCbCr), labelBackground)
+	for i, l := range lines {
+		Text(m, box.Min.Add(image.Pt(pad, pad+i*lineHeight*scale)), l, scale, White)
+	}
+}
+
+// FromImage converts an image, e.g. a decoded PNG or JPEG avatar, to
+// limited range 4:2:0 YCbCr like decoded video
+func FromImage(src image.Image) *image.YCbCr {
+	b := src.Bounds()
+	m := image.NewYCbCr(image.Rect(0, 0, b.Dx(), b.Dy()), image.YCbCrSubsampleRatio420)
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := Limited(color.YCbCrModel.Convert(src.At(b.Min.X+x, b.Min.Y+y)).(color.YCbCr))
+			m.Y[m.YOffset(x, y)] = c.Y
+			if x%2 == 0 && y%2 == 0 {
+				ci := m.COffset(x, y)
+				m.Cb[ci], m.Cr[ci] = c.Cb, c.Cr
+			}
+		}
+	}
+	return m
+}
+
+// Limited converts a full range colour, as image/color's, to the limited
+// range of video
+func Limited(c color.YCbCr) color.YCbCr {
+	return color.YCbCr{
+		Y:  uint8(16 + int(c.Y)*219/255),
+		Cb: uint8(128 + (int(c.Cb)-128)*224/255),
+		Cr: uint8(128 + (int(c.Cr)-128)*224/255),
+	}
+}
@@ -0,0 +1,86 @@
+package pixel
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	m := uniform(image.Rect(0, 0, 32, 16), Black)
+	Text(m, image.Pt(2, 2), "I", 1, White)
+	// The stem of the I
+	for y := 2; y < 9; y++ {
+		assert.Equal(t, White, m.YCbCrAt(4, y), "row %d", y)
+	}
+	assert.Equal(t, Black, m.YCbCrAt(2, 5))
+	assert.Equal(t, Black, m.YCbCrAt(4, 9))
+
+	assert.Equal(t, image.Pt(46, 14), TextSize("abcd", 2))
+	assert.Equal(t, image.Point{}, TextSize("", 2))
+
+	// Clipped to the image, and unprintable characters are drawn
+	Text(m, image.Pt(28, 12), "é", 2, White)
+}
+
+func TestLabel(t *testing.T) {
+	m := uniform(image.Rect(0, 0, 320, 180), Black)
+	Label(m, "Alice")
+	// A box at the bottom left, scale 2 for the height
+	assert.Equal(t, labelBackground, m.YCbCrAt(0, 179))
+	assert.Equal(t, labelBackground, m.YCbCrAt(0, 158))
+	assert.Equal(t, Black, m.YCbCrAt(0, 157))
+	assert.Equal(t, Black, m.YCbCrAt(70, 179))
+	// The stem of the A's left side
+	assert.Equal(t, White, m.YCbCrAt(4, 170))
+
+	// Long names are cut to the tile
+	small := uniform(image.Rect(0, 0, 40, 20), Black)
+	Label(small, "A very long display name")
+	assert.Equal(t, labelBackground, small.YCbCrAt(38, 19))
+}
+
+func TestOverlay(t *testing.T) {
+	m := uniform(image.Rect(0, 0, 320, 180), Black)
+	Overlay(m, BottomRight, 0, "01:02:03:04", "sid")
+	// Two lines at scale 1, the longest 11 characters wide
+	assert.Equal(t, labelBackground, m.YCbCrAt(319, 179))
+	assert.Equal(t, labelBackground, m.YCbCrAt(319-68, 179-19))
+	assert.Equal(t, Black, m.YCbCrAt(319-69, 179))
+	assert.Equal(t, Black, m.YCbCrAt(319, 179-20))
+
+	m = uniform(image.Rect(0, 0, 320, 180), Black)
+	Overlay(m, TopLeft, 2, "I")
+	assert.Equal(t, labelBackground, m.YCbCrAt(0, 0))
+	assert.Equal(t, White, m.YCbCrAt(8, 10))
+	assert.Equal(t, labelBackground, m.YCbCrAt(17, 0))
+	assert.Equal(t, Black, m.YCbCrAt(18, 0))
+
+	// Clipped to small frames
+	Overlay(uniform(image.Rect(0, 0, 8, 8), Black), BottomLeft, 0, "00:00:00:00")
+
+	c, err := ParseCorner("Bottom-Right")
+	assert.NoError(t, err)
+	assert.Equal(t, BottomRight, c)
+	_, err = ParseCorner("middle")
+	assert.Equal(t, ErrCorner, err)
+}
+
+func TestFromImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		src.Set(x, 10, color.White)
+		src.Set(x, 11, color.White)
+	}
+	src.Set(12, 10, color.Black)
+	src.Set(13, 10, color.Black)
+	src.Set(12, 11, color.Black)
+	src.Set(13, 11, color.Black)
+
+	m := FromImage(src)
+	assert.Equal(t, image.Rect(0, 0, 4, 2), m.Rect)
+	assert.Equal(t, White, m.YCbCrAt(0, 0))
+	assert.Equal(t, Black, m.YCbCrAt(3, 1))
+}
@@ -0,0 +1,18 @@
+//go:build libopus
+// +build libopus
+
+package podcast
+
+import (
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/opus"
+)
+
+func init() {
+	newDecoder = func() (elements.OpusPCMDecoder, error) {
+		return opus.NewDecoder(opus.SampleRate, 1)
+	}
+	newEncoder = func(bitrate int) (elements.OpusPCMEncoder, error) {
+		return opus.NewEncoder(opus.SampleRate, 1, bitrate)
+	}
+}
@@ -0,0 +1,394 @@
+// Package podcast renders the audio of a recording as a podcast episode,
+// an Ogg Opus file ready to publish. Its speech is brought to a common
+// level, silence at either end is trimmed and long pauses are shortened.
+// The episode is tagged with its title, artist and date, and with
+// chapters where the speaker changes, as Vorbis comments, the Ogg
+// equivalent of ID3 tags.
+//
+// Audio is decoded from the segments of the recording's first audio track
+// and encoded again, which needs the libopus build tag.
+package podcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/ingest"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/ogg"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/timeline"
+)
+
+var (
+	// ErrNoAudio is returned for recordings without audio
+	ErrNoAudio = errors.New("podcast: recording has no audio")
+	// ErrSilent is returned for recordings whose audio is all silence
+	ErrSilent = errors.New("podcast: recording is silent")
+	// ErrNoCodec is returned when built without an Opus codec
+	ErrNoCodec = errors.New("podcast: no Opus codec, build with libopus")
+)
+
+// newDecoder and newEncoder create the default codecs of Config, set when
+// built with libopus
+var (
+	newDecoder func() (elements.OpusPCMDecoder, error)
+	newEncoder func(bitrate int) (elements.OpusPCMEncoder, error)
+)
+
+const (
+	// vendor names the writer in the comment header
+	vendor = "ion-avp"
+	// block is the samples of the 20ms blocks audio is measured, trimmed
+	// and encoded in
+	block = opus.SampleRate / 50
+	// ceiling is the peak level audio is limited to, -1dBFS
+	ceiling = 0.891 * math.MaxInt16
+)
+
+// Config configures episodes
+type Config struct {
+	// Level: Target level of speech in dBFS, its RMS, defaults to -18
+	Level float64
+	// Silence: Level in dBFS below which audio is silence, defaults to -50
+	Silence float64
+	// MaxGain: Most the audio is turned up in dB, so the noise of quiet
+	// recordings isn't brought up with it, defaults to 20
+	MaxGain float64
+	// MaxPause: Longest pause kept, longer ones are shortened to it,
+	// defaults to 1s
+	MaxPause time.Duration
+	// Chapter: Shortest chapter, defaults to 5m. Chapters start where the
+	// speaker changes, from the recording's events, at least this long
+	// into the last.
+	Chapter time.Duration
+	// Bitrate: Of the episode in bits per second, defaults to 64kbps
+	Bitrate int
+	// Title: Of the episode, defaults to the recording's ID
+	Title string
+	// Artist: Of the episode, e.g. the show
+	Artist string
+	// Decoder: Creates the decoder of the recording's audio, decoding to
+	// mono, defaults to libopus's
+	Decoder func() (elements.OpusPCMDecoder, error)
+	// Encoder: Creates the encoder of the episode, defaults to libopus's
+	Encoder func(bitrate int) (elements.OpusPCMEncoder, error)
+}
+
+// Supported reports whether an episode can be written to a file of name,
+// by its extension
+func Supported(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".opus", ".ogg":
+		return true
+	}
+	return false
+}
+
+// Render writes the episode of the recording described by m to w.
+// progress, if not nil, is called with the fraction done, the recording
+// being read twice: to measure it, then to encode it.
+func Render(ctx context.Context, w io.Writer, m *manifest.Manifest, open manifest.OpenFunc, c Config, progress func(float64)) error {
+	if c.Level == 0 {
+		c.Level = -18
+	}
+	if c.Silence == 0 {
+		c.Silence = -50
+	}
+	if c.MaxGain <= 0 {
+		c.MaxGain = 20
+	}
+	if c.MaxPause <= 0 {
+		c.MaxPause = time.Second
+	}
+	if c.Chapter <= 0 {
+		c.Chapter = 5 * time.Minute
+	}
+	if c.Bitrate <= 0 {
+		c.Bitrate = 64000
+	}
+	if c.Title == "" {
+		c.Title = m.ID
+	}
+	if c.Decoder == nil {
+		c.Decoder = newDecoder
+	}
+	if c.Encoder == nil {
+		c.Encoder = newEncoder
+	}
+	if c.Decoder == nil || c.Encoder == nil {
+		return ErrNoCodec
+	}
+	tracks, err := ingest.FindTracks(m, open, ingest.KindAudio, 1)
+	if err != nil {
+		return err
+	}
+	if len(tracks) == 0 {
+		return ErrNoAudio
+	}
+	segs, track := tracks[0].Segments, tracks[0].ID
+	half := func(offset float64) func(float64) {
+		if progress == nil {
+			return nil
+		}
+		return func(p float64) { progress(offset + p/2) }
+	}
+
+	// Measure the level of each block
+	var power []float64
+	err = decode(ctx, segs, track, open, c.Decoder, half(0), func(pcm []int16) error {
+		power = append(power, meanSquare(pcm))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	silence := math.Pow(10, c.Silence/10) * -math.MinInt16 * -math.MinInt16
+	keep := trim(power, silence, int(c.MaxPause/(20*time.Millisecond)))
+	speech, n := 0.0, 0
+	for i, p := range power {
+		if keep[i] && p >= silence {
+			speech += p
+			n++
+		}
+	}
+	if n == 0 {
+		return ErrSilent
+	}
+	level := 10 * math.Log10(speech/float64(n)/(-math.MinInt16*-math.MinInt16))
+	gain := math.Pow(10, math.Min(c.Level-level, c.MaxGain)/20)
+
+	chapters, err := chapters(m, open, keep, c.Chapter)
+	if err != nil {
+		return err
+	}
+	comments := []string{"TITLE=" + c.Title}
+	if c.Artist != "" {
+		comments = append(comments, "ARTIST="+c.Artist)
+	}
+	if !m.Start.IsZero() {
+		comments = append(comments, "DATE="+m.Start.UTC().Format("2006-01-02"))
+	}
+	comments = append(comments, chapters...)
+
+	enc, err := c.Encoder(c.Bitrate)
+	if err != nil {
+		return err
+	}
+	defer enc.Close()
+	e := &episode{ogg: ogg.NewWriter(w, rand.Uint32())}
+	if err := e.ogg.WritePage(ogg.First, 0, ogg.OpusHead(1, 0)); err != nil {
+		return err
+	}
+	if err := e.ogg.WritePage(0, 0, ogg.OpusTags(vendor, comments...)); err != nil {
+		return err
+	}
+	i := 0
+	err = decode(ctx, segs, track, open, c.Decoder, half(0.5), func(pcm []int16) error {
+		defer func() { i++ }()
+		if i >= len(keep) || !keep[i] {
+			return nil
+		}
+		packet, err := enc.Encode(amplify(pcm, gain))
+		if err != nil {
+			return err
+		}
+		return e.write(packet)
+	})
+	if err != nil {
+		return err
+	}
+	return e.close()
+}
+
+// episode writes the packets of an episode a page each, one behind so the
+// last can be marked as such
+type episode struct {
+	ogg     *ogg.Writer
+	granule uint64
+	last    []byte
+}
+
+func (e *episode) write(packet []byte) error {
+	if e.last != nil {
+		if err := e.ogg.WritePage(0, e.granule, e.last); err != nil {
+			return err
+		}
+	}
+	e.last = packet
+	e.granule += block
+	return nil
+}
+
+func (e *episode) close() error {
+	return e.ogg.WritePage(ogg.Last, e.granule, e.last)
+}
+
+// decode calls f with each 20ms block of the track's audio, decoded to
+// mono. Gaps, such as silence not sent, are filled with silence.
+func decode(ctx context.Context, segs []manifest.Segment, track string, open manifest.OpenFunc, newDecoder func() (elements.OpusPCMDecoder, error), progress func(float64), f func([]int16) error) error {
+	dec, err := newDecoder()
+	if err != nil {
+		return err
+	}
+	defer dec.Close()
+	pcm := make([]int16, opus.MaxFrame)
+	var pending []int16
+	var written int64 // samples decoded
+	for i, seg := range segs {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		media, err := ingest.DemuxSegment(open, seg.Name)
+		if err != nil {
+			return err
+		}
+		for _, sample := range media.Samples {
+			payload, ok := sample.Payload.([]byte)
+			if sample.ID != track || !ok {
+				continue
+			}
+			at := seg.Offset + time.Duration(int64(sample.Timestamp)*int64(time.Second)/int64(sample.ClockRate))
+			pos := int64(at * opus.SampleRate / time.Second)
+			// Timestamps within half a block are taken as contiguous
+			switch {
+			case pos > written+block/2:
+				pending = append(pending, make([]int16, pos-written)...)
+				written = pos
+			case pos < written-block/2:
+				// Overlaps audio already decoded
+				continue
+			}
+			n, err := dec.Decode(payload, pcm)
+			if err != nil {
+				return fmt.Errorf("podcast: segment %s: %w", seg.Name, err)
+			}
+			pending = append(pending, pcm[:n]...)
+			written += int64(n)
+			for len(pending) >= block {
+				if err := f(pending[:block]); err != nil {
+					return err
+				}
+				pending = pending[block:]
+			}
+		}
+		if progress != nil {
+			progress(float64(i+1) / float64(len(segs)))
+		}
+	}
+	if len(pending) > 0 {
+		return f(append(pending, make([]int16, block-len(pending))...))
+	}
+	return nil
+}
+
+// trim returns which blocks of the episode are kept: from the first to
+// the last with audio louder than silence, with pauses longer than
+// maxPause blocks cut down to their start and end
+func trim(power []float64, silence float64, maxPause int) []bool {
+	keep := make([]bool, len(power))
+	first, last := -1, -1
+	for i, p := range power {
+		if p >= silence {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return keep
+	}
+	pause := 0
+	for i := first; i <= last; i++ {
+		keep[i] = true
+		if power[i] >= silence {
+			if pause > maxPause {
+				// Keep the tail of the last speech and the lead in to
+				// this, dropping the middle
+				for j := i - pause + maxPause/2; j < i-(maxPause-maxPause/2); j++ {
+					keep[j] = false
+				}
+			}
+			pause = 0
+			continue
+		}
+		pause++
+	}
+	return keep
+}
+
+// chapters returns the chapter comments of the episode, a chapter
+// starting at each change of speaker at least min into the last.
+// Recordings without changes have none.
+func chapters(m *manifest.Manifest, open manifest.OpenFunc, keep []bool, min time.Duration) ([]string, error) {
+	ev, err := timeline.ReadEvents(m, open)
+	if err != nil {
+		return nil, err
+	}
+	// Times in the episode, less the audio trimmed
+	kept := make([]int, len(keep)+1)
+	for i, k := range keep {
+		kept[i+1] = kept[i]
+		if k {
+			kept[i+1]++
+		}
+	}
+	starts := []time.Duration{0}
+	for _, s := range ev.Speakers {
+		i := int(s.At / (20 * time.Millisecond))
+		if i > len(keep) {
+			i = len(keep)
+		}
+		if at := time.Duration(kept[i]) * 20 * time.Millisecond; at-starts[len(starts)-1] >= min {
+			starts = append(starts, at)
+		}
+	}
+	if len(starts) == 1 {
+		return nil, nil
+	}
+	var comments []string
+	for i, at := range starts {
+		ms := int64(at / time.Millisecond)
+		comments = append(comments,
+			fmt.Sprintf("CHAPTER%03d=%02d:%02d:%02d.%03d", i+1, ms/3600000, ms/60000%60, ms/1000%60, ms%1000),
+			fmt.Sprintf("CHAPTER%03dNAME=Chapter %d", i+1, i+1))
+	}
+	return comments, nil
+}
+
+// meanSquare of a block of audio, its power
+func meanSquare(pcm []int16) float64 {
+	sum := 0.0
+	for _, v := range pcm {
+		sum += float64(v) * float64(v)
+	}
+	return sum / float64(len(pcm))
+}
+
+// amplify returns a block turned up by gain, less if that would take it
+// over the ceiling
+func amplify(pcm []int16, gain float64) []int16 {
+	peak := 0.0
+	for _, v := range pcm {
+		peak = math.Max(peak, math.Abs(float64(v)))
+	}
+	if peak*gain > ceiling {
+		gain = ceiling / peak
+	}
+	out := make([]int16, len(pcm))
+	for i, v := range pcm {
+		out[i] = int16(math.Round(float64(v) * gain))
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/manifest/manifesttest"
+	"github.com/pion/ion-avp/pkg/timeline"
+	"github.com/stretchr/testify/assert"
+)
+
+// levelDecoder decodes packets to 20ms at 100 times their first byte
+type levelDecoder struct{}
+
+func (levelDecoder) Decode(packet []byte, pcm []int16) (int, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty")
+	}
+	for i := 0; i < block; i++ {
+		pcm[i] = 100 * int16(int8(packet[0]))
+	}
+	return block, nil
+}
+
+func (levelDecoder) Close() {}
+
+// pcmEncoder keeps the frames it's given
+type pcmEncoder struct {
+	frames [][]int16
+}
+
+func (e *pcmEncoder) Encode(pcm []int16) ([]byte, error) {
+	e.frames = append(e.frames, pcm)
+	return []byte{byte(len(e.frames))}, nil
+}
+
+func (e *pcmEncoder) Close() {}
+
+// segment has a 20ms packet of each level, those of -1 not sent
+func segment(t *testing.T, levels []int8) []byte {
+	var blocks []manifesttest.Block
+	for i, l := range levels {
+		if l >= 0 {
+			blocks = append(blocks, manifesttest.Block{Track: 1, Time: int64(20 * i), Data: []byte{byte(l)}})
+		}
+	}
+	return manifesttest.Segment(t, []webm.TrackEntry{manifesttest.Mic}, blocks)
+}
+
+// levels returns n blocks of level l
+func levels(n int, l int8) []int8 {
+	ls := make([]int8, n)
+	for i := range ls {
+		ls[i] = l
+	}
+	return ls
+}
+
+// recording is a second of silence, a second of speech, a two second
+// pause and a second of louder speech, then in the next segment a second
+// of silence, mostly not sent, and another of speech
+func recording(t *testing.T) (*manifest.Manifest, manifest.OpenFunc) {
+	var first []int8
+	for _, ls := range [][]int8{levels(50, 0), levels(50, 10), levels(100, 0), levels(50, 20)} {
+		first = append(first, ls...)
+	}
+	events, err := json.Marshal(timeline.Events{Speakers: []timeline.Switch{
+		{At: 0, Track: "alice"},
+		{At: 4 * time.Second, Track: "bob"},
+		{At: 6 * time.Second, Track: "alice"},
+	}})
+	assert.NoError(t, err)
+	files := manifesttest.Files{
+		"0.webm":      segment(t, first),
+		"1.webm":      segment(t, append(append(levels(1, 0), levels(49, -1)...), levels(50, 10)...)),
+		"events.json": events,
+	}
+	m := manifest.New("rec", "sid", time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC))
+	m.Segments = manifesttest.Consecutive("alice", 5*time.Second, "0.webm", "1.webm")
+	m.Sidecars = []manifest.Sidecar{{File: manifest.File{Name: "events.json"}, Kind: timeline.EventsKind}}
+	return m, files.Open
+}
+
+func TestSupported(t *testing.T) {
+	assert.True(t, Supported("episode.opus"))
+	assert.True(t, Supported("episode.OGG"))
+	assert.False(t, Supported("out.webm"))
+}
+
+func TestRender(t *testing.T) {
+	m, open := recording(t)
+	enc := &pcmEncoder{}
+	c := Config{
+		Chapter: time.Second,
+		Artist:  "The Show",
+		Decoder: func() (elements.OpusPCMDecoder, error) { return levelDecoder{}, nil },
+		Encoder: func(int) (elements.OpusPCMEncoder, error) { return enc, nil },
+	}
+	var progress []float64
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Render(context.Background(), buf, m, open, c, func(p float64) {
+		progress = append(progress, p)
+	}))
+	assert.Equal(t, []float64{0.25, 0.5, 0.75, 1}, progress)
+
+	// The leading second of silence is trimmed and the pause halved
+	if assert.Len(t, enc.frames, 250) {
+		// Speech of 1000 and 2000 is turned up to an RMS of -18dBFS
+		gain := math.Pow(10, (-18-10*math.Log10(2e6/(32768*32768)))/20)
+		assert.InDelta(t, 1000*gain, enc.frames[0][0], 1)
+		assert.Equal(t, int16(0), enc.frames[50][0])
+		assert.InDelta(t, 2000*gain, enc.frames[100][0], 1)
+		assert.Equal(t, int16(0), enc.frames[150][0])
+		assert.InDelta(t, 1000*gain, enc.frames[249][block-1], 1)
+	}
+	out := buf.String()
+	assert.Equal(t, "OggS", out[:4])
+	for _, tag := range []string{
+		"TITLE=rec", "ARTIST=The Show", "DATE=2021-02-01",
+		"CHAPTER001=00:00:00.000", "CHAPTER002=00:00:02.000", "CHAPTER003=00:00:04.000", "CHAPTER003NAME=Chapter 3",
+	} {
+		assert.Contains(t, out, tag)
+	}
+
+	// Loud speech is limited rather than clipped
+	enc.frames = nil
+	c.Level, c.MaxGain = -1, 40
+	assert.NoError(t, Render(context.Background(), &bytes.Buffer{}, m, open, c, nil))
+	assert.InDelta(t, ceiling, enc.frames[100][0], 1)
+	assert.Less(t, float64(enc.frames[0][0]), ceiling)
+
+	m.Segments = nil
+	assert.Equal(t, ErrNoAudio, Render(context.Background(), buf, m, open, c, nil))
+}
+
+func TestTrim(t *testing.T) {
+	power := []float64{0, 0, 1, 0, 0, 0, 0, 0, 1, 0, 1, 0}
+	assert.Equal(t, []bool{false, false, true, true, false, false, false, true, true, true, true, false}, trim(power, 1, 2))
+	assert.Equal(t, make([]bool, 3), trim([]float64{0, 0, 0}, 1, 2))
+}
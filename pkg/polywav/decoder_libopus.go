@@ -0,0 +1,15 @@
+//go:build libopus
+// +build libopus
+
+package polywav
+
+import (
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/opus"
+)
+
+func init() {
+	newDecoder = func(channels int) (elements.OpusPCMDecoder, error) {
+		return opus.NewDecoder(opus.SampleRate, channels)
+	}
+}
@@ -0,0 +1,238 @@
+// Package polywav renders the audio of a recording for audio
+// post-production, each participant's track kept apart rather than mixed.
+//
+// A .wav output is a polyphonic Broadcast Wave file, a poly WAV, with a
+// channel pair per participant. A .zip output holds a Broadcast Wave file
+// per participant instead. Every track spans the whole recording, silence
+// filling where a participant wasn't sending, and carries the timecode of
+// its start, the recording's UTC time of day, in its bext chunk so editors
+// line the files up. Tracks are named in an iXML chunk.
+//
+// Audio is decoded from the segments of the recording's tracks, which
+// needs the libopus build tag.
+package polywav
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/ingest"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/opus"
+	"github.com/pion/ion-avp/pkg/wav"
+)
+
+var (
+	// ErrUnsupported is returned for outputs that aren't .wav or .zip
+	ErrUnsupported = errors.New("polywav: unsupported format")
+	// ErrNoAudio is returned for recordings without audio
+	ErrNoAudio = errors.New("polywav: recording has no audio")
+	// ErrNoDecoder is returned when built without an Opus decoder
+	ErrNoDecoder = errors.New("polywav: no Opus decoder, build with libopus")
+)
+
+// newDecoder creates the default decoder of Config.Decoder, set when built
+// with libopus
+var newDecoder func(channels int) (elements.OpusPCMDecoder, error)
+
+const (
+	// originator names the writer in the bext chunk
+	originator = "ion-avp"
+	// chunk is the frames of each track interleaved at a time, 100ms
+	chunk = opus.SampleRate / 10
+	// block is the frames of an Opus packet's tolerance to timestamp jitter
+	block = opus.SampleRate / 50
+)
+
+// Config configures exports
+type Config struct {
+	// Channels: Of each participant, 2 for a channel pair or 1 for mono,
+	// defaults to 2
+	Channels int
+	// Decoder: Creates the decoder of a track's audio, defaults to
+	// libopus's
+	Decoder func(channels int) (elements.OpusPCMDecoder, error)
+}
+
+// Supported reports whether a recording can be exported to a file of
+// name, by its extension
+func Supported(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".wav", ".zip":
+		return true
+	}
+	return false
+}
+
+// Render writes the audio of the recording described by m to w. name is
+// the output file, whose extension selects a poly WAV or a zip of WAV
+// files. progress, if not nil, is called with the fraction done.
+func Render(ctx context.Context, w io.Writer, name string, m *manifest.Manifest, open manifest.OpenFunc, c Config, progress func(float64)) error {
+	ext := strings.ToLower(path.Ext(name))
+	if !Supported(ext) {
+		return fmt.Errorf("%w: %s", ErrUnsupported, name)
+	}
+	if c.Channels != 1 {
+		c.Channels = 2
+	}
+	if c.Decoder == nil {
+		c.Decoder = newDecoder
+	}
+	if c.Decoder == nil {
+		return ErrNoDecoder
+	}
+	tracks, err := ingest.FindTracks(m, open, ingest.KindAudio, 0)
+	if err != nil {
+		return err
+	}
+	if len(tracks) == 0 {
+		return ErrNoAudio
+	}
+	frames := int64(m.Duration() * opus.SampleRate / time.Second)
+	bext := wav.Bext{
+		Description:   m.ID,
+		Originator:    originator,
+		Origination:   m.Start.UTC(),
+		TimeReference: timeReference(m.Start.UTC()),
+	}
+
+	if ext == ".wav" {
+		readers := make([]*trackReader, len(tracks))
+		for i, t := range tracks {
+			if readers[i], err = newTrackReader(open, t, c); err != nil {
+				return err
+			}
+			defer readers[i].close()
+		}
+		return write(ctx, w, readers, frames, bext, c.Channels, func(p float64) {
+			if progress != nil {
+				progress(p)
+			}
+		})
+	}
+
+	z := zip.NewWriter(w)
+	for i, t := range tracks {
+		f, err := z.CreateHeader(&zip.FileHeader{Name: fileName(t.Name) + ".wav", Method: zip.Store, Modified: m.Start})
+		if err != nil {
+			return err
+		}
+		r, err := newTrackReader(open, t, c)
+		if err != nil {
+			return err
+		}
+		err = write(ctx, f, []*trackReader{r}, frames, bext, c.Channels, func(p float64) {
+			if progress != nil {
+				progress((float64(i) + p) / float64(len(tracks)))
+			}
+		})
+		r.close()
+		if err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// write writes a WAV file of frames of the tracks of readers, interleaved
+func write(ctx context.Context, w io.Writer, readers []*trackReader, frames int64, bext wav.Bext, channels int, progress func(float64)) error {
+	width := len(readers) * channels
+	size := uint32(wav.Unknown)
+	if n := frames * int64(width) * 2; n < wav.Unknown {
+		size = uint32(n)
+	}
+	names := make([]string, len(readers))
+	for i, r := range readers {
+		names[i] = r.Name
+	}
+	ixml, err := trackList(names, channels)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(wav.BroadcastHeader(opus.SampleRate, width, size, bext, ixml)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunk*width*2)
+	for done := int64(0); done < frames; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		n := int64(chunk)
+		if frames-done < n {
+			n = frames - done
+		}
+		for i, r := range readers {
+			pcm, err := r.read(int(n))
+			if err != nil {
+				return err
+			}
+			for f := 0; f < int(n); f++ {
+				for ch := 0; ch < channels; ch++ {
+					v := uint16(pcm[f*channels+ch])
+					at := (f*width + i*channels + ch) * 2
+					buf[at], buf[at+1] = byte(v), byte(v>>8)
+				}
+			}
+		}
+		if _, err := w.Write(buf[:n*int64(width)*2]); err != nil {
+			return err
+		}
+		done += n
+		progress(float64(done) / float64(frames))
+	}
+	return nil
+}
+
+// timeReference is the samples from midnight to t
+func timeReference(t time.Time) uint64 {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return uint64(t.Sub(midnight) * opus.SampleRate / time.Second)
+}
+
+// fileName returns a track's name fit for a file in the zip
+func fileName(track string) string {
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(track)
+}
+
+// ixmlTrack is a channel in the TRACK_LIST of an iXML chunk
+type ixmlTrack struct {
+	ChannelIndex    int    `xml:"CHANNEL_INDEX"`
+	InterleaveIndex int    `xml:"INTERLEAVE_INDEX"`
+	Name            string `xml:"NAME"`
+}
+
+// trackList returns the iXML chunk naming the channels of tracks
+func trackList(tracks []string, channels int) ([]byte, error) {
+	doc := struct {
+		XMLName xml.Name    `xml:"BWFXML"`
+		Version string      `xml:"IXML_VERSION"`
+		Count   int         `xml:"TRACK_LIST>TRACK_COUNT"`
+		Tracks  []ixmlTrack `xml:"TRACK_LIST>TRACK"`
+	}{Version: "1.61", Count: len(tracks) * channels}
+	for _, t := range tracks {
+		for ch := 0; ch < channels; ch++ {
+			name := t
+			if channels == 2 {
+				name += [...]string{" L", " R"}[ch]
+			}
+			i := len(doc.Tracks) + 1
+			doc.Tracks = append(doc.Tracks, ixmlTrack{ChannelIndex: i, InterleaveIndex: i, Name: name})
+		}
+	}
+	b, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
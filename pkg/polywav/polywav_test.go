@@ -0,0 +1,143 @@
+package polywav
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/manifest/manifesttest"
+	"github.com/stretchr/testify/assert"
+)
+
+// levelDecoder decodes packets to 20ms at 100 times their first byte,
+// negated on the right
+type levelDecoder struct {
+	channels int
+}
+
+func (d levelDecoder) Decode(packet []byte, pcm []int16) (int, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty")
+	}
+	for i := 0; i < block*d.channels; i++ {
+		pcm[i] = 100 * int16(packet[0])
+		if i%d.channels == 1 {
+			pcm[i] = -pcm[i]
+		}
+	}
+	return block, nil
+}
+
+func (levelDecoder) Close() {}
+
+// segment has n 20ms packets of level
+func segment(t *testing.T, n int, level byte) []byte {
+	return manifesttest.Segment(t, []webm.TrackEntry{manifesttest.Mic}, manifesttest.Every(1, n, 20, func(int) []byte {
+		return []byte{level}
+	}))
+}
+
+// recording has 1.5s of Alice over 2s, loud after the first second, and
+// half a second of Bob from 500ms
+func recording(t *testing.T) (*manifest.Manifest, manifest.OpenFunc) {
+	files := manifesttest.Files{
+		"alice/0.webm": segment(t, 50, 10),
+		"alice/1.webm": segment(t, 25, 20),
+		"bob/0.webm":   segment(t, 25, 30),
+	}
+	m := manifest.New("rec", "sid", time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC))
+	m.Segments = []manifest.Segment{
+		{File: manifest.File{Name: "alice/0.webm"}, Track: "alice", Duration: time.Second},
+		{File: manifest.File{Name: "alice/1.webm"}, Track: "alice", Seq: 1, Offset: time.Second, Duration: time.Second},
+		{File: manifest.File{Name: "bob/0.webm"}, Track: "bob", Offset: 500 * time.Millisecond, Duration: 500 * time.Millisecond},
+	}
+	return m, files.Open
+}
+
+// chunks returns the chunks of a WAV file by id
+func chunks(t *testing.T, b []byte) map[string][]byte {
+	cs := make(map[string][]byte)
+	assert.Equal(t, "WAVE", string(b[8:12]))
+	for b = b[12:]; len(b) >= 8; {
+		n := int(binary.LittleEndian.Uint32(b[4:]))
+		cs[string(b[:4])] = b[8 : 8+n]
+		b = b[8+n+n%2:]
+	}
+	return cs
+}
+
+func TestRender(t *testing.T) {
+	m, open := recording(t)
+	c := Config{Decoder: func(channels int) (elements.OpusPCMDecoder, error) { return levelDecoder{channels}, nil }}
+	var progress []float64
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Render(context.Background(), buf, "rec.wav", m, open, c, func(p float64) {
+		progress = append(progress, p)
+	}))
+	assert.Len(t, progress, 20)
+	assert.Equal(t, 1.0, progress[19])
+
+	cs := chunks(t, buf.Bytes())
+	assert.Equal(t, uint16(4), binary.LittleEndian.Uint16(cs["fmt "][2:]))
+	assert.Equal(t, uint64(36000*48000), binary.LittleEndian.Uint64(cs["bext"][338:]))
+	assert.Contains(t, string(cs["iXML"]), "<TRACK_COUNT>4</TRACK_COUNT>")
+	assert.Contains(t, string(cs["iXML"]), "<CHANNEL_INDEX>3</CHANNEL_INDEX><INTERLEAVE_INDEX>3</INTERLEAVE_INDEX><NAME>bob L</NAME>")
+	data := cs["data"]
+	assert.Len(t, data, 2*48000*4*2)
+	at := func(frame, channel int) int16 {
+		return int16(binary.LittleEndian.Uint16(data[(frame*4+channel)*2:]))
+	}
+	for _, f := range []struct {
+		frame, channel int
+		want           int16
+	}{
+		{0, 0, 1000}, {0, 1, -1000}, {0, 2, 0},
+		{30000, 0, 1000}, {30000, 2, 3000}, {30000, 3, -3000},
+		{60000, 0, 2000}, {60000, 1, -2000}, {60000, 2, 0},
+		{95999, 0, 0}, {95999, 3, 0},
+	} {
+		assert.Equal(t, f.want, at(f.frame, f.channel), "frame %d channel %d", f.frame, f.channel)
+	}
+
+	// A file per participant
+	buf.Reset()
+	progress = nil
+	assert.NoError(t, Render(context.Background(), buf, "rec.zip", m, open, c, func(p float64) {
+		progress = append(progress, p)
+	}))
+	assert.Equal(t, 0.025, progress[0])
+	assert.Equal(t, 1.0, progress[len(progress)-1])
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	if assert.Len(t, z.File, 2) {
+		assert.Equal(t, "alice.wav", z.File[0].Name)
+		assert.Equal(t, "bob.wav", z.File[1].Name)
+		r, err := z.File[1].Open()
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		cs = chunks(t, b)
+		assert.Equal(t, uint16(2), binary.LittleEndian.Uint16(cs["fmt "][2:]))
+		assert.Equal(t, uint64(36000*48000), binary.LittleEndian.Uint64(cs["bext"][338:]))
+		assert.Len(t, cs["data"], 2*48000*2*2)
+		assert.Equal(t, int16(3000), int16(binary.LittleEndian.Uint16(cs["data"][30000*4:])))
+	}
+
+	// Mono
+	buf.Reset()
+	c.Channels = 1
+	assert.NoError(t, Render(context.Background(), buf, "rec.wav", m, open, c, nil))
+	assert.Len(t, chunks(t, buf.Bytes())["data"], 2*48000*2*2)
+
+	assert.True(t, errors.Is(Render(context.Background(), buf, "rec.mp3", m, open, c, nil), ErrUnsupported))
+	m.Segments = nil
+	assert.Equal(t, ErrNoAudio, Render(context.Background(), buf, "rec.wav", m, open, c, nil))
+}
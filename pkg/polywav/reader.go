@@ -0,0 +1,120 @@
+package polywav
+
+import (
+	"fmt"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/ingest"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/opus"
+)
+
+// trackReader decodes the audio of a track from the start of the
+// recording, a segment at a time, filling gaps with silence
+type trackReader struct {
+	ingest.SegmentTrack
+	open     manifest.OpenFunc
+	channels int
+	dec      elements.OpusPCMDecoder
+	pcm      []int16
+
+	seg     int           // of the segment to read next
+	offset  time.Duration // of the segment being read
+	samples []*avp.Sample // left of it
+	pending []int16       // decoded, not yet read
+	end     int64         // frame after those decoded
+}
+
+func newTrackReader(open manifest.OpenFunc, t ingest.SegmentTrack, c Config) (*trackReader, error) {
+	dec, err := c.Decoder(c.Channels)
+	if err != nil {
+		return nil, err
+	}
+	return &trackReader{
+		SegmentTrack: t,
+		open:         open,
+		channels:     c.Channels,
+		dec:          dec,
+		pcm:          make([]int16, opus.MaxFrame*c.Channels),
+	}, nil
+}
+
+// read returns the next frames of the track
+func (r *trackReader) read(frames int) ([]int16, error) {
+	n := frames * r.channels
+	for len(r.pending) < n {
+		sample, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if sample == nil {
+			// Silence to the end of the recording
+			r.pending = append(r.pending, make([]int16, n-len(r.pending))...)
+			break
+		}
+		if err := r.decode(sample); err != nil {
+			return nil, err
+		}
+	}
+	pcm := r.pending[:n:n]
+	r.pending = r.pending[n:]
+	return pcm, nil
+}
+
+// next returns the track's next sample, nil after the last
+func (r *trackReader) next() (*avp.Sample, error) {
+	for len(r.samples) == 0 {
+		if r.seg == len(r.Segments) {
+			return nil, nil
+		}
+		seg := r.Segments[r.seg]
+		media, err := ingest.DemuxSegment(r.open, seg.Name)
+		if err != nil {
+			return nil, err
+		}
+		r.samples = r.samples[:0]
+		for _, s := range media.Samples {
+			if s.ID == r.ID {
+				r.samples = append(r.samples, s)
+			}
+		}
+		r.offset = seg.Offset
+		r.seg++
+	}
+	s := r.samples[0]
+	r.samples = r.samples[1:]
+	return s, nil
+}
+
+// decode decodes a sample after those decoded, at its time in the
+// recording
+func (r *trackReader) decode(sample *avp.Sample) error {
+	payload, ok := sample.Payload.([]byte)
+	if !ok {
+		return nil
+	}
+	at := r.offset + time.Duration(int64(sample.Timestamp)*int64(time.Second)/int64(sample.ClockRate))
+	pos := int64(at * opus.SampleRate / time.Second)
+	// Timestamps within half a packet are taken as contiguous
+	switch {
+	case pos > r.end+block/2:
+		r.pending = append(r.pending, make([]int16, (pos-r.end)*int64(r.channels))...)
+		r.end = pos
+	case pos < r.end-block/2:
+		// Overlaps audio already decoded
+		return nil
+	}
+	n, err := r.dec.Decode(payload, r.pcm)
+	if err != nil {
+		return fmt.Errorf("polywav: track %s: %w", r.Name, err)
+	}
+	r.pending = append(r.pending, r.pcm[:n*r.channels]...)
+	r.end += int64(n)
+	return nil
+}
+
+func (r *trackReader) close() {
+	r.dec.Close()
+}
@@ -0,0 +1,264 @@
+package avp
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/colorspace"
+	log "github.com/pion/ion-log"
+)
+
+// Priority decides which pipelines degrade first when a node can't keep
+// up, e.g. live restreams over recordings over snapshots and ML.
+type Priority int
+
+// Priority classes
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	}
+	return "normal"
+}
+
+// queueSize is the number of samples buffered per element. Higher
+// priorities ride out longer stalls before dropping. Low memory mode
+// shrinks every queue.
+func (p Priority) queueSize() int {
+	switch p {
+	case PriorityLow:
+		return 25
+	case PriorityHigh:
+		return 400
+	}
+	return 100
+}
+
+// shedAt is the node pressure from which samples are dropped before
+// reaching the element, so lower priorities make room for higher ones.
+// High priority elements only drop when their own queue is full.
+func (p Priority) shedAt() float64 {
+	switch p {
+	case PriorityLow:
+		return 0.25
+	case PriorityHigh:
+		return 2
+	}
+	return 0.5
+}
+
+// Prioritized is implemented by elements with a priority other than normal
+type Prioritized interface {
+	Priority() Priority
+}
+
+type prioritized struct {
+	Element
+	priority Priority
+}
+
+func (p *prioritized) Priority() Priority {
+	return p.priority
+}
+
+func (p *prioritized) WriteContext(ctx context.Context, s *Sample) error {
+	return WriteContext(ctx, p.Element, s)
+}
+
+// WithPriority sets the priority of an element
+func WithPriority(e Element, p Priority) Element {
+	if p == PriorityNormal {
+		return e
+	}
+	return &prioritized{Element: e, priority: p}
+}
+
+// unwrap returns the element wrapped by WithPriority and WithCorrelation
+func unwrap(e Element) Element {
+	for {
+		switch w := e.(type) {
+		case *prioritized:
+			e = w.Element
+		case *correlated:
+			e = w.Element
+		default:
+			return e
+		}
+	}
+}
+
+func priorityOf(e Element) Priority {
+	if p, ok := e.(Prioritized); ok {
+		return p.Priority()
+	}
+	return PriorityNormal
+}
+
+// load tracks samples queued to elements across the node
+var load struct {
+	queued   int64
+	capacity int64
+}
+
+// Pressure returns how backed up element queues are on this node,
+// from 0 when idle to 1 when every queue is full
+func Pressure() float64 {
+	c := atomic.LoadInt64(&load.capacity)
+	if c == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&load.queued)) / float64(c)
+}
+
+// Overloaded reports whether the node is shedding samples of new elements
+// of a priority
+func Overloaded(p Priority) bool {
+	return Pressure() >= p.shedAt()
+}
+
+// elementQueue feeds samples to an element from its own goroutine, so a
+// slow element doesn't hold up others on the same track
+type elementQueue struct {
+	// usage is updated atomically, so it comes first to be 64-bit aligned
+	// on 32-bit platforms
+	usage    usage
+	e        Element
+	priority Priority
+	ch       chan *Sample
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	// keyframe, if set, asks the sender for a keyframe once video is
+	// dropped
+	keyframe func()
+	resync   bool // dropping video until a keyframe
+}
+
+// drainTimeout is how long a stopping queue waits for its element to
+// catch up before canceling its writes
+var drainTimeout = 10 * time.Second
+
+func newElementQueue(e Element) *elementQueue {
+	p := priorityOf(e)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &elementQueue{
+		e:        e,
+		priority: p,
+		ch:       make(chan *Sample, queueCap(p.queueSize())),
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (q *elementQueue) start() {
+	atomic.AddInt64(&load.capacity, int64(cap(q.ch)))
+	go q.run()
+}
+
+func (q *elementQueue) run() {
+	defer close(q.done)
+	for s := range q.ch {
+		atomic.AddInt64(&load.queued, -1)
+		if err := q.usage.measure(q.ctx, q.e, s); err != nil && q.ctx.Err() == nil {
+			log.Errorf("error writing sample: %s", err)
+		}
+	}
+}
+
+// push queues a sample, dropping it if the queue is full or the node is
+// under more pressure than the element's priority tolerates. Once a video
+// frame is dropped, those after it are too until a keyframe, so decoders
+// and savers never see frames that reference a missing one.
+func (q *elementQueue) push(s *Sample) {
+	key := keyframe(s)
+	if (key || !q.resync) && Pressure() < q.priority.shedAt() {
+		select {
+		case q.ch <- s:
+			atomic.AddInt64(&load.queued, 1)
+			if key {
+				q.resync = false
+			}
+			return
+		default:
+		}
+	}
+	if video(s) && (key || !q.resync) {
+		q.resync = true
+		if q.keyframe != nil {
+			q.keyframe()
+		}
+	}
+	if dropped := atomic.AddUint64(&q.usage.dropped, 1); dropped%100 == 1 {
+		log.Warnf("dropping samples for %s priority element, %d dropped", q.priority, dropped)
+	}
+}
+
+// video reports whether a sample is an encoded video frame
+func video(s *Sample) bool {
+	switch s.Type {
+	case TypeVP8, TypeVP9, TypeH264, TypeAV1:
+		return true
+	}
+	return false
+}
+
+// keyframe reports whether a sample can be decoded without those before
+// it, as all but inter video frames can
+func keyframe(s *Sample) bool {
+	if !video(s) {
+		return true
+	}
+	payload, ok := s.Payload.([]byte)
+	if !ok || len(payload) == 0 {
+		return false
+	}
+	switch s.Type {
+	case TypeVP8:
+		return payload[0]&0x1 == 0
+	case TypeVP9:
+		_, err := colorspace.ParseVP9(payload)
+		return err == nil
+	case TypeAV1:
+		_, err := colorspace.ParseAV1(payload)
+		return err == nil
+	}
+	for _, nal := range colorspace.SplitAnnexB(payload) {
+		if len(nal) > 0 && nal[0]&0x1f == nalIDR {
+			return true
+		}
+	}
+	return false
+}
+
+// nalIDR is the H.264 NAL unit type of keyframe slices
+const nalIDR = 5
+
+// stop drains the queue and waits for the element to catch up, canceling
+// the writes of context elements that take too long
+func (q *elementQueue) stop() {
+	close(q.ch)
+	select {
+	case <-q.done:
+	case <-time.After(drainTimeout):
+		log.Warnf("%s priority element not draining, canceling its writes", q.priority)
+		q.cancel()
+		<-q.done
+	}
+	atomic.AddInt64(&load.capacity, -int64(cap(q.ch)))
+}
+
+// close closes the element once stopped
+func (q *elementQueue) close() {
+	q.e.Close()
+	q.cancel()
+}
@@ -0,0 +1,168 @@
+package avp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingElement struct {
+	release chan struct{}
+	written int
+}
+
+func (e *blockingElement) Write(*Sample) error {
+	<-e.release
+	e.written++
+	return nil
+}
+
+func (e *blockingElement) Attach(Element) {}
+
+func (e *blockingElement) Close() {}
+
+type stuckElement struct {
+	blockingElement
+}
+
+func (e *stuckElement) WriteContext(ctx context.Context, s *Sample) error {
+	select {
+	case <-e.release:
+		return e.Write(s)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestElementQueue_Priority(t *testing.T) {
+	release := make(chan struct{})
+	lowElem := &blockingElement{release: release}
+	highElem := &blockingElement{release: release}
+
+	low := newElementQueue(WithPriority(lowElem, PriorityLow))
+	high := newElementQueue(WithPriority(highElem, PriorityHigh))
+	assert.Equal(t, PriorityLow, low.priority)
+	assert.Equal(t, PriorityHigh, high.priority)
+	low.start()
+
+	// The stalled low priority element sheds load once the node is under pressure
+	for i := 0; i < 200; i++ {
+		low.push(&Sample{})
+	}
+	assert.True(t, int(low.usage.dropped) > 0)
+	assert.True(t, len(low.ch) < cap(low.ch))
+
+	// High priority elements queue until full
+	high.start()
+	for i := 0; i < 300; i++ {
+		high.push(&Sample{})
+	}
+	assert.Equal(t, 0, int(high.usage.dropped))
+	assert.True(t, Pressure() > 0.5)
+	assert.True(t, Overloaded(PriorityNormal))
+	assert.False(t, Overloaded(PriorityHigh))
+
+	// Normal priority elements shed at that pressure
+	normal := newElementQueue(&blockingElement{release: release})
+	normal.push(&Sample{})
+	assert.Equal(t, 1, int(normal.usage.dropped))
+
+	close(release)
+	low.stop()
+	high.stop()
+	assert.Equal(t, 200-int(low.usage.dropped), lowElem.written)
+	assert.Equal(t, 300, highElem.written)
+	assert.Equal(t, float64(0), Pressure())
+}
+
+func TestElementQueue_StopCancels(t *testing.T) {
+	defer func(d time.Duration) { drainTimeout = d }(drainTimeout)
+	drainTimeout = 50 * time.Millisecond
+
+	elem := &stuckElement{blockingElement{release: make(chan struct{})}}
+	q := newElementQueue(WithPriority(elem, PriorityHigh))
+	q.start()
+	for i := 0; i < 3; i++ {
+		q.push(&Sample{})
+	}
+
+	// The stuck write is canceled rather than holding up stop
+	start := time.Now()
+	q.stop()
+	assert.True(t, time.Since(start) >= drainTimeout)
+	assert.Equal(t, 0, elem.written)
+	q.close()
+}
+
+// framesElement keeps the numbers of the frames written to it, blocking
+// until released
+type framesElement struct {
+	release chan struct{}
+	frames  []byte
+}
+
+func (e *framesElement) Write(s *Sample) error {
+	<-e.release
+	e.frames = append(e.frames, s.Payload.([]byte)[1])
+	return nil
+}
+
+func (e *framesElement) Attach(Element) {}
+
+func (e *framesElement) Close() {}
+
+func TestElementQueue_Resync(t *testing.T) {
+	// VP8 frames numbered by their second byte
+	frame := func(key bool, n int) *Sample {
+		payload := []byte{1, byte(n)}
+		if key {
+			payload[0] = 0
+		}
+		return &Sample{Type: TypeVP8, Payload: payload}
+	}
+	e := &framesElement{release: make(chan struct{})}
+	q := newElementQueue(e)
+	requests := 0
+	q.keyframe = func() { requests++ }
+	q.start()
+
+	// The stalled element sheds video, asking for a keyframe once
+	q.push(frame(true, 0))
+	for i := 1; i < 200; i++ {
+		q.push(frame(false, i))
+	}
+	dropped := q.usage.dropped
+	assert.True(t, dropped > 0)
+	assert.Equal(t, 1, requests)
+
+	// Caught up, it is still fed nothing until the keyframe
+	close(e.release)
+	for len(q.ch) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	q.push(frame(false, 200))
+	q.push(frame(true, 201))
+	q.push(frame(false, 202))
+	q.stop()
+	assert.Equal(t, dropped+1, q.usage.dropped)
+	assert.Equal(t, 1, requests)
+
+	// So it sees every frame from the first up to the drop, then from the
+	// keyframe on
+	n := len(e.frames)
+	assert.Equal(t, []byte{201, 202}, e.frames[n-2:])
+	for i, f := range e.frames[:n-2] {
+		assert.Equal(t, byte(i), f)
+	}
+}
+
+func TestKeyframe(t *testing.T) {
+	assert.True(t, keyframe(&Sample{Type: TypeOpus, Payload: []byte{1}}))
+	assert.True(t, keyframe(&Sample{Type: TypeVP8, Payload: []byte{0x10}}))
+	assert.False(t, keyframe(&Sample{Type: TypeVP8, Payload: []byte{0x11}}))
+	assert.False(t, keyframe(&Sample{Type: TypeVP8}))
+	assert.True(t, keyframe(&Sample{Type: TypeH264, Payload: []byte{0, 0, 0, 1, 0x67, 0x42, 0, 0, 0, 1, 0x65, 0x88}}))
+	assert.False(t, keyframe(&Sample{Type: TypeH264, Payload: []byte{0, 0, 0, 1, 0x41, 0x9a}}))
+}
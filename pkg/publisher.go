@@ -24,7 +24,7 @@ func NewPublisher(cfg WebRTCTransportConfig) (*Publisher, error) {
 		return nil, errPeerConnectionInitFailed
 	}
 
-	_, err = pc.CreateDataChannel("ion-sfu", &webrtc.DataChannelInit{})
+	_, err = pc.CreateDataChannel(sfuDataChannel, &webrtc.DataChannelInit{})
 
 	if err != nil {
 		log.Errorf("error creating data channel: %v", err)
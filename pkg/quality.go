@@ -0,0 +1,223 @@
+package avp
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/pion/ion-log"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+)
+
+// QualityConfig configures the timeline of receive quality recorded for
+// each track, so glitches in a recording can be traced to the network
+type QualityConfig struct {
+	// Interval between timeline entries, zero disables the timeline
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Quality is the receive quality of a track over one timeline interval
+type Quality struct {
+	At time.Time `json:"at"`
+	// Packets received in the interval
+	Packets uint32 `json:"packets"`
+	// Lost packets in the interval, from gaps in sequence numbers
+	Lost uint32 `json:"lost"`
+	// FractionLost of the packets expected in the interval
+	FractionLost float64 `json:"fractionLost"`
+	// TotalLost since the track started
+	TotalLost uint32 `json:"totalLost"`
+	// Jitter is the interarrival jitter estimate of RFC 3550
+	Jitter time.Duration `json:"jitter"`
+	// RTT is the latest round trip time from RTCP reception reports,
+	// zero when the sender doesn't report one
+	RTT time.Duration `json:"rtt,omitempty"`
+}
+
+// QualityObserver is implemented by elements recording the quality
+// timeline of their track
+type QualityObserver interface {
+	ObserveQuality(Quality)
+}
+
+// quality tracks loss and jitter of received RTP following RFC 3550
+// appendix A, and round trip time from RTCP
+type quality struct {
+	mu        sync.Mutex
+	clockRate float64
+	started   bool
+	baseSeq   uint32
+	maxSeq    uint32 // extended with the number of wraps
+	received  uint32
+	transit   float64
+	jitter    float64 // in clock rate units
+
+	expectedPrior uint32
+	receivedPrior uint32
+
+	rtt time.Duration
+}
+
+func newQuality(clockRate uint32) *quality {
+	return &quality{clockRate: float64(clockRate)}
+}
+
+func (q *quality) packet(p *rtp.Packet, arrival time.Time) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	seq := uint32(p.SequenceNumber)
+	if !q.started {
+		q.started = true
+		q.baseSeq, q.maxSeq = seq, seq
+	} else {
+		cycles := q.maxSeq &^ 0xffff
+		delta := int16(p.SequenceNumber - uint16(q.maxSeq))
+		if delta > 0 {
+			if uint16(q.maxSeq)+uint16(delta) < uint16(q.maxSeq) {
+				cycles += 1 << 16
+			}
+			q.maxSeq = cycles | seq
+		}
+	}
+	q.received++
+
+	if q.clockRate == 0 {
+		return
+	}
+	transit := float64(arrival.UnixNano())*q.clockRate/1e9 - float64(p.Timestamp)
+	if q.received > 1 {
+		d := transit - q.transit
+		if d < 0 {
+			d = -d
+		}
+		q.jitter += (d - q.jitter) / 16
+	}
+	q.transit = transit
+}
+
+// rtcp records the round trip time from reception reports, computed as
+// in RFC 3550 section 6.4.1 from the arrival time of the report
+func (q *quality) rtcp(pkts []rtcp.Packet, arrival time.Time) {
+	if q == nil {
+		return
+	}
+	var reports []rtcp.ReceptionReport
+	for _, p := range pkts {
+		switch p := p.(type) {
+		case *rtcp.SenderReport:
+			reports = append(reports, p.Reports...)
+		case *rtcp.ReceiverReport:
+			reports = append(reports, p.Reports...)
+		}
+	}
+
+	now := ntpMiddle(arrival)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, r := range reports {
+		if r.LastSenderReport == 0 {
+			continue
+		}
+		rtt := now - r.LastSenderReport - r.Delay
+		if int32(rtt) > 0 {
+			q.rtt = time.Duration(rtt) * time.Second / 65536
+		}
+	}
+}
+
+// ntpMiddle returns the middle 32 bits of the NTP timestamp of t
+func ntpMiddle(t time.Time) uint32 {
+	secs := uint64(t.Unix()) + ntpEpochOffset
+	frac := uint64(t.Nanosecond()) << 32 / 1e9
+	return uint32(secs<<16 | frac>>16)
+}
+
+// sample returns the quality since the previous sample
+func (q *quality) sample(at time.Time) Quality {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s := Quality{At: at, RTT: q.rtt}
+	if !q.started {
+		return s
+	}
+	expected := q.maxSeq - q.baseSeq + 1
+	if expected > q.received {
+		s.TotalLost = expected - q.received
+	}
+
+	expectedInterval := expected - q.expectedPrior
+	s.Packets = q.received - q.receivedPrior
+	q.expectedPrior, q.receivedPrior = expected, q.received
+	if expectedInterval > s.Packets {
+		s.Lost = expectedInterval - s.Packets
+		s.FractionLost = float64(s.Lost) / float64(expectedInterval)
+	}
+	if q.clockRate > 0 {
+		s.Jitter = time.Duration(q.jitter / q.clockRate * 1e9)
+	}
+	return s
+}
+
+// trackQuality records the quality of the builder's track every interval
+func (b *Builder) trackQuality(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if b.stopped.get() {
+			return
+		}
+		s := b.quality.sample(now)
+		b.mu.RLock()
+		for _, q := range b.elements {
+			if o, ok := unwrap(q.e).(QualityObserver); ok {
+				o.ObserveQuality(s)
+			}
+		}
+		b.mu.RUnlock()
+		log.Tracef("track %s quality: %+v", b.track.ID(), s)
+	}
+}
+
+// TrackStats is the receive quality of a track since it started
+type TrackStats struct {
+	Session string
+	Track   string
+	Packets uint64
+	Lost    uint64
+	Jitter  time.Duration
+}
+
+// TrackStats returns the receive quality of the builder's track
+func (b *Builder) TrackStats() TrackStats {
+	q := b.quality
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := TrackStats{Track: b.track.ID(), Packets: uint64(q.received)}
+	if q.started {
+		if expected := q.maxSeq - q.baseSeq + 1; expected > q.received {
+			s.Lost = uint64(expected - q.received)
+		}
+	}
+	if q.clockRate > 0 {
+		s.Jitter = time.Duration(q.jitter / q.clockRate * 1e9)
+	}
+	return s
+}
+
+// TrackStats returns the receive quality of every track
+func (t *WebRTCTransport) TrackStats() []TrackStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	stats := make([]TrackStats, 0, len(t.builders))
+	for _, b := range t.builders {
+		s := b.TrackStats()
+		s.Session = t.id
+		stats = append(stats, s)
+	}
+	return stats
+}
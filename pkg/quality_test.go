@@ -0,0 +1,68 @@
+package avp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuality_Loss(t *testing.T) {
+	q := newQuality(90000)
+	start := time.Now()
+	send := func(seq uint16) {
+		q.packet(&rtp.Packet{Header: rtp.Header{SequenceNumber: seq}}, start)
+	}
+
+	// Sequence numbers wrap around and one packet in ten is lost
+	for i := 0; i < 100; i++ {
+		if i%10 != 5 {
+			send(uint16(65500 + i))
+		}
+	}
+	s := q.sample(start)
+	assert.Equal(t, uint32(90), s.Packets)
+	assert.Equal(t, uint32(10), s.Lost)
+	assert.Equal(t, uint32(10), s.TotalLost)
+	assert.InDelta(t, 0.1, s.FractionLost, 1e-9)
+
+	// Reordered packets aren't lost
+	send(65)
+	send(64)
+	s = q.sample(start)
+	assert.Equal(t, uint32(2), s.Packets)
+	assert.Equal(t, uint32(0), s.Lost)
+	assert.Equal(t, uint32(10), s.TotalLost)
+}
+
+func TestQuality_Jitter(t *testing.T) {
+	q := newQuality(90000)
+	start := time.Now()
+	// Packets 20ms apart, arriving alternately on time and 10ms late
+	for i := 0; i < 500; i++ {
+		arrival := start.Add(time.Duration(i) * 20 * time.Millisecond)
+		if i%2 == 1 {
+			arrival = arrival.Add(10 * time.Millisecond)
+		}
+		q.packet(&rtp.Packet{Header: rtp.Header{
+			SequenceNumber: uint16(i),
+			Timestamp:      uint32(i * 1800),
+		}}, arrival)
+	}
+	s := q.sample(start)
+	assert.InDelta(t, float64(10*time.Millisecond), float64(s.Jitter), float64(time.Millisecond))
+}
+
+func TestQuality_RTT(t *testing.T) {
+	q := newQuality(48000)
+	sent := time.Now()
+	arrival := sent.Add(250 * time.Millisecond)
+	// The report was sent after holding our report for 50ms
+	q.rtcp([]rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{{
+		LastSenderReport: ntpMiddle(sent),
+		Delay:            65536 / 20,
+	}}}}, arrival)
+	assert.InDelta(t, float64(200*time.Millisecond), float64(q.sample(arrival).RTT), float64(time.Millisecond))
+}
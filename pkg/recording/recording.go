@@ -0,0 +1,550 @@
+// Package recording tracks each recording through an explicit state
+// machine, so its progress can be reported instead of inferred from which
+// elements happen to exist.
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lucsky/cuid"
+	"github.com/pion/ion-avp/pkg/retry"
+	log "github.com/pion/ion-log"
+)
+
+// ErrTransition is returned for changes the state machine doesn't allow
+var ErrTransition = errors.New("invalid recording state transition")
+
+// retention is how long finished recordings are listed
+const retention = time.Hour
+
+// State of a recording
+type State int
+
+// Recording states
+const (
+	// StatePending recordings have not received media yet
+	StatePending State = iota
+	// StateWaitingForKeyframe recordings drop video until a keyframe arrives
+	StateWaitingForKeyframe
+	StateRecording
+	StatePaused
+	// StateFinalizing recordings are flushing and closing their container
+	StateFinalizing
+	// StateUploading recordings are being written to storage
+	StateUploading
+	StateComplete
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateWaitingForKeyframe:
+		return "waiting-for-keyframe"
+	case StateRecording:
+		return "recording"
+	case StatePaused:
+		return "paused"
+	case StateFinalizing:
+		return "finalizing"
+	case StateUploading:
+		return "uploading"
+	case StateComplete:
+		return "complete"
+	case StateFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// MarshalText encodes the state by name
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a state encoded by MarshalText
+func (s *State) UnmarshalText(b []byte) error {
+	for st := StatePending; st <= StateFailed; st++ {
+		if st.String() == string(b) {
+			*s = st
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown recording state %q", b)
+}
+
+// Finished reports whether the recording has ended
+func (s State) Finished() bool {
+	return s == StateComplete || s == StateFailed
+}
+
+// transitions lists the states reachable from each state. Any unfinished
+// recording may fail.
+var transitions = map[State][]State{
+	StatePending:            {StateWaitingForKeyframe, StateRecording, StatePaused, StateFinalizing},
+	StateWaitingForKeyframe: {StateRecording, StatePaused, StateFinalizing},
+	StateRecording:          {StatePaused, StateFinalizing},
+	StatePaused:             {StateWaitingForKeyframe, StateRecording, StateFinalizing},
+	StateFinalizing:         {StateUploading, StateComplete},
+	StateUploading:          {StateComplete},
+}
+
+func allowed(from, to State) bool {
+	if to == StateFailed {
+		return !from.Finished()
+	}
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Status of a recording
+type Status struct {
+	ID      string `json:"id"`
+	Session string `json:"sid"`
+	Track   string `json:"tid"`
+	Name    string `json:"name"`
+	// Correlation is the ID of the request that started the recording
+	Correlation string `json:"correlation,omitempty"`
+	State       State  `json:"state"`
+	Error       string `json:"error,omitempty"`
+	// URL is a signed download url of the completed recording
+	URL     string    `json:"url,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Segment is a finished file of a recording split into several, e.g. by
+// rotation
+type Segment struct {
+	// Recording is the ID of the recording
+	Recording   string    `json:"recording"`
+	Correlation string    `json:"correlation,omitempty"`
+	Session     string    `json:"sid"`
+	Track       string    `json:"tid"`
+	Name        string    `json:"name"`
+	Index       int       `json:"index"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// event is a state change or segment, in the order they happen
+type event struct {
+	status  Status
+	started bool // first time recording
+	segment *Segment
+}
+
+// Recording is the state machine of one recording. Elements move it along
+// as media flows; its methods are safe to call on a nil Recording, so
+// elements can be used without one.
+type Recording struct {
+	mu       sync.Mutex
+	status   Status
+	started  bool
+	segments int
+	notify   func(event)
+	finished []func(Status)
+}
+
+// State returns the current state
+func (r *Recording) State() State {
+	if r == nil {
+		return StatePending
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status.State
+}
+
+// Status returns a snapshot of the recording
+func (r *Recording) Status() Status {
+	if r == nil {
+		return Status{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// Set moves the recording to state s. Setting the current state is a no-op.
+func (r *Recording) Set(s State) error {
+	return r.set(s, nil)
+}
+
+// Fail moves the recording to StateFailed with the error that caused it
+func (r *Recording) Fail(err error) {
+	if err := r.set(StateFailed, err); err != nil {
+		log.Warnf("recording: %v", err)
+	}
+}
+
+// SetURL sets the download url reported with the next state change
+func (r *Recording) SetURL(u string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.status.URL = u
+	r.mu.Unlock()
+}
+
+// OnFinished calls f once the recording is complete or has failed
+func (r *Recording) OnFinished(f func(Status)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.finished = append(r.finished, f)
+	r.mu.Unlock()
+}
+
+// Segment reports a finished segment file of the recording, from start to
+// end, to the tracker's segment hooks
+func (r *Recording) Segment(name string, start, end time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	seg := &Segment{
+		Recording:   r.status.ID,
+		Correlation: r.status.Correlation,
+		Session:     r.status.Session,
+		Track:       r.status.Track,
+		Name:        name,
+		Index:       r.segments,
+		Start:       start,
+		End:         end,
+	}
+	r.segments++
+	notify := r.notify
+	r.mu.Unlock()
+	if notify != nil {
+		notify(event{segment: seg})
+	}
+}
+
+// Pause stops the recording from writing media until Resume
+func (r *Recording) Pause() error {
+	return r.Set(StatePaused)
+}
+
+// Resume continues a paused recording from the next keyframe
+func (r *Recording) Resume() error {
+	if r.State() != StatePaused {
+		return fmt.Errorf("%w: %s is not paused", ErrTransition, r.Status().ID)
+	}
+	return r.Set(StateWaitingForKeyframe)
+}
+
+func (r *Recording) set(s State, cause error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	from := r.status.State
+	if from == s {
+		r.mu.Unlock()
+		return nil
+	}
+	if !allowed(from, s) {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s from %s to %s", ErrTransition, r.status.ID, from, s)
+	}
+	r.status.State = s
+	r.status.Updated = time.Now()
+	if cause != nil {
+		r.status.Error = cause.Error()
+	}
+	started := s == StateRecording && !r.started
+	if started {
+		r.started = true
+	}
+	snapshot := r.status
+	notify := r.notify
+	var finished []func(Status)
+	if s.Finished() {
+		finished = r.finished
+	}
+	r.mu.Unlock()
+
+	if snapshot.Correlation != "" {
+		log.Infof("recording %s %s: %s (correlation %s)", snapshot.ID, snapshot.Name, s, snapshot.Correlation)
+	} else {
+		log.Infof("recording %s %s: %s", snapshot.ID, snapshot.Name, s)
+	}
+	if notify != nil {
+		notify(event{status: snapshot, started: started})
+	}
+	for _, f := range finished {
+		f(snapshot)
+	}
+	return nil
+}
+
+// Config configures recording tracking
+type Config struct {
+	// Webhook receives a POST with the status on every state change
+	Webhook string `mapstructure:"webhook"`
+	// Counters is the file segment numbers are kept in across restarts,
+	// by default they start from 0 each time a recording starts
+	Counters string `mapstructure:"counters"`
+	// Index is the file the recordings of the node are indexed in, for
+	// searching, none by default
+	Index string `mapstructure:"index"`
+	// Gaps is the default policy for jumps in WebM timestamps, see
+	// elements.GapPolicy: keep, fill, clamp or rebase
+	Gaps string `mapstructure:"gaps"`
+	// MaxGap is the longest jump that isn't a gap, 1s by default
+	MaxGap time.Duration `mapstructure:"maxgap"`
+}
+
+// Tracker keeps the recordings on a node, one per session track
+type Tracker struct {
+	mu         sync.Mutex
+	recordings map[string]*Recording
+	webhook    string
+	client     *http.Client
+	retry      *retry.Retrier
+	events     chan event
+	closed     bool
+	hooks      hooks
+	newID      func() string
+}
+
+// hooks are the callbacks registered for each event
+type hooks struct {
+	state   []func(Status)
+	started []func(Status)
+	err     []func(Status)
+	segment []func(Segment)
+}
+
+// NewTracker creates a tracker, posting state changes to the configured
+// webhook in order from a background goroutine
+func NewTracker(c Config) *Tracker {
+	t := &Tracker{
+		recordings: make(map[string]*Recording),
+		webhook:    c.Webhook,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		retry:      retry.New("recording-webhook", retry.Policy{}),
+		newID:      cuid.New,
+	}
+	if t.webhook != "" {
+		t.start()
+	}
+	return t
+}
+
+// start delivers events from a background goroutine, with t.mu held
+func (t *Tracker) start() {
+	if t.events == nil && !t.closed {
+		t.events = make(chan event, 100)
+		go t.dispatch()
+	}
+}
+
+// OnStateChange calls f with every state change of every recording, as
+// the webhook receives them. Hooks are called in order from a background
+// goroutine, so a slow hook delays later events but not recording. Events
+// are dropped while hooks are backed up.
+func (t *Tracker) OnStateChange(f func(Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks.state = append(t.hooks.state, f)
+	t.start()
+}
+
+// OnRecordingStarted calls f when a recording first writes media
+func (t *Tracker) OnRecordingStarted(f func(Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks.started = append(t.hooks.started, f)
+	t.start()
+}
+
+// OnError calls f when a recording fails, with the error in its status
+func (t *Tracker) OnError(f func(Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks.err = append(t.hooks.err, f)
+	t.start()
+}
+
+// OnSegment calls f when a recording finishes a segment file
+func (t *Tracker) OnSegment(f func(Segment)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks.segment = append(t.hooks.segment, f)
+	t.start()
+}
+
+// SetIDGenerator replaces how recording IDs are generated, e.g. to use
+// IDs from another system. f must return unique IDs.
+func (t *Tracker) SetIDGenerator(f func() string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.newID = f
+}
+
+func key(sid, tid string) string {
+	return sid + "/" + tid
+}
+
+// Start tracks a new recording of a session track, replacing any earlier
+// recording of it
+func (t *Tracker) Start(sid, tid, name string) *Recording {
+	return t.StartCorrelated(sid, tid, name, "")
+}
+
+// StartCorrelated is Start for a request with a correlation ID, which is
+// reported with the recording's events
+func (t *Tracker) StartCorrelated(sid, tid, name, correlation string) *Recording {
+	now := time.Now()
+	r := &Recording{
+		status: Status{
+			Session:     sid,
+			Track:       tid,
+			Name:        name,
+			Correlation: correlation,
+			Created:     now,
+			Updated:     now,
+		},
+		notify: t.notify,
+	}
+
+	t.mu.Lock()
+	r.status.ID = t.newID()
+	t.prune()
+	t.recordings[key(sid, tid)] = r
+	t.mu.Unlock()
+
+	t.notify(event{status: r.Status()})
+	return r
+}
+
+// Get returns the recording of a session track
+func (t *Tracker) Get(sid, tid string) (*Recording, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.recordings[key(sid, tid)]
+	return r, ok
+}
+
+// Epoch returns when the first recording of session sid still tracked
+// started, for aligning the files of its participants
+func (t *Tracker) Epoch(sid string) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var epoch time.Time
+	for _, r := range t.recordings {
+		if s := r.Status(); s.Session == sid && (epoch.IsZero() || s.Created.Before(epoch)) {
+			epoch = s.Created
+		}
+	}
+	return epoch
+}
+
+// List returns the status of recordings in progress and recently
+// finished, optionally only those of session sid
+func (t *Tracker) List(sid string) []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune()
+	var list []Status
+	for _, r := range t.recordings {
+		if s := r.Status(); sid == "" || s.Session == sid {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// Close stops posting to the webhook and calling hooks
+func (t *Tracker) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if t.events != nil {
+		close(t.events)
+		t.events = nil
+	}
+}
+
+// prune forgets recordings that finished more than retention ago
+func (t *Tracker) prune() {
+	for k, r := range t.recordings {
+		if s := r.Status(); s.State.Finished() && time.Since(s.Updated) > retention {
+			delete(t.recordings, k)
+		}
+	}
+}
+
+func (t *Tracker) notify(e event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.events == nil {
+		return
+	}
+	select {
+	case t.events <- e:
+	default:
+		if e.segment != nil {
+			log.Warnf("recording %s events backed up, dropping segment %s", e.segment.Recording, e.segment.Name)
+		} else {
+			log.Warnf("recording %s events backed up, dropping %s", e.status.ID, e.status.State)
+		}
+	}
+}
+
+func (t *Tracker) dispatch() {
+	for e := range t.events {
+		t.mu.Lock()
+		h := t.hooks
+		t.mu.Unlock()
+
+		if e.segment != nil {
+			for _, f := range h.segment {
+				f(*e.segment)
+			}
+			continue
+		}
+		for _, f := range h.state {
+			f(e.status)
+		}
+		if e.started {
+			for _, f := range h.started {
+				f(e.status)
+			}
+		}
+		if e.status.State == StateFailed {
+			for _, f := range h.err {
+				f(e.status)
+			}
+		}
+		if t.webhook != "" {
+			t.post(e.status)
+		}
+	}
+}
+
+func (t *Tracker) post(s Status) {
+	body, err := json.Marshal(s)
+	if err != nil {
+		log.Errorf("recording %s webhook: %v", s.ID, err)
+		return
+	}
+	if err := t.retry.PostJSON(context.Background(), t.client, t.webhook, body); err != nil {
+		log.Errorf("recording %s webhook: %v", s.ID, err)
+	}
+}
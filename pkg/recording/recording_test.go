@@ -0,0 +1,182 @@
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecording_Transitions(t *testing.T) {
+	r := NewTracker(Config{}).Start("sid", "tid", "rec.webm")
+	assert.Equal(t, StatePending, r.State())
+
+	assert.NoError(t, r.Set(StateWaitingForKeyframe))
+	assert.NoError(t, r.Set(StateRecording))
+	assert.NoError(t, r.Set(StateRecording))
+	assert.True(t, errors.Is(r.Resume(), ErrTransition))
+	assert.NoError(t, r.Pause())
+	assert.NoError(t, r.Resume())
+	assert.Equal(t, StateWaitingForKeyframe, r.State())
+	assert.NoError(t, r.Set(StateFinalizing))
+	assert.True(t, errors.Is(r.Set(StateRecording), ErrTransition))
+	assert.NoError(t, r.Set(StateUploading))
+
+	r.Fail(errors.New("upload failed"))
+	s := r.Status()
+	assert.Equal(t, StateFailed, s.State)
+	assert.Equal(t, "upload failed", s.Error)
+	assert.True(t, s.State.Finished())
+	assert.True(t, errors.Is(r.Set(StateComplete), ErrTransition))
+}
+
+func TestRecording_OnFinished(t *testing.T) {
+	r := NewTracker(Config{}).Start("sid", "tid", "rec.webm")
+	var finished []State
+	r.OnFinished(func(s Status) { finished = append(finished, s.State) })
+	assert.NoError(t, r.Set(StateRecording))
+	assert.NoError(t, r.Set(StateFinalizing))
+	assert.Empty(t, finished)
+	assert.NoError(t, r.Set(StateComplete))
+	r.Fail(errors.New("ignored"))
+	assert.Equal(t, []State{StateComplete}, finished)
+}
+
+func TestRecording_Nil(t *testing.T) {
+	var r *Recording
+	assert.Equal(t, StatePending, r.State())
+	assert.NoError(t, r.Set(StateRecording))
+	r.Fail(errors.New("ignored"))
+}
+
+func TestTracker(t *testing.T) {
+	type event struct {
+		Session string `json:"sid"`
+		State   string `json:"state"`
+	}
+	events := make(chan event, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var e event
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&e))
+		events <- e
+	}))
+	defer srv.Close()
+
+	tr := NewTracker(Config{Webhook: srv.URL})
+	defer tr.Close()
+	r := tr.Start("sid", "tid", "rec.webm")
+	tr.Start("other", "tid", "other.webm")
+
+	got, ok := tr.Get("sid", "tid")
+	assert.True(t, ok)
+	assert.Equal(t, r, got)
+	assert.Len(t, tr.List(""), 2)
+	list := tr.List("sid")
+	assert.Len(t, list, 1)
+	assert.Equal(t, "rec.webm", list[0].Name)
+
+	assert.NoError(t, r.Set(StateRecording))
+
+	var states []string
+	for len(states) < 2 {
+		select {
+		case e := <-events:
+			if e.Session == "sid" {
+				states = append(states, e.State)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for webhook")
+		}
+	}
+	assert.Equal(t, []string{"pending", "recording"}, states)
+}
+
+func TestTracker_Hooks(t *testing.T) {
+	tr := NewTracker(Config{})
+	defer tr.Close()
+	events := make(chan string, 20)
+	tr.OnStateChange(func(s Status) { events <- "state " + s.State.String() })
+	tr.OnRecordingStarted(func(s Status) { events <- "started " + s.Name })
+	tr.OnError(func(s Status) { events <- "error " + s.Error })
+	tr.OnSegment(func(s Segment) { events <- fmt.Sprintf("segment %d %s", s.Index, s.Name) })
+
+	r := tr.Start("sid", "tid", "rec.webm")
+	assert.NoError(t, r.Set(StateRecording))
+	r.Segment("rec-0.webm", time.Now(), time.Now())
+	assert.NoError(t, r.Pause())
+	assert.NoError(t, r.Resume())
+	assert.NoError(t, r.Set(StateRecording))
+	r.Fail(errors.New("disk full"))
+
+	var got []string
+	for len(got) < 9 {
+		select {
+		case e := <-events:
+			got = append(got, e)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for hooks")
+		}
+	}
+	assert.Equal(t, []string{
+		"state pending",
+		"state recording", "started rec.webm",
+		"segment 0 rec-0.webm",
+		"state paused", "state waiting-for-keyframe", "state recording",
+		"state failed", "error disk full",
+	}, got)
+}
+
+func TestTracker_Epoch(t *testing.T) {
+	tr := NewTracker(Config{})
+	assert.True(t, tr.Epoch("sid").IsZero())
+
+	first := tr.Start("sid", "audio", "audio.webm")
+	time.Sleep(time.Millisecond)
+	tr.Start("sid", "video", "video.webm")
+	tr.Start("other", "audio", "other.webm")
+	assert.Equal(t, first.Status().Created, tr.Epoch("sid"))
+	assert.True(t, tr.Epoch("other").After(first.Status().Created))
+}
+
+func TestTracker_Correlation(t *testing.T) {
+	tr := NewTracker(Config{})
+	defer tr.Close()
+	n := 0
+	tr.SetIDGenerator(func() string {
+		n++
+		return fmt.Sprintf("rec-%d", n)
+	})
+	segments := make(chan Segment, 1)
+	tr.OnSegment(func(s Segment) { segments <- s })
+
+	r := tr.StartCorrelated("sid", "tid", "rec.webm", "req-1")
+	assert.Equal(t, "rec-1", r.Status().ID)
+	assert.Equal(t, "req-1", r.Status().Correlation)
+	assert.Equal(t, "rec-2", tr.Start("sid", "other", "other.webm").Status().ID)
+
+	r.Segment("rec-0.webm", time.Now(), time.Now())
+	select {
+	case s := <-segments:
+		assert.Equal(t, "rec-1", s.Recording)
+		assert.Equal(t, "req-1", s.Correlation)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for segment")
+	}
+}
+
+func TestState_Text(t *testing.T) {
+	for s := StatePending; s <= StateFailed; s++ {
+		b, err := s.MarshalText()
+		assert.NoError(t, err)
+		var decoded State
+		assert.NoError(t, decoded.UnmarshalText(b))
+		assert.Equal(t, s, decoded)
+	}
+	var s State
+	assert.Error(t, s.UnmarshalText([]byte("lost")))
+}
@@ -0,0 +1,138 @@
+package recordings
+
+import (
+	"crypto/subtle"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pion/ion-avp/pkg/storage"
+	log "github.com/pion/ion-log"
+)
+
+var contentTypes = map[string]string{
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+	".mp4":  "video/mp4",
+	".m4s":  "video/iso.segment",
+	".ts":   "video/mp2t",
+	".m3u8": "application/vnd.apple.mpegurl",
+	".mpd":  "application/dash+xml",
+	".ivf":  "video/x-ivf",
+	".ogg":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+	".vtt":  "text/vtt",
+	".srt":  "application/x-subrip",
+	".json": "application/json",
+}
+
+// ContentType returns the mime type for a recording file name.
+func ContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// Handler serves recordings from a directory over HTTP.
+// Range requests are supported, so players can seek, and files that are
+// still being written can be fetched again as they grow.
+type Handler struct {
+	root  string
+	token string
+}
+
+// NewHandler creates a recordings handler rooted at dir.
+// If token is not empty, requests must carry it either as a
+// "Authorization: Bearer <token>" header or a "token" query parameter.
+func NewHandler(dir, token string) *Handler {
+	return &Handler{
+		root:  dir,
+		token: token,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	name := path.Clean("/" + r.URL.Path)
+	f, err := os.Open(filepath.Join(h.root, filepath.FromSlash(name)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Errorf("error opening recording %s: %s", name, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Serve the content of deduplicated segments
+	if fi.Size() <= storage.MaxRefSize {
+		if blob, ok := h.resolve(f); ok {
+			if f, err = os.Open(filepath.Join(h.root, filepath.FromSlash(blob))); err != nil {
+				log.Errorf("error opening blob %s of recording %s: %s", blob, name, err)
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			defer f.Close()
+		}
+	}
+
+	w.Header().Set("Content-Type", ContentType(name))
+	// Recordings may still be growing, make sure clients revalidate.
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, name, fi.ModTime(), f)
+}
+
+// resolve returns the blob referenced by f, leaving f at its start
+func (h *Handler) resolve(f *os.File) (string, bool) {
+	data, err := ioutil.ReadAll(io.LimitReader(f, storage.MaxRefSize))
+	if err != nil {
+		return "", false
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", false
+	}
+	return storage.ParseRef(data)
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return true
+	}
+
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
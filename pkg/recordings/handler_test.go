@@ -0,0 +1,68 @@
+package recordings
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/ion-avp/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Range(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recordings")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "test.webm"), []byte("0123456789"), 0600)
+	assert.NoError(t, err)
+
+	h := NewHandler(dir, "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/test.webm", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/test.webm", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Range", "bytes=2-5")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "2345", rec.Body.String())
+	assert.Equal(t, "video/webm", rec.Header().Get("Content-Type"))
+
+	req = httptest.NewRequest(http.MethodGet, "/../test.webm?token=secret", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/missing.webm?token=secret", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_Dedup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recordings")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	d := storage.NewDedup(storage.NewLocal(storage.LocalConfig{Root: dir}), storage.DedupConfig{Enabled: true})
+	w, err := d.Open("slate.webm")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("0123456789"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodGet, "/slate.webm", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rec := httptest.NewRecorder()
+	NewHandler(dir, "").ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "2345", rec.Body.String())
+}
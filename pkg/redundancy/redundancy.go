@@ -0,0 +1,99 @@
+// Package redundancy coordinates recording a session on two nodes, so a
+// single node failing never loses a recording that must be kept.
+//
+// The app server starts the same recording on both nodes, one as the
+// primary and one as the backup naming the primary as its peer. The
+// primary records as usual. The backup records to BackupName, and once
+// finished asks its peer how the primary's recording ended: if the primary
+// completed its copy the backup is a duplicate and is deleted, otherwise
+// the backup is kept in its place.
+package redundancy
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/recording"
+)
+
+// ErrNotFound is returned by peers without a recording of the track
+var ErrNotFound = errors.New("redundancy: no recording on peer")
+
+// Role of a node in a redundant recording
+type Role int
+
+// Roles
+const (
+	RolePrimary Role = iota
+	RoleBackup
+)
+
+func (r Role) String() string {
+	if r == RoleBackup {
+		return "backup"
+	}
+	return "primary"
+}
+
+// Config configures how backups wait for their primary
+type Config struct {
+	// Wait is how long a finished backup waits for the primary to
+	// finish, defaults to 1m. The backup is kept if it doesn't.
+	Wait time.Duration `mapstructure:"wait"`
+	// Poll is the interval the primary is asked at, defaults to 2s
+	Poll time.Duration `mapstructure:"poll"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Wait <= 0 {
+		c.Wait = time.Minute
+	}
+	if c.Poll <= 0 {
+		c.Poll = 2 * time.Second
+	}
+	return c
+}
+
+// Peer is the node recording the other copy
+type Peer interface {
+	// Recording returns the status of the peer's recording of a session
+	// track, or ErrNotFound
+	Recording(ctx context.Context, sid, tid string) (recording.Status, error)
+}
+
+// BackupName returns the name a backup records name to, so both copies
+// can share storage
+func BackupName(name string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + ".backup" + ext
+}
+
+// Keep reports whether a finished backup of a session track must be kept.
+// It waits for the primary's recording to finish, and is false only if it
+// completed. The error says why the backup was kept when the primary
+// couldn't be asked.
+func Keep(ctx context.Context, c Config, p Peer, sid, tid string) (bool, error) {
+	c = c.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, c.Wait)
+	defer cancel()
+
+	ticker := time.NewTicker(c.Poll)
+	defer ticker.Stop()
+	for {
+		s, err := p.Recording(ctx, sid, tid)
+		if err != nil {
+			return true, err
+		}
+		if s.State.Finished() {
+			return s.State != recording.StateComplete, nil
+		}
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
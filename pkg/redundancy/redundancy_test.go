@@ -0,0 +1,59 @@
+package redundancy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/recording"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePeer struct {
+	mu     sync.Mutex
+	states []recording.State
+	err    error
+}
+
+func (p *fakePeer) Recording(ctx context.Context, sid, tid string) (recording.Status, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return recording.Status{}, p.err
+	}
+	s := p.states[0]
+	if len(p.states) > 1 {
+		p.states = p.states[1:]
+	}
+	return recording.Status{Session: sid, Track: tid, State: s}, nil
+}
+
+func TestBackupName(t *testing.T) {
+	assert.Equal(t, "/rec/sid/tid.backup.webm", BackupName("/rec/sid/tid.webm"))
+	assert.Equal(t, "tid.backup", BackupName("tid"))
+}
+
+func TestKeep(t *testing.T) {
+	c := Config{Wait: time.Second, Poll: time.Millisecond}
+	for _, tc := range []struct {
+		name string
+		peer *fakePeer
+		keep bool
+		err  error
+	}{
+		{"primary complete", &fakePeer{states: []recording.State{recording.StateRecording, recording.StateFinalizing, recording.StateComplete}}, false, nil},
+		{"primary failed", &fakePeer{states: []recording.State{recording.StateUploading, recording.StateFailed}}, true, nil},
+		{"primary unreachable", &fakePeer{err: ErrNotFound}, true, ErrNotFound},
+	} {
+		keep, err := Keep(context.Background(), c, tc.peer, "sid", "tid")
+		assert.Equal(t, tc.keep, keep, tc.name)
+		assert.True(t, errors.Is(err, tc.err), tc.name)
+	}
+
+	c.Wait = 10 * time.Millisecond
+	keep, err := Keep(context.Background(), c, &fakePeer{states: []recording.State{recording.StateRecording}}, "sid", "tid")
+	assert.True(t, keep)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
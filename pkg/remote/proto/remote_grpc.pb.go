@@ -0,0 +1,137 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// RemoteClient is the client API for Remote service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RemoteClient interface {
+	// Element runs one element for the life of the stream. The first
+	// Upstream message must be Open.
+	Element(ctx context.Context, opts ...grpc.CallOption) (Remote_ElementClient, error)
+}
+
+type remoteClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRemoteClient(cc grpc.ClientConnInterface) RemoteClient {
+	return &remoteClient{cc}
+}
+
+func (c *remoteClient) Element(ctx context.Context, opts ...grpc.CallOption) (Remote_ElementClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Remote_ServiceDesc.Streams[0], "/remote.Remote/Element", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &remoteElementClient{stream}
+	return x, nil
+}
+
+type Remote_ElementClient interface {
+	Send(*Upstream) error
+	Recv() (*Downstream, error)
+	grpc.ClientStream
+}
+
+type remoteElementClient struct {
+	grpc.ClientStream
+}
+
+func (x *remoteElementClient) Send(m *Upstream) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *remoteElementClient) Recv() (*Downstream, error) {
+	m := new(Downstream)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RemoteServer is the server API for Remote service.
+// All implementations must embed UnimplementedRemoteServer
+// for forward compatibility
+type RemoteServer interface {
+	// Element runs one element for the life of the stream. The first
+	// Upstream message must be Open.
+	Element(Remote_ElementServer) error
+	mustEmbedUnimplementedRemoteServer()
+}
+
+// UnimplementedRemoteServer must be embedded to have forward compatible implementations.
+type UnimplementedRemoteServer struct {
+}
+
+func (UnimplementedRemoteServer) Element(Remote_ElementServer) error {
+	return status.Errorf(codes.Unimplemented, "method Element not implemented")
+}
+func (UnimplementedRemoteServer) mustEmbedUnimplementedRemoteServer() {}
+
+// UnsafeRemoteServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RemoteServer will
+// result in compilation errors.
+type UnsafeRemoteServer interface {
+	mustEmbedUnimplementedRemoteServer()
+}
+
+func RegisterRemoteServer(s grpc.ServiceRegistrar, srv RemoteServer) {
+	s.RegisterService(&Remote_ServiceDesc, srv)
+}
+
+func _Remote_Element_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RemoteServer).Element(&remoteElementServer{stream})
+}
+
+type Remote_ElementServer interface {
+	Send(*Downstream) error
+	Recv() (*Upstream, error)
+	grpc.ServerStream
+}
+
+type remoteElementServer struct {
+	grpc.ServerStream
+}
+
+func (x *remoteElementServer) Send(m *Downstream) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *remoteElementServer) Recv() (*Upstream, error) {
+	m := new(Upstream)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Remote_ServiceDesc is the grpc.ServiceDesc for Remote service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Remote_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "remote.Remote",
+	HandlerType: (*RemoteServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Element",
+			Handler:       _Remote_Element_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/remote/proto/remote.proto",
+}
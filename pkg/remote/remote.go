@@ -0,0 +1,304 @@
+// Package remote runs elements on other machines, e.g. a GPU transcoding
+// fleet. Samples are streamed to a Server over gRPC, which runs the element
+// and streams its output back.
+//
+// Flow control is credit based: the client may send Window samples, and the
+// server grants more as the element gets through them. Samples written while
+// the client is out of credit or disconnected are dropped, so a slow or
+// unreachable remote never stalls the local pipeline.
+package remote
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	pb "github.com/pion/ion-avp/pkg/remote/proto"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultWindow is the number of samples in flight when none is set
+	DefaultWindow = 64
+	closeTimeout  = 5 * time.Second
+	maxBackoff    = 30 * time.Second
+)
+
+// ErrPayload is returned for samples whose payload is not a byte slice
+var ErrPayload = errors.New("remote: only []byte payloads can be sent")
+
+// Wrap returns an ElementFun running the element eid on the remote at cc
+func Wrap(cc grpc.ClientConnInterface, eid string, window uint32) avp.ElementFun {
+	return func(sid, pid, tid string, config []byte) avp.Element {
+		return New(cc, eid, sid, pid, tid, config, window)
+	}
+}
+
+// Element proxies samples to an element on a remote. Samples written by
+// the remote element are passed to attached elements.
+type Element struct {
+	elements.Node
+	client pb.RemoteClient
+	open   *pb.Open
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	watch  sync.Once
+
+	// children guards Node, which is written to from the receive goroutine
+	children sync.RWMutex
+
+	// credit is updated by the receive goroutine without taking a lock, so
+	// receiving never waits on a send blocked by HTTP/2 flow control
+	credit uint32
+
+	// sending serializes sends, which may block, and is never held with mu
+	sending chan struct{}
+	dropped uint64
+
+	mu     sync.Mutex
+	stream pb.Remote_ElementClient
+	closed bool
+}
+
+// New opens the element eid on the remote at cc, reconnecting with
+// backoff whenever the stream breaks
+func New(cc grpc.ClientConnInterface, eid, sid, pid, tid string, config []byte, window uint32) *Element {
+	if window == 0 {
+		window = DefaultWindow
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Element{
+		client: pb.NewRemoteClient(cc),
+		open: &pb.Open{
+			Eid:    eid,
+			Sid:    sid,
+			Pid:    pid,
+			Tid:    tid,
+			Config: config,
+			Window: window,
+		},
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		sending: make(chan struct{}, 1),
+	}
+	go e.run()
+	return e
+}
+
+func (e *Element) run() {
+	defer close(e.done)
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := e.connect()
+
+		e.mu.Lock()
+		closed := e.closed
+		e.mu.Unlock()
+		if closed || e.ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) > maxBackoff {
+			backoff = time.Second
+		}
+		log.Warnf("remote element %s: %v, reconnecting in %s", e.open.Eid, err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-e.ctx.Done():
+			return
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connect opens a stream and receives from it until it breaks
+func (e *Element) connect() error {
+	stream, err := e.client.Element(e.ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.Upstream{Payload: &pb.Upstream_Open{Open: e.open}}); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if e.closed {
+		stream.CloseSend() // nolint: errcheck
+	} else {
+		e.stream = stream
+		atomic.StoreUint32(&e.credit, e.open.Window)
+	}
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.stream = nil
+		e.mu.Unlock()
+	}()
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch p := msg.Payload.(type) {
+		case *pb.Downstream_Sample:
+			e.children.RLock()
+			if err := e.Node.Write(fromProto(p.Sample)); err != nil {
+				log.Errorf("remote: error writing sample: %v", err)
+			}
+			e.children.RUnlock()
+		case *pb.Downstream_Credit:
+			atomic.AddUint32(&e.credit, p.Credit.Samples)
+		}
+	}
+}
+
+// Attach attaches an element to receive the remote element's output
+func (e *Element) Attach(el avp.Element) {
+	e.children.Lock()
+	defer e.children.Unlock()
+	e.Node.Attach(el)
+}
+
+// Write sends a sample to the remote, dropping it while disconnected or
+// out of credit
+func (e *Element) Write(s *avp.Sample) error {
+	sample, err := toProto(s)
+	if err != nil {
+		return err
+	}
+
+	e.sending <- struct{}{}
+	defer func() { <-e.sending }()
+
+	e.mu.Lock()
+	stream := e.stream
+	e.mu.Unlock()
+
+	if stream == nil || atomic.LoadUint32(&e.credit) == 0 {
+		if e.dropped++; e.dropped%100 == 1 {
+			log.Warnf("remote element %s not keeping up, %d samples dropped", e.open.Eid, e.dropped)
+		}
+		return nil
+	}
+	if err := stream.Send(&pb.Upstream{Payload: &pb.Upstream_Sample{Sample: sample}}); err != nil {
+		// Recv fails too and the stream is reopened
+		log.Errorf("remote element %s: send failed: %v", e.open.Eid, err)
+		e.mu.Lock()
+		if e.stream == stream {
+			e.stream = nil
+		}
+		e.mu.Unlock()
+		return nil
+	}
+	atomic.AddUint32(&e.credit, ^uint32(0))
+	return nil
+}
+
+// WriteContext is Write, abandoning the stream if ctx is canceled so a
+// send blocked on a stalled remote returns
+func (e *Element) WriteContext(ctx context.Context, s *avp.Sample) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.watch.Do(func() {
+		go func() {
+			select {
+			case <-ctx.Done():
+				e.cancel()
+			case <-e.ctx.Done():
+			}
+		}()
+	})
+	return e.Write(s)
+}
+
+// Close ends the stream, giving the remote element time to flush its
+// output, then closes attached elements
+func (e *Element) Close() {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.closed = true
+	stream := e.stream
+	e.mu.Unlock()
+
+	if stream == nil {
+		e.cancel()
+	} else {
+		// CloseSend must not race a send, which may be stuck on a stalled
+		// remote until the stream is abandoned
+		select {
+		case e.sending <- struct{}{}:
+			stream.CloseSend() // nolint: errcheck
+			<-e.sending
+		case <-time.After(closeTimeout):
+			e.cancel()
+		}
+	}
+
+	select {
+	case <-e.done:
+	case <-time.After(closeTimeout):
+		log.Warnf("remote element %s did not close, abandoning it", e.open.Eid)
+	}
+	e.cancel()
+	<-e.done
+
+	e.children.Lock()
+	defer e.children.Unlock()
+	e.Node.Close()
+}
+
+func toProto(s *avp.Sample) (*pb.Sample, error) {
+	payload, ok := s.Payload.([]byte)
+	if !ok {
+		return nil, ErrPayload
+	}
+	p := &pb.Sample{
+		Id:             s.ID,
+		Type:           int32(s.Type),
+		Timestamp:      s.Timestamp,
+		SequenceNumber: uint32(s.SequenceNumber),
+		Payload:        payload,
+		ClockRate:      s.ClockRate,
+	}
+	if !s.CaptureTime.IsZero() {
+		p.CaptureTime = s.CaptureTime.UnixNano()
+	}
+	if l := s.AudioLevel; l != nil {
+		p.AudioLevel = &pb.AudioLevel{Level: uint32(l.Level), Voice: l.Voice}
+	}
+	return p, nil
+}
+
+func fromProto(s *pb.Sample) *avp.Sample {
+	sample := &avp.Sample{
+		ID:             s.Id,
+		Type:           int(s.Type),
+		Timestamp:      s.Timestamp,
+		SequenceNumber: uint16(s.SequenceNumber),
+		ClockRate:      s.ClockRate,
+		Payload:        s.Payload,
+	}
+	if s.CaptureTime != 0 {
+		sample.CaptureTime = time.Unix(0, s.CaptureTime).UTC()
+	}
+	if l := s.AudioLevel; l != nil {
+		sample.AudioLevel = &avp.AudioLevel{Level: uint8(l.Level), Voice: l.Voice}
+	}
+	return sample
+}
@@ -0,0 +1,310 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	pb "github.com/pion/ion-avp/pkg/remote/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// upper echoes payloads upper cased, waiting for gate if set
+type upper struct {
+	elements.Node
+	gate chan struct{}
+}
+
+func (u *upper) Write(s *avp.Sample) error {
+	if u.gate != nil {
+		<-u.gate
+	}
+	s.Payload = bytes.ToUpper(s.Payload.([]byte))
+	return u.Node.Write(s)
+}
+
+// amplify writes n samples of size bytes for each sample written to it
+type amplify struct {
+	elements.Node
+	n, size int
+}
+
+func (a *amplify) Write(s *avp.Sample) error {
+	for i := 0; i < a.n; i++ {
+		if err := a.Node.Write(&avp.Sample{ID: s.ID, Payload: make([]byte, a.size)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count counts samples written to it
+type count struct {
+	elements.Leaf
+	n int64
+}
+
+func (c *count) Write(s *avp.Sample) error {
+	atomic.AddInt64(&c.n, 1)
+	return nil
+}
+
+type collect struct {
+	elements.Leaf
+	ch     chan *avp.Sample
+	closed chan struct{}
+}
+
+func newCollect() *collect {
+	return &collect{ch: make(chan *avp.Sample, 10), closed: make(chan struct{})}
+}
+
+func (c *collect) Write(s *avp.Sample) error {
+	c.ch <- s
+	return nil
+}
+
+func (c *collect) Close() {
+	close(c.closed)
+}
+
+// remote serves elements over an in memory listener that can be restarted
+type remote struct {
+	mu  sync.Mutex
+	lis *bufconn.Listener
+	srv *grpc.Server
+}
+
+func (r *remote) start(elems map[string]avp.ElementFun) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lis = bufconn.Listen(1 << 20)
+	r.srv = grpc.NewServer()
+	pb.RegisterRemoteServer(r.srv, NewServer(elems))
+	go r.srv.Serve(r.lis) // nolint: errcheck
+}
+
+func (r *remote) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.srv.Stop()
+}
+
+func (r *remote) dial(t *testing.T) *grpc.ClientConn {
+	cc, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			return r.lis.Dial()
+		}))
+	require.NoError(t, err)
+	return cc
+}
+
+func waitSample(t *testing.T, c *collect) *avp.Sample {
+	t.Helper()
+	select {
+	case s := <-c.ch:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for sample")
+		return nil
+	}
+}
+
+// write retries until the remote is connected and answers
+func write(t *testing.T, e *Element, c *collect, payload string) *avp.Sample {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		assert.NoError(t, e.Write(&avp.Sample{ID: "track", Payload: []byte(payload)}))
+		select {
+		case s := <-c.ch:
+			return s
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	t.Fatal("remote never answered")
+	return nil
+}
+
+func TestSampleProto(t *testing.T) {
+	in := &avp.Sample{
+		ID:             "video",
+		Type:           -1,
+		Timestamp:      90000,
+		SequenceNumber: 65535,
+		ClockRate:      44100,
+		CaptureTime:    time.Date(2021, 2, 1, 10, 0, 0, 123456789, time.UTC),
+		AudioLevel:     &avp.AudioLevel{Level: 30, Voice: true},
+		Payload:        []byte{1, 2, 3},
+	}
+	// Every field is set, so fields added to Sample must be sent too
+	v := reflect.ValueOf(*in)
+	for i := 0; i < v.NumField(); i++ {
+		assert.False(t, v.Field(i).IsZero(), v.Type().Field(i).Name)
+	}
+	p, err := toProto(in)
+	require.NoError(t, err)
+	b, err := proto.Marshal(p)
+	require.NoError(t, err)
+	var out pb.Sample
+	require.NoError(t, proto.Unmarshal(b, &out))
+	assert.Equal(t, in, fromProto(&out))
+
+	// Unknown when unset
+	p, err = toProto(&avp.Sample{Payload: []byte{}})
+	require.NoError(t, err)
+	s := fromProto(p)
+	assert.True(t, s.CaptureTime.IsZero())
+	assert.Nil(t, s.AudioLevel)
+}
+
+func TestElement(t *testing.T) {
+	var r remote
+	r.start(map[string]avp.ElementFun{
+		"upper": func(sid, pid, tid string, config []byte) avp.Element {
+			return &upper{}
+		},
+	})
+	defer r.stop()
+	cc := r.dial(t)
+	defer cc.Close()
+
+	c := newCollect()
+	e := New(cc, "upper", "sid", "pid", "tid", nil, 0)
+	e.Attach(c)
+
+	s := write(t, e, c, "hello")
+	assert.Equal(t, "track", s.ID)
+	assert.Equal(t, []byte("HELLO"), s.Payload)
+
+	assert.Equal(t, ErrPayload, e.Write(&avp.Sample{Payload: "text"}))
+
+	e.Close()
+	<-c.closed
+}
+
+func TestElement_FlowControl(t *testing.T) {
+	gate := make(chan struct{})
+	var r remote
+	r.start(map[string]avp.ElementFun{
+		"upper": func(sid, pid, tid string, config []byte) avp.Element {
+			return &upper{gate: gate}
+		},
+	})
+	defer r.stop()
+	cc := r.dial(t)
+	defer cc.Close()
+
+	c := newCollect()
+	e := New(cc, "upper", "sid", "pid", "tid", nil, 1)
+	e.Attach(c)
+
+	// Wait for the stream, then use up the only credit
+	assert.Eventually(t, func() bool {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.stream != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, e.Write(&avp.Sample{Payload: []byte("a")}))
+	assert.NoError(t, e.Write(&avp.Sample{Payload: []byte("dropped")}))
+
+	close(gate)
+	assert.Equal(t, []byte("A"), waitSample(t, c).Payload)
+	assert.Equal(t, []byte("B"), write(t, e, c, "b").Payload)
+
+	e.Close()
+	<-c.closed
+	for len(c.ch) > 0 {
+		assert.NotEqual(t, []byte("DROPPED"), (<-c.ch).Payload)
+	}
+}
+
+func TestElement_LargeOutput(t *testing.T) {
+	var r remote
+	r.start(map[string]avp.ElementFun{
+		"amplify": func(sid, pid, tid string, config []byte) avp.Element {
+			return &amplify{n: 4, size: 64 << 10}
+		},
+	})
+	defer r.stop()
+	cc := r.dial(t)
+	defer cc.Close()
+
+	c := &count{}
+	e := New(cc, "amplify", "sid", "pid", "tid", nil, 0)
+	e.Attach(c)
+	assert.Eventually(t, func() bool {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.stream != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Output fills the flow control windows while input is still being
+	// sent, so credit must be received while a send is blocked
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			assert.NoError(t, e.Write(&avp.Sample{ID: "track", Payload: make([]byte, 16<<10)}))
+		}
+		e.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("deadlocked sending to the remote")
+	}
+	assert.NotZero(t, atomic.LoadInt64(&c.n))
+}
+
+func TestElement_Reconnect(t *testing.T) {
+	elems := map[string]avp.ElementFun{
+		"upper": func(sid, pid, tid string, config []byte) avp.Element {
+			return &upper{}
+		},
+	}
+	var r remote
+	r.start(elems)
+	cc := r.dial(t)
+	defer cc.Close()
+
+	c := newCollect()
+	e := New(cc, "upper", "sid", "pid", "tid", nil, 0)
+	e.Attach(c)
+	assert.Equal(t, []byte("ONE"), write(t, e, c, "one").Payload)
+
+	r.stop()
+	r.start(elems)
+	defer r.stop()
+	assert.Equal(t, []byte("TWO"), write(t, e, c, "two").Payload)
+
+	e.Close()
+	<-c.closed
+}
+
+func TestServer_NotFound(t *testing.T) {
+	var r remote
+	r.start(map[string]avp.ElementFun{})
+	defer r.stop()
+	cc := r.dial(t)
+	defer cc.Close()
+
+	stream, err := pb.NewRemoteClient(cc).Element(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.Upstream{Payload: &pb.Upstream_Open{Open: &pb.Open{Eid: "missing"}}}))
+	_, err = stream.Recv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package remote
+
+import (
+	"io"
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	pb "github.com/pion/ion-avp/pkg/remote/proto"
+	log "github.com/pion/ion-log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server hosts elements for remote pipelines
+type Server struct {
+	pb.UnimplementedRemoteServer
+	elems map[string]avp.ElementFun
+}
+
+// NewServer returns a server hosting the given elements
+func NewServer(elems map[string]avp.ElementFun) *Server {
+	return &Server{elems: elems}
+}
+
+// Element runs an element for the life of the stream
+func (s *Server) Element(stream pb.Remote_ElementServer) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	open := msg.GetOpen()
+	if open == nil {
+		return status.Error(codes.InvalidArgument, "first message must be open")
+	}
+	f, ok := s.elems[open.Eid]
+	if !ok {
+		return status.Errorf(codes.NotFound, "element %s not found", open.Eid)
+	}
+
+	log.Infof("remote element %s opened for session %s track %s", open.Eid, open.Sid, open.Tid)
+	e := f(open.Sid, open.Pid, open.Tid, open.Config)
+	out := &sender{stream: stream}
+	e.Attach(out)
+	err = serve(stream, out, e, open.Window)
+	// Close before returning, the element may still send output
+	e.Close()
+	log.Infof("remote element %s closed for session %s track %s", open.Eid, open.Sid, open.Tid)
+	return err
+}
+
+func serve(stream pb.Remote_ElementServer, out *sender, e avp.Element, window uint32) error {
+	if window == 0 {
+		window = DefaultWindow
+	}
+	grant := window / 2
+	if grant == 0 {
+		grant = 1
+	}
+
+	var processed uint32
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		sample := msg.GetSample()
+		if sample == nil {
+			continue
+		}
+		if err := e.Write(fromProto(sample)); err != nil {
+			log.Errorf("remote: error writing sample: %v", err)
+		}
+		if processed++; processed >= grant {
+			credit := &pb.Downstream{Payload: &pb.Downstream_Credit{Credit: &pb.Credit{Samples: processed}}}
+			if err := out.send(credit); err != nil {
+				return err
+			}
+			processed = 0
+		}
+	}
+}
+
+// sender is attached to the hosted element to stream its output back.
+// Credit is sent through it too, as stream sends must not be concurrent.
+type sender struct {
+	mu     sync.Mutex
+	stream pb.Remote_ElementServer
+}
+
+func (s *sender) Write(sample *avp.Sample) error {
+	p, err := toProto(sample)
+	if err != nil {
+		return err
+	}
+	return s.send(&pb.Downstream{Payload: &pb.Downstream_Sample{Sample: p}})
+}
+
+func (s *sender) send(msg *pb.Downstream) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stream.Send(msg)
+}
+
+func (s *sender) Attach(e avp.Element) {}
+
+func (s *sender) Close() {}
@@ -0,0 +1,299 @@
+// Package retry retries calls to network sinks with exponential backoff,
+// and stops calling a sink that keeps failing until it has had time to
+// recover. Each Retrier keeps metrics, listed by All.
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/pion/ion-log"
+)
+
+// ErrOpen is returned without calling the sink while its circuit is open
+var ErrOpen = errors.New("retry: circuit open")
+
+// Policy configures retries and the circuit breaker. Zero fields use the
+// defaults.
+type Policy struct {
+	// Attempts is the most calls made per Do, defaults to 5
+	Attempts int `mapstructure:"attempts"`
+	// Initial is the wait before the first retry, defaults to 500ms
+	Initial time.Duration `mapstructure:"initial"`
+	// Max caps the wait between retries, defaults to 30s
+	Max time.Duration `mapstructure:"max"`
+	// Threshold is the number of failed Do calls in a row that opens the
+	// circuit, defaults to 5. Negative disables the breaker.
+	Threshold int `mapstructure:"threshold"`
+	// Cooldown is how long the circuit stays open, defaults to 30s
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+var (
+	defaultsMu sync.Mutex
+	defaults   = Policy{
+		Attempts:  5,
+		Initial:   500 * time.Millisecond,
+		Max:       30 * time.Second,
+		Threshold: 5,
+		Cooldown:  30 * time.Second,
+	}
+)
+
+// SetDefaults overrides the defaults used for zero policy fields by
+// retriers created afterwards
+func SetDefaults(p Policy) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaults = p.withDefaults(defaults)
+}
+
+func (p Policy) withDefaults(d Policy) Policy {
+	if p.Attempts <= 0 {
+		p.Attempts = d.Attempts
+	}
+	if p.Initial <= 0 {
+		p.Initial = d.Initial
+	}
+	if p.Max <= 0 {
+		p.Max = d.Max
+	}
+	if p.Threshold == 0 {
+		p.Threshold = d.Threshold
+	}
+	if p.Cooldown <= 0 {
+		p.Cooldown = d.Cooldown
+	}
+	return p
+}
+
+type permanent struct {
+	err error
+}
+
+func (p *permanent) Error() string {
+	return p.err.Error()
+}
+
+func (p *permanent) Unwrap() error {
+	return p.err
+}
+
+// Permanent marks an error retrying won't fix, e.g. a rejected request
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanent{err: err}
+}
+
+// strip removes the Permanent mark, which only matters to Do
+func strip(err error) error {
+	if p, ok := err.(*permanent); ok {
+		return p.err
+	}
+	return err
+}
+
+// Status marks err permanent if the http status code shows the request was
+// rejected, rather than the server failing or asking to slow down
+func Status(code int, err error) error {
+	if code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
+
+// PostJSON posts body to url, e.g. for webhooks
+func (r *Retrier) PostJSON(ctx context.Context, client *http.Client, url string, body []byte) error {
+	return r.Do(ctx, func() error {
+		res, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return Status(res.StatusCode, fmt.Errorf("POST %s: %s", url, res.Status))
+		}
+		return nil
+	})
+}
+
+// Stats are the metrics of a Retrier
+type Stats struct {
+	Name     string
+	Calls    uint64 // Do calls
+	Retries  uint64 // calls to the sink after the first of a Do
+	Failures uint64 // Do calls that failed
+	Rejected uint64 // Do calls refused while the circuit was open
+	Open     bool   // whether the circuit is open
+}
+
+// Retrier retries calls to one sink
+type Retrier struct {
+	// counters are updated atomically, so they come first to be 64-bit
+	// aligned on 32-bit platforms
+	calls    uint64
+	retries  uint64
+	failed   uint64
+	rejected uint64
+
+	name   string
+	policy Policy
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Retrier{}
+)
+
+// New creates a retrier for the sink called name. Retriers are listed by
+// All under their name; a later retrier replaces an earlier one of the
+// same name.
+func New(name string, p Policy) *Retrier {
+	defaultsMu.Lock()
+	p = p.withDefaults(defaults)
+	defaultsMu.Unlock()
+
+	r := &Retrier{name: name, policy: p}
+	registryMu.Lock()
+	registry[name] = r
+	registryMu.Unlock()
+	return r
+}
+
+// All returns the stats of every retrier, sorted by name
+func All() []Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	stats := make([]Stats, 0, len(registry))
+	for _, r := range registry {
+		stats = append(stats, r.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Stats returns the retrier's metrics
+func (r *Retrier) Stats() Stats {
+	r.mu.Lock()
+	open := time.Now().Before(r.openUntil)
+	r.mu.Unlock()
+	return Stats{
+		Name:     r.name,
+		Calls:    atomic.LoadUint64(&r.calls),
+		Retries:  atomic.LoadUint64(&r.retries),
+		Failures: atomic.LoadUint64(&r.failed),
+		Rejected: atomic.LoadUint64(&r.rejected),
+		Open:     open,
+	}
+}
+
+// Do calls f until it succeeds, returns a Permanent error, the attempts
+// run out or ctx is done. A nil Retrier calls f once.
+func (r *Retrier) Do(ctx context.Context, f func() error) error {
+	if r == nil {
+		return strip(f())
+	}
+	atomic.AddUint64(&r.calls, 1)
+	if !r.allow() {
+		atomic.AddUint64(&r.rejected, 1)
+		return ErrOpen
+	}
+
+	wait := r.policy.Initial
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = f(); err == nil {
+			r.done(true)
+			return nil
+		}
+		var p *permanent
+		if errors.As(err, &p) {
+			// The sink answered, so it is up
+			atomic.AddUint64(&r.failed, 1)
+			r.done(true)
+			return strip(err)
+		}
+		if attempt >= r.policy.Attempts {
+			break
+		}
+
+		// Full jitter keeps clients that failed together from retrying together
+		d := time.Duration(rand.Int63n(int64(wait)) + 1) // nolint: gosec
+		log.Debugf("retry %s: %v, attempt %d in %s", r.name, err, attempt+1, d)
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			// Not the sink's fault, so leave the breaker be
+			atomic.AddUint64(&r.failed, 1)
+			r.mu.Lock()
+			r.probing = false
+			r.mu.Unlock()
+			return ctx.Err()
+		}
+		atomic.AddUint64(&r.retries, 1)
+		if wait *= 2; wait > r.policy.Max {
+			wait = r.policy.Max
+		}
+	}
+	r.done(false)
+	return err
+}
+
+// allow reports whether the sink may be called. Once the cooldown has
+// passed one call is let through to probe whether the sink recovered.
+func (r *Retrier) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.openUntil.IsZero() {
+		return true
+	}
+	if time.Now().Before(r.openUntil) || r.probing {
+		return false
+	}
+	r.probing = true
+	return true
+}
+
+func (r *Retrier) done(ok bool) {
+	if !ok {
+		atomic.AddUint64(&r.failed, 1)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.probing = false
+	if ok {
+		if !r.openUntil.IsZero() {
+			log.Infof("retry %s: circuit closed", r.name)
+		}
+		r.failures = 0
+		r.openUntil = time.Time{}
+		return
+	}
+	r.failures++
+	if r.policy.Threshold > 0 && r.failures >= r.policy.Threshold {
+		if r.openUntil.IsZero() {
+			log.Warnf("retry %s: %d failures in a row, circuit open for %s", r.name, r.failures, r.policy.Cooldown)
+		}
+		r.openUntil = time.Now().Add(r.policy.Cooldown)
+	}
+}
@@ -0,0 +1,124 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTransient = errors.New("transient")
+
+func fast(name string, threshold int) *Retrier {
+	return New(name, Policy{
+		Attempts:  3,
+		Initial:   time.Millisecond,
+		Max:       2 * time.Millisecond,
+		Threshold: threshold,
+		Cooldown:  50 * time.Millisecond,
+	})
+}
+
+func TestRetrier_Do(t *testing.T) {
+	r := fast("do", -1)
+
+	calls := 0
+	assert.NoError(t, r.Do(context.Background(), func() error {
+		if calls++; calls < 3 {
+			return errTransient
+		}
+		return nil
+	}))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	assert.Equal(t, errTransient, r.Do(context.Background(), func() error {
+		calls++
+		return errTransient
+	}))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	errRejected := errors.New("rejected")
+	assert.Equal(t, errRejected, r.Do(context.Background(), func() error {
+		calls++
+		return Permanent(errRejected)
+	}))
+	assert.Equal(t, 1, calls)
+
+	st := r.Stats()
+	assert.Equal(t, "do", st.Name)
+	assert.Equal(t, uint64(3), st.Calls)
+	assert.Equal(t, uint64(4), st.Retries)
+	assert.Equal(t, uint64(2), st.Failures)
+	assert.False(t, st.Open)
+	assert.Contains(t, All(), st)
+}
+
+func TestRetrier_Nil(t *testing.T) {
+	var r *Retrier
+	err := errors.New("once")
+	assert.Equal(t, err, r.Do(context.Background(), func() error {
+		return Permanent(err)
+	}))
+}
+
+func TestRetrier_Canceled(t *testing.T) {
+	r := New("canceled", Policy{Initial: time.Hour, Max: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, r.Do(ctx, func() error {
+		return errTransient
+	}))
+}
+
+func TestRetrier_Breaker(t *testing.T) {
+	r := fast("breaker", 2)
+	fail := func() error { return errTransient }
+
+	assert.Equal(t, errTransient, r.Do(context.Background(), fail))
+	assert.Equal(t, errTransient, r.Do(context.Background(), fail))
+	assert.True(t, r.Stats().Open)
+
+	called := false
+	assert.Equal(t, ErrOpen, r.Do(context.Background(), func() error {
+		called = true
+		return nil
+	}))
+	assert.False(t, called)
+	assert.Equal(t, uint64(1), r.Stats().Rejected)
+
+	// After the cooldown a probe is let through and closes the circuit
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, r.Do(context.Background(), func() error { return nil }))
+	assert.False(t, r.Stats().Open)
+	assert.NoError(t, r.Do(context.Background(), func() error { return nil }))
+}
+
+func TestRetrier_PostJSON(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	r := fast("post", -1)
+	assert.NoError(t, r.PostJSON(context.Background(), srv.Client(), srv.URL, []byte("{}")))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Client errors aren't retried
+	assert.Error(t, r.PostJSON(context.Background(), srv.Client(), srv.URL, []byte("{}")))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
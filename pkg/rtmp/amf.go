@@ -0,0 +1,199 @@
+package rtmp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AMF0 type markers
+const (
+	amfNumber      = 0x00
+	amfBoolean     = 0x01
+	amfString      = 0x02
+	amfObject      = 0x03
+	amfNull        = 0x05
+	amfUndefined   = 0x06
+	amfECMAArray   = 0x08
+	amfObjectEnd   = 0x09
+	amfStrictArray = 0x0a
+	amfDate        = 0x0b
+	amfLongString  = 0x0c
+)
+
+// Object is an AMF0 object, e.g. the command object of connect or the
+// information object of onStatus
+type Object map[string]interface{}
+
+// encodeAMF encodes values of commands: numbers, booleans, strings,
+// objects and nil. Object properties are written sorted by name.
+func encodeAMF(values ...interface{}) []byte {
+	var b []byte
+	for _, v := range values {
+		b = appendAMF(b, v)
+	}
+	return b
+}
+
+func appendAMF(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, amfNull)
+	case bool:
+		if v {
+			return append(b, amfBoolean, 1)
+		}
+		return append(b, amfBoolean, 0)
+	case int:
+		return appendAMF(b, float64(v))
+	case uint32:
+		return appendAMF(b, float64(v))
+	case float64:
+		b = append(b, amfNumber)
+		return append(b, be64(math.Float64bits(v))...)
+	case string:
+		b = append(b, amfString)
+		return appendName(b, v)
+	case Object:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b = append(b, amfObject)
+		for _, name := range names {
+			b = appendName(b, name)
+			b = appendAMF(b, v[name])
+		}
+		return append(b, 0, 0, amfObjectEnd)
+	default:
+		panic(fmt.Sprintf("rtmp: can't encode %T as AMF0", v))
+	}
+}
+
+// appendName appends a string without its marker, as object property
+// names are written
+func appendName(b []byte, s string) []byte {
+	b = append(b, byte(len(s)>>8), byte(len(s)))
+	return append(b, s...)
+}
+
+// decodeAMF decodes the values of a command. Numbers and dates are
+// float64, ECMA arrays Objects, strict arrays []interface{}, and null and
+// undefined nil.
+func decodeAMF(b []byte) ([]interface{}, error) {
+	d := amfDecoder{b: b}
+	var values []interface{}
+	for len(d.b) > 0 {
+		v, err := d.value()
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+type amfDecoder struct {
+	b []byte
+}
+
+// take returns the next n bytes
+func (d *amfDecoder) take(n int) ([]byte, error) {
+	if len(d.b) < n {
+		return nil, fmt.Errorf("%w: truncated AMF0", ErrProtocol)
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
+
+func (d *amfDecoder) name() (string, error) {
+	n, err := d.take(2)
+	if err != nil {
+		return "", err
+	}
+	s, err := d.take(int(binary.BigEndian.Uint16(n)))
+	return string(s), err
+}
+
+func (d *amfDecoder) value() (interface{}, error) {
+	marker, err := d.take(1)
+	if err != nil {
+		return nil, err
+	}
+	switch marker[0] {
+	case amfNumber:
+		b, err := d.take(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case amfBoolean:
+		b, err := d.take(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case amfString:
+		return d.name()
+	case amfLongString:
+		n, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.take(int(binary.BigEndian.Uint32(n)))
+		return string(s), err
+	case amfNull, amfUndefined:
+		return nil, nil
+	case amfECMAArray:
+		// The count is only a hint, the properties end like an object's
+		if _, err := d.take(4); err != nil {
+			return nil, err
+		}
+		return d.object()
+	case amfObject:
+		return d.object()
+	case amfStrictArray:
+		n, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		count := binary.BigEndian.Uint32(n)
+		var values []interface{}
+		for i := uint32(0); i < count; i++ {
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case amfDate:
+		b, err := d.take(10) // ms since the epoch, and an unused time zone
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	default:
+		return nil, fmt.Errorf("%w: AMF0 marker %#x", ErrProtocol, marker[0])
+	}
+}
+
+func (d *amfDecoder) object() (Object, error) {
+	o := Object{}
+	for {
+		name, err := d.name()
+		if err != nil {
+			return nil, err
+		}
+		if name == "" && len(d.b) > 0 && d.b[0] == amfObjectEnd {
+			d.b = d.b[1:]
+			return o, nil
+		}
+		if o[name], err = d.value(); err != nil {
+			return nil, err
+		}
+	}
+}
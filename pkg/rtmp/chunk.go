@@ -0,0 +1,217 @@
+package rtmp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Message types
+const (
+	msgSetChunkSize  = 1
+	msgAck           = 3
+	msgUserControl   = 4
+	msgWindowAckSize = 5
+	msgAudio         = 8
+	msgVideo         = 9
+	msgCommand       = 20 // AMF0
+)
+
+// Chunk stream ids messages are sent on
+const (
+	csControl = 2
+	csCommand = 3
+	csAudio   = 4
+	csVideo   = 6
+)
+
+// Chunk sizes, the protocol's default and the one the client sends with
+const (
+	defaultChunkSize = 128
+	chunkSize        = 4096
+)
+
+// extended marks a timestamp too large for its 3 byte field, written after
+// the message header instead
+const extended = 0xffffff
+
+// message is an RTMP message, split into chunks on the wire
+type message struct {
+	typ       uint8
+	stream    uint32
+	timestamp uint32 // ms
+	data      []byte
+}
+
+// chunkWriter splits messages into chunks. Each message starts with a full
+// header, so the writer keeps no state between them.
+type chunkWriter struct {
+	w    io.Writer
+	size int
+}
+
+// write sends m on chunk stream cs, which must be below 64
+func (c *chunkWriter) write(cs uint8, m message) error {
+	field := m.timestamp
+	if field >= extended {
+		field = extended
+	}
+	b := make([]byte, 0, 16+len(m.data)+len(m.data)/c.size*5)
+	b = append(b, cs)
+	b = append(b, be24(field)...)
+	b = append(b, be24(uint32(len(m.data)))...)
+	b = append(b, m.typ)
+	b = append(b, le32(m.stream)...)
+	if field == extended {
+		b = append(b, be32(m.timestamp)...)
+	}
+	for i := 0; i < len(m.data); i += c.size {
+		if i > 0 {
+			// Continuation chunks have no message header
+			b = append(b, 0xc0|cs)
+			if field == extended {
+				b = append(b, be32(m.timestamp)...)
+			}
+		}
+		end := i + c.size
+		if end > len(m.data) {
+			end = len(m.data)
+		}
+		b = append(b, m.data[i:end]...)
+	}
+	_, err := c.w.Write(b)
+	return err
+}
+
+// chunkStream is the state of a chunk stream being read, the header of its
+// last message and the part read of the current one
+type chunkStream struct {
+	timestamp uint32
+	delta     uint32
+	length    uint32
+	typ       uint8
+	stream    uint32
+	extended  bool
+	reading   bool
+	data      []byte
+}
+
+// chunkReader reassembles messages from their chunks
+type chunkReader struct {
+	r       *bufio.Reader
+	size    uint32
+	streams map[uint32]*chunkStream
+}
+
+func newChunkReader(r io.Reader) *chunkReader {
+	return &chunkReader{
+		r:       bufio.NewReader(r),
+		size:    defaultChunkSize,
+		streams: map[uint32]*chunkStream{},
+	}
+}
+
+// read returns the next complete message
+func (c *chunkReader) read() (message, error) {
+	var b [11]byte
+	for {
+		if _, err := io.ReadFull(c.r, b[:1]); err != nil {
+			return message{}, err
+		}
+		format, id := b[0]>>6, uint32(b[0]&0x3f)
+		switch id {
+		case 0:
+			if _, err := io.ReadFull(c.r, b[:1]); err != nil {
+				return message{}, err
+			}
+			id = 64 + uint32(b[0])
+		case 1:
+			if _, err := io.ReadFull(c.r, b[:2]); err != nil {
+				return message{}, err
+			}
+			id = 64 + uint32(b[0]) + uint32(b[1])<<8
+		}
+		cs := c.streams[id]
+		if cs == nil {
+			if format != 0 {
+				return message{}, fmt.Errorf("%w: chunk stream %d starts without a header", ErrProtocol, id)
+			}
+			cs = &chunkStream{}
+			c.streams[id] = cs
+		}
+
+		n := [4]int{11, 7, 3, 0}[format]
+		if _, err := io.ReadFull(c.r, b[:n]); err != nil {
+			return message{}, err
+		}
+		var field uint32
+		if n > 0 {
+			field = uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+			cs.extended = field == extended
+		}
+		if n >= 7 {
+			cs.length = uint32(b[3])<<16 | uint32(b[4])<<8 | uint32(b[5])
+			cs.typ = b[6]
+		}
+		if n == 11 {
+			cs.stream = binary.LittleEndian.Uint32(b[7:11])
+		}
+		if cs.extended {
+			if _, err := io.ReadFull(c.r, b[:4]); err != nil {
+				return message{}, err
+			}
+			if n > 0 {
+				field = binary.BigEndian.Uint32(b[:4])
+			}
+		}
+		switch {
+		case format == 0:
+			cs.timestamp, cs.delta = field, 0
+		case format < 3:
+			cs.delta = field
+			cs.timestamp += field
+		case !cs.reading:
+			// A new message with the header of the last
+			cs.timestamp += cs.delta
+		}
+
+		cs.reading = true
+		size := cs.length - uint32(len(cs.data))
+		if size > c.size {
+			size = c.size
+		}
+		start := len(cs.data)
+		cs.data = append(cs.data, make([]byte, size)...)
+		if _, err := io.ReadFull(c.r, cs.data[start:]); err != nil {
+			return message{}, err
+		}
+		if uint32(len(cs.data)) == cs.length {
+			m := message{typ: cs.typ, stream: cs.stream, timestamp: cs.timestamp, data: cs.data}
+			cs.data, cs.reading = nil, false
+			return m, nil
+		}
+	}
+}
+
+func be24(v uint32) []byte {
+	return []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func be32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func be64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
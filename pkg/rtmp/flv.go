@@ -0,0 +1,47 @@
+package rtmp
+
+// FLV codec ids and packet types of the audio and video messages
+const (
+	flvAVC = 7
+	flvAAC = 10
+
+	flvKeyframe   = 1
+	flvInterframe = 2
+
+	flvSequenceHeader = 0
+	flvData           = 1
+)
+
+// AVCSequenceHeader returns the video message carrying the
+// AVCDecoderConfigurationRecord of an H.264 stream, sent before its first
+// frame and whenever its parameter sets change
+func AVCSequenceHeader(config []byte) []byte {
+	return append([]byte{flvKeyframe<<4 | flvAVC, flvSequenceHeader, 0, 0, 0}, config...)
+}
+
+// AVCFrame returns the video message of an H.264 access unit, its NAL
+// units prefixed with their 4 byte length. WebRTC streams have no B-frames,
+// so the composition time offset is always zero.
+func AVCFrame(key bool, data []byte) []byte {
+	frame := byte(flvInterframe)
+	if key {
+		frame = flvKeyframe
+	}
+	return append([]byte{frame<<4 | flvAVC, flvData, 0, 0, 0}, data...)
+}
+
+// AACSequenceHeader returns the audio message carrying the
+// AudioSpecificConfig of an AAC stream, sent before its first frame
+func AACSequenceHeader(config []byte) []byte {
+	return append([]byte{aacFlags, flvSequenceHeader}, config...)
+}
+
+// AACFrame returns the audio message of a raw AAC frame, without ADTS
+// header
+func AACFrame(data []byte) []byte {
+	return append([]byte{aacFlags, flvData}, data...)
+}
+
+// aacFlags are the flags of AAC audio messages. Rate, size and channels
+// are fixed for AAC, the AudioSpecificConfig giving the actual ones.
+const aacFlags = flvAAC<<4 | 3<<2 | 1<<1 | 1
@@ -0,0 +1,444 @@
+// Package rtmp publishes live streams to RTMP ingest servers, such as
+// YouTube's or Twitch's.
+//
+// Only what a publishing client needs is implemented: the plain handshake,
+// chunking, the connect, createStream and publish commands, and audio and
+// video messages of FLV tags. Servers ask for the stream key in the last
+// path segment of their ingest URL:
+//
+//	conn, err := rtmp.Dial(ctx, "rtmp://a.rtmp.youtube.com/live2/<key>")
+//	...
+//	err = conn.WriteVideo(0, rtmp.AVCSequenceHeader(config))
+//	err = conn.WriteVideo(0, rtmp.AVCFrame(true, frame))
+//
+// rtmps URLs connect over TLS.
+package rtmp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrURL is returned for URLs that aren't RTMP ingest URLs
+	ErrURL = errors.New("rtmp: invalid url")
+	// ErrProtocol is returned when the server breaks the protocol
+	ErrProtocol = errors.New("rtmp: protocol error")
+	// ErrRejected is returned when the server refuses to connect or
+	// publish, e.g. for an invalid stream key
+	ErrRejected = errors.New("rtmp: rejected")
+)
+
+// Timeouts of connecting, unless the context has a deadline, and of
+// writing a message
+const (
+	dialTimeout  = 10 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+// handshakeSize is the size of the C1, C2, S1 and S2 handshake packets
+const handshakeSize = 1536
+
+// Conn is a connection publishing a stream
+type Conn struct {
+	conn   net.Conn
+	read   *counter
+	r      *chunkReader
+	stream uint32 // message stream id of the published stream
+	key    string
+
+	mu     sync.Mutex // of writes
+	w      chunkWriter
+	window uint32 // bytes the server expects acknowledged
+	acked  uint64
+
+	done chan struct{}
+	err  error
+}
+
+// Endpoint splits an ingest URL, rtmp://host[:port]/app/key, into the
+// address to connect to, the tcUrl of the app and the stream key
+func Endpoint(rawurl string) (addr, app, tcURL, key string, err error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("%w: %s", ErrURL, err)
+	}
+	port := "1935"
+	switch u.Scheme {
+	case "rtmp":
+	case "rtmps":
+		port = "443"
+	default:
+		return "", "", "", "", fmt.Errorf("%w: scheme %q", ErrURL, u.Scheme)
+	}
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if u.Host == "" || i <= 0 || i == len(path)-1 {
+		return "", "", "", "", fmt.Errorf("%w: %s needs a host, app and stream key", ErrURL, u.Redacted())
+	}
+	addr = u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	app, key = path[:i], path[i+1:]
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return addr, app, u.Scheme + "://" + u.Host + "/" + app, key, nil
+}
+
+// Dial connects to the ingest URL and publishes a live stream with its
+// stream key. The context bounds connecting, not the connection.
+func Dial(ctx context.Context, rawurl string) (*Conn, error) {
+	addr, app, tcURL, key, err := Endpoint(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	var nc net.Conn
+	if strings.HasPrefix(rawurl, "rtmps:") {
+		nc, err = (&tls.Dialer{}).DialContext(ctx, "tcp", addr)
+	} else {
+		nc, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(dialTimeout)
+	}
+	if err := nc.SetDeadline(deadline); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	// Cancelling the context interrupts reads and writes
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			nc.Close()
+		case <-stop:
+		}
+	}()
+
+	read := &counter{r: nc}
+	c := &Conn{
+		conn: nc,
+		read: read,
+		r:    newChunkReader(read),
+		w:    chunkWriter{w: nc, size: defaultChunkSize},
+		key:  key,
+		done: make(chan struct{}),
+	}
+	if err := c.publish(app, tcURL); err != nil {
+		nc.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if err := nc.SetDeadline(time.Time{}); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	go c.receive()
+	return c, nil
+}
+
+// publish shakes hands, connects to the app and publishes the stream
+func (c *Conn) publish(app, tcURL string) error {
+	if err := c.handshake(); err != nil {
+		return fmt.Errorf("rtmp handshake: %w", err)
+	}
+	if err := c.send(csControl, msgSetChunkSize, 0, be32(chunkSize)); err != nil {
+		return err
+	}
+	c.w.size = chunkSize
+
+	if err := c.command(0, "connect", 1, Object{
+		"app":      app,
+		"type":     "nonprivate",
+		"flashVer": "FMLE/3.0 (compatible; ion-avp)",
+		"tcUrl":    tcURL,
+	}); err != nil {
+		return err
+	}
+	if _, err := c.result(1); err != nil {
+		return fmt.Errorf("rtmp connect: %w", err)
+	}
+
+	// Ingest servers expect the stream released and announced first, and
+	// answer or not
+	if err := c.command(0, "releaseStream", 2, nil, c.key); err != nil {
+		return err
+	}
+	if err := c.command(0, "FCPublish", 3, nil, c.key); err != nil {
+		return err
+	}
+	if err := c.command(0, "createStream", 4, nil); err != nil {
+		return err
+	}
+	v, err := c.result(4)
+	if err != nil {
+		return fmt.Errorf("rtmp createStream: %w", err)
+	}
+	id, ok := v[len(v)-1].(float64)
+	if !ok {
+		return fmt.Errorf("%w: createStream returned no stream id", ErrProtocol)
+	}
+	c.stream = uint32(id)
+
+	if err := c.command(c.stream, "publish", 5, nil, c.key, "live"); err != nil {
+		return err
+	}
+	for {
+		name, v, err := c.next()
+		if err != nil {
+			return fmt.Errorf("rtmp publish: %w", err)
+		}
+		if name != "onStatus" {
+			continue
+		}
+		info, _ := v[len(v)-1].(Object)
+		if info["code"] == "NetStream.Publish.Start" {
+			return nil
+		}
+		if info["level"] == "error" {
+			return fmt.Errorf("%w: %s", ErrRejected, describe(info))
+		}
+	}
+}
+
+// handshake exchanges the C0, C1 and C2 handshake packets for S0, S1 and
+// S2, echoing S1
+func (c *Conn) handshake() error {
+	c1 := make([]byte, 1+handshakeSize)
+	c1[0] = 3 // version
+	if _, err := rand.Read(c1[9:]); err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(c1); err != nil {
+		return err
+	}
+	s1 := make([]byte, 1+handshakeSize)
+	if _, err := io.ReadFull(c.r.r, s1); err != nil {
+		return err
+	}
+	if s1[0] != 3 {
+		return fmt.Errorf("%w: version %d", ErrProtocol, s1[0])
+	}
+	if _, err := c.conn.Write(s1[1:]); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(c.r.r, make([]byte, handshakeSize))
+	return err
+}
+
+// command sends a command message of its name, transaction id and
+// arguments
+func (c *Conn) command(stream uint32, name string, txn int, args ...interface{}) error {
+	data := encodeAMF(append([]interface{}{name, txn}, args...)...)
+	return c.send(csCommand, msgCommand, stream, data)
+}
+
+// result reads messages until the result of a transaction, failing if
+// the server answers with an error
+func (c *Conn) result(txn int) ([]interface{}, error) {
+	for {
+		name, v, err := c.next()
+		if err != nil {
+			return nil, err
+		}
+		if len(v) < 2 || v[1] != float64(txn) {
+			continue
+		}
+		switch name {
+		case "_result":
+			return v, nil
+		case "_error":
+			info, _ := v[len(v)-1].(Object)
+			return nil, fmt.Errorf("%w: %s", ErrRejected, describe(info))
+		}
+	}
+}
+
+// next reads messages until a command, returning its name and values
+func (c *Conn) next() (string, []interface{}, error) {
+	for {
+		m, err := c.r.read()
+		if err != nil {
+			return "", nil, err
+		}
+		if err := c.control(m); err != nil {
+			return "", nil, err
+		}
+		if m.typ != msgCommand {
+			continue
+		}
+		v, err := decodeAMF(m.data)
+		if err != nil {
+			return "", nil, err
+		}
+		if name, ok := v[0].(string); ok {
+			return name, v, nil
+		}
+	}
+}
+
+// control handles protocol control messages, and acknowledges what has
+// been read when the server's window is full
+func (c *Conn) control(m message) error {
+	switch m.typ {
+	case msgSetChunkSize:
+		if len(m.data) < 4 {
+			return fmt.Errorf("%w: short set chunk size", ErrProtocol)
+		}
+		c.r.size = binary.BigEndian.Uint32(m.data) & 0x7fffffff
+		if c.r.size == 0 {
+			return fmt.Errorf("%w: chunk size 0", ErrProtocol)
+		}
+	case msgWindowAckSize:
+		if len(m.data) < 4 {
+			return fmt.Errorf("%w: short window acknowledgement size", ErrProtocol)
+		}
+		c.mu.Lock()
+		c.window = binary.BigEndian.Uint32(m.data)
+		c.mu.Unlock()
+	case msgUserControl:
+		// Answer ping requests with their timestamp
+		if len(m.data) >= 6 && binary.BigEndian.Uint16(m.data) == 6 {
+			pong := append([]byte{0, 7}, m.data[2:6]...)
+			if err := c.send(csControl, msgUserControl, 0, pong); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n := c.read.total(); c.window > 0 && n-c.acked >= uint64(c.window) {
+		c.acked = n
+		return c.w.write(csControl, message{typ: msgAck, data: be32(uint32(n))})
+	}
+	return nil
+}
+
+// receive reads the server's messages while the stream is published,
+// until the connection fails or is closed
+func (c *Conn) receive() {
+	defer close(c.done)
+	for {
+		m, err := c.r.read()
+		if err == nil {
+			err = c.control(m)
+		}
+		if err != nil {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+			return
+		}
+	}
+}
+
+// send writes a message
+func (c *Conn) send(cs uint8, typ uint8, stream uint32, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.w.write(cs, message{typ: typ, stream: stream, data: data})
+}
+
+// write sends a media message of the stream at ts
+func (c *Conn) write(cs uint8, typ uint8, ts time.Duration, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return c.w.write(cs, message{typ: typ, stream: c.stream, timestamp: uint32(ts / time.Millisecond), data: data})
+}
+
+// WriteVideo sends a video message at ts from the start of the stream,
+// e.g. an AVCFrame
+func (c *Conn) WriteVideo(ts time.Duration, data []byte) error {
+	return c.write(csVideo, msgVideo, ts, data)
+}
+
+// WriteAudio sends an audio message at ts from the start of the stream,
+// e.g. an AACFrame
+func (c *Conn) WriteAudio(ts time.Duration, data []byte) error {
+	return c.write(csAudio, msgAudio, ts, data)
+}
+
+// Done is closed when the connection fails or is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection failed, once Done is closed
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close unpublishes the stream and closes the connection
+func (c *Conn) Close() error {
+	select {
+	case <-c.done:
+	default:
+		// Best effort, the server ends the stream when the connection
+		// closes anyway
+		_ = c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		_ = c.command(0, "FCUnpublish", 6, nil, c.key)
+		_ = c.command(0, "deleteStream", 7, nil, c.stream)
+	}
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// describe returns the description of an error information object, or its
+// code
+func describe(info Object) string {
+	if d, ok := info["description"].(string); ok && d != "" {
+		return d
+	}
+	if code, ok := info["code"].(string); ok {
+		return code
+	}
+	return "no description"
+}
+
+// counter counts the bytes read, for acknowledgements
+type counter struct {
+	sync.Mutex
+	r io.Reader
+	n uint64
+}
+
+func (c *counter) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.Lock()
+	c.n += uint64(n)
+	c.Unlock()
+	return n, err
+}
+
+func (c *counter) total() uint64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.n
+}
@@ -0,0 +1,265 @@
+package rtmp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMF(t *testing.T) {
+	b := encodeAMF("connect", 1, Object{"app": "live", "n": 2.5, "ok": true}, nil)
+	v, err := decodeAMF(b)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"connect", float64(1), Object{"app": "live", "n": 2.5, "ok": true}, nil}, v)
+
+	// ECMA and strict arrays, as servers send
+	v, err = decodeAMF([]byte{
+		amfECMAArray, 0, 0, 0, 1, 0, 1, 'a', amfNull, 0, 0, amfObjectEnd,
+		amfStrictArray, 0, 0, 0, 1, amfBoolean, 1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{Object{"a": nil}, []interface{}{true}}, v)
+
+	_, err = decodeAMF(b[:len(b)-3])
+	assert.True(t, errors.Is(err, ErrProtocol))
+	_, err = decodeAMF([]byte{0x10})
+	assert.True(t, errors.Is(err, ErrProtocol))
+}
+
+func TestChunks(t *testing.T) {
+	var b bytes.Buffer
+	w := chunkWriter{w: &b, size: 128}
+	long := bytes.Repeat([]byte{1, 2, 3}, 100)
+	messages := []message{
+		{typ: msgVideo, stream: 1, timestamp: 40, data: long},
+		{typ: msgAudio, stream: 1, timestamp: 0x1000000, data: long},
+		{typ: msgCommand, data: []byte{}},
+	}
+	for _, m := range messages {
+		require.NoError(t, w.write(csVideo, m))
+	}
+
+	r := newChunkReader(&b)
+	r.size = 128
+	for _, want := range messages {
+		m, err := r.read()
+		require.NoError(t, err)
+		if len(want.data) == 0 {
+			want.data = nil
+		}
+		assert.Equal(t, want, m)
+	}
+	_, err := r.read()
+	assert.Equal(t, io.EOF, err)
+
+	// Headers compressed against the last message of the chunk stream
+	r = newChunkReader(bytes.NewReader([]byte{
+		0x05, 0, 0, 10, 0, 0, 1, msgAudio, 1, 0, 0, 0, 'a',
+		0x45, 0, 0, 20, 0, 0, 1, msgAudio, 'b',
+		0x85, 0, 0, 5, 'c',
+		0xc5, 'd',
+	}))
+	for _, want := range []struct {
+		ts   uint32
+		data string
+	}{{10, "a"}, {30, "b"}, {35, "c"}, {40, "d"}} {
+		m, err := r.read()
+		require.NoError(t, err)
+		assert.Equal(t, want.ts, m.timestamp)
+		assert.Equal(t, want.data, string(m.data))
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	addr, app, tcURL, key, err := Endpoint("rtmp://a.rtmp.youtube.com/live2/abcd-1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "a.rtmp.youtube.com:1935", addr)
+	assert.Equal(t, "live2", app)
+	assert.Equal(t, "rtmp://a.rtmp.youtube.com/live2", tcURL)
+	assert.Equal(t, "abcd-1234", key)
+
+	addr, app, _, key, err = Endpoint("rtmps://ingest.example.com/app/inst/key?bandwidthtest=true")
+	assert.NoError(t, err)
+	assert.Equal(t, "ingest.example.com:443", addr)
+	assert.Equal(t, "app/inst", app)
+	assert.Equal(t, "key?bandwidthtest=true", key)
+
+	for _, u := range []string{"http://host/app/key", "rtmp://host/key", "rtmp://host/app/", "rtmp:///app/key"} {
+		_, _, _, _, err := Endpoint(u)
+		assert.True(t, errors.Is(err, ErrURL), u)
+	}
+}
+
+// server accepts one connection, answering a publishing client, and sends
+// the messages it then receives
+type server struct {
+	ln       net.Listener
+	reject   bool
+	messages chan message
+}
+
+func newServer(t *testing.T) *server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &server{ln: ln, messages: make(chan message, 16)}
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *server) url() string {
+	return "rtmp://" + s.ln.Addr().String() + "/live/key"
+}
+
+func (s *server) serve(t *testing.T) net.Conn {
+	conn, err := s.ln.Accept()
+	require.NoError(t, err)
+	go func() {
+		defer close(s.messages)
+		c1 := make([]byte, 1+handshakeSize)
+		if _, err := io.ReadFull(conn, c1); err != nil {
+			return
+		}
+		s1 := make([]byte, 1+handshakeSize)
+		s1[0] = 3
+		if _, err := conn.Write(append(s1, c1[1:]...)); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(conn, make([]byte, handshakeSize)); err != nil {
+			return
+		}
+
+		r := newChunkReader(conn)
+		w := chunkWriter{w: conn, size: defaultChunkSize}
+		reply := func(stream uint32, values ...interface{}) {
+			_ = w.write(csCommand, message{typ: msgCommand, stream: stream, data: encodeAMF(values...)})
+		}
+		_ = w.write(csControl, message{typ: msgWindowAckSize, data: be32(4096)})
+		for {
+			m, err := r.read()
+			if err != nil {
+				return
+			}
+			switch m.typ {
+			case msgSetChunkSize:
+				r.size = be32u(m.data)
+				continue
+			case msgCommand:
+				v, _ := decodeAMF(m.data)
+				switch v[0] {
+				case "connect":
+					reply(0, "_result", v[1], Object{}, Object{"code": "NetConnection.Connect.Success"})
+				case "createStream":
+					reply(0, "_result", v[1], nil, 1)
+				case "publish":
+					if s.reject {
+						reply(1, "onStatus", 0, nil, Object{"level": "error", "code": "NetStream.Publish.BadName", "description": "bad key"})
+						continue
+					}
+					reply(1, "onStatus", 0, nil, Object{"level": "status", "code": "NetStream.Publish.Start"})
+				}
+			}
+			s.messages <- m
+		}
+	}()
+	return conn
+}
+
+func be32u(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+// commands returns the names of the commands received, until a media
+// message
+func (s *server) commands(t *testing.T) ([]string, message) {
+	var names []string
+	for m := range s.messages {
+		if m.typ != msgCommand {
+			return names, m
+		}
+		v, err := decodeAMF(m.data)
+		require.NoError(t, err)
+		names = append(names, v[0].(string))
+	}
+	return names, message{}
+}
+
+func TestDial(t *testing.T) {
+	s := newServer(t)
+	go s.serve(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, s.url())
+	require.NoError(t, err)
+	frame := bytes.Repeat([]byte{9}, 10000)
+	require.NoError(t, conn.WriteVideo(1500*time.Millisecond, AVCFrame(true, frame)))
+
+	names, m := s.commands(t)
+	assert.Equal(t, []string{"connect", "releaseStream", "FCPublish", "createStream", "publish"}, names)
+	assert.Equal(t, uint8(msgVideo), m.typ)
+	assert.Equal(t, uint32(1), m.stream)
+	assert.Equal(t, uint32(1500), m.timestamp)
+	assert.Equal(t, AVCFrame(true, frame), m.data)
+
+	require.NoError(t, conn.WriteAudio(0, AACFrame([]byte{1})))
+	m = <-s.messages
+	assert.Equal(t, []byte{0xaf, 1, 1}, m.data)
+
+	assert.NoError(t, conn.Close())
+	names, _ = s.commands(t)
+	assert.Equal(t, []string{"FCUnpublish", "deleteStream"}, names)
+}
+
+func TestDial_Rejected(t *testing.T) {
+	s := newServer(t)
+	s.reject = true
+	go s.serve(t)
+
+	_, err := Dial(context.Background(), s.url())
+	assert.True(t, errors.Is(err, ErrRejected))
+	assert.True(t, strings.Contains(err.Error(), "bad key"))
+}
+
+func TestConn_Done(t *testing.T) {
+	s := newServer(t)
+	served := make(chan net.Conn)
+	go func() { served <- s.serve(t) }()
+
+	conn, err := Dial(context.Background(), s.url())
+	require.NoError(t, err)
+	(<-served).Close()
+	select {
+	case <-conn.Done():
+		assert.Error(t, conn.Err())
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not done")
+	}
+	conn.Close()
+}
+
+func TestDial_Cancel(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		// Accept and never answer
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = Dial(ctx, "rtmp://"+ln.Addr().String()+"/live/key")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
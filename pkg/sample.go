@@ -1,11 +1,17 @@
 package avp
 
+import "time"
+
 // Types for samples
 const (
 	TypeOpus = 1
 	TypeVP8  = 2
 	TypeVP9  = 3
 	TypeH264 = 4
+	TypeAV1  = 5
+	// TypeAAC samples are raw AAC frames from encoders, WebRTC doesn't
+	// carry AAC
+	TypeAAC = 6
 )
 
 // Sample of audio or video
@@ -14,5 +20,14 @@ type Sample struct {
 	Type           int
 	Timestamp      uint32
 	SequenceNumber uint16
-	Payload        interface{}
+	// ClockRate is the rate of Timestamp in Hz, from the track's codec.
+	// Zero when unknown, when elements assume 48kHz audio and 90kHz video.
+	ClockRate uint32
+	// CaptureTime is when the sample was captured, from the abs-capture-time
+	// header extension or RTCP sender reports. Zero when unknown.
+	CaptureTime time.Time
+	// AudioLevel is the sender's level of an audio sample, from the
+	// ssrc-audio-level header extension. Nil when unknown.
+	AudioLevel *AudioLevel
+	Payload    interface{}
 }
@@ -0,0 +1,90 @@
+package sandbox
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	avp "github.com/pion/ion-avp/pkg"
+	log "github.com/pion/ion-log"
+)
+
+// IsChild reports whether this process was started as a sandbox child
+func IsChild() bool {
+	return os.Getenv(envSocket) != ""
+}
+
+// Main runs the element the parent asked for, using the given registry
+// of elements, and returns the process exit code. Binaries used as the
+// sandbox command call it on startup when IsChild is true.
+func Main(elems map[string]avp.ElementFun) int {
+	eid := os.Getenv(envElement)
+	f, ok := elems[eid]
+	if !ok {
+		log.Errorf("sandbox: element %s not found", eid)
+		return 1
+	}
+	config, err := base64.StdEncoding.DecodeString(os.Getenv(envConfig))
+	if err != nil {
+		log.Errorf("sandbox: bad config: %v", err)
+		return 1
+	}
+
+	conn, err := net.Dial("unix", os.Getenv(envSocket))
+	if err != nil {
+		log.Errorf("sandbox: %v", err)
+		return 1
+	}
+	defer conn.Close()
+
+	e := f(os.Getenv(envSID), os.Getenv(envPID), os.Getenv(envTID), config)
+	if err := Serve(conn, e); err != nil {
+		log.Errorf("sandbox: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// Serve writes samples read from conn to e, and sends samples written by
+// e back over conn. It closes e and returns once the parent closes conn.
+func Serve(conn io.ReadWriter, e avp.Element) error {
+	e.Attach(&sender{w: bufio.NewWriter(conn)})
+	defer e.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		s, err := ReadSample(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := e.Write(s); err != nil {
+			log.Errorf("sandbox: error writing sample: %v", err)
+		}
+	}
+}
+
+// sender is attached to the sandboxed element to pass its output back
+type sender struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func (s *sender) Write(sample *avp.Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := WriteSample(s.w, sample); err != nil {
+		return fmt.Errorf("sending sample to parent: %w", err)
+	}
+	return s.w.Flush()
+}
+
+func (s *sender) Attach(e avp.Element) {}
+
+func (s *sender) Close() {}
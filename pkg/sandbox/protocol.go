@@ -0,0 +1,141 @@
+package sandbox
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+)
+
+// Samples are exchanged over the socket as frames:
+//
+//	uint32 length of the rest of the frame
+//	uint8  frame type, 2 for samples
+//	uint16 id length, id
+//	int32  type
+//	uint32 timestamp
+//	uint16 sequence number
+//	uint32 clock rate
+//	uint8  flags, 1 capture time known, 2 audio level known, 4 voice
+//	int64  capture time in unix nanoseconds
+//	uint8  audio level
+//	payload
+//
+// All integers are big endian. Frames of type 1, samples without clock
+// rate, flags, capture time and audio level, are still read.
+
+const (
+	frameSampleV1 = 1
+	frameSample   = 2
+	// maxFrame bounds frames read, a 4K RGBA frame is about 32 MiB
+	maxFrame = 64 << 20
+)
+
+// Flags of sample frames
+const (
+	flagCaptureTime = 1 << iota
+	flagAudioLevel
+	flagVoice
+)
+
+// headerSize is the size of the fields of sample frames after the id
+var headerSize = map[byte]int{frameSampleV1: 10, frameSample: 24}
+
+// ErrPayload is returned for samples whose payload is not a byte slice
+var ErrPayload = errors.New("sandbox: only []byte payloads can be sent")
+
+// WriteSample writes a sample frame to w
+func WriteSample(w io.Writer, s *avp.Sample) error {
+	payload, ok := s.Payload.([]byte)
+	if !ok {
+		return ErrPayload
+	}
+	if len(s.ID) > 0xffff {
+		return errors.New("sandbox: sample id too long")
+	}
+	n := 1 + 2 + len(s.ID) + headerSize[frameSample] + len(payload)
+	if n > maxFrame {
+		return fmt.Errorf("sandbox: sample too large (%d bytes)", n)
+	}
+
+	var flags, level byte
+	var captured int64
+	if !s.CaptureTime.IsZero() {
+		flags |= flagCaptureTime
+		captured = s.CaptureTime.UnixNano()
+	}
+	if l := s.AudioLevel; l != nil {
+		flags |= flagAudioLevel
+		level = l.Level
+		if l.Voice {
+			flags |= flagVoice
+		}
+	}
+	hdr := make([]byte, 0, 4+n-len(payload))
+	hdr = append(hdr, byte(n>>24), byte(n>>16), byte(n>>8), byte(n), frameSample)
+	hdr = append(hdr, byte(len(s.ID)>>8), byte(len(s.ID)))
+	hdr = append(hdr, s.ID...)
+	hdr = append(hdr,
+		byte(s.Type>>24), byte(s.Type>>16), byte(s.Type>>8), byte(s.Type),
+		byte(s.Timestamp>>24), byte(s.Timestamp>>16), byte(s.Timestamp>>8), byte(s.Timestamp),
+		byte(s.SequenceNumber>>8), byte(s.SequenceNumber),
+		byte(s.ClockRate>>24), byte(s.ClockRate>>16), byte(s.ClockRate>>8), byte(s.ClockRate),
+		flags,
+	)
+	hdr = append(hdr, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(hdr[len(hdr)-8:], uint64(captured))
+	hdr = append(hdr, level)
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// ReadSample reads a sample frame from r
+func ReadSample(r *bufio.Reader) (*avp.Sample, error) {
+	var lb [4]byte
+	if _, err := io.ReadFull(r, lb[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(lb[:])
+	if n < 13 || n > maxFrame {
+		return nil, fmt.Errorf("sandbox: bad frame length %d", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	size, ok := headerSize[buf[0]]
+	if !ok {
+		return nil, fmt.Errorf("sandbox: unknown frame type %d", buf[0])
+	}
+	idLen := int(binary.BigEndian.Uint16(buf[1:]))
+	if 3+idLen+size > len(buf) {
+		return nil, errors.New("sandbox: short frame")
+	}
+	p := buf[3+idLen:]
+	s := &avp.Sample{
+		ID:             string(buf[3 : 3+idLen]),
+		Type:           int(int32(binary.BigEndian.Uint32(p))),
+		Timestamp:      binary.BigEndian.Uint32(p[4:]),
+		SequenceNumber: binary.BigEndian.Uint16(p[8:]),
+		Payload:        p[size:],
+	}
+	if buf[0] == frameSampleV1 {
+		return s, nil
+	}
+	s.ClockRate = binary.BigEndian.Uint32(p[10:])
+	flags := p[14]
+	if flags&flagCaptureTime != 0 {
+		s.CaptureTime = time.Unix(0, int64(binary.BigEndian.Uint64(p[15:]))).UTC()
+	}
+	if flags&flagAudioLevel != 0 {
+		s.AudioLevel = &avp.AudioLevel{Level: p[23], Voice: flags&flagVoice != 0}
+	}
+	return s, nil
+}
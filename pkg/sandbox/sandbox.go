@@ -0,0 +1,283 @@
+// Package sandbox runs elements in child processes, so a crash in native
+// codec code only takes down that element instead of the whole node.
+//
+// The parent starts the child with the element to run described in its
+// environment, and exchanges samples with it over a unix socket. The
+// child is restarted if it exits while the element is in use; samples
+// written while it is down are dropped.
+package sandbox
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lucsky/cuid"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	log "github.com/pion/ion-log"
+)
+
+// Environment passed to the child process
+const (
+	envSocket  = "AVP_SANDBOX_SOCKET"
+	envElement = "AVP_SANDBOX_ELEMENT"
+	envSID     = "AVP_SANDBOX_SID"
+	envPID     = "AVP_SANDBOX_PID"
+	envTID     = "AVP_SANDBOX_TID"
+	envConfig  = "AVP_SANDBOX_CONFIG"
+)
+
+const (
+	acceptTimeout = 10 * time.Second
+	closeTimeout  = 5 * time.Second
+	maxBackoff    = 30 * time.Second
+)
+
+// Config configures the child processes
+type Config struct {
+	// Command is the runner binary, defaults to the current executable
+	Command string
+	// Args are passed to the runner
+	Args []string
+}
+
+// Wrap returns an ElementFun running the element eid in a child process
+func Wrap(c Config, eid string) avp.ElementFun {
+	return func(sid, pid, tid string, config []byte) avp.Element {
+		return New(c, eid, sid, pid, tid, config)
+	}
+}
+
+// Element proxies samples to an element running in a child process.
+// Samples written by the child element are passed to attached elements.
+type Element struct {
+	elements.Node
+	cfg Config
+	env []string
+
+	// children guards Node, which is written to from the reader goroutine
+	children sync.RWMutex
+
+	mu       sync.Mutex
+	sock     string
+	listener net.Listener
+	conn     net.Conn
+	w        *bufio.Writer
+	cmd      *exec.Cmd
+	closed   bool
+	dropped  uint64
+	exited   chan struct{}
+	stop     chan struct{} // closed by Close, ending restarts
+	done     chan struct{} // closed once the child is gone for good
+}
+
+// New starts a child process running the element eid
+func New(c Config, eid, sid, pid, tid string, config []byte) *Element {
+	if c.Command == "" {
+		if exe, err := os.Executable(); err == nil {
+			c.Command = exe
+		}
+	}
+	e := &Element{
+		cfg: c,
+		env: []string{
+			envElement + "=" + eid,
+			envSID + "=" + sid,
+			envPID + "=" + pid,
+			envTID + "=" + tid,
+			envConfig + "=" + base64.StdEncoding.EncodeToString(config),
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go e.supervise()
+	return e
+}
+
+// supervise runs the child, restarting it with backoff until closed
+func (e *Element) supervise() {
+	defer close(e.done)
+	backoff := time.Second
+	for {
+		started := time.Now()
+		if err := e.run(); err != nil {
+			log.Errorf("sandbox %s: %v", e.cfg.Command, err)
+		}
+
+		e.mu.Lock()
+		closed := e.closed
+		e.mu.Unlock()
+		if closed {
+			return
+		}
+
+		if time.Since(started) > maxBackoff {
+			backoff = time.Second
+		}
+		log.Warnf("sandbox %s exited, restarting in %s", e.cfg.Command, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-e.stop:
+			return
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// run starts the child and forwards its output until it exits, then
+// removes its socket
+func (e *Element) run() error {
+	sock := filepath.Join(os.TempDir(), "avp-sandbox-"+cuid.New()+".sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sock)
+	defer l.Close()
+
+	cmd := exec.Command(e.cfg.Command, e.cfg.Args...) // nolint: gosec
+	cmd.Env = append(append(os.Environ(), e.env...), envSocket+"="+sock)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	exited := make(chan struct{})
+
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	if err := cmd.Start(); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	e.sock = sock
+	e.listener = l
+	e.cmd = cmd
+	e.exited = exited
+	e.mu.Unlock()
+
+	var waitErr error
+	go func() {
+		waitErr = cmd.Wait()
+		close(exited)
+	}()
+
+	if ul, ok := l.(*net.UnixListener); ok {
+		ul.SetDeadline(time.Now().Add(acceptTimeout)) // nolint: errcheck
+	}
+	conn, err := l.Accept()
+	if err != nil {
+		cmd.Process.Kill() // nolint: errcheck
+		<-exited
+		return fmt.Errorf("child did not connect: %w", err)
+	}
+
+	e.mu.Lock()
+	e.conn = conn
+	e.w = bufio.NewWriter(conn)
+	e.mu.Unlock()
+
+	r := bufio.NewReader(conn)
+	for {
+		s, err := ReadSample(r)
+		if err != nil {
+			break
+		}
+		e.children.RLock()
+		if err := e.Node.Write(s); err != nil {
+			log.Errorf("sandbox: error writing sample: %v", err)
+		}
+		e.children.RUnlock()
+	}
+
+	e.mu.Lock()
+	e.conn = nil
+	e.w = nil
+	e.mu.Unlock()
+	conn.Close()
+
+	<-exited
+	return waitErr
+}
+
+// Attach attaches an element to receive the child element's output
+func (e *Element) Attach(el avp.Element) {
+	e.children.Lock()
+	defer e.children.Unlock()
+	e.Node.Attach(el)
+}
+
+// Write sends a sample to the child, dropping it while the child is down
+func (e *Element) Write(s *avp.Sample) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.w == nil {
+		if e.dropped++; e.dropped%100 == 1 {
+			log.Warnf("sandbox %s not running, %d samples dropped", e.cfg.Command, e.dropped)
+		}
+		return nil
+	}
+	err := WriteSample(e.w, s)
+	if err == nil {
+		err = e.w.Flush()
+	}
+	if err != nil && err != ErrPayload {
+		// The child is stuck or gone, kill it so it gets restarted
+		log.Errorf("sandbox: write to child failed: %v", err)
+		e.w = nil
+		e.conn.Close()
+		e.cmd.Process.Kill() // nolint: errcheck
+		return nil
+	}
+	return err
+}
+
+// Close stops the child, giving its element time to flush, and waits
+// for its socket to be removed, then closes attached elements
+func (e *Element) Close() {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.closed = true
+	close(e.stop)
+	l, conn, cmd, exited := e.listener, e.conn, e.cmd, e.exited
+	e.mu.Unlock()
+
+	if conn != nil {
+		// Closing our side tells the child to close its element and exit
+		if uc, ok := conn.(*net.UnixConn); ok {
+			uc.CloseWrite() // nolint: errcheck
+		} else {
+			conn.Close()
+		}
+	}
+	if cmd != nil {
+		select {
+		case <-exited:
+		case <-time.After(closeTimeout):
+			log.Warnf("sandbox %s did not exit, killing it", e.cfg.Command)
+			cmd.Process.Kill() // nolint: errcheck
+			<-exited
+		}
+	}
+	if l != nil {
+		// A child that never connected isn't waited for
+		l.Close()
+	}
+	<-e.done
+	e.children.Lock()
+	defer e.children.Unlock()
+	e.Node.Close()
+}
@@ -0,0 +1,189 @@
+package sandbox
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain makes the test binary its own sandbox runner
+func TestMain(m *testing.M) {
+	if IsChild() {
+		os.Exit(Main(map[string]avp.ElementFun{
+			"upper": func(sid, pid, tid string, config []byte) avp.Element {
+				return &upper{prefix: config}
+			},
+		}))
+	}
+	os.Exit(m.Run())
+}
+
+// upper echoes payloads upper cased, and exits on "crash"
+type upper struct {
+	elements.Node
+	prefix []byte
+}
+
+func (u *upper) Write(s *avp.Sample) error {
+	p := s.Payload.([]byte)
+	if string(p) == "crash" {
+		os.Exit(2)
+	}
+	s.Payload = append(append([]byte{}, u.prefix...), bytes.ToUpper(p)...)
+	return u.Node.Write(s)
+}
+
+type collect struct {
+	elements.Leaf
+	ch     chan *avp.Sample
+	closed chan struct{}
+}
+
+func newCollect() *collect {
+	return &collect{ch: make(chan *avp.Sample, 10), closed: make(chan struct{})}
+}
+
+func (c *collect) Write(s *avp.Sample) error {
+	c.ch <- s
+	return nil
+}
+
+func (c *collect) Close() {
+	close(c.closed)
+}
+
+func TestSampleRoundTrip(t *testing.T) {
+	in := &avp.Sample{
+		ID:             "video",
+		Type:           -1,
+		Timestamp:      90000,
+		SequenceNumber: 65535,
+		ClockRate:      44100,
+		CaptureTime:    time.Date(2021, 2, 1, 10, 0, 0, 123456789, time.UTC),
+		AudioLevel:     &avp.AudioLevel{Level: 30, Voice: true},
+		Payload:        []byte{1, 2, 3},
+	}
+	// Every field is set, so fields added to Sample must be sent too
+	v := reflect.ValueOf(*in)
+	for i := 0; i < v.NumField(); i++ {
+		assert.False(t, v.Field(i).IsZero(), v.Type().Field(i).Name)
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSample(&buf, in))
+	assert.NoError(t, WriteSample(&buf, &avp.Sample{ID: "empty", Payload: []byte{}}))
+
+	r := bufio.NewReader(&buf)
+	out, err := ReadSample(r)
+	assert.NoError(t, err)
+	assert.Equal(t, in, out)
+
+	out, err = ReadSample(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "empty", out.ID)
+	assert.Empty(t, out.Payload)
+
+	_, err = ReadSample(r)
+	assert.Error(t, err)
+
+	assert.Equal(t, ErrPayload, WriteSample(&buf, &avp.Sample{Payload: "text"}))
+}
+
+func TestSampleV1(t *testing.T) {
+	// A frame of the first version, without clock rate, capture time or
+	// audio level
+	frame := []byte{0, 0, 0, 16, frameSampleV1, 0, 1, 'a', 0, 0, 0, 2, 0, 0, 0, 3, 0, 4, 5, 6}
+	s, err := ReadSample(bufio.NewReader(bytes.NewReader(frame)))
+	assert.NoError(t, err)
+	assert.Equal(t, &avp.Sample{ID: "a", Type: 2, Timestamp: 3, SequenceNumber: 4, Payload: []byte{5, 6}}, s)
+}
+
+func waitSample(t *testing.T, c *collect) *avp.Sample {
+	t.Helper()
+	select {
+	case s := <-c.ch:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for sample")
+		return nil
+	}
+}
+
+// write retries until the child is up and answers
+func write(t *testing.T, e *Element, c *collect, payload string) *avp.Sample {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		assert.NoError(t, e.Write(&avp.Sample{ID: "track", Payload: []byte(payload)}))
+		select {
+		case s := <-c.ch:
+			return s
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Fatal("child never answered")
+	return nil
+}
+
+func TestElement(t *testing.T) {
+	c := newCollect()
+	e := New(Config{}, "upper", "sid", "pid", "tid", []byte("> "))
+	e.Attach(c)
+
+	s := write(t, e, c, "hello")
+	assert.Equal(t, "track", s.ID)
+	assert.Equal(t, []byte("> HELLO"), s.Payload)
+
+	// A crashed child is restarted
+	assert.NoError(t, e.Write(&avp.Sample{Payload: []byte("crash")}))
+	s = write(t, e, c, "again")
+	assert.Equal(t, []byte("> AGAIN"), s.Payload)
+
+	// Its socket is gone once closed
+	e.mu.Lock()
+	sock := e.sock
+	e.mu.Unlock()
+	_, err := os.Stat(sock)
+	assert.NoError(t, err)
+	e.Close()
+	<-c.closed
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestElement_Missing(t *testing.T) {
+	c := newCollect()
+	e := New(Config{}, "missing", "sid", "pid", "tid", nil)
+	e.Attach(c)
+	assert.NoError(t, e.Write(&avp.Sample{Payload: []byte("dropped")}))
+
+	// Close doesn't wait out the backoff before a restart
+	time.Sleep(500 * time.Millisecond)
+	start := time.Now()
+	e.Close()
+	assert.True(t, time.Since(start) < time.Second)
+	<-c.closed
+	assert.Empty(t, c.ch)
+	_, err := os.Stat(e.sock)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestServe_EOF(t *testing.T) {
+	var in bytes.Buffer
+	assert.NoError(t, WriteSample(&in, &avp.Sample{ID: "a", Payload: []byte("x")}))
+	var out bytes.Buffer
+	assert.NoError(t, Serve(struct {
+		io.Reader
+		io.Writer
+	}{&in, &out}, &upper{}))
+	s, err := ReadSample(bufio.NewReader(&out))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("X"), s.Payload)
+}
@@ -0,0 +1,248 @@
+// Package seal encrypts recordings at rest with AES-GCM.
+//
+// Streams are written as they are recorded, so they are sealed in chunks
+// rather than whole: a header, then chunks of ChunkSize bytes each sealed
+// with its own nonce, the last marked as such so a truncated stream fails
+// to open rather than reading short.
+//
+//	header: "AVPS" | version 1 | chunk size uint32 | nonce prefix [7]byte
+//	chunk:  AES-GCM(nonce prefix | index uint32 | last byte, plaintext)
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ChunkSize is the size of the plaintext of each chunk but the last
+const ChunkSize = 64 * 1024
+
+const (
+	magic      = "AVPS"
+	version    = 1
+	prefixSize = 7
+	headerSize = len(magic) + 1 + 4 + prefixSize
+)
+
+var (
+	// ErrKeySize is returned for keys that aren't 16, 24 or 32 bytes
+	ErrKeySize = errors.New("seal: key must be 16, 24 or 32 bytes")
+	// ErrFormat is returned for streams that weren't sealed
+	ErrFormat = errors.New("seal: not a sealed stream")
+	// ErrOpen is returned when a chunk fails to open, from the wrong key,
+	// tampering or truncation
+	ErrOpen = errors.New("seal: chunk failed to open, wrong key or damaged")
+)
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, ErrKeySize
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce returns the nonce of chunk i
+func nonce(prefix []byte, i uint32, last bool) []byte {
+	n := make([]byte, 12)
+	copy(n, prefix)
+	binary.BigEndian.PutUint32(n[prefixSize:], i)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
+
+// Writer seals what is written to it onto another writer
+type Writer struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint32
+	buf    []byte
+	out    []byte
+	err    error
+	closed bool
+}
+
+// NewWriter returns a writer sealing with key onto w. The header is
+// written with the first chunk. Close must be called to write the last.
+func NewWriter(w io.Writer, key []byte) (*Writer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, prefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, ChunkSize)}, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		if len(w.buf) == ChunkSize {
+			// Only sealed once more follows, the last chunk is marked
+			if w.err = w.seal(false); w.err != nil {
+				return 0, w.err
+			}
+		}
+		c := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (w *Writer) seal(last bool) error {
+	w.out = w.out[:0]
+	if w.index == 0 {
+		w.out = append(w.out, magic...)
+		w.out = append(w.out, version)
+		w.out = append(w.out, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(w.out[len(magic)+1:], ChunkSize)
+		w.out = append(w.out, w.prefix...)
+	}
+	w.out = w.aead.Seal(w.out, nonce(w.prefix, w.index, last), w.buf, nil)
+	w.index++
+	w.buf = w.buf[:0]
+	_, err := w.w.Write(w.out)
+	return err
+}
+
+// Close seals the last chunk. It doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.seal(true)
+	return w.err
+}
+
+// WriteCloser seals onto a file, closing it after the last chunk
+type WriteCloser struct {
+	*Writer
+	c io.Closer
+}
+
+// NewWriteCloser returns a writer sealing with key onto w, such as a
+// sidecar file, which Close closes after writing the last chunk
+func NewWriteCloser(w io.WriteCloser, key []byte) (*WriteCloser, error) {
+	s, err := NewWriter(w, key)
+	if err != nil {
+		return nil, err
+	}
+	return &WriteCloser{Writer: s, c: w}, nil
+}
+
+// Close seals the last chunk and closes the underlying writer
+func (w *WriteCloser) Close() error {
+	err := w.Writer.Close()
+	if cerr := w.c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Reader opens a sealed stream
+type Reader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	size   int
+	index  uint32
+	chunk  []byte // sealed, read ahead by a byte to tell the last
+	plain  []byte
+	eof    bool
+}
+
+// NewReader returns a reader opening the stream sealed with key read from
+// r. It fails if the stream wasn't sealed.
+func NewReader(r io.Reader, key []byte) (*Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	hdr := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, ErrFormat
+	}
+	if string(hdr[:len(magic)]) != magic {
+		return nil, ErrFormat
+	}
+	if v := hdr[len(magic)]; v != version {
+		return nil, fmt.Errorf("seal: unknown version %d", v)
+	}
+	size := int(binary.BigEndian.Uint32(hdr[len(magic)+1:]))
+	if size <= 0 || size > 16*1024*1024 {
+		return nil, ErrFormat
+	}
+	return &Reader{
+		r:      r,
+		aead:   aead,
+		prefix: hdr[len(magic)+5:],
+		size:   size,
+		chunk:  make([]byte, 0, size+aead.Overhead()+1),
+	}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next opens the next chunk
+func (r *Reader) next() error {
+	full := r.size + r.aead.Overhead()
+	// The byte read ahead of the last chunk starts this one
+	n, err := io.ReadFull(r.r, r.chunk[len(r.chunk):full+1])
+	r.chunk = r.chunk[:len(r.chunk)+n]
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	last := len(r.chunk) <= full
+	sealed := r.chunk
+	if !last {
+		sealed = r.chunk[:full]
+	}
+	plain, err := r.aead.Open(nil, nonce(r.prefix, r.index, last), sealed, nil)
+	if err != nil {
+		return ErrOpen
+	}
+	r.index++
+	r.plain = plain
+	if last {
+		r.eof = true
+		r.chunk = r.chunk[:0]
+	} else {
+		r.chunk = append(r.chunk[:0], r.chunk[full])
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package seal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func seal(t *testing.T, key, data []byte, writes int) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key)
+	assert.NoError(t, err)
+	step := len(data)/writes + 1
+	for i := 0; i < len(data); i += step {
+		end := i + step
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := w.Write(data[i:end])
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestSeal(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	for _, size := range []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 100} {
+		data := make([]byte, size)
+		rand.Read(data)
+		sealed := seal(t, key, data, 7)
+		// The last chunk may be full, an empty stream has an empty one
+		chunks := (size + ChunkSize - 1) / ChunkSize
+		if chunks == 0 {
+			chunks = 1
+		}
+		assert.Len(t, sealed, headerSize+size+chunks*16, "size %d", size)
+		assert.False(t, size > 16 && bytes.Contains(sealed, data[:16]))
+
+		opened, err := open(key, sealed)
+		assert.NoError(t, err, "size %d", size)
+		assert.Equal(t, data, append([]byte{}, opened...), "size %d", size)
+	}
+}
+
+func TestSeal_Damaged(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 16)
+	data := make([]byte, 2*ChunkSize+10)
+	sealed := seal(t, key, data, 1)
+
+	_, err := open(bytes.Repeat([]byte{8}, 16), sealed)
+	assert.Equal(t, ErrOpen, err)
+
+	// Cut at a chunk boundary, what is left is whole chunks but not the last
+	_, err = open(key, sealed[:headerSize+ChunkSize+16])
+	assert.Equal(t, ErrOpen, err)
+
+	flipped := append([]byte{}, sealed...)
+	flipped[len(flipped)-1] ^= 1
+	_, err = open(key, flipped)
+	assert.Equal(t, ErrOpen, err)
+
+	_, err = open(key, data)
+	assert.Equal(t, ErrFormat, err)
+	_, err = NewWriter(ioutil.Discard, []byte("short"))
+	assert.Equal(t, ErrKeySize, err)
+}
+
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestWriteCloser(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	f := &closeBuffer{}
+	w, err := NewWriteCloser(f, key)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("plaintext"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.True(t, f.closed)
+	assert.False(t, bytes.Contains(f.Bytes(), []byte("plaintext")))
+	plain, err := open(key, f.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "plaintext", string(plain))
+
+	_, err = NewWriteCloser(f, []byte("short"))
+	assert.Equal(t, ErrKeySize, err)
+}
@@ -0,0 +1,137 @@
+package avp
+
+import (
+	log "github.com/pion/ion-log"
+	"github.com/pion/webrtc/v3"
+)
+
+// Simulcast layers the SFU forwards video at
+const (
+	LayerHigh   = "high"
+	LayerMedium = "medium"
+	LayerLow    = "low"
+	LayerOff    = "none"
+)
+
+// Composite is implemented by elements combining the video of several
+// participants, such as a grid. While a session has one, the dominant
+// speaker's video is requested at a higher simulcast layer than the
+// others', which are shown smaller, so less is decoded for the same
+// picture.
+type Composite interface {
+	Composite() bool
+}
+
+// SimulcastConfig configures the simulcast layers requested for the
+// video of composite recordings
+type SimulcastConfig struct {
+	// Speaker is the layer of the dominant speaker, defaults to high
+	Speaker string `mapstructure:"speaker"`
+	// Others is the layer of everyone else, defaults to low
+	Others string `mapstructure:"others"`
+}
+
+func (c SimulcastConfig) withDefaults() SimulcastConfig {
+	if c.Speaker == "" {
+		c.Speaker = LayerHigh
+	}
+	if c.Others == "" {
+		c.Others = LayerLow
+	}
+	return c
+}
+
+// escalate requests the layers of the session's video when the SFU's audio
+// levels change, levels being stream ids loudest first
+func (t *WebRTCTransport) escalate(levels []string) {
+	if len(levels) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dominant == levels[0] {
+		return
+	}
+	t.dominant = levels[0]
+	t.rebalance()
+}
+
+// Dominant returns the tracks of the dominant speaker's stream, for
+// composites highlighting who is speaking
+func (t *WebRTCTransport) Dominant() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.dominant == "" {
+		return nil
+	}
+	return t.streamTracks(t.dominant)
+}
+
+// StreamTracks returns the tracks of the stream of track tid, e.g. a
+// participant's video as well as their audio
+func (t *WebRTCTransport) StreamTracks(tid string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	b, ok := t.builders[tid]
+	if !ok {
+		return []string{tid}
+	}
+	return t.streamTracks(b.Track().StreamID())
+}
+
+// streamTracks returns the tracks of a stream. Holds t.mu.
+func (t *WebRTCTransport) streamTracks(stream string) []string {
+	var tids []string
+	for tid, b := range t.builders {
+		if b.Track().StreamID() == stream {
+			tids = append(tids, tid)
+		}
+	}
+	return tids
+}
+
+// rebalance requests the layer of each video stream for the dominant
+// speaker, if the session is composited. Holds t.mu.
+func (t *WebRTCTransport) rebalance() {
+	if t.dominant == "" || !t.composite() {
+		return
+	}
+	for _, b := range t.builders {
+		if b.Track().Kind() == webrtc.RTPCodecTypeVideo {
+			t.request(b.Track().StreamID())
+		}
+	}
+}
+
+// request asks for the layer of a stream, if it changed. Holds t.mu.
+func (t *WebRTCTransport) request(stream string) {
+	layer := t.simulcast.Others
+	if stream == t.dominant {
+		layer = t.simulcast.Speaker
+	}
+	if t.layers[stream] == layer {
+		return
+	}
+	if err := t.feedback(SFUFeedback{StreamID: stream, Video: layer, Audio: true}); err != nil {
+		log.Warnf("requesting %s layer of stream %s: %v", layer, stream, err)
+		return
+	}
+	log.Debugf("requested %s layer of stream %s", layer, stream)
+	t.layers[stream] = layer
+}
+
+// composite reports whether a process or room of the session composites
+// video. Holds t.mu.
+func (t *WebRTCTransport) composite() bool {
+	for _, p := range t.processes {
+		if c, ok := unwrap(p).(Composite); ok && c.Composite() {
+			return true
+		}
+	}
+	for r := range t.rooms {
+		if c, ok := r.(Composite); ok && c.Composite() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package avp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type compositeElement struct {
+	blockingElement
+}
+
+func (e *compositeElement) Composite() bool { return true }
+
+func TestWebRTCTransport_Escalate(t *testing.T) {
+	var sent []SFUFeedback
+	transport := &WebRTCTransport{
+		builders:  make(map[string]*Builder),
+		processes: map[string]Element{"recorder": &blockingElement{}},
+		simulcast: SimulcastConfig{Others: LayerMedium}.withDefaults(),
+		layers:    make(map[string]string),
+		feedback: func(f SFUFeedback) error {
+			sent = append(sent, f)
+			return nil
+		},
+	}
+	assert.Equal(t, LayerHigh, transport.simulcast.Speaker)
+
+	// Without a composite, the SFU's choice of layers is left alone
+	transport.escalate([]string{"alice", "bob"})
+	assert.Equal(t, "alice", transport.dominant)
+	assert.False(t, transport.composite())
+	assert.Empty(t, sent)
+
+	transport.processes["grid"] = WithCorrelation(WithPriority(&compositeElement{}, PriorityHigh), "c1")
+	assert.True(t, transport.composite())
+	transport.request("alice")
+	transport.request("bob")
+	assert.Equal(t, []SFUFeedback{
+		{StreamID: "alice", Video: LayerHigh, Audio: true},
+		{StreamID: "bob", Video: LayerMedium, Audio: true},
+	}, sent)
+
+	// Layers are only requested when they change
+	sent = nil
+	transport.escalate([]string{"bob", "alice"})
+	transport.request("alice")
+	transport.request("bob")
+	transport.request("bob")
+	assert.Equal(t, []SFUFeedback{
+		{StreamID: "alice", Video: LayerMedium, Audio: true},
+		{StreamID: "bob", Video: LayerHigh, Audio: true},
+	}, sent)
+}
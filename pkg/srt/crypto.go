@@ -0,0 +1,158 @@
+package srt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1" // nolint: gosec
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ErrSecret is returned when the peers' passphrases differ
+var ErrSecret = errors.New("srt: wrong passphrase")
+
+// Key material message fields
+const (
+	kmHeader  = 0x12   // version 1, key material message
+	kmSign    = 0x2029 // "HAI"
+	kmEven    = 1      // key flags of the even key
+	kmCipher  = 2      // AES-CTR
+	kmSE      = 2      // stream encapsulation, MPEG-TS over SRT
+	saltSize  = 16
+	kekRounds = 2048
+)
+
+// keyMaterial is the stream encrypting key of a connection, exchanged
+// wrapped by the key derived from the passphrase
+type keyMaterial struct {
+	salt  []byte
+	key   []byte
+	block cipher.Block
+}
+
+// newKeyMaterial makes a random stream encrypting key of size bytes
+func newKeyMaterial(size int) (*keyMaterial, error) {
+	km := &keyMaterial{salt: make([]byte, saltSize), key: make([]byte, size)}
+	if _, err := rand.Read(km.salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(km.key); err != nil {
+		return nil, err
+	}
+	var err error
+	km.block, err = aes.NewCipher(km.key)
+	return km, err
+}
+
+// kek derives the key encrypting key of a passphrase, salted with the last
+// 8 bytes of the salt
+func kek(passphrase string, salt []byte, size int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt[saltSize-8:], kekRounds, size, sha1.New)
+}
+
+// marshal returns the key material message, the key wrapped with the
+// passphrase
+func (km *keyMaterial) marshal(passphrase string) ([]byte, error) {
+	wrapped, err := wrap(kek(passphrase, km.salt, len(km.key)), km.key)
+	if err != nil {
+		return nil, err
+	}
+	b := []byte{
+		kmHeader, kmSign >> 8, kmSign & 0xff, kmEven,
+		0, 0, 0, 0, // key encrypting key index
+		kmCipher, 0, kmSE, 0, 0, 0,
+		saltSize / 4, byte(len(km.key) / 4),
+	}
+	b = append(b, km.salt...)
+	return append(b, wrapped...), nil
+}
+
+// unmarshalKeyMaterial unwraps the key of a key material message with the
+// passphrase
+func unmarshalKeyMaterial(b []byte, passphrase string) (*keyMaterial, error) {
+	if len(b) < 16 || b[0] != kmHeader || binary.BigEndian.Uint16(b[1:]) != kmSign {
+		return nil, fmt.Errorf("%w: invalid key material", ErrProtocol)
+	}
+	if b[3]&3 != kmEven || b[8] != kmCipher {
+		return nil, fmt.Errorf("%w: only AES-CTR with one key is supported", ErrProtocol)
+	}
+	salt, size := int(b[14])*4, int(b[15])*4
+	if salt != saltSize || (size != 16 && size != 24 && size != 32) || len(b) < 16+salt+size+8 {
+		return nil, fmt.Errorf("%w: invalid key material", ErrProtocol)
+	}
+	km := &keyMaterial{salt: append([]byte(nil), b[16:16+salt]...)}
+	var err error
+	if km.key, err = unwrap(kek(passphrase, km.salt, size), b[16+salt:16+salt+size+8]); err != nil {
+		return nil, err
+	}
+	km.block, err = aes.NewCipher(km.key)
+	return km, err
+}
+
+// crypt encrypts or decrypts the payload of the data packet seq in place,
+// with AES-CTR from an IV of the salt and the sequence number
+func (km *keyMaterial) crypt(seq uint32, payload []byte) {
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint32(iv[10:], seq)
+	for i := 0; i < 14; i++ {
+		iv[i] ^= km.salt[i]
+	}
+	cipher.NewCTR(km.block, iv).XORKeyStream(payload, payload)
+}
+
+// wrapIV is the initial value of AES key wrap
+var wrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// wrap wraps key with kek (RFC 3394)
+func wrap(kek, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(key) / 8
+	out := make([]byte, 8+len(key))
+	copy(out, wrapIV)
+	copy(out[8:], key)
+	b := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			block.Encrypt(b, b)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out, binary.BigEndian.Uint64(b)^t)
+			copy(out[8*i:], b[8:])
+		}
+	}
+	return out, nil
+}
+
+// unwrap unwraps a key wrapped with kek, failing if kek is wrong
+func unwrap(kek, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	out := append([]byte(nil), wrapped...)
+	b := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b, binary.BigEndian.Uint64(out)^t)
+			copy(b[8:], out[8*i:8*i+8])
+			block.Decrypt(b, b)
+			copy(out, b[:8])
+			copy(out[8*i:], b[8:])
+		}
+	}
+	if !bytes.Equal(out[:8], wrapIV) {
+		return nil, ErrSecret
+	}
+	return out[8:], nil
+}
@@ -0,0 +1,249 @@
+package srt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// Control packet types
+const (
+	ctrlHandshake = 0x0000
+	ctrlKeepalive = 0x0001
+	ctrlACK       = 0x0002
+	ctrlNAK       = 0x0003
+	ctrlShutdown  = 0x0005
+	ctrlACKACK    = 0x0006
+)
+
+// Handshake types, and the base of rejection reasons
+const (
+	hsInduction  = 0x00000001
+	hsConclusion = 0xffffffff
+	hsRejected   = 1000
+)
+
+// Rejection reasons
+const (
+	rejectPeer      = 2
+	rejectVersion   = 8
+	rejectBadSecret = 10
+	rejectUnsecure  = 11
+)
+
+// Handshake extensions, and the flags of those in a conclusion
+const (
+	extHSReq = 1
+	extHSRsp = 2
+	extKMReq = 3
+	extKMRsp = 4
+
+	extFlagHSReq = 0x1
+	extFlagKMReq = 0x2
+)
+
+// srtMagic is the extension field of a listener's induction response,
+// showing it speaks handshake version 5
+const srtMagic = 0x4a17
+
+// SRT options of the HSREQ extension: TSBPD both ways, encryption
+// capable, too late packet drop, periodic NAKs and the retransmission flag
+const srtFlags = 0x3f
+
+// srtVersion is the SRT version spoken, 1.4.1
+const srtVersion = 0x010401
+
+// Sizes of packets
+const (
+	headerSize = 16
+	// PayloadSize is the largest payload of a data packet, seven
+	// transport stream packets
+	PayloadSize = 1316
+	mtu         = 1500
+)
+
+// packet is an SRT packet, control or data
+type packet struct {
+	control bool
+	// Control packets
+	typ     uint16
+	subtype uint16
+	info    uint32 // type specific
+	// Data packets
+	seq     uint32
+	msg     uint32
+	key     byte // encryption key flags, even or odd
+	rexmit  bool
+	time    uint32 // µs since the connection started
+	socket  uint32 // of the destination
+	payload []byte
+}
+
+func (p *packet) marshal() []byte {
+	b := make([]byte, headerSize, headerSize+len(p.payload))
+	if p.control {
+		binary.BigEndian.PutUint32(b, 1<<31|uint32(p.typ)<<16|uint32(p.subtype))
+		binary.BigEndian.PutUint32(b[4:], p.info)
+	} else {
+		binary.BigEndian.PutUint32(b, p.seq&seqMask)
+		// A solo packet of a message, its own
+		flags := uint32(3)<<30 | uint32(p.key)<<27 | p.msg&msgMask
+		if p.rexmit {
+			flags |= 1 << 26
+		}
+		binary.BigEndian.PutUint32(b[4:], flags)
+	}
+	binary.BigEndian.PutUint32(b[8:], p.time)
+	binary.BigEndian.PutUint32(b[12:], p.socket)
+	return append(b, p.payload...)
+}
+
+func unmarshal(b []byte) (*packet, error) {
+	if len(b) < headerSize {
+		return nil, fmt.Errorf("%w: short packet", ErrProtocol)
+	}
+	p := &packet{
+		time:    binary.BigEndian.Uint32(b[8:]),
+		socket:  binary.BigEndian.Uint32(b[12:]),
+		payload: b[headerSize:],
+	}
+	w := binary.BigEndian.Uint32(b)
+	if w&(1<<31) != 0 {
+		p.control = true
+		p.typ = uint16(w >> 16 & 0x7fff)
+		p.subtype = uint16(w)
+		p.info = binary.BigEndian.Uint32(b[4:])
+		return p, nil
+	}
+	p.seq = w
+	flags := binary.BigEndian.Uint32(b[4:])
+	p.key = byte(flags >> 27 & 3)
+	p.rexmit = flags&(1<<26) != 0
+	p.msg = flags & msgMask
+	return p, nil
+}
+
+// Masks of sequence and message numbers
+const (
+	seqMask = 0x7fffffff
+	msgMask = 0x03ffffff
+)
+
+// seqBefore reports whether sequence number a comes before b, allowing
+// for wraparound
+func seqBefore(a, b uint32) bool {
+	d := (b - a) & seqMask
+	return d != 0 && d < 1<<30
+}
+
+// extension is a handshake extension
+type extension struct {
+	typ  uint16
+	data []byte // a multiple of 4 bytes
+}
+
+// handshake is the payload of a handshake packet
+type handshake struct {
+	version    uint32
+	encryption uint16
+	extension  uint16
+	isn        uint32
+	mtu        uint32
+	window     uint32
+	typ        uint32
+	socket     uint32
+	cookie     uint32
+	peer       [16]byte
+	extensions []extension
+}
+
+func (h *handshake) marshal() []byte {
+	b := make([]byte, 48)
+	binary.BigEndian.PutUint32(b, h.version)
+	binary.BigEndian.PutUint16(b[4:], h.encryption)
+	binary.BigEndian.PutUint16(b[6:], h.extension)
+	binary.BigEndian.PutUint32(b[8:], h.isn)
+	binary.BigEndian.PutUint32(b[12:], h.mtu)
+	binary.BigEndian.PutUint32(b[16:], h.window)
+	binary.BigEndian.PutUint32(b[20:], h.typ)
+	binary.BigEndian.PutUint32(b[24:], h.socket)
+	binary.BigEndian.PutUint32(b[28:], h.cookie)
+	copy(b[32:], h.peer[:])
+	for _, e := range h.extensions {
+		b = append(b, byte(e.typ>>8), byte(e.typ), byte(len(e.data)/4>>8), byte(len(e.data)/4))
+		b = append(b, e.data...)
+	}
+	return b
+}
+
+func unmarshalHandshake(b []byte) (*handshake, error) {
+	if len(b) < 48 {
+		return nil, fmt.Errorf("%w: short handshake", ErrProtocol)
+	}
+	h := &handshake{
+		version:    binary.BigEndian.Uint32(b),
+		encryption: binary.BigEndian.Uint16(b[4:]),
+		extension:  binary.BigEndian.Uint16(b[6:]),
+		isn:        binary.BigEndian.Uint32(b[8:]),
+		mtu:        binary.BigEndian.Uint32(b[12:]),
+		window:     binary.BigEndian.Uint32(b[16:]),
+		typ:        binary.BigEndian.Uint32(b[20:]),
+		socket:     binary.BigEndian.Uint32(b[24:]),
+		cookie:     binary.BigEndian.Uint32(b[28:]),
+	}
+	copy(h.peer[:], b[32:48])
+	for b = b[48:]; len(b) >= 4; {
+		typ := binary.BigEndian.Uint16(b)
+		n := int(binary.BigEndian.Uint16(b[2:])) * 4
+		if len(b) < 4+n {
+			return nil, fmt.Errorf("%w: short handshake extension", ErrProtocol)
+		}
+		h.extensions = append(h.extensions, extension{typ: typ, data: b[4 : 4+n]})
+		b = b[4+n:]
+	}
+	return h, nil
+}
+
+// find returns the data of an extension, or nil
+func (h *handshake) find(typ uint16) []byte {
+	for _, e := range h.extensions {
+		if e.typ == typ {
+			return e.data
+		}
+	}
+	return nil
+}
+
+// hsExtension returns the HSREQ or HSRSP extension of the latency
+// asked for both ways
+func hsExtension(typ uint16, latency uint16) extension {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint32(b, srtVersion)
+	binary.BigEndian.PutUint32(b[4:], srtFlags)
+	binary.BigEndian.PutUint16(b[8:], latency)  // receiving
+	binary.BigEndian.PutUint16(b[10:], latency) // sending
+	return extension{typ: typ, data: b}
+}
+
+// peerLatency returns the latency a peer's HSREQ or HSRSP asks to receive
+// with
+func peerLatency(data []byte) (uint16, error) {
+	if len(data) < 12 {
+		return 0, fmt.Errorf("%w: short SRT handshake", ErrProtocol)
+	}
+	return binary.BigEndian.Uint16(data[8:]), nil
+}
+
+// peerIP returns the peer address field of a handshake. Each 32 bit word
+// holds the address bytes in the reverse order, as the reference
+// implementation writes them.
+func peerIP(addr *net.UDPAddr) (b [16]byte) {
+	ip := addr.IP.To4()
+	if ip == nil {
+		ip = addr.IP.To16()
+	}
+	for i := 0; i+4 <= len(ip); i += 4 {
+		b[i], b[i+1], b[i+2], b[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+	return b
+}
@@ -0,0 +1,697 @@
+// Package srt sends live streams over SRT (Secure Reliable Transport), as
+// broadcast contribution links use.
+//
+// Only what a live sender needs is implemented: the version 5 handshake as
+// caller or listener, latency negotiation, retransmission of the packets
+// receivers report lost until they are too late to play, keepalives, and
+// AES-CTR encryption with a key exchanged under a passphrase. Each write
+// is a message of one packet, e.g. seven transport stream packets:
+//
+//	conn, err := srt.Dial(ctx, "decoder.example.com:9000", srt.Config{Latency: 200 * time.Millisecond})
+//	...
+//	_, err = conn.Write(ts)
+//
+// Receiving isn't supported, packets from the peer other than control
+// packets are ignored.
+package srt
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrConfig is returned for invalid configs
+	ErrConfig = errors.New("srt: invalid config")
+	// ErrProtocol is returned when the peer breaks the protocol
+	ErrProtocol = errors.New("srt: protocol error")
+	// ErrRejected is returned when the peer rejects the connection
+	ErrRejected = errors.New("srt: rejected")
+	// ErrTimeout is returned when the peer stops answering
+	ErrTimeout = errors.New("srt: peer timed out")
+	// ErrClosed is returned once the connection is closed, by either peer
+	ErrClosed = errors.New("srt: connection closed")
+)
+
+// Timing of the connection
+const (
+	defaultLatency = 120 * time.Millisecond
+	dialTimeout    = 5 * time.Second
+	resend         = 250 * time.Millisecond // of handshakes
+	tick           = 100 * time.Millisecond
+	keepalive      = time.Second
+	peerTimeout    = 5 * time.Second
+)
+
+// window is the most packets kept for retransmission, the flow window
+const window = 8192
+
+// Config of a connection.
+// Latency: How long the receiver buffers packets for lost ones to be
+// retransmitted, defaults to 120ms. The larger of the peers' is used.
+// Passphrase: Encrypts the stream, 10 to 79 characters. The peer's must
+// match.
+// KeyLength: Of the AES key encrypting the stream, 16, 24 or 32 bytes,
+// defaults to 16.
+type Config struct {
+	Latency    time.Duration
+	Passphrase string
+	KeyLength  int
+}
+
+// Check validates the config, filling in defaults
+func (c *Config) Check() error {
+	if c.Latency <= 0 {
+		c.Latency = defaultLatency
+	}
+	if c.Latency > 0xffff*time.Millisecond {
+		return fmt.Errorf("%w: latency %s too long", ErrConfig, c.Latency)
+	}
+	if c.Passphrase == "" {
+		return nil
+	}
+	if n := len(c.Passphrase); n < 10 || n > 79 {
+		return fmt.Errorf("%w: passphrases are 10 to 79 characters", ErrConfig)
+	}
+	switch c.KeyLength {
+	case 0:
+		c.KeyLength = 16
+	case 16, 24, 32:
+	default:
+		return fmt.Errorf("%w: keys are 16, 24 or 32 bytes", ErrConfig)
+	}
+	return nil
+}
+
+// encryption returns the encryption field of handshakes, the key length
+// in 64 bit words
+func (c *Config) encryption() uint16 {
+	if c.Passphrase == "" {
+		return 0
+	}
+	return uint16(c.KeyLength / 8)
+}
+
+// sent is a data packet kept for retransmission
+type sent struct {
+	p  *packet
+	at time.Time
+}
+
+// Conn is a connection sending a stream
+type Conn struct {
+	sock       *net.UDPConn
+	peer       *net.UDPAddr // of a listener's peer, a caller's socket is connected
+	socket     uint32
+	peerSocket uint32
+	start      time.Time
+	latency    time.Duration
+	km         *keyMaterial
+	// response to a caller's conclusion, repeated if the caller repeats it
+	response []byte
+
+	mu       sync.Mutex
+	seq, msg uint32
+	sent     []sent
+	lastSend time.Time
+	lastRecv time.Time
+	err      error
+	done     chan struct{}
+	stop     chan struct{}
+	stopped  chan struct{}
+	close    sync.Once
+}
+
+func newConn(sock *net.UDPConn, peer *net.UDPAddr) *Conn {
+	now := time.Now()
+	return &Conn{
+		sock:     sock,
+		peer:     peer,
+		socket:   random() & seqMask,
+		start:    now,
+		msg:      1,
+		lastSend: now,
+		lastRecv: now,
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// random returns a random 32 bit number, for socket ids and sequence
+// numbers
+func random() uint32 {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+// Dial calls a listening peer at addr, host:port. The context bounds
+// connecting, not the connection.
+func Dial(ctx context.Context, addr string, c Config) (*Conn, error) {
+	if err := c.Check(); err != nil {
+		return nil, err
+	}
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	sock, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dialTimeout)
+		defer cancel()
+	}
+	conn, err := dial(ctx, sock, raddr, c)
+	if err != nil {
+		sock.Close()
+		return nil, err
+	}
+	go conn.receive(true)
+	return conn, nil
+}
+
+func dial(ctx context.Context, sock *net.UDPConn, raddr *net.UDPAddr, c Config) (*Conn, error) {
+	conn := newConn(sock, nil)
+	isn := random() & seqMask
+	hs := &handshake{
+		version:   4,
+		extension: 2,
+		isn:       isn,
+		mtu:       mtu,
+		window:    window,
+		typ:       hsInduction,
+		socket:    conn.socket,
+		peer:      peerIP(raddr),
+	}
+	res, err := conn.exchange(ctx, hs)
+	if err != nil {
+		return nil, err
+	}
+	if res.version != 5 || res.extension != srtMagic {
+		return nil, fmt.Errorf("%w: peer doesn't speak handshake version 5", ErrProtocol)
+	}
+
+	hs.version = 5
+	hs.encryption = c.encryption()
+	hs.extension = extFlagHSReq
+	hs.typ = hsConclusion
+	hs.cookie = res.cookie
+	hs.extensions = []extension{hsExtension(extHSReq, uint16(c.Latency/time.Millisecond))}
+	var km []byte
+	if c.Passphrase != "" {
+		if conn.km, err = newKeyMaterial(c.KeyLength); err != nil {
+			return nil, err
+		}
+		if km, err = conn.km.marshal(c.Passphrase); err != nil {
+			return nil, err
+		}
+		hs.extension |= extFlagKMReq
+		hs.extensions = append(hs.extensions, extension{typ: extKMReq, data: km})
+	}
+	if res, err = conn.exchange(ctx, hs); err != nil {
+		return nil, err
+	}
+	if res.typ >= hsRejected && res.typ < hsConclusion {
+		return nil, rejection(res.typ - hsRejected)
+	}
+	if res.typ != hsConclusion {
+		return nil, fmt.Errorf("%w: handshake %#x instead of a conclusion", ErrProtocol, res.typ)
+	}
+	latency, err := peerLatency(res.find(extHSRsp))
+	if err != nil {
+		return nil, err
+	}
+	conn.latency = c.Latency
+	if d := time.Duration(latency) * time.Millisecond; d > conn.latency {
+		conn.latency = d
+	}
+	if km != nil {
+		if rsp := res.find(extKMRsp); len(rsp) != len(km) {
+			return nil, ErrSecret
+		}
+	}
+	conn.peerSocket = res.socket
+	conn.seq = isn
+	return conn, nil
+}
+
+// exchange sends a caller's handshake until the listener answers
+func (c *Conn) exchange(ctx context.Context, hs *handshake) (*handshake, error) {
+	req := (&packet{control: true, typ: ctrlHandshake, payload: hs.marshal()}).marshal()
+	buf := make([]byte, mtu)
+	for {
+		if _, err := c.sock.Write(req); err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(resend)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		if err := c.sock.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := c.sock.Read(buf)
+			if err != nil {
+				var ne net.Error
+				if !errors.As(err, &ne) || !ne.Timeout() {
+					return nil, err
+				}
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				break
+			}
+			p, err := unmarshal(buf[:n])
+			if err != nil || !p.control || p.typ != ctrlHandshake || p.socket != c.socket {
+				continue
+			}
+			return unmarshalHandshake(append([]byte(nil), p.payload...))
+		}
+	}
+}
+
+// rejection returns the error of a rejection reason
+func rejection(reason uint32) error {
+	switch reason {
+	case rejectBadSecret:
+		return ErrSecret
+	case rejectUnsecure:
+		return fmt.Errorf("%w: the peers don't both encrypt", ErrRejected)
+	case rejectVersion:
+		return fmt.Errorf("%w: unsupported version", ErrRejected)
+	}
+	return fmt.Errorf("%w: reason %d", ErrRejected, reason)
+}
+
+// Listener accepts callers
+type Listener struct {
+	sock   *net.UDPConn
+	cfg    Config
+	secret []byte // of SYN cookies
+}
+
+// Listen listens for callers on addr, host:port
+func Listen(addr string, c Config) (*Listener, error) {
+	if err := c.Check(); err != nil {
+		return nil, err
+	}
+	laddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	sock, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		sock.Close()
+		return nil, err
+	}
+	return &Listener{sock: sock, cfg: c, secret: secret}, nil
+}
+
+// Addr returns the address listened on
+func (l *Listener) Addr() net.Addr {
+	return l.sock.LocalAddr()
+}
+
+// Close stops listening, closing the connection accepted last
+func (l *Listener) Close() error {
+	return l.sock.Close()
+}
+
+// cookie returns the SYN cookie of a caller, valid for a minute or two
+func (l *Listener) cookie(addr *net.UDPAddr, at time.Time) uint32 {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "%s/%d", addr, at.Unix()/60)
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
+
+// Accept waits for a caller to connect. Connections share the listener's
+// socket, so one caller is accepted at a time: Accept again once the
+// connection closes.
+func (l *Listener) Accept(ctx context.Context) (*Conn, error) {
+	buf := make([]byte, mtu)
+	for {
+		if err := l.sock.SetReadDeadline(time.Now().Add(tick)); err != nil {
+			return nil, err
+		}
+		n, addr, err := l.sock.ReadFromUDP(buf)
+		if err != nil {
+			var ne net.Error
+			if !errors.As(err, &ne) || !ne.Timeout() {
+				return nil, err
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		p, err := unmarshal(buf[:n])
+		if err != nil || !p.control || p.typ != ctrlHandshake {
+			continue
+		}
+		hs, err := unmarshalHandshake(p.payload)
+		if err != nil {
+			continue
+		}
+		if conn := l.answer(addr, hs); conn != nil {
+			go conn.receive(false)
+			return conn, nil
+		}
+	}
+}
+
+// answer answers a caller's handshake, returning the connection once it
+// concludes
+func (l *Listener) answer(addr *net.UDPAddr, hs *handshake) *Conn {
+	now := time.Now()
+	res := &handshake{
+		version:    5,
+		encryption: l.cfg.encryption(),
+		isn:        hs.isn,
+		mtu:        mtu,
+		window:     window,
+		typ:        hs.typ,
+		peer:       peerIP(addr),
+	}
+	switch {
+	case hs.typ == hsInduction:
+		res.extension = srtMagic
+		res.socket = random() & seqMask
+		res.cookie = l.cookie(addr, now)
+		l.send(addr, hs.socket, res)
+		return nil
+	case hs.typ != hsConclusion || hs.version != 5:
+		return nil
+	case hs.cookie != l.cookie(addr, now) && hs.cookie != l.cookie(addr, now.Add(-time.Minute)):
+		return nil
+	}
+
+	hsreq := hs.find(extHSReq)
+	latency, err := peerLatency(hsreq)
+	if err != nil {
+		l.reject(addr, hs, rejectVersion)
+		return nil
+	}
+	kmreq := hs.find(extKMReq)
+	if (l.cfg.Passphrase == "") != (kmreq == nil) {
+		l.reject(addr, hs, rejectUnsecure)
+		return nil
+	}
+	conn := newConn(l.sock, addr)
+	if kmreq != nil {
+		if conn.km, err = unmarshalKeyMaterial(kmreq, l.cfg.Passphrase); err != nil {
+			reason := uint32(rejectPeer)
+			if errors.Is(err, ErrSecret) {
+				reason = rejectBadSecret
+			}
+			l.reject(addr, hs, reason)
+			return nil
+		}
+	}
+	conn.latency = l.cfg.Latency
+	if d := time.Duration(latency) * time.Millisecond; d > conn.latency {
+		conn.latency = d
+	}
+	conn.peerSocket = hs.socket
+	conn.seq = hs.isn
+
+	res.encryption = hs.encryption
+	res.extension = extFlagHSReq
+	res.socket = conn.socket
+	res.cookie = hs.cookie
+	res.extensions = []extension{hsExtension(extHSRsp, uint16(conn.latency/time.Millisecond))}
+	if kmreq != nil {
+		res.extension |= extFlagKMReq
+		res.extensions = append(res.extensions, extension{typ: extKMRsp, data: kmreq})
+	}
+	conn.response = l.send(addr, hs.socket, res)
+	return conn
+}
+
+// reject refuses a caller's conclusion
+func (l *Listener) reject(addr *net.UDPAddr, hs *handshake, reason uint32) {
+	l.send(addr, hs.socket, &handshake{
+		version: 5,
+		isn:     hs.isn,
+		mtu:     mtu,
+		window:  window,
+		typ:     hsRejected + reason,
+		cookie:  hs.cookie,
+		peer:    peerIP(addr),
+	})
+}
+
+// send sends a handshake to a caller, returning the packet
+func (l *Listener) send(addr *net.UDPAddr, socket uint32, hs *handshake) []byte {
+	b := (&packet{control: true, typ: ctrlHandshake, socket: socket, payload: hs.marshal()}).marshal()
+	// Callers repeat handshakes that go unanswered
+	_, _ = l.sock.WriteToUDP(b, addr)
+	return b
+}
+
+// Latency returns the latency negotiated with the peer
+func (c *Conn) Latency() time.Duration {
+	return c.latency
+}
+
+// Encrypted reports whether the stream is encrypted
+func (c *Conn) Encrypted() bool {
+	return c.km != nil
+}
+
+// Write sends b as a data packet of at most PayloadSize bytes
+func (c *Conn) Write(b []byte) (int, error) {
+	if len(b) > PayloadSize {
+		return 0, fmt.Errorf("%w: %d bytes is more than a packet", ErrConfig, len(b))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	now := time.Now()
+	p := &packet{
+		seq:     c.seq,
+		msg:     c.msg,
+		time:    c.timestamp(now),
+		socket:  c.peerSocket,
+		payload: append([]byte(nil), b...),
+	}
+	if c.km != nil {
+		c.km.crypt(p.seq, p.payload)
+		p.key = kmEven
+	}
+	c.seq = (c.seq + 1) & seqMask
+	if c.msg = (c.msg + 1) & msgMask; c.msg == 0 {
+		c.msg = 1
+	}
+	if len(c.sent) >= window {
+		c.sent = c.sent[1:]
+	}
+	c.sent = append(c.sent, sent{p: p, at: now})
+	if err := c.send(p); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Done is closed when the connection fails or is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection ended, once Done is closed
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close tells the peer the stream is over and closes the connection
+func (c *Conn) Close() error {
+	c.close.Do(func() {
+		c.mu.Lock()
+		if c.err == nil {
+			_ = c.send(&packet{control: true, typ: ctrlShutdown, time: c.timestamp(time.Now()), socket: c.peerSocket, payload: make([]byte, 4)})
+		}
+		c.mu.Unlock()
+		c.fail(ErrClosed)
+		close(c.stop)
+		<-c.stopped
+		if c.peer == nil {
+			c.sock.Close()
+		}
+	})
+	return nil
+}
+
+// fail ends the connection
+func (c *Conn) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
+
+// timestamp returns the µs since the connection started
+func (c *Conn) timestamp(at time.Time) uint32 {
+	return uint32(at.Sub(c.start) / time.Microsecond)
+}
+
+// send writes a packet, with c locked
+func (c *Conn) send(p *packet) error {
+	b := p.marshal()
+	var err error
+	if c.peer == nil {
+		_, err = c.sock.Write(b)
+	} else {
+		_, err = c.sock.WriteToUDP(b, c.peer)
+	}
+	c.lastSend = time.Now()
+	return err
+}
+
+// receive handles the peer's control packets until the connection closes,
+// between them sending keepalives, checking the peer is alive and
+// dropping packets too late to retransmit
+func (c *Conn) receive(connected bool) {
+	defer close(c.stopped)
+	buf := make([]byte, mtu)
+	for {
+		select {
+		case <-c.stop:
+			return
+		default:
+		}
+		if err := c.sock.SetReadDeadline(time.Now().Add(tick)); err != nil {
+			c.fail(err)
+			return
+		}
+		var n int
+		var addr *net.UDPAddr
+		var err error
+		if connected {
+			n, err = c.sock.Read(buf)
+		} else {
+			n, addr, err = c.sock.ReadFromUDP(buf)
+		}
+		if err != nil {
+			var ne net.Error
+			if !errors.As(err, &ne) || !ne.Timeout() {
+				c.fail(err)
+				return
+			}
+		} else if addr == nil || (addr.IP.Equal(c.peer.IP) && addr.Port == c.peer.Port) {
+			if p, err := unmarshal(buf[:n]); err == nil && p.control && p.socket == c.socket {
+				if err := c.handle(p); err != nil {
+					c.fail(err)
+				}
+			}
+		}
+		c.tick()
+		if c.Err() != nil {
+			// Leaving a listener's socket to accept the next caller
+			return
+		}
+	}
+}
+
+// handle handles a control packet of the peer, returning ErrClosed when
+// the peer shuts the connection down
+func (c *Conn) handle(p *packet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastRecv = time.Now()
+	switch p.typ {
+	case ctrlACK:
+		if len(p.payload) < 4 {
+			return nil
+		}
+		acked := binary.BigEndian.Uint32(p.payload) & seqMask
+		i := 0
+		for i < len(c.sent) && seqBefore(c.sent[i].p.seq, acked) {
+			i++
+		}
+		c.sent = c.sent[i:]
+		// Light ACKs, of the sequence number alone, aren't acknowledged
+		if len(p.payload) > 4 {
+			_ = c.send(&packet{control: true, typ: ctrlACKACK, info: p.info, time: c.timestamp(c.lastRecv), socket: c.peerSocket})
+		}
+	case ctrlNAK:
+		for b := p.payload; len(b) >= 4; b = b[4:] {
+			from := binary.BigEndian.Uint32(b)
+			to := from & seqMask
+			if from&(1<<31) != 0 && len(b) >= 8 {
+				// A range
+				b = b[4:]
+				to = binary.BigEndian.Uint32(b) & seqMask
+			}
+			c.retransmit(from&seqMask, to)
+		}
+	case ctrlShutdown:
+		return ErrClosed
+	case ctrlHandshake:
+		if c.response != nil {
+			_, _ = c.sock.WriteToUDP(c.response, c.peer)
+		}
+	}
+	return nil
+}
+
+// retransmit resends the packets from one sequence number to another
+// still held, with c locked
+func (c *Conn) retransmit(from, to uint32) {
+	for _, s := range c.sent {
+		if seqBefore(s.p.seq, from) {
+			continue
+		}
+		if seqBefore(to, s.p.seq) {
+			return
+		}
+		p := *s.p
+		p.rexmit = true
+		_ = c.send(&p)
+	}
+}
+
+// tick sends a keepalive when nothing has been sent for a while, fails the
+// connection if the peer has gone quiet, and drops packets the receiver
+// will have given up on
+func (c *Conn) tick() {
+	c.mu.Lock()
+	now := time.Now()
+	if now.Sub(c.lastSend) >= keepalive {
+		_ = c.send(&packet{control: true, typ: ctrlKeepalive, time: c.timestamp(now), socket: c.peerSocket})
+	}
+	late := now.Add(-c.latency - time.Second)
+	i := 0
+	for i < len(c.sent) && c.sent[i].at.Before(late) {
+		i++
+	}
+	c.sent = c.sent[i:]
+	quiet := now.Sub(c.lastRecv) >= peerTimeout
+	c.mu.Unlock()
+	if quiet {
+		c.fail(ErrTimeout)
+	}
+}
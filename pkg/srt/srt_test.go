@@ -0,0 +1,247 @@
+package srt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	// RFC 3394 4.1
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+	wrapped, err := wrap(kek, key)
+	require.NoError(t, err)
+	assert.Equal(t, "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5", hex.EncodeToString(wrapped))
+
+	unwrapped, err := unwrap(kek, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+	kek[0] ^= 1
+	_, err = unwrap(kek, wrapped)
+	assert.Equal(t, ErrSecret, err)
+}
+
+func TestKeyMaterial(t *testing.T) {
+	km, err := newKeyMaterial(24)
+	require.NoError(t, err)
+	b, err := km.marshal("passphrase1")
+	require.NoError(t, err)
+	assert.Equal(t, 16+16+24+8, len(b))
+
+	got, err := unmarshalKeyMaterial(b, "passphrase1")
+	require.NoError(t, err)
+	assert.Equal(t, km.key, got.key)
+	assert.Equal(t, km.salt, got.salt)
+	_, err = unmarshalKeyMaterial(b, "passphrase2")
+	assert.Equal(t, ErrSecret, err)
+
+	payload := []byte("transport stream")
+	km.crypt(7, payload)
+	assert.NotEqual(t, []byte("transport stream"), payload)
+	got.crypt(7, payload)
+	assert.Equal(t, []byte("transport stream"), payload)
+}
+
+func TestSeqBefore(t *testing.T) {
+	assert.True(t, seqBefore(1, 2))
+	assert.False(t, seqBefore(2, 2))
+	assert.False(t, seqBefore(3, 2))
+	assert.True(t, seqBefore(seqMask, 0))
+}
+
+// caller is a receiving peer, calling a Listener
+type caller struct {
+	t      *testing.T
+	sock   *net.UDPConn
+	socket uint32
+	km     *keyMaterial
+	res    *handshake
+}
+
+func newCaller(t *testing.T, addr net.Addr) *caller {
+	sock, err := net.DialUDP("udp", nil, addr.(*net.UDPAddr))
+	require.NoError(t, err)
+	t.Cleanup(func() { sock.Close() })
+	return &caller{t: t, sock: sock, socket: 42}
+}
+
+func (c *caller) write(p *packet) {
+	_, err := c.sock.Write(p.marshal())
+	require.NoError(c.t, err)
+}
+
+// read returns the next packet, skipping keepalives
+func (c *caller) read() *packet {
+	buf := make([]byte, mtu)
+	for {
+		require.NoError(c.t, c.sock.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, err := c.sock.Read(buf)
+		require.NoError(c.t, err)
+		p, err := unmarshal(append([]byte(nil), buf[:n]...))
+		require.NoError(c.t, err)
+		if !p.control || p.typ != ctrlKeepalive {
+			return p
+		}
+	}
+}
+
+// connect shakes hands with the listener
+func (c *caller) connect(latency uint16, passphrase string) {
+	hs := &handshake{version: 4, extension: 2, isn: 1000, typ: hsInduction, socket: c.socket}
+	c.write(&packet{control: true, typ: ctrlHandshake, payload: hs.marshal()})
+	res, err := unmarshalHandshake(c.read().payload)
+	require.NoError(c.t, err)
+	assert.Equal(c.t, uint32(5), res.version)
+	assert.Equal(c.t, uint16(srtMagic), res.extension)
+
+	hs.version, hs.typ, hs.cookie = 5, hsConclusion, res.cookie
+	hs.extensions = []extension{hsExtension(extHSReq, latency)}
+	if passphrase != "" {
+		c.km, err = newKeyMaterial(16)
+		require.NoError(c.t, err)
+		km, err := c.km.marshal(passphrase)
+		require.NoError(c.t, err)
+		hs.extensions = append(hs.extensions, extension{typ: extKMReq, data: km})
+	}
+	c.write(&packet{control: true, typ: ctrlHandshake, payload: hs.marshal()})
+	p := c.read()
+	assert.Equal(c.t, c.socket, p.socket)
+	c.res, err = unmarshalHandshake(p.payload)
+	require.NoError(c.t, err)
+}
+
+func accept(t *testing.T, l *Listener) chan *Conn {
+	accepted := make(chan *Conn, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := l.Accept(ctx)
+		assert.NoError(t, err)
+		accepted <- conn
+	}()
+	return accepted
+}
+
+func TestListener(t *testing.T) {
+	l, err := Listen("127.0.0.1:0", Config{Latency: 200 * time.Millisecond, Passphrase: "passphrase1"})
+	require.NoError(t, err)
+	defer l.Close()
+	accepted := accept(t, l)
+
+	c := newCaller(t, l.Addr())
+	c.connect(300, "passphrase1")
+	require.Equal(t, uint32(hsConclusion), c.res.typ)
+	latency, err := peerLatency(c.res.find(extHSRsp))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(300), latency)
+	conn := <-accepted
+	require.NotNil(t, conn)
+	assert.Equal(t, 300*time.Millisecond, conn.Latency())
+	assert.True(t, conn.Encrypted())
+
+	for _, s := range []string{"one", "two", "three"} {
+		_, err = conn.Write([]byte(s))
+		require.NoError(t, err)
+	}
+	for i, s := range []string{"one", "two", "three"} {
+		p := c.read()
+		assert.False(t, p.control)
+		assert.Equal(t, uint32(1000+i), p.seq)
+		assert.Equal(t, byte(kmEven), p.key)
+		c.km.crypt(p.seq, p.payload)
+		assert.Equal(t, s, string(p.payload))
+	}
+
+	// Lost packets are retransmitted, flagged
+	c.write(&packet{control: true, typ: ctrlNAK, socket: conn.socket, payload: []byte{0x80, 0, 0x03, 0xe8, 0, 0, 0x03, 0xe9}})
+	for _, s := range []string{"one", "two"} {
+		p := c.read()
+		assert.True(t, p.rexmit)
+		c.km.crypt(p.seq, p.payload)
+		assert.Equal(t, s, string(p.payload))
+	}
+
+	// Full ACKs are acknowledged, and acknowledged packets no longer held
+	ack := make([]byte, 16)
+	binary.BigEndian.PutUint32(ack, 1002)
+	c.write(&packet{control: true, typ: ctrlACK, info: 5, socket: conn.socket, payload: ack})
+	p := c.read()
+	assert.Equal(t, uint16(ctrlACKACK), p.typ)
+	assert.Equal(t, uint32(5), p.info)
+	c.write(&packet{control: true, typ: ctrlNAK, socket: conn.socket, payload: []byte{0, 0, 0x03, 0xe8}})
+
+	assert.NoError(t, conn.Close())
+	p = c.read()
+	assert.Equal(t, uint16(ctrlShutdown), p.typ)
+	assert.Equal(t, ErrClosed, conn.Err())
+	_, err = conn.Write([]byte("four"))
+	assert.Equal(t, ErrClosed, err)
+
+	// The next caller can connect, once the last closes
+	accepted = accept(t, l)
+	c = newCaller(t, l.Addr())
+	c.connect(0, "wrong passphrase")
+	assert.Equal(t, uint32(hsRejected+rejectBadSecret), c.res.typ)
+	c.connect(0, "")
+	assert.Equal(t, uint32(hsRejected+rejectUnsecure), c.res.typ)
+	c.connect(0, "passphrase1")
+	conn = <-accepted
+	assert.Equal(t, 200*time.Millisecond, conn.Latency())
+
+	// The peer shutting down closes the connection
+	c.write(&packet{control: true, typ: ctrlShutdown, socket: conn.socket})
+	select {
+	case <-conn.Done():
+		assert.Equal(t, ErrClosed, conn.Err())
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed")
+	}
+	conn.Close()
+}
+
+func TestDial(t *testing.T) {
+	l, err := Listen("127.0.0.1:0", Config{Passphrase: "passphrase1", KeyLength: 32})
+	require.NoError(t, err)
+	defer l.Close()
+	accepted := accept(t, l)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = Dial(ctx, l.Addr().String(), Config{Passphrase: "passphrase2"})
+	assert.Equal(t, ErrSecret, err)
+	_, err = Dial(ctx, l.Addr().String(), Config{})
+	assert.True(t, errors.Is(err, ErrRejected))
+
+	conn, err := Dial(ctx, l.Addr().String(), Config{Latency: 500 * time.Millisecond, Passphrase: "passphrase1"})
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, 500*time.Millisecond, conn.Latency())
+	accepted2 := <-accepted
+	require.NotNil(t, accepted2)
+	defer accepted2.Close()
+	assert.Equal(t, 500*time.Millisecond, accepted2.Latency())
+	assert.Equal(t, conn.km.key, accepted2.km.key)
+
+	_, err = conn.Write(bytes.Repeat([]byte{1}, PayloadSize+1))
+	assert.True(t, errors.Is(err, ErrConfig))
+}
+
+func TestConfig(t *testing.T) {
+	for _, c := range []Config{
+		{Passphrase: "short"},
+		{Passphrase: "passphrase1", KeyLength: 20},
+		{Latency: time.Minute * 2},
+	} {
+		_, err := Listen("127.0.0.1:0", c)
+		assert.True(t, errors.Is(err, ErrConfig))
+	}
+}
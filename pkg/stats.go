@@ -0,0 +1,153 @@
+package avp
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// sampleEvery is how often a Write call is sampled for CPU time and
+// allocations. Reading memory stats stops the world, so only one call per
+// period is sampled across the node, and each element's measurements are
+// scaled up by its sample count.
+var sampleEvery = time.Second
+
+// nextSample is when the next call is sampled, in Unix nanoseconds
+var nextSample int64
+
+// sampling reports whether the call starting at now is sampled
+func sampling(now time.Time) bool {
+	next := atomic.LoadInt64(&nextSample)
+	return now.UnixNano() >= next &&
+		atomic.CompareAndSwapInt64(&nextSample, next, now.Add(sampleEvery).UnixNano())
+}
+
+// ElementStats is the resource usage of an element on one track
+type ElementStats struct {
+	// ID is the process id, or the track id for elements attached with Run
+	ID       string
+	Session  string
+	Track    string
+	Element  string
+	Priority Priority
+	Samples  uint64
+	Dropped  uint64
+	// Busy is the wall clock time spent in Write, not CPU time: it
+	// includes time the element's goroutine waits, e.g. on I/O, locks or
+	// the scheduler of a loaded node. Timing Write is cheap, so every call
+	// is measured.
+	Busy time.Duration
+	// CPU estimates the CPU time used in Write, from sampled calls. Only
+	// the element's own goroutine is measured, and it is zero where
+	// per thread CPU time is not available.
+	CPU time.Duration
+	// Allocated estimates the bytes allocated in Write, from sampled
+	// calls. Allocations by other goroutines during a sampled call are
+	// included, so it is an upper bound on busy nodes.
+	Allocated uint64
+	// Correlation is the ID of the request that started the element
+	Correlation string
+
+	element Element
+}
+
+// usage accumulates measurements of Write calls
+type usage struct {
+	samples uint64
+	dropped uint64
+	busy    int64
+
+	// sampled calls and what they used
+	sampled   uint64
+	cpu       int64
+	allocated uint64
+}
+
+func (u *usage) measure(ctx context.Context, e Element, s *Sample) error {
+	atomic.AddUint64(&u.samples, 1)
+	start := time.Now()
+	if !sampling(start) {
+		err := WriteContext(ctx, e, s)
+		atomic.AddInt64(&u.busy, int64(time.Since(start)))
+		return err
+	}
+
+	// Lock the thread, so its CPU time is only this goroutine's
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	cpu := threadCPU()
+	start = time.Now()
+	err := WriteContext(ctx, e, s)
+	atomic.AddInt64(&u.busy, int64(time.Since(start)))
+	cpu = threadCPU() - cpu
+	runtime.ReadMemStats(&after)
+
+	atomic.AddInt64(&u.cpu, int64(cpu))
+	atomic.AddUint64(&u.allocated, after.TotalAlloc-before.TotalAlloc)
+	atomic.AddUint64(&u.sampled, 1)
+	return err
+}
+
+// estimate scales what sampled calls used up to all calls
+func (u *usage) estimate(samples uint64) (time.Duration, uint64) {
+	sampled := atomic.LoadUint64(&u.sampled)
+	if sampled == 0 {
+		return 0, 0
+	}
+	scale := float64(samples) / float64(sampled)
+	cpu := float64(atomic.LoadInt64(&u.cpu)) * scale
+	allocated := float64(atomic.LoadUint64(&u.allocated)) * scale
+	return time.Duration(cpu), uint64(allocated)
+}
+
+func (q *elementQueue) stats() ElementStats {
+	samples := atomic.LoadUint64(&q.usage.samples)
+	cpu, allocated := q.usage.estimate(samples)
+	return ElementStats{
+		Element:     fmt.Sprintf("%T", unwrap(q.e)),
+		Priority:    q.priority,
+		Samples:     samples,
+		Dropped:     atomic.LoadUint64(&q.usage.dropped),
+		Busy:        time.Duration(atomic.LoadInt64(&q.usage.busy)),
+		CPU:         cpu,
+		Allocated:   allocated,
+		Correlation: correlationOf(q.e),
+		element:     q.e,
+	}
+}
+
+// Stats returns the resource usage of the elements attached to the builder
+func (b *Builder) Stats() []ElementStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stats := make([]ElementStats, 0, len(b.elements))
+	for _, q := range b.elements {
+		s := q.stats()
+		s.Track = b.track.ID()
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// Stats returns the resource usage of the elements of every track
+func (t *WebRTCTransport) Stats() []ElementStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var stats []ElementStats
+	for _, b := range t.builders {
+		for _, s := range b.Stats() {
+			s.Session = t.id
+			for id, e := range t.processes {
+				if e == s.element {
+					s.ID = id
+				}
+			}
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
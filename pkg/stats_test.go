@@ -0,0 +1,99 @@
+package avp
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sleepingElement struct {
+	elementMock
+}
+
+func (e *sleepingElement) Write(*Sample) error {
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+// spinningElement allocates and burns CPU in Write
+type spinningElement struct {
+	elementMock
+	buf [][]byte
+}
+
+func (e *spinningElement) Write(*Sample) error {
+	e.buf = append(e.buf, make([]byte, 1<<20))
+	for start := time.Now(); time.Since(start) < time.Millisecond; {
+	}
+	return nil
+}
+
+func runStats(e Element, samples int) ElementStats {
+	q := newElementQueue(WithPriority(e, PriorityLow))
+	q.start()
+	for i := 0; i < samples; i++ {
+		q.push(&Sample{})
+		// Keep the queue short, so nothing is shed
+		for len(q.ch) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	q.stop()
+	return q.stats()
+}
+
+func TestElementQueue_Stats(t *testing.T) {
+	const samples = 64
+	s := runStats(&sleepingElement{}, samples)
+	assert.Equal(t, "*avp.sleepingElement", s.Element)
+	assert.Equal(t, PriorityLow, s.Priority)
+	assert.Equal(t, uint64(samples), s.Samples)
+	assert.Equal(t, uint64(0), s.Dropped)
+	// Sleeping counts, as Busy is wall clock time
+	assert.True(t, s.Busy >= samples*time.Millisecond)
+}
+
+func TestElementQueue_StatsSampled(t *testing.T) {
+	defer func(every time.Duration) { sampleEvery = every }(sampleEvery)
+	sampleEvery = 0
+	atomic.StoreInt64(&nextSample, 0)
+
+	const samples = 16
+	s := runStats(&sleepingElement{}, samples)
+	// Sleeping is not CPU time
+	assert.True(t, s.CPU < s.Busy/2, "%s of %s", s.CPU, s.Busy)
+
+	s = runStats(&spinningElement{}, samples)
+	assert.True(t, s.Allocated >= samples<<20, "%d", s.Allocated)
+	if runtime.GOOS == "linux" {
+		assert.True(t, s.CPU >= samples*time.Millisecond/2, "%s", s.CPU)
+	}
+}
+
+func TestElementQueue_StatsScaled(t *testing.T) {
+	u := usage{sampled: 2, cpu: int64(3 * time.Millisecond), allocated: 1000}
+	cpu, allocated := u.estimate(10)
+	assert.Equal(t, 15*time.Millisecond, cpu)
+	assert.Equal(t, uint64(5000), allocated)
+
+	cpu, allocated = (&usage{}).estimate(10)
+	assert.Zero(t, cpu)
+	assert.Zero(t, allocated)
+}
+
+func TestElementQueue_StatsCorrelation(t *testing.T) {
+	e := &sleepingElement{}
+	for _, el := range []Element{
+		WithCorrelation(WithPriority(e, PriorityHigh), "req-1"),
+		WithPriority(WithCorrelation(e, "req-1"), PriorityHigh),
+	} {
+		s := newElementQueue(el).stats()
+		assert.Equal(t, "*avp.sleepingElement", s.Element)
+		assert.Equal(t, PriorityHigh, s.Priority)
+		assert.Equal(t, "req-1", s.Correlation)
+	}
+	assert.Equal(t, "", newElementQueue(WithCorrelation(e, "")).stats().Correlation)
+}
@@ -0,0 +1,162 @@
+// Package mp4 stitches the segments of a recording into a single MP4
+// file.
+package mp4
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/stitch"
+)
+
+// Matroska codecs MP4 holds, those of Mp4Saver
+const (
+	codecH264 = "V_MPEG4/ISO/AVC"
+	codecOpus = "A_OPUS"
+)
+
+// Supported reports whether a recording can be stitched to a file of
+// name, by its extension
+func Supported(name string) bool {
+	return strings.ToLower(path.Ext(name)) == ".mp4"
+}
+
+// Render writes the recording described by m to w as a single fragmented
+// MP4 file, muxed as an Mp4Saver muxes recordings. MP4 holds one H.264
+// video track and one Opus audio track, so recordings of other codecs,
+// e.g. VP8, or of more tracks return stitch.ErrUnsupported; stitch those
+// as WebM. progress, if not nil, is called with the fraction of segments
+// read.
+func Render(ctx context.Context, w io.Writer, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+	var kinds []int
+	var avc *avcConfig
+	var saver *elements.Mp4Saver
+	out := &output{w: w}
+	err := stitch.Frames(ctx, m, open, progress, func(tracks []webm.TrackEntry) error {
+		var audio, video bool
+		for _, t := range tracks {
+			switch {
+			case t.CodecID == codecOpus && !audio:
+				audio = true
+				kinds = append(kinds, avp.TypeOpus)
+			case t.CodecID == codecH264 && !video:
+				var err error
+				if avc, err = parseAVCConfig(t.CodecPrivate); err != nil {
+					return fmt.Errorf("mp4: track %s: %w", t.Name, err)
+				}
+				video = true
+				kinds = append(kinds, avp.TypeH264)
+			default:
+				return fmt.Errorf("%w: mp4 of %s track %s, mp4 holds one H.264 and one Opus track", stitch.ErrUnsupported, t.CodecID, t.Name)
+			}
+		}
+		saver = elements.NewMp4Saver(&elements.Mp4SaverConfig{Audio: audio, Video: video})
+		saver.Attach(out)
+		return nil
+	}, func(f stitch.Frame) error {
+		sample := &avp.Sample{Type: kinds[f.Track-1], Payload: f.Data}
+		switch sample.Type {
+		case avp.TypeH264:
+			sample.ClockRate = 90000
+			sample.Payload = avc.annexB(f.Data, f.Keyframe)
+		case avp.TypeOpus:
+			sample.ClockRate = 48000
+		}
+		sample.Timestamp = uint32(int64(f.Time) * int64(sample.ClockRate) / int64(time.Second))
+		if err := saver.Write(sample); err != nil {
+			return err
+		}
+		return out.err
+	})
+	if saver != nil {
+		saver.Close()
+	}
+	if err == nil {
+		err = out.err
+	}
+	return err
+}
+
+// output writes the file of an Mp4Saver to w
+type output struct {
+	elements.Leaf
+	w   io.Writer
+	err error
+}
+
+func (o *output) Write(sample *avp.Sample) error {
+	if o.err == nil {
+		_, o.err = o.w.Write(sample.Payload.([]byte))
+	}
+	return o.err
+}
+
+// avcConfig is the parameter sets and NAL unit length size of an H.264
+// track, from its AVCDecoderConfigurationRecord
+type avcConfig struct {
+	size int
+	sets [][]byte
+}
+
+var errAVCConfig = errors.New("bad AVC decoder configuration")
+
+func parseAVCConfig(b []byte) (*avcConfig, error) {
+	if len(b) < 6 {
+		return nil, errAVCConfig
+	}
+	c := &avcConfig{size: int(b[4]&3) + 1}
+	n, p := int(b[5]&0x1f), b[6:]
+	for i := 0; i < 2; i++ {
+		for ; n > 0; n-- {
+			if len(p) < 2 || len(p) < 2+int(binary.BigEndian.Uint16(p)) {
+				return nil, errAVCConfig
+			}
+			l := int(binary.BigEndian.Uint16(p))
+			c.sets = append(c.sets, p[2:2+l])
+			p = p[2+l:]
+		}
+		if i == 0 {
+			if len(p) < 1 {
+				return nil, errAVCConfig
+			}
+			n, p = int(p[0]), p[1:]
+		}
+	}
+	return c, nil
+}
+
+// annexB returns a frame of length prefixed NAL units as an Annex B
+// access unit, as RTP depacketizes them, keyframes led by the parameter
+// sets
+func (c *avcConfig) annexB(frame []byte, keyframe bool) []byte {
+	start := []byte{0, 0, 0, 1}
+	var b []byte
+	if keyframe {
+		for _, set := range c.sets {
+			b = append(append(b, start...), set...)
+		}
+	}
+	for len(frame) >= c.size {
+		var l int
+		for _, x := range frame[:c.size] {
+			l = l<<8 | int(x)
+		}
+		frame = frame[c.size:]
+		if l > len(frame) {
+			break
+		}
+		b = append(append(b, start...), frame[:l]...)
+		frame = frame[l:]
+	}
+	return b
+}
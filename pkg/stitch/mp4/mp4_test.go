@@ -0,0 +1,137 @@
+package mp4
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	avp "github.com/pion/ion-avp/pkg"
+	"github.com/pion/ion-avp/pkg/elements"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/manifest/manifesttest"
+	"github.com/pion/ion-avp/pkg/stitch"
+	"github.com/stretchr/testify/assert"
+)
+
+// H.264 access units of a 640x480 stream, a keyframe with its parameter
+// sets and a predicted frame
+var (
+	h264Keyframe = []byte{
+		0, 0, 0, 1, 0x67, 0x42, 0x00, 0x1e, 0x95, 0xa8, 0x28, 0x0f, 0x64,
+		0, 0, 0, 1, 0x68, 0xce, 0x3c, 0x80,
+		0, 0, 0, 1, 0x65, 0x88, 0x84, 0x00,
+	}
+	h264Frame = []byte{0, 0, 0, 1, 0x41, 0x9a, 0x02}
+	idr       = []byte{0x65, 0x88, 0x84, 0x00}
+)
+
+// collector keeps the file a saver writes
+type collector struct {
+	elements.Leaf
+	bytes.Buffer
+}
+
+func (c *collector) Write(sample *avp.Sample) error {
+	_, err := c.Buffer.Write(sample.Payload.([]byte))
+	return err
+}
+
+// h264Segment writes a WebM of a second of 30fps H.264 with a keyframe
+// first, and 20ms Opus packets
+func h264Segment(t *testing.T) []byte {
+	saver := elements.NewWebmSaver(&elements.WebmSaverConfig{Audio: true, Video: true})
+	c := &collector{}
+	saver.Attach(c)
+	audio := 0
+	for i := 0; i < 30; i++ {
+		frame := h264Frame
+		if i == 0 {
+			frame = h264Keyframe
+		}
+		assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeH264, Timestamp: uint32(i * 3000), Payload: frame}))
+		for ; audio*3 < (i+1)*5; audio++ {
+			assert.NoError(t, saver.Write(&avp.Sample{Type: avp.TypeOpus, Timestamp: uint32(audio * 960), Payload: []byte{0xf8, 0xff, 0xfe}}))
+		}
+	}
+	saver.Close()
+	return c.Bytes()
+}
+
+// boxes returns the types of the top level boxes of an MP4 file
+func boxes(t *testing.T, b []byte) []string {
+	var types []string
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b))
+		if !assert.True(t, size >= 8 && size <= len(b), "box size") {
+			break
+		}
+		types = append(types, string(b[4:8]))
+		b = b[size:]
+	}
+	return types
+}
+
+func TestMP4(t *testing.T) {
+	files := manifesttest.Files{
+		"0.webm": h264Segment(t),
+		"1.webm": h264Segment(t),
+	}
+	m := manifest.New("rec", "sid", time.Now())
+	m.Segments = []manifest.Segment{
+		{File: manifest.File{Name: "0.webm"}, Track: "main", Seq: 0},
+		{File: manifest.File{Name: "1.webm"}, Track: "main", Seq: 1},
+	}
+
+	var out bytes.Buffer
+	var progress []float64
+	assert.NoError(t, Render(context.Background(), &out, m, files.Open, func(p float64) {
+		progress = append(progress, p)
+	}))
+	assert.Equal(t, []float64{0.5, 1}, progress)
+
+	// An initialization segment of both tracks, then fragments
+	types := boxes(t, out.Bytes())
+	if assert.True(t, len(types) > 3) {
+		assert.Equal(t, []string{"ftyp", "moov", "moof", "mdat"}, types[:4])
+	}
+	assert.True(t, bytes.Contains(out.Bytes(), []byte("avc1")))
+	assert.True(t, bytes.Contains(out.Bytes(), []byte("Opus")))
+	// Both segments' keyframes, with every frame, as length prefixed NAL
+	// units
+	assert.Equal(t, 2, bytes.Count(out.Bytes(), idr))
+	assert.Equal(t, 58, bytes.Count(out.Bytes(), []byte{0, 0, 0, 3, 0x41, 0x9a, 0x02}))
+}
+
+func TestMP4_Unsupported(t *testing.T) {
+	files := manifesttest.Files{"0.webm": h264Segment(t)}
+	m := manifest.New("rec", "sid", time.Now())
+	// Two chains of video and audio, of which MP4 holds one
+	m.Segments = []manifest.Segment{
+		{File: manifest.File{Name: "0.webm"}, Track: "a", Seq: 0},
+		{File: manifest.File{Name: "0.webm"}, Track: "b", Seq: 0},
+	}
+	err := Render(context.Background(), &bytes.Buffer{}, m, files.Open, nil)
+	assert.True(t, errors.Is(err, stitch.ErrUnsupported))
+}
+
+func TestSupported(t *testing.T) {
+	assert.True(t, Supported("out.mp4"))
+	assert.True(t, Supported("OUT.MP4"))
+	assert.False(t, Supported("out.webm"))
+}
+
+func TestAVCConfig(t *testing.T) {
+	sps, pps := h264Keyframe[4:13], h264Keyframe[17:21]
+	config := append([]byte{1, sps[1], sps[2], sps[3], 0xff, 0xe1, 0, byte(len(sps))}, sps...)
+	config = append(append(config, 1, 0, byte(len(pps))), pps...)
+	c, err := parseAVCConfig(config)
+	assert.NoError(t, err)
+	assert.Equal(t, h264Keyframe, c.annexB(append([]byte{0, 0, 0, 4}, idr...), true))
+	assert.Equal(t, h264Frame, c.annexB([]byte{0, 0, 0, 3, 0x41, 0x9a, 0x02}, false))
+
+	_, err = parseAVCConfig(config[:10])
+	assert.Equal(t, errAVCConfig, err)
+}
@@ -0,0 +1,291 @@
+// Package stitch joins the segments of a recording into a single file.
+package stitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/at-wat/ebml-go"
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/manifest"
+)
+
+// ErrUnsupported is returned for output formats that can't be stitched
+var ErrUnsupported = errors.New("stitch: unsupported format")
+
+// Stitch writes the recording described by m to w as one file. format is
+// the output container, "webm" or a file name with its extension. MP4
+// files are written by package stitch/mp4.
+// progress, if not nil, is called with the fraction of segments read.
+func Stitch(ctx context.Context, w io.WriteCloser, format string, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+	if ext := path.Ext(format); ext != "" {
+		format = ext[1:]
+	}
+	switch strings.ToLower(format) {
+	case "webm", "mkv":
+		return WebM(ctx, w, m, open, progress)
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupported, format)
+}
+
+// block is a frame with its time from the start of the recording
+type block struct {
+	track    uint64
+	time     time.Duration
+	keyframe bool
+	data     []byte
+}
+
+// trackReader yields the blocks of one manifest track in time order,
+// reading one segment at a time
+type trackReader struct {
+	segs   []manifest.Segment
+	open   manifest.OpenFunc
+	tracks map[uint64]uint64 // segment track number to output track number
+	codecs map[uint64]string
+	onLoad func()
+
+	blocks   []block
+	loaded   bool
+	last     time.Duration
+	interval time.Duration
+}
+
+// next returns the next block, or nil at the end of the track
+func (r *trackReader) next() (*block, error) {
+	for len(r.blocks) == 0 {
+		if len(r.segs) == 0 {
+			return nil, nil
+		}
+		seg := r.segs[0]
+		r.segs = r.segs[1:]
+		if err := r.load(seg); err != nil {
+			return nil, fmt.Errorf("stitch: segment %s: %w", seg.Name, err)
+		}
+		r.onLoad()
+	}
+	b := r.blocks[0]
+	return &b, nil
+}
+
+func (r *trackReader) pop() {
+	r.blocks = r.blocks[1:]
+}
+
+func readSegment(open manifest.OpenFunc, name string) (*webm.Segment, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var doc struct {
+		Header  webm.EBMLHeader `ebml:"EBML"`
+		Segment webm.Segment    `ebml:"Segment"`
+	}
+	if err := ebml.Unmarshal(f, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Segment, nil
+}
+
+// load reads the blocks of seg, shifting them to start at the segment
+// offset. Segments without an offset, or overlapping the previous one,
+// continue one frame interval after it.
+func (r *trackReader) load(seg manifest.Segment) error {
+	s, err := readSegment(r.open, seg.Name)
+	if err != nil {
+		return err
+	}
+	for _, t := range s.Tracks.TrackEntry {
+		codec, ok := r.codecs[t.TrackNumber]
+		if !ok {
+			return fmt.Errorf("unexpected track %d", t.TrackNumber)
+		}
+		if codec != t.CodecID {
+			return fmt.Errorf("track %d changes codec from %s to %s", t.TrackNumber, codec, t.CodecID)
+		}
+	}
+
+	scale := time.Duration(s.Info.TimecodeScale)
+	if scale == 0 {
+		scale = time.Millisecond
+	}
+	start := seg.Offset
+	if end := r.last + r.interval; r.loaded && start < end {
+		start = end
+	}
+	r.loaded = true
+
+	var blocks []block
+	add := func(cluster uint64, b ebml.Block) {
+		out, ok := r.tracks[b.TrackNumber]
+		if !ok {
+			return
+		}
+		t := start + time.Duration(int64(cluster)+int64(b.Timecode))*scale
+		for _, data := range b.Data {
+			blocks = append(blocks, block{track: out, time: t, keyframe: b.Keyframe, data: data})
+		}
+	}
+	for _, c := range s.Cluster {
+		for _, b := range c.SimpleBlock {
+			add(c.Timecode, b)
+		}
+		for _, g := range c.BlockGroup {
+			g.Block.Keyframe = g.ReferenceBlock == 0
+			add(c.Timecode, g.Block)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].time < blocks[j].time })
+
+	if n := len(blocks); n > 0 {
+		if n > 1 {
+			r.interval = blocks[n-1].time - blocks[n-2].time
+		}
+		r.last = blocks[n-1].time
+	}
+	r.blocks = blocks
+	return nil
+}
+
+// readTracks returns the tracks of every segment chain of m, numbered
+// from 1 in order, and the readers of their blocks. The tracks of each
+// chain are taken from its first segment, later segments must use the
+// same codecs.
+func readTracks(m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) ([]webm.TrackEntry, []*trackReader, error) {
+	var tracks []webm.TrackEntry
+	var readers []*trackReader
+	loaded := 0
+	onLoad := func() {
+		loaded++
+		if progress != nil {
+			progress(float64(loaded) / float64(len(m.Segments)))
+		}
+	}
+	for _, name := range m.Tracks() {
+		segs := m.TrackSegments(name)
+		first, err := readSegment(open, segs[0].Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("stitch: segment %s: %w", segs[0].Name, err)
+		}
+		r := &trackReader{
+			segs:   segs,
+			open:   open,
+			tracks: make(map[uint64]uint64),
+			codecs: make(map[uint64]string),
+			onLoad: onLoad,
+		}
+		for _, t := range first.Tracks.TrackEntry {
+			out := uint64(len(tracks) + 1)
+			r.tracks[t.TrackNumber] = out
+			r.codecs[t.TrackNumber] = t.CodecID
+			t.TrackNumber = out
+			t.TrackUID = out
+			if t.Name == "" {
+				t.Name = name
+			}
+			tracks = append(tracks, t)
+		}
+		readers = append(readers, r)
+	}
+	if len(tracks) == 0 {
+		return nil, nil, errors.New("stitch: no tracks")
+	}
+	return tracks, readers, nil
+}
+
+// merge calls fn with the blocks of every reader in time order
+func merge(ctx context.Context, readers []*trackReader, fn func(*block) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		var min *block
+		var from *trackReader
+		for _, r := range readers {
+			b, err := r.next()
+			if err != nil {
+				return err
+			}
+			if b != nil && (min == nil || b.time < min.time) {
+				min, from = b, r
+			}
+		}
+		if min == nil {
+			return nil
+		}
+		from.pop()
+		if err := fn(min); err != nil {
+			return err
+		}
+	}
+}
+
+// Frame is a frame of a stitched recording
+type Frame struct {
+	// Track is the number of its track, from 1 in the order of the tracks
+	Track    uint64
+	Time     time.Duration // from the start of the recording
+	Keyframe bool
+	// Data is the frame as Matroska holds it
+	Data []byte
+}
+
+// Frames reads the segments of m as WebM stitches them, for muxing into
+// other containers. tracks is called with the tracks of every segment
+// chain, then frame with the frames of all of them in time order.
+// progress, if not nil, is called with the fraction of segments read.
+func Frames(ctx context.Context, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64), tracks func([]webm.TrackEntry) error, frame func(Frame) error) error {
+	entries, readers, err := readTracks(m, open, progress)
+	if err != nil {
+		return err
+	}
+	if err := tracks(entries); err != nil {
+		return err
+	}
+	return merge(ctx, readers, func(b *block) error {
+		return frame(Frame{Track: b.track, Time: b.time, Keyframe: b.keyframe, Data: b.data})
+	})
+}
+
+// WebM remuxes the segments of every track of m into a single WebM
+// file. The tracks of each segment chain are taken from its first
+// segment, later segments must use the same codecs.
+func WebM(ctx context.Context, w io.WriteCloser, m *manifest.Manifest, open manifest.OpenFunc, progress func(float64)) error {
+	tracks, readers, err := readTracks(m, open, progress)
+	if err != nil {
+		return err
+	}
+	writers, err := webm.NewSimpleBlockWriter(w, tracks)
+	if err != nil {
+		return err
+	}
+	closeAll := func() error {
+		var err error
+		for _, bw := range writers {
+			if e := bw.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	}
+
+	if err := merge(ctx, readers, func(b *block) error {
+		_, err := writers[b.track-1].Write(b.keyframe, int64(b.time/time.Millisecond), b.data)
+		return err
+	}); err != nil {
+		closeAll() // nolint: errcheck
+		return err
+	}
+	return closeAll()
+}
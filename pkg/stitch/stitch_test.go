@@ -0,0 +1,77 @@
+package stitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/at-wat/ebml-go"
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/pion/ion-avp/pkg/manifest/manifesttest"
+	"github.com/stretchr/testify/assert"
+)
+
+// segment writes a webm with one opus track and a block every 20ms
+func segment(t *testing.T, frames int) []byte {
+	return manifesttest.Segment(t, []webm.TrackEntry{{
+		Name:        "Audio",
+		TrackNumber: 1,
+		TrackUID:    1,
+		CodecID:     "A_OPUS",
+		TrackType:   2,
+		Audio:       &webm.Audio{SamplingFrequency: 48000, Channels: 2},
+	}}, manifesttest.Every(1, frames, 20, func(i int) []byte {
+		return []byte{byte(i)}
+	}))
+}
+
+func TestWebM(t *testing.T) {
+	files := manifesttest.Files{
+		"0.webm": segment(t, 5),
+		"1.webm": segment(t, 5),
+	}
+	open := files.Open
+
+	m := manifest.New("rec", "sid", time.Now())
+	m.Segments = []manifest.Segment{
+		{File: manifest.File{Name: "0.webm"}, Track: "audio", Seq: 0},
+		// No offset, continues after the previous segment
+		{File: manifest.File{Name: "1.webm"}, Track: "audio", Seq: 1},
+	}
+
+	var out manifesttest.Buffer
+	var progress []float64
+	assert.NoError(t, Stitch(context.Background(), &out, "out.webm", m, open, func(p float64) {
+		progress = append(progress, p)
+	}))
+	assert.Equal(t, []float64{0.5, 1}, progress)
+
+	var doc struct {
+		Header  webm.EBMLHeader `ebml:"EBML"`
+		Segment webm.Segment    `ebml:"Segment"`
+	}
+	assert.NoError(t, ebml.Unmarshal(bytes.NewReader(out.Bytes()), &doc))
+	assert.Len(t, doc.Segment.Tracks.TrackEntry, 1)
+
+	var times []int64
+	var data []byte
+	for _, c := range doc.Segment.Cluster {
+		for _, b := range c.SimpleBlock {
+			times = append(times, int64(c.Timecode)+int64(b.Timecode))
+			data = append(data, b.Data[0]...)
+		}
+	}
+	assert.Equal(t, []int64{0, 20, 40, 60, 80, 100, 120, 140, 160, 180}, times)
+	assert.Equal(t, []byte{0, 1, 2, 3, 4, 0, 1, 2, 3, 4}, data)
+
+	err := Stitch(context.Background(), &out, "out.avi", m, open, nil)
+	assert.True(t, errors.Is(err, ErrUnsupported))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Stitch(ctx, &manifesttest.Buffer{}, "out.webm", m, open, nil)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/retry"
+)
+
+const defaultAzureBlockSize = 4 * 1024 * 1024
+
+const azureVersion = "2019-12-12"
+
+// AzureConfig configures Azure Blob storage.
+// SAS is a shared access signature query string with
+// create, write, delete and list permissions on the container.
+// Endpoint overrides "https://<account>.blob.core.windows.net",
+// e.g. for the Azurite emulator or sovereign clouds.
+// BlockSize is the size in bytes of each uploaded block, 4MiB by default.
+// AccountKey is the base64 storage account key, only used to sign
+// download urls.
+type AzureConfig struct {
+	Account    string `mapstructure:"account"`
+	Container  string `mapstructure:"container"`
+	Prefix     string `mapstructure:"prefix"`
+	SAS        string `mapstructure:"sas"`
+	Endpoint   string `mapstructure:"endpoint"`
+	BlockSize  int    `mapstructure:"blocksize"`
+	AccountKey string `mapstructure:"accountkey"`
+}
+
+// Azure stores objects as block blobs in an Azure storage container.
+// Blobs are streamed as a series of blocks while they are written,
+// so recordings never need to be spooled to local disk.
+type Azure struct {
+	cfg    AzureConfig
+	client *http.Client
+	retry  *retry.Retrier
+}
+
+// NewAzure creates an Azure Blob storage
+func NewAzure(c AzureConfig) (*Azure, error) {
+	if c.Container == "" || (c.Account == "" && c.Endpoint == "") {
+		return nil, errors.New("azure: account and container are required")
+	}
+	c.SAS = strings.TrimPrefix(c.SAS, "?")
+	c.Endpoint = strings.TrimSuffix(c.Endpoint, "/")
+	if c.Endpoint == "" {
+		c.Endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", c.Account)
+	}
+	if c.BlockSize <= 0 {
+		c.BlockSize = defaultAzureBlockSize
+	}
+	return &Azure{
+		cfg:    c,
+		client: &http.Client{},
+		retry:  retry.New("azure:"+c.Container, retry.Policy{}),
+	}, nil
+}
+
+func (a *Azure) blob(name string) string {
+	return strings.TrimPrefix(a.cfg.Prefix+name, "/")
+}
+
+// Key returns the name of the blob of name in the container, under the
+// prefix
+func (a *Azure) Key(name string) string {
+	return a.blob(name)
+}
+
+func (a *Azure) url(blob string, query url.Values) string {
+	u := a.cfg.Endpoint + "/" + a.cfg.Container
+	if blob != "" {
+		u += "/" + (&url.URL{Path: blob}).EscapedPath()
+	}
+	q := query.Encode()
+	if a.cfg.SAS != "" {
+		if q != "" {
+			q += "&"
+		}
+		q += a.cfg.SAS
+	}
+	if q != "" {
+		u += "?" + q
+	}
+	return u
+}
+
+func (a *Azure) do(method, u string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("x-ms-version", azureVersion)
+	return a.client.Do(req)
+}
+
+// Open returns a writer which uploads the blob block by block.
+// The blob is committed when the writer is closed.
+func (a *Azure) Open(name string) (io.WriteCloser, error) {
+	return &azureBlockWriter{
+		azure: a,
+		blob:  a.blob(name),
+	}, nil
+}
+
+// Finalize is a no-op, blobs are committed when their writer is closed
+func (a *Azure) Finalize(name string) error {
+	return nil
+}
+
+// Abort removes the blob, if committed. Blocks not committed are dropped
+// by Azure after a week.
+func (a *Azure) Abort(name string) error {
+	return deleteAny(a, name)
+}
+
+// Delete removes the blob
+func (a *Azure) Delete(name string) error {
+	return checkResponse(a.do(http.MethodDelete, a.url(a.blob(name), nil), nil, 0, nil))
+}
+
+// List returns the names of blobs starting with prefix
+func (a *Azure) List(prefix string) ([]string, error) {
+	var names []string
+	query := url.Values{
+		"restype": {"container"},
+		"comp":    {"list"},
+		"prefix":  {a.blob(prefix)},
+	}
+	for {
+		res, err := a.do(http.MethodGet, a.url("", query), nil, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, checkResponse(res, nil)
+		}
+		var result struct {
+			Blobs []struct {
+				Name string `xml:"Name"`
+			} `xml:"Blobs>Blob"`
+			NextMarker string `xml:"NextMarker"`
+		}
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range result.Blobs {
+			names = append(names, strings.TrimPrefix(b.Name, strings.TrimPrefix(a.cfg.Prefix, "/")))
+		}
+		if result.NextMarker == "" {
+			return names, nil
+		}
+		query.Set("marker", result.NextMarker)
+	}
+}
+
+// azureBlockWriter uploads a block blob as it is written
+type azureBlockWriter struct {
+	azure *Azure
+	blob  string
+	buf   []byte
+	ids   []string
+	err   error
+}
+
+func (w *azureBlockWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= w.azure.cfg.BlockSize {
+		if w.err = w.putBlock(w.buf[:w.azure.cfg.BlockSize]); w.err != nil {
+			return 0, w.err
+		}
+		w.buf = append(w.buf[:0], w.buf[w.azure.cfg.BlockSize:]...)
+	}
+	return len(p), nil
+}
+
+func (w *azureBlockWriter) putBlock(b []byte) error {
+	// Block ids must all have the same length
+	id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%010d", len(w.ids))))
+	u := w.azure.url(w.blob, url.Values{"comp": {"block"}, "blockid": {id}})
+	if err := w.azure.retry.Do(context.Background(), func() error {
+		return checkResponse(w.azure.do(http.MethodPut, u, bytes.NewReader(b), int64(len(b)), nil))
+	}); err != nil {
+		return err
+	}
+	w.ids = append(w.ids, id)
+	return nil
+}
+
+func (w *azureBlockWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) > 0 {
+		if err := w.putBlock(w.buf); err != nil {
+			return err
+		}
+		w.buf = nil
+	}
+
+	var list bytes.Buffer
+	list.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	for _, id := range w.ids {
+		list.WriteString("<Latest>" + id + "</Latest>")
+	}
+	list.WriteString("</BlockList>")
+
+	u := w.azure.url(w.blob, url.Values{"comp": {"blocklist"}})
+	return w.azure.retry.Do(context.Background(), func() error {
+		return checkResponse(w.azure.do(http.MethodPut, u, bytes.NewReader(list.Bytes()), int64(list.Len()), http.Header{
+			"Content-Type": {"application/xml"},
+		}))
+	})
+}
+
+// SignURL returns a read only service SAS url of the blob. It requires
+// the account name and key.
+func (a *Azure) SignURL(name string, expires time.Duration) (string, error) {
+	return a.signURL(name, expires, time.Now())
+}
+
+func (a *Azure) signURL(name string, expires time.Duration, now time.Time) (string, error) {
+	if a.cfg.Account == "" || a.cfg.AccountKey == "" {
+		return "", fmt.Errorf("%w: azure needs account and accountkey", ErrNotSignable)
+	}
+	key, err := base64.StdEncoding.DecodeString(a.cfg.AccountKey)
+	if err != nil {
+		return "", fmt.Errorf("azure: invalid account key: %w", err)
+	}
+	blob := a.blob(name)
+	expiry := now.UTC().Add(expires).Format("2006-01-02T15:04:05Z")
+	// The blob service SAS string to sign for versions since 2018-11-09
+	stringToSign := strings.Join([]string{
+		"r",
+		"",
+		expiry,
+		"/blob/" + a.cfg.Account + "/" + a.cfg.Container + "/" + blob,
+		"",
+		"",
+		"https",
+		azureVersion,
+		"b",
+		"",
+		"", "", "", "", "",
+	}, "\n")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+
+	query := url.Values{
+		"sv":  {azureVersion},
+		"sr":  {"b"},
+		"sp":  {"r"},
+		"se":  {expiry},
+		"spr": {"https"},
+		"sig": {base64.StdEncoding.EncodeToString(mac.Sum(nil))},
+	}
+	return a.cfg.Endpoint + "/" + a.cfg.Container + "/" + (&url.URL{Path: blob}).EscapedPath() + "?" + query.Encode(), nil
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// BlobPrefix is where deduplicated content is stored, by hash
+const BlobPrefix = ".blobs/"
+
+var refMagic = []byte("avp-ref sha256:")
+
+// DedupConfig configures content addressed deduplication
+type DedupConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinSize is the smallest object deduplicated, smaller ones are stored as is
+	MinSize int64 `mapstructure:"minsize"`
+}
+
+// Dedup stores object content once per sha256 hash. Objects are written
+// as small references to blobs below BlobPrefix, see ParseRef.
+//
+// Blobs are not reference counted, Delete only removes the reference.
+type Dedup struct {
+	Storage
+	minSize int64
+
+	mu    sync.Mutex
+	known map[string]bool
+}
+
+// NewDedup wraps s with deduplication
+func NewDedup(s Storage, c DedupConfig) *Dedup {
+	return &Dedup{
+		Storage: s,
+		minSize: c.MinSize,
+		known:   make(map[string]bool),
+	}
+}
+
+// BlobName returns the name of the blob holding content with hash sum
+func BlobName(sum string) string {
+	return BlobPrefix + sum[:2] + "/" + sum
+}
+
+// ParseRef returns the blob name referenced by data, if data is a reference
+func ParseRef(data []byte) (string, bool) {
+	if !bytes.HasPrefix(data, refMagic) {
+		return "", false
+	}
+	sum := string(bytes.TrimSpace(data[len(refMagic):]))
+	if len(sum) != sha256.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return "", false
+	}
+	return BlobName(sum), true
+}
+
+// MaxRefSize is an upper bound on the size of a reference object
+const MaxRefSize = 128
+
+// exists reports whether the blob with hash sum is stored
+func (d *Dedup) exists(sum string) (bool, error) {
+	d.mu.Lock()
+	ok := d.known[sum]
+	d.mu.Unlock()
+	if ok {
+		return true, nil
+	}
+	names, err := d.Storage.List(BlobName(sum))
+	if err != nil {
+		return false, err
+	}
+	for _, n := range names {
+		if n == BlobName(sum) {
+			d.remember(sum)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d *Dedup) remember(sum string) {
+	d.mu.Lock()
+	d.known[sum] = true
+	d.mu.Unlock()
+}
+
+// Open returns a writer hashing the object as it is spooled to disk.
+// On Close the content is stored unless an identical blob exists.
+func (d *Dedup) Open(name string) (io.WriteCloser, error) {
+	h := sha256.New()
+	w, err := newSpooledWriter(nil, func(f *os.File, size int64) error {
+		return d.store(name, f, size, h)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hashingWriter{spooledWriter: w, h: h}, nil
+}
+
+func (d *Dedup) store(name string, f *os.File, size int64, h hash.Hash) error {
+	if size < d.minSize {
+		return d.copy(name, f)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	ok, err := d.exists(sum)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		blob := BlobName(sum)
+		if err := d.copy(blob, f); err != nil {
+			return err
+		}
+		if err := d.Storage.Finalize(blob); err != nil {
+			return err
+		}
+		d.remember(sum)
+	}
+
+	w, err := d.Storage.Open(name)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(append([]byte{}, refMagic...), sum+"\n"...)); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (d *Dedup) copy(name string, r io.Reader) error {
+	w, err := d.Storage.Open(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// List returns names of objects starting with prefix, excluding blobs
+func (d *Dedup) List(prefix string) ([]string, error) {
+	names, err := d.Storage.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	out := names[:0]
+	for _, n := range names {
+		if !strings.HasPrefix(n, BlobPrefix) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+type hashingWriter struct {
+	*spooledWriter
+	h hash.Hash
+}
+
+func (w *hashingWriter) Write(p []byte) (int, error) {
+	n, err := w.spooledWriter.Write(p)
+	w.h.Write(p[:n]) // nolint: errcheck
+	return n, err
+}
@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/retry"
+)
+
+const gcsHost = "storage.googleapis.com"
+
+const gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCSConfig configures Google Cloud Storage.
+// If Token is empty, tokens are fetched from the GCE metadata server.
+// Credentials is the path of a service account JSON key, only used to
+// sign download urls.
+// ChunkSize streams objects as resumable uploads of chunks of that many
+// bytes, rounded up to a multiple of 256KiB, instead of spooling them to
+// disk before uploading.
+// Endpoint overrides "https://storage.googleapis.com", e.g. for an emulator.
+type GCSConfig struct {
+	Bucket      string `mapstructure:"bucket"`
+	Prefix      string `mapstructure:"prefix"`
+	Token       string `mapstructure:"token"`
+	Credentials string `mapstructure:"credentials"`
+	ChunkSize   int    `mapstructure:"chunksize"`
+	Endpoint    string `mapstructure:"endpoint"`
+}
+
+// gcsChunk is the multiple of the size of the chunks of resumable uploads
+const gcsChunk = 256 * 1024
+
+// GCS stores objects in a Google Cloud Storage bucket
+type GCS struct {
+	cfg    GCSConfig
+	client *http.Client
+	retry  *retry.Retrier
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewGCS creates a Google Cloud Storage storage
+func NewGCS(c GCSConfig) (*GCS, error) {
+	if c.Bucket == "" {
+		return nil, errors.New("gcs: bucket is required")
+	}
+	if c.ChunkSize > 0 {
+		c.ChunkSize = (c.ChunkSize + gcsChunk - 1) / gcsChunk * gcsChunk
+	}
+	c.Endpoint = strings.TrimSuffix(c.Endpoint, "/")
+	if c.Endpoint == "" {
+		c.Endpoint = "https://" + gcsHost
+	}
+	return &GCS{
+		cfg:    c,
+		client: &http.Client{},
+		retry:  retry.New("gcs:"+c.Bucket, retry.Policy{}),
+	}, nil
+}
+
+func (g *GCS) object(name string) string {
+	return strings.TrimPrefix(g.cfg.Prefix+name, "/")
+}
+
+// Key returns the name of the object of name in the bucket, under the
+// prefix
+func (g *GCS) Key(name string) string {
+	return g.object(name)
+}
+
+func (g *GCS) accessToken() (string, error) {
+	if g.cfg.Token != "" {
+		return g.cfg.Token, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Now().Before(g.expires) {
+		return g.token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, gcsMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	res, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&t); err != nil {
+		return "", err
+	}
+	g.token = t.AccessToken
+	// Refresh a minute early
+	g.expires = time.Now().Add(time.Duration(t.ExpiresIn-60) * time.Second)
+	return g.token, nil
+}
+
+func (g *GCS) do(req *http.Request) (*http.Response, error) {
+	token, err := g.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return g.client.Do(req)
+}
+
+// Open returns a writer which uploads the object when closed, or chunk by
+// chunk as it is written with a ChunkSize
+func (g *GCS) Open(name string) (io.WriteCloser, error) {
+	if g.cfg.ChunkSize > 0 {
+		return &gcsResumableWriter{gcs: g, name: g.object(name)}, nil
+	}
+	u := g.cfg.Endpoint + "/upload/storage/v1/b/" + url.PathEscape(g.cfg.Bucket) +
+		"/o?uploadType=media&name=" + url.QueryEscape(g.object(name))
+	return newSpooledWriter(g.retry, func(f *os.File, size int64) error {
+		req, err := http.NewRequest(http.MethodPost, u, f)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return checkResponse(g.do(req))
+	})
+}
+
+// Finalize is a no-op, objects are committed when their writer is closed
+func (g *GCS) Finalize(name string) error {
+	return nil
+}
+
+// Abort removes the object, if uploaded. Resumable uploads not completed
+// are dropped by GCS after a week.
+func (g *GCS) Abort(name string) error {
+	return deleteAny(g, name)
+}
+
+// Delete removes the object
+func (g *GCS) Delete(name string) error {
+	req, err := http.NewRequest(http.MethodDelete, g.objectURL(name), nil)
+	if err != nil {
+		return err
+	}
+	return checkResponse(g.do(req))
+}
+
+func (g *GCS) objectURL(name string) string {
+	return g.cfg.Endpoint + "/storage/v1/b/" + url.PathEscape(g.cfg.Bucket) +
+		"/o/" + url.PathEscape(g.object(name))
+}
+
+// List returns the names of objects starting with prefix
+func (g *GCS) List(prefix string) ([]string, error) {
+	var names []string
+	query := url.Values{"prefix": {g.object(prefix)}}
+	for {
+		req, err := http.NewRequest(http.MethodGet,
+			g.cfg.Endpoint+"/storage/v1/b/"+url.PathEscape(g.cfg.Bucket)+"/o?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := g.do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, checkResponse(res, nil)
+		}
+		var result struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			names = append(names, strings.TrimPrefix(item.Name, strings.TrimPrefix(g.cfg.Prefix, "/")))
+		}
+		if result.NextPageToken == "" {
+			return names, nil
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+}
+
+// gcsResumableWriter uploads an object chunk by chunk as it is written,
+// in a resumable upload session
+type gcsResumableWriter struct {
+	gcs     *GCS
+	name    string
+	session string // uri of the upload, once started
+	buf     []byte
+	offset  int64 // of buf in the object
+	err     error
+}
+
+func (w *gcsResumableWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= w.gcs.cfg.ChunkSize {
+		if w.err = w.putChunk(w.buf[:w.gcs.cfg.ChunkSize], false); w.err != nil {
+			return 0, w.err
+		}
+		w.buf = append(w.buf[:0], w.buf[w.gcs.cfg.ChunkSize:]...)
+	}
+	return len(p), nil
+}
+
+// start begins the upload session
+func (w *gcsResumableWriter) start() error {
+	u := w.gcs.cfg.Endpoint + "/upload/storage/v1/b/" + url.PathEscape(w.gcs.cfg.Bucket) +
+		"/o?uploadType=resumable&name=" + url.QueryEscape(w.name)
+	return w.gcs.retry.Do(context.Background(), func() error {
+		req, err := http.NewRequest(http.MethodPost, u, nil)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		res, err := w.gcs.do(req)
+		if err != nil || res.StatusCode != http.StatusOK {
+			return checkResponse(res, err)
+		}
+		res.Body.Close()
+		if w.session = res.Header.Get("Location"); w.session == "" {
+			return retry.Permanent(errors.New("gcs: no resumable upload session"))
+		}
+		return nil
+	})
+}
+
+// putChunk uploads b at the offset, the end of the object if last
+func (w *gcsResumableWriter) putChunk(b []byte, last bool) error {
+	if w.session == "" {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	total := "*"
+	if last {
+		total = strconv.FormatInt(w.offset+int64(len(b)), 10)
+	}
+	span := "*"
+	if len(b) > 0 {
+		span = fmt.Sprintf("%d-%d", w.offset, w.offset+int64(len(b))-1)
+	}
+	if err := w.gcs.retry.Do(context.Background(), func() error {
+		req, err := http.NewRequest(http.MethodPut, w.session, bytes.NewReader(b))
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		req.ContentLength = int64(len(b))
+		req.Header.Set("Content-Range", "bytes "+span+"/"+total)
+		res, err := w.gcs.do(req)
+		// 308 Resume Incomplete acknowledges chunks before the last
+		if err == nil && res.StatusCode == http.StatusPermanentRedirect && !last {
+			res.Body.Close()
+			return nil
+		}
+		return checkResponse(res, err)
+	}); err != nil {
+		return err
+	}
+	w.offset += int64(len(b))
+	return nil
+}
+
+func (w *gcsResumableWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	err := w.putChunk(w.buf, true)
+	w.buf = nil
+	return err
+}
+
+// maxGCSExpiry is the longest a signed url can be valid for
+const maxGCSExpiry = 7 * 24 * time.Hour
+
+type gcsCredentials struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+}
+
+func (g *GCS) signingKey() (string, *rsa.PrivateKey, error) {
+	if g.cfg.Credentials == "" {
+		return "", nil, fmt.Errorf("%w: gcs needs credentials", ErrNotSignable)
+	}
+	b, err := ioutil.ReadFile(g.cfg.Credentials)
+	if err != nil {
+		return "", nil, err
+	}
+	var c gcsCredentials
+	if err := json.Unmarshal(b, &c); err != nil {
+		return "", nil, fmt.Errorf("gcs: credentials: %w", err)
+	}
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return "", nil, errors.New("gcs: credentials: no private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", nil, fmt.Errorf("gcs: credentials: %w", err)
+	}
+	rk, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", nil, errors.New("gcs: credentials: not an rsa key")
+	}
+	return c.ClientEmail, rk, nil
+}
+
+// SignURL returns a V4 signed GET url of the object, valid for up to 7
+// days. It requires service account credentials.
+func (g *GCS) SignURL(name string, expires time.Duration) (string, error) {
+	return g.signURL(name, expires, time.Now())
+}
+
+func (g *GCS) signURL(name string, expires time.Duration, now time.Time) (string, error) {
+	if expires <= 0 || expires > maxGCSExpiry {
+		return "", fmt.Errorf("gcs: url expiry %s out of range", expires)
+	}
+	email, key, err := g.signingKey()
+	if err != nil {
+		return "", err
+	}
+	now = now.UTC()
+	datetime := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/auto/storage/goog4_request"
+	query := url.Values{
+		"X-Goog-Algorithm":     {"GOOG4-RSA-SHA256"},
+		"X-Goog-Credential":    {email + "/" + scope},
+		"X-Goog-Date":          {datetime},
+		"X-Goog-Expires":       {strconv.Itoa(int(expires / time.Second))},
+		"X-Goog-SignedHeaders": {"host"},
+	}
+	path := "/" + uriEncode(g.cfg.Bucket, true) + "/" + uriEncode(g.object(name), false)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		path,
+		canonicalQuery(query),
+		"host:" + gcsHost + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "GOOG4-RSA-SHA256\n" + datetime + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	digest := sha256.Sum256([]byte(stringToSign))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	query.Set("X-Goog-Signature", hex.EncodeToString(sig))
+	return "https://" + gcsHost + path + "?" + canonicalQuery(query), nil
+}
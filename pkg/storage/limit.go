@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// GlobalLimiter is shared by all scheduled storages, capping the
+// combined upload rate across destinations.
+var GlobalLimiter = NewLimiter(0)
+
+// Limiter is a token bucket limiting throughput to rate bytes per second,
+// with bursts of up to one second worth of data.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a limiter, a rate of 0 means unlimited
+func NewLimiter(rate int64) *Limiter {
+	return &Limiter{rate: rate, tokens: float64(rate), last: time.Now()}
+}
+
+// SetRate changes the rate, a rate of 0 means unlimited
+func (l *Limiter) SetRate(rate int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	if l.tokens > float64(rate) {
+		l.tokens = float64(rate)
+	}
+}
+
+// Wait blocks until n bytes may be sent
+func (l *Limiter) Wait(n int) {
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * float64(l.rate)
+	if l.tokens > float64(l.rate) {
+		l.tokens = float64(l.rate)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	// Callers going into debt sleep it off, later callers queue behind them
+	delay := time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// limitedReader waits on each limiter before returning data
+type limitedReader struct {
+	r        io.Reader
+	limiters []*Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for _, l := range r.limiters {
+		l.Wait(n)
+	}
+	return n, err
+}
+
+// window is a daily time range, in local time, during which uploads run.
+// The zero window is always open.
+type window struct {
+	start, end time.Duration
+}
+
+// parseWindow parses "HH:MM-HH:MM". Ranges may wrap past midnight.
+func parseWindow(s string) (window, error) {
+	if s == "" {
+		return window{}, nil
+	}
+	var h1, m1, h2, m2 int
+	if _, err := fmt.Sscanf(s, "%d:%d-%d:%d", &h1, &m1, &h2, &m2); err != nil {
+		return window{}, fmt.Errorf("invalid upload window %q: %w", s, err)
+	}
+	if h1 < 0 || h1 > 23 || h2 < 0 || h2 > 23 || m1 < 0 || m1 > 59 || m2 < 0 || m2 > 59 {
+		return window{}, fmt.Errorf("invalid upload window %q", s)
+	}
+	return window{
+		start: time.Duration(h1)*time.Hour + time.Duration(m1)*time.Minute,
+		end:   time.Duration(h2)*time.Hour + time.Duration(m2)*time.Minute,
+	}, nil
+}
+
+// until returns how long from now until the window opens, 0 if it is open
+func (w window) until(now time.Time) time.Duration {
+	if w.start == w.end {
+		return 0
+	}
+	y, m, d := now.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	t := now.Sub(midnight)
+
+	open := t >= w.start && t < w.end
+	if w.start > w.end {
+		open = t >= w.start || t < w.end
+	}
+	if open {
+		return 0
+	}
+	if t < w.start {
+		return w.start - t
+	}
+	return 24*time.Hour - t + w.start
+}
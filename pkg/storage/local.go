@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalConfig configures local filesystem storage
+type LocalConfig struct {
+	Root string `mapstructure:"root"`
+}
+
+// Local stores objects as files below a root directory.
+// Files are written in place, so they can be read while in progress.
+type Local struct {
+	root string
+}
+
+// NewLocal creates a local filesystem storage.
+// With an empty root, object names are used as file paths.
+func NewLocal(c LocalConfig) *Local {
+	return &Local{root: c.Root}
+}
+
+func (l *Local) path(name string) string {
+	return filepath.Join(l.root, filepath.FromSlash(name))
+}
+
+// Open creates or truncates the file for name, creating parent directories as needed
+func (l *Local) Open(name string) (io.WriteCloser, error) {
+	p := l.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+}
+
+// Finalize flushes the file for name to stable storage
+func (l *Local) Finalize(name string) error {
+	f, err := os.Open(l.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+// Delete removes the file for name
+func (l *Local) Delete(name string) error {
+	err := os.Remove(l.path(name))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Abort removes the file for name, if any
+func (l *Local) Abort(name string) error {
+	return deleteAny(l, name)
+}
+
+// List returns the names of all files below root starting with prefix
+func (l *Local) List(prefix string) ([]string, error) {
+	var names []string
+	err := filepath.Walk(l.path(""), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.path(""), p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Memory keeps objects in memory, for tests and short lived pipelines
+// whose output is read back in process. Objects appear once their writer
+// is closed.
+type Memory struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// NewMemory creates an empty in-memory storage
+func NewMemory() *Memory {
+	return &Memory{objects: make(map[string][]byte)}
+}
+
+// Open returns a writer buffering the object until closed
+func (m *Memory) Open(name string) (io.WriteCloser, error) {
+	return &memoryWriter{m: m, name: name}, nil
+}
+
+// Finalize checks the object was stored
+func (m *Memory) Finalize(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[name]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Abort removes the object, if stored
+func (m *Memory) Abort(name string) error {
+	return deleteAny(m, name)
+}
+
+// Delete removes the object
+func (m *Memory) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.objects, name)
+	return nil
+}
+
+// List returns the names of objects starting with prefix, sorted
+func (m *Memory) List(prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Get returns the content of the object
+func (m *Memory) Get(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.objects[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+type memoryWriter struct {
+	m    *Memory
+	name string
+	buf  bytes.Buffer
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memoryWriter) Close() error {
+	w.m.mu.Lock()
+	defer w.m.mu.Unlock()
+	w.m.objects[w.name] = w.buf.Bytes()
+	return nil
+}
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ReplicaConfig configures an additional destination for recordings
+type ReplicaConfig struct {
+	Config `mapstructure:",squash"`
+	// Name identifies the replica in status reports, defaults to its type
+	Name string `mapstructure:"name"`
+	// Required replicas must succeed for a recording to complete
+	Required bool `mapstructure:"required"`
+}
+
+// Replica is a destination of a Replicated storage
+type Replica struct {
+	Storage
+	Name     string
+	Required bool
+}
+
+// ReplicaState is the progress of one object on one replica
+type ReplicaState int
+
+// Replica states
+const (
+	ReplicaWriting ReplicaState = iota
+	ReplicaClosed
+	ReplicaDone
+	ReplicaFailed
+)
+
+func (s ReplicaState) String() string {
+	switch s {
+	case ReplicaWriting:
+		return "writing"
+	case ReplicaClosed:
+		return "closed"
+	case ReplicaDone:
+		return "done"
+	case ReplicaFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("ReplicaState(%d)", int(s))
+}
+
+// ReplicaStatus is the state of an object on a replica
+type ReplicaStatus struct {
+	Name     string
+	Required bool
+	State    ReplicaState
+	Err      error
+}
+
+// Replicated writes every object to several storages in parallel.
+// Failures on optional replicas are recorded but don't fail the write.
+type Replicated struct {
+	replicas []Replica
+
+	mu         sync.Mutex
+	status     map[string][]ReplicaStatus
+	onComplete func(name string, status []ReplicaStatus)
+}
+
+// NewReplicated creates a storage writing to replicas. The first replica
+// is used for List.
+func NewReplicated(replicas ...Replica) *Replicated {
+	return &Replicated{
+		replicas: replicas,
+		status:   make(map[string][]ReplicaStatus),
+	}
+}
+
+// OnComplete sets a handler called once an object is finalized on every
+// required replica
+func (r *Replicated) OnComplete(f func(name string, status []ReplicaStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onComplete = f
+}
+
+// Status returns the per replica state of an object that is being written
+func (r *Replicated) Status(name string) ([]ReplicaStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.status[name]
+	if !ok {
+		return nil, false
+	}
+	return append([]ReplicaStatus(nil), s...), true
+}
+
+// each runs fn for the replicas with state in parallel, returning the
+// error of the first failing required replica. Failed replicas are
+// skipped and replicas whose fn fails are marked failed.
+func (r *Replicated) each(name string, fn func(i int) error) error {
+	r.mu.Lock()
+	status := r.status[name]
+	r.mu.Unlock()
+
+	errs := make([]error, len(r.replicas))
+	var wg sync.WaitGroup
+	for i := range r.replicas {
+		if status != nil && status[i].State == ReplicaFailed {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	for i, e := range errs {
+		if e == nil {
+			continue
+		}
+		if s, ok := r.status[name]; ok {
+			s[i].State = ReplicaFailed
+			s[i].Err = e
+		}
+		if r.replicas[i].Required && err == nil {
+			err = fmt.Errorf("replica %s: %w", r.replicas[i].Name, e)
+		}
+	}
+	return err
+}
+
+func (r *Replicated) setState(name string, state ReplicaState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.status[name] {
+		if r.status[name][i].State != ReplicaFailed {
+			r.status[name][i].State = state
+		}
+	}
+}
+
+// Open opens name on every replica
+func (r *Replicated) Open(name string) (io.WriteCloser, error) {
+	status := make([]ReplicaStatus, len(r.replicas))
+	for i, rep := range r.replicas {
+		status[i] = ReplicaStatus{Name: rep.Name, Required: rep.Required}
+	}
+	r.mu.Lock()
+	r.status[name] = status
+	r.mu.Unlock()
+
+	w := &replicatedWriter{r: r, name: name, writers: make([]io.WriteCloser, len(r.replicas))}
+	err := r.each(name, func(i int) error {
+		var err error
+		w.writers[i], err = r.replicas[i].Open(name)
+		return err
+	})
+	if err != nil {
+		for _, wr := range w.writers {
+			if wr != nil {
+				wr.Close()
+			}
+		}
+		r.mu.Lock()
+		delete(r.status, name)
+		r.mu.Unlock()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Finalize finalizes name on every replica it was written to. The
+// completion handler is called if all required replicas succeeded.
+func (r *Replicated) Finalize(name string) error {
+	err := r.each(name, func(i int) error {
+		return r.replicas[i].Finalize(name)
+	})
+
+	r.mu.Lock()
+	status := r.status[name]
+	for i := range status {
+		if status[i].State != ReplicaFailed {
+			status[i].State = ReplicaDone
+		}
+		if status[i].Required && status[i].State == ReplicaFailed && err == nil {
+			err = fmt.Errorf("replica %s: %w", status[i].Name, status[i].Err)
+		}
+	}
+	delete(r.status, name)
+	handler := r.onComplete
+	r.mu.Unlock()
+
+	if err == nil && handler != nil {
+		handler(name, status)
+	}
+	return err
+}
+
+// Abort aborts name on every replica, without calling the completion
+// handler
+func (r *Replicated) Abort(name string) error {
+	r.mu.Lock()
+	delete(r.status, name)
+	r.mu.Unlock()
+	return r.each(name, func(i int) error {
+		return r.replicas[i].Abort(name)
+	})
+}
+
+// Delete removes name from every replica
+func (r *Replicated) Delete(name string) error {
+	return r.each(name, func(i int) error {
+		return r.replicas[i].Delete(name)
+	})
+}
+
+// List lists objects on the first replica
+func (r *Replicated) List(prefix string) ([]string, error) {
+	return r.replicas[0].List(prefix)
+}
+
+type replicatedWriter struct {
+	r       *Replicated
+	name    string
+	writers []io.WriteCloser
+	err     error
+}
+
+func (w *replicatedWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.err = w.r.each(w.name, func(i int) error {
+		_, err := w.writers[i].Write(p)
+		return err
+	})
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+func (w *replicatedWriter) Close() error {
+	// Release writers of replicas that failed mid-write
+	if status, ok := w.r.Status(w.name); ok {
+		for i, s := range status {
+			if s.State == ReplicaFailed && w.writers[i] != nil {
+				w.writers[i].Close()
+			}
+		}
+	}
+	err := w.r.each(w.name, func(i int) error {
+		return w.writers[i].Close()
+	})
+	w.r.setState(w.name, ReplicaClosed)
+	if w.err != nil {
+		return w.err
+	}
+	return err
+}
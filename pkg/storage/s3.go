@@ -0,0 +1,502 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/retry"
+)
+
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// maxS3Expiry is the longest a presigned url can be valid for
+const maxS3Expiry = 7 * 24 * time.Hour
+
+// MinS3PartSize is the smallest part of a multipart upload, but for the last
+const MinS3PartSize = 5 * 1024 * 1024
+
+// S3Config configures Amazon S3 or S3 compatible storage.
+// Endpoint overrides the AWS endpoint, e.g. "http://minio:9000" for MinIO or Ceph.
+// PathStyle addresses buckets as "endpoint/bucket/key" instead of "bucket.endpoint/key".
+// Insecure disables TLS certificate verification, for self-signed on-prem endpoints.
+// PartSize streams objects as multipart uploads of parts of that many bytes,
+// at least MinS3PartSize, instead of spooling them to disk before uploading.
+// Retry is the backoff of each request, zero fields using the retry defaults.
+type S3Config struct {
+	Region       string       `mapstructure:"region"`
+	Bucket       string       `mapstructure:"bucket"`
+	Prefix       string       `mapstructure:"prefix"`
+	AccessKey    string       `mapstructure:"accesskey"`
+	SecretKey    string       `mapstructure:"secretkey"`
+	SessionToken string       `mapstructure:"sessiontoken"`
+	Endpoint     string       `mapstructure:"endpoint"`
+	PathStyle    bool         `mapstructure:"pathstyle"`
+	Insecure     bool         `mapstructure:"insecure"`
+	PartSize     int          `mapstructure:"partsize"`
+	Retry        retry.Policy `mapstructure:"retry"`
+}
+
+// S3 stores objects in an S3 bucket
+type S3 struct {
+	cfg      S3Config
+	scheme   string
+	endpoint string
+	client   *http.Client
+	retry    *retry.Retrier
+}
+
+// NewS3 creates an S3 storage
+func NewS3(c S3Config) (*S3, error) {
+	if c.Bucket == "" {
+		return nil, errors.New("s3: bucket is required")
+	}
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	if c.PartSize > 0 && c.PartSize < MinS3PartSize {
+		c.PartSize = MinS3PartSize
+	}
+
+	s := &S3{
+		cfg:      c,
+		scheme:   "https",
+		endpoint: fmt.Sprintf("s3.%s.amazonaws.com", c.Region),
+		client:   &http.Client{},
+		retry:    retry.New("s3:"+c.Bucket, c.Retry),
+	}
+
+	if c.Endpoint != "" {
+		endpoint := c.Endpoint
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "https://" + endpoint
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("s3: invalid endpoint: %w", err)
+		}
+		s.scheme = u.Scheme
+		s.endpoint = u.Host
+	}
+
+	if c.Insecure {
+		s.client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // nolint: gosec
+		}
+	}
+
+	return s, nil
+}
+
+func (s *S3) key(name string) string {
+	return strings.TrimPrefix(s.cfg.Prefix+name, "/")
+}
+
+// Key returns the key of the object of name in the bucket, under the prefix
+func (s *S3) Key(name string) string {
+	return s.key(name)
+}
+
+func (s *S3) url(key string, query url.Values) *url.URL {
+	u := &url.URL{
+		Scheme:   s.scheme,
+		Host:     s.cfg.Bucket + "." + s.endpoint,
+		Path:     "/" + key,
+		RawPath:  "/" + uriEncode(key, false),
+		RawQuery: canonicalQuery(query),
+	}
+	if s.cfg.PathStyle {
+		u.Host = s.endpoint
+		u.Path = "/" + s.cfg.Bucket + u.Path
+		u.RawPath = "/" + uriEncode(s.cfg.Bucket, true) + u.RawPath
+	}
+	return u
+}
+
+func (s *S3) do(method, key string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.url(key, query).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s.sign(req, unsignedPayload, time.Now())
+	return s.client.Do(req)
+}
+
+// Open returns a writer which uploads the object when closed, or part by
+// part as it is written with a PartSize
+func (s *S3) Open(name string) (io.WriteCloser, error) {
+	key := s.key(name)
+	if s.cfg.PartSize > 0 {
+		return &s3MultipartWriter{s3: s, key: key}, nil
+	}
+	return newSpooledWriter(s.retry, func(f *os.File, size int64) error {
+		return checkResponse(s.do(http.MethodPut, key, nil, f, size))
+	})
+}
+
+// Finalize is a no-op, objects are committed when their writer is closed
+func (s *S3) Finalize(name string) error {
+	return nil
+}
+
+// Delete removes the object
+func (s *S3) Delete(name string) error {
+	return checkResponse(s.do(http.MethodDelete, s.key(name), nil, nil, 0))
+}
+
+// Abort cancels multipart uploads of the object in progress, freeing
+// their parts, and removes it if uploaded
+func (s *S3) Abort(name string) error {
+	key := s.key(name)
+	res, err := s.do(http.MethodGet, "", url.Values{"uploads": {""}, "prefix": {key}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return checkResponse(res, nil)
+	}
+	var result struct {
+		Uploads []struct {
+			Key      string `xml:"Key"`
+			UploadID string `xml:"UploadId"`
+		} `xml:"Upload"`
+	}
+	err = xml.NewDecoder(res.Body).Decode(&result)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+	for _, u := range result.Uploads {
+		if u.Key != key {
+			continue
+		}
+		err := checkResponse(s.do(http.MethodDelete, key, url.Values{"uploadId": {u.UploadID}}, nil, 0))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return deleteAny(s, name)
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the names of objects starting with prefix
+func (s *S3) List(prefix string) ([]string, error) {
+	var names []string
+	query := url.Values{
+		"list-type": {"2"},
+		"prefix":    {s.key(prefix)},
+	}
+	for {
+		res, err := s.do(http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, checkResponse(res, nil)
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range result.Contents {
+			names = append(names, strings.TrimPrefix(c.Key, strings.TrimPrefix(s.cfg.Prefix, "/")))
+		}
+		if !result.IsTruncated {
+			return names, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// s3MultipartWriter uploads an object part by part as it is written.
+// Objects smaller than a part are put in one request when closed.
+type s3MultipartWriter struct {
+	s3    *S3
+	key   string
+	id    string // of the upload, once started
+	buf   []byte
+	parts []s3Part
+	err   error
+}
+
+type s3Part struct {
+	Number int    `xml:"PartNumber"`
+	ETag   string `xml:"ETag"`
+}
+
+func (w *s3MultipartWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= w.s3.cfg.PartSize {
+		if w.err = w.putPart(w.buf[:w.s3.cfg.PartSize]); w.err != nil {
+			w.abort()
+			return 0, w.err
+		}
+		w.buf = append(w.buf[:0], w.buf[w.s3.cfg.PartSize:]...)
+	}
+	return len(p), nil
+}
+
+// start begins the multipart upload
+func (w *s3MultipartWriter) start() error {
+	return w.s3.retry.Do(context.Background(), func() error {
+		res, err := w.s3.do(http.MethodPost, w.key, url.Values{"uploads": {""}}, nil, 0)
+		if err != nil || res.StatusCode != http.StatusOK {
+			return checkResponse(res, err)
+		}
+		defer res.Body.Close()
+		var result struct {
+			UploadID string `xml:"UploadId"`
+		}
+		if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+			return err
+		}
+		w.id = result.UploadID
+		return nil
+	})
+}
+
+func (w *s3MultipartWriter) putPart(b []byte) error {
+	if w.id == "" {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	part := s3Part{Number: len(w.parts) + 1}
+	query := url.Values{"partNumber": {strconv.Itoa(part.Number)}, "uploadId": {w.id}}
+	if err := w.s3.retry.Do(context.Background(), func() error {
+		res, err := w.s3.do(http.MethodPut, w.key, query, bytes.NewReader(b), int64(len(b)))
+		if err == nil {
+			part.ETag = res.Header.Get("ETag")
+		}
+		return checkResponse(res, err)
+	}); err != nil {
+		return err
+	}
+	w.parts = append(w.parts, part)
+	return nil
+}
+
+// abort drops the parts uploaded so they aren't charged for
+func (w *s3MultipartWriter) abort() {
+	if w.id != "" {
+		checkResponse(w.s3.do(http.MethodDelete, w.key, url.Values{"uploadId": {w.id}}, nil, 0)) // nolint: errcheck
+	}
+}
+
+func (w *s3MultipartWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.id == "" {
+		return w.s3.retry.Do(context.Background(), func() error {
+			return checkResponse(w.s3.do(http.MethodPut, w.key, nil, bytes.NewReader(w.buf), int64(len(w.buf))))
+		})
+	}
+	if len(w.buf) > 0 {
+		if err := w.putPart(w.buf); err != nil {
+			w.abort()
+			return err
+		}
+		w.buf = nil
+	}
+
+	list, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: w.parts})
+	if err != nil {
+		return err
+	}
+	query := url.Values{"uploadId": {w.id}}
+	err = w.s3.retry.Do(context.Background(), func() error {
+		res, err := w.s3.do(http.MethodPost, w.key, query, bytes.NewReader(list), int64(len(list)))
+		if err != nil || res.StatusCode != http.StatusOK {
+			return checkResponse(res, err)
+		}
+		// Completing can fail after the status is sent, with an error in
+		// the body
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(body, []byte("<Error>")) {
+			return fmt.Errorf("POST %s: %s", res.Request.URL.Path, body)
+		}
+		return nil
+	})
+	if err != nil {
+		w.abort()
+	}
+	return err
+}
+
+// sign adds an AWS signature version 4 to req
+func (s *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", s.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if k == "content-type" || k == "content-md5" || strings.HasPrefix(k, "x-amz-") {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	var names []string
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(date)
+	signature := s.signature(date, amzDate, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+func (s *S3) scope(date string) string {
+	return date + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request with the key derived for date
+func (s *S3) signature(date, amzDate, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + s.scope(date) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// SignURL returns a presigned GET url of the object, valid for up to 7 days
+func (s *S3) SignURL(name string, expires time.Duration) (string, error) {
+	return s.signURL(name, expires, time.Now())
+}
+
+func (s *S3) signURL(name string, expires time.Duration, now time.Time) (string, error) {
+	if expires <= 0 || expires > maxS3Expiry {
+		return "", fmt.Errorf("s3: url expiry %s out of range", expires)
+	}
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.cfg.AccessKey + "/" + s.scope(date)},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(expires / time.Second))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	if s.cfg.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	u := s.url(s.key(name), query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		u.RawQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(date, amzDate, canonicalRequest))
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode escapes s as required by AWS signatures
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func canonicalQuery(v url.Values) string {
+	var keys []string
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), v[k]...)
+		sort.Strings(vals)
+		for _, val := range vals {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(val, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/pion/ion-log"
+)
+
+// UploadConfig limits how uploads use the network
+type UploadConfig struct {
+	// Rate limits uploads to this destination, in bytes per second
+	Rate int64 `mapstructure:"rate"`
+	// GlobalRate limits uploads to all destinations combined, in bytes per second
+	GlobalRate int64 `mapstructure:"globalrate"`
+	// Window restricts uploads to a daily local time range, e.g. "22:00-06:00"
+	Window string `mapstructure:"window"`
+	// Spool is where objects are queued until uploaded
+	Spool string `mapstructure:"spool"`
+}
+
+func (c UploadConfig) enabled() bool {
+	return c.Rate > 0 || c.GlobalRate > 0 || c.Window != ""
+}
+
+const scheduleRetry = time.Minute
+
+// Scheduled queues objects on local disk and uploads them to another
+// storage in the background, within the configured rate and window.
+//
+// Objects are queued once their writer is closed. A segment already
+// uploading when the window closes is finished.
+type Scheduled struct {
+	Storage
+	spool    *Local
+	window   window
+	limiters []*Limiter
+
+	mu     sync.Mutex
+	queue  []string
+	notify chan struct{}
+	done   chan struct{}
+}
+
+// NewScheduled wraps s with scheduled uploads. Objects left in the spool
+// by a previous run are queued again.
+func NewScheduled(s Storage, c UploadConfig) (*Scheduled, error) {
+	w, err := parseWindow(c.Window)
+	if err != nil {
+		return nil, err
+	}
+	if c.Spool == "" {
+		c.Spool = filepath.Join(os.TempDir(), "avp-spool")
+	}
+	if c.GlobalRate > 0 {
+		GlobalLimiter.SetRate(c.GlobalRate)
+	}
+
+	spool := NewLocal(LocalConfig{Root: c.Spool})
+	pending, err := spool.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &Scheduled{
+		Storage:  s,
+		spool:    spool,
+		window:   w,
+		limiters: []*Limiter{NewLimiter(c.Rate), GlobalLimiter},
+		queue:    pending,
+		notify:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go sc.run()
+	if len(pending) > 0 {
+		log.Infof("storage: resuming %d queued uploads", len(pending))
+		sc.wake()
+	}
+	return sc, nil
+}
+
+// Open returns a writer to the spool, the object is queued when it is closed
+func (s *Scheduled) Open(name string) (io.WriteCloser, error) {
+	w, err := s.spool.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &queuedWriter{WriteCloser: w, s: s, name: name}, nil
+}
+
+// Finalize is a no-op, objects are finalized once uploaded
+func (s *Scheduled) Finalize(name string) error {
+	return nil
+}
+
+// Abort drops name from the queue and the spool, and from the
+// destination should it have been uploaded
+func (s *Scheduled) Abort(name string) error {
+	s.dequeue(name)
+	if err := s.spool.Abort(name); err != nil {
+		return err
+	}
+	return s.Storage.Abort(name)
+}
+
+// Pending returns the number of objects waiting to be uploaded
+func (s *Scheduled) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// Stop ends background uploads. Queued objects stay in the spool.
+func (s *Scheduled) Stop() {
+	close(s.done)
+}
+
+func (s *Scheduled) enqueue(name string) {
+	s.mu.Lock()
+	s.queue = append(s.queue, name)
+	s.mu.Unlock()
+	s.wake()
+}
+
+// dequeue removes name from the queue
+func (s *Scheduled) dequeue(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, n := range s.queue {
+		if n == name {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Scheduled) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// sleep waits for d, returning false if stopped
+func (s *Scheduled) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+func (s *Scheduled) run() {
+	for {
+		select {
+		case <-s.notify:
+		case <-s.done:
+			return
+		}
+
+		for {
+			s.mu.Lock()
+			if len(s.queue) == 0 {
+				s.mu.Unlock()
+				break
+			}
+			name := s.queue[0]
+			s.mu.Unlock()
+
+			if d := s.window.until(time.Now()); d > 0 {
+				log.Infof("storage: upload window opens in %s", d.Round(time.Minute))
+				if !s.sleep(d) {
+					return
+				}
+			}
+
+			if err := s.upload(name); err != nil && !os.IsNotExist(err) {
+				log.Errorf("storage: upload %s: %v", name, err)
+				if !s.sleep(scheduleRetry) {
+					return
+				}
+				continue
+			}
+			// Aborted objects are gone from the spool
+			s.dequeue(name)
+		}
+	}
+}
+
+func (s *Scheduled) upload(name string) error {
+	f, err := os.Open(s.spool.path(name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := s.Storage.Open(name)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 32*1024)
+	if _, err := io.CopyBuffer(w, &limitedReader{r: f, limiters: s.limiters}, buf); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := s.Storage.Finalize(name); err != nil {
+		return err
+	}
+	return deleteAny(s.spool, name)
+}
+
+type queuedWriter struct {
+	io.WriteCloser
+	s    *Scheduled
+	name string
+}
+
+func (w *queuedWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	w.s.enqueue(w.name)
+	return nil
+}
@@ -0,0 +1,659 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/pion/ion-log"
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTPConfig configures SFTP storage
+type SFTPConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	KeyFile  string `mapstructure:"keyfile"`
+	// HostKey is the server public key in authorized_keys format
+	HostKey string `mapstructure:"hostkey"`
+	// InsecureIgnoreHostKey skips host key verification when HostKey is empty
+	InsecureIgnoreHostKey bool   `mapstructure:"insecureignorehostkey"`
+	Root                  string `mapstructure:"root"`
+	// Template lays out object names on the server, see nameTemplate
+	Template string `mapstructure:"template"`
+	// PoolSize is the maximum number of idle connections kept open
+	PoolSize int `mapstructure:"poolsize"`
+}
+
+// SFTP stores objects on an SFTP server
+type SFTP struct {
+	cfg  SFTPConfig
+	ssh  *ssh.ClientConfig
+	tmpl *nameTemplate
+	pool chan *sftpConn
+}
+
+// NewSFTP creates a SFTP storage
+func NewSFTP(c SFTPConfig) (*SFTP, error) {
+	if c.Addr == "" {
+		return nil, errors.New("sftp: addr is required")
+	}
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		c.Addr = net.JoinHostPort(c.Addr, "22")
+	}
+	if c.PoolSize <= 0 {
+		c.PoolSize = 4
+	}
+
+	var auth []ssh.AuthMethod
+	if c.KeyFile != "" {
+		pem, err := ioutil.ReadFile(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(pem)
+		if err != nil {
+			return nil, fmt.Errorf("sftp: parsing key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if c.Password != "" {
+		auth = append(auth, ssh.Password(c.Password))
+	}
+
+	var hostKey ssh.HostKeyCallback
+	switch {
+	case c.HostKey != "":
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(c.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("sftp: parsing host key: %w", err)
+		}
+		hostKey = ssh.FixedHostKey(key)
+	case c.InsecureIgnoreHostKey:
+		log.Warnf("sftp: host key verification disabled for %s", c.Addr)
+		hostKey = ssh.InsecureIgnoreHostKey() // nolint: gosec
+	default:
+		return nil, errors.New("sftp: hostkey is required")
+	}
+
+	tmpl, err := newNameTemplate(c.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SFTP{
+		cfg: c,
+		ssh: &ssh.ClientConfig{
+			User:            c.Username,
+			Auth:            auth,
+			HostKeyCallback: hostKey,
+			Timeout:         10 * time.Second,
+		},
+		tmpl: tmpl,
+		pool: make(chan *sftpConn, c.PoolSize),
+	}, nil
+}
+
+// get takes an idle connection from the pool or dials a new one
+func (s *SFTP) get() (*sftpConn, error) {
+	select {
+	case c := <-s.pool:
+		return c, nil
+	default:
+		return dialSFTP(s.cfg.Addr, s.ssh)
+	}
+}
+
+// put returns a connection to the pool. Broken connections, and those
+// that don't fit, are closed.
+func (s *SFTP) put(c *sftpConn, err error) {
+	if err != nil && !isStatus(err) {
+		c.close()
+		return
+	}
+	select {
+	case s.pool <- c:
+	default:
+		c.close()
+	}
+}
+
+func (s *SFTP) path(name string) string {
+	return path.Join("/", s.cfg.Root, name)
+}
+
+// Open returns a writer streaming to the templated path of name
+func (s *SFTP) Open(name string) (io.WriteCloser, error) {
+	name, err := s.tmpl.expand(name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	p := s.path(name)
+	if err = c.mkdirAll(path.Dir(p)); err != nil {
+		s.put(c, err)
+		return nil, err
+	}
+	h, err := c.open(p, sftpFlagWrite|sftpFlagCreate|sftpFlagTrunc)
+	if err != nil {
+		s.put(c, err)
+		return nil, err
+	}
+	return &sftpWriter{s: s, c: c, handle: h}, nil
+}
+
+// Finalize is a no-op, objects are committed when their writer is closed
+func (s *SFTP) Finalize(name string) error {
+	return nil
+}
+
+// Abort removes the object at today's templated path of name, if any
+func (s *SFTP) Abort(name string) error {
+	p, err := s.tmpl.expand(name, time.Now())
+	if err != nil {
+		return err
+	}
+	return deleteAny(s, p)
+}
+
+// Delete removes the object. name is a path as returned by List.
+func (s *SFTP) Delete(name string) error {
+	c, err := s.get()
+	if err != nil {
+		return err
+	}
+	err = c.remove(s.path(name))
+	s.put(c, err)
+	return err
+}
+
+// List returns the names of objects starting with prefix.
+// Directories are walked recursively.
+func (s *SFTP) List(prefix string) ([]string, error) {
+	c, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	root := s.path("")
+	var names []string
+	err = c.walk(path.Dir(s.path(prefix)), func(p string) {
+		name := strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	})
+	s.put(c, err)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return names, err
+}
+
+type sftpWriter struct {
+	s      *SFTP
+	c      *sftpConn
+	handle string
+	offset uint64
+	err    error
+}
+
+func (w *sftpWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > sftpMaxWrite {
+			chunk = chunk[:sftpMaxWrite]
+		}
+		if w.err = w.c.write(w.handle, w.offset, chunk); w.err != nil {
+			return n, w.err
+		}
+		w.offset += uint64(len(chunk))
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return n, nil
+}
+
+func (w *sftpWriter) Close() error {
+	if w.c == nil {
+		return w.err
+	}
+	err := w.c.closeHandle(w.handle)
+	w.s.put(w.c, err)
+	w.c = nil
+	if w.err != nil {
+		return w.err
+	}
+	w.err = err
+	return err
+}
+
+// Minimal SFTP version 3 client, see draft-ietf-secsh-filexfer-02.
+
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpOpen     = 3
+	sftpClose    = 4
+	sftpWrite    = 6
+	sftpOpenDir  = 11
+	sftpReadDir  = 12
+	sftpRemove   = 13
+	sftpMkdir    = 14
+	sftpStat     = 17
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpName     = 104
+	sftpAttrs    = 105
+	sftpMaxWrite = 32 * 1024
+
+	sftpFlagWrite  = 0x02
+	sftpFlagCreate = 0x08
+	sftpFlagTrunc  = 0x10
+
+	sftpOK         = 0
+	sftpEOF        = 1
+	sftpNoSuchFile = 2
+
+	sftpAttrSize        = 0x01
+	sftpAttrUIDGID      = 0x02
+	sftpAttrPermissions = 0x04
+	sftpAttrACModTime   = 0x08
+	sftpAttrExtended    = 0x80000000
+
+	sftpModeType = 0170000
+	sftpModeDir  = 0040000
+)
+
+// sftpStatusError is a non-OK status returned by the server
+type sftpStatusError struct {
+	code uint32
+	msg  string
+}
+
+func (e *sftpStatusError) Error() string {
+	return fmt.Sprintf("sftp: status %d: %s", e.code, e.msg)
+}
+
+func (e *sftpStatusError) Is(target error) bool {
+	return target == ErrNotFound && e.code == sftpNoSuchFile
+}
+
+// isStatus reports whether err came from the server, in which case the
+// connection is still usable
+func isStatus(err error) bool {
+	var s *sftpStatusError
+	return errors.As(err, &s)
+}
+
+type sftpConn struct {
+	mu      sync.Mutex
+	client  *ssh.Client
+	session *ssh.Session
+	w       io.WriteCloser
+	r       *bufio.Reader
+	id      uint32
+}
+
+func dialSFTP(addr string, config *ssh.ClientConfig) (*sftpConn, error) {
+	client, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	c := &sftpConn{client: client}
+	if err = c.start(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *sftpConn) start() error {
+	var err error
+	if c.session, err = c.client.NewSession(); err != nil {
+		return err
+	}
+	if c.w, err = c.session.StdinPipe(); err != nil {
+		return err
+	}
+	r, err := c.session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	c.r = bufio.NewReader(r)
+	if err = c.session.RequestSubsystem("sftp"); err != nil {
+		return err
+	}
+
+	var b sftpBuf
+	b.u32(3)
+	if err = c.send(sftpInit, b); err != nil {
+		return err
+	}
+	typ, _, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if typ != sftpVersion {
+		return fmt.Errorf("sftp: unexpected packet %d during init", typ)
+	}
+	return nil
+}
+
+func (c *sftpConn) close() {
+	c.session.Close()
+	c.client.Close()
+}
+
+func (c *sftpConn) send(typ byte, b sftpBuf) error {
+	hdr := make([]byte, 5)
+	binary.BigEndian.PutUint32(hdr, uint32(len(b)+1))
+	hdr[4] = typ
+	if _, err := c.w.Write(hdr); err != nil {
+		return err
+	}
+	_, err := c.w.Write(b)
+	return err
+}
+
+func (c *sftpConn) recv() (byte, sftpBuf, error) {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(c.r, hdr); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr)
+	if n < 1 || n > 256*1024 {
+		return 0, nil, fmt.Errorf("sftp: bad packet length %d", n)
+	}
+	b := make(sftpBuf, n-1)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		return 0, nil, err
+	}
+	return hdr[4], b, nil
+}
+
+// request sends a packet with a fresh id and waits for its reply.
+// STATUS replies other than OK are returned as errors.
+func (c *sftpConn) request(typ byte, args sftpBuf) (byte, sftpBuf, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.id++
+	var b sftpBuf
+	b.u32(c.id)
+	b = append(b, args...)
+	if err := c.send(typ, b); err != nil {
+		return 0, nil, err
+	}
+	rtyp, res, err := c.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	id, err := res.getU32()
+	if err != nil {
+		return 0, nil, err
+	}
+	if id != c.id {
+		return 0, nil, fmt.Errorf("sftp: reply id %d, want %d", id, c.id)
+	}
+	if rtyp == sftpStatus {
+		code, _ := res.getU32()
+		if code == sftpOK {
+			return rtyp, res, nil
+		}
+		msg, _ := res.getString()
+		return rtyp, res, &sftpStatusError{code: code, msg: msg}
+	}
+	return rtyp, res, nil
+}
+
+func (c *sftpConn) handleRequest(typ byte, args sftpBuf) (string, error) {
+	rtyp, res, err := c.request(typ, args)
+	if err != nil {
+		return "", err
+	}
+	if rtyp != sftpHandle {
+		return "", fmt.Errorf("sftp: unexpected packet %d, want handle", rtyp)
+	}
+	return res.getString()
+}
+
+func (c *sftpConn) open(p string, flags uint32) (string, error) {
+	var b sftpBuf
+	b.str(p)
+	b.u32(flags)
+	b.u32(0) // no attributes
+	return c.handleRequest(sftpOpen, b)
+}
+
+func (c *sftpConn) write(handle string, offset uint64, data []byte) error {
+	var b sftpBuf
+	b.str(handle)
+	b.u64(offset)
+	b.str(string(data))
+	_, _, err := c.request(sftpWrite, b)
+	return err
+}
+
+func (c *sftpConn) closeHandle(handle string) error {
+	var b sftpBuf
+	b.str(handle)
+	_, _, err := c.request(sftpClose, b)
+	return err
+}
+
+func (c *sftpConn) remove(p string) error {
+	var b sftpBuf
+	b.str(p)
+	_, _, err := c.request(sftpRemove, b)
+	return err
+}
+
+// isDir stats p, returning ErrNotFound if it doesn't exist
+func (c *sftpConn) isDir(p string) (bool, error) {
+	var b sftpBuf
+	b.str(p)
+	rtyp, res, err := c.request(sftpStat, b)
+	if err != nil {
+		return false, err
+	}
+	if rtyp != sftpAttrs {
+		return false, fmt.Errorf("sftp: unexpected packet %d, want attrs", rtyp)
+	}
+	mode, err := res.getAttrs()
+	return mode&sftpModeType == sftpModeDir, err
+}
+
+// mkdirAll creates dir and any missing parents
+func (c *sftpConn) mkdirAll(dir string) error {
+	if dir == "/" || dir == "." {
+		return nil
+	}
+	ok, err := c.isDir(dir)
+	if err == nil {
+		if !ok {
+			return fmt.Errorf("sftp: %s is not a directory", dir)
+		}
+		return nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := c.mkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+	var b sftpBuf
+	b.str(dir)
+	b.u32(0)
+	_, _, err = c.request(sftpMkdir, b)
+	return err
+}
+
+// walk calls fn with the path of every file below dir
+func (c *sftpConn) walk(dir string, fn func(p string)) error {
+	var b sftpBuf
+	b.str(dir)
+	handle, err := c.handleRequest(sftpOpenDir, b)
+	if err != nil {
+		return err
+	}
+
+	var dirs []string
+	for {
+		var b sftpBuf
+		b.str(handle)
+		rtyp, res, err := c.request(sftpReadDir, b)
+		if err != nil {
+			var s *sftpStatusError
+			if errors.As(err, &s) && s.code == sftpEOF {
+				break
+			}
+			c.closeHandle(handle) // nolint: errcheck
+			return err
+		}
+		if rtyp != sftpName {
+			c.closeHandle(handle) // nolint: errcheck
+			return fmt.Errorf("sftp: unexpected packet %d, want name", rtyp)
+		}
+		count, err := res.getU32()
+		if err != nil {
+			return err
+		}
+		for i := uint32(0); i < count; i++ {
+			name, err := res.getString()
+			if err != nil {
+				return err
+			}
+			if _, err = res.getString(); err != nil { // long name
+				return err
+			}
+			mode, err := res.getAttrs()
+			if err != nil {
+				return err
+			}
+			if name == "." || name == ".." {
+				continue
+			}
+			p := path.Join(dir, name)
+			if mode&sftpModeType == sftpModeDir {
+				dirs = append(dirs, p)
+			} else {
+				fn(p)
+			}
+		}
+	}
+	if err := c.closeHandle(handle); err != nil {
+		return err
+	}
+
+	for _, d := range dirs {
+		if err := c.walk(d, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sftpBuf encodes and decodes SFTP packet fields
+type sftpBuf []byte
+
+var errShortPacket = errors.New("sftp: short packet")
+
+func (b *sftpBuf) u32(v uint32) {
+	*b = append(*b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func (b *sftpBuf) u64(v uint64) {
+	b.u32(uint32(v >> 32))
+	b.u32(uint32(v))
+}
+
+func (b *sftpBuf) str(s string) {
+	b.u32(uint32(len(s)))
+	*b = append(*b, s...)
+}
+
+func (b *sftpBuf) getU32() (uint32, error) {
+	if len(*b) < 4 {
+		return 0, errShortPacket
+	}
+	v := binary.BigEndian.Uint32(*b)
+	*b = (*b)[4:]
+	return v, nil
+}
+
+func (b *sftpBuf) getString() (string, error) {
+	n, err := b.getU32()
+	if err != nil {
+		return "", err
+	}
+	if uint32(len(*b)) < n {
+		return "", errShortPacket
+	}
+	s := string((*b)[:n])
+	*b = (*b)[n:]
+	return s, nil
+}
+
+// getAttrs decodes an ATTRS structure, returning the permissions
+func (b *sftpBuf) getAttrs() (uint32, error) {
+	flags, err := b.getU32()
+	if err != nil {
+		return 0, err
+	}
+	skip := func(n int) error {
+		if len(*b) < n {
+			return errShortPacket
+		}
+		*b = (*b)[n:]
+		return nil
+	}
+	if flags&sftpAttrSize != 0 {
+		if err := skip(8); err != nil {
+			return 0, err
+		}
+	}
+	if flags&sftpAttrUIDGID != 0 {
+		if err := skip(8); err != nil {
+			return 0, err
+		}
+	}
+	var mode uint32
+	if flags&sftpAttrPermissions != 0 {
+		if mode, err = b.getU32(); err != nil {
+			return 0, err
+		}
+	}
+	if flags&sftpAttrACModTime != 0 {
+		if err := skip(8); err != nil {
+			return 0, err
+		}
+	}
+	if flags&sftpAttrExtended != 0 {
+		count, err := b.getU32()
+		if err != nil {
+			return 0, err
+		}
+		for i := uint32(0); i < count*2; i++ {
+			if _, err := b.getString(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return mode, nil
+}
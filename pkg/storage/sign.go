@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotSignable is returned for storage that can't sign download urls
+var ErrNotSignable = errors.New("storage: cannot sign urls")
+
+// Signer is implemented by storage that can create time limited download
+// urls, so objects can be fetched without sharing the storage credentials
+type Signer interface {
+	SignURL(name string, expires time.Duration) (string, error)
+}
+
+// SignURL returns a download url of the object name valid for expires,
+// signed by s or the backend it uploads to. Replicated storage signs with
+// its first replica. The urls of scheduled uploads are only valid once
+// the upload is done.
+func SignURL(s Storage, name string, expires time.Duration) (string, error) {
+	switch t := s.(type) {
+	case Signer:
+		return t.SignURL(name, expires)
+	case *Replicated:
+		return SignURL(t.replicas[0].Storage, name, expires)
+	case *Scheduled:
+		return SignURL(t.Storage, name, expires)
+	}
+	return "", ErrNotSignable
+}
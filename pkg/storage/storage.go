@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when an object does not exist
+	ErrNotFound = errors.New("object not found")
+	// ErrUnknownBackend is returned when the configured storage type is not known
+	ErrUnknownBackend = errors.New("unknown storage backend")
+)
+
+// Storage is a destination for recordings.
+//
+// Objects are written through the io.WriteCloser returned by Open. Data is
+// only guaranteed to be stored once Close returns without error. Finalize is
+// called after Close, once the recording is complete, so backends can
+// commit any state they keep for the object. Abort is called instead when
+// a recording fails, whether or not its writer was closed, to discard what
+// was stored of it; objects that don't exist aren't an error.
+type Storage interface {
+	Open(name string) (io.WriteCloser, error)
+	Finalize(name string) error
+	Abort(name string) error
+	Delete(name string) error
+	List(prefix string) ([]string, error)
+}
+
+// Config selects and configures a storage backend.
+type Config struct {
+	Type   string       `mapstructure:"type"`
+	Local  LocalConfig  `mapstructure:"local"`
+	S3     S3Config     `mapstructure:"s3"`
+	GCS    GCSConfig    `mapstructure:"gcs"`
+	Azure  AzureConfig  `mapstructure:"azure"`
+	WebDAV WebDAVConfig `mapstructure:"webdav"`
+	SFTP   SFTPConfig   `mapstructure:"sftp"`
+	Upload UploadConfig `mapstructure:"upload"`
+	Dedup  DedupConfig  `mapstructure:"dedup"`
+	// URLExpiry is how long signed download urls of completed recordings
+	// are valid for, none are signed if zero
+	URLExpiry time.Duration `mapstructure:"urlexpiry"`
+	// Replicas are written in parallel with this backend
+	Replicas []ReplicaConfig `mapstructure:"replicas"`
+}
+
+// New creates the storage backend selected by c.Type.
+// An empty type selects local storage. Content is deduplicated if
+// c.Dedup is enabled, and uploads are scheduled
+// when c.Upload sets a rate or window. With replicas, a Replicated
+// storage is returned and the backend itself is a required replica.
+func New(c Config) (Storage, error) {
+	s, err := newBackend(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Dedup.Enabled {
+		s = NewDedup(s, c.Dedup)
+	}
+	if c.Upload.enabled() {
+		if s, err = NewScheduled(s, c.Upload); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.Replicas) == 0 {
+		return s, nil
+	}
+
+	replicas := []Replica{{Storage: s, Name: typeName(c.Type), Required: true}}
+	for _, rc := range c.Replicas {
+		rs, err := New(rc.Config)
+		if err != nil {
+			return nil, err
+		}
+		name := rc.Name
+		if name == "" {
+			name = typeName(rc.Type)
+		}
+		replicas = append(replicas, Replica{Storage: rs, Name: name, Required: rc.Required})
+	}
+	return NewReplicated(replicas...), nil
+}
+
+// deleteAny deletes name, ignoring objects that don't exist
+func deleteAny(s Storage, name string) error {
+	if err := s.Delete(name); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func typeName(t string) string {
+	if t == "" {
+		return "local"
+	}
+	return t
+}
+
+func newBackend(c Config) (Storage, error) {
+	switch c.Type {
+	case "", "local":
+		return NewLocal(c.Local), nil
+	case "memory":
+		return NewMemory(), nil
+	case "s3":
+		return NewS3(c.S3)
+	case "gcs":
+		return NewGCS(c.GCS)
+	case "azure":
+		return NewAzure(c.Azure)
+	case "webdav":
+		return NewWebDAV(c.WebDAV)
+	case "sftp":
+		return NewSFTP(c.SFTP)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, c.Type)
+}
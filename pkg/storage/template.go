@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// nameTemplate maps object names to paths on the remote side, e.g.
+// "{{.Date}}/{{.Dir}}/{{.Base}}" stores "sid/track.webm" as
+// "2021-02-01/sid/track.webm". An empty template keeps names unchanged.
+//
+// Available fields: Name, Dir, Base, Ext, Date (2006-01-02), Year, Month, Day.
+type nameTemplate struct {
+	tmpl *template.Template
+}
+
+func newNameTemplate(text string) (*nameTemplate, error) {
+	if text == "" {
+		return &nameTemplate{}, nil
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &nameTemplate{tmpl: tmpl}, nil
+}
+
+func (t *nameTemplate) expand(name string, now time.Time) (string, error) {
+	if t.tmpl == nil {
+		return name, nil
+	}
+	dir, base := path.Split(name)
+	var b strings.Builder
+	err := t.tmpl.Execute(&b, map[string]string{
+		"Name":  name,
+		"Dir":   strings.TrimSuffix(dir, "/"),
+		"Base":  base,
+		"Ext":   path.Ext(base),
+		"Date":  now.Format("2006-01-02"),
+		"Year":  now.Format("2006"),
+		"Month": now.Format("01"),
+		"Day":   now.Format("02"),
+	})
+	if err != nil {
+		return "", err
+	}
+	return path.Clean(strings.TrimPrefix(b.String(), "/")), nil
+}
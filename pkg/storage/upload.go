@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/pion/ion-avp/pkg/retry"
+)
+
+// spooledWriter buffers an object in a temporary file
+// and hands it to upload when closed, retrying with r.
+type spooledWriter struct {
+	*os.File
+	retry  *retry.Retrier
+	upload func(f *os.File, size int64) error
+}
+
+func newSpooledWriter(r *retry.Retrier, upload func(f *os.File, size int64) error) (*spooledWriter, error) {
+	f, err := ioutil.TempFile("", "avp-upload-")
+	if err != nil {
+		return nil, err
+	}
+	return &spooledWriter{File: f, retry: r, upload: upload}, nil
+}
+
+func (w *spooledWriter) Close() error {
+	defer func() {
+		w.File.Close()
+		os.Remove(w.File.Name())
+	}()
+
+	size, err := w.File.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	return w.retry.Do(context.Background(), func() error {
+		if _, err := w.File.Seek(0, io.SeekStart); err != nil {
+			return retry.Permanent(err)
+		}
+		return w.upload(w.File, size)
+	})
+}
+
+// checkResponse turns unexpected http responses into errors. Rejected
+// requests are marked permanent so they aren't retried.
+func checkResponse(res *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return retry.Permanent(ErrNotFound)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return retry.Status(res.StatusCode,
+			fmt.Errorf("%s %s: %s %s", res.Request.Method, res.Request.URL.Path, res.Status, body))
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/retry"
+)
+
+// WebDAVConfig configures WebDAV storage
+type WebDAVConfig struct {
+	URL      string `mapstructure:"url"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Template lays out object names on the server, see nameTemplate
+	Template string `mapstructure:"template"`
+	// PoolSize is the maximum number of idle connections kept open
+	PoolSize int `mapstructure:"poolsize"`
+}
+
+// WebDAV stores objects on a WebDAV server
+type WebDAV struct {
+	cfg    WebDAVConfig
+	base   *url.URL
+	client *http.Client
+	tmpl   *nameTemplate
+	retry  *retry.Retrier
+}
+
+// NewWebDAV creates a WebDAV storage
+func NewWebDAV(c WebDAVConfig) (*WebDAV, error) {
+	if c.URL == "" {
+		return nil, errors.New("webdav: url is required")
+	}
+	base, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	tmpl, err := newNameTemplate(c.Template)
+	if err != nil {
+		return nil, err
+	}
+	if c.PoolSize <= 0 {
+		c.PoolSize = 4
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = c.PoolSize
+	return &WebDAV{
+		cfg:    c,
+		base:   base,
+		client: &http.Client{Transport: transport},
+		tmpl:   tmpl,
+		retry:  retry.New("webdav:"+base.Host, retry.Policy{}),
+	}, nil
+}
+
+func (d *WebDAV) url(name string) string {
+	u := *d.base
+	u.Path = path.Join(u.Path, "/", name)
+	return u.String()
+}
+
+func (d *WebDAV) do(method, u string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if d.cfg.Username != "" {
+		req.SetBasicAuth(d.cfg.Username, d.cfg.Password)
+	}
+	return d.client.Do(req)
+}
+
+// mkdirAll creates the collections leading up to name
+func (d *WebDAV) mkdirAll(name string) error {
+	dir := ""
+	parts := strings.Split(path.Dir(path.Clean("/"+name)), "/")
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		dir += "/" + p
+		res, err := d.do("MKCOL", d.url(dir)+"/", nil, 0, nil)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		// 405 means the collection already exists
+		if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusMethodNotAllowed {
+			return checkResponse(res, nil)
+		}
+	}
+	return nil
+}
+
+// Open returns a writer which uploads the object to the templated path
+// of name when closed
+func (d *WebDAV) Open(name string) (io.WriteCloser, error) {
+	name, err := d.tmpl.expand(name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return newSpooledWriter(d.retry, func(f *os.File, size int64) error {
+		if err := d.mkdirAll(name); err != nil {
+			return err
+		}
+		return checkResponse(d.do(http.MethodPut, d.url(name), f, size, nil))
+	})
+}
+
+// Finalize is a no-op, objects are committed when their writer is closed
+func (d *WebDAV) Finalize(name string) error {
+	return nil
+}
+
+// Abort removes the object at today's templated path of name, if any
+func (d *WebDAV) Abort(name string) error {
+	p, err := d.tmpl.expand(name, time.Now())
+	if err != nil {
+		return err
+	}
+	return deleteAny(d, p)
+}
+
+// Delete removes the object
+func (d *WebDAV) Delete(name string) error {
+	return checkResponse(d.do(http.MethodDelete, d.url(name), nil, 0, nil))
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`
+
+// List returns the names of objects starting with prefix.
+// Collections are walked recursively.
+func (d *WebDAV) List(prefix string) ([]string, error) {
+	var names []string
+	err := d.walk(path.Dir(path.Clean("/"+prefix)), func(name string) {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	})
+	return names, err
+}
+
+func (d *WebDAV) walk(dir string, fn func(name string)) error {
+	res, err := d.do("PROPFIND", d.url(dir)+"/", strings.NewReader(propfindBody), int64(len(propfindBody)), http.Header{
+		"Depth":        {"1"},
+		"Content-Type": {"application/xml"},
+	})
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusMultiStatus {
+		return checkResponse(res, nil)
+	}
+	var result struct {
+		Responses []struct {
+			Href       string    `xml:"href"`
+			Collection *struct{} `xml:"propstat>prop>resourcetype>collection"`
+		} `xml:"response"`
+	}
+	err = xml.NewDecoder(res.Body).Decode(&result)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	root := strings.TrimSuffix(d.base.Path, "/")
+	for _, r := range result.Responses {
+		href, err := url.PathUnescape(r.Href)
+		if err != nil {
+			return err
+		}
+		if u, err := url.Parse(href); err == nil && u.Path != "" {
+			href = u.Path
+		}
+		name := strings.TrimPrefix(strings.TrimSuffix(href, "/"), root)
+		if name == strings.TrimSuffix(dir, "/") || name == "" {
+			continue
+		}
+		if r.Collection != nil {
+			if err := d.walk(name, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		fn(strings.TrimPrefix(name, "/"))
+	}
+	return nil
+}
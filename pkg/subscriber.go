@@ -1,6 +1,8 @@
 package avp
 
 import (
+	"encoding/json"
+	"errors"
 	"sync"
 
 	log "github.com/pion/ion-log"
@@ -13,8 +15,14 @@ type Subscriber struct {
 	candidatesLock sync.Mutex
 
 	onTrackFn func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver)
+
+	mu         sync.RWMutex
+	dc         *webrtc.DataChannel
+	onLevelsFn func(levels []string)
 }
 
+var errNoDataChannel = errors.New("sfu data channel isn't open")
+
 // NewSubscriber creates a new Subscriber
 func NewSubscriber(cfg WebRTCTransportConfig) (*Subscriber, error) {
 	me := webrtc.MediaEngine{}
@@ -23,6 +31,30 @@ func NewSubscriber(cfg WebRTCTransportConfig) (*Subscriber, error) {
 		log.Errorf("NewSubscriber error: %v", err)
 		return nil, errPeerConnectionInitFailed
 	}
+	// AV1 isn't a default codec of pion yet
+	if err := me.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:     MimeTypeAV1,
+			ClockRate:    90000,
+			RTCPFeedback: []webrtc.RTCPFeedback{{Type: "goog-remb"}, {Type: "ccm", Parameter: "fir"}, {Type: "nack"}, {Type: "nack", Parameter: "pli"}},
+		},
+		PayloadType: 45,
+	}, webrtc.RTPCodecTypeVideo); err != nil {
+		log.Errorf("NewSubscriber error: %v", err)
+		return nil, errPeerConnectionInitFailed
+	}
+	for _, typ := range []webrtc.RTPCodecType{webrtc.RTPCodecTypeAudio, webrtc.RTPCodecTypeVideo} {
+		for _, uri := range []string{AbsCaptureTimeURI, DoNotRecordURI} {
+			if err := me.RegisterHeaderExtension(webrtc.RTPHeaderExtensionCapability{URI: uri}, typ); err != nil {
+				log.Errorf("NewSubscriber error: %v", err)
+				return nil, errPeerConnectionInitFailed
+			}
+		}
+	}
+	if err := me.RegisterHeaderExtension(webrtc.RTPHeaderExtensionCapability{URI: AudioLevelURI}, webrtc.RTPCodecTypeAudio); err != nil {
+		log.Errorf("NewSubscriber error: %v", err)
+		return nil, errPeerConnectionInitFailed
+	}
 	api := webrtc.NewAPI(webrtc.WithMediaEngine(&me), webrtc.WithSettingEngine(cfg.setting))
 	pc, err := api.NewPeerConnection(cfg.configuration)
 
@@ -41,6 +73,32 @@ func NewSubscriber(cfg WebRTCTransportConfig) (*Subscriber, error) {
 		}
 	})
 
+	pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+		if dc.Label() != sfuDataChannel {
+			return
+		}
+		dc.OnOpen(func() {
+			s.mu.Lock()
+			s.dc = dc
+			s.mu.Unlock()
+		})
+		dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+			// The SFU sends the stream ids of the audio levels it
+			// observes, loudest first
+			var levels []string
+			if err := json.Unmarshal(msg.Data, &levels); err != nil {
+				log.Debugf("sfu data channel message: %v", err)
+				return
+			}
+			s.mu.RLock()
+			f := s.onLevelsFn
+			s.mu.RUnlock()
+			if f != nil {
+				f(levels)
+			}
+		})
+	})
+
 	return s, nil
 }
 
@@ -48,6 +106,29 @@ func (s *Subscriber) OnTrack(f func(track *webrtc.TrackRemote, receiver *webrtc.
 	s.onTrackFn = f
 }
 
+// OnAudioLevels sets a handler called with the stream ids of the session
+// in order of audio level, loudest first, when the order changes
+func (s *Subscriber) OnAudioLevels(f func(levels []string)) {
+	s.mu.Lock()
+	s.onLevelsFn = f
+	s.mu.Unlock()
+}
+
+// SendFeedback asks the SFU to forward a stream at other layers
+func (s *Subscriber) SendFeedback(f SFUFeedback) error {
+	s.mu.RLock()
+	dc := s.dc
+	s.mu.RUnlock()
+	if dc == nil {
+		return errNoDataChannel
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return dc.SendText(string(b))
+}
+
 // Close the webrtc transport
 func (s *Subscriber) Close() error {
 	return s.pc.Close()
@@ -0,0 +1,110 @@
+package timeline
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/manifest"
+)
+
+// timecode formats a duration as non drop frame HH:MM:SS:FF
+func timecode(d time.Duration, rate float64) string {
+	fps := int64(rate + 0.5)
+	f := frames(d, rate)
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", f/(3600*fps), f/(60*fps)%60, f/fps%60, f%fps)
+}
+
+// cuts returns which track is on screen when: screen shares while they
+// run, otherwise the current speaker. Adjacent spans of a track are merged.
+func cuts(ev *Events, until time.Duration) []Interval {
+	speakers := speakerSpans(ev, until)
+	points := map[time.Duration]bool{}
+	for _, i := range append(speakers, ev.ScreenShares...) {
+		points[i.Start], points[i.End] = true, true
+	}
+	var times []time.Duration
+	for t := range points {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	var out []Interval
+	for i := 0; i+1 < len(times); i++ {
+		start, end := times[i], times[i+1]
+		track := ""
+		for _, s := range speakers {
+			if s.Start <= start && start < s.End {
+				track = s.Track
+			}
+		}
+		// The latest screen share started wins
+		for _, s := range ev.ScreenShares {
+			if s.Start <= start && start < s.End {
+				track = s.Track
+			}
+		}
+		if track == "" {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Track == track && out[n-1].End == start {
+			out[n-1].End = end
+			continue
+		}
+		out = append(out, Interval{Track: track, Start: start, End: end})
+	}
+	return out
+}
+
+type edlEvent struct {
+	seg      manifest.Segment
+	reel     string
+	from, to time.Duration // in the recording
+}
+
+// EDL writes a CMX 3600 edit decision list cutting between participants
+// as the speaker changes, and to screen shares while they run. Without
+// events it lists every segment at its place in the recording.
+func EDL(w io.Writer, m *manifest.Manifest, ev *Events, rate float64) error {
+	reels := map[string]string{}
+	for i, track := range m.Tracks() {
+		reels[track] = fmt.Sprintf("TRK%03d", i+1)
+	}
+
+	var events []edlEvent
+	if len(ev.Speakers) == 0 && len(ev.ScreenShares) == 0 {
+		for _, track := range m.Tracks() {
+			for _, s := range m.TrackSegments(track) {
+				events = append(events, edlEvent{seg: s, reel: reels[track], from: s.Offset, to: s.Offset + s.Duration})
+			}
+		}
+		sort.SliceStable(events, func(i, j int) bool { return events[i].from < events[j].from })
+	}
+	for _, c := range cuts(ev, end(m, ev)) {
+		// A cut may span several segments of its track, or fall in gaps
+		for _, s := range m.TrackSegments(c.Track) {
+			from, to := c.Start, c.End
+			if s.Offset > from {
+				from = s.Offset
+			}
+			if e := s.Offset + s.Duration; e < to {
+				to = e
+			}
+			if frames(from, rate) < frames(to, rate) {
+				events = append(events, edlEvent{seg: s, reel: reels[c.Track], from: from, to: to})
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "TITLE: %s\nFCM: NON-DROP FRAME\n", m.ID)
+	for i, e := range events {
+		fmt.Fprintf(bw, "\n%03d  %-8s V     C        %s %s %s %s\n", i+1, e.reel,
+			timecode(e.from-e.seg.Offset, rate), timecode(e.to-e.seg.Offset, rate),
+			timecode(e.from, rate), timecode(e.to, rate))
+		fmt.Fprintf(bw, "* FROM CLIP NAME: %s\n", e.seg.Name)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,148 @@
+package timeline
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/manifest"
+)
+
+type otioTime struct {
+	Schema string  `json:"OTIO_SCHEMA"`
+	Rate   float64 `json:"rate"`
+	Value  int64   `json:"value"`
+}
+
+type otioRange struct {
+	Schema   string   `json:"OTIO_SCHEMA"`
+	Start    otioTime `json:"start_time"`
+	Duration otioTime `json:"duration"`
+}
+
+type otioReference struct {
+	Schema    string                 `json:"OTIO_SCHEMA"`
+	TargetURL string                 `json:"target_url"`
+	Available *otioRange             `json:"available_range"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+type otioMarker struct {
+	Schema   string                 `json:"OTIO_SCHEMA"`
+	Name     string                 `json:"name"`
+	Color    string                 `json:"color"`
+	Range    otioRange              `json:"marked_range"`
+	Comment  string                 `json:"comment"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// otioItem is a clip, gap, track or stack. Only tracks and stacks have
+// children, always listed even if empty.
+type otioItem struct {
+	Schema    string                 `json:"OTIO_SCHEMA"`
+	Name      string                 `json:"name"`
+	Kind      string                 `json:"kind,omitempty"`
+	Range     *otioRange             `json:"source_range"`
+	Reference *otioReference         `json:"media_reference,omitempty"`
+	Children  interface{}            `json:"children,omitempty"`
+	Effects   []interface{}          `json:"effects"`
+	Markers   []otioMarker           `json:"markers"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+type otioTimeline struct {
+	Schema   string                 `json:"OTIO_SCHEMA"`
+	Name     string                 `json:"name"`
+	Start    *otioTime              `json:"global_start_time"`
+	Tracks   *otioItem              `json:"tracks"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+func otioRangeOf(start, duration time.Duration, rate float64) *otioRange {
+	return &otioRange{
+		Schema:   "TimeRange.1",
+		Start:    otioTime{Schema: "RationalTime.1", Rate: rate, Value: frames(start, rate)},
+		Duration: otioTime{Schema: "RationalTime.1", Rate: rate, Value: frames(duration, rate)},
+	}
+}
+
+func newOTIOItem(schema, name string) *otioItem {
+	return &otioItem{
+		Schema:   schema,
+		Name:     name,
+		Effects:  []interface{}{},
+		Markers:  []otioMarker{},
+		Metadata: map[string]interface{}{},
+	}
+}
+
+func otioMarkerOf(name, color string, i Interval, rate float64) otioMarker {
+	return otioMarker{
+		Schema:   "Marker.2",
+		Name:     name,
+		Color:    color,
+		Range:    *otioRangeOf(i.Start, i.End-i.Start, rate),
+		Metadata: map[string]interface{}{"track": i.Track},
+	}
+}
+
+// trackKind guesses whether a participant track is audio from its id,
+// since manifests don't record codecs
+func trackKind(track string) string {
+	if strings.Contains(strings.ToLower(track), "audio") {
+		return "Audio"
+	}
+	return "Video"
+}
+
+// OTIO writes an OpenTimelineIO project with a track per participant,
+// placing each segment at its offset in the recording. Speaker switches
+// are markers on the timeline, screen shares markers on their track.
+func OTIO(w io.Writer, m *manifest.Manifest, ev *Events, rate float64) error {
+	stack := newOTIOItem("Stack.1", "tracks")
+	tracks := []*otioItem{}
+	for _, track := range m.Tracks() {
+		t := newOTIOItem("Track.1", track)
+		t.Kind = trackKind(track)
+		children := []*otioItem{}
+		var at time.Duration
+		for _, s := range m.TrackSegments(track) {
+			if s.Offset > at {
+				gap := newOTIOItem("Gap.1", "")
+				gap.Range = otioRangeOf(0, s.Offset-at, rate)
+				children = append(children, gap)
+			}
+			clip := newOTIOItem("Clip.1", s.Name)
+			clip.Range = otioRangeOf(0, s.Duration, rate)
+			clip.Reference = &otioReference{
+				Schema:    "ExternalReference.1",
+				TargetURL: s.Name,
+				Available: otioRangeOf(0, s.Duration, rate),
+				Metadata:  map[string]interface{}{},
+			}
+			children = append(children, clip)
+			at = s.Offset + s.Duration
+		}
+		t.Children = children
+		for _, i := range ev.ScreenShares {
+			if i.Track == track {
+				t.Markers = append(t.Markers, otioMarkerOf("screenshare", "BLUE", i, rate))
+			}
+		}
+		tracks = append(tracks, t)
+	}
+	stack.Children = tracks
+	for _, i := range speakerSpans(ev, end(m, ev)) {
+		stack.Markers = append(stack.Markers, otioMarkerOf("speaker: "+i.Track, "GREEN", i, rate))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(otioTimeline{
+		Schema:   "Timeline.1",
+		Name:     m.ID,
+		Tracks:   stack,
+		Metadata: map[string]interface{}{"sid": m.SessionID},
+	})
+}
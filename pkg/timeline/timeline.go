@@ -0,0 +1,155 @@
+// Package timeline exports a recording as an editor project, so
+// post-production can import a session into a non-linear editor instead of
+// syncing participant files by hand. OpenTimelineIO (.otio) projects keep
+// every participant on its own track with markers for speaker switches and
+// screen shares. CMX 3600 edit decision lists (.edl) cut between
+// participants as the speaker changes, and to screen shares while they run.
+//
+// Speaker switches and screen shares are read from the recording's events
+// sidecar, of kind "events":
+//
+//	{
+//	  "speakers": [{"at": 0, "track": "alice"}, {"at": 5000000000, "track": "bob"}],
+//	  "screenshares": [{"track": "alice-screen", "start": 10000000000, "end": 20000000000}]
+//	}
+//
+// Times are nanoseconds from the start of the recording, like manifest
+// offsets.
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/manifest"
+)
+
+// EventsKind is the manifest sidecar kind of the events file
+const EventsKind = "events"
+
+// DefaultRate is the frame rate of exported timecodes
+const DefaultRate = 30
+
+// ErrUnsupported is returned for formats that aren't editor projects
+var ErrUnsupported = errors.New("timeline: unsupported format")
+
+// Switch is the moment a participant starts speaking
+type Switch struct {
+	At    time.Duration `json:"at"`
+	Track string        `json:"track"`
+}
+
+// Interval is a span of a track, such as a screen share
+type Interval struct {
+	Track string        `json:"track"`
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
+// Events are the session events placed on the timeline
+type Events struct {
+	Speakers     []Switch   `json:"speakers,omitempty"`
+	ScreenShares []Interval `json:"screenshares,omitempty"`
+}
+
+// ReadEvents reads the events sidecar of m, if it has one
+func ReadEvents(m *manifest.Manifest, open manifest.OpenFunc) (*Events, error) {
+	ev := &Events{}
+	for _, s := range m.Sidecars {
+		if s.Kind != EventsKind {
+			continue
+		}
+		r, err := open(s.Name)
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewDecoder(r).Decode(ev)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("timeline: %s: %w", s.Name, err)
+		}
+	}
+	sort.SliceStable(ev.Speakers, func(i, j int) bool { return ev.Speakers[i].At < ev.Speakers[j].At })
+	sort.SliceStable(ev.ScreenShares, func(i, j int) bool { return ev.ScreenShares[i].Start < ev.ScreenShares[j].Start })
+	return ev, nil
+}
+
+// Supported reports whether the format, or the extension of a file name,
+// is an editor project
+func Supported(format string) bool {
+	switch formatOf(format) {
+	case "otio", "edl":
+		return true
+	}
+	return false
+}
+
+func formatOf(format string) string {
+	if ext := path.Ext(format); ext != "" {
+		format = ext[1:]
+	}
+	return strings.ToLower(format)
+}
+
+// Export writes the editor project of the recording described by m to w.
+// format is "otio", "edl" or a file name with either extension.
+func Export(ctx context.Context, w io.Writer, format string, m *manifest.Manifest, open manifest.OpenFunc) error {
+	if !Supported(format) {
+		return fmt.Errorf("%w: %s", ErrUnsupported, format)
+	}
+	ev, err := ReadEvents(m, open)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if formatOf(format) == "otio" {
+		return OTIO(w, m, ev, DefaultRate)
+	}
+	return EDL(w, m, ev, DefaultRate)
+}
+
+// frames converts a duration to a whole number of frames
+func frames(d time.Duration, rate float64) int64 {
+	return int64(d.Seconds()*rate + 0.5)
+}
+
+// end returns the end of the recording, the later of its segments and events
+func end(m *manifest.Manifest, ev *Events) time.Duration {
+	d := m.Duration()
+	for _, s := range ev.Speakers {
+		if s.At > d {
+			d = s.At
+		}
+	}
+	for _, s := range ev.ScreenShares {
+		if s.End > d {
+			d = s.End
+		}
+	}
+	return d
+}
+
+// speakerSpans returns the interval each speaker held the floor, until
+// the next switch or the end of the recording
+func speakerSpans(ev *Events, until time.Duration) []Interval {
+	var spans []Interval
+	for i, s := range ev.Speakers {
+		e := until
+		if i+1 < len(ev.Speakers) {
+			e = ev.Speakers[i+1].At
+		}
+		if e > s.At {
+			spans = append(spans, Interval{Track: s.Track, Start: s.At, End: e})
+		}
+	}
+	return spans
+}
@@ -0,0 +1,142 @@
+package timeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/ion-avp/pkg/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func session(t *testing.T) (*manifest.Manifest, manifest.OpenFunc) {
+	m := manifest.New("rec", "sid", time.Now())
+	seg := func(track, name string, seq int, offset, duration time.Duration) {
+		m.Segments = append(m.Segments, manifest.Segment{
+			File: manifest.File{Name: name}, Track: track, Seq: seq, Offset: offset, Duration: duration,
+		})
+	}
+	seg("alice", "alice/0.webm", 0, 0, 20*time.Second)
+	seg("bob", "bob/0.webm", 0, 2*time.Second, 10*time.Second)
+	seg("bob", "bob/1.webm", 1, 14*time.Second, 6*time.Second)
+	seg("screen", "screen/0.webm", 0, 8*time.Second, 4*time.Second)
+	m.Sidecars = []manifest.Sidecar{{File: manifest.File{Name: "events.json"}, Kind: EventsKind}}
+
+	events, err := json.Marshal(Events{
+		Speakers: []Switch{
+			{At: 5 * time.Second, Track: "bob"},
+			{At: 0, Track: "alice"},
+			{At: 15 * time.Second, Track: "alice"},
+		},
+		ScreenShares: []Interval{{Track: "screen", Start: 8 * time.Second, End: 12 * time.Second}},
+	})
+	assert.NoError(t, err)
+	open := func(name string) (io.ReadCloser, error) {
+		if name != "events.json" {
+			return nil, errors.New("not found")
+		}
+		return ioutil.NopCloser(bytes.NewReader(events)), nil
+	}
+	return m, open
+}
+
+func TestCuts(t *testing.T) {
+	m, open := session(t)
+	ev, err := ReadEvents(m, open)
+	assert.NoError(t, err)
+	assert.Equal(t, []Interval{
+		{Track: "alice", Start: 0, End: 5 * time.Second},
+		{Track: "bob", Start: 5 * time.Second, End: 8 * time.Second},
+		{Track: "screen", Start: 8 * time.Second, End: 12 * time.Second},
+		{Track: "bob", Start: 12 * time.Second, End: 15 * time.Second},
+		{Track: "alice", Start: 15 * time.Second, End: 20 * time.Second},
+	}, cuts(ev, end(m, ev)))
+}
+
+func TestEDL(t *testing.T) {
+	m, open := session(t)
+	var buf bytes.Buffer
+	assert.NoError(t, Export(context.Background(), &buf, "out.edl", m, open))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "TITLE: rec", lines[0])
+	var events []string
+	for _, l := range lines {
+		if len(l) > 0 && l[0] >= '0' && l[0] <= '9' {
+			events = append(events, l)
+		}
+	}
+	// Bob's cut from 12s to 15s falls in a gap and continues in his second segment
+	assert.Equal(t, []string{
+		"001  TRK001   V     C        00:00:00:00 00:00:05:00 00:00:00:00 00:00:05:00",
+		"002  TRK002   V     C        00:00:03:00 00:00:06:00 00:00:05:00 00:00:08:00",
+		"003  TRK003   V     C        00:00:00:00 00:00:04:00 00:00:08:00 00:00:12:00",
+		"004  TRK002   V     C        00:00:00:00 00:00:01:00 00:00:14:00 00:00:15:00",
+		"005  TRK001   V     C        00:00:15:00 00:00:20:00 00:00:15:00 00:00:20:00",
+	}, events)
+	assert.Contains(t, buf.String(), "* FROM CLIP NAME: bob/1.webm")
+}
+
+func TestOTIO(t *testing.T) {
+	m, open := session(t)
+	var buf bytes.Buffer
+	assert.NoError(t, Export(context.Background(), &buf, "otio", m, open))
+
+	var tl struct {
+		Schema string `json:"OTIO_SCHEMA"`
+		Tracks struct {
+			Children []struct {
+				Name     string `json:"name"`
+				Kind     string `json:"kind"`
+				Children []struct {
+					Schema string `json:"OTIO_SCHEMA"`
+					Range  struct {
+						Duration struct {
+							Value int64 `json:"value"`
+						} `json:"duration"`
+					} `json:"source_range"`
+					Reference struct {
+						TargetURL string `json:"target_url"`
+					} `json:"media_reference"`
+				} `json:"children"`
+				Markers []struct {
+					Name string `json:"name"`
+				} `json:"markers"`
+			} `json:"children"`
+			Markers []struct {
+				Name string `json:"name"`
+			} `json:"markers"`
+		} `json:"tracks"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &tl))
+	assert.Equal(t, "Timeline.1", tl.Schema)
+	assert.Len(t, tl.Tracks.Children, 3)
+
+	bob := tl.Tracks.Children[1]
+	assert.Equal(t, "bob", bob.Name)
+	assert.Equal(t, "Video", bob.Kind)
+	var schemas []string
+	for _, c := range bob.Children {
+		schemas = append(schemas, c.Schema)
+	}
+	assert.Equal(t, []string{"Gap.1", "Clip.1", "Gap.1", "Clip.1"}, schemas)
+	assert.Equal(t, int64(60), bob.Children[0].Range.Duration.Value)
+	assert.Equal(t, "bob/0.webm", bob.Children[1].Reference.TargetURL)
+	assert.Equal(t, int64(60), bob.Children[2].Range.Duration.Value)
+
+	assert.Len(t, tl.Tracks.Children[2].Markers, 1)
+	assert.Equal(t, "screenshare", tl.Tracks.Children[2].Markers[0].Name)
+	assert.Len(t, tl.Tracks.Markers, 3)
+	assert.Equal(t, "speaker: alice", tl.Tracks.Markers[0].Name)
+}
+
+func TestExport_Unsupported(t *testing.T) {
+	m, open := session(t)
+	assert.True(t, errors.Is(Export(context.Background(), ioutil.Discard, "out.webm", m, open), ErrUnsupported))
+}
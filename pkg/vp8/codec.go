@@ -0,0 +1,159 @@
+//go:build libvpx
+// +build libvpx
+
+package vp8
+
+/*
+#cgo pkg-config: vpx
+#include <stdlib.h>
+#include <vpx/vpx_encoder.h>
+#include <vpx/vp8cx.h>
+
+static vpx_codec_err_t vp8_config_default(vpx_codec_enc_cfg_t *cfg) {
+	return vpx_codec_enc_config_default(vpx_codec_vp8_cx(), cfg, 0);
+}
+
+static vpx_codec_err_t vp8_init(vpx_codec_ctx_t *ctx, vpx_codec_enc_cfg_t *cfg) {
+	return vpx_codec_enc_init(ctx, vpx_codec_vp8_cx(), cfg, 0);
+}
+
+// vp8_frame returns the data of a packet of an encoded frame, or NULL for
+// other packets
+static const void *vp8_frame(const vpx_codec_cx_pkt_t *pkt, size_t *size, int *key) {
+	if (pkt->kind != VPX_CODEC_CX_FRAME_PKT) {
+		return NULL;
+	}
+	*size = pkt->data.frame.sz;
+	*key = (pkt->data.frame.flags & VPX_FRAME_IS_KEY) != 0;
+	return pkt->data.frame.buf;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"runtime"
+	"unsafe"
+)
+
+// ErrFrameSize is returned for frames of another size than the encoder's,
+// or not 4:2:0
+var ErrFrameSize = errors.New("vp8: frame doesn't match the encoder")
+
+func vpxError(op string, code C.vpx_codec_err_t) error {
+	return fmt.Errorf("vp8: %s: %s", op, C.GoString(C.vpx_codec_err_to_string(code)))
+}
+
+// Encoder encodes 4:2:0 frames of one size to VP8, for real time
+type Encoder struct {
+	ctx    *C.vpx_codec_ctx_t
+	img    *C.vpx_image_t
+	width  int
+	height int
+	pts    int64
+}
+
+// NewEncoder creates an encoder of frames of c's size, at c's rate
+func NewEncoder(c Config) (*Encoder, error) {
+	if c.Width <= 0 || c.Height <= 0 || c.FrameRate <= 0 {
+		return nil, ErrFrameSize
+	}
+	if c.Keyframes <= 0 {
+		c.Keyframes = c.FrameRate
+	}
+	var cfg C.vpx_codec_enc_cfg_t
+	if code := C.vp8_config_default(&cfg); code != C.VPX_CODEC_OK {
+		return nil, vpxError("configuring", code)
+	}
+	cfg.g_w = C.uint(c.Width)
+	cfg.g_h = C.uint(c.Height)
+	cfg.g_timebase.num = 1
+	cfg.g_timebase.den = C.int(c.FrameRate)
+	cfg.g_threads = C.uint(runtime.NumCPU())
+	cfg.g_lag_in_frames = 0
+	cfg.g_error_resilient = C.VPX_ERROR_RESILIENT_DEFAULT
+	cfg.rc_end_usage = C.VPX_CBR
+	if c.Bitrate > 0 {
+		cfg.rc_target_bitrate = C.uint(c.Bitrate / 1000)
+	}
+	cfg.kf_mode = C.VPX_KF_AUTO
+	cfg.kf_max_dist = C.uint(c.Keyframes)
+
+	// libvpx keeps the context, so it is allocated in C
+	e := &Encoder{
+		ctx:    (*C.vpx_codec_ctx_t)(C.calloc(1, C.sizeof_vpx_codec_ctx_t)),
+		width:  c.Width,
+		height: c.Height,
+	}
+	if code := C.vp8_init(e.ctx, &cfg); code != C.VPX_CODEC_OK {
+		C.free(unsafe.Pointer(e.ctx))
+		return nil, vpxError("initializing", code)
+	}
+	e.img = C.vpx_img_alloc(nil, C.VPX_IMG_FMT_I420, C.uint(c.Width), C.uint(c.Height), 1)
+	if e.img == nil {
+		C.vpx_codec_destroy(e.ctx)
+		C.free(unsafe.Pointer(e.ctx))
+		return nil, errors.New("vp8: allocating frame")
+	}
+	return e, nil
+}
+
+// Encode encodes a frame, a keyframe if key is set, returning the frames
+// libvpx outputs for it: none while it drops frames to keep to the bitrate
+func (e *Encoder) Encode(m *image.YCbCr, key bool) ([]Frame, error) {
+	if m.Rect.Dx() != e.width || m.Rect.Dy() != e.height || m.SubsampleRatio != image.YCbCrSubsampleRatio420 {
+		return nil, ErrFrameSize
+	}
+	cw, ch := (e.width+1)/2, (e.height+1)/2
+	for i, p := range []struct {
+		pix    []byte
+		stride int
+		w, h   int
+	}{
+		{m.Y[m.YOffset(m.Rect.Min.X, m.Rect.Min.Y):], m.YStride, e.width, e.height},
+		{m.Cb[m.COffset(m.Rect.Min.X, m.Rect.Min.Y):], m.CStride, cw, ch},
+		{m.Cr[m.COffset(m.Rect.Min.X, m.Rect.Min.Y):], m.CStride, cw, ch},
+	} {
+		stride := int(e.img.stride[i])
+		plane := (*[1 << 30]byte)(unsafe.Pointer(e.img.planes[i]))[: stride*p.h : stride*p.h]
+		for y := 0; y < p.h; y++ {
+			copy(plane[y*stride:y*stride+p.w], p.pix[y*p.stride:])
+		}
+	}
+
+	var flags C.vpx_enc_frame_flags_t
+	if key {
+		flags = C.VPX_EFLAG_FORCE_KF
+	}
+	if code := C.vpx_codec_encode(e.ctx, e.img, C.vpx_codec_pts_t(e.pts), 1, flags, C.VPX_DL_REALTIME); code != C.VPX_CODEC_OK {
+		return nil, vpxError("encoding", code)
+	}
+	e.pts++
+
+	var (
+		frames []Frame
+		iter   C.vpx_codec_iter_t
+	)
+	for {
+		pkt := C.vpx_codec_get_cx_data(e.ctx, &iter)
+		if pkt == nil {
+			return frames, nil
+		}
+		var (
+			size C.size_t
+			k    C.int
+		)
+		if buf := C.vp8_frame(pkt, &size, &k); buf != nil {
+			frames = append(frames, Frame{Data: C.GoBytes(buf, C.int(size)), Key: k != 0})
+		}
+	}
+}
+
+// Close frees the encoder
+func (e *Encoder) Close() {
+	C.vpx_img_free(e.img)
+	C.vpx_codec_destroy(e.ctx)
+	C.free(unsafe.Pointer(e.ctx))
+}
@@ -0,0 +1,24 @@
+// Package vp8 binds libvpx to encode VP8 video, for elements that draw
+// frames of their own, such as composites. Like the VP8 decoder, the
+// encoder is only built with a build tag, libvpx, as it needs the C
+// library.
+package vp8
+
+// Config configures an Encoder
+type Config struct {
+	Width  int
+	Height int
+	// FrameRate of the frames encoded, in frames per second
+	FrameRate int
+	// Bitrate targeted, in bits per second
+	Bitrate int
+	// Keyframes is the most frames between keyframes, a second's worth
+	// if 0, so recordings can be cut and players can join often
+	Keyframes int
+}
+
+// Frame is an encoded frame
+type Frame struct {
+	Data []byte
+	Key  bool
+}
@@ -0,0 +1,196 @@
+// Package watermark hides an identifier in audio, so a leaked copy of a
+// confidential recording can be traced back to its session or tenant.
+//
+// The identifier is hashed to a 64 bit payload which is repeated for the
+// whole recording. Each bit is spread over a pseudo random noise sequence
+// derived from a secret key, added at a small fraction of the loudness of
+// the audio around it so it is masked by the audio itself. Silence is left
+// untouched. Detect recovers the payload by correlating audio with the
+// same sequence, which needs the key and audio from the start of the
+// recording.
+package watermark
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+const (
+	// Bits is the size of the payload
+	Bits = 64
+	// DefaultChips is the number of samples each bit is spread over,
+	// 200ms at 48kHz
+	DefaultChips = 9600
+	// DefaultStrength is the amplitude of the watermark relative to the
+	// audio, about -34dB
+	DefaultStrength = 0.02
+	// block is the number of samples the loudness of the audio is
+	// measured over
+	block = 480
+	// clearVote is the number of standard deviations by which a bit's vote
+	// must be clear of chance
+	clearVote = 3
+)
+
+// Config configures the watermark. Zero fields use the defaults.
+type Config struct {
+	// Key seeds the noise sequence, the same key detects the watermark
+	Key string `mapstructure:"key"`
+	// Strength is the amplitude relative to the audio
+	Strength float64 `mapstructure:"strength"`
+	// Chips is the number of samples per channel each bit is spread over
+	Chips int `mapstructure:"chips"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Strength <= 0 {
+		c.Strength = DefaultStrength
+	}
+	if c.Chips <= 0 {
+		c.Chips = DefaultChips
+	}
+	return c
+}
+
+// Payload returns the 64 bit payload embedded for an identifier
+func Payload(id string) uint64 {
+	sum := sha256.Sum256([]byte(id))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// noise is a xorshift generator of the chip sequence
+type noise struct {
+	state uint64
+}
+
+func newNoise(key string) *noise {
+	n := &noise{state: Payload("watermark:" + key)}
+	if n.state == 0 {
+		n.state = 1
+	}
+	return n
+}
+
+func (n *noise) next() float64 {
+	n.state ^= n.state << 13
+	n.state ^= n.state >> 7
+	n.state ^= n.state << 17
+	if n.state&1 == 0 {
+		return -1
+	}
+	return 1
+}
+
+// Embedder adds a watermark to interleaved 16 bit PCM, written in order
+// from the start of the recording
+type Embedder struct {
+	cfg      Config
+	payload  uint64
+	channels int
+	noise    *noise
+	n        int // samples per channel written
+	prev     float64
+}
+
+// NewEmbedder creates an embedder of the payload of id
+func NewEmbedder(c Config, id string, channels int) *Embedder {
+	if channels < 1 {
+		channels = 1
+	}
+	return &Embedder{
+		cfg:      c.withDefaults(),
+		payload:  Payload(id),
+		channels: channels,
+		noise:    newNoise(c.Key),
+	}
+}
+
+// Embed watermarks pcm in place. Its length should be a multiple of the
+// number of channels.
+func (e *Embedder) Embed(pcm []int16) {
+	frames := len(pcm) / e.channels
+	for start := 0; start < frames; start += block {
+		end := start + block
+		if end > frames {
+			end = frames
+		}
+		var sum float64
+		for _, s := range pcm[start*e.channels : end*e.channels] {
+			sum += float64(s) * float64(s)
+		}
+		rms := math.Sqrt(sum / float64((end-start)*e.channels))
+		// Follow the loudness smoothly so the watermark doesn't click
+		amp := e.cfg.Strength * (rms + e.prev) / 2
+		e.prev = rms
+
+		for i := start; i < end; i++ {
+			chip := e.noise.next()
+			bit := int(e.n/e.cfg.Chips) % Bits
+			if e.payload>>(Bits-1-bit)&1 == 0 {
+				chip = -chip
+			}
+			e.n++
+			for c := 0; c < e.channels; c++ {
+				j := i*e.channels + c
+				pcm[j] = clamp(float64(pcm[j]) + amp*chip)
+			}
+		}
+	}
+}
+
+func clamp(v float64) int16 {
+	v = math.Round(v)
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+// Detect recovers the payload from interleaved 16 bit PCM starting at the
+// start of the recording. Each bit is voted on by every repetition of the
+// payload. Confidence is the fraction of bits voted on far more clearly
+// than audio without a watermark would, from 0 to 1.
+func Detect(c Config, pcm []int16, channels int) (payload uint64, confidence float64) {
+	c = c.withDefaults()
+	if channels < 1 {
+		channels = 1
+	}
+	frames := len(pcm) / channels
+	noise := newNoise(c.Key)
+
+	// Correlate the first difference of the mono mix, which whitens the
+	// audio much more than the noise, with the first difference of the
+	// sequence. Without a watermark each vote is a sum of random signs
+	// with the variance below.
+	var votes, variance [Bits]float64
+	var prevX, prevChip float64
+	for i := 0; i < frames; i++ {
+		var x float64
+		for ch := 0; ch < channels; ch++ {
+			x += float64(pcm[i*channels+ch])
+		}
+		chip := noise.next()
+		if i > 0 {
+			bit := (i / c.Chips) % Bits
+			v := (x - prevX) * (chip - prevChip)
+			votes[bit] += v
+			variance[bit] += v * v
+		}
+		prevX, prevChip = x, chip
+	}
+
+	var clear int
+	for bit, v := range votes {
+		if v > 0 {
+			payload |= 1 << (Bits - 1 - bit)
+		}
+		if variance[bit] > 0 && math.Abs(v) > clearVote*math.Sqrt(variance[bit]) {
+			clear++
+		}
+	}
+	return payload, float64(clear) / Bits
+}
@@ -0,0 +1,59 @@
+package watermark
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// speechLike returns stereo audio of tones with a varying envelope and
+// some noise
+func speechLike(seconds int) []int16 {
+	r := rand.New(rand.NewSource(1))
+	pcm := make([]int16, seconds*48000*2)
+	for i := 0; i < len(pcm)/2; i++ {
+		t := float64(i) / 48000
+		env := 0.2 + 0.8*math.Abs(math.Sin(2*math.Pi*0.37*t))
+		v := env * (6000*math.Sin(2*math.Pi*220*t) + 3000*math.Sin(2*math.Pi*1250*t) + 500*r.NormFloat64())
+		pcm[2*i], pcm[2*i+1] = int16(v), int16(v*0.8)
+	}
+	return pcm
+}
+
+func TestEmbedDetect(t *testing.T) {
+	c := Config{Key: "secret"}
+	pcm := speechLike(30)
+	clean := append([]int16(nil), pcm...)
+
+	e := NewEmbedder(c, "tenant/session", 2)
+	// Written in packets of 20ms, as decoded from Opus
+	for i := 0; i < len(pcm); i += 960 * 2 {
+		e.Embed(pcm[i : i+960*2])
+	}
+
+	// The watermark is far below the audio
+	var signal, mark float64
+	for i := range pcm {
+		signal += float64(clean[i]) * float64(clean[i])
+		d := float64(pcm[i]) - float64(clean[i])
+		mark += d * d
+	}
+	assert.Less(t, 10*math.Log10(mark/signal), -30.0)
+
+	payload, confidence := Detect(c, pcm, 2)
+	assert.Equal(t, Payload("tenant/session"), payload)
+	assert.Greater(t, confidence, 0.9)
+
+	_, confidence = Detect(c, clean, 2)
+	assert.Less(t, confidence, 0.1)
+	_, confidence = Detect(Config{Key: "other"}, pcm, 2)
+	assert.Less(t, confidence, 0.1)
+}
+
+func TestEmbed_Silence(t *testing.T) {
+	pcm := make([]int16, 4800)
+	NewEmbedder(Config{}, "session", 1).Embed(pcm)
+	assert.Equal(t, make([]int16, 4800), pcm)
+}
@@ -0,0 +1,141 @@
+// Package wav writes 16 bit PCM WAVE files, for tools that only read WAV,
+// such as many transcription and analytics jobs, and Broadcast Wave (BWF)
+// files for audio post-production.
+//
+// Streams of unknown length, written as they are recorded, get a header
+// with unknown sizes, which most readers take as reaching the end of the
+// file. Fix fills them in once the file is finished.
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrFormat is returned for files that aren't WAV files written by
+// this package
+var ErrFormat = errors.New("wav: invalid file")
+
+// HeaderSize is the size of the header of a file, before its samples
+const HeaderSize = 44
+
+// Unknown is the size of streams of unknown length
+const Unknown = 0xffffffff
+
+// Header returns the header of a file of size bytes of interleaved 16 bit
+// samples at rate with channels
+func Header(rate, channels int, size uint32) []byte {
+	b := make([]byte, HeaderSize)
+	copy(b, "RIFF")
+	binary.LittleEndian.PutUint32(b[4:], riffSize(size))
+	copy(b[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(b[16:], 16)
+	binary.LittleEndian.PutUint16(b[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(b[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(b[24:], uint32(rate))
+	binary.LittleEndian.PutUint32(b[28:], uint32(rate*channels*2))
+	binary.LittleEndian.PutUint16(b[32:], uint16(channels*2))
+	binary.LittleEndian.PutUint16(b[34:], 16)
+	copy(b[36:], "data")
+	binary.LittleEndian.PutUint32(b[40:], size)
+	return b
+}
+
+// bextSize is the size of the bext chunk, version 1 without coding history
+const bextSize = 602
+
+// Bext is the broadcast extension of a BWF file
+type Bext struct {
+	Description string
+	Originator  string
+	// Origination is when the audio was recorded
+	Origination time.Time
+	// TimeReference is the timecode of the first sample, in samples since
+	// midnight
+	TimeReference uint64
+}
+
+// BroadcastHeader returns the header of a Broadcast Wave file of size
+// bytes of interleaved 16 bit samples at rate with channels: Header's with
+// a bext chunk and, unless empty, an iXML chunk of ixml before the samples
+func BroadcastHeader(rate, channels int, size uint32, b Bext, ixml []byte) []byte {
+	h := Header(rate, channels, size)
+	out := make([]byte, 0, len(h)+8+bextSize+8+len(ixml)+1)
+	out = append(out, h[:36]...)
+
+	bext := make([]byte, bextSize)
+	copy(bext[:256], b.Description)
+	copy(bext[256:288], b.Originator)
+	if !b.Origination.IsZero() {
+		copy(bext[320:330], b.Origination.Format("2006-01-02"))
+		copy(bext[330:338], b.Origination.Format("15:04:05"))
+	}
+	binary.LittleEndian.PutUint64(bext[338:], b.TimeReference)
+	binary.LittleEndian.PutUint16(bext[346:], 1) // version
+	out = appendChunk(out, "bext", bext)
+	if len(ixml) > 0 {
+		out = appendChunk(out, "iXML", ixml)
+	}
+	out = append(out, h[36:]...)
+
+	riff := uint32(Unknown)
+	if uint64(size)+uint64(len(out))-8 < Unknown && size != Unknown {
+		riff = size + uint32(len(out)) - 8
+	}
+	binary.LittleEndian.PutUint32(out[4:], riff)
+	return out
+}
+
+// appendChunk appends a RIFF chunk of data, padded to an even size
+func appendChunk(b []byte, id string, data []byte) []byte {
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(data)))
+	b = append(b, id...)
+	b = append(b, n[:]...)
+	b = append(b, data...)
+	if len(data)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// riffSize is the size of the RIFF chunk holding size bytes of samples
+func riffSize(size uint32) uint32 {
+	if size > Unknown-HeaderSize+8 {
+		return Unknown
+	}
+	return size + HeaderSize - 8
+}
+
+// Fix sets the sizes in the header of a finished file, written with
+// unknown sizes. Files of 4GB or more keep them unknown, as WAV can't say.
+func Fix(f io.ReadWriteSeeker) error {
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, HeaderSize)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fmt.Errorf("%w: %s", ErrFormat, err)
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:16]) != "WAVEfmt " || string(header[36:40]) != "data" {
+		return fmt.Errorf("%w: no RIFF WAVE header", ErrFormat)
+	}
+	size := uint32(Unknown)
+	if n := end - HeaderSize; n < Unknown {
+		size = uint32(n)
+	}
+	binary.LittleEndian.PutUint32(header[4:], riffSize(size))
+	binary.LittleEndian.PutUint32(header[40:], size)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = f.Write(header)
+	return err
+}
@@ -0,0 +1,81 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader(t *testing.T) {
+	h := Header(48000, 2, 960*4)
+	assert.Len(t, h, HeaderSize)
+	assert.Equal(t, "RIFF", string(h[:4]))
+	assert.Equal(t, uint32(36+960*4), binary.LittleEndian.Uint32(h[4:]))
+	assert.Equal(t, "WAVEfmt ", string(h[8:16]))
+	assert.Equal(t, uint16(2), binary.LittleEndian.Uint16(h[22:]))
+	assert.Equal(t, uint32(48000), binary.LittleEndian.Uint32(h[24:]))
+	assert.Equal(t, uint32(192000), binary.LittleEndian.Uint32(h[28:]))
+	assert.Equal(t, uint16(4), binary.LittleEndian.Uint16(h[32:]))
+	assert.Equal(t, "data", string(h[36:40]))
+	assert.Equal(t, uint32(960*4), binary.LittleEndian.Uint32(h[40:]))
+
+	h = Header(16000, 1, Unknown)
+	assert.Equal(t, uint32(Unknown), binary.LittleEndian.Uint32(h[4:]))
+	assert.Equal(t, uint32(Unknown), binary.LittleEndian.Uint32(h[40:]))
+}
+
+func TestFix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wav")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audio.wav")
+	data := append(Header(48000, 1, Unknown), make([]byte, 1000)...)
+	assert.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, Fix(f))
+	assert.NoError(t, f.Close())
+	data, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Header(48000, 1, 1000), data[:HeaderSize])
+	assert.Len(t, data, HeaderSize+1000)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("not a wav file, at least 44 bytes long......"), 0600))
+	f, err = os.OpenFile(path, os.O_RDWR, 0)
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.True(t, errors.Is(Fix(f), ErrFormat))
+}
+
+func TestBroadcastHeader(t *testing.T) {
+	at := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	h := BroadcastHeader(48000, 4, 1000, Bext{Description: "rec", Originator: "ion-avp", Origination: at, TimeReference: 36000 * 48000}, []byte("<BWFXML/>"))
+	assert.Equal(t, "RIFF", string(h[:4]))
+	assert.Equal(t, uint32(len(h)-8+1000), binary.LittleEndian.Uint32(h[4:]))
+	assert.Equal(t, Header(48000, 4, 1000)[8:36], h[8:36])
+	assert.Equal(t, "bext", string(h[36:40]))
+	assert.Equal(t, uint32(602), binary.LittleEndian.Uint32(h[40:]))
+	bext := h[44 : 44+602]
+	assert.Equal(t, "rec", string(bext[:3]))
+	assert.Equal(t, "ion-avp", string(bext[256:263]))
+	assert.Equal(t, "2021-02-0110:00:00", string(bext[320:338]))
+	assert.Equal(t, uint64(36000*48000), binary.LittleEndian.Uint64(bext[338:]))
+	ixml := h[44+602:]
+	assert.Equal(t, "iXML", string(ixml[:4]))
+	assert.Equal(t, uint32(9), binary.LittleEndian.Uint32(ixml[4:]))
+	assert.Equal(t, "<BWFXML/>\x00", string(ixml[8:18]))
+	assert.Equal(t, "data", string(ixml[18:22]))
+	assert.Equal(t, uint32(1000), binary.LittleEndian.Uint32(ixml[22:]))
+	assert.Len(t, ixml, 26)
+
+	h = BroadcastHeader(48000, 1, Unknown, Bext{}, nil)
+	assert.Equal(t, uint32(Unknown), binary.LittleEndian.Uint32(h[4:]))
+	assert.Equal(t, "data", string(h[44+602:44+606]))
+}
@@ -1,11 +1,13 @@
 package avp
 
 import (
-	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/pion/ion-avp/pkg/interpret"
+	"github.com/pion/ion-avp/pkg/layout"
 	log "github.com/pion/ion-log"
 	"github.com/pion/rtcp"
 
@@ -23,6 +25,12 @@ type WebRTCTransportConfig struct {
 	setting       webrtc.SettingEngine
 }
 
+// sfuDataChannel is the label of the SFU's data channel for signalling
+// within a session
+const sfuDataChannel = "ion-sfu"
+
+// SFUFeedback asks the SFU to forward a stream's video at a simulcast
+// layer, one of the Layer constants, and whether to forward its audio
 type SFUFeedback struct {
 	StreamID string `json:"streamId"`
 	Video    string `json:"video"`
@@ -44,7 +52,18 @@ type WebRTCTransport struct {
 	builders  map[string]*Builder         // one builder per track
 	pending   map[string][]PendingProcess // maps track id to pending element constructors
 	processes map[string]Element          // existing processes
+	optedOut  map[string]bool             // tracks the application opted out of recording
+	rooms     map[Room]map[string]Element // elements of rooms fed each track
 	onCloseFn func()
+
+	simulcast SimulcastConfig
+	dominant  string            // stream id of the dominant speaker
+	layers    map[string]string // simulcast layer requested of each stream
+	feedback  func(SFUFeedback) error
+
+	layout *layout.Current // of the session's composites
+	roster *layout.Roster
+	canvas *layout.Canvas
 }
 
 // NewWebRTCTransport creates a new webrtc transport
@@ -65,6 +84,8 @@ func NewWebRTCTransport(id string, c Config) *WebRTCTransport {
 		}
 	}
 
+	se.SetNetworkTypes(c.Family().networkTypes())
+
 	var iceServers []webrtc.ICEServer
 	for _, iceServer := range c.WebRTC.ICEServers {
 		s := webrtc.ICEServer{
@@ -101,7 +122,16 @@ func NewWebRTCTransport(id string, c Config) *WebRTCTransport {
 		builders:  make(map[string]*Builder),
 		pending:   make(map[string][]PendingProcess),
 		processes: make(map[string]Element),
+		optedOut:  make(map[string]bool),
+		rooms:     make(map[Room]map[string]Element),
+		simulcast: c.Simulcast.withDefaults(),
+		layers:    make(map[string]string),
+		feedback:  sub.SendFeedback,
+		layout:    layout.NewCurrent(layout.Layout{}),
+		roster:    layout.NewRoster(),
+		canvas:    layout.NewCanvas(),
 	}
+	sub.OnAudioLevels(t.escalate)
 
 	sub.OnTrack(func(track *webrtc.TrackRemote, recv *webrtc.RTPReceiver) {
 		id := track.ID()
@@ -116,10 +146,21 @@ func NewWebRTCTransport(id string, c Config) *WebRTCTransport {
 			log.Warnf("maxlate should not be 0. Using 100.")
 			maxlate = 100
 		}
+		maxlate = maxLate(maxlate)
 
 		builder := NewBuilder(track, maxlate)
+		if recv != nil {
+			builder.clock.negotiated(recv.GetParameters())
+			builder.levels.negotiated(recv.GetParameters())
+			builder.optOut.negotiated(recv.GetParameters())
+			go builder.readRTCP(recv)
+		}
+		if c.Quality.Interval > 0 {
+			go builder.trackQuality(c.Quality.Interval)
+		}
 		t.mu.Lock()
 		defer t.mu.Unlock()
+		builder.optOut.set(t.optedOut[id])
 		t.builders[id] = builder
 
 		// If there is a pending pipeline for this track,
@@ -135,12 +176,24 @@ func NewWebRTCTransport(id string, c Config) *WebRTCTransport {
 			}
 			delete(t.pending, id)
 		}
+		for r, joined := range t.rooms {
+			joined[id] = r.Join(id)
+			builder.AttachElement(joined[id])
+		}
+		if track.Kind() == webrtc.RTPCodecTypeVideo && t.dominant != "" && t.composite() {
+			t.request(track.StreamID())
+		}
 
 		if track.Kind() == webrtc.RTPCodecTypeVideo {
-			err := sub.pc.WriteRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{SenderSSRC: uint32(track.SSRC()), MediaSSRC: uint32(track.SSRC())}})
-			if err != nil {
-				log.Errorf("error writing pli %s", err)
+			pli := func() {
+				err := sub.pc.WriteRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{SenderSSRC: uint32(track.SSRC()), MediaSSRC: uint32(track.SSRC())}})
+				if err != nil {
+					log.Errorf("error writing pli %s", err)
+				}
 			}
+			pli()
+			// Elements shedding video resume from the next keyframe
+			builder.OnKeyframeRequest(func() { go pli() })
 		}
 
 		builder.OnStop(func() {
@@ -210,6 +263,11 @@ func (t *WebRTCTransport) Close() error {
 	if t.onCloseFn != nil {
 		t.onCloseFn()
 	}
+	for r := range t.rooms {
+		r.Close()
+		delete(t.rooms, r)
+	}
+	interpret.End(t.id)
 
 	err := t.sub.Close()
 	if err != nil {
@@ -220,14 +278,25 @@ func (t *WebRTCTransport) Close() error {
 
 // Process creates a pipeline
 func (t *WebRTCTransport) Process(pid, tid, eid string, config []byte) error {
-	log.Infof("WebRTCTransport.Process id=%s", pid)
+	return t.ProcessWithPriority(pid, tid, eid, config, PriorityNormal)
+}
+
+// ProcessWithPriority creates a pipeline with a priority
+func (t *WebRTCTransport) ProcessWithPriority(pid, tid, eid string, config []byte, priority Priority) error {
+	return t.ProcessCorrelated(pid, tid, eid, config, priority, "")
+}
+
+// ProcessCorrelated creates a pipeline with a priority, for the request
+// with a correlation ID
+func (t *WebRTCTransport) ProcessCorrelated(pid, tid, eid string, config []byte, priority Priority, correlation string) error {
+	log.Infof("WebRTCTransport.Process id=%s priority=%s correlation=%s", pid, priority, correlation)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	e := registry.GetElement(eid)
 	if e == nil {
 		log.Errorf("element not found: %s", eid)
-		return errors.New("element not found")
+		return ErrElementNotFound
 	}
 
 	b := t.builders[tid]
@@ -235,22 +304,99 @@ func (t *WebRTCTransport) Process(pid, tid, eid string, config []byte) error {
 		log.Debugf("builder not found for track %s. queuing.", tid)
 		t.pending[tid] = append(t.pending[tid], PendingProcess{
 			pid: pid,
-			fn:  func() Element { return e(t.id, pid, tid, config) },
+			fn: func() Element {
+				return WithCorrelation(WithPriority(e(t.id, pid, tid, config), priority), correlation)
+			},
 		})
 		return nil
 	}
 
 	process := t.processes[pid]
 	if process == nil {
-		process = e(t.id, pid, tid, config)
+		process = WithCorrelation(WithPriority(e(t.id, pid, tid, config), priority), correlation)
 		t.processes[pid] = process
 	}
 
 	b.AttachElement(process)
+	t.rebalance()
 
 	return nil
 }
 
+// Codec returns the codec of a track, if it has arrived
+func (t *WebRTCTransport) Codec(tid string) (webrtc.RTPCodecParameters, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	b := t.builders[tid]
+	if b == nil {
+		return webrtc.RTPCodecParameters{}, false
+	}
+	return b.Track().Codec(), true
+}
+
+// Layout returns the layout composites of the session place participants
+// with, which can be switched while they run
+func (t *WebRTCTransport) Layout() *layout.Current {
+	return t.layout
+}
+
+// Roster returns the names and avatars composites of the session show on
+// participants' tiles
+func (t *WebRTCTransport) Roster() *layout.Roster {
+	return t.roster
+}
+
+// Canvas returns the background and safe margins composites of the session
+// draw participants' tiles on
+func (t *WebRTCTransport) Canvas() *layout.Canvas {
+	return t.canvas
+}
+
+// Interpreters returns the routes of the session's interpreters to the
+// channels of languages, shared with the session's InterpreterRouters
+func (t *WebRTCTransport) Interpreters() *interpret.Routes {
+	return interpret.Session(t.id)
+}
+
+// SetOptOut opts a track out of recording, or back in, whether or not it
+// has arrived. While it is opted out, by this or by the sender with the
+// DoNotRecordURI header extension, its samples reach no element.
+func (t *WebRTCTransport) SetOptOut(tid string, v bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if v {
+		t.optedOut[tid] = true
+	} else {
+		delete(t.optedOut, tid)
+	}
+	if b := t.builders[tid]; b != nil {
+		b.optOut.set(v)
+	}
+}
+
+// OptedOut reports whether a track is opted out of recording, by the
+// application or the sender
+func (t *WebRTCTransport) OptedOut(tid string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if b := t.builders[tid]; b != nil {
+		return b.optOut.active()
+	}
+	return t.optedOut[tid]
+}
+
+// Tracks returns the ids of the tracks that have arrived
+func (t *WebRTCTransport) Tracks() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	tids := make([]string, 0, len(t.builders))
+	for tid := range t.builders {
+		tids = append(tids, tid)
+	}
+	sort.Strings(tids)
+	return tids
+}
+
 // Attach an element that already exists
 func (t *WebRTCTransport) Run(tid string, element Element) error {
 	log.Infof("WebRTCTransport.Run tid=%s", tid)
@@ -276,6 +422,73 @@ func (t *WebRTCTransport) Run(tid string, element Element) error {
 	return nil
 }
 
+// Attach feeds an element the samples of a track that has arrived, beside
+// its pipelines, until Detach. Unlike Run it isn't queued for a track to
+// come, or kept as the track's process.
+func (t *WebRTCTransport) Attach(tid string, element Element) error {
+	t.mu.RLock()
+	b := t.builders[tid]
+	t.mu.RUnlock()
+	if b == nil {
+		return ErrTrackNotFound
+	}
+	b.AttachElement(element)
+	return nil
+}
+
+// Detach stops feeding an element attached with Attach and closes it
+func (t *WebRTCTransport) Detach(tid string, element Element) {
+	t.mu.RLock()
+	b := t.builders[tid]
+	t.mu.RUnlock()
+	if b != nil {
+		b.DetachElement(element)
+	}
+}
+
+// Room is an element of a whole session, e.g. a RoomRecorder, fed the
+// samples of each track through an element of its own
+type Room interface {
+	// Join returns the element of a track, closed when the track ends
+	Join(tid string) Element
+	Close()
+}
+
+// RunRoom feeds a room the tracks of the session, those that have arrived
+// and those arriving, until StopRoom or the transport closes
+func (t *WebRTCTransport) RunRoom(r Room) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	joined := make(map[string]Element)
+	for tid, b := range t.builders {
+		joined[tid] = r.Join(tid)
+		b.AttachElement(joined[tid])
+	}
+	t.rooms[r] = joined
+	t.rebalance()
+}
+
+// StopRoom stops feeding a room the tracks of the session and closes it
+func (t *WebRTCTransport) StopRoom(r Room) {
+	t.mu.Lock()
+	joined, ok := t.rooms[r]
+	delete(t.rooms, r)
+	builders := make(map[string]*Builder)
+	for tid := range joined {
+		builders[tid] = t.builders[tid]
+	}
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+	for tid, e := range joined {
+		if b := builders[tid]; b != nil {
+			b.DetachElement(e)
+		}
+	}
+	r.Close()
+}
+
 // Stop processing a track. Key is pid (Process) or tid (Run).
 func (t *WebRTCTransport) Stop(key string) {
 	if b := t.builders[key]; b != nil {